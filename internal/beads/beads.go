@@ -3,6 +3,7 @@ package beads
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,6 +11,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/steveyegge/gastown/internal/runtime"
 )
@@ -34,6 +36,7 @@ type Issue struct {
 	CreatedBy   string   `json:"created_by,omitempty"`
 	UpdatedAt   string   `json:"updated_at"`
 	ClosedAt    string   `json:"closed_at,omitempty"`
+	CloseReason string   `json:"close_reason,omitempty"`
 	Parent      string   `json:"parent,omitempty"`
 	Assignee    string   `json:"assignee,omitempty"`
 	Children    []string `json:"children,omitempty"`
@@ -77,6 +80,7 @@ type ListOptions struct {
 	Parent     string // filter by parent ID
 	Assignee   string // filter by assignee (e.g., "gastown/Toast")
 	NoAssignee bool   // filter for issues with no assignee
+	Limit      int    // max results; 0 for bd's default, -1 for no limit
 }
 
 // CreateOptions specifies options for creating an issue.
@@ -113,8 +117,9 @@ type SyncStatus struct {
 // Beads wraps bd CLI operations for a working directory.
 type Beads struct {
 	workDir  string
-	beadsDir string // Optional BEADS_DIR override for cross-database access
-	isolated bool   // If true, suppress inherited beads env vars (for test isolation)
+	beadsDir string        // Optional BEADS_DIR override for cross-database access
+	isolated bool          // If true, suppress inherited beads env vars (for test isolation)
+	timeout  time.Duration // If set, bd commands are killed after this long
 
 	// Lazy-cached town root for routing resolution.
 	// Populated on first call to getTownRoot() to avoid filesystem walk on every operation.
@@ -140,6 +145,12 @@ func NewWithBeadsDir(workDir, beadsDir string) *Beads {
 	return &Beads{workDir: workDir, beadsDir: beadsDir}
 }
 
+// SetTimeout limits how long each bd command may run. Callers that must stay
+// responsive, like the dashboard, set it; zero means no limit.
+func (b *Beads) SetTimeout(d time.Duration) {
+	b.timeout = d
+}
+
 // getActor returns the BD_ACTOR value for this context.
 // Returns empty string when in isolated mode (tests) to prevent
 // inherited actors from routing to production databases.
@@ -202,7 +213,13 @@ func (b *Beads) run(args ...string) ([]byte, error) {
 		fullArgs = append([]string{"--db", beadsDB}, fullArgs...)
 	}
 
-	cmd := exec.Command("bd", fullArgs...) //nolint:gosec // G204: bd is a trusted internal tool
+	ctx := context.Background()
+	if b.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.timeout)
+		defer cancel()
+	}
+	cmd := exec.CommandContext(ctx, "bd", fullArgs...) //nolint:gosec // G204: bd is a trusted internal tool
 	cmd.Dir = b.workDir
 
 	// Build environment: filter beads env vars when in isolated mode (tests)
@@ -220,6 +237,9 @@ func (b *Beads) run(args ...string) ([]byte, error) {
 	cmd.Stderr = &stderr
 
 	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("bd timed out after %v", b.timeout)
+	}
 	if err != nil {
 		return nil, b.wrapError(err, stderr.String(), args)
 	}
@@ -289,6 +309,15 @@ func filterBeadsEnv(environ []string) []string {
 
 // List returns issues matching the given options.
 func (b *Beads) List(opts ListOptions) ([]*Issue, error) {
+	if r := b.sqlReader(); r != nil {
+		if issues, err := r.List(opts); err == nil {
+			if opts.Limit > 0 && len(issues) > opts.Limit {
+				issues = issues[:opts.Limit]
+			}
+			return issues, nil
+		}
+	}
+
 	args := []string{"list", "--json"}
 
 	if opts.Status != "" {
@@ -313,6 +342,11 @@ func (b *Beads) List(opts ListOptions) ([]*Issue, error) {
 	if opts.NoAssignee {
 		args = append(args, "--no-assignee")
 	}
+	if opts.Limit < 0 {
+		args = append(args, "--limit=0")
+	} else if opts.Limit > 0 {
+		args = append(args, fmt.Sprintf("--limit=%d", opts.Limit))
+	}
 
 	out, err := b.run(args...)
 	if err != nil {
@@ -382,6 +416,12 @@ func (b *Beads) GetAssignedIssue(assignee string) (*Issue, error) {
 
 // Ready returns issues that are ready to work (not blocked).
 func (b *Beads) Ready() ([]*Issue, error) {
+	if r := b.sqlReader(); r != nil {
+		if issues, err := r.Ready(); err == nil {
+			return issues, nil
+		}
+	}
+
 	out, err := b.run("ready", "--json")
 	if err != nil {
 		return nil, err
@@ -414,6 +454,10 @@ func (b *Beads) ReadyWithType(issueType string) ([]*Issue, error) {
 
 // Show returns detailed information about an issue.
 func (b *Beads) Show(id string) (*Issue, error) {
+	if issues, ok := b.sqlShowMultiple([]string{id}); ok && issues[id] != nil {
+		return issues[id], nil
+	}
+
 	out, err := b.run("show", id, "--json")
 	if err != nil {
 		return nil, err
@@ -438,6 +482,9 @@ func (b *Beads) ShowMultiple(ids []string) (map[string]*Issue, error) {
 	if len(ids) == 0 {
 		return make(map[string]*Issue), nil
 	}
+	if issues, ok := b.sqlShowMultiple(ids); ok {
+		return issues, nil
+	}
 
 	// bd show supports multiple IDs
 	args := append([]string{"show", "--json"}, ids...)
@@ -461,6 +508,12 @@ func (b *Beads) ShowMultiple(ids []string) (map[string]*Issue, error) {
 
 // Blocked returns issues that are blocked by dependencies.
 func (b *Beads) Blocked() ([]*Issue, error) {
+	if r := b.sqlReader(); r != nil {
+		if issues, err := r.Blocked(); err == nil {
+			return issues, nil
+		}
+	}
+
 	out, err := b.run("blocked", "--json")
 	if err != nil {
 		return nil, err
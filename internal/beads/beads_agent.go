@@ -529,6 +529,12 @@ func (b *Beads) GetAgentBead(id string) (*Issue, *AgentFields, error) {
 // ListAgentBeads returns all agent beads in a single query.
 // Returns a map of agent bead ID to Issue.
 func (b *Beads) ListAgentBeads() (map[string]*Issue, error) {
+	if r := b.sqlReader(); r != nil {
+		if issues, err := r.ListAgentBeads(); err == nil {
+			return issues, nil
+		}
+	}
+
 	out, err := b.run("list", "--label=gt:agent", "--json")
 	if err != nil {
 		return nil, err
@@ -1562,6 +1562,33 @@ Wisp_TTL_Error: 336h`,
 	}
 }
 
+// TestParseRoleConfigWitness tests the witness auto-spawn fields round-trip.
+func TestParseRoleConfigWitness(t *testing.T) {
+	config := ParseRoleConfig(`max-workers: 6
+spawn_delay_ms: 2000
+auto_spawn: false
+epic_id: gt-epic-1
+issue_prefix: gt-
+max_queue_depth: -1`)
+	if config == nil {
+		t.Fatal("ParseRoleConfig() = nil")
+	}
+	if config.MaxWorkers != 6 || config.SpawnDelayMs != 2000 || config.MaxQueueDepth != -1 {
+		t.Errorf("numbers = %d, %d, %d", config.MaxWorkers, config.SpawnDelayMs, config.MaxQueueDepth)
+	}
+	if config.AutoSpawn == nil || *config.AutoSpawn {
+		t.Errorf("AutoSpawn = %v, want false", config.AutoSpawn)
+	}
+	if config.EpicID != "gt-epic-1" || config.IssuePrefix != "gt-" {
+		t.Errorf("EpicID = %q, IssuePrefix = %q", config.EpicID, config.IssuePrefix)
+	}
+
+	again := ParseRoleConfig(FormatRoleConfig(config))
+	if again == nil || again.MaxWorkers != 6 || again.AutoSpawn == nil || *again.AutoSpawn || again.EpicID != "gt-epic-1" {
+		t.Errorf("round trip = %+v", again)
+	}
+}
+
 // TestFormatRoleConfigWispTTLs tests that wisp TTLs are included in format output.
 func TestFormatRoleConfigWispTTLs(t *testing.T) {
 	config := &RoleConfig{
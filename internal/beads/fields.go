@@ -23,6 +23,7 @@ type AttachmentFields struct {
 	AttachedArgs     string // Natural language args passed via gt sling --args (no-tmux mode)
 	DispatchedBy     string // Agent ID that dispatched this work (for completion notification)
 	NoMerge          bool   // If true, gt done skips merge queue (for upstream PRs/human review)
+	TraceID          string // Lifecycle trace ID (see internal/tracing)
 }
 
 // ParseAttachmentFields extracts attachment fields from an issue's description.
@@ -70,6 +71,9 @@ func ParseAttachmentFields(issue *Issue) *AttachmentFields {
 		case "no_merge", "no-merge", "nomerge":
 			fields.NoMerge = strings.ToLower(value) == "true"
 			hasFields = true
+		case "trace_id", "trace-id", "traceid":
+			fields.TraceID = value
+			hasFields = true
 		}
 	}
 
@@ -103,6 +107,9 @@ func FormatAttachmentFields(fields *AttachmentFields) string {
 	if fields.NoMerge {
 		lines = append(lines, "no_merge: true")
 	}
+	if fields.TraceID != "" {
+		lines = append(lines, "trace_id: "+fields.TraceID)
+	}
 
 	return strings.Join(lines, "\n")
 }
@@ -128,6 +135,9 @@ func SetAttachmentFields(issue *Issue, fields *AttachmentFields) string {
 		"no_merge":          true,
 		"no-merge":          true,
 		"nomerge":           true,
+		"trace_id":          true,
+		"trace-id":          true,
+		"traceid":           true,
 	}
 
 	// Collect non-attachment lines from existing description
@@ -564,6 +574,15 @@ type RoleConfig struct {
 	// Examples: wisp_ttl_patrol: 48h, wisp_ttl_error: 336h, wisp_ttl_gc_report: 24h
 	// These override rig config and hardcoded defaults for compaction policy.
 	WispTTLs map[string]string
+
+	// Witness auto-spawn settings (see witness.WitnessConfig). Rig
+	// settings override these.
+	MaxWorkers    int
+	SpawnDelayMs  int
+	AutoSpawn     *bool
+	EpicID        string
+	IssuePrefix   string
+	MaxQueueDepth int
 }
 
 // ParseRoleConfig extracts RoleConfig from a role bead's description.
@@ -628,6 +647,32 @@ func ParseRoleConfig(description string) *RoleConfig {
 		case "stuck_threshold", "stuck-threshold", "stuckthreshold":
 			config.StuckThreshold = value
 			hasFields = true
+		// Witness auto-spawn fields
+		case "max_workers", "max-workers", "maxworkers":
+			if n, err := parseIntValue(value); err == nil {
+				config.MaxWorkers = n
+				hasFields = true
+			}
+		case "spawn_delay_ms", "spawn-delay-ms", "spawndelayms":
+			if n, err := parseIntValue(value); err == nil {
+				config.SpawnDelayMs = n
+				hasFields = true
+			}
+		case "auto_spawn", "auto-spawn", "autospawn":
+			autoSpawn := strings.ToLower(value) == "true"
+			config.AutoSpawn = &autoSpawn
+			hasFields = true
+		case "epic_id", "epic-id", "epicid":
+			config.EpicID = value
+			hasFields = true
+		case "issue_prefix", "issue-prefix", "issueprefix":
+			config.IssuePrefix = value
+			hasFields = true
+		case "max_queue_depth", "max-queue-depth", "maxqueuedepth":
+			if n, err := parseIntValue(value); err == nil {
+				config.MaxQueueDepth = n
+				hasFields = true
+			}
 		default:
 			// Check for wisp_ttl_* pattern (e.g., wisp_ttl_patrol, wisp-ttl-error)
 			lowerKey := strings.ToLower(key)
@@ -699,6 +744,24 @@ func FormatRoleConfig(config *RoleConfig) string {
 	for _, wt := range wispTypes {
 		lines = append(lines, "wisp_ttl_"+wt+": "+config.WispTTLs[wt])
 	}
+	if config.MaxWorkers != 0 {
+		lines = append(lines, fmt.Sprintf("max_workers: %d", config.MaxWorkers))
+	}
+	if config.SpawnDelayMs != 0 {
+		lines = append(lines, fmt.Sprintf("spawn_delay_ms: %d", config.SpawnDelayMs))
+	}
+	if config.AutoSpawn != nil {
+		lines = append(lines, fmt.Sprintf("auto_spawn: %t", *config.AutoSpawn))
+	}
+	if config.EpicID != "" {
+		lines = append(lines, "epic_id: "+config.EpicID)
+	}
+	if config.IssuePrefix != "" {
+		lines = append(lines, "issue_prefix: "+config.IssuePrefix)
+	}
+	if config.MaxQueueDepth != 0 {
+		lines = append(lines, fmt.Sprintf("max_queue_depth: %d", config.MaxQueueDepth))
+	}
 
 	return strings.Join(lines, "\n")
 }
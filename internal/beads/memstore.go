@@ -0,0 +1,434 @@
+package beads
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemStore is an in-memory Store that follows bd's semantics closely
+// enough for hermetic tests: list filters, hierarchical child IDs,
+// gt:<type> labels for CreateOptions.Type, blocking dependencies, and
+// dependency details only on Show. It is safe for concurrent use.
+//
+// Issues are returned as copies, so callers may modify them freely.
+type MemStore struct {
+	mu     sync.Mutex
+	prefix string
+	seq    int
+	issues map[string]*Issue
+	order  []string            // IDs in creation order
+	deps   map[string][]string // issue ID -> IDs it depends on
+	now    func() time.Time
+}
+
+// NewMemStore returns an empty MemStore whose generated IDs use prefix
+// (e.g. "gt" or "gt-" yields gt-1, gt-2, ...).
+func NewMemStore(prefix string) *MemStore {
+	prefix = strings.TrimSuffix(prefix, "-")
+	if prefix == "" {
+		prefix = "bd"
+	}
+	return &MemStore{
+		prefix: prefix,
+		issues: make(map[string]*Issue),
+		deps:   make(map[string][]string),
+		now:    time.Now,
+	}
+}
+
+// Compile-time check that MemStore satisfies Store.
+var _ Store = (*MemStore)(nil)
+
+// Add stores copies of issues as given, replacing any with the same ID.
+// It seeds state Create cannot express, such as statuses, assignees and
+// timestamps. An issue's DependsOn entries become blocking dependencies.
+func (m *MemStore) Add(issues ...*Issue) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, issue := range issues {
+		c := cloneIssue(issue)
+		if c.Status == "" {
+			c.Status = "open"
+		}
+		if c.Type == "" {
+			c.Type = "task"
+		}
+		if _, exists := m.issues[c.ID]; !exists {
+			m.order = append(m.order, c.ID)
+		}
+		m.deps[c.ID] = c.DependsOn
+		c.DependsOn, c.Children = nil, nil
+		c.Dependencies, c.Dependents = nil, nil
+		m.issues[c.ID] = c
+	}
+}
+
+// List returns issues matching opts in creation order.
+func (m *MemStore) List(opts ListOptions) ([]*Issue, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	label := opts.Label
+	if label == "" && opts.Type != "" {
+		label = "gt:" + opts.Type
+	}
+	var result []*Issue
+	for _, id := range m.order {
+		issue := m.issues[id]
+		switch opts.Status {
+		case "":
+			if issue.Status == "closed" {
+				continue
+			}
+		case "all":
+		default:
+			if issue.Status != opts.Status {
+				continue
+			}
+		}
+		if label != "" && !HasLabel(issue, label) {
+			continue
+		}
+		if opts.Priority >= 0 && issue.Priority != opts.Priority {
+			continue
+		}
+		if opts.Parent != "" && issue.Parent != opts.Parent {
+			continue
+		}
+		if opts.Assignee != "" && issue.Assignee != opts.Assignee {
+			continue
+		}
+		if opts.NoAssignee && issue.Assignee != "" {
+			continue
+		}
+		if opts.Limit > 0 && len(result) == opts.Limit {
+			break
+		}
+		result = append(result, m.listView(issue))
+	}
+	return result, nil
+}
+
+// Ready returns open, non-ephemeral issues whose blocking dependencies are
+// all closed, highest priority first.
+func (m *MemStore) Ready() ([]*Issue, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var result []*Issue
+	for _, id := range m.order {
+		issue := m.issues[id]
+		if issue.Status != "open" || issue.Ephemeral || m.blocked(id) {
+			continue
+		}
+		result = append(result, m.listView(issue))
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Priority < result[j].Priority
+	})
+	return result, nil
+}
+
+// Show returns an issue with its Dependencies and Dependents filled in.
+func (m *MemStore) Show(id string) (*Issue, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	issue, ok := m.issues[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return m.showView(issue), nil
+}
+
+// ShowMultiple returns the issues found among ids. Missing IDs are skipped.
+func (m *MemStore) ShowMultiple(ids []string) (map[string]*Issue, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := make(map[string]*Issue, len(ids))
+	for _, id := range ids {
+		if issue, ok := m.issues[id]; ok {
+			result[id] = m.showView(issue)
+		}
+	}
+	return result, nil
+}
+
+// Create creates an issue. Children of a parent get hierarchical IDs
+// (<parent>.1, <parent>.2, ...), others <prefix>-<n>.
+func (m *MemStore) Create(opts CreateOptions) (*Issue, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var id string
+	if opts.Parent != "" {
+		for n := 1; id == "" || m.issues[id] != nil; n++ {
+			id = opts.Parent + "." + strconv.Itoa(n)
+		}
+	} else {
+		for id == "" || m.issues[id] != nil {
+			m.seq++
+			id = m.prefix + "-" + strconv.Itoa(m.seq)
+		}
+	}
+	return m.create(id, opts)
+}
+
+// CreateWithID creates an issue with the given ID.
+func (m *MemStore) CreateWithID(id string, opts CreateOptions) (*Issue, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.issues[id]; exists {
+		return nil, fmt.Errorf("issue %s already exists", id)
+	}
+	return m.create(id, opts)
+}
+
+func (m *MemStore) create(id string, opts CreateOptions) (*Issue, error) {
+	if opts.Title == "" {
+		return nil, fmt.Errorf("creating %s: title is required", id)
+	}
+	if opts.Parent != "" {
+		if _, ok := m.issues[opts.Parent]; !ok {
+			return nil, fmt.Errorf("creating %s: parent %s not found", id, opts.Parent)
+		}
+	}
+	priority := opts.Priority
+	if priority < 0 {
+		priority = 2 // bd's default
+	}
+	now := m.timestamp()
+	issue := &Issue{
+		ID:          id,
+		Title:       opts.Title,
+		Description: opts.Description,
+		Status:      "open",
+		Priority:    priority,
+		Type:        "task",
+		CreatedAt:   now,
+		CreatedBy:   opts.Actor,
+		UpdatedAt:   now,
+		Parent:      opts.Parent,
+		Ephemeral:   opts.Ephemeral,
+	}
+	if opts.Type != "" {
+		issue.Labels = []string{"gt:" + opts.Type}
+	}
+	m.issues[id] = issue
+	m.order = append(m.order, id)
+	return m.listView(issue), nil
+}
+
+// Update applies opts. SetLabels replaces all labels; otherwise AddLabels
+// and RemoveLabels are applied.
+func (m *MemStore) Update(id string, opts UpdateOptions) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	issue, ok := m.issues[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if opts.Title != nil {
+		issue.Title = *opts.Title
+	}
+	if opts.Status != nil {
+		m.setStatus(issue, *opts.Status)
+	}
+	if opts.Priority != nil {
+		issue.Priority = *opts.Priority
+	}
+	if opts.Description != nil {
+		issue.Description = *opts.Description
+	}
+	if opts.Assignee != nil {
+		issue.Assignee = *opts.Assignee
+	}
+	if len(opts.SetLabels) > 0 {
+		issue.Labels = slices.Clone(opts.SetLabels)
+	} else {
+		for _, label := range opts.AddLabels {
+			if !slices.Contains(issue.Labels, label) {
+				issue.Labels = append(issue.Labels, label)
+			}
+		}
+		for _, label := range opts.RemoveLabels {
+			issue.Labels = slices.DeleteFunc(issue.Labels, func(l string) bool { return l == label })
+		}
+	}
+	issue.UpdatedAt = m.timestamp()
+	return nil
+}
+
+// Close closes issues. Nothing is closed if any ID is unknown.
+func (m *MemStore) Close(ids ...string) error {
+	return m.CloseWithReason("", ids...)
+}
+
+// CloseWithReason closes issues. MemStore does not keep the reason.
+func (m *MemStore) CloseWithReason(reason string, ids ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, id := range ids {
+		if _, ok := m.issues[id]; !ok {
+			return fmt.Errorf("closing %s: %w", id, ErrNotFound)
+		}
+	}
+	for _, id := range ids {
+		m.setStatus(m.issues[id], "closed")
+	}
+	return nil
+}
+
+// AddDependency records that issue is blocked by dependsOn.
+func (m *MemStore) AddDependency(issue, dependsOn string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if issue == dependsOn {
+		return fmt.Errorf("issue %s cannot depend on itself", issue)
+	}
+	for _, id := range []string{issue, dependsOn} {
+		if _, ok := m.issues[id]; !ok {
+			return fmt.Errorf("adding dependency: %s: %w", id, ErrNotFound)
+		}
+	}
+	if !slices.Contains(m.deps[issue], dependsOn) {
+		m.deps[issue] = append(m.deps[issue], dependsOn)
+	}
+	return nil
+}
+
+// RemoveDependency removes a dependency. Removing a missing one is a no-op.
+func (m *MemStore) RemoveDependency(issue, dependsOn string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.issues[issue]; !ok {
+		return ErrNotFound
+	}
+	m.deps[issue] = slices.DeleteFunc(m.deps[issue], func(id string) bool { return id == dependsOn })
+	return nil
+}
+
+// SetHookBead sets an agent bead's hook slot.
+func (m *MemStore) SetHookBead(agentBeadID, hookBeadID string) error {
+	return m.setHook(agentBeadID, hookBeadID)
+}
+
+// ClearHookBead empties an agent bead's hook slot.
+func (m *MemStore) ClearHookBead(agentBeadID string) error {
+	return m.setHook(agentBeadID, "")
+}
+
+func (m *MemStore) setHook(agentBeadID, hookBeadID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	agent, ok := m.issues[agentBeadID]
+	if !ok {
+		return fmt.Errorf("setting hook: %w", ErrNotFound)
+	}
+	agent.HookBead = hookBeadID
+	agent.UpdatedAt = m.timestamp()
+	return nil
+}
+
+func (m *MemStore) setStatus(issue *Issue, status string) {
+	issue.Status = status
+	if status == "closed" {
+		issue.ClosedAt = m.timestamp()
+	} else {
+		issue.ClosedAt = ""
+	}
+}
+
+func (m *MemStore) timestamp() string {
+	return m.now().UTC().Format(time.RFC3339)
+}
+
+// blocked reports whether any of id's blocking dependencies is not closed.
+func (m *MemStore) blocked(id string) bool {
+	for _, dep := range m.deps[id] {
+		if d, ok := m.issues[dep]; ok && d.Status != "closed" {
+			return true
+		}
+	}
+	return false
+}
+
+// listView is an issue as bd list reports it: counts but no details.
+func (m *MemStore) listView(issue *Issue) *Issue {
+	c := cloneIssue(issue)
+	c.DependencyCount = len(m.deps[issue.ID])
+	c.DependentCount = 0
+	c.BlockedByCount = 0
+	for _, id := range m.order {
+		if slices.Contains(m.deps[id], issue.ID) {
+			c.DependentCount++
+		}
+	}
+	for _, dep := range m.deps[issue.ID] {
+		if d, ok := m.issues[dep]; ok && d.Status != "closed" {
+			c.BlockedByCount++
+		}
+	}
+	return c
+}
+
+// showView is an issue as bd show reports it, with dependency details.
+// The parent appears as a parent-child dependency, children as
+// parent-child dependents.
+func (m *MemStore) showView(issue *Issue) *Issue {
+	c := m.listView(issue)
+	if parent, ok := m.issues[issue.Parent]; ok {
+		c.Dependencies = append(c.Dependencies, issueDep(parent, "parent-child"))
+	}
+	for _, dep := range m.deps[issue.ID] {
+		if d, ok := m.issues[dep]; ok {
+			c.Dependencies = append(c.Dependencies, issueDep(d, "blocks"))
+		}
+	}
+	for _, id := range m.order {
+		other := m.issues[id]
+		if other.Parent == issue.ID {
+			c.Children = append(c.Children, id)
+			c.Dependents = append(c.Dependents, issueDep(other, "parent-child"))
+		}
+		if slices.Contains(m.deps[id], issue.ID) {
+			c.Dependents = append(c.Dependents, issueDep(other, "blocks"))
+		}
+	}
+	return c
+}
+
+func issueDep(issue *Issue, depType string) IssueDep {
+	return IssueDep{
+		ID:             issue.ID,
+		Title:          issue.Title,
+		Status:         issue.Status,
+		Priority:       issue.Priority,
+		Type:           issue.Type,
+		DependencyType: depType,
+	}
+}
+
+func cloneIssue(issue *Issue) *Issue {
+	c := *issue
+	c.Children = slices.Clone(issue.Children)
+	c.DependsOn = slices.Clone(issue.DependsOn)
+	c.Blocks = slices.Clone(issue.Blocks)
+	c.BlockedBy = slices.Clone(issue.BlockedBy)
+	c.Labels = slices.Clone(issue.Labels)
+	c.Dependencies = slices.Clone(issue.Dependencies)
+	c.Dependents = slices.Clone(issue.Dependents)
+	return &c
+}
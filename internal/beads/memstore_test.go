@@ -0,0 +1,183 @@
+package beads
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func issueIDs(issues []*Issue) string {
+	var ids []string
+	for _, issue := range issues {
+		ids = append(ids, issue.ID)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestMemStore_CreateAndList(t *testing.T) {
+	s := NewMemStore("gt-")
+
+	epic, err := s.Create(CreateOptions{Title: "Epic", Type: "epic", Priority: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if epic.ID != "gt-1" || !HasLabel(epic, "gt:epic") || epic.Status != "open" {
+		t.Errorf("epic = %+v", epic)
+	}
+	child, err := s.Create(CreateOptions{Title: "Child", Parent: epic.ID, Priority: -1, Actor: "mayor"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if child.ID != "gt-1.1" || child.Priority != 2 || child.CreatedBy != "mayor" {
+		t.Errorf("child = %+v", child)
+	}
+	if _, err := s.CreateWithID("hq-mayor", CreateOptions{Title: "Mayor", Type: "agent"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CreateWithID("hq-mayor", CreateOptions{Title: "again"}); err == nil {
+		t.Error("duplicate CreateWithID should fail")
+	}
+	if _, err := s.Create(CreateOptions{Title: "orphan", Parent: "gt-404"}); err == nil {
+		t.Error("missing parent should fail")
+	}
+	assignee := "gastown/polecats/nux"
+	if err := s.Update(child.ID, UpdateOptions{Assignee: &assignee}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close("hq-mayor"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		opts ListOptions
+		want string
+	}{
+		{ListOptions{Priority: -1}, "gt-1,gt-1.1"},
+		{ListOptions{Status: "all", Priority: -1}, "gt-1,gt-1.1,hq-mayor"},
+		{ListOptions{Status: "closed", Priority: -1}, "hq-mayor"},
+		{ListOptions{Type: "epic", Priority: -1}, "gt-1"},
+		{ListOptions{Label: "gt:agent", Status: "all", Priority: -1}, "hq-mayor"},
+		{ListOptions{Priority: 2}, "gt-1.1"},
+		{ListOptions{Parent: "gt-1", Priority: -1}, "gt-1.1"},
+		{ListOptions{Assignee: assignee, Priority: -1}, "gt-1.1"},
+		{ListOptions{NoAssignee: true, Priority: -1}, "gt-1"},
+		{ListOptions{Status: "all", Priority: -1, Limit: 2}, "gt-1,gt-1.1"},
+		{ListOptions{Status: "all", Priority: -1, Limit: -1}, "gt-1,gt-1.1,hq-mayor"},
+	}
+	for _, tt := range tests {
+		got, err := s.List(tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ids := issueIDs(got); ids != tt.want {
+			t.Errorf("List(%+v) = %s, want %s", tt.opts, ids, tt.want)
+		}
+	}
+}
+
+func TestMemStore_Dependencies(t *testing.T) {
+	s := NewMemStore("gt")
+	s.Add(
+		&Issue{ID: "gt-mol", Title: "Molecule", Priority: 2},
+		&Issue{ID: "gt-mol.1", Title: "Design", Parent: "gt-mol", Priority: 1},
+		&Issue{ID: "gt-mol.2", Title: "Build", Parent: "gt-mol", Priority: 0, DependsOn: []string{"gt-mol.1"}},
+		&Issue{ID: "gt-wisp", Title: "Patrol", Ephemeral: true},
+	)
+
+	ready, _ := s.Ready()
+	if ids := issueIDs(ready); ids != "gt-mol.1,gt-mol" {
+		t.Errorf("Ready = %s", ids)
+	}
+
+	listed, _ := s.List(ListOptions{Parent: "gt-mol", Priority: -1})
+	if len(listed[1].Dependencies) != 0 || listed[1].DependencyCount != 1 || listed[1].BlockedByCount != 1 {
+		t.Errorf("list view = %+v", listed[1])
+	}
+
+	build, err := s.Show("gt-mol.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(build.Dependencies) != 2 || build.Dependencies[0].DependencyType != "parent-child" ||
+		build.Dependencies[1].ID != "gt-mol.1" || build.Dependencies[1].DependencyType != "blocks" {
+		t.Errorf("Dependencies = %+v", build.Dependencies)
+	}
+	mol, _ := s.Show("gt-mol")
+	if strings.Join(mol.Children, ",") != "gt-mol.1,gt-mol.2" || len(mol.Dependents) != 2 {
+		t.Errorf("molecule = %+v", mol)
+	}
+
+	if err := s.Close("gt-mol.1"); err != nil {
+		t.Fatal(err)
+	}
+	ready, _ = s.Ready()
+	if ids := issueIDs(ready); ids != "gt-mol.2,gt-mol" {
+		t.Errorf("Ready after close = %s", ids)
+	}
+
+	if err := s.RemoveDependency("gt-mol.2", "gt-mol.1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddDependency("gt-mol.1", "gt-mol.1"); err == nil {
+		t.Error("self dependency should fail")
+	}
+	if err := s.AddDependency("gt-mol.1", "gt-404"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("AddDependency(missing) = %v", err)
+	}
+	shown, _ := s.ShowMultiple([]string{"gt-mol.2", "gt-404"})
+	if len(shown) != 1 || len(shown["gt-mol.2"].Dependencies) != 1 {
+		t.Errorf("ShowMultiple = %+v", shown)
+	}
+}
+
+func TestMemStore_LabelsAndSlots(t *testing.T) {
+	s := NewMemStore("hq")
+	agent, _ := s.CreateWithID("hq-deacon", CreateOptions{Title: "Deacon", Type: "agent"})
+
+	if err := s.Update(agent.ID, UpdateOptions{AddLabels: []string{"a", "b", "a"}, RemoveLabels: []string{"gt:agent"}}); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := s.Show(agent.ID)
+	if strings.Join(got.Labels, ",") != "a,b" {
+		t.Errorf("labels = %v", got.Labels)
+	}
+	if err := s.Update(agent.ID, UpdateOptions{SetLabels: []string{"gt:agent"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.SetHookBead(agent.ID, "gt-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetHookBead(agent.ID, "gt-2"); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = s.Show(agent.ID)
+	if got.HookBead != "gt-2" || strings.Join(got.Labels, ",") != "gt:agent" {
+		t.Errorf("agent = %+v", got)
+	}
+
+	// Returned issues are copies.
+	got.Labels[0] = "mutated"
+	if again, _ := s.Show(agent.ID); again.Labels[0] != "gt:agent" {
+		t.Error("Show should return a copy")
+	}
+
+	if err := s.ClearHookBead(agent.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.Show(agent.ID); got.HookBead != "" {
+		t.Errorf("hook = %q after clear", got.HookBead)
+	}
+	if err := s.SetHookBead("hq-404", "gt-1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("SetHookBead(missing) = %v", err)
+	}
+	if err := s.Update("hq-404", UpdateOptions{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Update(missing) = %v", err)
+	}
+	if err := s.Close(agent.ID, "hq-404"); err == nil {
+		t.Error("Close with a missing ID should fail")
+	}
+	if got, _ := s.Show(agent.ID); got.Status != "open" {
+		t.Error("failed Close should close nothing")
+	}
+}
@@ -0,0 +1,433 @@
+package beads
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1" //nolint:gosec // G505: mysql_native_password is defined in terms of SHA-1
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// This file is a minimal MySQL client-protocol implementation: enough to
+// log in to a local Dolt sql-server and run text-protocol queries. Gas Town
+// avoids a database driver dependency (see doltserver), and the read path
+// only needs plain SELECTs.
+
+// Capability flags sent in the handshake response.
+const (
+	mysqlClientLongPassword     = 0x00000001
+	mysqlClientLongFlag         = 0x00000004
+	mysqlClientConnectWithDB    = 0x00000008
+	mysqlClientProtocol41       = 0x00000200
+	mysqlClientTransactions     = 0x00002000
+	mysqlClientSecureConnection = 0x00008000
+	mysqlClientMultiResults     = 0x00020000
+	mysqlClientPluginAuth       = 0x00080000
+
+	mysqlComQuery       = 0x03
+	mysqlCharsetUTF8MB4 = 45
+	mysqlMaxPacket      = 1<<24 - 1
+)
+
+// mysqlError is an error reported by the server. The connection is still
+// usable after one.
+type mysqlError struct {
+	Code    uint16
+	Message string
+}
+
+func (e *mysqlError) Error() string {
+	return fmt.Sprintf("mysql error %d: %s", e.Code, e.Message)
+}
+
+// mysqlResult is a text-protocol result set. NULL values are nil.
+type mysqlResult struct {
+	Columns []string
+	Rows    [][]*string
+}
+
+// mysqlConn is a single client connection. It is not safe for concurrent use.
+type mysqlConn struct {
+	conn    net.Conn
+	r       *bufio.Reader
+	seq     byte
+	timeout time.Duration
+}
+
+// dialMySQL connects and authenticates.
+func dialMySQL(addr, user, password, database string, timeout time.Duration) (*mysqlConn, error) {
+	nc, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	c := &mysqlConn{conn: nc, r: bufio.NewReader(nc), timeout: timeout}
+	if err := c.handshake(user, password, database); err != nil {
+		nc.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *mysqlConn) close() error {
+	return c.conn.Close()
+}
+
+func (c *mysqlConn) handshake(user, password, database string) error {
+	_ = c.conn.SetDeadline(time.Now().Add(c.timeout))
+	defer func() { _ = c.conn.SetDeadline(time.Time{}) }()
+
+	pkt, err := c.readPacket()
+	if err != nil {
+		return fmt.Errorf("reading handshake: %w", err)
+	}
+	if len(pkt) > 0 && pkt[0] == 0xff {
+		return parseMySQLError(pkt)
+	}
+	scramble, plugin, err := parseHandshake(pkt)
+	if err != nil {
+		return err
+	}
+
+	auth, err := mysqlAuthResponse(plugin, password, scramble)
+	if err != nil {
+		return err
+	}
+	flags := uint32(mysqlClientLongPassword | mysqlClientLongFlag | mysqlClientProtocol41 |
+		mysqlClientTransactions | mysqlClientSecureConnection | mysqlClientMultiResults | mysqlClientPluginAuth)
+	if database != "" {
+		flags |= mysqlClientConnectWithDB
+	}
+
+	var resp bytes.Buffer
+	_ = binary.Write(&resp, binary.LittleEndian, flags)
+	_ = binary.Write(&resp, binary.LittleEndian, uint32(mysqlMaxPacket))
+	resp.WriteByte(mysqlCharsetUTF8MB4)
+	resp.Write(make([]byte, 23))
+	resp.WriteString(user)
+	resp.WriteByte(0)
+	resp.WriteByte(byte(len(auth)))
+	resp.Write(auth)
+	if database != "" {
+		resp.WriteString(database)
+		resp.WriteByte(0)
+	}
+	resp.WriteString(plugin)
+	resp.WriteByte(0)
+	if err := c.writePacket(resp.Bytes()); err != nil {
+		return err
+	}
+
+	for {
+		pkt, err := c.readPacket()
+		if err != nil {
+			return fmt.Errorf("reading auth result: %w", err)
+		}
+		switch {
+		case len(pkt) == 0:
+			return errors.New("empty auth result")
+		case pkt[0] == 0x00:
+			return nil
+		case pkt[0] == 0xff:
+			return parseMySQLError(pkt)
+		case pkt[0] == 0xfe:
+			// Auth switch request: plugin name, then new scramble.
+			rest := pkt[1:]
+			end := bytes.IndexByte(rest, 0)
+			if end < 0 {
+				return errors.New("malformed auth switch request")
+			}
+			plugin = string(rest[:end])
+			scramble = bytes.TrimRight(rest[end+1:], "\x00")
+			auth, err := mysqlAuthResponse(plugin, password, scramble)
+			if err != nil {
+				return err
+			}
+			if err := c.writePacket(auth); err != nil {
+				return err
+			}
+		case pkt[0] == 0x01 && len(pkt) == 2 && pkt[1] == 0x03:
+			// caching_sha2_password fast-auth success; the OK follows.
+		case pkt[0] == 0x01:
+			return errors.New("server requires full caching_sha2_password authentication (TLS), which is unsupported")
+		default:
+			return fmt.Errorf("unexpected auth packet 0x%02x", pkt[0])
+		}
+	}
+}
+
+// parseHandshake extracts the auth scramble and plugin from a v10 handshake.
+func parseHandshake(pkt []byte) ([]byte, string, error) {
+	if len(pkt) < 1 || pkt[0] != 10 {
+		return nil, "", errors.New("unsupported handshake protocol")
+	}
+	pos := 1
+	end := bytes.IndexByte(pkt[pos:], 0)
+	if end < 0 {
+		return nil, "", errors.New("malformed handshake")
+	}
+	pos += end + 1 // server version
+	pos += 4       // connection id
+	if len(pkt) < pos+8+1+2+1+2+2+1+10 {
+		return nil, "", errors.New("short handshake")
+	}
+	scramble := append([]byte{}, pkt[pos:pos+8]...)
+	pos += 8 + 1 // part 1 + filler
+	pos += 2 + 1 + 2 + 2
+	authLen := int(pkt[pos])
+	pos += 1 + 10
+	part2 := authLen - 8
+	if part2 < 13 {
+		part2 = 13
+	}
+	if len(pkt) < pos+part2 {
+		return nil, "", errors.New("short handshake scramble")
+	}
+	scramble = append(scramble, bytes.TrimRight(pkt[pos:pos+part2], "\x00")...)
+	pos += part2
+	plugin := "mysql_native_password"
+	if pos < len(pkt) {
+		if end := bytes.IndexByte(pkt[pos:], 0); end >= 0 {
+			plugin = string(pkt[pos : pos+end])
+		} else {
+			plugin = string(pkt[pos:])
+		}
+	}
+	return scramble, plugin, nil
+}
+
+// mysqlAuthResponse computes the scrambled password for an auth plugin.
+func mysqlAuthResponse(plugin, password string, scramble []byte) ([]byte, error) {
+	if password == "" {
+		return nil, nil
+	}
+	switch plugin {
+	case "mysql_native_password":
+		// SHA1(password) XOR SHA1(scramble + SHA1(SHA1(password)))
+		h1 := sha1.Sum([]byte(password)) //nolint:gosec // G401: protocol-mandated
+		h2 := sha1.Sum(h1[:])            //nolint:gosec // G401: protocol-mandated
+		h := sha1.New()                  //nolint:gosec // G401: protocol-mandated
+		h.Write(scramble)
+		h.Write(h2[:])
+		h3 := h.Sum(nil)
+		for i := range h3 {
+			h3[i] ^= h1[i]
+		}
+		return h3, nil
+	case "caching_sha2_password":
+		// SHA256(password) XOR SHA256(SHA256(SHA256(password)) + scramble)
+		h1 := sha256.Sum256([]byte(password))
+		h2 := sha256.Sum256(h1[:])
+		h := sha256.New()
+		h.Write(h2[:])
+		h.Write(scramble)
+		h3 := h.Sum(nil)
+		for i := range h3 {
+			h3[i] ^= h1[i]
+		}
+		return h3, nil
+	default:
+		return nil, fmt.Errorf("unsupported auth plugin %q", plugin)
+	}
+}
+
+// query runs a statement and returns its result set (empty for statements
+// that return none).
+func (c *mysqlConn) query(q string) (*mysqlResult, error) {
+	if c.timeout > 0 {
+		_ = c.conn.SetDeadline(time.Now().Add(c.timeout))
+		defer func() { _ = c.conn.SetDeadline(time.Time{}) }()
+	}
+
+	c.seq = 0
+	if err := c.writePacket(append([]byte{mysqlComQuery}, q...)); err != nil {
+		return nil, err
+	}
+	pkt, err := c.readPacket()
+	if err != nil {
+		return nil, err
+	}
+	if len(pkt) == 0 {
+		return nil, errors.New("empty query response")
+	}
+	switch pkt[0] {
+	case 0x00:
+		return &mysqlResult{}, nil
+	case 0xff:
+		return nil, parseMySQLError(pkt)
+	}
+
+	ncols, _, ok := readLenEncInt(pkt)
+	if !ok {
+		return nil, errors.New("malformed column count")
+	}
+	res := &mysqlResult{Columns: make([]string, 0, ncols)}
+	for i := uint64(0); i < ncols; i++ {
+		pkt, err := c.readPacket()
+		if err != nil {
+			return nil, err
+		}
+		name, err := parseColumnName(pkt)
+		if err != nil {
+			return nil, err
+		}
+		res.Columns = append(res.Columns, name)
+	}
+	if pkt, err := c.readPacket(); err != nil {
+		return nil, err
+	} else if !isEOFPacket(pkt) {
+		return nil, errors.New("expected EOF after column definitions")
+	}
+
+	for {
+		pkt, err := c.readPacket()
+		if err != nil {
+			return nil, err
+		}
+		if isEOFPacket(pkt) {
+			return res, nil
+		}
+		if len(pkt) > 0 && pkt[0] == 0xff {
+			return nil, parseMySQLError(pkt)
+		}
+		row := make([]*string, 0, ncols)
+		pos := 0
+		for i := uint64(0); i < ncols; i++ {
+			if pos >= len(pkt) {
+				return nil, errors.New("short row")
+			}
+			if pkt[pos] == 0xfb {
+				row = append(row, nil)
+				pos++
+				continue
+			}
+			n, size, ok := readLenEncInt(pkt[pos:])
+			if !ok || pos+size+int(n) > len(pkt) {
+				return nil, errors.New("malformed row")
+			}
+			pos += size
+			v := string(pkt[pos : pos+int(n)])
+			pos += int(n)
+			row = append(row, &v)
+		}
+		res.Rows = append(res.Rows, row)
+	}
+}
+
+// parseColumnName returns the name from a Protocol::ColumnDefinition41.
+func parseColumnName(pkt []byte) (string, error) {
+	pos := 0
+	var name string
+	// catalog, schema, table, org_table, name
+	for i := 0; i < 5; i++ {
+		n, size, ok := readLenEncInt(pkt[pos:])
+		if !ok || pos+size+int(n) > len(pkt) {
+			return "", errors.New("malformed column definition")
+		}
+		pos += size
+		name = string(pkt[pos : pos+int(n)])
+		pos += int(n)
+	}
+	return name, nil
+}
+
+func isEOFPacket(pkt []byte) bool {
+	return len(pkt) > 0 && pkt[0] == 0xfe && len(pkt) < 9
+}
+
+func parseMySQLError(pkt []byte) error {
+	if len(pkt) < 3 {
+		return &mysqlError{Message: "malformed error packet"}
+	}
+	e := &mysqlError{Code: binary.LittleEndian.Uint16(pkt[1:3])}
+	msg := pkt[3:]
+	if len(msg) >= 6 && msg[0] == '#' {
+		msg = msg[6:] // SQL state marker and state
+	}
+	e.Message = string(msg)
+	return e
+}
+
+// readLenEncInt decodes a length-encoded integer, returning its value and
+// encoded size.
+func readLenEncInt(b []byte) (uint64, int, bool) {
+	if len(b) == 0 {
+		return 0, 0, false
+	}
+	switch b[0] {
+	case 0xfc:
+		if len(b) < 3 {
+			return 0, 0, false
+		}
+		return uint64(binary.LittleEndian.Uint16(b[1:3])), 3, true
+	case 0xfd:
+		if len(b) < 4 {
+			return 0, 0, false
+		}
+		return uint64(b[1]) | uint64(b[2])<<8 | uint64(b[3])<<16, 4, true
+	case 0xfe:
+		if len(b) < 9 {
+			return 0, 0, false
+		}
+		return binary.LittleEndian.Uint64(b[1:9]), 9, true
+	case 0xfb, 0xff:
+		return 0, 0, false
+	default:
+		return uint64(b[0]), 1, true
+	}
+}
+
+// readPacket reads one logical packet, joining max-size continuations.
+func (c *mysqlConn) readPacket() ([]byte, error) {
+	var payload []byte
+	for {
+		var header [4]byte
+		if _, err := io.ReadFull(c.r, header[:]); err != nil {
+			return nil, err
+		}
+		n := int(header[0]) | int(header[1])<<8 | int(header[2])<<16
+		c.seq = header[3] + 1
+		buf := make([]byte, n)
+		if _, err := io.ReadFull(c.r, buf); err != nil {
+			return nil, err
+		}
+		payload = append(payload, buf...)
+		if n < mysqlMaxPacket {
+			break
+		}
+	}
+	if len(payload) == 0 {
+		return nil, errors.New("empty packet")
+	}
+	return payload, nil
+}
+
+// writePacket writes payload, splitting it into max-size packets.
+func (c *mysqlConn) writePacket(payload []byte) error {
+	for {
+		n := len(payload)
+		if n > mysqlMaxPacket {
+			n = mysqlMaxPacket
+		}
+		header := []byte{byte(n), byte(n >> 8), byte(n >> 16), c.seq}
+		c.seq++
+		if _, err := c.conn.Write(append(header, payload[:n]...)); err != nil {
+			return err
+		}
+		payload = payload[n:]
+		if n < mysqlMaxPacket {
+			return nil
+		}
+	}
+}
+
+// sqlQuote renders s as a single-quoted SQL string literal.
+func sqlQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\x00", `\0`, "\n", `\n`, "\r", `\r`, "\x1a", `\Z`)
+	return "'" + r.Replace(s) + "'"
+}
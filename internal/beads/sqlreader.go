@@ -0,0 +1,534 @@
+package beads
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SQL read path defaults.
+const (
+	DefaultSQLPort     = 3307 // doltserver.DefaultPort (doltserver imports beads)
+	DefaultSQLUser     = "root"
+	DefaultSQLMaxConns = 4
+	DefaultSQLTimeout  = 5 * time.Second
+
+	// sqlRetryAfter is how long a reader that failed to connect stays
+	// disabled before it dials again, so callers fall back to bd quickly.
+	sqlRetryAfter = 30 * time.Second
+)
+
+// SQLReadEnv enables the SQL read path when set to "1" or "true".
+const SQLReadEnv = "GT_BEADS_SQL"
+
+// ErrSQLUnavailable is returned by an SQLReader that recently failed to
+// connect.
+var ErrSQLUnavailable = errors.New("dolt sql-server unavailable")
+
+// SQLConfig locates a beads database on a Dolt sql-server.
+type SQLConfig struct {
+	Addr     string // host:port
+	User     string
+	Password string
+	Database string
+
+	// MaxConns caps the reader's open connections (default 4).
+	MaxConns int
+
+	// Timeout bounds dialing and each query (default 5s).
+	Timeout time.Duration
+}
+
+// SQLConfigFor returns the SQL location of the database behind beadsDir.
+// ok is false unless the directory's metadata.json puts it in Dolt server
+// mode with a named database.
+func SQLConfigFor(beadsDir string) (cfg SQLConfig, ok bool) {
+	data, err := os.ReadFile(filepath.Join(beadsDir, "metadata.json")) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		return cfg, false
+	}
+	var meta struct {
+		DoltMode     string `json:"dolt_mode"`
+		DoltDatabase string `json:"dolt_database"`
+		Host         string `json:"dolt_server_host"`
+		Port         int    `json:"dolt_server_port"`
+		User         string `json:"dolt_server_user"`
+	}
+	if err := json.Unmarshal(data, &meta); err != nil || meta.DoltMode != "server" || meta.DoltDatabase == "" {
+		return cfg, false
+	}
+	host, port, user := meta.Host, meta.Port, meta.User
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	if port == 0 {
+		port = DefaultSQLPort
+	}
+	if user == "" {
+		user = DefaultSQLUser
+	}
+	return SQLConfig{
+		Addr:     fmt.Sprintf("%s:%d", host, port),
+		User:     user,
+		Password: os.Getenv("BEADS_DOLT_PASSWORD"),
+		Database: meta.DoltDatabase,
+	}, true
+}
+
+// SQLReader answers hot read queries (list, show, ready, blocked, agent
+// beads) directly from a Dolt sql-server over a small connection pool,
+// avoiding a bd subprocess and connection per query. It is read-only:
+// writes must go through bd so its hooks, validation and JSONL export run.
+//
+// It reads bd's Dolt schema (issues, labels, dependencies). List results
+// carry labels and parent but not dependency counts; Show adds
+// Dependencies and Dependents. Ready and Blocked consider direct "blocks"
+// dependencies. Any error means the caller should fall back to bd.
+type SQLReader struct {
+	cfg SQLConfig
+	sem chan struct{} // one slot per open connection
+
+	mu        sync.Mutex
+	idle      []*mysqlConn
+	downUntil time.Time
+}
+
+// NewSQLReader returns a reader for cfg. Connections are opened lazily.
+func NewSQLReader(cfg SQLConfig) *SQLReader {
+	if cfg.MaxConns <= 0 {
+		cfg.MaxConns = DefaultSQLMaxConns
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultSQLTimeout
+	}
+	return &SQLReader{cfg: cfg, sem: make(chan struct{}, cfg.MaxConns)}
+}
+
+var (
+	sqlReadersMu sync.Mutex
+	sqlReaders   = make(map[SQLConfig]*SQLReader)
+)
+
+// SQLReaderFor returns the process-wide reader for beadsDir's database, or
+// nil when the SQL read path is disabled (see SQLReadEnv) or the directory
+// is not served by a Dolt sql-server. Readers are shared so their
+// connection pools are reused across Beads wrappers.
+func SQLReaderFor(beadsDir string) *SQLReader {
+	if !sqlReadEnabled() {
+		return nil
+	}
+	cfg, ok := SQLConfigFor(beadsDir)
+	if !ok {
+		return nil
+	}
+	sqlReadersMu.Lock()
+	defer sqlReadersMu.Unlock()
+	r := sqlReaders[cfg]
+	if r == nil {
+		r = NewSQLReader(cfg)
+		sqlReaders[cfg] = r
+	}
+	return r
+}
+
+func sqlReadEnabled() bool {
+	v := strings.ToLower(os.Getenv(SQLReadEnv))
+	return v == "1" || v == "true"
+}
+
+// Close closes idle connections. The reader stays usable.
+func (r *SQLReader) Close() error {
+	r.mu.Lock()
+	idle := r.idle
+	r.idle = nil
+	r.mu.Unlock()
+	for _, c := range idle {
+		_ = c.close()
+	}
+	return nil
+}
+
+// query runs q on a pooled connection. If an idle connection turns out to
+// be stale (e.g. the server restarted), the idle pool is dropped and q is
+// retried once on a fresh connection.
+func (r *SQLReader) query(q string) (*mysqlResult, error) {
+	r.sem <- struct{}{}
+	defer func() { <-r.sem }()
+
+	for attempt := 0; ; attempt++ {
+		conn, pooled, err := r.conn()
+		if err != nil {
+			return nil, err
+		}
+		res, err := conn.query(q)
+		var sqlErr *mysqlError
+		switch {
+		case err == nil:
+			r.release(conn)
+			return res, nil
+		case errors.As(err, &sqlErr):
+			r.release(conn)
+			return nil, fmt.Errorf("querying %s: %w", r.cfg.Database, err)
+		}
+		_ = conn.close()
+		if !pooled || attempt > 0 {
+			return nil, fmt.Errorf("querying %s: %w", r.cfg.Database, err)
+		}
+		_ = r.Close()
+	}
+}
+
+func (r *SQLReader) conn() (*mysqlConn, bool, error) {
+	r.mu.Lock()
+	if n := len(r.idle); n > 0 {
+		c := r.idle[n-1]
+		r.idle = r.idle[:n-1]
+		r.mu.Unlock()
+		return c, true, nil
+	}
+	if time.Now().Before(r.downUntil) {
+		r.mu.Unlock()
+		return nil, false, ErrSQLUnavailable
+	}
+	r.mu.Unlock()
+
+	c, err := dialMySQL(r.cfg.Addr, r.cfg.User, r.cfg.Password, r.cfg.Database, r.cfg.Timeout)
+	if err != nil {
+		r.mu.Lock()
+		r.downUntil = time.Now().Add(sqlRetryAfter)
+		r.mu.Unlock()
+		return nil, false, fmt.Errorf("%w: %v", ErrSQLUnavailable, err)
+	}
+	return c, false, nil
+}
+
+func (r *SQLReader) release(c *mysqlConn) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.idle = append(r.idle, c)
+}
+
+// sqlIssueColumns are read from bd's issues table, in scanIssue order.
+const sqlIssueColumns = "i.id, i.title, i.description, i.status, i.priority, i.issue_type, " +
+	"i.assignee, i.created_at, i.created_by, i.updated_at, i.closed_at, " +
+	"i.ephemeral, i.hook_bead, i.agent_state, i.close_reason"
+
+// sqlOpenBlocker matches issues with an unclosed "blocks" dependency.
+const sqlOpenBlocker = "EXISTS (SELECT 1 FROM dependencies d JOIN issues b ON b.id = d.depends_on_id " +
+	"WHERE d.issue_id = i.id AND d.type = 'blocks' AND b.status <> 'closed')"
+
+// List returns issues matching opts, ordered by priority then age.
+func (r *SQLReader) List(opts ListOptions) ([]*Issue, error) {
+	var where []string
+	switch opts.Status {
+	case "":
+		where = append(where, "i.status <> 'closed'")
+	case "all":
+	default:
+		where = append(where, "i.status = "+sqlQuote(opts.Status))
+	}
+	label := opts.Label
+	if label == "" && opts.Type != "" {
+		label = "gt:" + opts.Type
+	}
+	if label != "" {
+		where = append(where, "EXISTS (SELECT 1 FROM labels l WHERE l.issue_id = i.id AND l.label = "+sqlQuote(label)+")")
+	}
+	if opts.Priority >= 0 {
+		where = append(where, "i.priority = "+strconv.Itoa(opts.Priority))
+	}
+	if opts.Parent != "" {
+		where = append(where, "EXISTS (SELECT 1 FROM dependencies p WHERE p.issue_id = i.id AND p.type = 'parent-child' AND p.depends_on_id = "+sqlQuote(opts.Parent)+")")
+	}
+	if opts.Assignee != "" {
+		where = append(where, "i.assignee = "+sqlQuote(opts.Assignee))
+	}
+	if opts.NoAssignee {
+		where = append(where, "(i.assignee IS NULL OR i.assignee = '')")
+	}
+	return r.selectIssues(where)
+}
+
+// Ready returns open, non-ephemeral issues with no open blockers.
+func (r *SQLReader) Ready() ([]*Issue, error) {
+	return r.selectIssues([]string{
+		"i.status = 'open'",
+		"COALESCE(i.ephemeral, 0) = 0",
+		"NOT " + sqlOpenBlocker,
+	})
+}
+
+// Blocked returns unclosed issues with at least one open blocker.
+func (r *SQLReader) Blocked() ([]*Issue, error) {
+	return r.selectIssues([]string{"i.status <> 'closed'", sqlOpenBlocker})
+}
+
+// ListAgentBeads returns unclosed agent beads keyed by ID.
+func (r *SQLReader) ListAgentBeads() (map[string]*Issue, error) {
+	issues, err := r.List(ListOptions{Label: "gt:agent", Priority: -1})
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]*Issue, len(issues))
+	for _, issue := range issues {
+		result[issue.ID] = issue
+	}
+	return result, nil
+}
+
+// Show returns an issue with its dependency details, or ErrNotFound.
+func (r *SQLReader) Show(id string) (*Issue, error) {
+	issues, err := r.ShowMultiple([]string{id})
+	if err != nil {
+		return nil, err
+	}
+	issue, ok := issues[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return issue, nil
+}
+
+// ShowMultiple returns the issues found among ids, with dependency details.
+func (r *SQLReader) ShowMultiple(ids []string) (map[string]*Issue, error) {
+	result := make(map[string]*Issue, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+	issues, err := r.selectIssues([]string{"i.id IN " + sqlList(ids)})
+	if err != nil {
+		return nil, err
+	}
+	for _, issue := range issues {
+		result[issue.ID] = issue
+	}
+	if err := r.attachDependencies(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *SQLReader) selectIssues(where []string) ([]*Issue, error) {
+	q := "SELECT " + sqlIssueColumns + " FROM issues i"
+	if len(where) > 0 {
+		q += " WHERE " + strings.Join(where, " AND ")
+	}
+	q += " ORDER BY i.priority, i.created_at, i.id"
+	res, err := r.query(q)
+	if err != nil {
+		return nil, err
+	}
+
+	issues := make([]*Issue, 0, len(res.Rows))
+	byID := make(map[string]*Issue, len(res.Rows))
+	for _, row := range res.Rows {
+		issue, err := scanIssue(row)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, issue)
+		byID[issue.ID] = issue
+	}
+	if len(issues) == 0 {
+		return issues, nil
+	}
+
+	ids := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		ids = append(ids, issue.ID)
+	}
+	in := sqlList(ids)
+	labels, err := r.query("SELECT issue_id, label FROM labels WHERE issue_id IN " + in + " ORDER BY issue_id, label")
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range labels.Rows {
+		if issue := byID[sqlString(row[0])]; issue != nil {
+			issue.Labels = append(issue.Labels, sqlString(row[1]))
+		}
+	}
+	parents, err := r.query("SELECT issue_id, depends_on_id FROM dependencies WHERE type = 'parent-child' AND issue_id IN " + in)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range parents.Rows {
+		if issue := byID[sqlString(row[0])]; issue != nil {
+			issue.Parent = sqlString(row[1])
+		}
+	}
+	return issues, nil
+}
+
+// attachDependencies fills in Dependencies and Dependents as bd show does.
+func (r *SQLReader) attachDependencies(issues map[string]*Issue) error {
+	if len(issues) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(issues))
+	for id := range issues {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	in := sqlList(ids)
+
+	const depColumns = "o.id, o.title, o.status, o.priority, o.issue_type, d.type"
+	deps, err := r.query("SELECT d.issue_id, " + depColumns + " FROM dependencies d JOIN issues o ON o.id = d.depends_on_id WHERE d.issue_id IN " + in + " ORDER BY d.issue_id, o.id")
+	if err != nil {
+		return err
+	}
+	for _, row := range deps.Rows {
+		if issue := issues[sqlString(row[0])]; issue != nil {
+			issue.Dependencies = append(issue.Dependencies, scanIssueDep(row[1:]))
+		}
+	}
+	dependents, err := r.query("SELECT d.depends_on_id, " + depColumns + " FROM dependencies d JOIN issues o ON o.id = d.issue_id WHERE d.depends_on_id IN " + in + " ORDER BY d.depends_on_id, o.id")
+	if err != nil {
+		return err
+	}
+	for _, row := range dependents.Rows {
+		if issue := issues[sqlString(row[0])]; issue != nil {
+			issue.Dependents = append(issue.Dependents, scanIssueDep(row[1:]))
+		}
+	}
+	return nil
+}
+
+// scanIssue builds an Issue from a row of sqlIssueColumns.
+func scanIssue(row []*string) (*Issue, error) {
+	if len(row) != 15 {
+		return nil, fmt.Errorf("issue row has %d columns, want 15", len(row))
+	}
+	priority, _ := strconv.Atoi(sqlString(row[4]))
+	return &Issue{
+		ID:          sqlString(row[0]),
+		Title:       sqlString(row[1]),
+		Description: sqlString(row[2]),
+		Status:      sqlString(row[3]),
+		Priority:    priority,
+		Type:        sqlString(row[5]),
+		Assignee:    sqlString(row[6]),
+		CreatedAt:   sqlTime(row[7]),
+		CreatedBy:   sqlString(row[8]),
+		UpdatedAt:   sqlTime(row[9]),
+		ClosedAt:    sqlTime(row[10]),
+		Ephemeral:   sqlString(row[11]) == "1",
+		HookBead:    sqlString(row[12]),
+		AgentState:  sqlString(row[13]),
+		CloseReason: sqlString(row[14]),
+	}, nil
+}
+
+func scanIssueDep(row []*string) IssueDep {
+	priority, _ := strconv.Atoi(sqlString(row[3]))
+	return IssueDep{
+		ID:             sqlString(row[0]),
+		Title:          sqlString(row[1]),
+		Status:         sqlString(row[2]),
+		Priority:       priority,
+		Type:           sqlString(row[4]),
+		DependencyType: sqlString(row[5]),
+	}
+}
+
+func sqlString(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
+
+// sqlTime converts a DATETIME value to RFC 3339, as bd's JSON reports it.
+func sqlTime(v *string) string {
+	s := sqlString(v)
+	if s == "" {
+		return ""
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05.999999999", time.RFC3339Nano} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC().Format(time.RFC3339)
+		}
+	}
+	return s
+}
+
+// sqlList renders values as a parenthesized list of string literals.
+func sqlList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = sqlQuote(v)
+	}
+	return "(" + strings.Join(quoted, ", ") + ")"
+}
+
+// sqlReader returns the SQL reader for this wrapper's database, or nil
+// when reads must go through bd.
+func (b *Beads) sqlReader() *SQLReader {
+	if b.isolated {
+		return nil
+	}
+	return SQLReaderFor(b.getResolvedBeadsDir())
+}
+
+// sqlShowMultiple shows ids over SQL, routing each ID to its database by
+// prefix as bd does. ok is false if any database has no reader or a query
+// fails, in which case the caller should ask bd instead.
+func (b *Beads) sqlShowMultiple(ids []string) (issues map[string]*Issue, ok bool) {
+	if b.isolated || !sqlReadEnabled() {
+		return nil, false
+	}
+	fallback := b.getResolvedBeadsDir()
+	townRoot := b.getTownRoot()
+	groups := make(map[string][]string)
+	for _, id := range ids {
+		dir := ResolveRoutingTarget(townRoot, id, fallback)
+		groups[dir] = append(groups[dir], id)
+	}
+
+	issues = make(map[string]*Issue, len(ids))
+	for dir, group := range groups {
+		r := SQLReaderFor(dir)
+		if r == nil {
+			return nil, false
+		}
+		found, err := r.ShowMultiple(group)
+		if err != nil {
+			return nil, false
+		}
+		for id, issue := range found {
+			issues[id] = issue
+		}
+	}
+	return issues, true
+}
+
+// ShowMultipleSQL is ShowMultiple over the SQL read path only, for callers
+// that keep their own bd fallback. ok is false when the SQL path is
+// disabled or unavailable for any of ids; workDir is resolved as for New.
+func ShowMultipleSQL(workDir string, ids []string) (issues map[string]*Issue, ok bool) {
+	if len(ids) == 0 {
+		return make(map[string]*Issue), true
+	}
+	return New(workDir).sqlShowMultiple(ids)
+}
+
+// ListAgentsSQL returns the unclosed agent beads (issue_type "agent") in
+// beadsDir over the SQL read path, matching bd list --type=agent --limit=0.
+// ok is false when the SQL path is disabled or fails.
+func ListAgentsSQL(beadsDir string) (issues []*Issue, ok bool) {
+	r := SQLReaderFor(beadsDir)
+	if r == nil {
+		return nil, false
+	}
+	issues, err := r.selectIssues([]string{"i.issue_type = 'agent'", "i.status <> 'closed'"})
+	if err != nil {
+		return nil, false
+	}
+	return issues, true
+}
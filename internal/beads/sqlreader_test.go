@@ -0,0 +1,423 @@
+package beads
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeMySQL is a minimal MySQL-protocol server that answers queries from a
+// handler, for exercising SQLReader without a Dolt sql-server.
+type fakeMySQL struct {
+	ln       net.Listener
+	password string
+	handler  func(q string) (cols []string, rows [][]*string, err *mysqlError)
+
+	accepted atomic.Int32
+	mu       sync.Mutex
+	queries  []string
+	conns    []net.Conn
+}
+
+func newFakeMySQL(t *testing.T, password string, handler func(string) ([]string, [][]*string, *mysqlError)) *fakeMySQL {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeMySQL{ln: ln, password: password, handler: handler}
+	t.Cleanup(func() {
+		ln.Close()
+		s.dropConns()
+	})
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			s.accepted.Add(1)
+			s.mu.Lock()
+			s.conns = append(s.conns, c)
+			s.mu.Unlock()
+			go s.serve(c)
+		}
+	}()
+	return s
+}
+
+func (s *fakeMySQL) addr() string { return s.ln.Addr().String() }
+
+// dropConns closes every server-side connection, as a server restart would.
+func (s *fakeMySQL) dropConns() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.conns {
+		c.Close()
+	}
+	s.conns = nil
+}
+
+func (s *fakeMySQL) seen() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.queries...)
+}
+
+func (s *fakeMySQL) serve(nc net.Conn) {
+	defer nc.Close()
+	c := &mysqlConn{conn: nc, r: bufio.NewReader(nc)}
+	scramble := []byte("abcdefghijklmnopqrst")
+
+	var hs bytes.Buffer
+	hs.WriteByte(10)
+	hs.WriteString("8.0.33-fake\x00")
+	hs.Write([]byte{1, 0, 0, 0})
+	hs.Write(scramble[:8])
+	hs.WriteByte(0)
+	hs.Write([]byte{0xff, 0xff, mysqlCharsetUTF8MB4, 2, 0, 0xff, 0xff, 21})
+	hs.Write(make([]byte, 10))
+	hs.Write(scramble[8:])
+	hs.WriteByte(0)
+	hs.WriteString("mysql_native_password\x00")
+	if c.writePacket(hs.Bytes()) != nil {
+		return
+	}
+
+	resp, err := c.readPacket()
+	if err != nil || len(resp) < 32 {
+		return
+	}
+	rest := resp[32:]
+	rest = rest[bytes.IndexByte(rest, 0)+1:] // user
+	auth := rest[1 : 1+int(rest[0])]
+	want, _ := mysqlAuthResponse("mysql_native_password", s.password, scramble)
+	if !bytes.Equal(auth, want) {
+		_ = c.writePacket(append([]byte{0xff, 0x15, 0x04}, "#28000Access denied"...))
+		return
+	}
+	if c.writePacket([]byte{0, 0, 0, 2, 0, 0, 0}) != nil {
+		return
+	}
+
+	for {
+		pkt, err := c.readPacket()
+		if err != nil || pkt[0] != mysqlComQuery {
+			return
+		}
+		q := string(pkt[1:])
+		s.mu.Lock()
+		s.queries = append(s.queries, q)
+		s.mu.Unlock()
+
+		cols, rows, qerr := s.handler(q)
+		if qerr != nil {
+			var e bytes.Buffer
+			e.WriteByte(0xff)
+			_ = binary.Write(&e, binary.LittleEndian, qerr.Code)
+			e.WriteString("#42000" + qerr.Message)
+			if c.writePacket(e.Bytes()) != nil {
+				return
+			}
+			continue
+		}
+		if c.writePacket(fakeLenEnc(nil, uint64(len(cols)))) != nil {
+			return
+		}
+		for _, col := range cols {
+			var def []byte
+			for _, part := range []string{"def", "beads", "issues", "issues", col, col} {
+				def = fakeLenEncString(def, part)
+			}
+			def = append(def, 0x0c, 45, 0, 0, 1, 0, 0xfd, 0, 0, 0, 0, 0)
+			if c.writePacket(def) != nil {
+				return
+			}
+		}
+		eof := []byte{0xfe, 0, 0, 2, 0}
+		if c.writePacket(eof) != nil {
+			return
+		}
+		for _, row := range rows {
+			var b []byte
+			for _, v := range row {
+				if v == nil {
+					b = append(b, 0xfb)
+				} else {
+					b = fakeLenEncString(b, *v)
+				}
+			}
+			if c.writePacket(b) != nil {
+				return
+			}
+		}
+		if c.writePacket(eof) != nil {
+			return
+		}
+	}
+}
+
+func fakeLenEnc(b []byte, n uint64) []byte {
+	if n < 251 {
+		return append(b, byte(n))
+	}
+	return append(b, 0xfc, byte(n), byte(n>>8))
+}
+
+func fakeLenEncString(b []byte, s string) []byte {
+	return append(fakeLenEnc(b, uint64(len(s))), s...)
+}
+
+func strs(values ...string) []*string {
+	row := make([]*string, len(values))
+	for i, v := range values {
+		if v != "NULL" {
+			v := v
+			row[i] = &v
+		}
+	}
+	return row
+}
+
+// beadsTables answers SQLReader's queries for a two-issue molecule: gt-1.2
+// is a child of gt-1 and blocked by gt-1.1.
+func beadsTables(q string) ([]string, [][]*string, *mysqlError) {
+	issueCols := strings.Split("id,title,description,status,priority,issue_type,assignee,created_at,created_by,updated_at,closed_at,ephemeral,hook_bead,agent_state,close_reason", ",")
+	switch {
+	case strings.HasPrefix(q, "SELECT i.id"):
+		return issueCols, [][]*string{
+			strs("gt-1.1", "Design", "", "open", "1", "task", "gastown/polecats/nux", "2026-01-02 03:04:05", "mayor", "2026-01-02 04:00:00", "NULL", "0", "NULL", "NULL", ""),
+			strs("gt-1.2", "Build", "it's done", "in_progress", "2", "task", "", "2026-01-03 00:00:00", "mayor", "2026-01-03 00:00:00", "NULL", "NULL", "", "", "NULL"),
+		}, nil
+	case strings.HasPrefix(q, "SELECT issue_id, label"):
+		return []string{"issue_id", "label"}, [][]*string{strs("gt-1.1", "gt:task"), strs("gt-1.2", "gt:task"), strs("gt-1.2", "urgent")}, nil
+	case strings.HasPrefix(q, "SELECT issue_id, depends_on_id"):
+		return []string{"issue_id", "depends_on_id"}, [][]*string{strs("gt-1.1", "gt-1"), strs("gt-1.2", "gt-1")}, nil
+	case strings.Contains(q, "o.id = d.depends_on_id"):
+		return []string{"issue_id", "id", "title", "status", "priority", "issue_type", "type"}, [][]*string{
+			strs("gt-1.2", "gt-1", "Molecule", "open", "2", "epic", "parent-child"),
+			strs("gt-1.2", "gt-1.1", "Design", "open", "1", "task", "blocks"),
+		}, nil
+	case strings.Contains(q, "o.id = d.issue_id"):
+		return []string{"depends_on_id", "id", "title", "status", "priority", "issue_type", "type"}, [][]*string{
+			strs("gt-1.1", "gt-1.2", "Build", "in_progress", "2", "task", "blocks"),
+		}, nil
+	}
+	return nil, nil, &mysqlError{Code: 1064, Message: "unexpected query: " + q}
+}
+
+func TestSQLReader_ListAndShow(t *testing.T) {
+	srv := newFakeMySQL(t, "", beadsTables)
+	r := NewSQLReader(SQLConfig{Addr: srv.addr(), User: "root", Database: "gastown"})
+	defer r.Close()
+
+	issues, err := r.List(ListOptions{Type: "task", Priority: -1, Assignee: "o'brien"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := issueIDs(issues); ids != "gt-1.1,gt-1.2" {
+		t.Fatalf("List = %s", ids)
+	}
+	design, build := issues[0], issues[1]
+	if design.Priority != 1 || design.CreatedAt != "2026-01-02T03:04:05Z" || design.ClosedAt != "" ||
+		design.Parent != "gt-1" || design.Assignee != "gastown/polecats/nux" {
+		t.Errorf("design = %+v", design)
+	}
+	if build.Description != "it's done" || strings.Join(build.Labels, ",") != "gt:task,urgent" || build.Ephemeral {
+		t.Errorf("build = %+v", build)
+	}
+	list := srv.seen()[0]
+	for _, want := range []string{"i.status <> 'closed'", "l.label = 'gt:task'", `i.assignee = 'o\'brien'`} {
+		if !strings.Contains(list, want) {
+			t.Errorf("list query missing %q: %s", want, list)
+		}
+	}
+	if strings.Contains(list, "i.priority =") {
+		t.Errorf("Priority -1 should not filter: %s", list)
+	}
+
+	shown, err := r.ShowMultiple([]string{"gt-1.1", "gt-1.2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deps := shown["gt-1.2"].Dependencies; len(deps) != 2 || deps[1].ID != "gt-1.1" || deps[1].DependencyType != "blocks" {
+		t.Errorf("Dependencies = %+v", deps)
+	}
+	if dependents := shown["gt-1.1"].Dependents; len(dependents) != 1 || dependents[0].ID != "gt-1.2" {
+		t.Errorf("Dependents = %+v", dependents)
+	}
+	if _, err := r.Show("gt-404"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Show(missing) = %v", err)
+	}
+
+	if _, err := r.Ready(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Blocked(); err != nil {
+		t.Fatal(err)
+	}
+	if got := srv.accepted.Load(); got != 1 {
+		t.Errorf("sequential queries opened %d connections, want 1", got)
+	}
+}
+
+func TestSQLReader_Pool(t *testing.T) {
+	fail := &mysqlError{Code: 1146, Message: "table not found"}
+	var broken atomic.Bool
+	srv := newFakeMySQL(t, "secret", func(q string) ([]string, [][]*string, *mysqlError) {
+		if broken.Load() {
+			return nil, nil, fail
+		}
+		return beadsTables(q)
+	})
+	r := NewSQLReader(SQLConfig{Addr: srv.addr(), User: "root", Password: "secret", Database: "hq", MaxConns: 2})
+	defer r.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := r.Ready(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := srv.accepted.Load(); got > 2 {
+		t.Errorf("opened %d connections, MaxConns is 2", got)
+	}
+
+	// A server error leaves the connection in the pool.
+	broken.Store(true)
+	before := srv.accepted.Load()
+	var sqlErr *mysqlError
+	if _, err := r.Ready(); !errors.As(err, &sqlErr) || sqlErr.Code != 1146 {
+		t.Errorf("Ready = %v, want server error", err)
+	}
+	broken.Store(false)
+	if _, err := r.Ready(); err != nil {
+		t.Fatal(err)
+	}
+	if srv.accepted.Load() != before {
+		t.Error("server error should not discard the connection")
+	}
+
+	// Stale pooled connections are replaced transparently.
+	srv.dropConns()
+	if _, err := r.Ready(); err != nil {
+		t.Fatalf("Ready after server restart = %v", err)
+	}
+}
+
+func TestSQLReader_Unavailable(t *testing.T) {
+	srv := newFakeMySQL(t, "secret", beadsTables)
+	r := NewSQLReader(SQLConfig{Addr: srv.addr(), User: "root", Password: "wrong", Timeout: time.Second})
+
+	if _, err := r.Ready(); !errors.Is(err, ErrSQLUnavailable) || !strings.Contains(err.Error(), "Access denied") {
+		t.Fatalf("Ready = %v", err)
+	}
+	if _, err := r.Ready(); !errors.Is(err, ErrSQLUnavailable) {
+		t.Fatalf("Ready = %v", err)
+	}
+	if got := srv.accepted.Load(); got != 1 {
+		t.Errorf("dialed %d times, want 1 while marked down", got)
+	}
+}
+
+func TestSQLConfigFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata string
+		want     string
+	}{
+		{"server", `{"dolt_mode":"server","dolt_database":"gastown"}`, "127.0.0.1:3307/gastown root"},
+		{"custom", `{"dolt_mode":"server","dolt_database":"hq","dolt_server_host":"db","dolt_server_port":3400,"dolt_server_user":"gt"}`, "db:3400/hq gt"},
+		{"embedded", `{"dolt_mode":"embedded","dolt_database":"gastown"}`, ""},
+		{"no database", `{"dolt_mode":"server"}`, ""},
+		{"sqlite", `{"database":"beads.db"}`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "metadata.json"), []byte(tt.metadata), 0644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, ok := SQLConfigFor(dir)
+			got := ""
+			if ok {
+				got = cfg.Addr + "/" + cfg.Database + " " + cfg.User
+			}
+			if got != tt.want {
+				t.Errorf("SQLConfigFor = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSQLReaderFor(t *testing.T) {
+	dir := t.TempDir()
+	meta := `{"dolt_mode":"server","dolt_database":"sqlreaderfor"}`
+	if err := os.WriteFile(filepath.Join(dir, "metadata.json"), []byte(meta), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(SQLReadEnv, "")
+	if SQLReaderFor(dir) != nil {
+		t.Errorf("SQL reads should be off unless %s is set", SQLReadEnv)
+	}
+	t.Setenv(SQLReadEnv, "1")
+	r := SQLReaderFor(dir)
+	if r == nil || SQLReaderFor(dir) != r {
+		t.Error("SQLReaderFor should return a shared reader")
+	}
+	if SQLReaderFor(t.TempDir()) != nil {
+		t.Error("non-server beads dir should have no reader")
+	}
+	if NewIsolated(filepath.Dir(dir)).sqlReader() != nil {
+		t.Error("isolated wrappers should always use bd")
+	}
+}
+
+// BenchmarkShowMultiple compares bd and SQL reads against a real Dolt
+// sql-server. Set GT_BEADS_SQL_BENCH_DIR to a server-mode beads directory
+// and GT_BEADS_SQL_BENCH_IDS to a comma-separated list of issue IDs in it.
+func BenchmarkShowMultiple(b *testing.B) {
+	beadsDir := os.Getenv("GT_BEADS_SQL_BENCH_DIR")
+	ids := strings.Split(os.Getenv("GT_BEADS_SQL_BENCH_IDS"), ",")
+	if beadsDir == "" || ids[0] == "" {
+		b.Skip("GT_BEADS_SQL_BENCH_DIR and GT_BEADS_SQL_BENCH_IDS not set")
+	}
+	workDir := filepath.Dir(beadsDir)
+
+	b.Run("bd", func(b *testing.B) {
+		b.Setenv(SQLReadEnv, "")
+		bd := NewWithBeadsDir(workDir, beadsDir)
+		for i := 0; i < b.N; i++ {
+			if _, err := bd.ShowMultiple(ids); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("sql", func(b *testing.B) {
+		b.Setenv(SQLReadEnv, "1")
+		r := SQLReaderFor(beadsDir)
+		if r == nil {
+			b.Skip("not a server-mode beads directory")
+		}
+		for i := 0; i < b.N; i++ {
+			if _, err := r.ShowMultiple(ids); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
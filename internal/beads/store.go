@@ -0,0 +1,54 @@
+package beads
+
+// Store is the issue storage the orchestrator builds on: listing and
+// showing issues, creating and updating them, closing them, dependencies,
+// agent slots and labels (through UpdateOptions).
+//
+// *Beads implements it by running the bd CLI. MemStore implements it in
+// memory, so logic that only needs a Store can be tested without bd or
+// Dolt installed. Functions that don't need anything bd-specific should
+// accept a Store rather than *Beads.
+type Store interface {
+	// List returns issues matching opts. Like bd list, it omits the
+	// Dependencies and Dependents details; use Show for those.
+	List(opts ListOptions) ([]*Issue, error)
+
+	// Ready returns open issues with no open blocking dependencies.
+	Ready() ([]*Issue, error)
+
+	// Show returns one issue with its dependency details, or ErrNotFound.
+	Show(id string) (*Issue, error)
+
+	// ShowMultiple returns the issues found among ids, keyed by ID.
+	ShowMultiple(ids []string) (map[string]*Issue, error)
+
+	// Create creates an issue with a generated ID.
+	Create(opts CreateOptions) (*Issue, error)
+
+	// CreateWithID creates an issue with the given ID.
+	CreateWithID(id string, opts CreateOptions) (*Issue, error)
+
+	// Update applies opts to an issue.
+	Update(id string, opts UpdateOptions) error
+
+	// Close closes issues.
+	Close(ids ...string) error
+
+	// CloseWithReason closes issues, recording why.
+	CloseWithReason(reason string, ids ...string) error
+
+	// AddDependency records that issue depends on (is blocked by) dependsOn.
+	AddDependency(issue, dependsOn string) error
+
+	// RemoveDependency removes a dependency added with AddDependency.
+	RemoveDependency(issue, dependsOn string) error
+
+	// SetHookBead sets an agent bead's hook slot, replacing any current hook.
+	SetHookBead(agentBeadID, hookBeadID string) error
+
+	// ClearHookBead empties an agent bead's hook slot.
+	ClearHookBead(agentBeadID string) error
+}
+
+// Compile-time check that the bd CLI wrapper satisfies Store.
+var _ Store = (*Beads)(nil)
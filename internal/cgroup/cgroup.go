@@ -0,0 +1,475 @@
+// Package cgroup applies per-session resource limits to agent processes.
+//
+// Limits are enforced either by launching the agent inside a transient
+// systemd user scope (systemd-run --user --scope) or by placing the agent's
+// shell directly into a cgroup v2 subtree that Gas Town manages. Both
+// backends bound memory, CPU weight and task count so that one runaway
+// polecat cannot starve the rest of the machine.
+package cgroup
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Backend identifies how resource limits are applied.
+type Backend string
+
+const (
+	// BackendAuto picks systemd when available, falling back to cgroupfs.
+	BackendAuto Backend = "auto"
+
+	// BackendSystemd wraps the agent in a transient systemd user scope.
+	BackendSystemd Backend = "systemd"
+
+	// BackendCgroupFS writes the agent's shell into a cgroup v2 directory.
+	BackendCgroupFS Backend = "cgroup"
+
+	// BackendNone means no limits are applied.
+	BackendNone Backend = "none"
+)
+
+// OOMKillReason is the session_death reason recorded when the kernel
+// OOM killer terminated a session's agent.
+const OOMKillReason = "oom_kill"
+
+// ErrUnsupported is returned when no usable backend exists on this host.
+var ErrUnsupported = errors.New("cgroup resource limits not supported on this host")
+
+// Paths used to locate cgroup state. Variables so tests can point them at
+// a temporary directory.
+var (
+	// SysFSRoot is the cgroup v2 unified hierarchy mount point.
+	SysFSRoot = "/sys/fs/cgroup"
+
+	// ProcRoot is the proc filesystem mount point.
+	ProcRoot = "/proc"
+
+	// StateDir records the cgroupfs directory of each session, so other
+	// processes (gt log crash, the daemon) find the group its creator made.
+	StateDir = defaultStateDir()
+)
+
+func defaultStateDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "gt-cgroups")
+	}
+	return filepath.Join(home, ".gt", "cgroups")
+}
+
+// Limits describes the resource bounds for one agent session.
+// Zero values mean "no limit" for that resource.
+type Limits struct {
+	// MemoryMax is the hard memory limit in systemd notation (e.g., "4G", "512M").
+	MemoryMax string
+
+	// CPUWeight is the relative CPU weight (1-10000, kernel default 100).
+	CPUWeight int
+
+	// PidsMax is the maximum number of tasks in the session.
+	PidsMax int
+}
+
+// IsZero reports whether no limits are configured.
+func (l Limits) IsZero() bool {
+	return l.MemoryMax == "" && l.CPUWeight == 0 && l.PidsMax == 0
+}
+
+// Validate checks that limit values are well formed.
+func (l Limits) Validate() error {
+	if l.MemoryMax != "" {
+		if _, err := ParseBytes(l.MemoryMax); err != nil {
+			return fmt.Errorf("memory_max: %w", err)
+		}
+	}
+	if l.CPUWeight != 0 && (l.CPUWeight < 1 || l.CPUWeight > 10000) {
+		return fmt.Errorf("cpu_weight %d out of range 1-10000", l.CPUWeight)
+	}
+	if l.PidsMax < 0 {
+		return fmt.Errorf("pids_max %d must not be negative", l.PidsMax)
+	}
+	return nil
+}
+
+// ParseBytes converts a size such as "512M", "4G" or "1073741824" to bytes.
+// Suffixes are binary (K=1024) to match systemd's MemoryMax semantics.
+func ParseBytes(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("empty size")
+	}
+	mult := int64(1)
+	switch suffix := strings.ToUpper(s[len(s)-1:]); suffix {
+	case "K":
+		mult = 1 << 10
+	case "M":
+		mult = 1 << 20
+	case "G":
+		mult = 1 << 30
+	case "T":
+		mult = 1 << 40
+	}
+	if mult != 1 {
+		s = s[:len(s)-1]
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return n * mult, nil
+}
+
+// UnitName returns the systemd scope unit (and cgroupfs directory) name for
+// a tmux session.
+func UnitName(session string) string {
+	return session + ".scope"
+}
+
+// Detect resolves the backend to use. BackendAuto (or "") prefers systemd
+// and falls back to cgroupfs; explicit backends are verified to be usable.
+func Detect(preferred Backend) (Backend, error) {
+	if runtime.GOOS != "linux" {
+		return BackendNone, ErrUnsupported
+	}
+	switch preferred {
+	case BackendSystemd:
+		if !systemdAvailable() {
+			return BackendNone, fmt.Errorf("%w: systemd user manager not reachable", ErrUnsupported)
+		}
+		return BackendSystemd, nil
+	case BackendCgroupFS:
+		if !cgroupV2Available() {
+			return BackendNone, fmt.Errorf("%w: cgroup v2 not mounted at %s", ErrUnsupported, SysFSRoot)
+		}
+		return BackendCgroupFS, nil
+	case BackendNone:
+		return BackendNone, nil
+	case "", BackendAuto:
+		if systemdAvailable() {
+			return BackendSystemd, nil
+		}
+		if cgroupV2Available() {
+			return BackendCgroupFS, nil
+		}
+		return BackendNone, ErrUnsupported
+	default:
+		return BackendNone, fmt.Errorf("unknown cgroup backend %q", preferred)
+	}
+}
+
+// systemdAvailable reports whether systemd-run can start user scopes.
+func systemdAvailable() bool {
+	if _, err := exec.LookPath("systemd-run"); err != nil {
+		return false
+	}
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" {
+		runtimeDir = fmt.Sprintf("/run/user/%d", os.Getuid())
+	}
+	_, err := os.Stat(filepath.Join(runtimeDir, "systemd"))
+	return err == nil
+}
+
+// cgroupV2Available reports whether the unified hierarchy is mounted.
+func cgroupV2Available() bool {
+	_, err := os.Stat(filepath.Join(SysFSRoot, "cgroup.controllers"))
+	return err == nil
+}
+
+// GroupDir returns the cgroupfs directory a new session gets.
+// Sessions live under a "gastown" child of the caller's own cgroup, which is
+// the subtree a systemd user manager delegates to the user. Existing
+// sessions are found with SessionGroupDir.
+func GroupDir(session string) (string, error) {
+	self, err := processCgroup("self")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(SysFSRoot, filepath.Dir(self), "gastown", UnitName(session)), nil
+}
+
+// SessionGroupDir returns the cgroupfs directory recorded when the session
+// started, or false if it has none.
+func SessionGroupDir(session string) (string, bool) {
+	data, err := os.ReadFile(statePath(session)) //nolint:gosec // G304: path is under StateDir
+	if err != nil {
+		return "", false
+	}
+	dir := strings.TrimSpace(string(data))
+	return dir, dir != ""
+}
+
+func statePath(session string) string {
+	return filepath.Join(StateDir, session)
+}
+
+// WrapCommand returns command wrapped so that it runs under limits.
+// The returned string is suitable for tmux.NewSessionWithCommand.
+func WrapCommand(backend Backend, session string, limits Limits, command string) (string, error) {
+	if limits.IsZero() || backend == BackendNone {
+		return command, nil
+	}
+	if err := limits.Validate(); err != nil {
+		return "", err
+	}
+
+	switch backend {
+	case BackendSystemd:
+		args := []string{"systemd-run", "--user", "--scope", "--quiet", "--unit=" + UnitName(session)}
+		for _, p := range systemdProperties(limits) {
+			args = append(args, "-p", p)
+		}
+		args = append(args, "--", "sh", "-c", shellQuote(command))
+		return "exec " + strings.Join(args, " "), nil
+
+	case BackendCgroupFS:
+		dir, err := createGroup(session, limits)
+		if err != nil {
+			return "", err
+		}
+		// Move the pane shell into the group before exec'ing the agent so
+		// every descendant inherits the limits.
+		return fmt.Sprintf("echo $$ > %s && %s", shellQuote(filepath.Join(dir, "cgroup.procs")), command), nil
+
+	default:
+		return "", fmt.Errorf("unknown cgroup backend %q", backend)
+	}
+}
+
+// systemdProperties converts limits to systemd-run -p assignments.
+func systemdProperties(l Limits) []string {
+	var props []string
+	if l.MemoryMax != "" {
+		props = append(props, "MemoryMax="+l.MemoryMax)
+		// Disable swap so the limit is a real bound rather than a slowdown.
+		props = append(props, "MemorySwapMax=0")
+	}
+	if l.CPUWeight > 0 {
+		props = append(props, fmt.Sprintf("CPUWeight=%d", l.CPUWeight))
+	}
+	if l.PidsMax > 0 {
+		props = append(props, fmt.Sprintf("TasksMax=%d", l.PidsMax))
+	}
+	return props
+}
+
+// createGroup creates (or resets) the cgroupfs directory for a session and
+// writes the limit files.
+func createGroup(session string, l Limits) (string, error) {
+	dir, err := GroupDir(session)
+	if err != nil {
+		return "", err
+	}
+	parent := filepath.Dir(dir)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return "", fmt.Errorf("creating cgroup %s: %w", parent, err)
+	}
+	// Enable controllers for children. Best-effort: they may already be
+	// enabled, or delegation may only allow a subset.
+	_ = writeFile(filepath.Join(filepath.Dir(parent), "cgroup.subtree_control"), "+memory +cpu +pids")
+	_ = writeFile(filepath.Join(parent, "cgroup.subtree_control"), "+memory +cpu +pids")
+
+	// A leftover group from a previous run still carries its OOM counters;
+	// remove it so a new session starts with a clean slate.
+	_ = os.Remove(dir)
+	if err := os.Mkdir(dir, 0755); err != nil && !os.IsExist(err) {
+		return "", fmt.Errorf("creating cgroup %s: %w", dir, err)
+	}
+
+	if l.MemoryMax != "" {
+		bytes, _ := ParseBytes(l.MemoryMax)
+		if err := writeFile(filepath.Join(dir, "memory.max"), strconv.FormatInt(bytes, 10)); err != nil {
+			return "", err
+		}
+		_ = writeFile(filepath.Join(dir, "memory.swap.max"), "0")
+	}
+	if l.CPUWeight > 0 {
+		if err := writeFile(filepath.Join(dir, "cpu.weight"), strconv.Itoa(l.CPUWeight)); err != nil {
+			return "", err
+		}
+	}
+	if l.PidsMax > 0 {
+		if err := writeFile(filepath.Join(dir, "pids.max"), strconv.Itoa(l.PidsMax)); err != nil {
+			return "", err
+		}
+	}
+
+	if err := os.MkdirAll(StateDir, 0755); err != nil {
+		return "", fmt.Errorf("creating %s: %w", StateDir, err)
+	}
+	if err := os.WriteFile(statePath(session), []byte(dir+"\n"), 0644); err != nil { //nolint:gosec // G306: not secret
+		return "", fmt.Errorf("recording cgroup for %s: %w", session, err)
+	}
+	return dir, nil
+}
+
+func writeFile(path, value string) error {
+	if err := os.WriteFile(path, []byte(value), 0644); err != nil { //nolint:gosec // G306: cgroup control files
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
+
+// Release clears leftover state for a session so its name can be reused:
+// a failed systemd scope is reset and an empty cgroupfs directory removed.
+// Errors are ignored; there is usually nothing to release.
+func Release(session string) {
+	if systemdAvailable() {
+		_ = exec.Command("systemctl", "--user", "reset-failed", UnitName(session)).Run() //nolint:gosec
+	}
+	if dir, ok := SessionGroupDir(session); ok {
+		_ = os.Remove(dir)
+		_ = os.Remove(statePath(session))
+	}
+}
+
+// OOMKilled reports whether the kernel OOM killer fired inside the session's
+// scope or cgroup. It is meant to be called after the agent exits.
+func OOMKilled(session string) bool {
+	if runtime.GOOS != "linux" {
+		return false
+	}
+	if systemdAvailable() {
+		out, err := exec.Command("systemctl", "--user", "show", UnitName(session), //nolint:gosec
+			"--property=Result", "--value").Output()
+		if err == nil && strings.TrimSpace(string(out)) == "oom-kill" {
+			return true
+		}
+	}
+	if dir, ok := SessionGroupDir(session); ok {
+		if n, ok := readKeyed(filepath.Join(dir, "memory.events"), "oom_kill"); ok && n > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Usage is a point-in-time view of a session's cgroup.
+type Usage struct {
+	// Path is the cgroup path relative to the unified hierarchy root.
+	Path string `json:"path"`
+
+	// MemoryCurrent is current memory usage in bytes.
+	MemoryCurrent int64 `json:"memory_current"`
+
+	// MemoryMax is the memory limit in bytes (0 = unlimited).
+	MemoryMax int64 `json:"memory_max,omitempty"`
+
+	// CPUWeight is the configured CPU weight.
+	CPUWeight int `json:"cpu_weight,omitempty"`
+
+	// PidsCurrent is the current task count.
+	PidsCurrent int `json:"pids_current"`
+
+	// PidsMax is the task limit (0 = unlimited).
+	PidsMax int `json:"pids_max,omitempty"`
+
+	// OOMKills is the number of OOM kills recorded in the group.
+	OOMKills int `json:"oom_kills,omitempty"`
+}
+
+// Limited reports whether any limit is in effect for the group.
+func (u *Usage) Limited() bool {
+	return u.MemoryMax > 0 || u.PidsMax > 0 || (u.CPUWeight > 0 && u.CPUWeight != 100)
+}
+
+// UsageForPID reads resource usage for the cgroup containing pid.
+func UsageForPID(pid int) (*Usage, error) {
+	rel, err := processCgroup(strconv.Itoa(pid))
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(SysFSRoot, rel)
+
+	u := &Usage{Path: rel}
+	u.MemoryCurrent, _ = readInt(filepath.Join(dir, "memory.current"))
+	u.MemoryMax, _ = readInt(filepath.Join(dir, "memory.max"))
+	if w, err := readInt(filepath.Join(dir, "cpu.weight")); err == nil {
+		u.CPUWeight = int(w)
+	}
+	if n, err := readInt(filepath.Join(dir, "pids.current")); err == nil {
+		u.PidsCurrent = int(n)
+	}
+	if n, err := readInt(filepath.Join(dir, "pids.max")); err == nil {
+		u.PidsMax = int(n)
+	}
+	if n, ok := readKeyed(filepath.Join(dir, "memory.events"), "oom_kill"); ok {
+		u.OOMKills = int(n)
+	}
+	return u, nil
+}
+
+// processCgroup returns the unified-hierarchy cgroup path for a process
+// ("self" or a numeric PID) from /proc/<pid>/cgroup.
+func processCgroup(pid string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(ProcRoot, pid, "cgroup"))
+	if err != nil {
+		return "", fmt.Errorf("reading cgroup for %s: %w", pid, err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		// cgroup v2 entries have the form "0::/path".
+		if rest, ok := strings.CutPrefix(line, "0::"); ok {
+			return rest, nil
+		}
+	}
+	return "", fmt.Errorf("%w: no unified cgroup for %s", ErrUnsupported, pid)
+}
+
+// readInt reads a single-integer cgroup file. "max" is reported as 0.
+func readInt(path string) (int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	s := strings.TrimSpace(string(data))
+	if s == "max" {
+		return 0, nil
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
+
+// readKeyed reads a "key value" line from a flat-keyed cgroup file such as
+// memory.events.
+func readKeyed(path, key string) (int64, bool) {
+	f, err := os.Open(path) //nolint:gosec // G304: path is under the cgroup mount
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == key {
+			n, err := strconv.ParseInt(fields[1], 10, 64)
+			return n, err == nil
+		}
+	}
+	return 0, false
+}
+
+// shellQuote wraps s in single quotes for safe use in sh -c.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// FormatBytes renders a byte count compactly (e.g., "1.5G").
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%c", float64(n)/float64(div), "KMGTPE"[exp])
+}
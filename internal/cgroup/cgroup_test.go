@@ -0,0 +1,252 @@
+package cgroup
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeHierarchy points SysFSRoot and ProcRoot at temp dirs and registers
+// the given process cgroup paths. Returns the fake sysfs root.
+func fakeHierarchy(t *testing.T, procs map[string]string) string {
+	t.Helper()
+	sys := t.TempDir()
+	proc := t.TempDir()
+
+	oldSys, oldProc, oldState := SysFSRoot, ProcRoot, StateDir
+	SysFSRoot, ProcRoot, StateDir = sys, proc, t.TempDir()
+	t.Cleanup(func() { SysFSRoot, ProcRoot, StateDir = oldSys, oldProc, oldState })
+
+	for pid, path := range procs {
+		dir := filepath.Join(proc, pid)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "cgroup"), []byte("0::"+path+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Join(sys, path), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return sys
+}
+
+func TestParseBytes(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"1024", 1024, false},
+		{"512K", 512 << 10, false},
+		{"512M", 512 << 20, false},
+		{"4G", 4 << 30, false},
+		{"4g", 4 << 30, false},
+		{"1T", 1 << 40, false},
+		{"", 0, true},
+		{"G", 0, true},
+		{"-1G", 0, true},
+		{"lots", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseBytes(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseBytes(%q) err = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseBytes(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLimitsValidate(t *testing.T) {
+	if err := (Limits{MemoryMax: "2G", CPUWeight: 50, PidsMax: 100}).Validate(); err != nil {
+		t.Errorf("valid limits: %v", err)
+	}
+	if err := (Limits{MemoryMax: "huge"}).Validate(); err == nil {
+		t.Error("expected error for bad memory_max")
+	}
+	if err := (Limits{CPUWeight: 20000}).Validate(); err == nil {
+		t.Error("expected error for cpu_weight out of range")
+	}
+	if err := (Limits{PidsMax: -1}).Validate(); err == nil {
+		t.Error("expected error for negative pids_max")
+	}
+}
+
+func TestWrapCommand_NoLimits(t *testing.T) {
+	cmd := "exec claude"
+	got, err := WrapCommand(BackendSystemd, "gt-rig-Toast", Limits{}, cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != cmd {
+		t.Errorf("got %q, want unchanged %q", got, cmd)
+	}
+
+	got, err = WrapCommand(BackendNone, "gt-rig-Toast", Limits{MemoryMax: "1G"}, cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != cmd {
+		t.Errorf("BackendNone: got %q, want unchanged %q", got, cmd)
+	}
+}
+
+func TestWrapCommand_Systemd(t *testing.T) {
+	got, err := WrapCommand(BackendSystemd, "gt-rig-Toast",
+		Limits{MemoryMax: "4G", CPUWeight: 50, PidsMax: 512},
+		"export GT_ROLE=polecat && exec claude 'it''s'")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"exec systemd-run --user --scope --quiet --unit=gt-rig-Toast.scope",
+		"-p MemoryMax=4G",
+		"-p MemorySwapMax=0",
+		"-p CPUWeight=50",
+		"-p TasksMax=512",
+		`-- sh -c 'export GT_ROLE=polecat && exec claude '\''it'\'''\''s'\'''`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("wrapped command missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestWrapCommand_CgroupFS(t *testing.T) {
+	sys := fakeHierarchy(t, map[string]string{"self": "/user.slice/user@1000.service/app.slice/tmux.scope"})
+
+	got, err := WrapCommand(BackendCgroupFS, "gt-rig-Toast",
+		Limits{MemoryMax: "1G", CPUWeight: 25, PidsMax: 64}, "exec claude")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(sys, "/user.slice/user@1000.service/app.slice/gastown/gt-rig-Toast.scope")
+	wantPrefix := "echo $$ > '" + filepath.Join(dir, "cgroup.procs") + "' && "
+	if !strings.HasPrefix(got, wantPrefix) || !strings.HasSuffix(got, "exec claude") {
+		t.Errorf("wrapped = %q, want prefix %q", got, wantPrefix)
+	}
+
+	for file, want := range map[string]string{
+		"memory.max": "1073741824",
+		"cpu.weight": "25",
+		"pids.max":   "64",
+	} {
+		data, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatalf("reading %s: %v", file, err)
+		}
+		if string(data) != want {
+			t.Errorf("%s = %q, want %q", file, data, want)
+		}
+	}
+}
+
+func TestSessionGroupDir_IndependentOfCaller(t *testing.T) {
+	sys := fakeHierarchy(t, map[string]string{"self": "/user.slice/user@1000.service/app.slice/tmux.scope"})
+	if _, err := WrapCommand(BackendCgroupFS, "gt-rig-Toast", Limits{MemoryMax: "1G"}, "exec claude"); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(sys, "/user.slice/user@1000.service/app.slice/gastown/gt-rig-Toast.scope")
+	if err := os.WriteFile(filepath.Join(dir, "memory.events"), []byte("oom 1\noom_kill 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// gt log crash runs in a different cgroup than the process that
+	// started the session.
+	if err := os.WriteFile(filepath.Join(ProcRoot, "self", "cgroup"), []byte("0::/user.slice/user@1000.service/app.slice/gt-daemon.scope\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := SessionGroupDir("gt-rig-Toast"); !ok || got != dir {
+		t.Errorf("SessionGroupDir = %q, %v; want %q", got, ok, dir)
+	}
+	if !OOMKilled("gt-rig-Toast") {
+		t.Error("OOMKilled = false, want true")
+	}
+
+	if err := os.Remove(filepath.Join(dir, "memory.events")); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{"memory.max", "memory.swap.max"} {
+		_ = os.Remove(filepath.Join(dir, f))
+	}
+	Release("gt-rig-Toast")
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("group dir after Release: %v, want removed", err)
+	}
+	if _, ok := SessionGroupDir("gt-rig-Toast"); ok {
+		t.Error("SessionGroupDir still recorded after Release")
+	}
+}
+
+func TestUsageForPID(t *testing.T) {
+	sys := fakeHierarchy(t, map[string]string{"4242": "/gastown/gt-rig-Toast.scope"})
+	dir := filepath.Join(sys, "gastown", "gt-rig-Toast.scope")
+	files := map[string]string{
+		"memory.current": "1048576\n",
+		"memory.max":     "2147483648\n",
+		"cpu.weight":     "50\n",
+		"pids.current":   "12\n",
+		"pids.max":       "max\n",
+		"memory.events":  "low 0\nhigh 0\nmax 3\noom 1\noom_kill 1\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	u, err := UsageForPID(4242)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Usage{
+		Path:          "/gastown/gt-rig-Toast.scope",
+		MemoryCurrent: 1 << 20,
+		MemoryMax:     2 << 30,
+		CPUWeight:     50,
+		PidsCurrent:   12,
+		PidsMax:       0,
+		OOMKills:      1,
+	}
+	if *u != want {
+		t.Errorf("UsageForPID = %+v, want %+v", *u, want)
+	}
+	if !u.Limited() {
+		t.Error("Limited() = false, want true")
+	}
+}
+
+func TestUsageForPID_Unlimited(t *testing.T) {
+	sys := fakeHierarchy(t, map[string]string{"7": "/user.slice"})
+	if err := os.WriteFile(filepath.Join(sys, "user.slice", "memory.max"), []byte("max\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	u, err := UsageForPID(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Limited() {
+		t.Errorf("Limited() = true for unlimited group: %+v", u)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{
+		512:     "512B",
+		2048:    "2.0K",
+		1 << 20: "1.0M",
+		3 << 29: "1.5G",
+	}
+	for in, want := range tests {
+		if got := FormatBytes(in); got != want {
+			t.Errorf("FormatBytes(%d) = %q, want %q", in, got, want)
+		}
+	}
+}
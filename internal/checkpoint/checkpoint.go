@@ -38,6 +38,10 @@ type Checkpoint struct {
 	// Branch is the current git branch.
 	Branch string `json:"branch,omitempty"`
 
+	// SnapshotRef is the git ref holding a snapshot of the uncommitted
+	// work (see Snapshot), empty if there was none.
+	SnapshotRef string `json:"snapshot_ref,omitempty"`
+
 	// HookedBead is the bead ID on the agent's hook.
 	HookedBead string `json:"hooked_bead,omitempty"`
 
@@ -210,6 +214,10 @@ func (cp *Checkpoint) Summary() string {
 		parts = append(parts, fmt.Sprintf("branch: %s", cp.Branch))
 	}
 
+	if cp.SnapshotRef != "" {
+		parts = append(parts, "WIP snapshot saved")
+	}
+
 	if len(parts) == 0 {
 		return "no significant state"
 	}
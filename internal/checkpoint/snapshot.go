@@ -0,0 +1,252 @@
+package checkpoint
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RefPrefix is the namespace for WIP snapshot refs. Refs under refs/ are
+// shared by every worktree of a repository, so a snapshot survives the
+// worktree that took it.
+const RefPrefix = "refs/gt/checkpoints/"
+
+// Snapshot retention defaults for PruneSnapshots.
+const (
+	DefaultSnapshotKeep   = 5
+	DefaultSnapshotMaxAge = 7 * 24 * time.Hour
+)
+
+// refTimeLayout is the timestamp component of a snapshot ref.
+const refTimeLayout = "20060102T150405Z"
+
+// SnapshotRef describes a WIP snapshot.
+type SnapshotRef struct {
+	Ref    string    `json:"ref"`
+	Commit string    `json:"commit"`
+	Agent  string    `json:"agent"`
+	Time   time.Time `json:"time"`
+}
+
+// Snapshot records the uncommitted and untracked (but not ignored) changes
+// in workDir as a commit on top of HEAD, stored at
+// refs/gt/checkpoints/<agent>/<timestamp>. The index, branch and working
+// tree are left untouched. Returns "" if there is nothing to snapshot.
+//
+// Older snapshots for the agent beyond DefaultSnapshotKeep are deleted.
+func Snapshot(workDir, agent string, at time.Time) (string, error) {
+	head, err := gitOutput(workDir, nil, "rev-parse", "--verify", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("resolving HEAD: %w", err)
+	}
+
+	// Stage everything into a throwaway index so the real one is untouched.
+	tmpDir, err := os.MkdirTemp("", "gt-checkpoint-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmpDir)
+	env := []string{"GIT_INDEX_FILE=" + filepath.Join(tmpDir, "index")}
+
+	if _, err := gitOutput(workDir, env, "read-tree", head); err != nil {
+		return "", err
+	}
+	if _, err := gitOutput(workDir, env, "add", "--all", "--", ":/"); err != nil {
+		return "", err
+	}
+	if _, err := gitOutput(workDir, env, "rm", "--cached", "--quiet", "--ignore-unmatch", "--", Filename); err != nil {
+		return "", err
+	}
+	tree, err := gitOutput(workDir, env, "write-tree")
+	if err != nil {
+		return "", err
+	}
+	headTree, err := gitOutput(workDir, nil, "rev-parse", head+"^{tree}")
+	if err != nil {
+		return "", err
+	}
+	if tree == headTree {
+		return "", nil
+	}
+
+	agent = sanitizeRefComponent(agent)
+	stamp := at.UTC().Format(refTimeLayout)
+	identity := []string{
+		"GIT_AUTHOR_NAME=gt checkpoint", "GIT_AUTHOR_EMAIL=checkpoint@gastown.local",
+		"GIT_COMMITTER_NAME=gt checkpoint", "GIT_COMMITTER_EMAIL=checkpoint@gastown.local",
+	}
+	commit, err := gitOutput(workDir, identity, "commit-tree", tree, "-p", head,
+		"-m", fmt.Sprintf("WIP checkpoint for %s at %s", agent, stamp))
+	if err != nil {
+		return "", err
+	}
+
+	ref := RefPrefix + agent + "/" + stamp
+	if _, err := gitOutput(workDir, nil, "update-ref", ref, commit); err != nil {
+		return "", err
+	}
+
+	// Best-effort: bound per-agent snapshots.
+	if snaps, err := ListSnapshots(workDir, agent); err == nil {
+		for _, s := range snaps[min(len(snaps), DefaultSnapshotKeep):] {
+			_, _ = gitOutput(workDir, nil, "update-ref", "-d", s.Ref)
+		}
+	}
+	return ref, nil
+}
+
+// Restore reapplies a snapshot's changes to workDir's working tree. The
+// changes are applied relative to the commit the snapshot was taken on, so
+// they can land on a different HEAD; if they don't apply cleanly a 3-way
+// merge is attempted, which may leave conflict markers and staged files.
+func Restore(workDir, ref string) error {
+	commit, err := gitOutput(workDir, nil, "rev-parse", "--verify", ref+"^{commit}")
+	if err != nil {
+		return fmt.Errorf("snapshot %s not found: %w", ref, err)
+	}
+	patch, err := gitBytes(workDir, nil, nil, "diff", "--binary", commit+"^", commit)
+	if err != nil {
+		return err
+	}
+	if len(patch) == 0 {
+		return nil
+	}
+	if _, err := gitBytes(workDir, nil, patch, "apply", "--whitespace=nowarn"); err == nil {
+		return nil
+	}
+	if _, err := gitBytes(workDir, nil, patch, "apply", "--3way", "--whitespace=nowarn"); err != nil {
+		return fmt.Errorf("applying snapshot %s: %w", ref, err)
+	}
+	return nil
+}
+
+// ListSnapshots returns an agent's snapshots newest first, or every
+// agent's when agent is empty.
+func ListSnapshots(workDir, agent string) ([]SnapshotRef, error) {
+	pattern := strings.TrimSuffix(RefPrefix, "/")
+	if agent != "" {
+		pattern = RefPrefix + sanitizeRefComponent(agent)
+	}
+	out, err := gitOutput(workDir, nil, "for-each-ref", "--format=%(refname) %(objectname)", pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var snaps []SnapshotRef
+	for _, line := range strings.Split(out, "\n") {
+		ref, commit, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		name, stamp, ok := strings.Cut(strings.TrimPrefix(ref, RefPrefix), "/")
+		if !ok {
+			continue
+		}
+		at, err := time.Parse(refTimeLayout, stamp)
+		if err != nil {
+			continue
+		}
+		snaps = append(snaps, SnapshotRef{Ref: ref, Commit: commit, Agent: name, Time: at})
+	}
+	sort.SliceStable(snaps, func(i, j int) bool { return snaps[i].Time.After(snaps[j].Time) })
+	return snaps, nil
+}
+
+// LatestSnapshot returns the agent's newest snapshot, or nil if it has none.
+func LatestSnapshot(workDir, agent string) (*SnapshotRef, error) {
+	snaps, err := ListSnapshots(workDir, agent)
+	if err != nil || len(snaps) == 0 {
+		return nil, err
+	}
+	return &snaps[0], nil
+}
+
+// PruneSnapshots deletes snapshots beyond the newest keep per agent and
+// any older than maxAge (0 = no age limit). With dryRun nothing is
+// deleted. Returns the refs that were (or would be) removed.
+func PruneSnapshots(workDir string, keep int, maxAge time.Duration, now time.Time, dryRun bool) ([]SnapshotRef, error) {
+	snaps, err := ListSnapshots(workDir, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []SnapshotRef
+	perAgent := make(map[string]int)
+	for _, s := range snaps {
+		perAgent[s.Agent]++
+		if perAgent[s.Agent] <= keep && (maxAge <= 0 || now.Sub(s.Time) <= maxAge) {
+			continue
+		}
+		if !dryRun {
+			if _, err := gitOutput(workDir, nil, "update-ref", "-d", s.Ref); err != nil {
+				return removed, err
+			}
+		}
+		removed = append(removed, s)
+	}
+	return removed, nil
+}
+
+// AgentName is the agent component of a worker's snapshot refs.
+func AgentName(rig, worker string) string {
+	if rig == "" {
+		return worker
+	}
+	return rig + "-" + worker
+}
+
+// WithSnapshot snapshots workDir's uncommitted work for agent and records
+// the ref on the checkpoint.
+func (cp *Checkpoint) WithSnapshot(workDir, agent string) error {
+	at := cp.Timestamp
+	if at.IsZero() {
+		at = time.Now()
+	}
+	ref, err := Snapshot(workDir, agent, at)
+	if err != nil {
+		return err
+	}
+	cp.SnapshotRef = ref
+	return nil
+}
+
+var invalidRefChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// sanitizeRefComponent makes s usable as a single ref path component.
+func sanitizeRefComponent(s string) string {
+	s = strings.Trim(invalidRefChars.ReplaceAllString(s, "-"), ".-")
+	s = strings.ReplaceAll(s, "..", ".")
+	if s == "" {
+		return "unknown"
+	}
+	return strings.TrimSuffix(s, ".lock")
+}
+
+func gitOutput(dir string, env []string, args ...string) (string, error) {
+	out, err := gitBytes(dir, env, nil, args...)
+	return strings.TrimSpace(string(out)), err
+}
+
+func gitBytes(dir string, env []string, stdin []byte, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
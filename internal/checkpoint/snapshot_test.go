@@ -0,0 +1,155 @@
+package checkpoint
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newRepo creates a repo with one commit of a.txt and .gitignore.
+func newRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q", "-b", "main")
+	writeFile(t, filepath.Join(dir, "a.txt"), "one\n")
+	writeFile(t, filepath.Join(dir, ".gitignore"), "*.log\n")
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-q", "-m", "init")
+	return dir
+}
+
+func TestSnapshot_RoundTrip(t *testing.T) {
+	repo := newRepo(t)
+	at := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+
+	if ref, err := Snapshot(repo, "nux", at); err != nil || ref != "" {
+		t.Fatalf("clean Snapshot = %q, %v; want no ref", ref, err)
+	}
+
+	writeFile(t, filepath.Join(repo, "a.txt"), "one\ntwo\n")
+	writeFile(t, filepath.Join(repo, "new.go"), "package x\n")
+	writeFile(t, filepath.Join(repo, "debug.log"), "ignored\n")
+	writeFile(t, filepath.Join(repo, Filename), "{}")
+	runGit(t, repo, "add", "a.txt")
+	statusBefore := runGit(t, repo, "status", "--porcelain")
+
+	ref, err := Snapshot(repo, "gastown/nux", at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref != RefPrefix+"gastown-nux/20261018T093000Z" {
+		t.Errorf("ref = %q", ref)
+	}
+	if got := runGit(t, repo, "status", "--porcelain"); got != statusBefore {
+		t.Errorf("status changed:\n%s\nwant\n%s", got, statusBefore)
+	}
+	if branch := runGit(t, repo, "rev-parse", "--abbrev-ref", "HEAD"); branch != "main" {
+		t.Errorf("branch = %s", branch)
+	}
+	files := runGit(t, repo, "ls-tree", "--name-only", ref)
+	if files != ".gitignore\na.txt\nnew.go" {
+		t.Errorf("snapshot files = %q", files)
+	}
+
+	// Restore into a fresh worktree of the same repo.
+	wt := filepath.Join(t.TempDir(), "wt")
+	runGit(t, repo, "worktree", "add", "-q", "-b", "repair", wt, "main")
+	if err := Restore(wt, ref); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(wt, "a.txt")); string(data) != "one\ntwo\n" {
+		t.Errorf("a.txt = %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(wt, "new.go")); err != nil {
+		t.Errorf("untracked file not restored: %v", err)
+	}
+	if staged := runGit(t, wt, "diff", "--cached", "--name-only"); staged != "" {
+		t.Errorf("restore should not stage files, staged %q", staged)
+	}
+}
+
+func TestSnapshot_ListAndPrune(t *testing.T) {
+	repo := newRepo(t)
+	writeFile(t, filepath.Join(repo, "a.txt"), "wip\n")
+	base := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < DefaultSnapshotKeep+2; i++ {
+		if _, err := Snapshot(repo, "nux", base.Add(time.Duration(i)*time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := Snapshot(repo, "toast", base.Add(-30*24*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	nux, err := ListSnapshots(repo, "nux")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nux) != DefaultSnapshotKeep || !nux[0].Time.Equal(base.Add(time.Duration(DefaultSnapshotKeep+1)*time.Hour)) {
+		t.Errorf("nux snapshots = %+v", nux)
+	}
+	latest, _ := LatestSnapshot(repo, "toast")
+	if latest == nil || latest.Agent != "toast" {
+		t.Errorf("LatestSnapshot = %+v", latest)
+	}
+
+	now := base.Add(24 * time.Hour)
+	removed, err := PruneSnapshots(repo, 2, 7*24*time.Hour, now, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// nux keeps its newest 2; toast's only snapshot is past maxAge.
+	if want := DefaultSnapshotKeep - 2 + 1; len(removed) != want {
+		t.Errorf("dry run would remove %d, want %d", len(removed), want)
+	}
+	if all, _ := ListSnapshots(repo, ""); len(all) != DefaultSnapshotKeep+1 {
+		t.Errorf("dry run removed refs: %d left", len(all))
+	}
+	if _, err := PruneSnapshots(repo, 2, 7*24*time.Hour, now, false); err != nil {
+		t.Fatal(err)
+	}
+	if all, _ := ListSnapshots(repo, ""); len(all) != 2 || all[0].Agent != "nux" {
+		t.Errorf("after prune = %+v", all)
+	}
+}
+
+func TestSanitizeRefComponent(t *testing.T) {
+	tests := map[string]string{
+		"nux":             "nux",
+		"gastown/nux":     "gastown-nux",
+		"..evil..":        "evil",
+		"a..b":            "a.b",
+		"name.lock":       "name",
+		"":                "unknown",
+		"crew max (test)": "crew-max-test",
+	}
+	for in, want := range tests {
+		if got := sanitizeRefComponent(in); got != want {
+			t.Errorf("sanitizeRefComponent(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/eventbus"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var (
+	activityFollowTypes []string
+	activityFollowActor string
+	activityFollowAudit bool
+	activityFollowJSON  bool
+)
+
+var activityFollowCmd = &cobra.Command{
+	Use:   "follow",
+	Short: "Stream activity events as they happen",
+	Long: `Subscribe to the town's event bus and print events as they arrive.
+
+Events come from the daemon's event socket (daemon/events.sock), which
+carries every event in the town regardless of which process emitted it.
+If the daemon is not running, the activity log is tailed directly.
+
+With --json each event is printed as one JSON line in the event bus
+schema, suitable for plugins and scripts.
+
+Examples:
+  gt activity follow
+  gt activity follow --type done --type merged
+  gt activity follow --actor gastown/ --audit --json`,
+	Args: cobra.NoArgs,
+	RunE: runActivityFollow,
+}
+
+func init() {
+	activityFollowCmd.Flags().StringSliceVar(&activityFollowTypes, "type", nil, "Only show these event types (repeatable)")
+	activityFollowCmd.Flags().StringVar(&activityFollowActor, "actor", "", "Only show events whose actor or agent starts with this prefix")
+	activityFollowCmd.Flags().BoolVar(&activityFollowAudit, "audit", false, "Include audit-only events")
+	activityFollowCmd.Flags().BoolVar(&activityFollowJSON, "json", false, "Output one JSON event per line")
+
+	activityCmd.AddCommand(activityFollowCmd)
+}
+
+func runActivityFollow(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	filter := eventbus.Filter{
+		Types: activityFollowTypes,
+		Actor: activityFollowActor,
+		Audit: activityFollowAudit,
+	}
+
+	stream, err := eventbus.Dial(townRoot, filter)
+	if errors.Is(err, eventbus.ErrUnavailable) {
+		if !activityFollowJSON {
+			fmt.Fprintln(os.Stderr, style.Dim.Render("Daemon not running; tailing the activity log"))
+		}
+		return followActivityLog(townRoot, filter)
+	}
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	// Close the stream on Ctrl-C so Next returns.
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt)
+	defer signal.Stop(sigCh)
+	go func() {
+		<-sigCh
+		_ = stream.Close()
+	}()
+
+	for {
+		e, err := stream.Next()
+		if err != nil {
+			return nil // Daemon stopped or interrupted
+		}
+		printBusEvent(e)
+	}
+}
+
+// followActivityLog polls the activity log when the daemon is down.
+func followActivityLog(townRoot string, filter eventbus.Filter) error {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt)
+	defer signal.Stop(sigCh)
+
+	tailer := eventbus.NewTailer(townRoot, true)
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-sigCh:
+			return nil
+		case <-ticker.C:
+			evs, err := tailer.Poll()
+			if err != nil {
+				return err
+			}
+			for _, e := range evs {
+				if filter.Match(e) {
+					printBusEvent(e)
+				}
+			}
+		}
+	}
+}
+
+// printBusEvent prints one event as JSON or a human-readable line.
+func printBusEvent(e eventbus.Event) {
+	if activityFollowJSON {
+		data, err := json.Marshal(e)
+		if err == nil {
+			fmt.Println(string(data))
+		}
+		return
+	}
+
+	ts := e.Time().Local().Format("15:04:05")
+	line := fmt.Sprintf("%s %s %s", style.Dim.Render(ts), style.Bold.Render("["+e.Type+"]"), e.Actor)
+	if e.Agent != "" && e.Agent != e.Actor {
+		line += " → " + e.Agent
+	}
+	if detail := busEventDetail(e); detail != "" {
+		line += ": " + detail
+	}
+	fmt.Println(line)
+}
+
+// busEventDetail summarizes an event's context or payload on one line.
+func busEventDetail(e eventbus.Event) string {
+	if e.Context != "" {
+		return e.Context
+	}
+	keys := make([]string, 0, len(e.Payload))
+	for k := range e.Payload {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if s := fmt.Sprint(e.Payload[k]); s != "" {
+			parts = append(parts, k+"="+s)
+		}
+	}
+	return truncateStr(strings.Join(parts, " "), 120)
+}
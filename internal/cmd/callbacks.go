@@ -481,6 +481,5 @@ func handleSling(townRoot string, msg *mail.Message, dryRun bool) (string, error
 
 // logCallback logs a callback processing event to the town log.
 func logCallback(townRoot, context string) {
-	logger := townlog.NewLogger(townRoot)
-	_ = logger.Log(townlog.EventCallback, "mayor/", context)
+	_ = LogEventWithRoot(townRoot, townlog.EventCallback, "mayor/", context)
 }
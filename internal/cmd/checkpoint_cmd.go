@@ -4,10 +4,12 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/checkpoint"
+	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
@@ -26,9 +28,12 @@ Checkpoint data includes:
 - Hooked bead
 - Modified files list
 - Git branch and last commit
+- A snapshot of uncommitted and untracked work
 - Timestamp
 
-Checkpoints are stored in .polecat-checkpoint.json in the polecat directory.`,
+Checkpoints are stored in .polecat-checkpoint.json in the polecat directory.
+Work snapshots are commits stored under refs/gt/checkpoints/<agent>/ in the
+shared repository, so they survive the worktree being nuked.`,
 }
 
 var checkpointWriteCmd = &cobra.Command{
@@ -41,7 +46,9 @@ This is typically called:
 - Periodically during long work sessions
 - Before handoff to another session
 
-The checkpoint captures git state, molecule progress, and hooked work.`,
+The checkpoint captures git state, molecule progress, and hooked work.
+Uncommitted and untracked changes are snapshotted to a hidden ref without
+touching the index or branch.`,
 	RunE: runCheckpointWrite,
 }
 
@@ -52,6 +59,29 @@ var checkpointReadCmd = &cobra.Command{
 	RunE:  runCheckpointRead,
 }
 
+var checkpointRestoreCmd = &cobra.Command{
+	Use:   "restore [ref]",
+	Short: "Reapply a checkpoint's work snapshot to the worktree",
+	Long: `Reapply the uncommitted work saved in a checkpoint snapshot.
+
+Without an argument, restores the snapshot recorded in the checkpoint file,
+or the agent's latest snapshot if there is no checkpoint file (e.g. in a
+repaired worktree). Changes are applied to the working tree only; if they
+don't apply cleanly a 3-way merge is attempted.
+
+Refuses to run on a worktree with uncommitted changes unless --force.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runCheckpointRestore,
+}
+
+var checkpointGCCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Delete old work snapshots",
+	Long: `Delete work snapshot refs beyond the newest --keep per agent, or older
+than --max-age.`,
+	RunE: runCheckpointGC,
+}
+
 var checkpointClearCmd = &cobra.Command{
 	Use:   "clear",
 	Short: "Clear the checkpoint file",
@@ -63,12 +93,19 @@ var (
 	checkpointNotes    string
 	checkpointMolecule string
 	checkpointStep     string
+
+	checkpointRestoreForce bool
+	checkpointGCKeep       int
+	checkpointGCMaxAge     string
+	checkpointGCDryRun     bool
 )
 
 func init() {
 	checkpointCmd.AddCommand(checkpointWriteCmd)
 	checkpointCmd.AddCommand(checkpointReadCmd)
 	checkpointCmd.AddCommand(checkpointClearCmd)
+	checkpointCmd.AddCommand(checkpointRestoreCmd)
+	checkpointCmd.AddCommand(checkpointGCCmd)
 
 	checkpointWriteCmd.Flags().StringVar(&checkpointNotes, "notes", "",
 		"Add notes to the checkpoint")
@@ -76,6 +113,14 @@ func init() {
 		"Override molecule ID (auto-detected if not specified)")
 	checkpointWriteCmd.Flags().StringVar(&checkpointStep, "step", "",
 		"Override step ID (auto-detected if not specified)")
+	checkpointRestoreCmd.Flags().BoolVar(&checkpointRestoreForce, "force", false,
+		"Restore even if the worktree has uncommitted changes")
+	checkpointGCCmd.Flags().IntVar(&checkpointGCKeep, "keep", checkpoint.DefaultSnapshotKeep,
+		"Snapshots to keep per agent")
+	checkpointGCCmd.Flags().StringVar(&checkpointGCMaxAge, "max-age", "7d",
+		"Delete snapshots older than this (0 for no limit)")
+	checkpointGCCmd.Flags().BoolVar(&checkpointGCDryRun, "dry-run", false,
+		"Show what would be deleted")
 
 	rootCmd.AddCommand(checkpointCmd)
 }
@@ -140,6 +185,11 @@ func runCheckpointWrite(cmd *cobra.Command, args []string) error {
 		cp.WithHookedBead(hookedBead)
 	}
 
+	// Snapshot uncommitted work (non-fatal: the checkpoint is still useful)
+	if err := cp.WithSnapshot(cwd, checkpointAgent(roleInfo)); err != nil {
+		fmt.Printf("%s Could not snapshot uncommitted work: %v\n", style.Warning.Render("⚠"), err)
+	}
+
 	// Write checkpoint
 	if err := checkpoint.Write(cwd, cp); err != nil {
 		return fmt.Errorf("writing checkpoint: %w", err)
@@ -188,6 +238,9 @@ func runCheckpointRead(cmd *cobra.Command, args []string) error {
 	if cp.LastCommit != "" {
 		fmt.Printf("Last Commit: %s\n", cp.LastCommit[:min(12, len(cp.LastCommit))])
 	}
+	if cp.SnapshotRef != "" {
+		fmt.Printf("Work Snapshot: %s\n", cp.SnapshotRef)
+	}
 	if len(cp.ModifiedFiles) > 0 {
 		fmt.Printf("Modified Files: %d\n", len(cp.ModifiedFiles))
 		for _, f := range cp.ModifiedFiles {
@@ -218,6 +271,103 @@ func runCheckpointClear(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func runCheckpointRestore(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting current directory: %w", err)
+	}
+
+	ref := ""
+	if len(args) > 0 {
+		ref = args[0]
+	} else {
+		ref, err = defaultSnapshotRef(cwd)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !checkpointRestoreForce {
+		status, err := git.NewGit(cwd).CheckUncommittedWork()
+		if err == nil && !status.Clean() {
+			return fmt.Errorf("worktree has uncommitted changes (%s); use --force to restore anyway", status.String())
+		}
+	}
+
+	if err := checkpoint.Restore(cwd, ref); err != nil {
+		return err
+	}
+	fmt.Printf("%s Restored work snapshot %s\n", style.Bold.Render("✓"), ref)
+	return nil
+}
+
+// defaultSnapshotRef returns the snapshot recorded in the checkpoint file,
+// falling back to the current agent's latest snapshot.
+func defaultSnapshotRef(workDir string) (string, error) {
+	cp, err := checkpoint.Read(workDir)
+	if err != nil {
+		return "", fmt.Errorf("reading checkpoint: %w", err)
+	}
+	if cp != nil && cp.SnapshotRef != "" {
+		return cp.SnapshotRef, nil
+	}
+
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil || townRoot == "" {
+		return "", fmt.Errorf("no checkpoint snapshot found")
+	}
+	roleInfo, err := GetRoleWithContext(workDir, townRoot)
+	if err != nil {
+		return "", fmt.Errorf("detecting role: %w", err)
+	}
+	latest, err := checkpoint.LatestSnapshot(workDir, checkpointAgent(roleInfo))
+	if err != nil {
+		return "", fmt.Errorf("listing snapshots: %w", err)
+	}
+	if latest == nil {
+		return "", fmt.Errorf("no checkpoint snapshot found")
+	}
+	return latest.Ref, nil
+}
+
+func runCheckpointGC(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting current directory: %w", err)
+	}
+	maxAge, err := krcParseDuration(checkpointGCMaxAge)
+	if err != nil {
+		return fmt.Errorf("invalid --max-age %q: %w", checkpointGCMaxAge, err)
+	}
+
+	removed, err := checkpoint.PruneSnapshots(cwd, checkpointGCKeep, maxAge, time.Now(), checkpointGCDryRun)
+	if err != nil {
+		return fmt.Errorf("pruning snapshots: %w", err)
+	}
+	if len(removed) == 0 {
+		fmt.Printf("%s No snapshots to delete\n", style.Dim.Render("○"))
+		return nil
+	}
+
+	verb := "Deleted"
+	if checkpointGCDryRun {
+		verb = "Would delete"
+	}
+	fmt.Printf("%s %d snapshot(s):\n", verb, len(removed))
+	for _, s := range removed {
+		fmt.Printf("  %s\n", s.Ref)
+	}
+	return nil
+}
+
+// checkpointAgent names the current worker in snapshot refs.
+func checkpointAgent(info RoleInfo) string {
+	if info.Polecat != "" {
+		return checkpoint.AgentName(info.Rig, info.Polecat)
+	}
+	return string(info.Role)
+}
+
 // detectMoleculeContext tries to detect the current molecule and step from beads.
 func detectMoleculeContext(workDir string, ctx RoleInfo) (moleculeID, stepID, stepTitle string) {
 	b := beads.New(workDir)
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/ledger"
 )
 
 var closeCmd = &cobra.Command{
@@ -15,7 +16,8 @@ var closeCmd = &cobra.Command{
 	Long: `Close one or more beads (wrapper for 'bd close').
 
 This is a convenience command that passes through to 'bd close' with
-all arguments and flags preserved.
+all arguments and flags preserved. Closed work is then recorded in the
+ledger (see 'gt ledger').
 
 Examples:
   gt close gt-abc              # Close bead gt-abc
@@ -55,5 +57,10 @@ func runClose(cmd *cobra.Command, args []string) error {
 	bdCmd.Stdin = os.Stdin
 	bdCmd.Stdout = os.Stdout
 	bdCmd.Stderr = os.Stderr
-	return bdCmd.Run()
+	if err := bdCmd.Run(); err != nil {
+		return err
+	}
+
+	exportToLedger(closeArgBeadIDs(convertedArgs), ledger.TriggerClose)
+	return nil
 }
@@ -17,6 +17,8 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/ledger"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tui/convoy"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -603,6 +605,8 @@ func checkSingleConvoy(townBeads, convoyID string, dryRun bool) error {
 	}
 
 	fmt.Printf("%s Auto-closed convoy 🚚 %s: %s\n", style.Bold.Render("✓"), convoyID, convoy.Title)
+	logConvoyClosed(convoyID, convoy.Title, "All tracked issues completed")
+	traceConvoyClosed(townBeads, convoyID, "All tracked issues completed", tracked)
 
 	// Send completion notification
 	notifyConvoyCompletion(townBeads, convoyID, convoy.Title)
@@ -673,6 +677,8 @@ func runConvoyClose(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Printf("%s Closed convoy 🚚 %s: %s\n", style.Bold.Render("✓"), convoyID, convoy.Title)
+	logConvoyClosed(convoyID, convoy.Title, reason)
+	traceConvoyClosed(townBeads, convoyID, reason, nil)
 	if convoyCloseReason != "" {
 		fmt.Printf("  Reason: %s\n", convoyCloseReason)
 	}
@@ -688,6 +694,12 @@ func runConvoyClose(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// logConvoyClosed records a convoy_closed event for the feed and webhooks.
+func logConvoyClosed(convoyID, title, reason string) {
+	_ = events.LogFeed(events.TypeConvoyClosed, detectActor(), events.ConvoyClosedPayload(convoyID, title, reason))
+	exportToLedger([]string{convoyID}, ledger.TriggerConvoy)
+}
+
 // sendCloseNotification sends a notification about convoy closure.
 func sendCloseNotification(addr, convoyID, title, reason string) {
 	subject := fmt.Sprintf("🚚 Convoy closed: %s", title)
@@ -945,6 +957,8 @@ func checkAndCloseCompletedConvoys(townBeads string, dryRun bool) ([]struct{ ID,
 			}
 
 			closed = append(closed, struct{ ID, Title string }{convoy.ID, convoy.Title})
+			logConvoyClosed(convoy.ID, convoy.Title, "All tracked issues completed")
+			traceConvoyClosed(townBeads, convoy.ID, "All tracked issues completed", tracked)
 
 			// Check if convoy has notify address and send notification
 			notifyConvoyCompletion(townBeads, convoy.ID, convoy.Title)
@@ -1470,6 +1484,21 @@ func getIssueDetailsBatch(issueIDs []string) map[string]*issueDetails {
 		return result
 	}
 
+	if cwd, err := os.Getwd(); err == nil {
+		if issues, ok := beads.ShowMultipleSQL(cwd, issueIDs); ok {
+			for id, issue := range issues {
+				result[id] = &issueDetails{
+					ID:        issue.ID,
+					Title:     issue.Title,
+					Status:    issue.Status,
+					IssueType: issue.Type,
+					Assignee:  issue.Assignee,
+				}
+			}
+			return result
+		}
+	}
+
 	// Build args: bd --no-daemon show id1 id2 id3 ... --json
 	// Use --no-daemon to ensure fresh data (avoid stale cache from daemon)
 	args := append([]string{"--no-daemon", "show"}, issueIDs...)
@@ -17,6 +17,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/ledger"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -954,11 +955,7 @@ type CostLogEntry struct {
 
 // getCostsLogPath returns the path to the costs log file (~/.gt/costs.jsonl).
 func getCostsLogPath() string {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "/tmp/gt-costs.jsonl" // Fallback
-	}
-	return filepath.Join(home, ".gt", "costs.jsonl")
+	return ledger.CostsLogPath()
 }
 
 // runCostsRecord captures the final cost from a session and appends it to a local log file.
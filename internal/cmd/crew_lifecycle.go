@@ -609,8 +609,7 @@ func runCrewStop(cmd *cobra.Command, args []string) error {
 		townRoot, _ := workspace.Find(r.Path)
 		if townRoot != "" {
 			agent := fmt.Sprintf("%s/crew/%s", r.Name, name)
-			logger := townlog.NewLogger(townRoot)
-			_ = logger.Log(townlog.EventKill, agent, "gt crew stop")
+			_ = LogEventWithRoot(townRoot, townlog.EventKill, agent, "gt crew stop")
 		}
 
 		// Log captured output (truncated)
@@ -695,8 +694,7 @@ func runCrewStopAll() error {
 		// Log kill event to town log
 		townRoot, _ := workspace.FindFromCwd()
 		if townRoot != "" {
-			logger := townlog.NewLogger(townRoot)
-			_ = logger.Log(townlog.EventKill, agentName, "gt crew stop --all")
+			_ = LogEventWithRoot(townRoot, townlog.EventKill, agentName, "gt crew stop --all")
 		}
 
 		// Log captured output (truncated)
@@ -10,6 +10,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/daemon"
 	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/systemd"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -54,7 +55,10 @@ var daemonStatusCmd = &cobra.Command{
 var daemonLogsCmd = &cobra.Command{
 	Use:   "logs",
 	Short: "View daemon logs",
-	Long:  `View the daemon log file.`,
+	Long: `View the daemon log file.
+
+When the daemon is running, lines are streamed over its control socket;
+otherwise the log file is read directly.`,
 	RunE:  runDaemonLogs,
 }
 
@@ -98,6 +102,15 @@ func runDaemonStart(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("daemon already running (PID %d)", pid)
 	}
 
+	// Installed as a systemd unit: let systemd own the process
+	if unit, ok := daemonUnit(townRoot); ok {
+		if err := systemd.Systemctl("start", unit); err != nil {
+			return fmt.Errorf("starting daemon: %w", err)
+		}
+		fmt.Printf("%s Daemon started via systemd (%s)\n", style.Bold.Render("✓"), unit)
+		return nil
+	}
+
 	// Start daemon in background
 	// We use 'gt daemon run' as the actual daemon process
 	gtPath, err := os.Executable()
@@ -156,6 +169,16 @@ func runDaemonStop(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("daemon is not running")
 	}
 
+	// Stop through systemd so it doesn't count as a failure or restart it
+	if daemonUnitActive(townRoot) {
+		unit, _ := daemonUnit(townRoot)
+		if err := systemd.Systemctl("stop", unit); err != nil {
+			return fmt.Errorf("stopping daemon: %w", err)
+		}
+		fmt.Printf("%s Daemon stopped via systemd (was PID %d)\n", style.Bold.Render("✓"), pid)
+		return nil
+	}
+
 	if err := daemon.StopDaemon(townRoot); err != nil {
 		return fmt.Errorf("stopping daemon: %w", err)
 	}
@@ -181,8 +204,21 @@ func runDaemonStatus(cmd *cobra.Command, args []string) error {
 			style.Bold.Render("running"),
 			pid)
 
-		// Load state for more details
+		// Prefer live status from the control socket; fall back to state.json
 		state, err := daemon.LoadState(townRoot)
+		var live *daemon.ControlStatus
+		if client, cerr := daemon.DialControl(townRoot); cerr == nil {
+			if live, cerr = client.Status(); cerr == nil {
+				state = &daemon.State{
+					Running:        true,
+					PID:            live.PID,
+					StartedAt:      live.StartedAt,
+					LastHeartbeat:  live.LastHeartbeat,
+					HeartbeatCount: live.HeartbeatCount,
+				}
+				err = nil
+			}
+		}
 		if err == nil && !state.StartedAt.IsZero() {
 			fmt.Printf("  Started: %s\n", state.StartedAt.Format("2006-01-02 15:04:05"))
 			if !state.LastHeartbeat.IsZero() {
@@ -201,12 +237,16 @@ func runDaemonStatus(cmd *cobra.Command, args []string) error {
 				}
 			}
 		}
+		if live != nil {
+			printLivePatrols(live)
+		}
 	} else {
 		fmt.Printf("%s Daemon is %s\n",
 			style.Dim.Render("○"),
 			"not running")
 		fmt.Printf("\nStart with: %s\n", style.Dim.Render("gt daemon start"))
 	}
+	printDaemonUnitStatus(townRoot)
 
 	return nil
 }
@@ -236,6 +276,11 @@ func runDaemonLogs(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no log file found at %s", logFile)
 	}
 
+	// Stream through the daemon when it is up; fall back to tail otherwise
+	if client, err := daemon.DialControl(townRoot); err == nil {
+		return client.Logs(daemonLogLines, daemonLogFollow, os.Stdout)
+	}
+
 	if daemonLogFollow {
 		// Use tail -f for following
 		tailCmd := exec.Command("tail", "-f", logFile)
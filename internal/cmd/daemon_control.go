@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/daemon"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var daemonDeathsJSON bool
+
+var daemonHeartbeatCmd = &cobra.Command{
+	Use:   "heartbeat",
+	Short: "Run a daemon heartbeat now",
+	Long: `Ask the running daemon to run a heartbeat immediately instead of
+waiting for the next recovery interval.`,
+	Args: cobra.NoArgs,
+	RunE: runDaemonHeartbeat,
+}
+
+var daemonReloadCmd = &cobra.Command{
+	Use:   "reload",
+	Short: "Reload daemon config now",
+	Long: `Ask the running daemon to re-read its config files now.
+
+The daemon also watches these files and applies edits on its own within
+a few seconds:
+  mayor/daemon.json   patrol toggles and rigs, heartbeat.interval,
+                      scheduler and dolt_server settings
+  .krc.yaml           KRC TTLs and prune interval
+  settings/*.json     validated; read on next use
+
+Invalid files are rejected and the previous settings stay in effect.
+Rejections are logged and emitted as config_rejected feed events.`,
+	Args: cobra.NoArgs,
+	RunE: runDaemonReload,
+}
+
+var daemonPauseCmd = &cobra.Command{
+	Use:   "pause <patrol>...",
+	Short: "Pause daemon patrols",
+	Long: `Pause one or more daemon patrols: deacon, witness, refinery, scheduler,
+or all.
+
+A paused patrol is skipped on each heartbeat. Unlike disabling a patrol in
+mayor/daemon.json, pausing leaves existing sessions running. Pauses last
+until 'gt daemon resume' or the daemon restarts.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runDaemonPause,
+}
+
+var daemonResumeCmd = &cobra.Command{
+	Use:   "resume <patrol>...",
+	Short: "Resume paused daemon patrols",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runDaemonResume,
+}
+
+var daemonDeathsCmd = &cobra.Command{
+	Use:   "deaths",
+	Short: "List session deaths seen by the daemon",
+	Long: `List agent session deaths the daemon has recorded since it started
+(most recent last, up to 100).`,
+	Args: cobra.NoArgs,
+	RunE: runDaemonDeaths,
+}
+
+func init() {
+	daemonDeathsCmd.Flags().BoolVar(&daemonDeathsJSON, "json", false, "Output as JSON")
+
+	daemonCmd.AddCommand(daemonHeartbeatCmd)
+	daemonCmd.AddCommand(daemonReloadCmd)
+	daemonCmd.AddCommand(daemonPauseCmd)
+	daemonCmd.AddCommand(daemonResumeCmd)
+	daemonCmd.AddCommand(daemonDeathsCmd)
+}
+
+// daemonControl connects to the running daemon's control socket.
+func daemonControl() (*daemon.ControlClient, error) {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return nil, fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	client, err := daemon.DialControl(townRoot)
+	if err != nil {
+		if errors.Is(err, daemon.ErrControlUnavailable) {
+			return nil, fmt.Errorf("daemon is not running (start with 'gt daemon start')")
+		}
+		return nil, err
+	}
+	return client, nil
+}
+
+func runDaemonHeartbeat(cmd *cobra.Command, args []string) error {
+	client, err := daemonControl()
+	if err != nil {
+		return err
+	}
+	status, err := client.Heartbeat()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s Heartbeat #%d complete\n", style.SuccessPrefix, status.HeartbeatCount)
+	return nil
+}
+
+func runDaemonReload(cmd *cobra.Command, args []string) error {
+	client, err := daemonControl()
+	if err != nil {
+		return err
+	}
+	result, err := client.Reload()
+	if err != nil {
+		return err
+	}
+	for _, r := range result.Rejected {
+		fmt.Printf("%s Rejected %s: %s\n", style.WarningPrefix, r.File, r.Reason)
+	}
+	if len(result.Changes) == 0 {
+		fmt.Printf("%s Config reloaded %s\n", style.SuccessPrefix, style.Dim.Render("(no changes)"))
+		return nil
+	}
+	fmt.Printf("%s Config reloaded\n", style.SuccessPrefix)
+	for _, c := range result.Changes {
+		fmt.Printf("  %s %s\n", style.ArrowPrefix, c)
+	}
+	return nil
+}
+
+func runDaemonPause(cmd *cobra.Command, args []string) error {
+	client, err := daemonControl()
+	if err != nil {
+		return err
+	}
+	paused, err := client.Pause(args...)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s Paused: %s\n", style.SuccessPrefix, strings.Join(paused, ", "))
+	return nil
+}
+
+func runDaemonResume(cmd *cobra.Command, args []string) error {
+	client, err := daemonControl()
+	if err != nil {
+		return err
+	}
+	paused, err := client.Resume(args...)
+	if err != nil {
+		return err
+	}
+	if len(paused) == 0 {
+		fmt.Printf("%s All patrols running\n", style.SuccessPrefix)
+	} else {
+		fmt.Printf("%s Still paused: %s\n", style.SuccessPrefix, strings.Join(paused, ", "))
+	}
+	return nil
+}
+
+func runDaemonDeaths(cmd *cobra.Command, args []string) error {
+	client, err := daemonControl()
+	if err != nil {
+		return err
+	}
+	deaths, err := client.Deaths()
+	if err != nil {
+		return err
+	}
+
+	if daemonDeathsJSON {
+		data, err := json.MarshalIndent(deaths, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(deaths) == 0 {
+		fmt.Println(style.Dim.Render("No session deaths recorded"))
+		return nil
+	}
+	for _, d := range deaths {
+		fmt.Printf("  %s  %-30s %s\n", d.At.Format("2006-01-02 15:04:05"), d.Session, style.Dim.Render(formatAge(d.At)))
+	}
+	return nil
+}
+
+// printLivePatrols prints patrol state reported over the control socket.
+func printLivePatrols(s *daemon.ControlStatus) {
+	names := make([]string, 0, len(s.Patrols))
+	for name := range s.Patrols {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var parts []string
+	for _, name := range names {
+		state := "on"
+		switch {
+		case slices.Contains(s.Paused, name):
+			state = "paused"
+		case !s.Patrols[name]:
+			state = "off"
+		}
+		parts = append(parts, name+"="+state)
+	}
+	if s.Scheduler {
+		state := "on"
+		if slices.Contains(s.Paused, "scheduler") {
+			state = "paused"
+		}
+		parts = append(parts, "scheduler="+state)
+	}
+	fmt.Printf("  Patrols: %s\n", strings.Join(parts, " "))
+}
@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/daemon"
+	"github.com/steveyegge/gastown/internal/doltserver"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/systemd"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var (
+	installUnitDolt   bool
+	installUnitPrint  bool
+	installUnitEnable bool
+)
+
+var daemonInstallUnitCmd = &cobra.Command{
+	Use:   "install-unit",
+	Short: "Install systemd user units for the daemon (and Dolt)",
+	Long: `Generate systemd user units so systemd supervises the daemon.
+
+Writes ~/.config/systemd/user/gastown-<town>-daemon.service (Type=notify)
+and, with --dolt, gastown-<town>-dolt.service for the Dolt SQL server.
+The daemon unit runs 'gt daemon run', reports readiness with sd_notify and
+pets a 10 minute watchdog from its main loop, so a wedged daemon is
+restarted. KillMode=process keeps agent tmux sessions alive across daemon
+restarts.
+
+Once installed, 'gt daemon start' and 'gt daemon stop' go through
+systemctl, and 'gt daemon status' reports the unit state.
+
+Examples:
+  gt daemon install-unit --print          # Show the units, write nothing
+  gt daemon install-unit --dolt --enable  # Install, enable and start`,
+	Args: cobra.NoArgs,
+	RunE: runDaemonInstallUnit,
+}
+
+var daemonUninstallUnitCmd = &cobra.Command{
+	Use:   "uninstall-unit",
+	Short: "Remove the daemon's systemd user units",
+	Long: `Stop, disable and remove the systemd user units installed by
+'gt daemon install-unit'. The daemon can then be run with 'gt daemon start'.`,
+	Args: cobra.NoArgs,
+	RunE: runDaemonUninstallUnit,
+}
+
+func init() {
+	daemonInstallUnitCmd.Flags().BoolVar(&installUnitDolt, "dolt", false, "Also install a unit for the Dolt SQL server")
+	daemonInstallUnitCmd.Flags().BoolVar(&installUnitPrint, "print", false, "Print the units instead of installing them")
+	daemonInstallUnitCmd.Flags().BoolVar(&installUnitEnable, "enable", false, "Enable and start the units after installing")
+
+	daemonCmd.AddCommand(daemonInstallUnitCmd)
+	daemonCmd.AddCommand(daemonUninstallUnitCmd)
+}
+
+// townUnits builds the units for a town.
+func townUnits(townRoot string, withDolt bool) ([]*systemd.Unit, error) {
+	gtPath, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("finding executable: %w", err)
+	}
+
+	var units []*systemd.Unit
+	doltUnit := ""
+	if withDolt {
+		doltPath, err := exec.LookPath("dolt")
+		if err != nil {
+			return nil, fmt.Errorf("dolt not found in PATH: %w", err)
+		}
+		cfg := doltserver.DefaultConfig(townRoot)
+		u := systemd.DoltUnit(townRoot, doltPath, doltserver.ServerArgs(cfg), cfg.DataDir, cfg.LogFile)
+		units = append(units, u)
+		doltUnit = u.Name
+	}
+
+	// User managers start with a minimal PATH; agents need tmux, bd, etc.
+	units = append(units, systemd.DaemonUnit(townRoot, gtPath, os.Getenv("PATH"), doltUnit))
+	return units, nil
+}
+
+func runDaemonInstallUnit(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	units, err := townUnits(townRoot, installUnitDolt)
+	if err != nil {
+		return err
+	}
+
+	if installUnitPrint {
+		for _, u := range units {
+			fmt.Printf("# %s\n%s\n", u.Name, u.Render())
+		}
+		return nil
+	}
+
+	if !systemd.Available() {
+		return fmt.Errorf("systemd user manager not reachable (is this a systemd host with a user session? try 'loginctl enable-linger')")
+	}
+
+	// A daemon started outside systemd would hold the lock and make the
+	// unit fail; make the operator hand over explicitly.
+	if installUnitEnable {
+		if running, pid, _ := daemon.IsRunning(townRoot); running && !daemonUnitActive(townRoot) {
+			return fmt.Errorf("daemon already running outside systemd (PID %d); stop it first with 'gt daemon stop'", pid)
+		}
+	}
+
+	for _, u := range units {
+		path, err := systemd.WriteUnit(u)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s Wrote %s\n", style.SuccessPrefix, path)
+	}
+	if err := systemd.Systemctl("daemon-reload"); err != nil {
+		return err
+	}
+
+	if !installUnitEnable {
+		fmt.Printf("\nEnable with: %s\n", style.Dim.Render("systemctl --user enable --now "+units[len(units)-1].Name))
+		return nil
+	}
+	for _, u := range units {
+		if err := systemd.Systemctl("enable", "--now", u.Name); err != nil {
+			return err
+		}
+		fmt.Printf("%s Enabled and started %s\n", style.SuccessPrefix, u.Name)
+	}
+	return nil
+}
+
+func runDaemonUninstallUnit(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	dir, err := systemd.UserUnitDir()
+	if err != nil {
+		return err
+	}
+
+	removed := 0
+	for _, service := range []string{"daemon", "dolt"} {
+		name := systemd.UnitName(townRoot, service)
+		if !systemd.Installed(name) {
+			continue
+		}
+		if systemd.Available() {
+			if err := systemd.Systemctl("disable", "--now", name); err != nil {
+				fmt.Printf("%s %v\n", style.WarningPrefix, err)
+			}
+		}
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			return fmt.Errorf("removing %s: %w", name, err)
+		}
+		fmt.Printf("%s Removed %s\n", style.SuccessPrefix, name)
+		removed++
+	}
+	if removed == 0 {
+		fmt.Println(style.Dim.Render("No units installed for this town"))
+		return nil
+	}
+	if systemd.Available() {
+		return systemd.Systemctl("daemon-reload")
+	}
+	return nil
+}
+
+// daemonUnit returns the town's daemon unit name if it is installed and
+// systemd is reachable, so start/stop should go through systemctl.
+func daemonUnit(townRoot string) (string, bool) {
+	name := systemd.UnitName(townRoot, "daemon")
+	if !systemd.Installed(name) || !systemd.Available() {
+		return "", false
+	}
+	return name, true
+}
+
+// daemonUnitActive reports whether systemd is currently running the daemon.
+func daemonUnitActive(townRoot string) bool {
+	name, ok := daemonUnit(townRoot)
+	if !ok {
+		return false
+	}
+	status, err := systemd.Show(name)
+	return err == nil && status.Active()
+}
+
+// printDaemonUnitStatus prints the daemon unit's systemd state, if installed.
+func printDaemonUnitStatus(townRoot string) {
+	name, ok := daemonUnit(townRoot)
+	if !ok {
+		return
+	}
+	status, err := systemd.Show(name)
+	if err != nil {
+		fmt.Printf("  systemd: %s %s\n", name, style.Dim.Render("("+err.Error()+")"))
+		return
+	}
+	line := fmt.Sprintf("  systemd: %s %s (%s)", name, status.ActiveState, status.SubState)
+	if status.WatchdogSec > 0 {
+		line += fmt.Sprintf(", watchdog %ds", status.WatchdogSec)
+	}
+	if status.Restarts > 0 {
+		line += fmt.Sprintf(", %d restart(s)", status.Restarts)
+	}
+	fmt.Println(line)
+}
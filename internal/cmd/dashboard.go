@@ -8,7 +8,9 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/web"
+	"github.com/steveyegge/gastown/internal/webhooks"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -29,6 +31,9 @@ The dashboard shows real-time convoy status with:
 - Last activity indicator (green/yellow/red)
 - Auto-refresh every 30 seconds via htmx
 
+When inbound webhook sources are configured, the dashboard also receives
+webhooks at /hooks/<source> (see 'gt serve hooks').
+
 Example:
   gt dashboard              # Start on default port 8080
   gt dashboard --port 3000  # Start on port 3000
@@ -47,7 +52,7 @@ func runDashboard(cmd *cobra.Command, args []string) error {
 	var handler http.Handler
 	var err error
 
-	if _, wsErr := workspace.FindFromCwdOrError(); wsErr != nil {
+	if townRoot, wsErr := workspace.FindFromCwdOrError(); wsErr != nil {
 		// No workspace - run in setup mode
 		handler, err = web.NewSetupMux()
 		if err != nil {
@@ -64,6 +69,19 @@ func runDashboard(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return fmt.Errorf("creating dashboard handler: %w", err)
 		}
+
+		// Mount the inbound webhook receiver alongside the dashboard. A bad
+		// webhooks config shouldn't take the dashboard down with it.
+		receiver, hookErr := newHookReceiver(townRoot)
+		if hookErr != nil {
+			style.PrintWarning("webhooks disabled: %v", hookErr)
+		}
+		if receiver != nil {
+			mux := http.NewServeMux()
+			mux.Handle(webhooks.HooksPrefix, receiver)
+			mux.Handle("/", handler)
+			handler = mux
+		}
 	}
 
 	// Build the URL
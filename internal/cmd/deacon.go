@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -15,7 +16,9 @@ import (
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/daemon"
 	"github.com/steveyegge/gastown/internal/deacon"
+	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/polecat"
 	"github.com/steveyegge/gastown/internal/runtime"
 	"github.com/steveyegge/gastown/internal/session"
@@ -450,9 +453,9 @@ func startDeaconSession(t *tmux.Tmux, sessionName, agentOverride string) error {
 		return fmt.Errorf("waiting for deacon to start: %w", err)
 	}
 
-	// Accept bypass permissions warning dialog if it appears.
-	// This prevents hangs on systems where Claude prompts for permissions.
-	_ = t.AcceptBypassPermissionsWarning(sessionName)
+	// Answer the runtime's trust and permission dialogs (each answer is logged).
+	// This prevents hangs on systems where the agent prompts for permissions.
+	_, _ = runtime.AnswerStartupPrompts(t, sessionName, "deacon", runtimeConfig)
 
 	time.Sleep(constants.ShutdownNotifyDelay)
 
@@ -646,6 +649,15 @@ func runDeaconTriggerPending(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("not in a Gas Town workspace: %w", err)
 	}
 
+	// Let the daemon do it when running, so triggers are serialized with
+	// its own heartbeat-driven triggering. Fall back to running locally.
+	if client, err := daemon.DialControl(townRoot); err == nil {
+		if result, err := client.TriggerPending(triggerTimeout); err == nil {
+			printTriggerPendingResult(result)
+			return nil
+		}
+	}
+
 	// Step 1: Check inbox for new POLECAT_STARTED messages
 	pending, err := polecat.CheckInboxForSpawns(townRoot)
 	if err != nil {
@@ -696,6 +708,33 @@ func runDeaconTriggerPending(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// printTriggerPendingResult reports a trigger-pending run done by the daemon.
+func printTriggerPendingResult(r *daemon.TriggerPendingResult) {
+	if r.Pending == 0 {
+		fmt.Printf("%s No pending spawns\n", style.Dim.Render("○"))
+		return
+	}
+	fmt.Printf("%s Found %d pending spawn(s) %s\n", style.Bold.Render("●"), r.Pending, style.Dim.Render("(via daemon)"))
+	for _, name := range r.Triggered {
+		fmt.Printf("  %s Triggered %s\n", style.Bold.Render("✓"), name)
+	}
+	failed := make([]string, 0, len(r.Errors))
+	for name := range r.Errors {
+		failed = append(failed, name)
+	}
+	sort.Strings(failed)
+	for _, name := range failed {
+		fmt.Printf("  %s %s: %s\n", style.Dim.Render("⚠"), name, r.Errors[name])
+	}
+	if r.Pruned > 0 {
+		fmt.Printf("  %s Pruned %d stale spawn(s)\n", style.Dim.Render("○"), r.Pruned)
+	}
+	if remaining := r.Pending - len(r.Triggered); remaining > 0 {
+		fmt.Printf("%s %d spawn(s) still waiting for Claude\n",
+			style.Dim.Render("○"), remaining)
+	}
+}
+
 // runDeaconHealthCheck implements the health-check command.
 // It sends a HEALTH_CHECK nudge to an agent, waits for response, and tracks state.
 func runDeaconHealthCheck(cmd *cobra.Command, args []string) error {
@@ -739,6 +778,26 @@ func runDeaconHealthCheck(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	// An agent sitting on a dialog or a question can't answer a HEALTH_CHECK,
+	// and counting that as a failure would end in a force-kill. Report what
+	// it's waiting on instead.
+	if pane, err := classifyAgentPane(t, townRoot, agent, sessionName); err == nil {
+		state := ""
+		if pane.State.Stuck() {
+			state = string(pane.State)
+		}
+		_ = events.LogPaneState(townRoot, sessionName, agent, state, pane.Detail, "deacon")
+		if pane.State.Blocked() {
+			fmt.Printf("%s Agent %s is blocked (%s): %s\n",
+				style.Warning.Render("⚠"), agent, pane.State, pane.Detail)
+			return nil
+		}
+		if pane.State.Stuck() {
+			fmt.Printf("%s Agent %s looks stuck (%s): %s\n",
+				style.Warning.Render("⚠"), agent, pane.State, pane.Detail)
+		}
+	}
+
 	// Record ping
 	agentState.RecordPing()
 
@@ -992,6 +1051,28 @@ func agentAddressToIDs(address string) (beadID, sessionName string, err error) {
 	}
 }
 
+// classifyAgentPane classifies an agent's session output using the pane
+// patterns of the runtime configured for its role.
+func classifyAgentPane(t *tmux.Tmux, townRoot, address, sessionName string) (tmux.PaneClassification, error) {
+	role, rigPath := constants.RoleDeacon, ""
+	parts := strings.Split(address, "/")
+	switch {
+	case address == "mayor":
+		role = constants.RoleMayor
+	case len(parts) == 2:
+		role, rigPath = parts[1], filepath.Join(townRoot, parts[0])
+	case len(parts) == 3 && parts[1] == "crew":
+		role, rigPath = constants.RoleCrew, filepath.Join(townRoot, parts[0])
+	case len(parts) == 3:
+		role, rigPath = constants.RolePolecat, filepath.Join(townRoot, parts[0])
+	}
+	classifier, err := tmux.NewPaneClassifier(config.ResolveRoleAgentConfig(role, townRoot, rigPath))
+	if err != nil {
+		return tmux.PaneClassification{}, err
+	}
+	return t.ClassifyPane(sessionName, classifier)
+}
+
 // getAgentBeadUpdateTime gets the update time from an agent bead.
 func getAgentBeadUpdateTime(townRoot, beadID string) (time.Time, error) {
 	cmd := exec.Command("bd", "show", beadID, "--json")
@@ -47,6 +47,7 @@ Infrastructure checks:
   - daemon                   Check if daemon is running (fixable)
   - repo-fingerprint         Check database has valid repo fingerprint (fixable)
   - boot-health              Check Boot watchdog health (vet mode)
+  - sandbox-runtime          Check podman/bubblewrap for sandboxed rigs
 
 Cleanup checks (fixable):
   - orphan-sessions          Detect orphaned tmux sessions
@@ -142,6 +143,7 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 	// Register built-in checks
 	d.Register(doctor.NewStaleBinaryCheck())
 	d.Register(doctor.NewSqlite3Check())
+	d.Register(doctor.NewSandboxRuntimeCheck())
 	d.Register(doctor.NewTownGitCheck())
 	d.Register(doctor.NewTownRootBranchCheck())
 	d.Register(doctor.NewPreCheckoutHookCheck())
@@ -10,6 +10,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/eventbus"
 	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/mail"
@@ -18,6 +19,8 @@ import (
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/townlog"
+	"github.com/steveyegge/gastown/internal/tracing"
+	"github.com/steveyegge/gastown/internal/witness"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -82,7 +85,7 @@ func init() {
 	rootCmd.AddCommand(doneCmd)
 }
 
-func runDone(cmd *cobra.Command, args []string) error {
+func runDone(cmd *cobra.Command, args []string) (retErr error) {
 	// Guard: Only polecats should call gt done
 	// Crew, deacons, witnesses etc. don't use gt done - they persist across tasks.
 	// Polecats are ephemeral workers that self-destruct after completing work.
@@ -249,6 +252,10 @@ func runDone(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	span := tracing.Start("gt.done", issueID)
+	span.SetAttr("exit_type", exitType)
+	defer func() { span.End(retErr) }()
+
 	// Get configured default branch for this rig
 	defaultBranch := "main" // fallback
 	if rigCfg, err := rig.LoadRigConfig(filepath.Join(townRoot, rigName)); err == nil && rigCfg.DefaultBranch != "" {
@@ -413,6 +420,8 @@ func runDone(cmd *cobra.Command, args []string) error {
 		}
 
 		// Check if MR bead already exists for this branch (idempotency)
+		mqSpan := tracing.Start("mq.submit", issueID)
+		mqSpan.SetAttr("branch", branch)
 		existingMR, err := bd.FindMRForBranch(branch)
 		if err != nil {
 			style.PrintWarning("could not check for existing MR: %v", err)
@@ -450,6 +459,7 @@ func runDone(cmd *cobra.Command, args []string) error {
 				Ephemeral:   true,
 			})
 			if err != nil {
+				mqSpan.End(err)
 				return fmt.Errorf("creating merge request bead: %w", err)
 			}
 			mrID = mrIssue.ID
@@ -468,6 +478,8 @@ func runDone(cmd *cobra.Command, args []string) error {
 			// Nudge refinery to pick up the new MR
 			nudgeRefinery(rigName, fmt.Sprintf("MR submitted: %s branch=%s", mrID, branch))
 		}
+		mqSpan.SetAttr("mr", mrID)
+		mqSpan.End(nil)
 		fmt.Printf("  Source: %s\n", branch)
 		fmt.Printf("  Target: %s\n", target)
 		fmt.Printf("  Issue: %s\n", issueID)
@@ -510,25 +522,21 @@ notifyWitness:
 	townRouter := mail.NewRouter(townRoot)
 	witnessAddr := fmt.Sprintf("%s/witness", rigName)
 
-	// Build notification body
-	var bodyLines []string
-	bodyLines = append(bodyLines, fmt.Sprintf("Exit: %s", exitType))
-	if issueID != "" {
-		bodyLines = append(bodyLines, fmt.Sprintf("Issue: %s", issueID))
-	}
-	if mrID != "" {
-		bodyLines = append(bodyLines, fmt.Sprintf("MR: %s", mrID))
-	}
-	if doneGate != "" {
-		bodyLines = append(bodyLines, fmt.Sprintf("Gate: %s", doneGate))
-	}
-	bodyLines = append(bodyLines, fmt.Sprintf("Branch: %s", branch))
+	// Build notification body (legacy lines plus typed protocol envelope)
+	doneBody := witness.FormatPolecatDoneBody(witness.PolecatDonePayload{
+		PolecatName: polecatName,
+		Exit:        exitType,
+		IssueID:     issueID,
+		MRID:        mrID,
+		Branch:      branch,
+		Gate:        doneGate,
+	})
 
 	doneNotification := &mail.Message{
 		To:      witnessAddr,
 		From:    sender,
 		Subject: fmt.Sprintf("POLECAT_DONE %s", polecatName),
-		Body:    strings.Join(bodyLines, "\n"),
+		Body:    doneBody,
 	}
 
 	fmt.Printf("\nNotifying Witness...\n")
@@ -546,7 +554,7 @@ notifyWitness:
 			To:      witnessAddr,
 			From:    sender,
 			Subject: fmt.Sprintf("WORK_DONE: %s", issueID),
-			Body:    strings.Join(bodyLines, "\n"),
+			Body:    doneBody,
 		}
 		if err := townRouter.Send(workDoneNotification); err != nil {
 			style.PrintWarning("could not notify witness of work done: %v", err)
@@ -556,8 +564,18 @@ notifyWitness:
 	}
 
 	// Log done event (townlog and activity feed)
-	_ = LogDone(townRoot, sender, issueID)
-	_ = events.LogFeed(events.TypeDone, sender, events.DonePayload(issueID, branch))
+	donePayload := events.DonePayload(issueID, branch)
+	donePayload["exit_type"] = exitType // Lets routing history tell completions from escalations
+	_ = eventbus.Publish(townRoot, eventbus.Event{
+		Type:    eventbus.TypeDone,
+		Actor:   sender,
+		Context: issueID,
+		Payload: donePayload,
+	})
+
+	// The session is killed below, so finish the trace now.
+	span.End(nil)
+	tracing.Flush()
 
 	// Update agent bead state (ZFC: self-report completion)
 	updateAgentStateOnDone(cwd, townRoot, exitType, issueID)
@@ -767,7 +785,7 @@ func updateAgentStateOnDone(cwd, townRoot, exitType, _ string) { // issueID unus
 // This is the authoritative source for what work a polecat is doing, since branch
 // names may not contain the issue ID (e.g., "polecat/furiosa-mkb0vq9f").
 // Returns empty string if agent doesn't exist or has no hook.
-func getIssueFromAgentHook(bd *beads.Beads, agentBeadID string) string {
+func getIssueFromAgentHook(bd beads.Store, agentBeadID string) string {
 	if agentBeadID == "" {
 		return ""
 	}
@@ -883,8 +901,7 @@ func selfKillSession(townRoot string, roleInfo RoleInfo) error {
 
 	// Log to townlog (human-readable audit log)
 	if townRoot != "" {
-		logger := townlog.NewLogger(townRoot)
-		_ = logger.Log(townlog.EventKill, agentID, "self-clean: done means gone")
+		_ = LogEventWithRoot(townRoot, townlog.EventKill, agentID, "self-clean: done means gone")
 	}
 
 	// Log to events (JSON audit log with structured payload)
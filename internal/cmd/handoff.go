@@ -11,6 +11,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/eventbus"
 	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/mail"
 	"github.com/steveyegge/gastown/internal/session"
@@ -172,9 +173,12 @@ func runHandoff(cmd *cobra.Command, args []string) error {
 		if agent == "" {
 			agent = currentSession
 		}
-		_ = LogHandoff(townRoot, agent, handoffSubject)
-		// Also log to activity feed
-		_ = events.LogFeed(events.TypeHandoff, agent, events.HandoffPayload(handoffSubject, true))
+		_ = eventbus.Publish(townRoot, eventbus.Event{
+			Type:    eventbus.TypeHandoff,
+			Actor:   agent,
+			Context: handoffSubject,
+			Payload: events.HandoffPayload(handoffSubject, true),
+		})
 	}
 
 	// Dry run mode - show what would happen (BEFORE any side effects)
@@ -57,6 +57,11 @@ var krcPruneCmd = &cobra.Command{
 Events are removed from both .events.jsonl and .feed.jsonl.
 The operation is atomic (uses temp files and rename).
 
+Events pruned from .events.jsonl are moved to a compressed cold archive
+under <town>/archive/events/ (one segment per day), where "gt krc query"
+can still find them. Segments older than the archive retention are deleted.
+Set the archive retention to 0 to drop pruned events instead.
+
 Use --dry-run to preview what would be pruned without making changes.`,
 	RunE: runKrcPrune,
 }
@@ -86,7 +91,8 @@ var krcConfigSetCmd = &cobra.Command{
 	Long: `Set the TTL for events matching the given pattern.
 
 Patterns support glob-style matching with * (e.g., "patrol_*" matches all patrol events).
-Use "default" as the pattern to set the default TTL.
+Use "default" as the pattern to set the default TTL, or "archive" to set
+how long pruned events stay in the cold archive (0 disables archiving).
 
 TTL format: 1h, 12h, 1d, 7d, 30d, etc.`,
 	Args: cobra.ExactArgs(2),
@@ -114,6 +120,22 @@ Events with low forensic scores are candidates for aggressive pruning.`,
 	RunE: runKrcDecay,
 }
 
+var krcQueryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "Search live and archived events",
+	Long: `Search events in .events.jsonl and the cold archive of pruned events.
+
+--type and --actor accept * globs. --since and --until accept an RFC 3339
+time, a date (2006-01-02), or an age such as 12h or 30d. A date given to
+--until includes the whole day.
+
+Examples:
+  gt krc query --type session_death --since 45d
+  gt krc query --type 'merge_*' --actor gastown/refinery --since 2026-09-01 --until 2026-09-08
+  gt krc query --actor 'gastown/polecats/*' --limit 20 --json`,
+	RunE: runKrcQuery,
+}
+
 var krcAutoPruneStatusCmd = &cobra.Command{
 	Use:   "auto-prune-status",
 	Short: "Show auto-prune scheduling state",
@@ -129,6 +151,13 @@ var (
 	krcPruneAuto   bool
 	krcStatsJSON   bool
 	krcDecayJSON   bool
+
+	krcQueryType  string
+	krcQueryActor string
+	krcQuerySince string
+	krcQueryUntil string
+	krcQueryLimit int
+	krcQueryJSON  bool
 )
 
 func init() {
@@ -138,6 +167,7 @@ func init() {
 	krcCmd.AddCommand(krcConfigCmd)
 	krcCmd.AddCommand(krcDecayCmd)
 	krcCmd.AddCommand(krcAutoPruneStatusCmd)
+	krcCmd.AddCommand(krcQueryCmd)
 	krcConfigCmd.AddCommand(krcConfigSetCmd)
 	krcConfigCmd.AddCommand(krcConfigResetCmd)
 
@@ -145,6 +175,13 @@ func init() {
 	krcPruneCmd.Flags().BoolVar(&krcPruneAuto, "auto", false, "Daemon mode: only prune if PruneInterval has elapsed")
 	krcStatsCmd.Flags().BoolVar(&krcStatsJSON, "json", false, "Output in JSON format")
 	krcDecayCmd.Flags().BoolVar(&krcDecayJSON, "json", false, "Output in JSON format")
+
+	krcQueryCmd.Flags().StringVar(&krcQueryType, "type", "", "Event type (glob)")
+	krcQueryCmd.Flags().StringVar(&krcQueryActor, "actor", "", "Actor (glob)")
+	krcQueryCmd.Flags().StringVar(&krcQuerySince, "since", "", "Earliest event time or age (e.g. 2026-09-01, 7d)")
+	krcQueryCmd.Flags().StringVar(&krcQueryUntil, "until", "", "Latest event time or age")
+	krcQueryCmd.Flags().IntVar(&krcQueryLimit, "limit", 100, "Show only the most recent N matches (0 for all)")
+	krcQueryCmd.Flags().BoolVar(&krcQueryJSON, "json", false, "Output in JSON format")
 }
 
 func runKrcStats(cmd *cobra.Command, args []string) error {
@@ -303,6 +340,7 @@ func runKrcPrune(cmd *cobra.Command, args []string) error {
 	fmt.Printf("  Events processed: %d\n", result.EventsProcessed)
 	fmt.Printf("  Events pruned:    %d\n", result.EventsPruned)
 	fmt.Printf("  Events retained:  %d\n", result.EventsRetained)
+	fmt.Printf("  Events archived:  %d\n", result.EventsArchived)
 	fmt.Printf("  Space saved:      %s\n", formatBytes(result.BytesBefore-result.BytesAfter))
 	fmt.Printf("  Duration:         %s\n", result.Duration.Round(time.Millisecond))
 
@@ -345,6 +383,11 @@ func runKrcConfig(cmd *cobra.Command, args []string) error {
 	fmt.Printf("Default TTL:     %s\n", krcFormatDuration(config.DefaultTTL))
 	fmt.Printf("Prune interval:  %s\n", krcFormatDuration(config.PruneInterval))
 	fmt.Printf("Min retain:      %d events\n", config.MinRetainCount)
+	if config.ArchiveRetention > 0 {
+		fmt.Printf("Archive:         %s in %s\n", krcFormatDuration(config.ArchiveRetention), krc.ArchiveDir(townRoot))
+	} else {
+		fmt.Printf("Archive:         %s\n", style.Dim.Render("disabled"))
+	}
 	fmt.Println()
 	fmt.Println(style.Bold.Render("TTLs by pattern:"))
 
@@ -381,10 +424,18 @@ func runKrcConfigSet(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("loading config: %w", err)
 	}
 
-	if pattern == "default" {
+	switch pattern {
+	case "default":
 		config.DefaultTTL = ttl
 		fmt.Printf("Set default TTL to %s\n", krcFormatDuration(ttl))
-	} else {
+	case "archive":
+		config.ArchiveRetention = ttl
+		if ttl == 0 {
+			fmt.Println("Disabled the event archive")
+		} else {
+			fmt.Printf("Set archive retention to %s\n", krcFormatDuration(ttl))
+		}
+	default:
 		if config.TTLs == nil {
 			config.TTLs = make(map[string]time.Duration)
 		}
@@ -628,3 +679,81 @@ func runKrcAutoPruneStatus(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+func runKrcQuery(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	now := time.Now()
+	since, err := krcParseTime(krcQuerySince, now, false)
+	if err != nil {
+		return fmt.Errorf("invalid --since: %w", err)
+	}
+	until, err := krcParseTime(krcQueryUntil, now, true)
+	if err != nil {
+		return fmt.Errorf("invalid --until: %w", err)
+	}
+
+	records, err := krc.Query(townRoot, krc.QueryOptions{
+		Type:  krcQueryType,
+		Actor: krcQueryActor,
+		Since: since,
+		Until: until,
+		Limit: krcQueryLimit,
+	})
+	if err != nil {
+		return fmt.Errorf("querying events: %w", err)
+	}
+
+	if krcQueryJSON {
+		if records == nil {
+			records = []krc.Record{}
+		}
+		data, err := json.MarshalIndent(records, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(records) == 0 {
+		fmt.Printf("%s No matching events\n", style.Dim.Render("○"))
+		return nil
+	}
+	for _, r := range records {
+		source := ""
+		if r.Segment != "live" {
+			source = style.Dim.Render(" [archived]")
+		}
+		fmt.Printf("%s  %-20s %-28s %s%s\n", r.Timestamp, r.Type, r.Actor, r.Context, source)
+	}
+	return nil
+}
+
+// krcParseTime parses an RFC 3339 time, a date, or an age relative to now
+// (e.g., "7d", "12h"). Empty means no bound. A date is the start of that
+// day, or with endOfDay its last instant, so an inclusive upper bound
+// covers the whole day.
+func krcParseTime(s string, now time.Time, endOfDay bool) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		if endOfDay {
+			t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		return t, nil
+	}
+	d, err := krcParseDuration(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not a time, date, or age", s)
+	}
+	return now.Add(-d), nil
+}
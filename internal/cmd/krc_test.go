@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKrcParseTime(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in       string
+		endOfDay bool
+		want     time.Time
+	}{
+		{"", false, time.Time{}},
+		{"2026-09-01T08:30:00Z", false, time.Date(2026, 9, 1, 8, 30, 0, 0, time.UTC)},
+		{"2026-09-01T08:30:00Z", true, time.Date(2026, 9, 1, 8, 30, 0, 0, time.UTC)},
+		{"2026-09-01", false, time.Date(2026, 9, 1, 0, 0, 0, 0, time.Local)},
+		{"2026-09-01", true, time.Date(2026, 9, 1, 23, 59, 59, 999999999, time.Local)},
+		{"7d", true, now.Add(-7 * 24 * time.Hour)},
+		{"90m", false, now.Add(-90 * time.Minute)},
+	}
+	for _, tt := range tests {
+		got, err := krcParseTime(tt.in, now, tt.endOfDay)
+		if err != nil {
+			t.Errorf("krcParseTime(%q): %v", tt.in, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("krcParseTime(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+	if _, err := krcParseTime("last tuesday", now, false); err == nil {
+		t.Error("krcParseTime should reject unparseable input")
+	}
+}
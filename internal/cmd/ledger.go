@@ -0,0 +1,298 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/ledger"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Ledger command flags
+var (
+	ledgerExportAll  bool
+	ledgerShowJSON   bool
+	ledgerVerifyJSON bool
+)
+
+var ledgerCmd = &cobra.Command{
+	Use:     "ledger",
+	GroupID: GroupWork,
+	Short:   "Permanent record of completed work",
+	Long: `Manage the town's ledger of completed work.
+
+When work completes, a record of it is exported from the operational beads
+to <town>/ledger, where it outlives compaction:
+  - Closing a bead (gt close) records a completion
+  - Closing a convoy records a convoy summary
+  - The refinery closing a merged MR records the merge
+
+Records are immutable and named by their content hash. Every export is an
+entry in a hash-chained log, so edits and deletions are detectable with
+'gt ledger verify'. Re-exporting unchanged work is a no-op; work that was
+reopened and closed again gets a correction entry.
+
+Export never blocks the operation that triggered it. 'gt ledger export
+--all' backfills anything a trigger missed.`,
+	RunE: requireSubcommand,
+}
+
+var ledgerExportCmd = &cobra.Command{
+	Use:   "export [bead-id...]",
+	Short: "Export closed beads to the ledger",
+	Long: `Export closed beads, convoys and merged MRs to the ledger.
+
+With --all, every closed bead in the town and its rigs is scanned; records
+already in the ledger are skipped.
+
+Examples:
+  gt ledger export gt-abc hq-cv-xyz   # Specific beads
+  gt ledger export --all              # Backfill / retry everything`,
+	RunE: runLedgerExport,
+}
+
+var ledgerShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show the ledger history of a bead, convoy or MR",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runLedgerShow,
+}
+
+var ledgerVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check the ledger's hash chain and records",
+	Long: `Walk the ledger chain and check every entry's hash, its link to the
+previous entry, and the content hash of the record it points to.
+
+Exits non-zero if anything was tampered with or is missing.`,
+	RunE: runLedgerVerify,
+}
+
+func init() {
+	ledgerExportCmd.Flags().BoolVar(&ledgerExportAll, "all", false, "Scan every closed bead in the town")
+	ledgerShowCmd.Flags().BoolVar(&ledgerShowJSON, "json", false, "Output as JSON")
+	ledgerVerifyCmd.Flags().BoolVar(&ledgerVerifyJSON, "json", false, "Output as JSON")
+
+	ledgerCmd.AddCommand(ledgerExportCmd)
+	ledgerCmd.AddCommand(ledgerShowCmd)
+	ledgerCmd.AddCommand(ledgerVerifyCmd)
+	rootCmd.AddCommand(ledgerCmd)
+}
+
+func runLedgerExport(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 && !ledgerExportAll {
+		return fmt.Errorf("specify bead IDs or --all")
+	}
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	x := ledger.NewTownExporter(townRoot)
+
+	added, unchanged, failed := 0, 0, 0
+	tally := func(results []ledger.Result) {
+		for _, r := range results {
+			if r.Added {
+				added++
+				fmt.Printf("%s %s %s\n", style.Bold.Render("✓"), r.Entry.Kind, r.Entry.Subject)
+			} else {
+				unchanged++
+			}
+		}
+	}
+
+	for _, id := range args {
+		results, err := x.Export(id, ledger.TriggerScan)
+		if err != nil {
+			failed++
+			style.PrintWarning("%v", err)
+			continue
+		}
+		tally(results)
+	}
+
+	if ledgerExportAll {
+		for _, location := range ledgerLocations(townRoot) {
+			issues, err := beads.New(location).List(beads.ListOptions{Status: "closed", Priority: -1})
+			if err != nil {
+				style.PrintWarning("listing closed beads in %s: %v", location, err)
+				continue
+			}
+			for _, issue := range issues {
+				// Reload through Show so the record matches what the close
+				// trigger would have exported; list output is abbreviated.
+				results, err := x.Export(issue.ID, ledger.TriggerScan)
+				if err != nil {
+					failed++
+					style.PrintWarning("%v", err)
+					continue
+				}
+				tally(results)
+			}
+		}
+	}
+
+	fmt.Printf("\nExported %d record(s), %d already in the ledger", added, unchanged)
+	if failed > 0 {
+		fmt.Printf(", %d failed", failed)
+	}
+	fmt.Println()
+	if failed > 0 {
+		return NewSilentExit(1)
+	}
+	return nil
+}
+
+// ledgerShowItem is one entry of 'gt ledger show --json'.
+type ledgerShowItem struct {
+	Entry  ledger.Entry   `json:"entry"`
+	Record *ledger.Record `json:"record,omitempty"`
+	Error  string         `json:"error,omitempty"`
+}
+
+func runLedgerShow(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	store := ledger.Open(townRoot)
+	history, err := store.History(args[0])
+	if err != nil {
+		return fmt.Errorf("reading ledger: %w", err)
+	}
+
+	items := make([]ledgerShowItem, 0, len(history))
+	for _, e := range history {
+		item := ledgerShowItem{Entry: e}
+		if rec, err := store.Record(e.Record); err != nil {
+			item.Error = err.Error()
+		} else {
+			item.Record = rec
+		}
+		items = append(items, item)
+	}
+
+	if ledgerShowJSON {
+		data, err := json.MarshalIndent(items, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(items) == 0 {
+		fmt.Printf("%s %s is not in the ledger\n", style.Dim.Render("○"), args[0])
+		return nil
+	}
+	for i, item := range items {
+		label := "Record"
+		if i > 0 {
+			label = "Correction"
+		}
+		e := item.Entry
+		fmt.Printf("%s %s #%d: %s %s\n", style.Bold.Render("📒"), label, e.Seq, e.Kind, e.Subject)
+		fmt.Printf("  Exported: %s (%s)\n", e.ExportedAt.Local().Format("2006-01-02 15:04:05"), e.Trigger)
+		fmt.Printf("  Record:   %s\n", e.Record)
+		fmt.Printf("  Entry:    %s\n", e.Hash)
+		if item.Error != "" {
+			fmt.Printf("  %s %s\n\n", style.Warning.Render("⚠"), item.Error)
+			continue
+		}
+		data, err := json.MarshalIndent(item.Record, "  ", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("  %s\n\n", data)
+	}
+	return nil
+}
+
+func runLedgerVerify(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	n, problems, err := ledger.Open(townRoot).Verify()
+	if err != nil {
+		return err
+	}
+
+	if ledgerVerifyJSON {
+		data, err := json.MarshalIndent(struct {
+			Entries  int              `json:"entries"`
+			Problems []ledger.Problem `json:"problems"`
+		}{n, problems}, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	} else if len(problems) == 0 {
+		fmt.Printf("%s Ledger intact: %d entries verified\n", style.Bold.Render("✓"), n)
+	} else {
+		fmt.Printf("%s Ledger verification failed: %d problem(s) in %d entries\n\n",
+			style.Warning.Render("⚠"), len(problems), n)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, p := range problems {
+			fmt.Fprintf(w, "  #%d\t%s\t%s\n", p.Seq, p.Subject, p.Detail)
+		}
+		_ = w.Flush()
+	}
+
+	if len(problems) > 0 {
+		return NewSilentExit(1)
+	}
+	return nil
+}
+
+// exportToLedger records closed beads in the ledger after a trigger fired.
+// Problems are reported as warnings and never fail the command that closed
+// the work.
+func exportToLedger(ids []string, trigger string) {
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil {
+		return
+	}
+	ledger.ExportClosed(townRoot, ids, trigger, func(id string, err error) {
+		fmt.Fprintf(os.Stderr, "Warning: ledger export of %s failed: %v\n", id, err)
+	})
+}
+
+// ledgerLocations returns the town and every rig directory.
+func ledgerLocations(townRoot string) []string {
+	locations := []string{townRoot}
+	rigsConfig, err := config.LoadRigsConfig(filepath.Join(townRoot, constants.DirMayor, constants.FileRigsJSON))
+	if err == nil && rigsConfig != nil {
+		for rigName := range rigsConfig.Rigs {
+			locations = append(locations, filepath.Join(townRoot, rigName))
+		}
+	}
+	return locations
+}
+
+// closeArgBeadIDs returns the bead IDs among 'bd close' arguments.
+func closeArgBeadIDs(args []string) []string {
+	valueFlags := map[string]bool{"--reason": true, "-r": true, "--session": true, "--actor": true, "--db": true}
+	var ids []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if valueFlags[arg] {
+			i++
+			continue
+		}
+		if strings.HasPrefix(arg, "-") {
+			continue
+		}
+		ids = append(ids, arg)
+	}
+	return ids
+}
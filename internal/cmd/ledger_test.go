@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCloseArgBeadIDs(t *testing.T) {
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"gt-abc"}, []string{"gt-abc"}},
+		{[]string{"gt-abc", "gt-def", "--force"}, []string{"gt-abc", "gt-def"}},
+		{[]string{"--reason", "Merged to main", "gt-mr1"}, []string{"gt-mr1"}},
+		{[]string{"-r", "done", "gt-1", "--session=s1", "--actor", "mayor"}, []string{"gt-1"}},
+		{[]string{"--reason=done"}, nil},
+	}
+	for _, tt := range tests {
+		if got := closeArgBeadIDs(tt.args); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("closeArgBeadIDs(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
@@ -8,6 +8,9 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/cgroup"
+	"github.com/steveyegge/gastown/internal/eventbus"
+	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/townlog"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -64,6 +67,10 @@ The exit code determines if this was a crash or expected exit:
   - Exit code 0: Expected exit (logged as 'done' if no other done was recorded)
   - Exit code non-zero: Crash (logged as 'crash')
 
+If the session ran under cgroup resource limits and the kernel OOM killer
+fired, the crash is also recorded as a session_death event with reason
+"oom_kill".
+
 Examples:
   gt log crash --agent greenplace/Toast --session gt-greenplace-Toast --exit-code 1`,
 	RunE: runLogCrash,
@@ -337,20 +344,35 @@ func runLogCrash(cmd *cobra.Command, args []string) error {
 		// Non-zero exit = crash
 		eventType = townlog.EventCrash
 		context = fmt.Sprintf("exit code %d", crashExitCode)
+		if crashSession != "" && cgroup.OOMKilled(crashSession) {
+			context = fmt.Sprintf("%s, exit code %d", cgroup.OOMKillReason, crashExitCode)
+			logOOMDeath(townRoot)
+		}
 		if crashSession != "" {
 			context += fmt.Sprintf(" (session: %s)", crashSession)
 		}
 	}
 
 	// Log the event
-	logger := townlog.NewLogger(townRoot)
-	if err := logger.Log(eventType, crashAgent, context); err != nil {
+	if err := LogEventWithRoot(townRoot, eventType, crashAgent, context); err != nil {
 		return fmt.Errorf("logging event: %w", err)
 	}
 
 	return nil
 }
 
+// logOOMDeath records a session_death event for a session whose agent was
+// killed by the OOM killer. The hook runs from tmux with an arbitrary cwd,
+// so the town root is passed explicitly. The crash itself goes to the town
+// log, so the death is only written to the activity log.
+func logOOMDeath(townRoot string) {
+	_ = eventbus.Emit(townRoot, eventbus.Event{
+		Type:    eventbus.TypeSessionDeath,
+		Actor:   crashAgent,
+		Payload: events.SessionDeathPayload(crashSession, crashAgent, cgroup.OOMKillReason, "pane-died"),
+	}, eventbus.SinkEvents)
+}
+
 // LogEvent is a helper that logs an event from anywhere in the codebase.
 // It finds the town root and logs the event.
 func LogEvent(eventType townlog.EventType, agent, context string) error {
@@ -362,14 +384,19 @@ func LogEvent(eventType townlog.EventType, agent, context string) error {
 		return nil
 	}
 
-	logger := townlog.NewLogger(townRoot)
-	return logger.Log(eventType, agent, context)
+	return LogEventWithRoot(townRoot, eventType, agent, context)
 }
 
 // LogEventWithRoot logs an event when the town root is already known.
+// The event goes to the town log only; callers that want it in the activity
+// feed log a typed event (e.g. session_death) alongside it.
 func LogEventWithRoot(townRoot string, eventType townlog.EventType, agent, context string) error {
-	logger := townlog.NewLogger(townRoot)
-	return logger.Log(eventType, agent, context)
+	return eventbus.Emit(townRoot, eventbus.Event{
+		Type:       string(eventType),
+		Actor:      agent,
+		Context:    context,
+		Visibility: eventbus.VisibilityAudit,
+	}, eventbus.SinkTownLog)
 }
 
 // Convenience functions for common events
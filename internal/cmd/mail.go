@@ -30,12 +30,17 @@ var (
 	mailReplySubject  string
 	mailReplyMessage  string
 	mailStdin         bool // Read message body from stdin
+	mailSendAt        string
+	mailSendIn        string
+	mailSendExpires   string
+	mailSendEvery     string
 
 	// Search flags
 	mailSearchFrom    string
 	mailSearchSubject bool
 	mailSearchBody    bool
 	mailSearchArchive bool
+	mailSearchReindex bool
 	mailSearchJSON    bool
 
 	// Announces flags
@@ -122,6 +127,17 @@ Priority levels:
 
 Use --urgent as shortcut for --priority 0.
 
+Scheduling:
+  --at <time>       Deliver at a time: 15:04, "2006-01-02 15:04" or RFC 3339
+  --in <duration>   Deliver after a delay, e.g. 2h or 30m
+  --every <rule>    Repeat: a duration (4h), hourly, daily@09:00, weekdays@09:00
+  --expires <when>  Drop the message if still unread after a duration (30m,
+                    counted from each delivery) or at a time
+
+Deferred and recurring messages are held by the daemon until due; list and
+cancel them with 'gt mail scheduled'. Expired messages disappear from the
+inbox.
+
 Examples:
   gt mail send greenplace/Toast -s "Status check" -m "How's that bug fix going?"
   gt mail send mayor/ -s "Work complete" -m "Finished gt-abc"
@@ -132,6 +148,9 @@ Examples:
   gt mail send --self -s "Handoff" -m "Context for next session"
   gt mail send greenplace/Toast -s "Update" -m "Progress report" --cc overseer
   gt mail send list:oncall -s "Alert" -m "System down"
+  gt mail send gastown/witness -s "Check nux" -m "Still stuck?" --in 2h
+  gt mail send gastown/nux -s "Rebase now" -m "main moved" --expires 30m
+  gt mail send deacon/ -s "Standup" -m "Post status" --every weekdays@09:00
 
   # Read body from stdin (avoids shell quoting issues):
   gt mail send mayor/ -s "Update" --stdin <<'BODY'
@@ -391,28 +410,49 @@ Examples:
 var mailSearchCmd = &cobra.Command{
 	Use:   "search <query>",
 	Short: "Search messages by content",
-	Long: `Search inbox for messages matching a pattern.
+	Long: `Search inbox and archive for messages matching a query.
 
 SYNTAX:
   gt mail search <query> [flags]
 
-The query is a regular expression pattern. Search is case-insensitive by default.
+Searches use a per-mailbox index that is built on first use and updated as
+mail is delivered, read and archived. Words match whole words in the subject
+or body, case-insensitively.
+
+QUERY SYNTAX:
+  deploy failed          Both words, anywhere
+  "deploy failed"        Exact phrase
+  deploy*                Words starting with "deploy"
+  subject:deploy         Word or phrase in the subject (body: for the body)
+  from:witness           Sender contains "witness"
+  to:gastown/nux         Recipient or CC contains "gastown/nux"
+  thread:<id>            Messages in a thread
+  type:task              task, scavenge, notification or reply
+  label:ci               Tagged "ci" (by a mail filter rule)
+  priority:high          Exact priority; priority:>=high for high and urgent
+  after:2025-01-02       Sent on or after a date (before: for earlier)
+  after:7d               Relative ages: 30m, 12h, 7d, 2w
+  is:unread              Also is:read, is:archived, is:inbox, is:pinned, is:wisp
+  a OR b, a AND b        Boolean operators (AND is implied)
+  NOT a, -a              Negation
+  ( ... )                Grouping
+
+Put -- before a query that starts with "-".
 
 FLAGS:
-  --from <sender>   Filter by sender address (substring match)
-  --subject         Only search subject lines
-  --body            Only search message body
-  --archive         Include archived (closed) messages
+  --from <sender>   Filter by sender address (same as from:)
+  --subject         Unqualified words match subject lines only
+  --body            Unqualified words match message bodies only
+  --reindex         Rebuild the index before searching
   --json            Output as JSON
 
-By default, searches both subject and body text.
-
 Examples:
-  gt mail search "urgent"                    # Find messages with "urgent"
-  gt mail search "status.*check" --subject   # Regex in subjects only
-  gt mail search "error" --from witness      # From witness, containing "error"
-  gt mail search "handoff" --archive         # Include archived messages
-  gt mail search "" --from mayor/            # All messages from mayor`,
+  gt mail search urgent                          # Messages mentioning "urgent"
+  gt mail search '"merge conflict" is:unread'    # Unread, with the phrase
+  gt mail search 'from:witness (stuck OR zombie)'
+  gt mail search 'type:task priority:>=high after:7d'
+  gt mail search 'handoff -is:archived'          # Inbox only
+  gt mail search "" --from mayor/                # All messages from mayor`,
 	Args: cobra.ExactArgs(1),
 	RunE: runMailSearch,
 }
@@ -465,6 +505,10 @@ func init() {
 	mailSendCmd.Flags().BoolVar(&mailPermanent, "permanent", false, "Send as permanent (not ephemeral, synced to remote)")
 	mailSendCmd.Flags().BoolVar(&mailSendSelf, "self", false, "Send to self (auto-detect from cwd)")
 	mailSendCmd.Flags().StringArrayVar(&mailCC, "cc", nil, "CC recipients (can be used multiple times)")
+	mailSendCmd.Flags().StringVar(&mailSendAt, "at", "", "Deliver at a time (15:04, \"2006-01-02 15:04\", RFC 3339)")
+	mailSendCmd.Flags().StringVar(&mailSendIn, "in", "", "Deliver after a delay (e.g. 2h)")
+	mailSendCmd.Flags().StringVar(&mailSendEvery, "every", "", "Repeat delivery (duration, hourly, daily@HH:MM, weekdays@HH:MM)")
+	mailSendCmd.Flags().StringVar(&mailSendExpires, "expires", "", "Expire after a duration from delivery, or at a time")
 	_ = mailSendCmd.MarkFlagRequired("subject") // cobra flags: error only at runtime if missing
 
 	// Inbox flags
@@ -496,6 +540,9 @@ func init() {
 	mailSearchCmd.Flags().BoolVar(&mailSearchSubject, "subject", false, "Only search subject lines")
 	mailSearchCmd.Flags().BoolVar(&mailSearchBody, "body", false, "Only search message body")
 	mailSearchCmd.Flags().BoolVar(&mailSearchArchive, "archive", false, "Include archived messages")
+	mailSearchCmd.Flags().BoolVar(&mailSearchReindex, "reindex", false, "Rebuild the search index first")
+	// The archive is always searched now; "-is:archived" excludes it.
+	_ = mailSearchCmd.Flags().MarkHidden("archive")
 	mailSearchCmd.Flags().BoolVar(&mailSearchJSON, "json", false, "Output as JSON")
 
 	// Announces flags
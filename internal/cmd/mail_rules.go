@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var (
+	mailRulesIdentity string
+	mailRulesJSON     bool
+)
+
+var mailRulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "Show a mailbox's filter rules",
+	Long: `Show the filter rules applied to mail delivered to a mailbox.
+
+Rules live in ~/gt/config/mail-rules/<identity>.json, with slashes in the
+identity replaced by underscores (gastown/witness → gastown_witness.json).
+They are evaluated in order as each message is delivered; every matching
+rule fires until one with "stop": true.
+
+  {
+    "rules": [
+      {
+        "name": "patrol-noise",
+        "match": {"from": "deacon", "subject": "Patrol*"},
+        "actions": {"archive": true, "labels": ["patrol"]},
+        "stop": true
+      },
+      {
+        "name": "escalations",
+        "match": {"subject": "/^(escalat|blocked)/", "priority": ">=normal"},
+        "actions": {"priority": "urgent", "interrupt": true}
+      },
+      {
+        "name": "merge-requests",
+        "match": {"type": "task", "cc": "*/refinery"},
+        "actions": {"forward": "queue:merges"}
+      }
+    ]
+  }
+
+MATCH (all set conditions must hold):
+  from       Sender address glob (* matches anything)
+  subject    Case-insensitive glob, or /regexp/
+  type       task, scavenge, notification or reply
+  priority   urgent, high, normal, low, or a comparison like >=high
+  cc         Glob matched against each CC'd address
+
+ACTIONS:
+  archive    File straight into the archive (no inbox, no notification)
+  labels     Tag the message (find it with gt mail search label:X)
+  forward    Send a copy to another address or queue:name
+  priority   Raise the message to at least this priority
+  interrupt  Nudge the recipient's session to read it now
+  reply      Auto-reply to the sender: {"subject": "...", "body": "..."}
+
+Forwarded copies and auto-replies skip the rules of their recipients, and
+no mailbox auto-replies to a reply, so rules cannot loop. A rules file that
+fails to parse is ignored at delivery; this command reports the error.
+
+Examples:
+  gt mail rules
+  gt mail rules --identity gastown/witness
+  gt mail rules test hq-abc12`,
+	Args: cobra.NoArgs,
+	RunE: runMailRules,
+}
+
+var mailRulesTestCmd = &cobra.Command{
+	Use:   "test <message-id>",
+	Short: "Explain which rules would fire for a message",
+	Long: `Evaluate the mailbox's filter rules against a message and explain,
+rule by rule, which conditions matched and which actions would run.
+
+The message can be an ID or an inbox index, as with gt mail read. Nothing
+is changed.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMailRulesTest,
+}
+
+func init() {
+	mailRulesCmd.PersistentFlags().StringVar(&mailRulesIdentity, "identity", "", "Mailbox whose rules to use (default: your own)")
+	mailRulesCmd.PersistentFlags().BoolVar(&mailRulesJSON, "json", false, "Output as JSON")
+	mailRulesCmd.AddCommand(mailRulesTestCmd)
+	mailCmd.AddCommand(mailRulesCmd)
+}
+
+// loadMailRules loads the rules for the --identity mailbox or the caller's.
+func loadMailRules() (string, string, *mail.RuleSet, error) {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return "", "", nil, fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	address := mailRulesIdentity
+	if address == "" {
+		address = detectSender()
+	}
+	path := mail.RulesPath(townRoot, address)
+	rs, err := mail.LoadRules(townRoot, address)
+	return address, path, rs, err
+}
+
+func runMailRules(cmd *cobra.Command, args []string) error {
+	address, path, rs, err := loadMailRules()
+	if err != nil {
+		return err
+	}
+
+	if mailRulesJSON {
+		if rs.Rules == nil {
+			rs.Rules = []*mail.Rule{}
+		}
+		data, err := json.MarshalIndent(rs, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(rs.Rules) == 0 {
+		fmt.Printf("No mail rules for %s.\n", address)
+		fmt.Printf("%s\n", style.Dim.Render("Create "+path+" to add some (see gt mail rules --help)."))
+		return nil
+	}
+	fmt.Printf("%s Mail rules for %s: %d\n", style.Bold.Render("📋"), address, len(rs.Rules))
+	fmt.Printf("%s\n\n", style.Dim.Render(path))
+	for i, rule := range rs.Rules {
+		name := rule.Name
+		if rule.Disabled {
+			name += " " + style.Dim.Render("(disabled)")
+		}
+		fmt.Printf("  %d. %s\n", i+1, style.Bold.Render(name))
+		if conds := describeRuleMatch(rule.Match); conds != "" {
+			fmt.Printf("     when %s\n", conds)
+		} else {
+			fmt.Printf("     when %s\n", style.Dim.Render("(every message)"))
+		}
+		then := strings.Join(rule.Actions.Describe(), ", ")
+		if rule.Stop {
+			then += ", then stop"
+		}
+		fmt.Printf("     then %s\n", then)
+	}
+	return nil
+}
+
+func runMailRulesTest(cmd *cobra.Command, args []string) error {
+	address, path, rs, err := loadMailRules()
+	if err != nil {
+		return err
+	}
+	mailbox, err := getMailbox(address)
+	if err != nil {
+		return err
+	}
+
+	msgID := args[0]
+	if idx, err := strconv.Atoi(msgID); err == nil && idx > 0 {
+		messages, err := mailbox.List()
+		if err != nil {
+			return fmt.Errorf("listing messages: %w", err)
+		}
+		if idx > len(messages) {
+			return fmt.Errorf("index %d out of range (inbox has %d messages)", idx, len(messages))
+		}
+		msgID = messages[idx-1].ID
+	}
+	msg, err := mailbox.Get(msgID)
+	if err != nil {
+		return fmt.Errorf("getting message: %w", err)
+	}
+
+	traces := rs.Explain(msg)
+	outcome := rs.Evaluate(msg)
+
+	if mailRulesJSON {
+		out := struct {
+			Message string            `json:"message"`
+			Mailbox string            `json:"mailbox"`
+			Rules   []mail.RuleTrace  `json:"rules"`
+			Outcome *mail.RuleOutcome `json:"outcome"`
+		}{msg.ID, address, traces, outcome}
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Printf("%s %s from %s: %s\n", style.Bold.Render("🧪"), msg.ID, msg.From, msg.Subject)
+	if len(traces) == 0 {
+		fmt.Printf("  No mail rules for %s (%s).\n", address, path)
+		return nil
+	}
+	fmt.Printf("%s\n\n", style.Dim.Render(fmt.Sprintf("Rules for %s (%s)", address, path)))
+	for _, t := range traces {
+		mark := style.Dim.Render("✗")
+		if t.Fired {
+			mark = style.SuccessPrefix
+		}
+		fmt.Printf("  %s %s\n", mark, style.Bold.Render(t.Rule.Name))
+		for _, reason := range t.Reasons {
+			fmt.Printf("      %s\n", style.Dim.Render(reason))
+		}
+		if t.Fired {
+			fmt.Printf("      → %s\n", strings.Join(t.Rule.Actions.Describe(), ", "))
+		}
+	}
+
+	fmt.Println()
+	if outcome == nil {
+		fmt.Println("No rules would fire; the message is delivered unchanged.")
+		return nil
+	}
+	fmt.Printf("%d rule(s) would fire: %s\n", len(outcome.Fired), strings.Join(outcome.Fired, ", "))
+	return nil
+}
+
+// describeRuleMatch renders a rule's conditions on one line.
+func describeRuleMatch(m mail.RuleMatch) string {
+	var conds []string
+	add := func(field, value string) {
+		if value != "" {
+			conds = append(conds, fmt.Sprintf("%s %s", field, value))
+		}
+	}
+	add("from", m.From)
+	add("subject", m.Subject)
+	add("type", string(m.Type))
+	add("priority", m.Priority)
+	add("cc", m.CC)
+	return strings.Join(conds, ", ")
+}
@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/mail"
+)
+
+func TestDescribeRuleMatch(t *testing.T) {
+	got := describeRuleMatch(mail.RuleMatch{From: "*/witness", Priority: ">=high"})
+	if want := "from */witness, priority >=high"; got != want {
+		t.Errorf("describeRuleMatch = %q, want %q", got, want)
+	}
+	if got := describeRuleMatch(mail.RuleMatch{}); got != "" {
+		t.Errorf("empty match = %q", got)
+	}
+}
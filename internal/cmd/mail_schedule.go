@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var mailScheduledJSON bool
+
+var mailScheduledCmd = &cobra.Command{
+	Use:   "scheduled",
+	Short: "List scheduled (deferred and recurring) messages",
+	Long: `List messages waiting for delivery.
+
+Messages sent with --at, --in or --every are held in the town's mail
+schedule (.runtime/mail-schedule.json) and delivered by the daemon when due.
+Recurring messages stay listed with their next delivery time.
+
+Examples:
+  gt mail scheduled
+  gt mail scheduled --json
+  gt mail scheduled cancel sched-1a2b3c4d5e6f`,
+	Args: cobra.NoArgs,
+	RunE: runMailScheduled,
+}
+
+var mailScheduledCancelCmd = &cobra.Command{
+	Use:   "cancel <id>...",
+	Short: "Cancel scheduled messages",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runMailScheduledCancel,
+}
+
+func init() {
+	mailScheduledCmd.Flags().BoolVar(&mailScheduledJSON, "json", false, "Output as JSON")
+	mailScheduledCmd.AddCommand(mailScheduledCancelCmd)
+	mailCmd.AddCommand(mailScheduledCmd)
+}
+
+func runMailScheduled(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	entries, err := mail.NewSchedule(townRoot).List()
+	if err != nil {
+		return fmt.Errorf("reading mail schedule: %w", err)
+	}
+
+	if mailScheduledJSON {
+		if entries == nil {
+			entries = []*mail.ScheduledMessage{}
+		}
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No scheduled messages.")
+		return nil
+	}
+	fmt.Printf("%s Scheduled messages: %d\n\n", style.Bold.Render("⏰"), len(entries))
+	for _, e := range entries {
+		msg := e.Message
+		fmt.Printf("  %s  %s\n", style.Bold.Render(e.ID), msg.Subject)
+		fmt.Printf("    %s → %s, due %s (%s)\n", msg.From, msg.To,
+			msg.DeliverAt.Local().Format("2006-01-02 15:04"), untilString(time.Until(*msg.DeliverAt)))
+		if msg.Recurrence != "" {
+			fmt.Printf("    %s\n", style.Dim.Render(fmt.Sprintf("repeats %s, delivered %d time(s)", msg.Recurrence, e.Deliveries)))
+		}
+		if msg.ExpiresAt != nil {
+			fmt.Printf("    %s\n", style.Dim.Render("expires "+msg.ExpiresAt.Local().Format("2006-01-02 15:04")))
+		}
+		if len(e.Recipients) > 1 || (len(e.Recipients) == 1 && e.Recipients[0] != msg.To) {
+			fmt.Printf("    %s\n", style.Dim.Render("recipients "+strings.Join(e.Recipients, ", ")))
+		}
+		if e.Failed {
+			fmt.Printf("    %s gave up after %d attempts: %s\n", style.WarningPrefix, e.Attempts, e.LastError)
+		} else if e.LastError != "" {
+			fmt.Printf("    %s last delivery failed: %s\n", style.WarningPrefix, e.LastError)
+		}
+	}
+	return nil
+}
+
+func runMailScheduledCancel(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	sched := mail.NewSchedule(townRoot)
+	var failed bool
+	for _, id := range args {
+		e, err := sched.Cancel(id)
+		if err != nil {
+			if errors.Is(err, mail.ErrScheduleNotFound) {
+				fmt.Printf("%s %s: not scheduled\n", style.ErrorPrefix, id)
+			} else {
+				fmt.Printf("%s %s: %v\n", style.ErrorPrefix, id, err)
+			}
+			failed = true
+			continue
+		}
+		fmt.Printf("%s Cancelled %s (%s → %s)\n", style.SuccessPrefix, id, e.Message.Subject, e.Message.To)
+	}
+	if failed {
+		return NewSilentExit(1)
+	}
+	return nil
+}
+
+// untilString describes a time remaining, or that a message is overdue
+// (waiting for the daemon).
+func untilString(d time.Duration) string {
+	if d <= 0 {
+		return "due now"
+	}
+	return "in " + formatDuration(d.Round(time.Minute))
+}
+
+// applyMailTiming sets the delivery, expiry and recurrence fields from the
+// send flags and reports whether the message must be scheduled rather than
+// sent now.
+func applyMailTiming(msg *mail.Message, now time.Time) (bool, error) {
+	if mailSendAt != "" && mailSendIn != "" {
+		return false, fmt.Errorf("use either --at or --in, not both")
+	}
+	if mailSendAt != "" {
+		at, err := parseMailTime(mailSendAt, now)
+		if err != nil {
+			return false, fmt.Errorf("--at: %w", err)
+		}
+		msg.DeliverAt = &at
+	}
+	if mailSendIn != "" {
+		d, err := time.ParseDuration(mailSendIn)
+		if err != nil || d < 0 {
+			return false, fmt.Errorf("--in: invalid duration %q", mailSendIn)
+		}
+		at := now.Add(d)
+		msg.DeliverAt = &at
+	}
+	if mailSendEvery != "" {
+		if _, err := mail.ParseRecurrence(mailSendEvery); err != nil {
+			return false, fmt.Errorf("--every: %w", err)
+		}
+		msg.Recurrence = mailSendEvery
+	}
+	if mailSendExpires != "" {
+		// A duration counts from delivery (the first one, for recurring mail;
+		// the schedule keeps the offset for later ones).
+		base := now
+		if msg.DeliverAt != nil {
+			base = *msg.DeliverAt
+		} else if msg.Recurrence != "" {
+			if rec, _ := mail.ParseRecurrence(msg.Recurrence); rec != nil && rec.Daily() {
+				base = rec.Next(now)
+			}
+		}
+		var exp time.Time
+		if d, err := time.ParseDuration(mailSendExpires); err == nil {
+			if d <= 0 {
+				return false, fmt.Errorf("--expires: duration must be positive")
+			}
+			exp = base.Add(d)
+		} else {
+			exp, err = parseMailTime(mailSendExpires, now)
+			if err != nil {
+				return false, fmt.Errorf("--expires: %w", err)
+			}
+		}
+		msg.ExpiresAt = &exp
+	}
+	scheduled := msg.Recurrence != "" || (msg.DeliverAt != nil && msg.DeliverAt.After(now))
+	return scheduled, nil
+}
+
+// parseMailTime parses a delivery or expiry time: a time of day (15:04,
+// the next one after now), a local date and time, or RFC 3339.
+func parseMailTime(s string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	if t, err := time.ParseInLocation("15:04", s, time.Local); err == nil {
+		now = now.Local()
+		at := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, time.Local)
+		if !at.After(now) {
+			at = at.AddDate(0, 0, 1)
+		}
+		return at, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (use 15:04, \"2006-01-02 15:04\" or RFC 3339)", s)
+}
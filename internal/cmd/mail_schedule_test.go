@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/mail"
+)
+
+func TestParseMailTime(t *testing.T) {
+	now := time.Date(2025, 6, 6, 14, 0, 0, 0, time.Local)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"15:30", time.Date(2025, 6, 6, 15, 30, 0, 0, time.Local)},
+		{"09:00", time.Date(2025, 6, 7, 9, 0, 0, 0, time.Local)}, // Already passed today
+		{"2025-07-01 08:15", time.Date(2025, 7, 1, 8, 15, 0, 0, time.Local)},
+		{"2025-07-01", time.Date(2025, 7, 1, 0, 0, 0, 0, time.Local)},
+		{"2025-07-01T08:15:00Z", time.Date(2025, 7, 1, 8, 15, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parseMailTime(tt.in, now)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("parseMailTime(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := parseMailTime("tomorrow", now); err == nil {
+		t.Error("parseMailTime(tomorrow) should fail")
+	}
+}
+
+func TestApplyMailTiming(t *testing.T) {
+	now := time.Date(2025, 6, 6, 14, 0, 0, 0, time.Local)
+	set := func(at, in, every, expires string) {
+		mailSendAt, mailSendIn, mailSendEvery, mailSendExpires = at, in, every, expires
+	}
+	defer set("", "", "", "")
+
+	set("", "2h", "", "30m")
+	msg := &mail.Message{}
+	scheduled, err := applyMailTiming(msg, now)
+	if err != nil || !scheduled {
+		t.Fatalf("--in: scheduled=%v err=%v", scheduled, err)
+	}
+	if !msg.DeliverAt.Equal(now.Add(2*time.Hour)) || !msg.ExpiresAt.Equal(now.Add(150*time.Minute)) {
+		t.Errorf("--in 2h --expires 30m: deliver %v, expires %v", msg.DeliverAt, msg.ExpiresAt)
+	}
+
+	set("", "", "", "30m")
+	msg = &mail.Message{}
+	if scheduled, err := applyMailTiming(msg, now); err != nil || scheduled {
+		t.Errorf("--expires alone should send now: scheduled=%v err=%v", scheduled, err)
+	}
+
+	set("", "", "daily@09:00", "1h")
+	msg = &mail.Message{}
+	if scheduled, err := applyMailTiming(msg, now); err != nil || !scheduled {
+		t.Fatalf("--every: scheduled=%v err=%v", scheduled, err)
+	}
+	if want := time.Date(2025, 6, 7, 10, 0, 0, 0, time.Local); !msg.ExpiresAt.Equal(want) {
+		t.Errorf("daily expiry = %v, want %v (an hour after the first delivery)", msg.ExpiresAt, want)
+	}
+
+	set("10:00", "1h", "", "")
+	if _, err := applyMailTiming(&mail.Message{}, now); err == nil {
+		t.Error("--at with --in should fail")
+	}
+	set("", "", "5s", "")
+	if _, err := applyMailTiming(&mail.Message{}, now); err == nil {
+		t.Error("too-short --every should fail")
+	}
+}
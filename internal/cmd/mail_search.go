@@ -36,6 +36,7 @@ func runMailSearch(cmd *cobra.Command, args []string) error {
 		FromFilter:  mailSearchFrom,
 		SubjectOnly: mailSearchSubject,
 		BodyOnly:    mailSearchBody,
+		Reindex:     mailSearchReindex,
 	}
 
 	// Execute search
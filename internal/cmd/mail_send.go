@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
@@ -123,6 +124,37 @@ func runMailSend(cmd *cobra.Command, args []string) error {
 		msg.ThreadID = generateThreadID()
 	}
 
+	// Deferred and recurring mail goes to the schedule, which resolves
+	// recipients now and stores the concrete addresses.
+	scheduled, err := applyMailTiming(msg, time.Now())
+	if err != nil {
+		return err
+	}
+	if scheduled {
+		entry, err := mail.NewRouter(workDir).Schedule(msg)
+		if err != nil {
+			return fmt.Errorf("scheduling message: %w", err)
+		}
+		if entry.Deliveries > 0 {
+			fmt.Printf("%s Message sent to %s\n", style.Bold.Render("✓"), to)
+		}
+		if entry.LastError != "" {
+			fmt.Fprintf(os.Stderr, "⚠ Some deliveries failed and will be retried: %s\n", entry.LastError)
+		}
+		if msg.Recurrence != "" || entry.Deliveries == 0 {
+			fmt.Printf("%s Scheduled %s for %s\n", style.Bold.Render("⏰"), entry.ID,
+				entry.Message.DeliverAt.Local().Format("2006-01-02 15:04"))
+		}
+		fmt.Printf("  Subject: %s\n", mailSubject)
+		if len(entry.Recipients) > 1 || (len(entry.Recipients) == 1 && entry.Recipients[0] != to) {
+			fmt.Printf("  Recipients: %s\n", strings.Join(entry.Recipients, ", "))
+		}
+		if msg.Recurrence != "" {
+			fmt.Printf("  Repeats: %s\n", msg.Recurrence)
+		}
+		return nil
+	}
+
 	// Use address resolver for new address types
 	townRoot, _ := workspace.FindFromCwd()
 	b := beads.New(townRoot)
@@ -117,7 +117,7 @@ func runMoleculeDag(cmd *cobra.Command, args []string) error {
 }
 
 // buildDAG constructs the DAG from molecule children.
-func buildDAG(b *beads.Beads, root *beads.Issue, children []*beads.Issue) (*DAGInfo, error) {
+func buildDAG(b beads.Store, root *beads.Issue, children []*beads.Issue) (*DAGInfo, error) {
 	dag := &DAGInfo{
 		RootID:    root.ID,
 		RootTitle: root.Title,
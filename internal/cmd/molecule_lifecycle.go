@@ -277,10 +277,11 @@ squashed_at: %s
 
 // closeDescendants recursively closes all descendant issues of a parent.
 // Returns the count of issues closed. Logs warnings on errors but doesn't fail.
-func closeDescendants(b *beads.Beads, parentID string) int {
+func closeDescendants(b beads.Store, parentID string) int {
 	children, err := b.List(beads.ListOptions{
-		Parent: parentID,
-		Status: "all",
+		Parent:   parentID,
+		Status:   "all",
+		Priority: -1,
 	})
 	if err != nil {
 		style.PrintWarning("could not list children of %s: %v", parentID, err)
@@ -116,6 +116,7 @@ func runMoleculeStepDone(cmd *cobra.Command, args []string) error {
 		}
 		result.StepClosed = true
 		fmt.Printf("%s Closed step %s: %s\n", style.Bold.Render("✓"), stepID, step.Title)
+		traceMoleculeStep(cwd, townRoot, step, moleculeID)
 	}
 
 	// Step 4: Find all ready steps (supports fan-out pattern)
@@ -202,7 +203,7 @@ func extractMoleculeIDFromStep(stepID string) string {
 // Returns (nextStep, allComplete, error).
 // If all steps are complete, returns (nil, true, nil).
 // If no steps are ready but some are blocked/in_progress, returns (nil, false, nil).
-func findNextReadyStep(b *beads.Beads, moleculeID string) (*beads.Issue, bool, error) {
+func findNextReadyStep(b beads.Store, moleculeID string) (*beads.Issue, bool, error) {
 	// Get all children of the molecule
 	children, err := b.List(beads.ListOptions{
 		Parent:   moleculeID,
@@ -290,7 +291,7 @@ func findNextReadyStep(b *beads.Beads, moleculeID string) (*beads.Issue, bool, e
 // Returns (readySteps, allComplete, error).
 // If all steps are complete, returns (nil, true, nil).
 // If no steps are ready but some are blocked/in_progress, returns (nil, false, nil).
-func findAllReadySteps(b *beads.Beads, moleculeID string) ([]*beads.Issue, bool, error) {
+func findAllReadySteps(b beads.Store, moleculeID string) ([]*beads.Issue, bool, error) {
 	// Get all children of the molecule
 	children, err := b.List(beads.ListOptions{
 		Parent:   moleculeID,
@@ -717,3 +717,39 @@ func TestOldBuggyBehavior(t *testing.T) {
 
 	t.Log("Old buggy behavior confirmed: both steps marked ready when only step 1 should be")
 }
+
+// TestFindReadyStepsWithStore runs the real step finders against an
+// in-memory store that reports dependencies the way bd does.
+func TestFindReadyStepsWithStore(t *testing.T) {
+	store := beads.NewMemStore("gt")
+	store.Add(
+		&beads.Issue{ID: "gt-mol", Title: "Molecule"},
+		makeStepIssue("gt-mol.1", "Step A", "gt-mol", "closed", nil),
+		makeStepIssue("gt-mol.2", "Step B", "gt-mol", "open", []string{"gt-mol.1"}),
+		makeStepIssue("gt-mol.3", "Step C", "gt-mol", "open", []string{"gt-mol.1"}),
+		makeStepIssue("gt-mol.4", "Synthesis", "gt-mol", "open", []string{"gt-mol.2", "gt-mol.3"}),
+	)
+
+	step, complete, err := findNextReadyStep(store, "gt-mol")
+	if err != nil || complete || step == nil || step.ID != "gt-mol.2" {
+		t.Fatalf("findNextReadyStep = %v, %v, %v", step, complete, err)
+	}
+	steps, _, err := findAllReadySteps(store, "gt-mol")
+	if err != nil || len(steps) != 2 {
+		t.Fatalf("findAllReadySteps = %v, %v", steps, err)
+	}
+
+	if err := store.Close("gt-mol.2", "gt-mol.3"); err != nil {
+		t.Fatal(err)
+	}
+	if step, _, _ := findNextReadyStep(store, "gt-mol"); step == nil || step.ID != "gt-mol.4" {
+		t.Fatalf("after closing B and C, next step = %v", step)
+	}
+
+	if n := closeDescendants(store, "gt-mol"); n != 1 {
+		t.Errorf("closeDescendants closed %d, want 1", n)
+	}
+	if _, complete, _ := findNextReadyStep(store, "gt-mol"); !complete {
+		t.Error("molecule should be complete")
+	}
+}
@@ -538,11 +538,12 @@ func runMqIntegrationLand(cmd *cobra.Command, args []string) error {
 }
 
 // findOpenMRsForIntegration finds all open merge requests targeting an integration branch.
-func findOpenMRsForIntegration(bd *beads.Beads, targetBranch string) ([]*beads.Issue, error) {
+func findOpenMRsForIntegration(bd beads.Store, targetBranch string) ([]*beads.Issue, error) {
 	// List all open merge requests
 	opts := beads.ListOptions{
-		Type:   "merge-request",
-		Status: "open",
+		Type:     "merge-request",
+		Status:   "open",
+		Priority: -1,
 	}
 	allMRs, err := bd.List(opts)
 	if err != nil {
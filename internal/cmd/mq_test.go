@@ -801,3 +801,31 @@ func TestMRFilteringByLabel(t *testing.T) {
 		})
 	}
 }
+
+func TestFindOpenMRsForIntegration(t *testing.T) {
+	store := beads.NewMemStore("gt")
+	for i, target := range []string{"integration/gt-epic", "main", "integration/gt-epic"} {
+		mr, err := store.Create(beads.CreateOptions{
+			Title:       "Merge",
+			Type:        "merge-request",
+			Priority:    i, // Any priority counts
+			Description: beads.FormatMRFields(&beads.MRFields{Branch: "polecat/nux", Target: target}),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 2 {
+			if err := store.Close(mr.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	got, err := findOpenMRsForIntegration(store, "integration/gt-epic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].ID != "gt-1" {
+		t.Errorf("findOpenMRsForIntegration = %v, want [gt-1]", got)
+	}
+}
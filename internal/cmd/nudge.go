@@ -10,6 +10,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/eventbus"
 	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/style"
@@ -188,11 +189,8 @@ func runNudge(cmd *cobra.Command, args []string) error {
 
 		fmt.Printf("%s Nudged deacon\n", style.Bold.Render("✓"))
 
-		// Log nudge event
-		if townRoot, err := workspace.FindFromCwd(); err == nil && townRoot != "" {
-			_ = LogNudge(townRoot, "deacon", message)
-		}
-		_ = events.LogFeed(events.TypeNudge, sender, events.NudgePayload("", "deacon", message))
+		// Log nudge event (townlog and activity feed)
+		logNudgeEvent(sender, "", "deacon", message)
 		return nil
 	}
 
@@ -234,11 +232,8 @@ func runNudge(cmd *cobra.Command, args []string) error {
 
 		fmt.Printf("%s Nudged %s/%s\n", style.Bold.Render("✓"), rigName, polecatName)
 
-		// Log nudge event
-		if townRoot, err := workspace.FindFromCwd(); err == nil && townRoot != "" {
-			_ = LogNudge(townRoot, target, message)
-		}
-		_ = events.LogFeed(events.TypeNudge, sender, events.NudgePayload(rigName, target, message))
+		// Log nudge event (townlog and activity feed)
+		logNudgeEvent(sender, rigName, target, message)
 	} else {
 		// Raw session name (legacy)
 		exists, err := t.HasSession(target)
@@ -255,11 +250,8 @@ func runNudge(cmd *cobra.Command, args []string) error {
 
 		fmt.Printf("✓ Nudged %s\n", target)
 
-		// Log nudge event
-		if townRoot, err := workspace.FindFromCwd(); err == nil && townRoot != "" {
-			_ = LogNudge(townRoot, target, message)
-		}
-		_ = events.LogFeed(events.TypeNudge, sender, events.NudgePayload("", target, message))
+		// Log nudge event (townlog and activity feed)
+		logNudgeEvent(sender, "", target, message)
 	}
 
 	return nil
@@ -525,3 +517,14 @@ func addressToAgentBeadID(address string) string {
 		return fmt.Sprintf("gt-%s-polecat-%s", rig, role)
 	}
 }
+
+// logNudgeEvent records a nudge in both the town log and the activity feed.
+func logNudgeEvent(sender, rigName, target, message string) {
+	_ = eventbus.Publish("", eventbus.Event{
+		Type:    eventbus.TypeNudge,
+		Actor:   sender,
+		Agent:   target,
+		Context: strings.TrimSpace(message),
+		Payload: events.NudgePayload(rigName, target, message),
+	})
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/cgroup"
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/polecat"
 	"github.com/steveyegge/gastown/internal/rig"
@@ -138,6 +139,8 @@ Displays comprehensive information including:
   - Session status (running/stopped, attached/detached)
   - Session creation time
   - Last activity time
+  - Resource usage and limits (memory, CPU weight, pids) when the
+    polecat role configures [resources]
 
 Examples:
   gt polecat status greenplace/Toast
@@ -585,6 +588,7 @@ type PolecatStatus struct {
 	Windows        int           `json:"windows,omitempty"`
 	CreatedAt      string        `json:"created_at,omitempty"`
 	LastActivity   string        `json:"last_activity,omitempty"`
+	Resources      *cgroup.Usage `json:"resources,omitempty"`
 }
 
 func runPolecatStatus(cmd *cobra.Command, args []string) error {
@@ -629,6 +633,7 @@ func runPolecatStatus(cmd *cobra.Command, args []string) error {
 			SessionID:      sessInfo.SessionID,
 			Attached:       sessInfo.Attached,
 			Windows:        sessInfo.Windows,
+			Resources:      sessInfo.Resources,
 		}
 		if !sessInfo.Created.IsZero() {
 			status.CreatedAt = sessInfo.Created.Format("2006-01-02 15:04:05")
@@ -698,6 +703,10 @@ func runPolecatStatus(cmd *cobra.Command, args []string) error {
 				sessInfo.LastActivity.Format("15:04:05"),
 				style.Dim.Render(ago))
 		}
+
+		if res := sessInfo.Resources; res != nil && res.Limited() {
+			printResourceUsage(res)
+		}
 	} else {
 		fmt.Printf("  Status:        %s\n", style.Dim.Render("not running"))
 	}
@@ -705,6 +714,36 @@ func runPolecatStatus(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// printResourceUsage prints cgroup usage against configured limits.
+func printResourceUsage(res *cgroup.Usage) {
+	fmt.Println()
+	fmt.Printf("%s\n", style.Bold.Render("Resources"))
+
+	mem := cgroup.FormatBytes(res.MemoryCurrent)
+	if res.MemoryMax > 0 {
+		mem += " / " + cgroup.FormatBytes(res.MemoryMax)
+		if res.MemoryCurrent*10 >= res.MemoryMax*9 {
+			mem = style.Warning.Render(mem)
+		}
+	}
+	fmt.Printf("  Memory:        %s\n", mem)
+
+	if res.CPUWeight > 0 {
+		fmt.Printf("  CPU Weight:    %d\n", res.CPUWeight)
+	}
+
+	pids := fmt.Sprintf("%d", res.PidsCurrent)
+	if res.PidsMax > 0 {
+		pids += fmt.Sprintf(" / %d", res.PidsMax)
+	}
+	fmt.Printf("  Pids:          %s\n", pids)
+
+	if res.OOMKills > 0 {
+		fmt.Printf("  OOM Kills:     %s\n", style.Error.Render(fmt.Sprintf("%d", res.OOMKills)))
+	}
+	fmt.Printf("  Cgroup:        %s\n", style.Dim.Render(res.Path))
+}
+
 // formatActivityTime returns a human-readable relative time string.
 func formatActivityTime(t time.Time) string {
 	d := time.Since(t)
@@ -17,6 +17,7 @@ import (
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tmux"
+	"github.com/steveyegge/gastown/internal/tracing"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -29,8 +30,9 @@ type SpawnedPolecatInfo struct {
 	Pane        string // Tmux pane ID (empty until StartSession is called)
 
 	// Internal fields for deferred session start
-	account string
-	agent   string
+	account  string
+	agent    string
+	hookBead string // Traced bead, if spawned for one
 }
 
 // AgentID returns the agent identifier (e.g., "gastown/polecats/Toast")
@@ -55,7 +57,11 @@ type SlingSpawnOptions struct {
 // SpawnPolecatForSling creates a fresh polecat and optionally starts its session.
 // This is used by gt sling when the target is a rig name.
 // The caller (sling) handles hook attachment and nudging.
-func SpawnPolecatForSling(rigName string, opts SlingSpawnOptions) (*SpawnedPolecatInfo, error) {
+func SpawnPolecatForSling(rigName string, opts SlingSpawnOptions) (_ *SpawnedPolecatInfo, retErr error) {
+	span := tracing.Start("polecat.spawn", opts.HookBead)
+	span.SetAttr("rig", rigName)
+	defer func() { span.End(retErr) }()
+
 	// Find workspace
 	townRoot, err := workspace.FindFromCwdOrError()
 	if err != nil {
@@ -178,6 +184,7 @@ func SpawnPolecatForSling(rigName string, opts SlingSpawnOptions) (*SpawnedPolec
 		Pane:        "", // Empty until StartSession is called
 		account:     opts.Account,
 		agent:       opts.Agent,
+		hookBead:    opts.HookBead,
 	}, nil
 }
 
@@ -185,11 +192,15 @@ func SpawnPolecatForSling(rigName string, opts SlingSpawnOptions) (*SpawnedPolec
 // This is called after the molecule/bead is attached, so the polecat
 // sees its work when gt prime runs on session start.
 // Returns the pane ID after session start.
-func (s *SpawnedPolecatInfo) StartSession() (string, error) {
+func (s *SpawnedPolecatInfo) StartSession() (_ string, retErr error) {
 	if s.SessionStarted() {
 		return s.Pane, nil
 	}
 
+	span := tracing.Start("polecat.start_session", s.hookBead)
+	span.SetAttr("polecat", s.AgentID())
+	defer func() { span.End(retErr) }()
+
 	townRoot, err := workspace.FindFromCwdOrError()
 	if err != nil {
 		return "", fmt.Errorf("not in a Gas Town workspace: %w", err)
@@ -231,13 +242,19 @@ func (s *SpawnedPolecatInfo) StartSession() (string, error) {
 		}
 		startOpts.Command = cmd
 	}
-	if err := polecatSessMgr.Start(s.PolecatName, startOpts); err != nil {
+	startSpan := tracing.Start("session.start", s.hookBead)
+	err = polecatSessMgr.Start(s.PolecatName, startOpts)
+	startSpan.End(err)
+	if err != nil {
 		return "", fmt.Errorf("starting session: %w", err)
 	}
 
 	// Wait for runtime to be fully ready before returning.
 	runtimeConfig := config.LoadRuntimeConfig(r.Path)
-	if err := t.WaitForRuntimeReady(s.SessionName, runtimeConfig, 30*time.Second); err != nil {
+	readySpan := tracing.Start("runtime.wait_ready", s.hookBead)
+	err = t.WaitForRuntimeReady(s.SessionName, runtimeConfig, 30*time.Second)
+	readySpan.End(err)
+	if err != nil {
 		fmt.Printf("Warning: runtime may not be fully ready: %v\n", err)
 	}
 
@@ -188,7 +188,7 @@ func parseMoleculeMetadata(description string) string {
 }
 
 // showMoleculeProgress displays the progress through a molecule's steps.
-func showMoleculeProgress(b *beads.Beads, rootID string) {
+func showMoleculeProgress(b beads.Store, rootID string) {
 	if rootID == "" {
 		return
 	}
@@ -3,6 +3,7 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -435,7 +436,7 @@ func runRigAdd(cmd *cobra.Command, args []string) error {
 	}
 
 	// Sync hooks for the new rig's targets
-	if err := syncRigHooks(townRoot, name); err != nil {
+	if err := syncRigHooks(os.Stdout, townRoot, name); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: failed to sync hooks for new rig: %v\n", err)
 	}
 
@@ -717,7 +718,7 @@ func runRigReset(cmd *cobra.Command, args []string) error {
 }
 
 // runResetStale resets in_progress issues whose assigned agent no longer has a session.
-func runResetStale(bd *beads.Beads, dryRun bool) error {
+func runResetStale(bd beads.Store, dryRun bool) error {
 	t := tmux.NewTmux()
 
 	// Get all in_progress issues
@@ -1649,8 +1650,9 @@ func getRigOperationalState(townRoot, rigName string) (state string, source stri
 	return "OPERATIONAL", "default"
 }
 
-// syncRigHooks syncs hooks for a specific rig's targets after rig creation.
-func syncRigHooks(townRoot, rigName string) error {
+// syncRigHooks syncs hooks for a specific rig's targets after rig creation,
+// reporting progress to out.
+func syncRigHooks(out io.Writer, townRoot, rigName string) error {
 	targets, err := hooks.DiscoverTargets(townRoot)
 	if err != nil {
 		return err
@@ -1669,7 +1671,7 @@ func syncRigHooks(townRoot, rigName string) error {
 	}
 
 	if synced > 0 {
-		fmt.Printf("  Synced hooks for %d target(s)\n", synced)
+		fmt.Fprintf(out, "  Synced hooks for %d target(s)\n", synced)
 	}
 	return nil
 }
@@ -0,0 +1,284 @@
+// Package cmd provides CLI commands for the gt tool.
+// This file implements gt rig apply and gt rig export, which provision a rig
+// from a declarative manifest and write one from an existing rig.
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var (
+	rigApplyDryRun        bool
+	rigApplyJSON          bool
+	rigExportFormat       string
+	rigExportOutput       string
+	rigExportWithOverlays bool
+)
+
+var rigApplyCmd = &cobra.Command{
+	Use:   "apply <manifest>",
+	Short: "Create or update a rig from a manifest",
+	Long: `Create or converge a rig to a manifest (TOML, or JSON for *.json files).
+
+A manifest declares the rig's repository, settings, crew, setup hooks,
+overlay files, plugins and Claude hook overrides in one file. If the rig
+doesn't exist it is created with 'gt rig add'; then everything the manifest
+declares that differs from the rig is created or updated. Applying the same
+manifest again changes nothing.
+
+Manifests are additive: crew members, files and settings keys the manifest
+doesn't mention are left alone. Each top-level settings key replaces the
+rig's value for that key.
+
+git_url, prefix, default_branch and local_repo are fixed once the rig
+exists. If the manifest sets them differently, apply reports the drift,
+applies everything else and exits non-zero.
+
+  name = "gastown"
+  git_url = "https://github.com/steveyegge/gastown"
+  prefix = "gt"
+  default_formula = "shiny"
+  crew = ["max", "joe"]
+
+  [role_agents]
+  polecat = "codex"
+
+  [settings.merge_queue]
+  enabled = true
+  test_command = "go test ./..."
+
+  [settings.namepool]
+  style = "minerals"
+
+  [[setup_hooks]]              # .runtime/setup-hooks/ (mode 0755)
+  name = "01-git-config.sh"
+  content = """
+  #!/bin/sh
+  git config user.name polecat
+  """
+
+  [[overlay]]                  # .runtime/overlay/ (mode 0644 unless set)
+  name = ".env"
+  mode = "0600"
+  content = "API_URL=http://localhost:8080\n"
+
+  [[plugins]]                  # plugins/<name>/plugin.md
+  name = "lint"
+  content = "..."
+
+  [[hooks.crew.SessionStart]]  # ~/.gt/hooks-overrides/gastown__crew.json
+  matcher = ""
+  hooks = [{ type = "command", command = "gt prime" }]
+
+Examples:
+  gt rig apply gastown.rig.toml --dry-run   # Show what would change
+  gt rig apply gastown.rig.toml
+  gt rig export gastown > gastown.rig.toml  # Start from an existing rig`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRigApply,
+}
+
+var rigExportCmd = &cobra.Command{
+	Use:   "export <rig>",
+	Short: "Write a manifest for an existing rig",
+	Long: `Write a manifest describing an existing rig, for 'gt rig apply'.
+
+The manifest covers the rig's repository, settings, crew, setup hooks,
+plugins and Claude hook overrides. Overlay files usually hold secrets, so
+their contents are only included with --include-overlay.
+
+Examples:
+  gt rig export gastown                          # TOML to stdout
+  gt rig export gastown --format json -o gastown.rig.json
+  gt rig export gastown --include-overlay`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRigExport,
+}
+
+func init() {
+	rigApplyCmd.Flags().BoolVarP(&rigApplyDryRun, "dry-run", "n", false, "Show the changes without making them")
+	rigApplyCmd.Flags().BoolVar(&rigApplyJSON, "json", false, "Output the plan as JSON")
+
+	rigExportCmd.Flags().StringVar(&rigExportFormat, "format", "toml", "Manifest format: toml or json")
+	rigExportCmd.Flags().StringVarP(&rigExportOutput, "output", "o", "", "Write to a file instead of stdout")
+	rigExportCmd.Flags().BoolVar(&rigExportWithOverlays, "include-overlay", false, "Include overlay file contents (may contain secrets)")
+
+	rigCmd.AddCommand(rigApplyCmd)
+	rigCmd.AddCommand(rigExportCmd)
+}
+
+func runRigApply(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	m, err := rig.LoadManifest(args[0])
+	if err != nil {
+		return err
+	}
+	plan, err := rig.PlanManifest(townRoot, m)
+	if err != nil {
+		return err
+	}
+
+	if rigApplyJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(plan); err != nil {
+			return err
+		}
+	} else {
+		printManifestPlan(plan, rigApplyDryRun)
+	}
+	drifted := len(plan.Drift) > 0
+	if rigApplyDryRun || len(plan.Changes) == 0 {
+		if drifted {
+			return NewSilentExit(1)
+		}
+		return nil
+	}
+
+	// With --json, stdout carries only the plan; child output and
+	// warnings go to stderr.
+	out := io.Writer(os.Stdout)
+	if rigApplyJSON {
+		out = os.Stderr
+	}
+
+	if plan.CreatesRig() {
+		addArgs := []string{"rig", "add", m.Name, m.GitURL}
+		if m.Prefix != "" {
+			addArgs = append(addArgs, "--prefix", m.Prefix)
+		}
+		if m.DefaultBranch != "" {
+			addArgs = append(addArgs, "--branch", m.DefaultBranch)
+		}
+		if m.LocalRepo != "" {
+			addArgs = append(addArgs, "--local-repo", m.LocalRepo)
+		}
+		if err := runGT(out, townRoot, addArgs...); err != nil {
+			return fmt.Errorf("gt rig add failed: %w", err)
+		}
+		// Plan the rest against the rig as created.
+		if plan, err = rig.PlanManifest(townRoot, m); err != nil {
+			return err
+		}
+	}
+
+	if err := plan.WriteFiles(); err != nil {
+		return err
+	}
+
+	failed := false
+	syncHooks := false
+	for _, c := range plan.Changes {
+		switch c.Kind {
+		case "crew":
+			if err := runGT(out, filepath.Join(townRoot, m.Name), "crew", "add", c.Name, "--rig", m.Name); err != nil {
+				applyWarning("could not create crew %s: %v", c.Name, err)
+				failed = true
+			}
+		case "hooks":
+			syncHooks = true
+		}
+	}
+	if syncHooks {
+		if err := syncRigHooks(out, townRoot, m.Name); err != nil {
+			applyWarning("could not sync hooks: %v", err)
+		}
+	}
+
+	if failed || drifted {
+		return NewSilentExit(1)
+	}
+	if !rigApplyJSON {
+		fmt.Printf("%s Rig %s matches %s\n", style.Success.Render("✓"), m.Name, args[0])
+	}
+	return nil
+}
+
+// runGT runs a gt subcommand in dir, passing its output through.
+// applyWarning prints a warning, on stderr when stdout carries the JSON plan.
+func applyWarning(format string, args ...interface{}) {
+	if rigApplyJSON {
+		fmt.Fprintf(os.Stderr, "Warning: "+format+"\n", args...)
+		return
+	}
+	style.PrintWarning(format, args...)
+}
+
+// runGT runs a gt subcommand in dir, sending its output to out.
+func runGT(out io.Writer, dir string, args ...string) error {
+	c := exec.Command("gt", args...)
+	c.Dir = dir
+	c.Stdout = out
+	c.Stderr = os.Stderr
+	return c.Run()
+}
+
+func printManifestPlan(plan *rig.ManifestPlan, dryRun bool) {
+	for _, d := range plan.Drift {
+		style.PrintWarning("%s is %q but the manifest has %q; apply can't change it", d.Field, d.Current, d.Manifest)
+	}
+	if len(plan.Changes) == 0 {
+		if len(plan.Drift) == 0 {
+			fmt.Printf("%s Rig %s already matches the manifest\n", style.Success.Render("✓"), plan.Rig)
+		}
+		return
+	}
+	verb := "Applying"
+	if dryRun {
+		verb = "Would apply"
+	}
+	fmt.Printf("%s %s %d change(s) to %s\n", style.Bold.Render("→"), verb, len(plan.Changes), plan.Rig)
+	for _, c := range plan.Changes {
+		marker := "+"
+		if c.Action == "update" {
+			marker = "~"
+		}
+		fmt.Printf("  %s %s %s %s\n", marker, c.Action, c.Kind, c.Name)
+		if dryRun && c.Diff != "" {
+			for _, line := range strings.Split(strings.TrimSuffix(c.Diff, "\n"), "\n") {
+				fmt.Printf("      %s\n", style.Dim.Render(line))
+			}
+		}
+	}
+}
+
+func runRigExport(cmd *cobra.Command, args []string) error {
+	townRoot, r, err := getRig(args[0])
+	if err != nil {
+		return err
+	}
+	if rigExportFormat != "toml" && rigExportFormat != "json" {
+		return fmt.Errorf("unknown format %q (want toml or json)", rigExportFormat)
+	}
+	m, err := rig.ExportManifest(townRoot, r.Name, rigExportWithOverlays)
+	if err != nil {
+		return err
+	}
+	data, err := m.Encode(rigExportFormat)
+	if err != nil {
+		return fmt.Errorf("encoding manifest: %w", err)
+	}
+	if rigExportOutput == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(rigExportOutput, data, 0644); err != nil { //nolint:gosec // G306: manifest is meant to be shared
+		return fmt.Errorf("writing manifest: %w", err)
+	}
+	fmt.Printf("%s Wrote %s\n", style.Success.Render("✓"), rigExportOutput)
+	return nil
+}
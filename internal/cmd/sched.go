@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/daemon"
+	"github.com/steveyegge/gastown/internal/scheduler"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var schedStatusJSON bool
+
+var schedCmd = &cobra.Command{
+	Use:     "sched",
+	GroupID: GroupServices,
+	Short:   "Town-wide work scheduler",
+	RunE:    requireSubcommand,
+	Long: `Inspect the daemon's town-wide work scheduler.
+
+When enabled, the daemon periodically pulls ready beads (bd ready) from
+every rig and slings them to polecats, respecting:
+  - each rig's max_polecats quota
+  - an optional town-wide polecat cap
+  - Dolt server connection capacity
+  - bead priority (P0 first), with the least-loaded rig winning ties
+
+The scheduler is off by default. Enable it in mayor/daemon.json:
+
+  "patrols": {
+    "scheduler": {
+      "enabled": true,
+      "interval": "1m",
+      "town_max_polecats": 12,
+      "max_dispatch_per_cycle": 3,
+      "label": "auto",
+      "rigs": ["gastown", "beads"]
+    }
+  }
+
+label and rigs are optional filters.`,
+}
+
+var schedStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the scheduling queue and why work is waiting",
+	Long: `Show the current scheduling queue.
+
+Computes a fresh plan (without dispatching anything) and lists the beads
+that would be slung next and, for every other ready bead, the reason it
+is waiting: rig quota full, town cap reached, Dolt at capacity, rig
+parked, or the per-cycle dispatch limit. Also shows the daemon's last
+scheduler cycle.`,
+	RunE: runSchedStatus,
+}
+
+func init() {
+	schedStatusCmd.Flags().BoolVar(&schedStatusJSON, "json", false, "Output as JSON")
+	schedCmd.AddCommand(schedStatusCmd)
+	rootCmd.AddCommand(schedCmd)
+}
+
+// SchedStatus is the JSON output of gt sched status.
+type SchedStatus struct {
+	Enabled bool              `json:"enabled"`
+	Config  *scheduler.Config `json:"config,omitempty"`
+	Plan    *scheduler.Plan   `json:"plan"`
+	LastRun *scheduler.Run    `json:"last_run,omitempty"`
+}
+
+func runSchedStatus(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	cfg := daemon.SchedulerConfig(daemon.LoadPatrolConfig(townRoot))
+	in, err := scheduler.Snapshot(townRoot, cfg)
+	if err != nil {
+		return err
+	}
+	status := SchedStatus{
+		Enabled: cfg != nil && cfg.Enabled,
+		Config:  cfg,
+		Plan:    scheduler.Schedule(in),
+	}
+	status.LastRun, err = scheduler.LoadLastRun(townRoot)
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+
+	if schedStatusJSON {
+		data, err := json.MarshalIndent(status, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	printSchedStatus(&status)
+	return nil
+}
+
+func printSchedStatus(s *SchedStatus) {
+	plan := s.Plan
+	if s.Enabled {
+		fmt.Printf("%s Scheduler enabled (every %v)\n", style.SuccessPrefix, s.Config.IntervalDuration())
+	} else {
+		fmt.Printf("%s Scheduler disabled %s\n", style.WarningPrefix,
+			style.Dim.Render("(enable patrols.scheduler in mayor/daemon.json)"))
+	}
+
+	townMax := "unlimited"
+	if plan.TownMax > 0 {
+		townMax = fmt.Sprintf("%d", plan.TownMax)
+	}
+	fmt.Printf("\n%s  %d working / %s\n", style.Bold.Render("Town pool:"), townActiveBefore(plan), townMax)
+	for _, r := range plan.Rigs {
+		max := "unlimited"
+		if r.Max > 0 {
+			max = fmt.Sprintf("%d", r.Max)
+		}
+		line := fmt.Sprintf("  %-20s %d / %s", r.Name, r.Active-dispatchedTo(plan, r.Name), max)
+		if r.Blocked != "" {
+			line += "  " + style.Dim.Render("("+r.Blocked+")")
+		}
+		fmt.Println(line)
+	}
+
+	fmt.Printf("\n%s\n", style.Bold.Render("Next dispatch:"))
+	if len(plan.Dispatch) == 0 {
+		fmt.Println(style.Dim.Render("  (nothing)"))
+	}
+	for _, it := range plan.Dispatch {
+		fmt.Printf("  %s P%d %-14s → %-14s %s\n", style.ArrowPrefix, it.Priority, it.Bead, it.Rig, it.Title)
+	}
+
+	fmt.Printf("\n%s\n", style.Bold.Render("Waiting:"))
+	if len(plan.Waiting) == 0 {
+		fmt.Println(style.Dim.Render("  (none)"))
+	}
+	for _, w := range plan.Waiting {
+		fmt.Printf("    P%d %-14s %-14s %s\n", w.Priority, w.Bead, w.Rig, style.Dim.Render(w.Reason))
+	}
+
+	if s.LastRun != nil {
+		fmt.Printf("\n%s %s", style.Bold.Render("Last cycle:"), formatAge(s.LastRun.At))
+		if s.LastRun.Error != "" {
+			fmt.Printf(" — %s", style.Error.Render(s.LastRun.Error))
+		}
+		fmt.Println()
+		for _, d := range s.LastRun.Dispatched {
+			if d.Skipped != "" {
+				fmt.Printf("  %s\n", style.Dim.Render(fmt.Sprintf("- %s → %s skipped: %s", d.Bead, d.Rig, d.Skipped)))
+			} else if d.Error != "" {
+				fmt.Printf("  %s %s → %s: %s\n", style.WarningPrefix, d.Bead, d.Rig, d.Error)
+			} else {
+				fmt.Printf("  %s %s → %s\n", style.SuccessPrefix, d.Bead, d.Rig)
+			}
+		}
+	}
+}
+
+// townActiveBefore returns the town's working polecats before the planned
+// dispatches (the plan reports the post-dispatch count).
+func townActiveBefore(plan *scheduler.Plan) int {
+	return plan.TownActive - len(plan.Dispatch)
+}
+
+// dispatchedTo counts planned dispatches to a rig.
+func dispatchedTo(plan *scheduler.Plan, rigName string) int {
+	n := 0
+	for _, it := range plan.Dispatch {
+		if it.Rig == rigName {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,307 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/scorecard"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/townlog"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Scorecard command flags
+var (
+	scorecardActor  string
+	scorecardPreset string
+	scorecardSince  string
+	scorecardJSON   bool
+)
+
+var scorecardCmd = &cobra.Command{
+	Use:     "scorecard",
+	GroupID: GroupDiag,
+	Short:   "Show agent performance scorecards",
+	Long: `Show how agents have performed, aggregated from history.
+
+Scorecards are computed per identity and per agent preset from beads,
+activity events (including the archive) and merge queue outcomes:
+  - Beads completed and median time from sling to done
+  - Merge requests and the share merged on the first attempt
+  - Merge failures by type (conflict, tests_fail, build_fail, ...)
+  - Handoffs per completed bead, escalations and crashes
+  - Session cost
+
+Sling routing uses the same first-pass merge rate to prefer agents whose
+work lands cleanly.
+
+Examples:
+  gt scorecard                          # Last 30 days, all agents
+  gt scorecard --since=7d               # Last week
+  gt scorecard --actor=gastown/crew/joe # One identity (partial match)
+  gt scorecard --preset=codex           # Identities running codex
+  gt scorecard --json                   # Machine-readable`,
+	RunE: runScorecard,
+}
+
+func init() {
+	scorecardCmd.Flags().StringVar(&scorecardActor, "actor", "", "Filter identities (agent address or partial match)")
+	scorecardCmd.Flags().StringVar(&scorecardPreset, "preset", "", "Filter by agent preset (e.g., claude, codex)")
+	scorecardCmd.Flags().StringVar(&scorecardSince, "since", "30d", "Only count outcomes since duration (e.g., 24h, 7d; 0 for all)")
+	scorecardCmd.Flags().BoolVar(&scorecardJSON, "json", false, "Output as JSON")
+
+	rootCmd.AddCommand(scorecardCmd)
+}
+
+func runScorecard(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	var since time.Time
+	if scorecardSince != "" && scorecardSince != "0" {
+		d, err := parseDuration(scorecardSince)
+		if err != nil {
+			return fmt.Errorf("invalid --since duration: %w", err)
+		}
+		since = time.Now().Add(-d)
+	}
+
+	report, err := buildScorecards(townRoot, since)
+	if err != nil {
+		return err
+	}
+	filterScorecards(report, scorecardActor, scorecardPreset)
+
+	if scorecardJSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(report.Identities) == 0 {
+		fmt.Printf("%s No agent activity found\n", style.Dim.Render("○"))
+		return nil
+	}
+	printScorecards(os.Stdout, report)
+	return nil
+}
+
+// buildScorecards gathers every scorecard source for the town. Sources
+// that can't be read are skipped with a warning.
+func buildScorecards(townRoot string, since time.Time) (*scorecard.Report, error) {
+	evs, err := scorecard.LoadEvents(townRoot, since)
+	if err != nil {
+		return nil, fmt.Errorf("reading events: %w", err)
+	}
+
+	src := scorecard.Sources{
+		Events: evs,
+		Beads:  collectScorecardBeads(townRoot),
+		Since:  since,
+		Preset: scorecardPresetResolver(townRoot),
+	}
+
+	if logged, err := townlog.ReadEvents(townRoot); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not read town log: %v\n", err)
+	} else {
+		for _, e := range logged {
+			if e.Type == townlog.EventCrash {
+				src.Crashes = append(src.Crashes, scorecard.Crash{Agent: e.Agent, At: e.Timestamp})
+			}
+		}
+	}
+
+	for _, c := range querySessionEvents() {
+		src.Costs = append(src.Costs, scorecard.Cost{
+			Identity: scorecard.IdentityFor(c.Role, c.Rig, c.Worker),
+			USD:      c.CostUSD,
+			At:       c.EndedAt,
+		})
+	}
+
+	return scorecard.Build(src), nil
+}
+
+// scorecardWorkTypes are the bead types that count as completed work.
+var scorecardWorkTypes = map[string]bool{"task": true, "bug": true, "feature": true, "chore": true}
+
+// collectScorecardBeads lists closed, assigned work beads from the town and
+// every rig.
+func collectScorecardBeads(townRoot string) []scorecard.Bead {
+	locations := []string{townRoot}
+	rigsConfig, err := config.LoadRigsConfig(filepath.Join(townRoot, constants.DirMayor, constants.FileRigsJSON))
+	if err == nil && rigsConfig != nil {
+		for rigName := range rigsConfig.Rigs {
+			locations = append(locations, filepath.Join(townRoot, rigName))
+		}
+	}
+
+	var out []scorecard.Bead
+	seen := make(map[string]bool)
+	for _, location := range locations {
+		issues, err := beads.New(location).List(beads.ListOptions{Status: "closed", Priority: -1})
+		if err != nil {
+			continue
+		}
+		for _, issue := range issues {
+			if seen[issue.ID] || issue.Assignee == "" || !scorecardWorkTypes[issue.Type] {
+				continue
+			}
+			seen[issue.ID] = true
+			out = append(out, scorecard.Bead{
+				ID:        issue.ID,
+				Assignee:  issue.Assignee,
+				CreatedAt: parseBeadsTimestamp(issue.CreatedAt),
+				ClosedAt:  parseBeadsTimestamp(issue.ClosedAt),
+			})
+		}
+	}
+	return out
+}
+
+// scorecardPresetResolver resolves the agent preset an identity's role is
+// configured to run, for identities no sling event recorded one for.
+func scorecardPresetResolver(townRoot string) func(string) string {
+	cache := make(map[string]string)
+	return func(identity string) string {
+		role, rigName := scorecardRole(identity)
+		if role == "" {
+			return ""
+		}
+		key := role + "@" + rigName
+		if preset, ok := cache[key]; ok {
+			return preset
+		}
+		rigPath := ""
+		if rigName != "" {
+			rigPath = filepath.Join(townRoot, rigName)
+		}
+		preset, _ := config.ResolveRoleAgentName(role, townRoot, rigPath)
+		cache[key] = preset
+		return preset
+	}
+}
+
+// scorecardRole returns the role and rig an identity address names.
+func scorecardRole(identity string) (role, rigName string) {
+	parts := strings.Split(identity, "/")
+	switch {
+	case len(parts) == 1 && (parts[0] == constants.RoleMayor || parts[0] == constants.RoleDeacon):
+		return parts[0], ""
+	case len(parts) == 2 && (parts[1] == constants.RoleWitness || parts[1] == constants.RoleRefinery):
+		return parts[1], parts[0]
+	case len(parts) == 3 && parts[1] == "polecats":
+		return constants.RolePolecat, parts[0]
+	case len(parts) == 3 && parts[1] == "crew":
+		return constants.RoleCrew, parts[0]
+	}
+	return "", ""
+}
+
+// filterScorecards narrows the report to matching identities and the
+// presets they ran.
+func filterScorecards(report *scorecard.Report, actor, preset string) {
+	if actor == "" && preset == "" {
+		return
+	}
+	keep := make(map[string]bool)
+	var identities []*scorecard.Card
+	for _, c := range report.Identities {
+		if actor != "" && !matchesActor(c.Key, actor) {
+			continue
+		}
+		if preset != "" && c.Preset != preset {
+			continue
+		}
+		identities = append(identities, c)
+		keep[c.Preset] = true
+	}
+	report.Identities = identities
+
+	// Preset cards aggregate every identity, so with --actor they would
+	// describe agents that were filtered out.
+	if actor != "" {
+		report.Presets = nil
+		return
+	}
+	var presets []*scorecard.Card
+	for _, c := range report.Presets {
+		if keep[c.Key] {
+			presets = append(presets, c)
+		}
+	}
+	report.Presets = presets
+}
+
+func printScorecards(out io.Writer, report *scorecard.Report) {
+	period := "all time"
+	if !report.Since.IsZero() {
+		period = "since " + report.Since.Format("2006-01-02")
+	}
+	fmt.Fprintf(out, "%s Agent scorecards (%s)\n\n", style.Bold.Render("📊"), period)
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "IDENTITY\tPRESET\tDONE\tMEDIAN\tMRS\tFIRST-PASS\tHANDOFFS/BEAD\tESC\tCRASH\tCOST\tMERGE FAILURES")
+	for _, c := range report.Identities {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", c.Key, valueOr(c.Preset, "-"), scorecardColumns(c))
+	}
+	_ = w.Flush()
+
+	if len(report.Presets) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "\n%s\n", style.Bold.Render("By preset:"))
+	w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PRESET\tAGENTS\tDONE\tMEDIAN\tMRS\tFIRST-PASS\tHANDOFFS/BEAD\tESC\tCRASH\tCOST\tMERGE FAILURES")
+	for _, c := range report.Presets {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", c.Key, c.Identities, scorecardColumns(c))
+	}
+	_ = w.Flush()
+}
+
+// scorecardColumns renders the metric columns shared by both tables.
+func scorecardColumns(c *scorecard.Card) string {
+	median, firstPass, perBead := "-", "-", "-"
+	if c.MedianTimeToDone > 0 {
+		median = formatDuration(c.MedianTimeToDone)
+	}
+	if c.MRs > 0 {
+		firstPass = fmt.Sprintf("%.0f%%", c.FirstPassRate*100)
+	}
+	if c.BeadsCompleted > 0 {
+		perBead = fmt.Sprintf("%.2f", c.HandoffsPerBead)
+	}
+
+	var failures []string
+	for kind, n := range c.MergeFailures {
+		failures = append(failures, fmt.Sprintf("%s:%d", kind, n))
+	}
+	sort.Strings(failures)
+
+	return fmt.Sprintf("%d\t%s\t%d\t%s\t%s\t%d\t%d\t$%.2f\t%s",
+		c.BeadsCompleted, median, c.MRs, firstPass, perBead,
+		c.Escalations, c.Crashes, c.CostUSD, valueOr(strings.Join(failures, " "), "-"))
+}
+
+func valueOr(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/scorecard"
+)
+
+func TestScorecardRole(t *testing.T) {
+	tests := []struct{ identity, role, rig string }{
+		{"mayor", "mayor", ""},
+		{"gastown/witness", "witness", "gastown"},
+		{"gastown/polecats/nux", "polecat", "gastown"},
+		{"gastown/crew/max", "crew", "gastown"},
+		{"gastown/unknown/x/y", "", ""},
+	}
+	for _, tt := range tests {
+		role, rig := scorecardRole(tt.identity)
+		if role != tt.role || rig != tt.rig {
+			t.Errorf("scorecardRole(%q) = %q, %q; want %q, %q", tt.identity, role, rig, tt.role, tt.rig)
+		}
+	}
+}
+
+func TestFilterScorecards(t *testing.T) {
+	newReport := func() *scorecard.Report {
+		return &scorecard.Report{
+			Identities: []*scorecard.Card{
+				{Key: "gastown/polecats/nux", Preset: "codex"},
+				{Key: "gastown/crew/max", Preset: "claude"},
+			},
+			Presets: []*scorecard.Card{{Key: "claude"}, {Key: "codex"}},
+		}
+	}
+
+	r := newReport()
+	filterScorecards(r, "", "codex")
+	if len(r.Identities) != 1 || r.Identities[0].Key != "gastown/polecats/nux" || len(r.Presets) != 1 || r.Presets[0].Key != "codex" {
+		t.Errorf("--preset codex = %+v / %+v", r.Identities, r.Presets)
+	}
+
+	r = newReport()
+	filterScorecards(r, "max", "")
+	if len(r.Identities) != 1 || r.Identities[0].Key != "gastown/crew/max" || r.Presets != nil {
+		t.Errorf("--actor max = %+v / %+v", r.Identities, r.Presets)
+	}
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/webhooks"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var serveHooksPort int
+
+var serveCmd = &cobra.Command{
+	Use:     "serve",
+	GroupID: GroupServices,
+	Short:   "Run HTTP services for the town",
+	RunE:    requireSubcommand,
+}
+
+var serveHooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "Receive inbound webhooks from GitHub, Gitea and other systems",
+	Long: `Serve the inbound webhook receiver at /hooks/<source>.
+
+Sources and mapping rules live in the "inbound" section of
+settings/webhooks.json:
+
+  "inbound": {
+    "sources": [
+      {"name": "github", "kind": "github", "secret_env": "GT_GITHUB_HOOK_SECRET"},
+      {"name": "alerts", "kind": "generic", "secret_env": "GT_ALERTS_SECRET"}
+    ],
+    "rules": [
+      {"name": "bugs", "source": "github", "event": "issue.opened",
+       "label": "bug", "action": "bead", "rig": "gastown", "priority": 1},
+      {"name": "ci", "event": "ci.failed", "repo": "acme/*", "action": "mail"},
+      {"name": "pages", "source": "alerts", "event": "page",
+       "action": "sling", "rig": "ops", "target": "ops"}
+    ]
+  }
+
+Source kinds:
+  github   X-Hub-Signature-256 over the body
+  gitea    X-Gitea-Signature over the body
+  generic  Gas Town signature (see gt webhooks) with X-Gastown-Timestamp,
+           body {"id", "type", "title", "body", "bead", "repo", "url", "labels"}
+
+Normalized events: issue.<action>, issue_comment.<action>,
+pr_comment.<action> and ci.failed (check_run, workflow_run and commit
+status failures); generic payloads use their "type". Rule event, repo,
+label and author fields are globs.
+
+Actions: "bead" creates a bead in the rule's rig, "mail" mails the target
+(default mayor/), "sling" slings the payload's bead - or a new one - to
+the target.
+
+Every source must have a secret. A delivery is acknowledged with 202 once
+recorded and its actions run in the background. Deliveries are remembered
+by a hash of their signed body for seven days and duplicates are rejected
+with 409; a delivery whose actions failed is accepted again, and runs only
+the rules that failed. 'gt dashboard' also serves
+/hooks/ when inbound sources are configured.`,
+	Args: cobra.NoArgs,
+	RunE: runServeHooks,
+}
+
+func init() {
+	serveHooksCmd.Flags().IntVar(&serveHooksPort, "port", 8090, "HTTP port to listen on")
+	serveCmd.AddCommand(serveHooksCmd)
+	rootCmd.AddCommand(serveCmd)
+}
+
+func runServeHooks(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	receiver, err := newHookReceiver(townRoot)
+	if err != nil {
+		return err
+	}
+	if receiver == nil {
+		return fmt.Errorf("no inbound sources configured in %s", webhooks.ConfigPath(townRoot))
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(webhooks.HooksPrefix, receiver)
+	fmt.Printf("%s Receiving webhooks at http://localhost:%d%s<source>  •  ctrl+c to stop\n",
+		style.SuccessPrefix, serveHooksPort, webhooks.HooksPrefix)
+
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", serveHooksPort),
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      60 * time.Second,
+		IdleTimeout:       120 * time.Second,
+	}
+	return server.ListenAndServe()
+}
+
+// newHookReceiver builds the inbound receiver for a town, or returns nil
+// when no inbound sources are configured.
+func newHookReceiver(townRoot string) (*webhooks.Receiver, error) {
+	cfg, err := webhooks.LoadConfig(townRoot)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Inbound == nil || len(cfg.Inbound.Sources) == 0 {
+		return nil, nil
+	}
+	seen, err := webhooks.NewSeenStore(webhooks.SeenPath(townRoot))
+	if err != nil {
+		return nil, err
+	}
+	logger := func(format string, args ...interface{}) {
+		fmt.Fprintf(os.Stderr, "%s "+format+"\n", append([]interface{}{time.Now().Format("15:04:05")}, args...)...)
+	}
+	return webhooks.NewReceiver(cfg.Inbound, &townHookActions{townRoot: townRoot}, seen, logger), nil
+}
+
+// townHookActions performs inbound webhook actions against a town.
+type townHookActions struct {
+	townRoot string
+}
+
+func (a *townHookActions) CreateBead(rig, title, description string, priority int) (string, error) {
+	bd := beads.New(beads.ResolveBeadsDir(filepath.Join(a.townRoot, rig)))
+	issue, err := bd.Create(beads.CreateOptions{
+		Title:       title,
+		Priority:    priority,
+		Description: description,
+		Actor:       "webhooks",
+	})
+	if err != nil {
+		return "", fmt.Errorf("creating bead in %s: %w", rig, err)
+	}
+	return issue.ID, nil
+}
+
+func (a *townHookActions) Mail(to, subject, body string) error {
+	return mail.NewRouter(a.townRoot).Send(mail.NewMessage("webhooks", to, subject, body))
+}
+
+func (a *townHookActions) Sling(bead, target string) error {
+	gtPath, err := os.Executable()
+	if err != nil {
+		gtPath = "gt"
+	}
+	c := exec.Command(gtPath, "sling", bead, target) //nolint:gosec // G204: args come from town config and a created bead ID
+	c.Dir = a.townRoot
+	if out, err := c.CombinedOutput(); err != nil {
+		return fmt.Errorf("gt sling %s %s: %w: %s", bead, target, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
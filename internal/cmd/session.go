@@ -277,8 +277,7 @@ func runSessionStart(cmd *cobra.Command, args []string) error {
 	// Log wake event
 	if townRoot, err := workspace.FindFromCwd(); err == nil && townRoot != "" {
 		agent := fmt.Sprintf("%s/%s", rigName, polecatName)
-		logger := townlog.NewLogger(townRoot)
-		_ = logger.Log(townlog.EventWake, agent, sessionIssue)
+		_ = LogEventWithRoot(townRoot, townlog.EventWake, agent, sessionIssue)
 	}
 
 	return nil
@@ -313,8 +312,7 @@ func runSessionStop(cmd *cobra.Command, args []string) error {
 		if sessionForce {
 			reason = "gt session stop --force"
 		}
-		logger := townlog.NewLogger(townRoot)
-		_ = logger.Log(townlog.EventKill, agent, reason)
+		_ = LogEventWithRoot(townRoot, townlog.EventKill, agent, reason)
 	}
 
 	return nil
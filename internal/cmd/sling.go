@@ -13,7 +13,10 @@ import (
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/routing"
 	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/tracing"
+	"github.com/steveyegge/gastown/internal/witness"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -85,6 +88,22 @@ Compare:
 
 The propulsion principle: if it's on your hook, YOU RUN IT.
 
+Capability Routing:
+  Beads can require capabilities with "needs:<capability>" labels (e.g.
+  needs:rust). When slinging such a bead to a rig, sling picks the best
+  target in that rig: an idle crew member or a fresh polecat running an
+  agent preset that advertises every required capability. Candidates are
+  ranked by availability, polecat pool headroom (max_polecats) and recent
+  success rate on beads with similar capabilities.
+
+  Capabilities are advertised in:
+    roles/<role>.toml            capabilities = ["rust"]
+    settings/config.json         "agent_capabilities": {"codex": ["db"]}
+    <rig>/settings/config.json   "crew": {"capabilities": {"max": ["rust"]}}
+
+  gt sling gt-abc gastown --route      # Route even without needs: labels
+  gt sling gt-abc gastown --explain    # Show why a target was chosen
+
 Batch Slinging:
   gt sling gt-abc gt-def gt-ghi gastown   # Sling multiple beads to a rig
   gt sling gt-abc gt-def gastown --max-concurrent 3  # Limit concurrent spawns
@@ -115,6 +134,8 @@ var (
 	slingNoMerge       bool   // --no-merge: skip merge queue on completion (for upstream PRs/human review)
 	slingNoBoot        bool   // --no-boot: skip wakeRigAgents (avoid witness/refinery boot and lock contention)
 	slingMaxConcurrent int    // --max-concurrent: limit concurrent spawns in batch mode
+	slingRoute         bool   // --route: pick target in rig via capability routing
+	slingExplain       bool   // --explain: show routing decision (implies --route)
 )
 
 func init() {
@@ -136,11 +157,17 @@ func init() {
 	slingCmd.Flags().BoolVar(&slingNoMerge, "no-merge", false, "Skip merge queue on completion (keep work on feature branch for review)")
 	slingCmd.Flags().BoolVar(&slingNoBoot, "no-boot", false, "Skip rig boot after polecat spawn (avoids witness/refinery lock contention)")
 	slingCmd.Flags().IntVar(&slingMaxConcurrent, "max-concurrent", 0, "Limit concurrent polecat spawns in batch mode (0 = no limit)")
+	slingCmd.Flags().BoolVar(&slingRoute, "route", false, "Pick the best target in the rig by capabilities, idle crew and success rate")
+	slingCmd.Flags().BoolVar(&slingExplain, "explain", false, "Explain the routing decision (implies --route)")
 
 	rootCmd.AddCommand(slingCmd)
 }
 
-func runSling(cmd *cobra.Command, args []string) error {
+func runSling(cmd *cobra.Command, args []string) (retErr error) {
+	// Trace the sling; the bead is bound below once it's resolved.
+	span := tracing.Start("gt.sling", "")
+	defer func() { span.End(retErr) }()
+
 	// Polecats cannot sling - check early before writing anything
 	if polecatName := os.Getenv("GT_POLECAT"); polecatName != "" {
 		return fmt.Errorf("polecats cannot sling (use gt done for handoff)")
@@ -233,6 +260,8 @@ func runSling(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	span.SetBead(beadID)
+
 	// Determine target agent (self or specified)
 	var targetAgent string
 	var targetPane string
@@ -241,10 +270,29 @@ func runSling(cmd *cobra.Command, args []string) error {
 	var delayedDogInfo *DogDispatchInfo     // For delayed dog session start after hook is set
 	var newPolecatInfo *SpawnedPolecatInfo  // Spawned polecat info (session started after bead setup)
 	var isSelfSling bool                    // True if slinging to self (skip nudge - agent already knows)
+	var requiredCaps []string               // Capabilities required by the bead (needs:* labels)
 
 	if len(args) > 1 {
 		target := args[1]
 
+		// Capability routing: when the target is a rig, let the scheduler
+		// choose between idle crew and polecats of different agent presets.
+		if rigName, isRig := IsRigName(target); isRig {
+			if bead, err := getBeadInfo(beadID); err == nil {
+				requiredCaps = routing.RequiredCapabilities(bead.Labels)
+			}
+			if shouldRouteSling(requiredCaps) {
+				routedTarget, routedAgent, err := routeSlingInRig(townRoot, rigName, requiredCaps)
+				if err != nil {
+					return err
+				}
+				target = routedTarget
+				if slingAgent == "" {
+					slingAgent = routedAgent
+				}
+			}
+		}
+
 		// Resolve "." to current agent identity (like git's "." meaning current directory)
 		if target == "." {
 			targetAgent, targetPane, _, err = resolveSelfTarget()
@@ -368,6 +416,8 @@ func runSling(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	span.SetAttr("target", targetAgent)
+
 	// Display what we're doing
 	if formulaName != "" {
 		fmt.Printf("%s Slinging formula %s on %s to %s...\n", style.Bold.Render("🎯"), formulaName, beadID, targetAgent)
@@ -414,7 +464,13 @@ func runSling(cmd *cobra.Command, args []string) error {
 					From:     "gt-sling",
 					To:       fmt.Sprintf("%s/witness", oldRigName),
 					Subject:  fmt.Sprintf("LIFECYCLE:Shutdown %s", oldPolecatName),
-					Body:     fmt.Sprintf("Reason: work_reassigned\nRequestedBy: %s\nBead: %s\nNewAssignee: %s", requester, beadID, targetAgent),
+					Body: witness.FormatLifecycleShutdownBody(witness.LifecycleShutdownPayload{
+						PolecatName: oldPolecatName,
+						Reason:      "work_reassigned",
+						RequestedBy: requester,
+						Bead:        beadID,
+						NewAssignee: targetAgent,
+					}),
 					Type:     mail.TypeTask,
 					Priority: mail.PriorityHigh,
 				}
@@ -579,7 +635,14 @@ func runSling(cmd *cobra.Command, args []string) error {
 
 	// Log sling event to activity feed
 	actor := detectActor()
-	_ = events.LogFeed(events.TypeSling, actor, events.SlingPayload(beadID, targetAgent))
+	slingPayload := events.SlingPayload(beadID, targetAgent)
+	if len(requiredCaps) > 0 {
+		slingPayload["capabilities"] = requiredCaps
+	}
+	if slingAgent != "" {
+		slingPayload["agent"] = slingAgent // Attributes scorecards to the preset
+	}
+	_ = events.LogFeed(events.TypeSling, actor, slingPayload)
 
 	// Update agent bead's hook_bead field (ZFC: agents track their current work)
 	// Skip if hook was already set atomically during polecat spawn - avoids "agent bead not found"
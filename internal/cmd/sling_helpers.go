@@ -6,12 +6,17 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/runtime"
+	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/tmux"
+	"github.com/steveyegge/gastown/internal/tracing"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -64,9 +69,10 @@ func resolveBeadDirFromRigsJSON(townRoot, prefix string) string {
 
 // beadInfo holds status and assignee for a bead.
 type beadInfo struct {
-	Title    string `json:"title"`
-	Status   string `json:"status"`
-	Assignee string `json:"assignee"`
+	Title    string   `json:"title"`
+	Status   string   `json:"status"`
+	Assignee string   `json:"assignee"`
+	Labels   []string `json:"labels,omitempty"`
 }
 
 // verifyBeadExists checks that the bead exists using bd show.
@@ -166,6 +172,8 @@ func storeArgsInBead(beadID, args string) error {
 
 // storeDispatcherInBead stores the dispatcher agent ID in the bead's description.
 // This enables polecats to notify the dispatcher when work is complete.
+// The bead's trace ID is recorded alongside so traces can be looked up
+// from the bead.
 func storeDispatcherInBead(beadID, dispatcher string) error {
 	if dispatcher == "" {
 		return nil
@@ -196,6 +204,9 @@ func storeDispatcherInBead(beadID, dispatcher string) error {
 
 	// Set the dispatcher
 	fields.DispatchedBy = dispatcher
+	if tracing.Enabled() {
+		fields.TraceID = tracing.TraceID(beadID)
+	}
 
 	// Update the description
 	newDesc := beads.SetAttachmentFields(issue, fields)
@@ -371,8 +382,8 @@ func getSessionFromPane(pane string) string {
 }
 
 // ensureAgentReady waits for an agent to be ready before nudging an existing session.
-// Uses a pragmatic approach: wait for the pane to leave a shell, then (Claude-only)
-// accept the bypass permissions warning and give it a moment to finish initializing.
+// Uses a pragmatic approach: wait for the pane to leave a shell, answer the agent's
+// startup dialogs from its resolved config, then give it a moment to finish initializing.
 func ensureAgentReady(sessionName string) error {
 	t := tmux.NewTmux()
 
@@ -386,11 +397,16 @@ func ensureAgentReady(sessionName string) error {
 		return fmt.Errorf("waiting for agent to start: %w", err)
 	}
 
-	// Claude-only: accept bypass permissions warning if present
+	// Answer trust and permission dialogs using the session's agent config
 	agentName, _ := t.GetEnvironment(sessionName, "GT_AGENT")
-	if agentName == "" || agentName == "claude" {
-		_ = t.AcceptBypassPermissionsWarning(sessionName)
+	rc, agentName := sessionAgentConfig(sessionName, agentName)
+	actor, _ := t.GetEnvironment(sessionName, "BD_ACTOR")
+	if actor == "" {
+		actor = sessionName
+	}
+	_, _ = runtime.AnswerStartupPrompts(t, sessionName, actor, rc)
 
+	if agentName == string(config.AgentClaude) {
 		// PRAGMATIC APPROACH: fixed delay rather than prompt detection.
 		// Claude startup takes ~5-8 seconds on typical machines.
 		time.Sleep(8 * time.Second)
@@ -401,6 +417,37 @@ func ensureAgentReady(sessionName string) error {
 	return nil
 }
 
+// sessionAgentConfig resolves the runtime config of the agent running in a
+// session the way the daemon does: by the session's role and rig, so rig
+// overrides such as tmux.startup_prompts apply. agentOverride is the
+// session's GT_AGENT; when it names a different agent than the role's, that
+// agent is resolved for the rig instead. Returns the config and agent name.
+func sessionAgentConfig(sessionName, agentOverride string) (*config.RuntimeConfig, string) {
+	townRoot, _ := workspace.FindFromCwd()
+	if townRoot == "" {
+		if agentOverride == "" {
+			agentOverride = string(config.DefaultAgentPreset())
+		}
+		return config.RuntimeConfigFromPreset(config.AgentPreset(agentOverride)), agentOverride
+	}
+
+	role, rigPath := "", ""
+	if id, err := session.ParseSessionName(sessionName); err == nil {
+		role = string(id.Role)
+		if id.Rig != "" {
+			rigPath = filepath.Join(townRoot, id.Rig)
+		}
+	}
+	roleAgent, _ := config.ResolveRoleAgentName(role, townRoot, rigPath)
+	if agentOverride == "" || agentOverride == roleAgent {
+		return config.ResolveRoleAgentConfig(role, townRoot, rigPath), roleAgent
+	}
+	if rc, _, err := config.ResolveAgentConfigWithOverride(townRoot, rigPath, agentOverride); err == nil {
+		return rc, agentOverride
+	}
+	return config.RuntimeConfigFromPreset(config.AgentPreset(agentOverride)), agentOverride
+}
+
 // detectCloneRoot finds the root of the current git clone.
 func detectCloneRoot() (string, error) {
 	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/steveyegge/gastown/internal/session"
 )
 
 // TestNudgeRefinerySessionName verifies that nudgeRefinery constructs the
@@ -97,3 +99,36 @@ func TestNudgeRefineryNoOpWithoutLog(t *testing.T) {
 	// Should not panic even though no tmux session exists
 	nudgeRefinery("nonexistent-rig", "test message")
 }
+
+// TestSessionAgentConfig_UsesRigOverrides verifies that the startup dialogs
+// answered for an existing session come from the rig's role agent, so an
+// empty startup_prompts override turns them off.
+func TestSessionAgentConfig_UsesRigOverrides(t *testing.T) {
+	townRoot := t.TempDir()
+	rigPath := filepath.Join(townRoot, "gastown")
+	for _, dir := range []string{filepath.Join(townRoot, "mayor"), filepath.Join(rigPath, "settings")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(townRoot, "mayor", "town.json"), []byte(`{"type":"town","version":2,"name":"test"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	settings := `{"type":"rig-settings","version":1,"role_agents":{"polecat":"quiet"},
+		"agents":{"quiet":{"command":"claude","tmux":{"startup_prompts":[]}}}}`
+	if err := os.WriteFile(filepath.Join(rigPath, "settings", "config.json"), []byte(settings), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(townRoot)
+
+	rc, name := sessionAgentConfig(session.PolecatSessionName("gastown", "Toast"), "")
+	if name != "quiet" || rc.Tmux == nil || rc.Tmux.StartupPrompts == nil || len(rc.Tmux.StartupPrompts) != 0 {
+		t.Errorf("sessionAgentConfig = %q, %+v; want quiet with no startup prompts", name, rc.Tmux)
+	}
+
+	// A GT_AGENT naming another agent resolves that agent instead.
+	rc, name = sessionAgentConfig(session.PolecatSessionName("gastown", "Toast"), "claude")
+	if name != "claude" || rc.Tmux == nil || len(rc.Tmux.StartupPrompts) == 0 {
+		t.Errorf("override = %q, %+v; want claude's startup prompts", name, rc.Tmux)
+	}
+}
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/crew"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/routing"
+	"github.com/steveyegge/gastown/internal/scorecard"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+// shouldRouteSling reports whether a rig-targeted sling goes through the
+// capability scheduler: when asked explicitly, or when the bead declares
+// required capabilities.
+func shouldRouteSling(required []string) bool {
+	return slingRoute || slingExplain || len(required) > 0
+}
+
+// routeSlingInRig picks the best target in a rig for a bead requiring the
+// given capabilities. It returns the sling target (a crew address or the
+// rig name for a fresh polecat) and the agent preset to spawn with.
+func routeSlingInRig(townRoot, rigName string, required []string) (target, agent string, err error) {
+	rigsConfig, err := config.LoadRigsConfig(filepath.Join(townRoot, "mayor", "rigs.json"))
+	if err != nil {
+		return "", "", fmt.Errorf("loading rigs config: %w", err)
+	}
+	r, err := rig.NewManager(townRoot, rigsConfig, git.NewGit(townRoot)).GetRig(rigName)
+	if err != nil {
+		return "", "", fmt.Errorf("rig '%s' not found", rigName)
+	}
+
+	candidates := gatherRouteCandidates(townRoot, r)
+	applyScorecards(townRoot, candidates)
+	hist, err := routing.LoadHistory(filepath.Join(townRoot, events.EventsFile))
+	if err != nil {
+		hist = nil // Routing still works with neutral success rates
+	}
+
+	decision := routing.Route(required, candidates, hist)
+	if slingExplain {
+		fmt.Printf("%s Routing in rig %s:\n", style.Bold.Render("🧭"), rigName)
+		decision.Explain(os.Stdout)
+		fmt.Println()
+	}
+
+	if decision.Chosen == nil {
+		if !slingExplain {
+			decision.Explain(os.Stdout)
+		}
+		return "", "", fmt.Errorf("no target in rig '%s' can take this bead (see above; use --explain for details)", rigName)
+	}
+
+	chosen := decision.Chosen
+	fmt.Printf("%s Routed to %s (agent %s, score %.1f)\n",
+		style.Bold.Render("→"), chosen.Target, chosen.Agent, chosen.Score)
+	if chosen.Kind == routing.KindPolecat {
+		return rigName, chosen.Agent, nil
+	}
+	return chosen.Target, "", nil
+}
+
+// gatherRouteCandidates lists idle-or-busy crew members and one fresh
+// polecat candidate per agent preset that could be spawned in the rig.
+func gatherRouteCandidates(townRoot string, r *rig.Rig) []routing.Candidate {
+	var candidates []routing.Candidate
+
+	rigSettings, err := config.LoadRigSettings(config.RigSettingsPath(r.Path))
+	if err != nil {
+		rigSettings = nil
+	}
+	townSettings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot))
+	if err != nil {
+		townSettings = config.NewTownSettings()
+	}
+
+	// Polecat candidates: the rig's polecat agent plus every agent that
+	// advertises capabilities. An explicit --agent narrows this to one.
+	polecatCaps := roleCapabilities(townRoot, r.Path, "polecat")
+	defaultAgent, _ := config.ResolveRoleAgentName("polecat", townRoot, r.Path)
+	agents := []string{defaultAgent}
+	if slingAgent != "" {
+		agents = []string{slingAgent}
+	} else {
+		for name := range townSettings.AgentCapabilities {
+			agents = appendUnique(agents, name)
+		}
+		if rigSettings != nil {
+			for name := range rigSettings.AgentCapabilities {
+				agents = appendUnique(agents, name)
+			}
+		}
+	}
+	slices.Sort(agents[1:])
+
+	poolUsed := 0
+	if polecats, err := polecat.NewManager(r, git.NewGit(r.Path), tmux.NewTmux()).List(); err == nil {
+		poolUsed = len(polecats)
+	}
+	poolMax := r.GetIntConfig("max_polecats")
+
+	for _, agentName := range agents {
+		candidates = append(candidates, routing.Candidate{
+			Target:       r.Name,
+			Kind:         routing.KindPolecat,
+			Rig:          r.Name,
+			Agent:        agentName,
+			Capabilities: unionCapabilities(polecatCaps, config.ResolveAgentCapabilities(townRoot, r.Path, agentName)),
+			PoolUsed:     poolUsed,
+			PoolMax:      poolMax,
+		})
+	}
+
+	// Crew candidates run their own configured agent, so an explicit
+	// --agent excludes them.
+	if slingAgent != "" {
+		return candidates
+	}
+	crewMgr := crew.NewManager(r, git.NewGit(r.Path))
+	workers, err := crewMgr.List()
+	if err != nil {
+		return candidates
+	}
+	crewCaps := roleCapabilities(townRoot, r.Path, "crew")
+	crewAgent, _ := config.ResolveRoleAgentName("crew", townRoot, r.Path)
+	bd := beads.New(r.Path)
+	for _, w := range workers {
+		address := fmt.Sprintf("%s/crew/%s", r.Name, w.Name)
+		caps := unionCapabilities(crewCaps, config.ResolveAgentCapabilities(townRoot, r.Path, crewAgent))
+		if rigSettings != nil && rigSettings.Crew != nil {
+			caps = unionCapabilities(caps, rigSettings.Crew.Capabilities[w.Name])
+		}
+		running, _ := crewMgr.IsRunning(w.Name)
+		candidates = append(candidates, routing.Candidate{
+			Target:       address,
+			Kind:         routing.KindCrew,
+			Rig:          r.Name,
+			Agent:        crewAgent,
+			Capabilities: caps,
+			Idle:         running && !hasActiveWork(bd, address),
+		})
+	}
+	return candidates
+}
+
+// routeScorecardWindow is how far back dispatch looks at merge outcomes.
+const routeScorecardWindow = 30 * 24 * time.Hour
+
+// applyScorecards fills in each candidate's first-pass merge reliability:
+// the preset's record for fresh polecats, the member's own for crew.
+func applyScorecards(townRoot string, candidates []routing.Candidate) {
+	evs, err := scorecard.LoadEvents(townRoot, time.Now().Add(-routeScorecardWindow))
+	if err != nil || len(evs) == 0 {
+		return // Neutral reliability
+	}
+	report := scorecard.Build(scorecard.Sources{Events: evs, Preset: scorecardPresetResolver(townRoot)})
+	for i := range candidates {
+		c := &candidates[i]
+		card := report.Identity(c.Target)
+		if c.Kind == routing.KindPolecat {
+			card = report.Preset(c.Agent)
+		}
+		if card != nil {
+			c.Reliability, c.ReliabilitySamples = card.Reliability()
+		}
+	}
+}
+
+// hasActiveWork reports whether an agent has hooked or in-progress beads.
+func hasActiveWork(bd beads.Store, assignee string) bool {
+	for _, status := range []string{beads.StatusHooked, "in_progress"} {
+		issues, err := bd.List(beads.ListOptions{Status: status, Assignee: assignee, Priority: -1})
+		if err != nil || len(issues) > 0 {
+			// Treat lookup failures as busy: never route onto an unknown hook.
+			return true
+		}
+	}
+	return false
+}
+
+// roleCapabilities returns the capabilities declared in a role definition.
+func roleCapabilities(townRoot, rigPath, role string) []string {
+	def, err := config.LoadRoleDefinition(townRoot, rigPath, role)
+	if err != nil {
+		return nil
+	}
+	return def.Capabilities
+}
+
+// unionCapabilities returns a followed by the members of b not already in a.
+func unionCapabilities(a, b []string) []string {
+	out := append([]string(nil), a...)
+	for _, s := range b {
+		out = appendUnique(out, s)
+	}
+	return out
+}
+
+func appendUnique(list []string, s string) []string {
+	if s == "" || slices.Contains(list, s) {
+		return list
+	}
+	return append(list, s)
+}
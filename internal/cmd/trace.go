@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/tracing"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// traceBarWidth is the width of the waterfall's timeline column.
+const traceBarWidth = 40
+
+var traceJSON bool
+
+var traceCmd = &cobra.Command{
+	Use:     "trace <bead>",
+	GroupID: GroupDiag,
+	Short:   "Show where time went in a bead's lifecycle",
+	Long: `Render a waterfall of a bead's lifecycle trace.
+
+Gas Town records a span for each phase of a bead's life: gt sling, polecat
+spawn (session start, runtime ready), molecule steps, gt done, merge queue
+submission, the refinery merge and convoy close. All spans for a bead share
+one trace ID, derived from the bead ID and stored on the bead as trace_id.
+
+Tracing is off until settings/tracing.json exists:
+
+  {
+    "type": "tracing",
+    "version": 1,
+    "exporter": "both",
+    "endpoint": "http://localhost:4318"
+  }
+
+Exporters:
+  file   Append spans to logs/traces.jsonl (default; read by this command)
+  otlp   Send spans to an OTLP/HTTP collector (Jaeger, Tempo, Honeycomb...)
+  both   Both of the above
+  none   Disable tracing
+
+The endpoint defaults to $OTEL_EXPORTER_OTLP_ENDPOINT; "headers" adds
+request headers such as API keys.
+
+Examples:
+  gt trace gt-abc12
+  gt trace gt-abc12 --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTrace,
+}
+
+func init() {
+	traceCmd.Flags().BoolVar(&traceJSON, "json", false, "Output spans as JSON")
+	rootCmd.AddCommand(traceCmd)
+}
+
+func runTrace(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	beadID := args[0]
+	traceID := tracing.TraceID(beadID)
+
+	spans, err := tracing.ReadSpans(tracing.FilePath(townRoot), traceID)
+	if err != nil {
+		return fmt.Errorf("reading traces: %w", err)
+	}
+
+	if traceJSON {
+		if spans == nil {
+			spans = []tracing.Span{}
+		}
+		data, err := json.MarshalIndent(spans, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(spans) == 0 {
+		fmt.Printf("No spans recorded for %s.\n", beadID)
+		cfg, err := tracing.LoadConfig(townRoot)
+		switch {
+		case err != nil:
+			fmt.Printf("%s %v\n", style.WarningPrefix, err)
+		case cfg == nil:
+			fmt.Printf("Tracing is off. Create %s to enable it (see gt trace --help).\n",
+				style.Dim.Render(tracing.ConfigPath(townRoot)))
+		case !cfg.UsesFile():
+			fmt.Printf("The file exporter is off (exporter %q); spans go to %s.\n", cfg.Exporter, cfg.Endpoint)
+		}
+		return nil
+	}
+
+	printWaterfall(os.Stdout, beadID, traceID, spans)
+	return nil
+}
+
+// printWaterfall draws one line per span: name (indented by nesting),
+// duration, offset from the trace start, and a bar on a shared timeline.
+func printWaterfall(w io.Writer, beadID, traceID string, spans []tracing.Span) {
+	rows := tracing.Waterfall(spans)
+
+	t0, t1 := spans[0].Start, spans[0].Finish
+	nameWidth := 0
+	for _, r := range rows {
+		if r.Span.Start.Before(t0) {
+			t0 = r.Span.Start
+		}
+		if r.Span.Finish.After(t1) {
+			t1 = r.Span.Finish
+		}
+		if n := 2*r.Depth + len(r.Span.Name); n > nameWidth {
+			nameWidth = n
+		}
+	}
+	total := t1.Sub(t0)
+
+	fmt.Fprintf(w, "%s %s  %s\n", style.Bold.Render("Trace"), beadID, style.Dim.Render(traceID))
+	fmt.Fprintf(w, "%d spans over %s, started %s\n\n", len(rows), spanDuration(total), t0.Local().Format("2006-01-02 15:04:05"))
+
+	for _, r := range rows {
+		s := r.Span
+		name := strings.Repeat("  ", r.Depth) + s.Name
+		line := fmt.Sprintf("%-*s  %8s  +%-8s  %s", nameWidth, name, spanDuration(s.Duration()),
+			spanDuration(s.Start.Sub(t0)), traceBar(s.Start.Sub(t0), s.Duration(), total))
+		if s.Status == tracing.StatusError {
+			line = style.Error.Render(line)
+		}
+		fmt.Fprintln(w, line)
+		if s.Error != "" {
+			fmt.Fprintf(w, "%s%s\n", strings.Repeat(" ", nameWidth+4), style.Error.Render("✗ "+s.Error))
+		}
+	}
+}
+
+// traceBar renders a span's position on a timeline of length total.
+func traceBar(offset, length, total time.Duration) string {
+	if total <= 0 {
+		return "|" + strings.Repeat("█", traceBarWidth) + "|"
+	}
+	start := int(float64(offset) / float64(total) * traceBarWidth)
+	width := int(float64(length) / float64(total) * traceBarWidth)
+	if width < 1 {
+		width = 1 // Keep instant spans visible
+	}
+	if start > traceBarWidth-1 {
+		start = traceBarWidth - 1
+	}
+	if start+width > traceBarWidth {
+		width = traceBarWidth - start
+	}
+	return "|" + strings.Repeat(" ", start) + strings.Repeat("█", width) +
+		strings.Repeat(" ", traceBarWidth-start-width) + "|"
+}
+
+// spanDuration formats a duration with millisecond precision below one
+// second.
+func spanDuration(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	if d < time.Minute {
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	}
+	return formatDuration(d)
+}
+
+// traceMoleculeStep records a closed molecule step in the trace of the
+// agent's hooked bead. The step is timed from when it was claimed
+// (updated_at while in progress) or otherwise from its creation.
+func traceMoleculeStep(cwd, townRoot string, step *beads.Issue, moleculeID string) {
+	if !tracing.Enabled() {
+		return
+	}
+	bead := moleculeID
+	if roleInfo, err := GetRoleWithContext(cwd, townRoot); err == nil {
+		agentBeadID := getAgentBeadID(RoleContext{
+			Role:     roleInfo.Role,
+			Rig:      roleInfo.Rig,
+			Polecat:  roleInfo.Polecat,
+			TownRoot: townRoot,
+			WorkDir:  cwd,
+		})
+		bd := beads.New(beads.ResolveBeadsDir(cwd))
+		if hooked := getIssueFromAgentHook(bd, agentBeadID); hooked != "" {
+			bead = hooked
+		}
+	}
+
+	stamp := step.CreatedAt
+	if step.Status == "in_progress" && step.UpdatedAt != "" {
+		stamp = step.UpdatedAt
+	}
+	now := time.Now()
+	start, err := time.Parse(time.RFC3339, stamp)
+	if err != nil || start.After(now) {
+		start = now
+	}
+	tracing.Record("molecule.step", bead, start, now, map[string]string{
+		"step":     step.ID,
+		"title":    step.Title,
+		"molecule": moleculeID,
+	})
+}
+
+// traceConvoyClosed marks a convoy's close in the trace of each bead it
+// tracked. tracked may be nil, in which case it is looked up.
+func traceConvoyClosed(townBeads, convoyID, reason string, tracked []trackedIssueInfo) {
+	if !tracing.Enabled() {
+		return
+	}
+	if tracked == nil {
+		tracked = getTrackedIssues(townBeads, convoyID)
+	}
+	now := time.Now()
+	for _, t := range tracked {
+		tracing.Record("convoy.close", t.ID, now, now, map[string]string{
+			"convoy": convoyID,
+			"reason": reason,
+		})
+	}
+}
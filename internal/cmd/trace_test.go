@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/tracing"
+)
+
+func TestTraceBar(t *testing.T) {
+	full := traceBar(0, time.Minute, time.Minute)
+	if full != "|"+strings.Repeat("█", traceBarWidth)+"|" {
+		t.Errorf("full bar = %q", full)
+	}
+	half := traceBar(30*time.Second, 30*time.Second, time.Minute)
+	if want := "|" + strings.Repeat(" ", traceBarWidth/2) + strings.Repeat("█", traceBarWidth/2) + "|"; half != want {
+		t.Errorf("second half = %q, want %q", half, want)
+	}
+	end := traceBar(time.Minute, 0, time.Minute)
+	if len([]rune(end)) != traceBarWidth+2 || !strings.HasSuffix(end, "█|") {
+		t.Errorf("instant span at end = %q", end)
+	}
+}
+
+func TestSpanDuration(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		250 * time.Millisecond:  "250ms",
+		1500 * time.Millisecond: "1.5s",
+		90 * time.Second:        "1m 30s",
+	} {
+		if got := spanDuration(d); got != want {
+			t.Errorf("spanDuration(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestPrintWaterfall(t *testing.T) {
+	t0 := time.Unix(1_700_000_000, 0)
+	trace := tracing.TraceID("gt-1")
+	spans := []tracing.Span{
+		{TraceID: trace, SpanID: "a", Name: "gt.sling", Start: t0, Finish: t0.Add(10 * time.Second), Status: tracing.StatusOK},
+		{TraceID: trace, SpanID: "b", ParentID: "a", Name: "polecat.spawn", Start: t0.Add(time.Second), Finish: t0.Add(4 * time.Second), Status: tracing.StatusOK},
+		{TraceID: trace, SpanID: "c", Name: "refinery.merge", Start: t0.Add(20 * time.Second), Finish: t0.Add(40 * time.Second), Status: tracing.StatusError, Error: "conflict"},
+	}
+	var buf bytes.Buffer
+	printWaterfall(&buf, "gt-1", trace, spans)
+	out := buf.String()
+	for _, want := range []string{"3 spans over 40.0s", "  polecat.spawn", "+1.0s", "conflict"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("waterfall missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/eventbus"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/webhooks"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var (
+	webhooksListJSON     bool
+	webhooksTestType     string
+	webhooksReplayName   string
+	webhooksReplayDryRun bool
+)
+
+var webhooksCmd = &cobra.Command{
+	Use:     "webhooks",
+	GroupID: GroupServices,
+	Short:   "Manage outbound webhooks for town events",
+	RunE:    requireSubcommand,
+	Long: `Manage outbound webhooks.
+
+The daemon POSTs town events to the endpoints configured in
+settings/webhooks.json, signed with HMAC-SHA256 when a secret is set:
+
+  {
+    "type": "webhooks",
+    "version": 1,
+    "endpoints": [
+      {
+        "name": "chat",
+        "url": "https://hooks.example.com/gastown",
+        "events": ["merged", "merge_failed", "escalation_sent"],
+        "secret_env": "GT_WEBHOOK_SECRET"
+      }
+    ]
+  }
+
+Endpoints without "events" receive sling, done, merged, merge_failed,
+escalation_sent, session_death, mass_death and convoy_closed; "*" means
+every feed event.
+
+Each request carries X-Gastown-Event, X-Gastown-Delivery,
+X-Gastown-Timestamp and, with a secret, X-Gastown-Signature:
+"sha256=" + hex(HMAC-SHA256(secret, "<timestamp>.<body>")).
+
+Failed deliveries are retried with exponential backoff (max_attempts,
+default 5; 4xx responses other than 429 are not retried) and then written
+to daemon/webhooks-dead.jsonl for 'gt webhooks replay'. Config edits are
+picked up by the running daemon.
+
+To receive webhooks from GitHub, Gitea and other systems, see
+'gt serve hooks'.`,
+}
+
+var webhooksListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List configured endpoints and failed deliveries",
+	Args:  cobra.NoArgs,
+	RunE:  runWebhooksList,
+}
+
+var webhooksTestCmd = &cobra.Command{
+	Use:   "test <endpoint>",
+	Short: "Send a test event to an endpoint",
+	Long: `Send a synthetic event to an endpoint once, without retries, and
+report the response. Use --type to send a specific event type.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runWebhooksTest,
+}
+
+var webhooksReplayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Retry dead-lettered deliveries",
+	Long: `Retry deliveries from daemon/webhooks-dead.jsonl using the current
+endpoint config. Successful deliveries are removed from the file; failures
+stay for the next replay.`,
+	Args: cobra.NoArgs,
+	RunE: runWebhooksReplay,
+}
+
+func init() {
+	webhooksListCmd.Flags().BoolVar(&webhooksListJSON, "json", false, "Output as JSON")
+	webhooksTestCmd.Flags().StringVar(&webhooksTestType, "type", "webhook_test", "Event type to send")
+	webhooksReplayCmd.Flags().StringVar(&webhooksReplayName, "endpoint", "", "Only replay deliveries for this endpoint")
+	webhooksReplayCmd.Flags().BoolVar(&webhooksReplayDryRun, "dry-run", false, "Show what would be replayed")
+
+	webhooksCmd.AddCommand(webhooksListCmd)
+	webhooksCmd.AddCommand(webhooksTestCmd)
+	webhooksCmd.AddCommand(webhooksReplayCmd)
+	rootCmd.AddCommand(webhooksCmd)
+}
+
+func loadWebhooks() (string, *webhooks.Config, error) {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return "", nil, fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	cfg, err := webhooks.LoadConfig(townRoot)
+	if err != nil {
+		return "", nil, err
+	}
+	return townRoot, cfg, nil
+}
+
+func runWebhooksList(cmd *cobra.Command, args []string) error {
+	townRoot, cfg, err := loadWebhooks()
+	if err != nil {
+		return err
+	}
+	dead, err := webhooks.ReadDeadLetters(townRoot)
+	if err != nil {
+		return err
+	}
+	deadBy := make(map[string]int)
+	for _, dl := range dead {
+		deadBy[dl.Endpoint]++
+	}
+
+	if webhooksListJSON {
+		type row struct {
+			Name     string   `json:"name"`
+			URL      string   `json:"url"`
+			Events   []string `json:"events"`
+			Signed   bool     `json:"signed"`
+			Disabled bool     `json:"disabled,omitempty"`
+			Dead     int      `json:"dead_letters"`
+		}
+		rows := make([]row, 0, len(cfg.Endpoints))
+		for i := range cfg.Endpoints {
+			ep := &cfg.Endpoints[i]
+			rows = append(rows, row{ep.Name, ep.URL, ep.EventTypes(), ep.SecretValue() != "", ep.Disabled, deadBy[ep.Name]})
+		}
+		data, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(cfg.Endpoints) == 0 {
+		fmt.Printf("No webhooks configured. Add endpoints to %s\n", style.Dim.Render(webhooks.ConfigPath(townRoot)))
+		return nil
+	}
+	for i := range cfg.Endpoints {
+		ep := &cfg.Endpoints[i]
+		name := style.Bold.Render(ep.Name)
+		if ep.Disabled {
+			name += style.Dim.Render(" (disabled)")
+		}
+		fmt.Printf("%s %s\n", name, ep.URL)
+		fmt.Printf("  events: %s\n", strings.Join(ep.EventTypes(), ", "))
+		switch {
+		case ep.SecretValue() != "":
+			fmt.Println("  signed: yes")
+		case ep.SecretEnv != "":
+			fmt.Printf("  signed: %s\n", style.Warning.Render("no ($"+ep.SecretEnv+" is empty)"))
+		default:
+			fmt.Println("  signed: no")
+		}
+		if n := deadBy[ep.Name]; n > 0 {
+			fmt.Printf("  %s %d failed deliver%s (gt webhooks replay --endpoint %s)\n",
+				style.WarningPrefix, n, pluralY(n), ep.Name)
+		}
+	}
+	return nil
+}
+
+func pluralY(n int) string {
+	if n == 1 {
+		return "y"
+	}
+	return "ies"
+}
+
+func runWebhooksTest(cmd *cobra.Command, args []string) error {
+	_, cfg, err := loadWebhooks()
+	if err != nil {
+		return err
+	}
+	ep := cfg.Endpoint(args[0])
+	if ep == nil {
+		return fmt.Errorf("no webhook endpoint named %q", args[0])
+	}
+
+	ev := eventbus.Event{
+		Version:    eventbus.SchemaVersion,
+		ID:         "test",
+		Timestamp:  time.Now().UTC().Format(time.RFC3339),
+		Source:     "gt",
+		Type:       webhooksTestType,
+		Actor:      detectActor(),
+		Payload:    map[string]interface{}{"message": "Test delivery from gt webhooks test"},
+		Visibility: eventbus.VisibilityFeed,
+	}
+	p := webhooks.NewPayload(ev)
+	status, err := webhooks.NewSender().Send(context.Background(), ep, p)
+	if err != nil {
+		return fmt.Errorf("delivery %s to %s failed: %w", p.Delivery, ep.Name, err)
+	}
+	fmt.Printf("%s Delivered %s to %s (HTTP %d, delivery %s)\n", style.SuccessPrefix, ev.Type, ep.Name, status, p.Delivery)
+	return nil
+}
+
+func runWebhooksReplay(cmd *cobra.Command, args []string) error {
+	townRoot, cfg, err := loadWebhooks()
+	if err != nil {
+		return err
+	}
+	dead, err := webhooks.ReadDeadLetters(townRoot)
+	if err != nil {
+		return err
+	}
+
+	sender := webhooks.NewSender()
+	delivered := make(map[string]bool)
+	failed, skipped := 0, 0
+	for _, dl := range dead {
+		if webhooksReplayName != "" && dl.Endpoint != webhooksReplayName {
+			continue
+		}
+		ep := cfg.Endpoint(dl.Endpoint)
+		if ep == nil || ep.Disabled {
+			skipped++
+			continue
+		}
+		if webhooksReplayDryRun {
+			fmt.Printf("Would replay %s %s to %s (failed %s: %s)\n",
+				dl.Payload.Event.Type, dl.Payload.Delivery, ep.Name, formatAge(dl.FailedAt), dl.Error)
+			continue
+		}
+
+		r := sender.Deliver(context.Background(), ep, dl.Payload)
+		if r.Err != nil {
+			failed++
+			fmt.Printf("%s %s %s to %s: %v\n", style.WarningPrefix, dl.Payload.Event.Type, dl.Payload.Delivery, ep.Name, r.Err)
+			continue
+		}
+		delivered[dl.Payload.Delivery] = true
+		fmt.Printf("%s Delivered %s %s to %s\n", style.SuccessPrefix, dl.Payload.Event.Type, dl.Payload.Delivery, ep.Name)
+	}
+
+	if len(delivered) > 0 {
+		if err := webhooks.RemoveDeadLetters(townRoot, delivered); err != nil {
+			return err
+		}
+	}
+	if skipped > 0 {
+		fmt.Fprintf(os.Stderr, "%s %d deliver%s skipped: endpoint removed or disabled\n", style.WarningPrefix, skipped, pluralY(skipped))
+	}
+	if webhooksReplayDryRun {
+		return nil
+	}
+	fmt.Printf("Replayed %d, %d still failing\n", len(delivered), failed)
+	if failed > 0 {
+		return NewSilentExit(1)
+	}
+	return nil
+}
@@ -19,6 +19,9 @@ var (
 	witnessStatusJSON    bool
 	witnessAgentOverride string
 	witnessEnvOverrides  []string
+	witnessSpawnDryRun   bool
+	witnessSpawnJSON     bool
+	witnessPanesJSON     bool
 )
 
 var witnessCmd = &cobra.Command{
@@ -119,6 +122,59 @@ Examples:
 	RunE: runWitnessRestart,
 }
 
+var witnessAutospawnCmd = &cobra.Command{
+	Use:   "autospawn <rig>",
+	Short: "Spawn polecats for ready issues, within the witness limits",
+	Long: `Sling the rig's ready issues to new polecats, as configured for the Witness.
+
+Run by the Witness patrol each cycle. Unassigned ready issues (tasks, bugs,
+features and chores) are taken highest priority first, up to max_workers
+working polecats, spawn_delay_ms apart. Spawning pauses while the merge
+queue has max_queue_depth or more open merge requests.
+
+Settings come from the "witness" section of <rig>/settings/config.json,
+falling back to the witness role bead (hq-witness-role, "max_workers: 4"
+lines in its description), then to the defaults:
+
+  {
+    "witness": {
+      "max_workers": 4,
+      "spawn_delay_ms": 5000,
+      "auto_spawn": true,
+      "epic_id": "gt-epic1",
+      "issue_prefix": "gt-",
+      "max_queue_depth": 10
+    }
+  }
+
+Examples:
+  gt witness autospawn greenplace
+  gt witness autospawn greenplace --dry-run`,
+	Args: cobra.ExactArgs(1),
+	RunE: runWitnessAutospawn,
+}
+
+var witnessPanesCmd = &cobra.Command{
+	Use:   "panes <rig>",
+	Short: "Classify what each polecat's session is doing",
+	Long: `Classify the recent output of every running polecat in a rig.
+
+Each pane is reported as working, idle, permission (a permission or trust
+dialog is waiting), waiting_input (the agent asked a question), error_loop
+(the same error over and over), repeating (identical output repeating) or
+empty. Patterns come from the polecat runtime's tmux.panes config, with
+defaults for each provider.
+
+Stuck panes (permission, waiting_input, error_loop, repeating) are logged
+to the feed as pane_state events. Run by the Witness patrol each cycle.
+
+Examples:
+  gt witness panes greenplace
+  gt witness panes greenplace --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runWitnessPanes,
+}
+
 func init() {
 	// Start flags
 	witnessStartCmd.Flags().BoolVar(&witnessForeground, "foreground", false, "Run in foreground (default: background)")
@@ -132,12 +188,21 @@ func init() {
 	witnessRestartCmd.Flags().StringVar(&witnessAgentOverride, "agent", "", "Agent alias to run the Witness with (overrides town default)")
 	witnessRestartCmd.Flags().StringArrayVar(&witnessEnvOverrides, "env", nil, "Environment variable override (KEY=VALUE, can be repeated)")
 
+	// Autospawn flags
+	witnessAutospawnCmd.Flags().BoolVarP(&witnessSpawnDryRun, "dry-run", "n", false, "Show what would be spawned")
+	witnessAutospawnCmd.Flags().BoolVar(&witnessSpawnJSON, "json", false, "Output as JSON")
+
+	// Panes flags
+	witnessPanesCmd.Flags().BoolVar(&witnessPanesJSON, "json", false, "Output as JSON")
+
 	// Add subcommands
 	witnessCmd.AddCommand(witnessStartCmd)
 	witnessCmd.AddCommand(witnessStopCmd)
 	witnessCmd.AddCommand(witnessRestartCmd)
 	witnessCmd.AddCommand(witnessStatusCmd)
 	witnessCmd.AddCommand(witnessAttachCmd)
+	witnessCmd.AddCommand(witnessAutospawnCmd)
+	witnessCmd.AddCommand(witnessPanesCmd)
 
 	rootCmd.AddCommand(witnessCmd)
 }
@@ -285,6 +350,91 @@ func runWitnessStatus(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func runWitnessAutospawn(cmd *cobra.Command, args []string) error {
+	mgr, err := getWitnessManager(args[0])
+	if err != nil {
+		return err
+	}
+	plan, err := mgr.AutoSpawn(witnessSpawnDryRun)
+	if err != nil {
+		return fmt.Errorf("planning spawns: %w", err)
+	}
+
+	if witnessSpawnJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(plan)
+	}
+
+	cfg := plan.Config
+	fmt.Printf("%s Autospawn: %s\n", style.Bold.Render(AgentTypeIcons[AgentWitness]), args[0])
+	fmt.Printf("  Workers: %d/%d, merge queue: %d, eligible issues: %d\n",
+		plan.Active, cfg.MaxWorkers, plan.QueueDepth, plan.Eligible)
+	if len(plan.Spawn) == 0 {
+		fmt.Printf("  %s\n", style.Dim.Render("Nothing to spawn: "+plan.Reason))
+		return nil
+	}
+
+	failed := false
+	for _, item := range plan.Spawn {
+		switch {
+		case witnessSpawnDryRun:
+			fmt.Printf("  Would sling %s (P%d) %s\n", item.ID, item.Priority, style.Dim.Render(item.Title))
+		case item.Skipped != "":
+			fmt.Printf("  %s\n", style.Dim.Render(fmt.Sprintf("- Skipped %s: %s", item.ID, item.Skipped)))
+		case item.Error != "":
+			failed = true
+			fmt.Printf("  %s %s: %s\n", style.ErrorPrefix, item.ID, item.Error)
+		default:
+			fmt.Printf("  %s Slung %s (P%d) %s\n", style.SuccessPrefix, item.ID, item.Priority, style.Dim.Render(item.Title))
+		}
+	}
+	if plan.Reason != "" {
+		fmt.Printf("  %s\n", style.Dim.Render(plan.Reason))
+	}
+	if failed {
+		return NewSilentExit(1)
+	}
+	return nil
+}
+
+func runWitnessPanes(cmd *cobra.Command, args []string) error {
+	mgr, err := getWitnessManager(args[0])
+	if err != nil {
+		return err
+	}
+	panes, err := mgr.SurveyPanes()
+	if err != nil {
+		return err
+	}
+
+	if witnessPanesJSON {
+		if panes == nil {
+			panes = []witness.PolecatPane{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(panes)
+	}
+
+	fmt.Printf("%s Polecat panes: %s\n", style.Bold.Render(AgentTypeIcons[AgentWitness]), args[0])
+	if len(panes) == 0 {
+		fmt.Printf("  %s\n", style.Dim.Render("No running polecats"))
+		return nil
+	}
+	for _, p := range panes {
+		switch {
+		case p.Error != "":
+			fmt.Printf("  %s %s: %s\n", style.ErrorPrefix, p.Polecat, p.Error)
+		case p.State.Stuck():
+			fmt.Printf("  %s %-12s %s %s\n", style.Warning.Render("⚠"), p.Polecat, style.Warning.Render(string(p.State)), style.Dim.Render(p.Detail))
+		default:
+			fmt.Printf("  %s %-12s %s\n", style.Dim.Render("○"), p.Polecat, p.State)
+		}
+	}
+	return nil
+}
+
 // witnessSessionName returns the tmux session name for a rig's witness.
 func witnessSessionName(rigName string) string {
 	return fmt.Sprintf("gt-%s-witness", rigName)
@@ -76,6 +76,10 @@ type AgentPresetInfo struct {
 
 	// NonInteractive contains settings for non-interactive mode.
 	NonInteractive *NonInteractiveConfig `json:"non_interactive,omitempty"`
+
+	// StartupPrompts are dialogs the agent may show at startup, answered
+	// automatically when a session is started (see StartupPrompt).
+	StartupPrompts []StartupPrompt `json:"startup_prompts,omitempty"`
 }
 
 // NonInteractiveConfig contains settings for running agents non-interactively.
@@ -116,6 +120,12 @@ var builtinPresets = map[AgentPreset]*AgentPresetInfo{
 		SupportsHooks:       true,
 		SupportsForkSession: true,
 		NonInteractive:      nil, // Claude is native non-interactive
+		StartupPrompts: []StartupPrompt{
+			// Option 1 "Yes, proceed" is preselected
+			{Name: "trust-folder", Pattern: `Do you trust the files in this folder\?`, Keys: []string{"Enter"}},
+			// Option 2 "Yes, I accept"; the default is to exit
+			{Name: "bypass-permissions", Pattern: `Bypass Permissions mode`, Keys: []string{"Down", "Enter"}},
+		},
 	},
 	AgentGemini: {
 		Name:                AgentGemini,
@@ -131,6 +141,10 @@ var builtinPresets = map[AgentPreset]*AgentPresetInfo{
 			PromptFlag: "-p",
 			OutputFlag: "--output-format json",
 		},
+		StartupPrompts: []StartupPrompt{
+			// Option 1 "Trust folder" is preselected
+			{Name: "trust-folder", Pattern: `Do you trust this folder\?`, Keys: []string{"Enter"}},
+		},
 	},
 	AgentCodex: {
 		Name:                AgentCodex,
@@ -146,6 +160,10 @@ var builtinPresets = map[AgentPreset]*AgentPresetInfo{
 			Subcommand: "exec",
 			OutputFlag: "--json",
 		},
+		StartupPrompts: []StartupPrompt{
+			// Option 1 "Yes, allow Codex to work in this folder" is preselected
+			{Name: "trust-folder", Pattern: `allow Codex to work in this folder`, Keys: []string{"Enter"}},
+		},
 	},
 	AgentCursor: {
 		Name:                AgentCursor,
@@ -161,6 +179,10 @@ var builtinPresets = map[AgentPreset]*AgentPresetInfo{
 			PromptFlag: "-p",
 			OutputFlag: "--output-format json",
 		},
+		StartupPrompts: []StartupPrompt{
+			// "[a] Trust this workspace"
+			{Name: "trust-workspace", Pattern: `(?i)workspace trust required`, Keys: []string{"a"}},
+		},
 	},
 	AgentAuggie: {
 		Name:                AgentAuggie,
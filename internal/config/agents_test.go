@@ -37,6 +37,55 @@ func TestBuiltinPresets(t *testing.T) {
 	}
 }
 
+func TestBuiltinStartupPrompts(t *testing.T) {
+	t.Parallel()
+	for name, preset := range builtinPresets {
+		for _, p := range preset.StartupPrompts {
+			if err := p.Validate(); err != nil {
+				t.Errorf("%s: %v", name, err)
+			}
+		}
+	}
+
+	rc := RuntimeConfigFromPreset(AgentGemini)
+	if len(rc.Tmux.StartupPrompts) != 1 || rc.Tmux.StartupPrompts[0].Name != "trust-folder" {
+		t.Errorf("gemini startup prompts = %+v", rc.Tmux.StartupPrompts)
+	}
+	rc.Tmux.StartupPrompts[0].Keys[0] = "Escape"
+	if builtinPresets[AgentGemini].StartupPrompts[0].Keys[0] != "Enter" {
+		t.Error("runtime config must not share startup prompts with the preset")
+	}
+
+	// An explicit empty list turns auto-answering off.
+	off := normalizeRuntimeConfig(&RuntimeConfig{Provider: "claude", Tmux: &RuntimeTmuxConfig{StartupPrompts: []StartupPrompt{}}})
+	if off.Tmux.StartupPrompts == nil || len(off.Tmux.StartupPrompts) != 0 {
+		t.Errorf("empty list replaced with %+v", off.Tmux.StartupPrompts)
+	}
+}
+
+func TestStartupPromptValidate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		prompt  StartupPrompt
+		wantErr string
+	}{
+		{StartupPrompt{Name: "ok", Pattern: "trust", Keys: []string{"Down", "Enter"}}, ""},
+		{StartupPrompt{Name: "text", Pattern: "trust", Keys: []string{"yes please"}}, "not allowed"},
+		{StartupPrompt{Name: "long", Pattern: "trust", Keys: []string{"Down", "Down", "Down", "Down", "Enter"}}, "keys"},
+		{StartupPrompt{Name: "regex", Pattern: "[", Keys: []string{"Enter"}}, "invalid pattern"},
+		{StartupPrompt{Pattern: "trust", Keys: []string{"Enter"}}, "missing name"},
+	}
+	for _, tt := range tests {
+		err := tt.prompt.Validate()
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("%s: unexpected error %v", tt.prompt.Name, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%s: error = %v, want %q", tt.prompt.Name, err, tt.wantErr)
+		}
+	}
+}
+
 func TestGetAgentPresetByName(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -199,6 +200,12 @@ func validateRigConfig(c *RigConfig) error {
 	return nil
 }
 
+// ValidateRigSettings checks rig settings the way LoadRigSettings and
+// SaveRigSettings do, for settings built in memory.
+func ValidateRigSettings(c *RigSettings) error {
+	return validateRigSettings(c)
+}
+
 // validateRigSettings validates a RigSettings.
 func validateRigSettings(c *RigSettings) error {
 	if c.Type != "rig-settings" && c.Type != "" {
@@ -212,6 +219,56 @@ func validateRigSettings(c *RigSettings) error {
 			return err
 		}
 	}
+	if c.Sandbox != nil {
+		if err := validateSandboxConfig(c.Sandbox); err != nil {
+			return err
+		}
+	}
+	if c.Witness != nil {
+		if err := validateWitnessSettings(c.Witness); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ErrInvalidWitness indicates an invalid witness setting.
+var ErrInvalidWitness = errors.New("invalid witness config")
+
+// validateWitnessSettings validates a WitnessSettings.
+func validateWitnessSettings(c *WitnessSettings) error {
+	if c.MaxWorkers != nil && *c.MaxWorkers <= 0 {
+		return fmt.Errorf("%w: max_workers must be positive", ErrInvalidWitness)
+	}
+	if c.SpawnDelayMs < 0 {
+		return fmt.Errorf("%w: spawn_delay_ms must not be negative", ErrInvalidWitness)
+	}
+	return nil
+}
+
+// ErrInvalidSandbox indicates an invalid sandbox setting.
+var ErrInvalidSandbox = errors.New("invalid sandbox config")
+
+// validateSandboxConfig validates a SandboxConfig.
+func validateSandboxConfig(c *SandboxConfig) error {
+	switch c.Runtime {
+	case "", "auto", "podman", "bwrap":
+	default:
+		return fmt.Errorf("%w: runtime '%s', want 'auto', 'podman' or 'bwrap'", ErrInvalidSandbox, c.Runtime)
+	}
+	switch c.Network {
+	case "", "restricted", "none", "host":
+	default:
+		return fmt.Errorf("%w: network '%s', want 'restricted', 'none' or 'host'", ErrInvalidSandbox, c.Network)
+	}
+	if c.Runtime == "bwrap" && (c.Network == "" || c.Network == "restricted") {
+		return fmt.Errorf("%w: network 'restricted' requires podman; set network to 'none' or 'host' for bwrap", ErrInvalidSandbox)
+	}
+	for _, p := range c.ReadOnlyMounts {
+		if !filepath.IsAbs(p) {
+			return fmt.Errorf("%w: ro_mounts entry '%s' must be an absolute path", ErrInvalidSandbox, p)
+		}
+	}
 	return nil
 }
 
@@ -1054,6 +1111,30 @@ func ResolveRoleAgentName(role, townRoot, rigPath string) (agentName string, isR
 	return "claude", false
 }
 
+// ResolveAgentCapabilities returns the capabilities advertised by an agent
+// (preset or custom) for routing: town-level agent_capabilities plus any
+// rig-level additions. The result is de-duplicated; nil if none are declared.
+func ResolveAgentCapabilities(townRoot, rigPath, agentName string) []string {
+	var caps []string
+	add := func(list []string) {
+		for _, c := range list {
+			if !slices.Contains(caps, c) {
+				caps = append(caps, c)
+			}
+		}
+	}
+
+	if townSettings, err := LoadOrCreateTownSettings(TownSettingsPath(townRoot)); err == nil {
+		add(townSettings.AgentCapabilities[agentName])
+	}
+	if rigPath != "" {
+		if rigSettings, err := LoadRigSettings(RigSettingsPath(rigPath)); err == nil {
+			add(rigSettings.AgentCapabilities[agentName])
+		}
+	}
+	return caps
+}
+
 // lookupAgentConfig looks up an agent by name.
 // Checks rig-level custom agents first, then town's custom agents, then built-in presets from agents.go.
 func lookupAgentConfig(name string, townSettings *TownSettings, rigSettings *RigSettings) *RuntimeConfig {
@@ -1162,6 +1243,10 @@ func fillRuntimeDefaults(rc *RuntimeConfig) *RuntimeConfig {
 			result.Tmux.ProcessNames = make([]string, len(rc.Tmux.ProcessNames))
 			copy(result.Tmux.ProcessNames, rc.Tmux.ProcessNames)
 		}
+		if rc.Tmux.Panes != nil {
+			result.Tmux.Panes = copyPanePatterns(rc.Tmux.Panes)
+		}
+		result.Tmux.StartupPrompts = copyStartupPrompts(rc.Tmux.StartupPrompts)
 	}
 
 	if rc.Instructions != nil {
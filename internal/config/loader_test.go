@@ -4,6 +4,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"strings"
 	"testing"
@@ -344,6 +345,83 @@ func TestRigSettingsValidation(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid sandbox",
+			settings: &RigSettings{
+				Type:    "rig-settings",
+				Version: 1,
+				Sandbox: &SandboxConfig{
+					Enabled:        true,
+					Runtime:        "podman",
+					Network:        "restricted",
+					ReadOnlyMounts: []string{"/opt/rust"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid sandbox runtime",
+			settings: &RigSettings{
+				Type:    "rig-settings",
+				Version: 1,
+				Sandbox: &SandboxConfig{Enabled: true, Runtime: "docker"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "bwrap sandbox with restricted network",
+			settings: &RigSettings{
+				Type:    "rig-settings",
+				Version: 1,
+				Sandbox: &SandboxConfig{Enabled: true, Runtime: "bwrap"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "relative sandbox mount",
+			settings: &RigSettings{
+				Type:    "rig-settings",
+				Version: 1,
+				Sandbox: &SandboxConfig{Enabled: true, ReadOnlyMounts: []string{"tools"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid witness",
+			settings: &RigSettings{
+				Type:    "rig-settings",
+				Version: 1,
+				Witness: &WitnessSettings{MaxWorkers: intPtr(3), SpawnDelayMs: 1000, MaxQueueDepth: -1},
+			},
+			wantErr: false,
+		},
+		{
+			name: "witness without max_workers",
+			settings: &RigSettings{
+				Type:    "rig-settings",
+				Version: 1,
+				Witness: &WitnessSettings{SpawnDelayMs: 1000},
+			},
+			wantErr: false,
+		},
+		{
+			name: "negative witness max_workers",
+			settings: &RigSettings{
+				Type:    "rig-settings",
+				Version: 1,
+				Witness: &WitnessSettings{MaxWorkers: intPtr(-1)},
+			},
+			wantErr: true,
+		},
+		{
+			name: "zero witness max_workers",
+			settings: &RigSettings{
+				Type:    "rig-settings",
+				Version: 1,
+				Witness: &WitnessSettings{MaxWorkers: intPtr(0)},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -841,6 +919,30 @@ func TestRuntimeConfigDefaults(t *testing.T) {
 	}
 }
 
+func TestRuntimeConfigPanePatterns(t *testing.T) {
+	t.Parallel()
+	if p := DefaultRuntimeConfig().PanePatterns(); len(p.Busy) == 0 || len(p.Permission) == 0 || len(p.Idle) == 0 {
+		t.Errorf("claude pane patterns = %+v", p)
+	}
+
+	// Provider inferred from the command; custom lists replace only themselves.
+	rc := &RuntimeConfig{
+		Command: "/usr/local/bin/codex",
+		Tmux:    &RuntimeTmuxConfig{Panes: &RuntimePanePatterns{Busy: []string{"thinking"}}},
+	}
+	p := rc.PanePatterns()
+	if len(p.Busy) != 1 || p.Busy[0] != "thinking" {
+		t.Errorf("Busy = %v, want custom pattern", p.Busy)
+	}
+	if len(p.Permission) == 0 || len(p.Error) == 0 {
+		t.Errorf("defaults missing: %+v", p)
+	}
+	p.Busy[0] = "changed"
+	if rc.Tmux.Panes.Busy[0] != "thinking" {
+		t.Error("PanePatterns must not share slices with the config")
+	}
+}
+
 func TestRuntimeConfigBuildCommand(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -3515,3 +3617,36 @@ func TestBuildStartupCommandWithAgentOverride_NoGTAgentWhenNoOverride(t *testing
 		t.Errorf("expected no GT_AGENT in command when no override, got: %q", cmd)
 	}
 }
+
+func TestResolveAgentCapabilities(t *testing.T) {
+	t.Parallel()
+	townRoot := t.TempDir()
+	rigPath := filepath.Join(townRoot, "testrig")
+
+	townSettings := NewTownSettings()
+	townSettings.AgentCapabilities = map[string][]string{
+		"codex":  {"go", "db"},
+		"claude": {"docs"},
+	}
+	if err := SaveTownSettings(TownSettingsPath(townRoot), townSettings); err != nil {
+		t.Fatalf("SaveTownSettings: %v", err)
+	}
+	rigSettings := NewRigSettings()
+	rigSettings.AgentCapabilities = map[string][]string{"codex": {"db", "rust"}}
+	if err := SaveRigSettings(RigSettingsPath(rigPath), rigSettings); err != nil {
+		t.Fatalf("SaveRigSettings: %v", err)
+	}
+
+	got := ResolveAgentCapabilities(townRoot, rigPath, "codex")
+	if want := []string{"go", "db", "rust"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("codex caps = %v, want %v", got, want)
+	}
+	if got := ResolveAgentCapabilities(townRoot, "", "codex"); !reflect.DeepEqual(got, []string{"go", "db"}) {
+		t.Errorf("town-only codex caps = %v", got)
+	}
+	if got := ResolveAgentCapabilities(townRoot, rigPath, "gemini"); got != nil {
+		t.Errorf("unknown agent caps = %v, want nil", got)
+	}
+}
+
+func intPtr(n int) *int { return &n }
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -33,11 +34,19 @@ type RoleDefinition struct {
 	// Health contains health check configuration.
 	Health RoleHealthConfig `toml:"health"`
 
+	// Resources contains optional cgroup resource limits for the agent.
+	Resources RoleResourcesConfig `toml:"resources"`
+
 	// Nudge is the initial prompt sent when starting the agent.
 	Nudge string `toml:"nudge,omitempty"`
 
 	// PromptTemplate is the name of the role's prompt template file.
 	PromptTemplate string `toml:"prompt_template,omitempty"`
+
+	// Capabilities are advertised by every agent in this role for
+	// capability-based routing (e.g., ["rust"] when the rig's polecats
+	// all have the Rust toolchain). Overrides add to, not replace, the list.
+	Capabilities []string `toml:"capabilities,omitempty"`
 }
 
 // RoleSessionConfig contains session-related configuration.
@@ -75,6 +84,29 @@ type RoleHealthConfig struct {
 	StuckThreshold Duration `toml:"stuck_threshold"`
 }
 
+// RoleResourcesConfig bounds the memory, CPU and task usage of an agent
+// session. Empty values leave the resource unlimited.
+type RoleResourcesConfig struct {
+	// MemoryMax is the hard memory limit (e.g., "4G", "512M").
+	MemoryMax string `toml:"memory_max,omitempty"`
+
+	// CPUWeight is the relative CPU weight (1-10000, default 100).
+	// Lower values yield CPU to other sessions under contention.
+	CPUWeight int `toml:"cpu_weight,omitempty"`
+
+	// PidsMax caps the number of processes and threads in the session.
+	PidsMax int `toml:"pids_max,omitempty"`
+
+	// Backend selects how limits are applied: "auto" (default), "systemd"
+	// (systemd-run --user --scope) or "cgroup" (cgroup v2 subtree).
+	Backend string `toml:"backend,omitempty"`
+}
+
+// IsZero reports whether no resource limits are configured.
+func (r RoleResourcesConfig) IsZero() bool {
+	return r.MemoryMax == "" && r.CPUWeight == 0 && r.PidsMax == 0
+}
+
 // Duration is a wrapper for time.Duration that supports TOML marshaling.
 type Duration struct {
 	time.Duration
@@ -247,6 +279,27 @@ func mergeRoleDefinition(base, override *RoleDefinition) {
 		base.Health.StuckThreshold = override.Health.StuckThreshold
 	}
 
+	// Resource limits
+	if override.Resources.MemoryMax != "" {
+		base.Resources.MemoryMax = override.Resources.MemoryMax
+	}
+	if override.Resources.CPUWeight != 0 {
+		base.Resources.CPUWeight = override.Resources.CPUWeight
+	}
+	if override.Resources.PidsMax != 0 {
+		base.Resources.PidsMax = override.Resources.PidsMax
+	}
+	if override.Resources.Backend != "" {
+		base.Resources.Backend = override.Resources.Backend
+	}
+
+	// Capabilities (union, preserving order)
+	for _, c := range override.Capabilities {
+		if !slices.Contains(base.Capabilities, c) {
+			base.Capabilities = append(base.Capabilities, c)
+		}
+	}
+
 	// Prompts
 	if override.Nudge != "" {
 		base.Nudge = override.Nudge
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -270,3 +272,72 @@ func TestToLegacyRoleConfig(t *testing.T) {
 		t.Errorf("ConsecutiveFailures = %d, want 3", legacy.ConsecutiveFailures)
 	}
 }
+
+func TestLoadRoleDefinition_ResourcesOverride(t *testing.T) {
+	townRoot := t.TempDir()
+	rigPath := filepath.Join(townRoot, "gastown")
+
+	writeRoleOverride(t, townRoot, "polecat", `
+[resources]
+memory_max = "4G"
+cpu_weight = 50
+`)
+	writeRoleOverride(t, rigPath, "polecat", `
+[resources]
+pids_max = 512
+backend = "cgroup"
+`)
+
+	def, err := LoadRoleDefinition(townRoot, rigPath, "polecat")
+	if err != nil {
+		t.Fatalf("LoadRoleDefinition: %v", err)
+	}
+
+	want := RoleResourcesConfig{MemoryMax: "4G", CPUWeight: 50, PidsMax: 512, Backend: "cgroup"}
+	if def.Resources != want {
+		t.Errorf("Resources = %+v, want %+v", def.Resources, want)
+	}
+	if def.Resources.IsZero() {
+		t.Error("IsZero() = true, want false")
+	}
+}
+
+func TestLoadBuiltinRoleDefinition_NoResourceLimits(t *testing.T) {
+	for _, role := range AllRoles() {
+		def, err := loadBuiltinRoleDefinition(role)
+		if err != nil {
+			t.Fatalf("loadBuiltinRoleDefinition(%s): %v", role, err)
+		}
+		if !def.Resources.IsZero() {
+			t.Errorf("%s: built-in resources = %+v, want unlimited", role, def.Resources)
+		}
+	}
+}
+
+func writeRoleOverride(t *testing.T, root, role, content string) {
+	t.Helper()
+	dir := filepath.Join(root, "roles")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, role+".toml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadRoleDefinition_CapabilitiesUnion(t *testing.T) {
+	townRoot := t.TempDir()
+	rigPath := filepath.Join(townRoot, "myrig")
+
+	writeRoleOverride(t, townRoot, "polecat", `capabilities = ["go", "rust"]`)
+	writeRoleOverride(t, rigPath, "polecat", `capabilities = ["rust", "db"]`)
+
+	def, err := LoadRoleDefinition(townRoot, rigPath, "polecat")
+	if err != nil {
+		t.Fatalf("LoadRoleDefinition: %v", err)
+	}
+	want := []string{"go", "rust", "db"}
+	if strings.Join(def.Capabilities, ",") != strings.Join(want, ",") {
+		t.Errorf("Capabilities = %v, want %v", def.Capabilities, want)
+	}
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// StartupPrompt is a dialog a runtime may show when it starts (a workspace
+// trust prompt, a permissions-mode warning) and the keys that answer it.
+// Sessions are started unattended, so Gas Town answers these itself.
+type StartupPrompt struct {
+	// Name identifies the prompt in logs and events (e.g., "trust-folder").
+	Name string `json:"name"`
+
+	// Pattern is a regular expression matched against the lines of the
+	// pane's recent output.
+	Pattern string `json:"pattern"`
+
+	// Keys are tmux key names sent in order to answer the prompt
+	// (e.g., ["Down", "Enter"]). Only AllowedStartupPromptKeys may be used.
+	Keys []string `json:"keys"`
+}
+
+// AllowedStartupPromptKeys are the keys a startup prompt may send: menu
+// navigation, confirmation and single-character menu choices. Anything else,
+// such as text that could become a shell command if the pattern matched the
+// wrong screen, is rejected.
+var AllowedStartupPromptKeys = map[string]bool{
+	"Enter": true, "Escape": true, "Tab": true,
+	"Up": true, "Down": true, "Left": true, "Right": true,
+	"1": true, "2": true, "3": true,
+	"y": true, "a": true,
+}
+
+// maxStartupPromptKeys bounds how many keys one answer may send.
+const maxStartupPromptKeys = 4
+
+// Validate checks that the prompt has a name, a valid pattern and a short
+// answer made of allowed keys.
+func (p StartupPrompt) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("startup prompt missing name")
+	}
+	if p.Pattern == "" {
+		return fmt.Errorf("startup prompt %s: missing pattern", p.Name)
+	}
+	if _, err := regexp.Compile(p.Pattern); err != nil {
+		return fmt.Errorf("startup prompt %s: invalid pattern: %w", p.Name, err)
+	}
+	if len(p.Keys) == 0 || len(p.Keys) > maxStartupPromptKeys {
+		return fmt.Errorf("startup prompt %s: needs 1-%d keys, got %d", p.Name, maxStartupPromptKeys, len(p.Keys))
+	}
+	for _, key := range p.Keys {
+		if !AllowedStartupPromptKeys[key] {
+			return fmt.Errorf("startup prompt %s: key %q is not allowed", p.Name, key)
+		}
+	}
+	return nil
+}
+
+// defaultStartupPrompts returns a copy of the provider preset's startup
+// prompts, or nil for providers without a preset.
+func defaultStartupPrompts(provider string) []StartupPrompt {
+	info := GetAgentPresetByName(provider)
+	if info == nil {
+		return nil
+	}
+	return copyStartupPrompts(info.StartupPrompts)
+}
+
+func copyStartupPrompts(prompts []StartupPrompt) []StartupPrompt {
+	if prompts == nil {
+		return nil
+	}
+	out := make([]StartupPrompt, len(prompts))
+	for i, p := range prompts {
+		out[i] = p
+		out[i].Keys = append([]string(nil), p.Keys...)
+	}
+	return out
+}
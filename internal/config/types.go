@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 )
@@ -64,6 +65,12 @@ type TownSettings struct {
 	// Example: {"mayor": "claude-opus", "witness": "claude-haiku", "polecat": "claude-sonnet"}
 	RoleAgents map[string]string `json:"role_agents,omitempty"`
 
+	// AgentCapabilities maps agent names (presets or custom agents) to the
+	// capabilities they advertise for capability-based routing in gt sling.
+	// Beads request capabilities with "needs:<capability>" labels.
+	// Example: {"codex": ["db", "sql"], "claude-rust": ["rust"]}
+	AgentCapabilities map[string][]string `json:"agent_capabilities,omitempty"`
+
 	// AgentEmailDomain is the domain used for agent git identity emails.
 	// Agent addresses like "gastown/crew/jack" become "gastown.crew.jack@{domain}".
 	// Default: "gastown.local"
@@ -217,6 +224,8 @@ type RigSettings struct {
 	Crew       *CrewConfig       `json:"crew,omitempty"`        // crew startup settings
 	Workflow   *WorkflowConfig   `json:"workflow,omitempty"`    // workflow settings
 	Runtime    *RuntimeConfig    `json:"runtime,omitempty"`     // LLM runtime settings (deprecated: use Agent)
+	Sandbox    *SandboxConfig    `json:"sandbox,omitempty"`     // polecat container sandbox settings
+	Witness    *WitnessSettings  `json:"witness,omitempty"`     // witness auto-spawn settings
 
 	// Agent selects which agent preset to use for this rig.
 	// Can be a built-in preset ("claude", "gemini", "codex", "cursor", "auggie", "amp")
@@ -236,6 +245,11 @@ type RigSettings struct {
 	// Overrides TownSettings.RoleAgents for this specific rig.
 	// Example: {"witness": "claude-haiku", "polecat": "claude-sonnet"}
 	RoleAgents map[string]string `json:"role_agents,omitempty"`
+
+	// AgentCapabilities adds per-rig capabilities to agents, on top of
+	// TownSettings.AgentCapabilities (e.g., a rig whose image has the Rust
+	// toolchain can advertise "rust" for its default agent).
+	AgentCapabilities map[string][]string `json:"agent_capabilities,omitempty"`
 }
 
 // CrewConfig represents crew workspace settings for a rig.
@@ -250,6 +264,58 @@ type CrewConfig struct {
 	//   "max, but not emma"      - start max, skip emma
 	// If empty, defaults to starting no crew automatically.
 	Startup string `json:"startup,omitempty"`
+
+	// Capabilities maps crew member names to the capabilities they
+	// advertise for capability-based routing in gt sling.
+	// Example: {"max": ["rust", "db"]}
+	Capabilities map[string][]string `json:"capabilities,omitempty"`
+}
+
+// WitnessSettings represents a rig's witness auto-spawn settings. Unset
+// fields fall back to the witness role bead, then to built-in defaults.
+type WitnessSettings struct {
+	// MaxWorkers caps the polecats the witness keeps working at once.
+	MaxWorkers *int `json:"max_workers,omitempty"`
+
+	// SpawnDelayMs is the pause between two spawns, in milliseconds.
+	SpawnDelayMs int `json:"spawn_delay_ms,omitempty"`
+
+	// AutoSpawn lets the witness sling ready issues to new polecats.
+	AutoSpawn *bool `json:"auto_spawn,omitempty"`
+
+	// EpicID limits spawning to children of this epic.
+	EpicID string `json:"epic_id,omitempty"`
+
+	// IssuePrefix limits spawning to issues whose ID has this prefix.
+	IssuePrefix string `json:"issue_prefix,omitempty"`
+
+	// MaxQueueDepth pauses spawning while this many merge requests are
+	// open. Negative disables the back-off.
+	MaxQueueDepth int `json:"max_queue_depth,omitempty"`
+}
+
+// SandboxConfig represents container sandbox settings for a rig's polecats.
+// When enabled, each polecat runs inside a rootless container that can only
+// see its own workspace, the rig's shared beads and the repo's git objects.
+type SandboxConfig struct {
+	// Enabled opts the rig's polecats into sandboxed execution.
+	Enabled bool `json:"enabled"`
+
+	// Runtime selects the container runtime: "auto" (default), "podman" or "bwrap".
+	Runtime string `json:"runtime,omitempty"`
+
+	// Image is the container image used by podman.
+	// Default: sandbox.DefaultImage
+	Image string `json:"image,omitempty"`
+
+	// Network restricts network access: "restricted" (default, internet
+	// and the host Dolt server but no other host services; podman only),
+	// "none" or "host".
+	Network string `json:"network,omitempty"`
+
+	// ReadOnlyMounts are extra host paths exposed read-only inside the
+	// sandbox (e.g., a toolchain directory).
+	ReadOnlyMounts []string `json:"ro_mounts,omitempty"`
 }
 
 // RuntimeConfig represents LLM runtime configuration for agent sessions.
@@ -330,6 +396,34 @@ type RuntimeTmuxConfig struct {
 
 	// ReadyDelayMs is a fixed delay used when prompt detection is unavailable.
 	ReadyDelayMs int `json:"ready_delay_ms,omitempty"`
+
+	// Panes holds the output patterns used to classify what a session is
+	// doing (see tmux.PaneClassifier). Unset lists use provider defaults.
+	Panes *RuntimePanePatterns `json:"panes,omitempty"`
+
+	// StartupPrompts are the dialogs answered automatically after the
+	// runtime starts. Default: the provider preset's table. Set to an
+	// empty list to answer nothing.
+	StartupPrompts []StartupPrompt `json:"startup_prompts,omitempty"`
+}
+
+// RuntimePanePatterns are regular expressions matched against the lines of
+// a runtime's pane output.
+type RuntimePanePatterns struct {
+	// Busy patterns show the runtime is working (e.g., "esc to interrupt").
+	Busy []string `json:"busy,omitempty"`
+
+	// Idle patterns match an empty input prompt.
+	Idle []string `json:"idle,omitempty"`
+
+	// Permission patterns match a permission or trust dialog.
+	Permission []string `json:"permission,omitempty"`
+
+	// Input patterns match the runtime asking the user a question.
+	Input []string `json:"input,omitempty"`
+
+	// Error patterns match error output; several in a row are an error loop.
+	Error []string `json:"error,omitempty"`
 }
 
 // RuntimeInstructionsConfig controls the name of the role instruction file.
@@ -483,6 +577,14 @@ func normalizeRuntimeConfig(rc *RuntimeConfig) *RuntimeConfig {
 		rc.Tmux.ReadyDelayMs = defaultReadyDelayMs(rc.Provider)
 	}
 
+	rc.Tmux.Panes = fillPanePatterns(rc.Tmux.Panes, rc.Provider, rc.Tmux.ReadyPromptPrefix)
+
+	if rc.Tmux.StartupPrompts == nil {
+		rc.Tmux.StartupPrompts = defaultStartupPrompts(rc.Provider)
+	} else {
+		rc.Tmux.StartupPrompts = copyStartupPrompts(rc.Tmux.StartupPrompts)
+	}
+
 	if rc.Instructions == nil {
 		rc.Instructions = &RuntimeInstructionsConfig{}
 	}
@@ -638,6 +740,112 @@ func defaultReadyDelayMs(provider string) int {
 	return 0
 }
 
+// PanePatterns returns the runtime's pane classification patterns, with
+// defaults for the runtime's provider filled in for any unset list. The
+// provider is inferred from the command when it isn't set.
+func (rc *RuntimeConfig) PanePatterns() *RuntimePanePatterns {
+	resolved := &RuntimeConfig{Provider: "claude"}
+	if rc != nil {
+		resolved = rc
+		if resolved.Provider == "" && resolved.Command != "" {
+			if info := GetAgentPresetByName(filepath.Base(resolved.Command)); info != nil {
+				resolved = &RuntimeConfig{Provider: string(info.Name), Command: rc.Command, Tmux: rc.Tmux}
+			}
+		}
+	}
+	return normalizeRuntimeConfig(resolved).Tmux.Panes
+}
+
+// Generic pane patterns, used by every provider.
+var (
+	genericPermissionPatterns = []string{
+		`(?i)do you want to (proceed|continue|allow)`,
+		`(?i)\b(allow|approve) (this|the following) (command|action|edit|tool)`,
+		`(?i)do you trust (the files|this folder|the authors)`,
+	}
+	genericInputPatterns = []string{
+		`(?i)\((y/n|yes/no)\)|\[(y/n|Y/n|y/N)\]`,
+		`(?i)press (enter|any key) to continue`,
+		`(?i)(waiting for|need) your (input|response|decision)`,
+		`(?i)(should i|would you like me to|do you want me to|shall i)\b.*\?\s*$`,
+		`(?i)(please confirm|let me know (if|how|which|what|whether))`,
+	}
+	genericErrorPatterns = []string{
+		`(?i)^\s*(error|fatal|panic)\b[:!]?`,
+		`(?i)\bapi error\b`,
+		`(?i)\b(rate limit(ed)?|overloaded|connection (refused|reset))\b`,
+	}
+)
+
+// defaultPanePatterns returns a provider's pane patterns.
+func defaultPanePatterns(provider, readyPromptPrefix string) RuntimePanePatterns {
+	p := RuntimePanePatterns{
+		Permission: genericPermissionPatterns,
+		Input:      genericInputPatterns,
+		Error:      genericErrorPatterns,
+	}
+	if prefix := strings.TrimSpace(readyPromptPrefix); prefix != "" {
+		p.Idle = []string{`^\s*` + regexp.QuoteMeta(prefix) + `\s*$`}
+	} else {
+		p.Idle = []string{`^\s*[>$›]\s*$`}
+	}
+
+	switch provider {
+	case "claude":
+		p.Busy = []string{`(?i)esc to interrupt`, `(?i)^\s*[·✢✳✶✻✽*]\s+\w+…`}
+		p.Permission = append([]string{
+			`(?i)do you want to (make this edit|create|run|fetch|use)`,
+			`^\s*❯\s*1\.\s*Yes`,
+		}, genericPermissionPatterns...)
+	case "codex":
+		p.Busy = []string{`(?i)esc to interrupt`, `(?i)^\s*working\b`}
+		p.Permission = append([]string{
+			`(?i)allow command\?`,
+			`(?i)(approve|run) this command`,
+		}, genericPermissionPatterns...)
+	case "gemini":
+		p.Busy = []string{`(?i)esc to cancel`}
+		p.Permission = append([]string{`(?i)allow execution`}, genericPermissionPatterns...)
+	case "opencode":
+		p.Busy = []string{`(?i)esc (to )?interrupt`, `(?i)working\.\.\.`}
+	}
+	return p
+}
+
+// copyPanePatterns returns a deep copy of p.
+func copyPanePatterns(p *RuntimePanePatterns) *RuntimePanePatterns {
+	if p == nil {
+		return nil
+	}
+	return &RuntimePanePatterns{
+		Busy:       append([]string(nil), p.Busy...),
+		Idle:       append([]string(nil), p.Idle...),
+		Permission: append([]string(nil), p.Permission...),
+		Input:      append([]string(nil), p.Input...),
+		Error:      append([]string(nil), p.Error...),
+	}
+}
+
+// fillPanePatterns copies p and fills its unset lists with defaults.
+func fillPanePatterns(p *RuntimePanePatterns, provider, readyPromptPrefix string) *RuntimePanePatterns {
+	defaults := defaultPanePatterns(provider, readyPromptPrefix)
+	out := copyPanePatterns(p)
+	if out == nil {
+		out = &RuntimePanePatterns{}
+	}
+	fill := func(dst *[]string, def []string) {
+		if len(*dst) == 0 {
+			*dst = append([]string(nil), def...)
+		}
+	}
+	fill(&out.Busy, defaults.Busy)
+	fill(&out.Idle, defaults.Idle)
+	fill(&out.Permission, defaults.Permission)
+	fill(&out.Input, defaults.Input)
+	fill(&out.Error, defaults.Error)
+	return out
+}
+
 func defaultInstructionsFile(provider string) string {
 	if provider == "codex" {
 		return "AGENTS.md"
@@ -0,0 +1,243 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/krc"
+	"github.com/steveyegge/gastown/internal/scheduler"
+	"github.com/steveyegge/gastown/internal/webhooks"
+)
+
+// ReloadResult is the outcome of a config reload (OpReload or a change
+// picked up by the config watcher).
+type ReloadResult struct {
+	// Changes describes the settings that were applied.
+	Changes []string `json:"changes,omitempty"`
+
+	// Rejected lists files whose new contents were invalid. The daemon
+	// keeps running with the previous settings from those files.
+	Rejected []ConfigRejection `json:"rejected,omitempty"`
+}
+
+// ConfigRejection explains why a changed config file was not applied.
+type ConfigRejection struct {
+	File   string `json:"file"`
+	Reason string `json:"reason"`
+}
+
+// reloadConfig validates and applies changed config files. With no paths,
+// every watched file is reloaded. Must run on the main loop.
+func (d *Daemon) reloadConfig(paths []string) ReloadResult {
+	all := len(paths) == 0
+	if all {
+		paths = WatchedConfigFiles(d.config.TownRoot)
+	}
+
+	var result ReloadResult
+	for _, path := range paths {
+		rel := d.relConfigPath(path)
+
+		var changes []string
+		var err error
+		switch {
+		case path == PatrolConfigFile(d.config.TownRoot):
+			changes, err = d.reloadPatrolConfig()
+		case path == krc.ConfigFile(d.config.TownRoot):
+			changes, err = d.reloadKRCConfig()
+		case path == webhooks.ConfigPath(d.config.TownRoot):
+			changes, err = d.reloadWebhooks()
+		default:
+			changes, err = validateSettingsFile(path)
+			if all {
+				// Full reload: settings are only validated, not known changed.
+				changes = nil
+			}
+		}
+
+		if err != nil {
+			d.logger.Printf("Config change rejected: %s: %v", rel, err)
+			result.Rejected = append(result.Rejected, ConfigRejection{File: rel, Reason: err.Error()})
+			_ = events.LogFeed(events.TypeConfigRejected, "daemon", map[string]interface{}{
+				"file":   rel,
+				"reason": err.Error(),
+			})
+			continue
+		}
+
+		for _, c := range changes {
+			d.logger.Printf("Config reloaded: %s: %s", rel, c)
+		}
+		result.Changes = append(result.Changes, changes...)
+		if len(changes) > 0 {
+			_ = events.LogFeed(events.TypeConfigReloaded, "daemon", map[string]interface{}{
+				"file":    rel,
+				"changes": changes,
+			})
+		}
+	}
+	return result
+}
+
+func (d *Daemon) relConfigPath(path string) string {
+	if rel, err := filepath.Rel(d.config.TownRoot, path); err == nil {
+		return rel
+	}
+	return path
+}
+
+// reloadPatrolConfig applies mayor/daemon.json: patrol toggles, the
+// heartbeat interval, the work scheduler and Dolt server settings.
+func (d *Daemon) reloadPatrolConfig() ([]string, error) {
+	newConfig, err := ReadPatrolConfig(d.config.TownRoot)
+	if err != nil {
+		return nil, err
+	}
+	oldConfig := d.patrolConfig
+	d.patrolConfig = newConfig
+
+	var changes []string
+	for _, p := range []string{"deacon", "witness", "refinery"} {
+		was, now := IsPatrolEnabled(oldConfig, p), IsPatrolEnabled(newConfig, p)
+		if was != now {
+			changes = append(changes, fmt.Sprintf("%s patrol: enabled %v → %v", p, was, now))
+		}
+		if rw, rn := GetPatrolRigs(oldConfig, p), GetPatrolRigs(newConfig, p); !reflect.DeepEqual(rw, rn) {
+			changes = append(changes, fmt.Sprintf("%s patrol: rigs %v → %v", p, rw, rn))
+		}
+	}
+
+	if was, now := HeartbeatInterval(oldConfig), HeartbeatInterval(newConfig); was != now {
+		changes = append(changes, fmt.Sprintf("heartbeat interval: %v → %v", was, now))
+		if d.heartbeatTimer != nil {
+			d.heartbeatTimer.Reset(now)
+		}
+	}
+
+	if oldSched, newSched := SchedulerConfig(oldConfig), SchedulerConfig(newConfig); !reflect.DeepEqual(oldSched, newSched) {
+		d.startWorkScheduler()
+		switch {
+		case d.workScheduler == nil || !d.workScheduler.Enabled():
+			changes = append(changes, "work scheduler: disabled")
+		default:
+			changes = append(changes, fmt.Sprintf("work scheduler: enabled (interval %v)", newSched.IntervalDuration()))
+		}
+	}
+
+	if oldDolt, newDolt := doltConfig(oldConfig), doltConfig(newConfig); !reflect.DeepEqual(oldDolt, newDolt) {
+		if d.doltServer == nil {
+			d.doltServer = NewDoltServerManager(d.config.TownRoot, newDolt, d.logger.Printf)
+		} else if d.doltServer.UpdateConfig(newDolt) {
+			changes = append(changes, "dolt server: stopped to apply new bind settings")
+		}
+		changes = append(changes, "dolt server: settings updated")
+	}
+
+	return changes, nil
+}
+
+// doltConfig returns the Dolt server section of a patrol config, or nil.
+func doltConfig(config *DaemonPatrolConfig) *DoltServerConfig {
+	if config == nil || config.Patrols == nil {
+		return nil
+	}
+	return config.Patrols.DoltServer
+}
+
+// startWorkScheduler starts the work scheduler if it is enabled in the
+// current patrol config. A running scheduler takes the new config on its
+// next tick, so a reload never cancels a sling in flight.
+func (d *Daemon) startWorkScheduler() {
+	cfg := SchedulerConfig(d.patrolConfig)
+	if d.workScheduler != nil {
+		if cfg == nil {
+			cfg = &scheduler.Config{}
+		}
+		d.workScheduler.UpdateConfig(cfg)
+		return
+	}
+	if cfg == nil || !cfg.Enabled {
+		return
+	}
+	d.workScheduler = NewWorkScheduler(d.config.TownRoot, cfg, d.logger.Printf)
+	d.workScheduler.paused = func() bool {
+		return d.isShutdownInProgress() || d.isPatrolPaused("scheduler")
+	}
+	d.workScheduler.Start()
+	d.logger.Printf("Work scheduler started (interval %v)", cfg.IntervalDuration())
+}
+
+// reloadKRCConfig applies new TTLs and prune interval to the KRC pruner.
+func (d *Daemon) reloadKRCConfig() ([]string, error) {
+	cfg, err := krc.LoadConfig(d.config.TownRoot)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if d.krcPruner == nil {
+		return nil, nil
+	}
+	old := d.krcPruner.currentConfig()
+	if reflect.DeepEqual(old, cfg) {
+		return nil, nil
+	}
+	d.krcPruner.UpdateConfig(cfg)
+
+	changes := []string{"krc: TTLs updated"}
+	if old.PruneInterval != cfg.PruneInterval {
+		changes = append(changes, fmt.Sprintf("krc: prune interval %v → %v", old.PruneInterval, cfg.PruneInterval))
+	}
+	return changes, nil
+}
+
+// validateSettingsFile checks a settings/*.json file. The daemon reads
+// settings on each use, so a valid file needs no further action.
+func validateSettingsFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return []string{filepath.Base(path) + " removed"}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if filepath.Base(path) == filepath.Base(config.TownSettingsPath("")) {
+		var settings config.TownSettings
+		if err := json.Unmarshal(data, &settings); err != nil {
+			return nil, fmt.Errorf("parsing town settings: %w", err)
+		}
+	} else if !json.Valid(data) {
+		return nil, fmt.Errorf("invalid JSON")
+	}
+	return []string{filepath.Base(path) + " updated (applied on next use)"}, nil
+}
+
+// watchConfig starts the config watcher. Changes are applied on the main
+// loop so they never race a heartbeat.
+func (d *Daemon) watchConfig() {
+	d.configWatcher = NewConfigWatcher(d.config.TownRoot, func(ctx context.Context, changed []string) {
+		_ = d.onMainLoop(ctx, func() { d.reloadConfig(changed) })
+	})
+	d.configWatcher.Start()
+	d.logger.Printf("Config watcher started (polling every %v)", configPollInterval)
+}
+
+// reloadWebhooks applies settings/webhooks.json to the running dispatcher.
+func (d *Daemon) reloadWebhooks() ([]string, error) {
+	cfg, err := webhooks.LoadConfig(d.config.TownRoot)
+	if err != nil {
+		return nil, err
+	}
+	if d.webhooks == nil {
+		return []string{"webhooks: validated (event bus not running)"}, nil
+	}
+	d.webhooks.UpdateConfig(cfg)
+	return []string{fmt.Sprintf("webhooks: %d endpoint(s)", len(cfg.Endpoints))}, nil
+}
@@ -0,0 +1,244 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/scheduler"
+	"github.com/steveyegge/gastown/internal/webhooks"
+)
+
+func newReloadTestDaemon(t *testing.T) *Daemon {
+	t.Helper()
+	townRoot := t.TempDir()
+	for _, dir := range []string{"mayor", "settings", "daemon"} {
+		if err := os.MkdirAll(filepath.Join(townRoot, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	return &Daemon{
+		config: &Config{TownRoot: townRoot},
+		logger: log.New(io.Discard, "", 0),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReloadConfig_PatrolConfig(t *testing.T) {
+	d := newReloadTestDaemon(t)
+	path := PatrolConfigFile(d.config.TownRoot)
+	d.heartbeatTimer = time.NewTimer(time.Hour)
+	defer d.heartbeatTimer.Stop()
+
+	writeTestFile(t, path, `{
+		"heartbeat": {"enabled": true, "interval": "10m"},
+		"patrols": {"witness": {"enabled": false}}
+	}`)
+	result := d.reloadConfig([]string{path})
+	if len(result.Rejected) != 0 {
+		t.Fatalf("unexpected rejection: %+v", result.Rejected)
+	}
+	if IsPatrolEnabled(d.patrolConfig, "witness") {
+		t.Error("witness patrol should be disabled after reload")
+	}
+	joined := strings.Join(result.Changes, "\n")
+	for _, want := range []string{"witness patrol: enabled true → false", "heartbeat interval: 3m0s → 10m0s"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("changes missing %q:\n%s", want, joined)
+		}
+	}
+
+	// An invalid edit is rejected and the running config kept.
+	writeTestFile(t, path, `{"heartbeat": {"interval": "5s"}}`)
+	result = d.reloadConfig([]string{path})
+	if len(result.Rejected) != 1 || !strings.Contains(result.Rejected[0].Reason, "minimum") {
+		t.Fatalf("Rejected = %+v", result.Rejected)
+	}
+	if result.Rejected[0].File != filepath.Join("mayor", "daemon.json") {
+		t.Errorf("Rejected file = %q", result.Rejected[0].File)
+	}
+	if IsPatrolEnabled(d.patrolConfig, "witness") || HeartbeatInterval(d.patrolConfig) != 10*time.Minute {
+		t.Error("rejected config must not replace the running config")
+	}
+}
+
+func TestReloadConfig_KRC(t *testing.T) {
+	d := newReloadTestDaemon(t)
+	pruner, err := NewKRCPruner(d.config.TownRoot, d.logger.Printf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.krcPruner = pruner
+	path := filepath.Join(d.config.TownRoot, ".krc.yaml")
+
+	writeTestFile(t, path, `{"prune_interval": 7200000000000}`)
+	result := d.reloadConfig([]string{path})
+	if len(result.Rejected) != 0 {
+		t.Fatalf("unexpected rejection: %+v", result.Rejected)
+	}
+	if got := pruner.currentConfig().PruneInterval; got != 2*time.Hour {
+		t.Errorf("PruneInterval = %v, want 2h", got)
+	}
+
+	writeTestFile(t, path, `{"prune_interval": 1}`)
+	result = d.reloadConfig([]string{path})
+	if len(result.Rejected) != 1 {
+		t.Fatalf("expected rejection, got %+v", result)
+	}
+	if got := pruner.currentConfig().PruneInterval; got != 2*time.Hour {
+		t.Errorf("PruneInterval changed to %v after rejected reload", got)
+	}
+}
+
+func TestReloadConfig_SchedulerKeepsInFlightSling(t *testing.T) {
+	d := newReloadTestDaemon(t)
+	path := PatrolConfigFile(d.config.TownRoot)
+	writeTestFile(t, path, `{"patrols": {"scheduler": {"enabled": true, "interval": "1h"}}}`)
+	if result := d.reloadConfig([]string{path}); len(result.Rejected) != 0 {
+		t.Fatalf("unexpected rejection: %+v", result.Rejected)
+	}
+	ws := d.workScheduler
+	if ws == nil || !ws.Enabled() {
+		t.Fatal("scheduler not started")
+	}
+	defer ws.Stop()
+
+	// A sling is running when the scheduler is reconfigured, then disabled.
+	started, release := make(chan struct{}), make(chan struct{})
+	result := make(chan error, 1)
+	ws.dispatch = func(ctx context.Context, _ string, _ scheduler.Item) error {
+		close(started)
+		<-release
+		return ctx.Err()
+	}
+	go func() {
+		run := &scheduler.Run{At: time.Now()}
+		ws.execute(run, scheduler.Input{
+			Items: []scheduler.Item{{Bead: "gt-a", Rig: "gastown"}},
+			Rigs:  []scheduler.RigState{{Name: "gastown", Max: 1}},
+		})
+		if len(run.Dispatched) != 1 || run.Dispatched[0].Error != "" {
+			result <- fmt.Errorf("dispatched = %+v", run.Dispatched)
+			return
+		}
+		result <- nil
+	}()
+	<-started
+
+	writeTestFile(t, path, `{"patrols": {"scheduler": {"enabled": true, "interval": "2h"}}}`)
+	d.reloadConfig([]string{path})
+	writeTestFile(t, path, `{"patrols": {"scheduler": {"enabled": false}}}`)
+	changes := d.reloadConfig([]string{path}).Changes
+	if d.workScheduler != ws || ws.Enabled() {
+		t.Errorf("reload replaced or left enabled the scheduler (changes %v)", changes)
+	}
+	close(release)
+	if err := <-result; err != nil {
+		t.Errorf("in-flight sling was cancelled by reload: %v", err)
+	}
+}
+
+func TestReloadConfig_Settings(t *testing.T) {
+	d := newReloadTestDaemon(t)
+	path := filepath.Join(d.config.TownRoot, "settings", "config.json")
+
+	writeTestFile(t, path, `{"type": "town-settings"`)
+	if result := d.reloadConfig([]string{path}); len(result.Rejected) != 1 {
+		t.Errorf("truncated settings should be rejected: %+v", result)
+	}
+
+	writeTestFile(t, path, `{"type": "town-settings"}`)
+	if result := d.reloadConfig([]string{path}); len(result.Rejected) != 0 || len(result.Changes) != 1 {
+		t.Errorf("valid settings: %+v", result)
+	}
+}
+
+func TestConfigWatcher_Poll(t *testing.T) {
+	townRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(townRoot, "settings"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	settings := filepath.Join(townRoot, "settings", "config.json")
+	writeTestFile(t, settings, `{}`)
+
+	w := NewConfigWatcher(townRoot, nil)
+	w.stamps = w.scan()
+	if changed := w.poll(); len(changed) != 0 {
+		t.Fatalf("no edits, got %v", changed)
+	}
+
+	// Created file
+	krcFile := filepath.Join(townRoot, ".krc.yaml")
+	writeTestFile(t, krcFile, `{}`)
+	if changed := w.poll(); len(changed) != 1 || changed[0] != krcFile {
+		t.Errorf("after create: %v", changed)
+	}
+
+	// Modified file (size change, independent of mtime granularity)
+	writeTestFile(t, settings, `{"type": "town-settings"}`)
+	if changed := w.poll(); len(changed) != 1 || changed[0] != settings {
+		t.Errorf("after modify: %v", changed)
+	}
+
+	// Removed file
+	if err := os.Remove(krcFile); err != nil {
+		t.Fatal(err)
+	}
+	if changed := w.poll(); len(changed) != 1 || changed[0] != krcFile {
+		t.Errorf("after remove: %v", changed)
+	}
+}
+
+func TestDoltServerManager_UpdateConfig(t *testing.T) {
+	townRoot := t.TempDir()
+	m := NewDoltServerManager(townRoot, &DoltServerConfig{Enabled: false}, func(string, ...interface{}) {})
+
+	// Not managed before: nothing to stop.
+	if m.UpdateConfig(&DoltServerConfig{Enabled: true, Port: 3307}) {
+		t.Error("UpdateConfig should not stop an unmanaged server")
+	}
+	if !m.IsEnabled() || m.config.Port != 3307 {
+		t.Errorf("config not applied: %+v", m.config)
+	}
+
+	// Managed, restart-only settings changed: no stop needed.
+	if m.UpdateConfig(&DoltServerConfig{Enabled: true, Port: 3307, AutoRestart: true}) {
+		t.Error("non-bind change should not stop the server")
+	}
+
+	// Managed, port changed: stop for restart.
+	if !m.UpdateConfig(&DoltServerConfig{Enabled: true, Port: 3308}) {
+		t.Error("port change should stop the server")
+	}
+}
+
+func TestReloadConfig_Webhooks(t *testing.T) {
+	d := newReloadTestDaemon(t)
+	path := webhooks.ConfigPath(d.config.TownRoot)
+
+	writeTestFile(t, path, `{"endpoints": [{"name": "ci", "url": "not a url"}]}`)
+	if result := d.reloadConfig([]string{path}); len(result.Rejected) != 1 {
+		t.Errorf("invalid webhook URL should be rejected: %+v", result)
+	}
+
+	writeTestFile(t, path, `{"endpoints": [{"name": "ci", "url": "http://127.0.0.1:9/hook"}]}`)
+	if result := d.reloadConfig([]string{path}); len(result.Rejected) != 0 || len(result.Changes) != 1 {
+		t.Errorf("valid webhook config: %+v", result)
+	}
+}
@@ -0,0 +1,125 @@
+package daemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/krc"
+)
+
+// configPollInterval is how often the watcher checks config file mtimes.
+// Polling keeps the daemon free of platform-specific notify APIs; config
+// edits are rare and a few seconds of latency is fine.
+const configPollInterval = 5 * time.Second
+
+// WatchedConfigFiles returns the config files the daemon reloads live:
+// mayor/daemon.json, the KRC config and settings/*.json.
+func WatchedConfigFiles(townRoot string) []string {
+	files := []string{
+		PatrolConfigFile(townRoot),
+		krc.ConfigFile(townRoot),
+	}
+	settings, _ := filepath.Glob(filepath.Join(townRoot, "settings", "*.json"))
+	sort.Strings(settings)
+	return append(files, settings...)
+}
+
+// fileStamp identifies a version of a file. A zero stamp means missing.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// ConfigWatcher polls the daemon's config files and reports changes.
+// It runs as a background goroutine within the daemon.
+type ConfigWatcher struct {
+	townRoot string
+	interval time.Duration
+	onChange func(ctx context.Context, changed []string)
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+
+	stamps map[string]fileStamp
+}
+
+// NewConfigWatcher creates a watcher that calls onChange with the paths of
+// files that were created, modified or removed since the previous poll.
+func NewConfigWatcher(townRoot string, onChange func(ctx context.Context, changed []string)) *ConfigWatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ConfigWatcher{
+		townRoot: townRoot,
+		interval: configPollInterval,
+		onChange: onChange,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Start records the current file versions and begins polling.
+func (w *ConfigWatcher) Start() {
+	w.stamps = w.scan()
+	w.wg.Add(1)
+	go w.run()
+}
+
+// Stop gracefully stops the watcher.
+func (w *ConfigWatcher) Stop() {
+	w.cancel()
+	w.wg.Wait()
+}
+
+// run is the main watch loop.
+func (w *ConfigWatcher) run() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+			if changed := w.poll(); len(changed) > 0 {
+				w.onChange(w.ctx, changed)
+			}
+		}
+	}
+}
+
+// poll rescans the watched files and returns those whose stamp changed.
+func (w *ConfigWatcher) poll() []string {
+	current := w.scan()
+	var changed []string
+	for path, stamp := range current {
+		if w.stamps[path] != stamp {
+			changed = append(changed, path)
+		}
+	}
+	for path := range w.stamps {
+		if _, ok := current[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	w.stamps = current
+	sort.Strings(changed)
+	return changed
+}
+
+// scan stats every watched file. Missing files are omitted.
+func (w *ConfigWatcher) scan() map[string]fileStamp {
+	stamps := make(map[string]fileStamp)
+	for _, path := range WatchedConfigFiles(w.townRoot) {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		stamps[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+	}
+	return stamps
+}
@@ -0,0 +1,434 @@
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/polecat"
+)
+
+// Control socket operations. Each connection carries one JSON request line;
+// the daemon answers with one JSON response line, or a stream of log lines
+// terminated by a final response for OpLogs.
+const (
+	OpStatus         = "status"
+	OpHeartbeat      = "heartbeat"
+	OpReload         = "reload"
+	OpPause          = "pause"
+	OpResume         = "resume"
+	OpDeaths         = "deaths"
+	OpLogs           = "logs"
+	OpTriggerPending = "trigger-pending"
+)
+
+// PausablePatrols are the patrol names accepted by pause/resume.
+// "all" pauses every patrol at once.
+var PausablePatrols = []string{"deacon", "witness", "refinery", "scheduler"}
+
+// maxDeathLog bounds the session death history served over the socket.
+const maxDeathLog = 100
+
+// ControlSocketPath returns the daemon control socket path.
+func ControlSocketPath(townRoot string) string {
+	return filepath.Join(townRoot, "daemon", "daemon.sock")
+}
+
+// ControlRequest is a request sent over the control socket.
+type ControlRequest struct {
+	Op string `json:"op"`
+
+	// Patrols names the patrols for pause/resume.
+	Patrols []string `json:"patrols,omitempty"`
+
+	// Lines is the number of trailing log lines for logs.
+	Lines int `json:"lines,omitempty"`
+
+	// Follow keeps a logs stream open for new lines.
+	Follow bool `json:"follow,omitempty"`
+
+	// Timeout is the readiness timeout for trigger-pending (Go duration).
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// ControlResponse is a response (or, for logs, a streamed line).
+type ControlResponse struct {
+	OK     bool            `json:"ok"`
+	Error  string          `json:"error,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+
+	// Line is set on streamed log lines, which are not final responses.
+	Line *string `json:"line,omitempty"`
+}
+
+// ControlStatus is the result of OpStatus.
+type ControlStatus struct {
+	PID            int             `json:"pid"`
+	StartedAt      time.Time       `json:"started_at"`
+	LastHeartbeat  time.Time       `json:"last_heartbeat"`
+	HeartbeatCount int64           `json:"heartbeat_count"`
+	Patrols        map[string]bool `json:"patrols"` // patrol -> enabled in config
+	Paused         []string        `json:"paused,omitempty"`
+	Scheduler      bool            `json:"scheduler"`
+}
+
+// DeathRecord is a session death tracked by the daemon.
+type DeathRecord struct {
+	Session string    `json:"session"`
+	At      time.Time `json:"at"`
+}
+
+// TriggerPendingResult is the result of OpTriggerPending.
+type TriggerPendingResult struct {
+	Pending   int               `json:"pending"`
+	Triggered []string          `json:"triggered,omitempty"` // rig/polecat
+	Errors    map[string]string `json:"errors,omitempty"`    // rig/polecat -> error
+	Pruned    int               `json:"pruned"`
+}
+
+// startControlServer listens on the control socket. Failure is not fatal:
+// the CLI falls back to PID files, state.json and signals.
+func (d *Daemon) startControlServer() {
+	path := ControlSocketPath(d.config.TownRoot)
+	// We hold the daemon lock, so any existing socket is stale.
+	_ = os.Remove(path)
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		d.logger.Printf("Warning: control socket unavailable: %v", err)
+		return
+	}
+	_ = os.Chmod(path, 0600)
+	d.controlListener = ln
+	d.logger.Printf("Control socket listening at %s", path)
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				if errors.Is(err, net.ErrClosed) {
+					return
+				}
+				d.logger.Printf("Control socket accept error: %v", err)
+				continue
+			}
+			go d.serveControl(conn)
+		}
+	}()
+}
+
+// stopControlServer closes the control socket.
+func (d *Daemon) stopControlServer() {
+	if d.controlListener == nil {
+		return
+	}
+	_ = d.controlListener.Close()
+	_ = os.Remove(ControlSocketPath(d.config.TownRoot))
+}
+
+// onMainLoop runs fn on the daemon's main loop goroutine, serialized with
+// heartbeats, and waits for it to finish. Returns an error if the daemon is
+// shutting down or ctx is done first.
+func (d *Daemon) onMainLoop(ctx context.Context, fn func()) error {
+	done := make(chan struct{})
+	select {
+	case d.control <- func() { fn(); close(done) }:
+	case <-ctx.Done():
+		return fmt.Errorf("daemon is shutting down")
+	case <-d.ctx.Done():
+		return fmt.Errorf("daemon is shutting down")
+	}
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("daemon is shutting down")
+	case <-d.ctx.Done():
+		return fmt.Errorf("daemon is shutting down")
+	}
+}
+
+// serveControl handles one control connection.
+func (d *Daemon) serveControl(conn net.Conn) {
+	defer conn.Close()
+
+	var req ControlRequest
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&req); err != nil {
+		writeControl(conn, ControlResponse{Error: fmt.Sprintf("invalid request: %v", err)})
+		return
+	}
+
+	if req.Op == OpLogs {
+		d.streamLogs(conn, req)
+		return
+	}
+
+	result, err := d.handleControl(req)
+	resp := ControlResponse{OK: err == nil}
+	if err != nil {
+		resp.Error = err.Error()
+	} else if result != nil {
+		data, merr := json.Marshal(result)
+		if merr != nil {
+			resp = ControlResponse{Error: merr.Error()}
+		} else {
+			resp.Result = data
+		}
+	}
+	writeControl(conn, resp)
+}
+
+func writeControl(w io.Writer, resp ControlResponse) {
+	data, _ := json.Marshal(resp)
+	_, _ = w.Write(append(data, '\n'))
+}
+
+func writeLogLine(w io.Writer, line string) {
+	writeControl(w, ControlResponse{OK: true, Line: &line})
+}
+
+// handleControl dispatches a non-streaming request.
+func (d *Daemon) handleControl(req ControlRequest) (interface{}, error) {
+	switch req.Op {
+	case OpStatus:
+		var status ControlStatus
+		err := d.onMainLoop(d.ctx, func() { status = d.controlStatus() })
+		return status, err
+
+	case OpHeartbeat:
+		var status ControlStatus
+		err := d.onMainLoop(d.ctx, func() {
+			d.logger.Println("Heartbeat requested via control socket")
+			d.heartbeat(d.state)
+			status = d.controlStatus()
+		})
+		return status, err
+
+	case OpReload:
+		var result ReloadResult
+		err := d.onMainLoop(d.ctx, func() { result = d.reloadConfig(nil) })
+		return result, err
+
+	case OpPause, OpResume:
+		patrols, err := expandPatrols(req.Patrols)
+		if err != nil {
+			return nil, err
+		}
+		d.setPatrolsPaused(patrols, req.Op == OpPause)
+		d.logger.Printf("Patrols %sd via control socket: %s", req.Op, strings.Join(patrols, ", "))
+		return d.pausedPatrolList(), nil
+
+	case OpDeaths:
+		d.deathsMu.Lock()
+		defer d.deathsMu.Unlock()
+		return append([]DeathRecord(nil), d.deathLog...), nil
+
+	case OpTriggerPending:
+		timeout := 2 * time.Second
+		if req.Timeout != "" {
+			t, err := time.ParseDuration(req.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timeout %q: %w", req.Timeout, err)
+			}
+			timeout = t
+		}
+		var result TriggerPendingResult
+		var terr error
+		err := d.onMainLoop(d.ctx, func() { result, terr = d.runTriggerPending(timeout) })
+		if err != nil {
+			return nil, err
+		}
+		return result, terr
+
+	default:
+		return nil, fmt.Errorf("unknown operation %q", req.Op)
+	}
+}
+
+// controlStatus snapshots daemon status. Must run on the main loop.
+func (d *Daemon) controlStatus() ControlStatus {
+	status := ControlStatus{
+		PID:       os.Getpid(),
+		Patrols:   make(map[string]bool),
+		Paused:    d.pausedPatrolList(),
+		Scheduler: d.workScheduler != nil && d.workScheduler.Enabled(),
+	}
+	if d.state != nil {
+		status.StartedAt = d.state.StartedAt
+		status.LastHeartbeat = d.state.LastHeartbeat
+		status.HeartbeatCount = d.state.HeartbeatCount
+	}
+	for _, p := range []string{"deacon", "witness", "refinery"} {
+		status.Patrols[p] = IsPatrolEnabled(d.patrolConfig, p)
+	}
+	return status
+}
+
+// expandPatrols validates patrol names and expands "all".
+func expandPatrols(names []string) ([]string, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no patrols given (use one of: %s, all)", strings.Join(PausablePatrols, ", "))
+	}
+	var out []string
+	for _, n := range names {
+		if n == "all" {
+			return append([]string(nil), PausablePatrols...), nil
+		}
+		if !slices.Contains(PausablePatrols, n) {
+			return nil, fmt.Errorf("unknown patrol %q (use one of: %s, all)", n, strings.Join(PausablePatrols, ", "))
+		}
+		if !slices.Contains(out, n) {
+			out = append(out, n)
+		}
+	}
+	return out, nil
+}
+
+// setPatrolsPaused pauses or resumes patrols. Pauses are in-memory only and
+// end when the daemon restarts.
+func (d *Daemon) setPatrolsPaused(patrols []string, paused bool) {
+	d.pauseMu.Lock()
+	defer d.pauseMu.Unlock()
+	if d.pausedPatrols == nil {
+		d.pausedPatrols = make(map[string]bool)
+	}
+	for _, p := range patrols {
+		if paused {
+			d.pausedPatrols[p] = true
+		} else {
+			delete(d.pausedPatrols, p)
+		}
+	}
+}
+
+// isPatrolPaused reports whether a patrol was paused over the control socket.
+func (d *Daemon) isPatrolPaused(patrol string) bool {
+	d.pauseMu.Lock()
+	defer d.pauseMu.Unlock()
+	return d.pausedPatrols[patrol]
+}
+
+func (d *Daemon) pausedPatrolList() []string {
+	d.pauseMu.Lock()
+	defer d.pauseMu.Unlock()
+	var out []string
+	for p := range d.pausedPatrols {
+		out = append(out, p)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// runTriggerPending triggers pending polecat spawns and reports the outcome.
+func (d *Daemon) runTriggerPending(timeout time.Duration) (TriggerPendingResult, error) {
+	var result TriggerPendingResult
+	pending, err := polecat.CheckInboxForSpawns(d.config.TownRoot)
+	if err != nil {
+		return result, fmt.Errorf("checking inbox: %w", err)
+	}
+	result.Pending = len(pending)
+	if len(pending) == 0 {
+		return result, nil
+	}
+	d.logger.Printf("Found %d pending spawn(s), attempting to trigger...", len(pending))
+
+	results, err := polecat.TriggerPendingSpawns(d.config.TownRoot, timeout)
+	if err != nil {
+		return result, fmt.Errorf("triggering: %w", err)
+	}
+	for _, r := range results {
+		name := r.Spawn.Rig + "/" + r.Spawn.Polecat
+		if r.Triggered {
+			result.Triggered = append(result.Triggered, name)
+			d.logger.Printf("Triggered polecat: %s", name)
+		} else if r.Error != nil {
+			if result.Errors == nil {
+				result.Errors = make(map[string]string)
+			}
+			result.Errors[name] = r.Error.Error()
+			d.logger.Printf("Error triggering %s: %v", r.Spawn.Session, r.Error)
+		}
+	}
+	result.Pruned, _ = polecat.PruneStalePending(d.config.TownRoot, 5*time.Minute)
+	return result, nil
+}
+
+// streamLogs sends the last req.Lines lines of the daemon log, then new
+// lines as they are written if req.Follow is set, until the client hangs up
+// or the daemon stops.
+func (d *Daemon) streamLogs(conn net.Conn, req ControlRequest) {
+	f, err := os.Open(d.config.LogFile)
+	if err != nil {
+		writeControl(conn, ControlResponse{Error: err.Error()})
+		return
+	}
+	defer f.Close()
+
+	lines := req.Lines
+	if lines <= 0 {
+		lines = 50
+	}
+	for _, line := range tailLines(f, lines) {
+		writeLogLine(conn, line)
+	}
+	if !req.Follow {
+		writeControl(conn, ControlResponse{OK: true})
+		return
+	}
+
+	// Detect client hangup: the client never writes after its request.
+	hangup := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(io.Discard, conn)
+		close(hangup)
+	}()
+
+	reader := bufio.NewReader(f)
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+	var partial string
+	for {
+		select {
+		case <-hangup:
+			return
+		case <-d.ctx.Done():
+			writeControl(conn, ControlResponse{OK: true})
+			return
+		case <-ticker.C:
+			for {
+				chunk, err := reader.ReadString('\n')
+				partial += chunk
+				if err != nil {
+					break
+				}
+				writeLogLine(conn, strings.TrimRight(partial, "\n"))
+				partial = ""
+			}
+		}
+	}
+}
+
+// tailLines reads the last n lines of f and leaves f positioned at EOF.
+func tailLines(f *os.File, n int) []string {
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if len(lines) > n {
+			lines = lines[1:]
+		}
+	}
+	_, _ = f.Seek(0, io.SeekEnd)
+	return lines
+}
@@ -0,0 +1,212 @@
+package daemon
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// ErrControlUnavailable is returned by DialControl when no daemon is
+// listening on the control socket. Callers fall back to file-based paths.
+var ErrControlUnavailable = errors.New("daemon control socket unavailable")
+
+// ErrControlTimeout is returned when the daemon accepts a request but does
+// not answer in time, e.g. because its main loop is stuck.
+var ErrControlTimeout = errors.New("daemon control request timed out")
+
+// Control socket timeouts. Requests run on the daemon's main loop, so a
+// slow heartbeat delays even a status call; the deadlines keep a hung
+// daemon from hanging the CLI.
+const (
+	// controlDialTimeout bounds connecting to the control socket.
+	controlDialTimeout = time.Second
+
+	// controlCallTimeout bounds a request and its response.
+	controlCallTimeout = 30 * time.Second
+
+	// controlSlowCallTimeout bounds heartbeat and reload, which do real work.
+	controlSlowCallTimeout = 3 * time.Minute
+)
+
+// ControlClient talks to a running daemon over its control socket.
+// Each call opens its own connection.
+type ControlClient struct {
+	path string
+
+	// timeout overrides the per-request deadline (tests).
+	timeout time.Duration
+}
+
+// DialControl returns a client for the town's daemon after checking that
+// the socket accepts connections. Returns ErrControlUnavailable otherwise.
+func DialControl(townRoot string) (*ControlClient, error) {
+	c := &ControlClient{path: ControlSocketPath(townRoot)}
+	conn, err := net.DialTimeout("unix", c.path, controlDialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrControlUnavailable, err)
+	}
+	_ = conn.Close()
+	return c, nil
+}
+
+// Status returns live daemon status.
+func (c *ControlClient) Status() (*ControlStatus, error) {
+	var status ControlStatus
+	if err := c.call(ControlRequest{Op: OpStatus}, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// Heartbeat runs a heartbeat now and returns the resulting status.
+func (c *ControlClient) Heartbeat() (*ControlStatus, error) {
+	var status ControlStatus
+	if err := c.call(ControlRequest{Op: OpHeartbeat}, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// Reload re-reads the patrol config.
+func (c *ControlClient) Reload() (*ReloadResult, error) {
+	var result ReloadResult
+	if err := c.call(ControlRequest{Op: OpReload}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Pause pauses patrols and returns the set of paused patrols.
+func (c *ControlClient) Pause(patrols ...string) ([]string, error) {
+	var paused []string
+	err := c.call(ControlRequest{Op: OpPause, Patrols: patrols}, &paused)
+	return paused, err
+}
+
+// Resume resumes patrols and returns the set still paused.
+func (c *ControlClient) Resume(patrols ...string) ([]string, error) {
+	var paused []string
+	err := c.call(ControlRequest{Op: OpResume, Patrols: patrols}, &paused)
+	return paused, err
+}
+
+// Deaths lists session deaths tracked since the daemon started.
+func (c *ControlClient) Deaths() ([]DeathRecord, error) {
+	var deaths []DeathRecord
+	err := c.call(ControlRequest{Op: OpDeaths}, &deaths)
+	return deaths, err
+}
+
+// TriggerPending triggers pending polecat spawns in the daemon.
+func (c *ControlClient) TriggerPending(timeout time.Duration) (*TriggerPendingResult, error) {
+	var result TriggerPendingResult
+	if err := c.call(ControlRequest{Op: OpTriggerPending, Timeout: timeout.String()}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Logs writes the last n daemon log lines to w. With follow, it keeps
+// streaming new lines until the daemon stops or the connection drops.
+func (c *ControlClient) Logs(n int, follow bool, w io.Writer) error {
+	// A follow stream stays open as long as the daemon runs.
+	var timeout time.Duration
+	if !follow {
+		timeout = c.callTimeout(ControlRequest{Op: OpLogs})
+	}
+	conn, err := c.send(ControlRequest{Op: OpLogs, Lines: n, Follow: follow}, timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	dec := json.NewDecoder(bufio.NewReader(conn))
+	for {
+		var resp ControlResponse
+		if err := dec.Decode(&resp); err != nil {
+			if errors.Is(err, io.EOF) && follow {
+				return nil
+			}
+			return fmt.Errorf("reading logs: %w", err)
+		}
+		if resp.Error != "" {
+			return errors.New(resp.Error)
+		}
+		if resp.Line == nil {
+			return nil // final response
+		}
+		if _, err := fmt.Fprintln(w, *resp.Line); err != nil {
+			return err
+		}
+	}
+}
+
+// send opens a connection and writes a request. A non-zero timeout sets
+// the deadline for the whole exchange.
+func (c *ControlClient) send(req ControlRequest, timeout time.Duration) (net.Conn, error) {
+	conn, err := net.DialTimeout("unix", c.path, controlDialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrControlUnavailable, err)
+	}
+	if timeout > 0 {
+		_ = conn.SetDeadline(time.Now().Add(timeout))
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	if _, err := conn.Write(append(data, '\n')); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("sending %s request: %w", req.Op, err)
+	}
+	return conn, nil
+}
+
+// callTimeout returns the deadline for a request's round trip.
+func (c *ControlClient) callTimeout(req ControlRequest) time.Duration {
+	if c.timeout > 0 {
+		return c.timeout
+	}
+	switch req.Op {
+	case OpHeartbeat, OpReload:
+		return controlSlowCallTimeout
+	case OpTriggerPending:
+		if d, err := time.ParseDuration(req.Timeout); err == nil {
+			return d + controlCallTimeout
+		}
+	}
+	return controlCallTimeout
+}
+
+// call sends a request and decodes the single response into result.
+func (c *ControlClient) call(req ControlRequest, result interface{}) error {
+	timeout := c.callTimeout(req)
+	conn, err := c.send(req, timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var resp ControlResponse
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return fmt.Errorf("%w: no %s response within %v", ErrControlTimeout, req.Op, timeout)
+		}
+		return fmt.Errorf("reading %s response: %w", req.Op, err)
+	}
+	if !resp.OK {
+		return fmt.Errorf("daemon: %s", resp.Error)
+	}
+	if result != nil && len(resp.Result) > 0 {
+		if err := json.Unmarshal(resp.Result, result); err != nil {
+			return fmt.Errorf("decoding %s response: %w", req.Op, err)
+		}
+	}
+	return nil
+}
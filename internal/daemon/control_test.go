@@ -0,0 +1,224 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newControlTestDaemon starts a control server for a daemon that is not
+// running heartbeats. A goroutine stands in for the main loop.
+func newControlTestDaemon(t *testing.T) *Daemon {
+	t.Helper()
+	// Unix socket paths are length-limited; t.TempDir can be too long.
+	townRoot, err := os.MkdirTemp("", "gtctl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(townRoot) })
+	if err := os.MkdirAll(filepath.Join(townRoot, "daemon"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	logFile := filepath.Join(townRoot, "daemon", "daemon.log")
+	if err := os.WriteFile(logFile, []byte("one\n\nthree\nfour\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Daemon{
+		config:  &Config{TownRoot: townRoot, LogFile: logFile},
+		logger:  log.New(io.Discard, "", 0),
+		ctx:     ctx,
+		cancel:  cancel,
+		control: make(chan func()),
+		state:   &State{Running: true, StartedAt: time.Now(), HeartbeatCount: 7},
+	}
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case fn := <-d.control:
+				fn()
+			}
+		}
+	}()
+	d.startControlServer()
+	if d.controlListener == nil {
+		t.Fatal("control server did not start")
+	}
+	t.Cleanup(func() {
+		cancel()
+		d.stopControlServer()
+	})
+	return d
+}
+
+func TestDialControl_Unavailable(t *testing.T) {
+	_, err := DialControl(t.TempDir())
+	if !errors.Is(err, ErrControlUnavailable) {
+		t.Fatalf("DialControl() error = %v, want ErrControlUnavailable", err)
+	}
+}
+
+func TestControl_TimesOutWhenDaemonHangs(t *testing.T) {
+	dir, err := os.MkdirTemp("", "gtctl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	// A daemon that accepts requests but never answers.
+	ln, err := net.Listen("unix", filepath.Join(dir, "control.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	c := &ControlClient{path: filepath.Join(dir, "control.sock"), timeout: 100 * time.Millisecond}
+	start := time.Now()
+	if _, err := c.Status(); !errors.Is(err, ErrControlTimeout) {
+		t.Fatalf("Status() error = %v, want ErrControlTimeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Status() took %v", elapsed)
+	}
+}
+
+func TestControl_Status(t *testing.T) {
+	d := newControlTestDaemon(t)
+	client, err := DialControl(d.config.TownRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := client.Status()
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if status.PID != os.Getpid() || status.HeartbeatCount != 7 {
+		t.Errorf("Status() = %+v", status)
+	}
+	// No patrol config: all patrols enabled by default.
+	for _, p := range []string{"deacon", "witness", "refinery"} {
+		if !status.Patrols[p] {
+			t.Errorf("patrol %s reported disabled", p)
+		}
+	}
+}
+
+func TestControl_PauseResume(t *testing.T) {
+	d := newControlTestDaemon(t)
+	client, err := DialControl(d.config.TownRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paused, err := client.Pause("witness", "refinery")
+	if err != nil {
+		t.Fatalf("Pause() error = %v", err)
+	}
+	if strings.Join(paused, ",") != "refinery,witness" {
+		t.Errorf("Pause() = %v", paused)
+	}
+	if !d.isPatrolPaused("witness") || d.isPatrolPaused("deacon") {
+		t.Error("pause state not applied")
+	}
+
+	if _, err := client.Pause("mayor"); err == nil {
+		t.Error("Pause(mayor) should fail")
+	}
+
+	paused, err = client.Resume("witness")
+	if err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	if strings.Join(paused, ",") != "refinery" {
+		t.Errorf("Resume() = %v", paused)
+	}
+
+	if _, err := client.Pause("all"); err != nil {
+		t.Fatal(err)
+	}
+	status, err := client.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status.Paused) != len(PausablePatrols) {
+		t.Errorf("Paused = %v, want all", status.Paused)
+	}
+}
+
+func TestControl_Deaths(t *testing.T) {
+	d := newControlTestDaemon(t)
+	d.recordSessionDeath("gt-gastown-witness")
+
+	client, err := DialControl(d.config.TownRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deaths, err := client.Deaths()
+	if err != nil {
+		t.Fatalf("Deaths() error = %v", err)
+	}
+	if len(deaths) != 1 || deaths[0].Session != "gt-gastown-witness" {
+		t.Errorf("Deaths() = %+v", deaths)
+	}
+}
+
+func TestControl_Logs(t *testing.T) {
+	d := newControlTestDaemon(t)
+	client, err := DialControl(d.config.TownRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := client.Logs(3, false, &buf); err != nil {
+		t.Fatalf("Logs() error = %v", err)
+	}
+	// Empty lines must survive the stream.
+	if got, want := buf.String(), "\nthree\nfour\n"; got != want {
+		t.Errorf("Logs() = %q, want %q", got, want)
+	}
+}
+
+func TestControl_UnknownOp(t *testing.T) {
+	d := newControlTestDaemon(t)
+	client, err := DialControl(d.config.TownRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.call(ControlRequest{Op: "explode"}, nil); err == nil ||
+		!strings.Contains(err.Error(), "unknown operation") {
+		t.Errorf("call(explode) error = %v", err)
+	}
+}
+
+func TestExpandPatrols(t *testing.T) {
+	got, err := expandPatrols([]string{"deacon", "deacon", "scheduler"})
+	if err != nil || strings.Join(got, ",") != "deacon,scheduler" {
+		t.Errorf("expandPatrols() = %v, %v", got, err)
+	}
+	if _, err := expandPatrols(nil); err == nil {
+		t.Error("expandPatrols(nil) should fail")
+	}
+}
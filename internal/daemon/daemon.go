@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"net"
 	"os"
 	"os/exec"
 	"os/signal"
@@ -22,12 +23,14 @@ import (
 	"github.com/steveyegge/gastown/internal/constants"
 	"github.com/steveyegge/gastown/internal/deacon"
 	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/eventbus"
 	"github.com/steveyegge/gastown/internal/feed"
-	"github.com/steveyegge/gastown/internal/polecat"
 	"github.com/steveyegge/gastown/internal/refinery"
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/session"
+	"github.com/steveyegge/gastown/internal/systemd"
 	"github.com/steveyegge/gastown/internal/tmux"
+	"github.com/steveyegge/gastown/internal/webhooks"
 	"github.com/steveyegge/gastown/internal/util"
 	"github.com/steveyegge/gastown/internal/wisp"
 	"github.com/steveyegge/gastown/internal/witness"
@@ -48,11 +51,34 @@ type Daemon struct {
 	convoyWatcher *ConvoyWatcher
 	doltServer    *DoltServerManager
 	krcPruner     *KRCPruner
+	mailScheduler *MailScheduler
+	workScheduler *WorkScheduler
+	configWatcher *ConfigWatcher
+	eventServer   *eventbus.Server
+	webhooks      *webhooks.Dispatcher
+
+	// heartbeatTimer drives the recovery heartbeat; reset on config reload
+	// when heartbeat.interval changes.
+	heartbeatTimer *time.Timer
+
+	// Control socket (see control.go). Requests that touch heartbeat state
+	// are run on the main loop via the control channel.
+	controlListener net.Listener
+	control         chan func()
+	state           *State
+
+	// Patrols paused over the control socket (in-memory only).
+	pauseMu       sync.Mutex
+	pausedPatrols map[string]bool
 
 	// Mass death detection: track recent session deaths
 	deathsMu     sync.Mutex
 	recentDeaths []sessionDeath
 
+	// deathLog is a bounded history of session deaths, served over the
+	// control socket. Unlike recentDeaths it is not pruned to the window.
+	deathLog []DeathRecord
+
 	// Deacon startup tracking: prevents race condition where newly started
 	// sessions are immediately killed by the heartbeat check.
 	// See: https://github.com/steveyegge/gastown/issues/567
@@ -112,6 +138,7 @@ func New(config *Config) (*Daemon, error) {
 		ctx:          ctx,
 		cancel:       cancel,
 		doltServer:   doltServer,
+		control:      make(chan func()),
 	}, nil
 }
 
@@ -157,6 +184,11 @@ func (d *Daemon) Run() error {
 	if err := SaveState(d.config.TownRoot, state); err != nil {
 		d.logger.Printf("Warning: failed to save state: %v", err)
 	}
+	d.state = state
+
+	// Control socket for gt daemon status/logs/pause etc.
+	d.startControlServer()
+	defer d.stopControlServer()
 
 	// Handle signals
 	sigChan := make(chan os.Signal, 1)
@@ -164,10 +196,34 @@ func (d *Daemon) Run() error {
 
 	// Fixed recovery-focused heartbeat (no activity-based backoff)
 	// Normal wake is handled by feed subscription (bd activity --follow)
-	timer := time.NewTimer(recoveryHeartbeatInterval)
+	timer := time.NewTimer(HeartbeatInterval(d.patrolConfig))
 	defer timer.Stop()
+	d.heartbeatTimer = timer
+
+	d.logger.Printf("Daemon running, recovery heartbeat interval %v", HeartbeatInterval(d.patrolConfig))
 
-	d.logger.Printf("Daemon running, recovery heartbeat interval %v", recoveryHeartbeatInterval)
+	// Start event bus server: tails the activity log and streams it to
+	// subscribers on daemon/events.sock
+	d.eventServer = eventbus.NewServer(d.config.TownRoot, d.logger.Printf)
+	if err := d.eventServer.Start(); err != nil {
+		d.logger.Printf("Warning: failed to start event bus: %v", err)
+		d.eventServer = nil
+	} else {
+		d.logger.Printf("Event bus listening on %s", eventbus.SocketPath(d.config.TownRoot))
+	}
+
+	// Start outbound webhooks (settings/webhooks.json), fed from the
+	// activity log
+	whCfg, err := webhooks.LoadConfig(d.config.TownRoot)
+	if err != nil {
+		d.logger.Printf("Warning: webhooks disabled: %v", err)
+		whCfg = &webhooks.Config{}
+	}
+	d.webhooks = webhooks.NewDispatcher(d.config.TownRoot, whCfg, d.logger.Printf)
+	d.webhooks.Start()
+	if n := len(whCfg.Endpoints); n > 0 {
+		d.logger.Printf("Webhooks started (%d endpoint(s))", n)
+	}
 
 	// Start feed curator goroutine
 	d.curator = feed.NewCurator(d.config.TownRoot)
@@ -198,6 +254,36 @@ func (d *Daemon) Run() error {
 		}
 	}
 
+	// Start mail scheduler for deferred, recurring and expiring mail
+	d.mailScheduler = NewMailScheduler(d.config.TownRoot, d.logger.Printf)
+	if err := d.mailScheduler.Start(); err != nil {
+		d.logger.Printf("Warning: failed to start mail scheduler: %v", err)
+	} else {
+		d.logger.Println("Mail scheduler started")
+	}
+
+	// Start work scheduler if enabled in mayor/daemon.json
+	d.startWorkScheduler()
+
+	// Watch config files so edits apply without a daemon restart
+	d.watchConfig()
+
+	// Tell systemd we're up (no-op unless started by a Type=notify unit).
+	// The watchdog is petted from this loop, so a wedged heartbeat gets
+	// the daemon restarted.
+	if ok, err := systemd.Ready(fmt.Sprintf("Running (PID %d)", os.Getpid())); err != nil {
+		d.logger.Printf("Warning: systemd notify failed: %v", err)
+	} else if ok {
+		d.logger.Println("Notified systemd: ready")
+	}
+	var watchdog <-chan time.Time
+	if interval, ok := systemd.WatchdogInterval(); ok {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		watchdog = ticker.C
+		d.logger.Printf("systemd watchdog enabled, petting every %v", interval)
+	}
+
 	// Initial heartbeat
 	d.heartbeat(state)
 
@@ -217,16 +303,25 @@ func (d *Daemon) Run() error {
 				return d.shutdown(state)
 			}
 
+		case <-watchdog:
+			_, _ = systemd.Watchdog()
+
+		case fn := <-d.control:
+			// Control socket request that must run between heartbeats
+			fn()
+
 		case <-timer.C:
 			d.heartbeat(state)
+			_, _ = systemd.Status(fmt.Sprintf("Heartbeat #%d at %s", state.HeartbeatCount, state.LastHeartbeat.Format("15:04:05")))
 
 			// Fixed recovery interval (no activity-based backoff)
-			timer.Reset(recoveryHeartbeatInterval)
+			timer.Reset(HeartbeatInterval(d.patrolConfig))
 		}
 	}
 }
 
-// recoveryHeartbeatInterval is the fixed interval for recovery-focused daemon.
+// recoveryHeartbeatInterval is the default interval for recovery-focused daemon.
+// It can be overridden with heartbeat.interval in mayor/daemon.json.
 // Normal wake is handled by feed subscription (bd activity --follow).
 // The daemon is a safety net for dead sessions, GUPP violations, and orphaned work.
 // 3 minutes is fast enough to detect stuck agents promptly while avoiding excessive overhead.
@@ -256,7 +351,11 @@ func (d *Daemon) heartbeat(state *State) {
 
 	// 1. Ensure Deacon is running (restart if dead)
 	// Check patrol config - can be disabled in mayor/daemon.json
-	if IsPatrolEnabled(d.patrolConfig, "deacon") {
+	// Paused patrols (gt daemon pause) are skipped without killing sessions.
+	deaconPaused := d.isPatrolPaused("deacon")
+	if deaconPaused {
+		d.logger.Printf("Deacon patrol paused, skipping")
+	} else if IsPatrolEnabled(d.patrolConfig, "deacon") {
 		d.ensureDeaconRunning()
 	} else {
 		d.logger.Printf("Deacon patrol disabled in config, skipping")
@@ -269,20 +368,22 @@ func (d *Daemon) heartbeat(state *State) {
 	// 2. Poke Boot for intelligent triage (stuck/nudge/interrupt)
 	// Boot handles nuanced "is Deacon responsive" decisions
 	// Only run if Deacon patrol is enabled
-	if IsPatrolEnabled(d.patrolConfig, "deacon") {
+	if !deaconPaused && IsPatrolEnabled(d.patrolConfig, "deacon") {
 		d.ensureBootRunning()
 	}
 
 	// 3. Direct Deacon heartbeat check (belt-and-suspenders)
 	// Boot may not detect all stuck states; this provides a fallback
 	// Only run if Deacon patrol is enabled
-	if IsPatrolEnabled(d.patrolConfig, "deacon") {
+	if !deaconPaused && IsPatrolEnabled(d.patrolConfig, "deacon") {
 		d.checkDeaconHeartbeat()
 	}
 
 	// 4. Ensure Witnesses are running for all rigs (restart if dead)
 	// Check patrol config - can be disabled in mayor/daemon.json
-	if IsPatrolEnabled(d.patrolConfig, "witness") {
+	if d.isPatrolPaused("witness") {
+		d.logger.Printf("Witness patrol paused, skipping")
+	} else if IsPatrolEnabled(d.patrolConfig, "witness") {
 		d.ensureWitnessesRunning()
 	} else {
 		d.logger.Printf("Witness patrol disabled in config, skipping")
@@ -292,7 +393,9 @@ func (d *Daemon) heartbeat(state *State) {
 
 	// 5. Ensure Refineries are running for all rigs (restart if dead)
 	// Check patrol config - can be disabled in mayor/daemon.json
-	if IsPatrolEnabled(d.patrolConfig, "refinery") {
+	if d.isPatrolPaused("refinery") {
+		d.logger.Printf("Refinery patrol paused, skipping")
+	} else if IsPatrolEnabled(d.patrolConfig, "refinery") {
 		d.ensureRefineriesRunning()
 	} else {
 		d.logger.Printf("Refinery patrol disabled in config, skipping")
@@ -795,45 +898,17 @@ func (d *Daemon) isRigOperational(rigName string) (bool, string) {
 func (d *Daemon) triggerPendingSpawns() {
 	const triggerTimeout = 2 * time.Second
 
-	// Check for pending spawns (from POLECAT_STARTED messages in Deacon inbox)
-	pending, err := polecat.CheckInboxForSpawns(d.config.TownRoot)
-	if err != nil {
-		d.logger.Printf("Error checking pending spawns: %v", err)
-		return
-	}
-
-	if len(pending) == 0 {
-		return
-	}
-
-	d.logger.Printf("Found %d pending spawn(s), attempting to trigger...", len(pending))
-
-	// Trigger pending spawns (uses WaitForRuntimeReady with short timeout)
-	results, err := polecat.TriggerPendingSpawns(d.config.TownRoot, triggerTimeout)
+	result, err := d.runTriggerPending(triggerTimeout)
 	if err != nil {
-		d.logger.Printf("Error triggering spawns: %v", err)
+		d.logger.Printf("Error with pending spawns: %v", err)
 		return
 	}
 
-	// Log results
-	triggered := 0
-	for _, r := range results {
-		if r.Triggered {
-			triggered++
-			d.logger.Printf("Triggered polecat: %s/%s", r.Spawn.Rig, r.Spawn.Polecat)
-		} else if r.Error != nil {
-			d.logger.Printf("Error triggering %s: %v", r.Spawn.Session, r.Error)
-		}
+	if n := len(result.Triggered); n > 0 {
+		d.logger.Printf("Triggered %d/%d pending spawn(s)", n, result.Pending)
 	}
-
-	if triggered > 0 {
-		d.logger.Printf("Triggered %d/%d pending spawn(s)", triggered, len(pending))
-	}
-
-	// Prune stale pending spawns (older than 5 minutes - likely dead sessions)
-	pruned, _ := polecat.PruneStalePending(d.config.TownRoot, 5*time.Minute)
-	if pruned > 0 {
-		d.logger.Printf("Pruned %d stale pending spawn(s)", pruned)
+	if result.Pruned > 0 {
+		d.logger.Printf("Pruned %d stale pending spawn(s)", result.Pruned)
 	}
 }
 
@@ -1010,6 +1085,13 @@ func (d *Daemon) killBeadsDaemon(beadsDir string) {
 // shutdown performs graceful shutdown.
 func (d *Daemon) shutdown(state *State) error { //nolint:unparam // error return kept for future use
 	d.logger.Println("Daemon shutting down")
+	_, _ = systemd.Stopping()
+
+	// Stop config watcher first so no reload races the shutdown
+	if d.configWatcher != nil {
+		d.configWatcher.Stop()
+		d.logger.Println("Config watcher stopped")
+	}
 
 	// Stop feed curator
 	if d.curator != nil {
@@ -1017,6 +1099,18 @@ func (d *Daemon) shutdown(state *State) error { //nolint:unparam // error return
 		d.logger.Println("Feed curator stopped")
 	}
 
+	// Stop webhooks (queued deliveries go to the dead-letter file)
+	if d.webhooks != nil {
+		d.webhooks.Stop()
+		d.logger.Println("Webhooks stopped")
+	}
+
+	// Stop event bus (disconnects subscribers)
+	if d.eventServer != nil {
+		d.eventServer.Stop()
+		d.logger.Println("Event bus stopped")
+	}
+
 	// Stop convoy watcher
 	if d.convoyWatcher != nil {
 		d.convoyWatcher.Stop()
@@ -1029,6 +1123,18 @@ func (d *Daemon) shutdown(state *State) error { //nolint:unparam // error return
 		d.logger.Println("KRC pruner stopped")
 	}
 
+	// Stop mail scheduler
+	if d.mailScheduler != nil {
+		d.mailScheduler.Stop()
+		d.logger.Println("Mail scheduler stopped")
+	}
+
+	// Stop work scheduler
+	if d.workScheduler != nil {
+		d.workScheduler.Stop()
+		d.logger.Println("Work scheduler stopped")
+	}
+
 	// Stop Dolt server if we're managing it
 	if d.doltServer != nil && d.doltServer.IsEnabled() && !d.doltServer.IsExternal() {
 		if err := d.doltServer.Stop(); err != nil {
@@ -1375,6 +1481,10 @@ func (d *Daemon) recordSessionDeath(sessionName string) {
 		sessionName: sessionName,
 		timestamp:   now,
 	})
+	d.deathLog = append(d.deathLog, DeathRecord{Session: sessionName, At: now})
+	if len(d.deathLog) > maxDeathLog {
+		d.deathLog = d.deathLog[len(d.deathLog)-maxDeathLog:]
+	}
 
 	// Prune deaths outside the window
 	cutoff := now.Add(-massDeathWindow)
@@ -1475,12 +1585,12 @@ func (d *Daemon) restartPolecatSession(rigName, polecatName, sessionName string)
 		return fmt.Errorf("sending startup command: %w", err)
 	}
 
-	// Wait for Claude to start, then accept bypass permissions warning if it appears.
-	// This ensures automated restarts aren't blocked by the warning dialog.
+	// Wait for the agent to start, then answer its startup dialogs so
+	// automated restarts aren't blocked by them.
 	if err := d.tmux.WaitForCommand(sessionName, constants.SupportedShells, constants.ClaudeStartTimeout); err != nil {
-		// Non-fatal - Claude might still start
+		// Non-fatal - the agent might still start
 	}
-	_ = d.tmux.AcceptBypassPermissionsWarning(sessionName)
+	d.answerStartupPrompts(sessionName, fmt.Sprintf("%s/polecats/%s", rigName, polecatName), constants.RolePolecat, rigName)
 
 	return nil
 }
@@ -114,6 +114,37 @@ func NewDoltServerManager(townRoot string, config *DoltServerConfig, logger func
 	}
 }
 
+// UpdateConfig swaps in new server settings. If the daemon manages the
+// server and its bind address or data dir changed, or management was turned
+// off, the server is stopped so the next EnsureRunning starts it (or not)
+// with the new settings. Returns true if the server was stopped.
+func (m *DoltServerManager) UpdateConfig(config *DoltServerConfig) bool {
+	if config == nil {
+		config = DefaultDoltServerConfig(m.townRoot)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	old := m.config
+	m.config = config
+
+	wasManaged := old != nil && old.Enabled && !old.External
+	if !wasManaged {
+		return false
+	}
+	bindChanged := old.Host != config.Host || old.Port != config.Port || old.DataDir != config.DataDir
+	if bindChanged || !config.Enabled || config.External {
+		m.stopLocked()
+		// New settings get a fresh restart budget.
+		m.currentDelay = 0
+		m.restartTimes = nil
+		m.escalated = false
+		return true
+	}
+	return false
+}
+
 // pidFile returns the path to the Dolt server PID file.
 func (m *DoltServerManager) pidFile() string {
 	return filepath.Join(m.townRoot, "daemon", "dolt.pid")
@@ -12,11 +12,16 @@ import (
 // It runs as a background goroutine within the daemon.
 type KRCPruner struct {
 	townRoot string
-	config   *krc.Config
 	logger   func(format string, args ...interface{})
 	ctx      context.Context
 	cancel   context.CancelFunc
 	wg       sync.WaitGroup
+
+	mu     sync.Mutex
+	config *krc.Config
+
+	// intervalChanged wakes the run loop to pick up a new prune interval.
+	intervalChanged chan struct{}
 }
 
 // NewKRCPruner creates a new KRC pruner.
@@ -29,11 +34,12 @@ func NewKRCPruner(townRoot string, logger func(format string, args ...interface{
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &KRCPruner{
-		townRoot: townRoot,
-		config:   config,
-		logger:   logger,
-		ctx:      ctx,
-		cancel:   cancel,
+		townRoot:        townRoot,
+		config:          config,
+		logger:          logger,
+		ctx:             ctx,
+		cancel:          cancel,
+		intervalChanged: make(chan struct{}, 1),
 	}, nil
 }
 
@@ -55,17 +61,42 @@ func (p *KRCPruner) Stop() {
 	p.wg.Wait()
 }
 
+// UpdateConfig replaces the TTL config used by subsequent prunes. A changed
+// prune interval takes effect immediately.
+func (p *KRCPruner) UpdateConfig(config *krc.Config) {
+	p.mu.Lock()
+	changed := p.config.PruneInterval != config.PruneInterval
+	p.config = config
+	p.mu.Unlock()
+
+	if changed {
+		select {
+		case p.intervalChanged <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// currentConfig returns the config in effect.
+func (p *KRCPruner) currentConfig() *krc.Config {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.config
+}
+
 // run is the main pruner loop.
 func (p *KRCPruner) run() {
 	defer p.wg.Done()
 
-	ticker := time.NewTicker(p.config.PruneInterval)
+	ticker := time.NewTicker(p.currentConfig().PruneInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-p.ctx.Done():
 			return
+		case <-p.intervalChanged:
+			ticker.Reset(p.currentConfig().PruneInterval)
 		case <-ticker.C:
 			p.prune()
 		}
@@ -74,7 +105,7 @@ func (p *KRCPruner) run() {
 
 // prune runs a single prune operation.
 func (p *KRCPruner) prune() {
-	pruner := krc.NewPruner(p.townRoot, p.config)
+	pruner := krc.NewPruner(p.townRoot, p.currentConfig())
 	result, err := pruner.Prune()
 	if err != nil {
 		p.logger("KRC prune error: %v", err)
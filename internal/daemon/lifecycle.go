@@ -13,7 +13,9 @@ import (
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/runtime"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/tmux"
 )
@@ -387,17 +389,30 @@ func (d *Daemon) restartSession(sessionName, identity string) error {
 		return fmt.Errorf("sending startup command: %w", err)
 	}
 
-	// Wait for Claude to start, then accept bypass permissions warning if it appears.
-	// This ensures automated role starts aren't blocked by the warning dialog.
+	// Wait for the agent to start, then answer its startup dialogs so
+	// automated role starts aren't blocked by them.
 	if err := d.tmux.WaitForCommand(sessionName, constants.SupportedShells, constants.ClaudeStartTimeout); err != nil {
-		// Non-fatal - Claude might still start
+		// Non-fatal - the agent might still start
 	}
-	_ = d.tmux.AcceptBypassPermissionsWarning(sessionName)
+	d.answerStartupPrompts(sessionName, identity, parsed.RoleType, parsed.RigName)
 	time.Sleep(constants.ShutdownNotifyDelay)
 
 	return nil
 }
 
+// answerStartupPrompts answers the trust and permission dialogs of a
+// session the daemon just started, from the role's runtime preset.
+func (d *Daemon) answerStartupPrompts(sessionName, agent, role, rigName string) {
+	rigPath := ""
+	if rigName != "" {
+		rigPath = filepath.Join(d.config.TownRoot, rigName)
+	}
+	rc := config.ResolveRoleAgentConfig(role, d.config.TownRoot, rigPath)
+	if _, err := runtime.AnswerStartupPrompts(d.tmux, sessionName, agent, rc); err != nil {
+		d.logger.Printf("Answering startup prompts for %s: %v", agent, err)
+	}
+}
+
 // getWorkDir determines the working directory for an agent.
 // Uses role config if available, falls back to hardcoded defaults.
 func (d *Daemon) getWorkDir(config *beads.RoleConfig, parsed *ParsedIdentity) string {
@@ -843,27 +858,62 @@ func (d *Daemon) checkRigGUPPViolations(rigName string) {
 
 			age := time.Since(updatedAt)
 			if age > GUPPViolationTimeout {
-				d.logger.Printf("GUPP violation: agent %s has hook_bead=%s but hasn't updated in %v (timeout: %v)",
-					agent.ID, agent.HookBead, age.Round(time.Minute), GUPPViolationTimeout)
+				// The pane says why: a dialog, a question, an error loop,
+				// or nothing visibly wrong.
+				pane := d.classifyPolecatPane(rigName, sessionName)
+				d.logger.Printf("GUPP violation: agent %s has hook_bead=%s but hasn't updated in %v (timeout: %v, pane: %s)",
+					agent.ID, agent.HookBead, age.Round(time.Minute), GUPPViolationTimeout, pane.State)
+				if pane.State != "" {
+					state := ""
+					if pane.State.Stuck() {
+						state = string(pane.State)
+					}
+					addr := fmt.Sprintf("%s/polecats/%s", rigName, polecatName)
+					_ = events.LogPaneState(d.config.TownRoot, sessionName, addr, state, pane.Detail, "daemon")
+				}
 
 				// Notify the witness for this rig
-				d.notifyWitnessOfGUPP(rigName, agent.ID, agent.HookBead, age)
+				d.notifyWitnessOfGUPP(rigName, agent.ID, agent.HookBead, age, pane)
 			}
 		}
 	}
 }
 
+// classifyPolecatPane classifies a polecat's session output with the rig's
+// polecat runtime patterns. The state is empty if the pane can't be read.
+func (d *Daemon) classifyPolecatPane(rigName, sessionName string) tmux.PaneClassification {
+	rigPath := filepath.Join(d.config.TownRoot, rigName)
+	classifier, err := tmux.NewPaneClassifier(config.ResolveRoleAgentConfig(constants.RolePolecat, d.config.TownRoot, rigPath))
+	if err != nil {
+		d.logger.Printf("Warning: %v", err)
+		return tmux.PaneClassification{}
+	}
+	pane, err := d.tmux.ClassifyPane(sessionName, classifier)
+	if err != nil {
+		return tmux.PaneClassification{}
+	}
+	return pane
+}
+
 // notifyWitnessOfGUPP sends a mail to the rig's witness about a GUPP violation.
-func (d *Daemon) notifyWitnessOfGUPP(rigName, agentID, hookBead string, stuckDuration time.Duration) {
+func (d *Daemon) notifyWitnessOfGUPP(rigName, agentID, hookBead string, stuckDuration time.Duration, pane tmux.PaneClassification) {
 	witnessAddr := rigName + "/witness"
 	subject := fmt.Sprintf("GUPP_VIOLATION: %s stuck for %v", agentID, stuckDuration.Round(time.Minute))
+	paneState := string(pane.State)
+	if paneState == "" {
+		paneState = "unknown"
+	}
+	if pane.Detail != "" {
+		paneState += " (" + pane.Detail + ")"
+	}
 	body := fmt.Sprintf(`Agent %s has work on hook but isn't progressing.
 
 hook_bead: %s
 stuck_duration: %v
+pane_state: %s
 
 Action needed: Check if agent is alive and responsive. Consider restarting if stuck.`,
-		agentID, hookBead, stuckDuration.Round(time.Minute))
+		agentID, hookBead, stuckDuration.Round(time.Minute), paneState)
 
 	cmd := exec.Command("gt", "mail", "send", witnessAddr, "-s", subject, "-m", body)
 	cmd.Dir = d.config.TownRoot
@@ -0,0 +1,98 @@
+package daemon
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/mail"
+)
+
+const (
+	// mailReleaseInterval is how often due scheduled mail is released.
+	mailReleaseInterval = 30 * time.Second
+
+	// mailPurgeInterval is how often expired delivered mail is closed.
+	// Inboxes hide expired mail on their own, so this only tidies up.
+	mailPurgeInterval = 15 * time.Minute
+)
+
+// MailScheduler releases scheduled mail when it falls due and closes
+// delivered mail once it expires. It runs as a background goroutine
+// within the daemon.
+type MailScheduler struct {
+	townRoot string
+	logger   func(format string, args ...interface{})
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+
+	lastPurge time.Time
+}
+
+// NewMailScheduler creates a new mail scheduler.
+func NewMailScheduler(townRoot string, logger func(format string, args ...interface{})) *MailScheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &MailScheduler{
+		townRoot: townRoot,
+		logger:   logger,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Start begins the scheduler goroutine.
+func (s *MailScheduler) Start() error {
+	s.wg.Add(1)
+	go s.run()
+	return nil
+}
+
+// Stop gracefully stops the scheduler.
+func (s *MailScheduler) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+func (s *MailScheduler) run() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(mailReleaseInterval)
+	defer ticker.Stop()
+
+	s.tick(time.Now())
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.tick(now)
+		}
+	}
+}
+
+// tick runs one release pass and, when due, one purge pass.
+func (s *MailScheduler) tick(now time.Time) {
+	router := mail.NewRouter(s.townRoot)
+
+	res, err := router.ReleaseDue(now)
+	if err != nil {
+		s.logger("Mail scheduler: release failed: %v", err)
+	}
+	if res.Delivered > 0 || res.Expired > 0 || res.Failed > 0 {
+		s.logger("Mail scheduler: delivered %d, expired %d, failed %d (gave up on %d)", res.Delivered, res.Expired, res.Failed, res.GaveUp)
+	}
+
+	if now.Sub(s.lastPurge) < mailPurgeInterval {
+		return
+	}
+	s.lastPurge = now
+	closed, err := router.PurgeExpired(now)
+	if err != nil {
+		s.logger("Mail scheduler: purge failed: %v", err)
+		return
+	}
+	if closed > 0 {
+		s.logger("Mail scheduler: closed %d expired message(s)", closed)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestLoadPatrolConfig(t *testing.T) {
@@ -51,3 +52,65 @@ func TestIsPatrolEnabled_NilConfig(t *testing.T) {
 		t.Error("expected default to be enabled")
 	}
 }
+
+func TestValidatePatrolConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *DaemonPatrolConfig
+		wantErr bool
+	}{
+		{"nil", nil, false},
+		{"valid heartbeat", &DaemonPatrolConfig{Heartbeat: &PatrolConfig{Interval: "5m"}}, false},
+		{"bad heartbeat", &DaemonPatrolConfig{Heartbeat: &PatrolConfig{Interval: "soon"}}, true},
+		{"heartbeat too short", &DaemonPatrolConfig{Heartbeat: &PatrolConfig{Interval: "1s"}}, true},
+		{"bad patrol interval", &DaemonPatrolConfig{Patrols: &PatrolsConfig{
+			Witness: &PatrolConfig{Enabled: true, Interval: "-1m"},
+		}}, true},
+		{"bad dolt port", &DaemonPatrolConfig{Patrols: &PatrolsConfig{
+			DoltServer: &DoltServerConfig{Enabled: true, Port: 70000},
+		}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePatrolConfig(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePatrolConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHeartbeatInterval(t *testing.T) {
+	if got := HeartbeatInterval(nil); got != recoveryHeartbeatInterval {
+		t.Errorf("HeartbeatInterval(nil) = %v", got)
+	}
+	cfg := &DaemonPatrolConfig{Heartbeat: &PatrolConfig{Interval: "10m"}}
+	if got := HeartbeatInterval(cfg); got != 10*time.Minute {
+		t.Errorf("HeartbeatInterval(10m) = %v", got)
+	}
+	cfg.Heartbeat.Interval = "1s"
+	if got := HeartbeatInterval(cfg); got != recoveryHeartbeatInterval {
+		t.Errorf("HeartbeatInterval(1s) = %v, want default", got)
+	}
+}
+
+func TestReadPatrolConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	if cfg, err := ReadPatrolConfig(tmpDir); cfg != nil || err != nil {
+		t.Fatalf("missing file: got %v, %v", cfg, err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(tmpDir, "mayor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(PatrolConfigFile(tmpDir), []byte(`{"heartbeat": {"interval": "nope"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadPatrolConfig(tmpDir); err == nil {
+		t.Error("expected validation error")
+	}
+	// LoadPatrolConfig stays lenient for startup.
+	if LoadPatrolConfig(tmpDir) == nil {
+		t.Error("LoadPatrolConfig should still load the file")
+	}
+}
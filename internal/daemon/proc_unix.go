@@ -30,3 +30,9 @@ func sendTermSignal(p *os.Process) error {
 func sendKillSignal(p *os.Process) error {
 	return p.Signal(syscall.SIGKILL)
 }
+
+// killProcessGroup sends SIGKILL to the process group led by p, which must
+// have been started with setSysProcAttr.
+func killProcessGroup(p *os.Process) error {
+	return syscall.Kill(-p.Pid, syscall.SIGKILL)
+}
@@ -36,3 +36,9 @@ func sendTermSignal(p *os.Process) error {
 func sendKillSignal(p *os.Process) error {
 	return p.Kill()
 }
+
+// killProcessGroup kills p.
+// On Windows there are no process groups to signal; children may outlive it.
+func killProcessGroup(p *os.Process) error {
+	return p.Kill()
+}
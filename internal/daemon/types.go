@@ -10,10 +10,12 @@ package daemon
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"time"
 
+	"github.com/steveyegge/gastown/internal/scheduler"
 	"github.com/steveyegge/gastown/internal/util"
 )
 
@@ -101,7 +103,8 @@ type PatrolConfig struct {
 	// Enabled controls whether this patrol runs during heartbeat.
 	Enabled bool `json:"enabled"`
 
-	// Interval is how often to run this patrol (not used yet).
+	// Interval is how often to run this patrol. Only the heartbeat
+	// section's interval is used; it overrides the recovery interval.
 	Interval string `json:"interval,omitempty"`
 
 	// Agent is the agent type for this patrol (not used yet).
@@ -117,6 +120,7 @@ type PatrolsConfig struct {
 	Witness    *PatrolConfig     `json:"witness,omitempty"`
 	Deacon     *PatrolConfig     `json:"deacon,omitempty"`
 	DoltServer *DoltServerConfig `json:"dolt_server,omitempty"`
+	Scheduler  *scheduler.Config `json:"scheduler,omitempty"`
 }
 
 // DaemonPatrolConfig is the structure of mayor/daemon.json.
@@ -148,6 +152,102 @@ func LoadPatrolConfig(townRoot string) *DaemonPatrolConfig {
 	return &config
 }
 
+// ReadPatrolConfig loads and validates mayor/daemon.json, reporting why a
+// config is unusable. Returns nil, nil if the file doesn't exist. Used for
+// live reloads, where a bad file must not replace the running config.
+func ReadPatrolConfig(townRoot string) (*DaemonPatrolConfig, error) {
+	configFile := PatrolConfigFile(townRoot)
+	data, err := os.ReadFile(configFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var config DaemonPatrolConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", configFile, err)
+	}
+	if err := ValidatePatrolConfig(&config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// minHeartbeatInterval keeps a misconfigured heartbeat from hammering tmux
+// and beads.
+const minHeartbeatInterval = 30 * time.Second
+
+// ValidatePatrolConfig checks intervals, scheduler limits and Dolt settings.
+func ValidatePatrolConfig(config *DaemonPatrolConfig) error {
+	if config == nil {
+		return nil
+	}
+	if config.Heartbeat != nil && config.Heartbeat.Interval != "" {
+		d, err := time.ParseDuration(config.Heartbeat.Interval)
+		if err != nil {
+			return fmt.Errorf("heartbeat.interval: %w", err)
+		}
+		if d < minHeartbeatInterval {
+			return fmt.Errorf("heartbeat.interval %v is below the %v minimum", d, minHeartbeatInterval)
+		}
+	}
+	if config.Patrols == nil {
+		return nil
+	}
+	for _, p := range []struct {
+		name string
+		cfg  *PatrolConfig
+	}{
+		{"deacon", config.Patrols.Deacon},
+		{"witness", config.Patrols.Witness},
+		{"refinery", config.Patrols.Refinery},
+	} {
+		if p.cfg == nil || p.cfg.Interval == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(p.cfg.Interval); err != nil || d <= 0 {
+			return fmt.Errorf("patrols.%s.interval: invalid duration %q", p.name, p.cfg.Interval)
+		}
+	}
+	if s := config.Patrols.Scheduler; s != nil {
+		if s.Interval != "" {
+			if d, err := time.ParseDuration(s.Interval); err != nil || d <= 0 {
+				return fmt.Errorf("patrols.scheduler.interval: invalid duration %q", s.Interval)
+			}
+		}
+		if s.TownMaxPolecats < 0 || s.MaxDispatchPerCycle < 0 {
+			return fmt.Errorf("patrols.scheduler: limits must not be negative")
+		}
+	}
+	if dc := config.Patrols.DoltServer; dc != nil {
+		if dc.Port < 0 || dc.Port > 65535 {
+			return fmt.Errorf("patrols.dolt_server.port %d out of range", dc.Port)
+		}
+		if dc.RestartDelay < 0 || dc.MaxRestartDelay < 0 || dc.RestartWindow < 0 || dc.HealthyResetInterval < 0 {
+			return fmt.Errorf("patrols.dolt_server: durations must not be negative")
+		}
+		if dc.MaxRestartsInWindow < 0 {
+			return fmt.Errorf("patrols.dolt_server.max_restarts_in_window must not be negative")
+		}
+	}
+	return nil
+}
+
+// HeartbeatInterval returns the recovery heartbeat interval: heartbeat.interval
+// from mayor/daemon.json if set and valid, else the built-in default.
+func HeartbeatInterval(config *DaemonPatrolConfig) time.Duration {
+	if config == nil || config.Heartbeat == nil || config.Heartbeat.Interval == "" {
+		return recoveryHeartbeatInterval
+	}
+	d, err := time.ParseDuration(config.Heartbeat.Interval)
+	if err != nil || d < minHeartbeatInterval {
+		return recoveryHeartbeatInterval
+	}
+	return d
+}
+
 // IsPatrolEnabled checks if a patrol is enabled in the config.
 // Returns true if the config doesn't exist (default enabled for backwards compatibility).
 func IsPatrolEnabled(config *DaemonPatrolConfig, patrol string) bool {
@@ -172,6 +272,15 @@ func IsPatrolEnabled(config *DaemonPatrolConfig, patrol string) bool {
 	return true // Default: enabled
 }
 
+// SchedulerConfig returns the work scheduler config, or nil if the scheduler
+// is not configured. Unlike patrols, the scheduler defaults to disabled.
+func SchedulerConfig(config *DaemonPatrolConfig) *scheduler.Config {
+	if config == nil || config.Patrols == nil {
+		return nil
+	}
+	return config.Patrols.Scheduler
+}
+
 // GetPatrolRigs returns the list of rigs for a patrol, or nil if all rigs should be patrolled.
 func GetPatrolRigs(config *DaemonPatrolConfig, patrol string) []string {
 	if config == nil || config.Patrols == nil {
@@ -0,0 +1,196 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/scheduler"
+)
+
+// slingTimeout bounds a single dispatch. Spawning a polecat creates a
+// worktree and starts a session, which can take a while on large repos.
+const slingTimeout = 5 * time.Minute
+
+// slingKillGrace is how long a cancelled sling's output pipes may stay open
+// after its process group is killed, so Stop never waits out slingTimeout.
+const slingKillGrace = 5 * time.Second
+
+// WorkScheduler periodically pulls ready beads across rigs and slings them
+// to polecats within the configured quotas. It runs as a background
+// goroutine within the daemon.
+type WorkScheduler struct {
+	townRoot string
+	logger   func(format string, args ...interface{})
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+
+	mu      sync.Mutex
+	config  *scheduler.Config
+	updated chan struct{} // signalled by UpdateConfig
+
+	// paused, when set, skips cycles (e.g. while gt down is running).
+	paused func() bool
+
+	// dispatch slings one bead; replaced in tests.
+	dispatch func(ctx context.Context, townRoot string, item scheduler.Item) error
+}
+
+// NewWorkScheduler creates a new work scheduler.
+func NewWorkScheduler(townRoot string, config *scheduler.Config, logger func(format string, args ...interface{})) *WorkScheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &WorkScheduler{
+		townRoot: townRoot,
+		config:   config,
+		updated:  make(chan struct{}, 1),
+		logger:   logger,
+		ctx:      ctx,
+		cancel:   cancel,
+		dispatch: slingItem,
+	}
+}
+
+// Start begins the scheduler goroutine.
+func (s *WorkScheduler) Start() {
+	s.wg.Add(1)
+	go s.run()
+}
+
+// Stop stops the scheduler at daemon shutdown. An in-flight sling is
+// cancelled (its process group is killed) rather than waited out, so Stop
+// returns within seconds and never holds up shutdown for a whole
+// slingTimeout. Config changes go through UpdateConfig instead.
+func (s *WorkScheduler) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// UpdateConfig swaps in a new config without interrupting a cycle in
+// progress: the next cycle plans with it, and a new interval applies from
+// then on. A disabled config makes cycles no-ops.
+func (s *WorkScheduler) UpdateConfig(config *scheduler.Config) {
+	s.mu.Lock()
+	s.config = config
+	s.mu.Unlock()
+	select {
+	case s.updated <- struct{}{}:
+	default:
+	}
+}
+
+// Enabled reports whether the current config enables dispatch.
+func (s *WorkScheduler) Enabled() bool {
+	return s.currentConfig().Enabled
+}
+
+func (s *WorkScheduler) currentConfig() *scheduler.Config {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.config
+}
+
+// run is the main scheduler loop.
+func (s *WorkScheduler) run() {
+	defer s.wg.Done()
+
+	interval := s.currentConfig().IntervalDuration()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-s.updated:
+			if next := s.currentConfig().IntervalDuration(); next != interval {
+				interval = next
+				ticker.Reset(interval)
+			}
+		case <-ticker.C:
+			s.cycle()
+		}
+	}
+}
+
+// cycle plans and dispatches one round of work.
+func (s *WorkScheduler) cycle() {
+	cfg := s.currentConfig()
+	if !cfg.Enabled || (s.paused != nil && s.paused()) {
+		return
+	}
+
+	run := &scheduler.Run{At: time.Now()}
+	in, err := scheduler.Snapshot(s.townRoot, cfg)
+	if err != nil {
+		s.logger("Scheduler: snapshot failed: %v", err)
+		run.Error = err.Error()
+		s.save(run)
+		return
+	}
+	s.execute(run, in)
+	s.save(run)
+}
+
+// execute plans from a snapshot and slings the planned beads. Each bead
+// is claimed first so a witness autospawning from the same ready list
+// doesn't sling it too.
+func (s *WorkScheduler) execute(run *scheduler.Run, in scheduler.Input) {
+	run.Plan = scheduler.Schedule(in)
+	scheduler.PruneClaims(s.townRoot, run.At)
+	for _, item := range run.Plan.Dispatch {
+		if s.ctx.Err() != nil {
+			return
+		}
+		d := scheduler.Dispatch{Bead: item.Bead, Rig: item.Rig}
+		claim, err := scheduler.ClaimBead(s.townRoot, item.Bead, "scheduler", time.Now())
+		if err != nil {
+			d.Skipped = err.Error()
+			s.logger("Scheduler: skipping %s: %v", item.Bead, err)
+			run.Dispatched = append(run.Dispatched, d)
+			continue
+		}
+		if err := s.dispatch(s.ctx, s.townRoot, item); err != nil {
+			claim.Release()
+			d.Error = err.Error()
+			s.logger("Scheduler: sling %s to %s failed: %v", item.Bead, item.Rig, err)
+		} else {
+			s.logger("Scheduler: slung %s (P%d) to %s", item.Bead, item.Priority, item.Rig)
+		}
+		run.Dispatched = append(run.Dispatched, d)
+	}
+}
+
+func (s *WorkScheduler) save(run *scheduler.Run) {
+	if err := scheduler.SaveLastRun(s.townRoot, run); err != nil {
+		s.logger("Scheduler: failed to save state: %v", err)
+	}
+}
+
+// slingItem dispatches a bead via gt sling, which handles polecat spawn,
+// capability routing and hook attachment.
+func slingItem(ctx context.Context, townRoot string, item scheduler.Item) error {
+	ctx, cancel := context.WithTimeout(ctx, slingTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "gt", "sling", item.Bead, item.Rig) //nolint:gosec // G204: args are bead/rig IDs from bd
+	cmd.Dir = townRoot
+	// Run gt sling in its own process group so cancelling kills the git
+	// and bd children too; otherwise they hold the output pipe open and
+	// CombinedOutput keeps waiting after gt itself is gone.
+	setSysProcAttr(cmd)
+	cmd.Cancel = func() error { return killProcessGroup(cmd.Process) }
+	cmd.WaitDelay = slingKillGrace
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			lines := strings.Split(msg, "\n")
+			return fmt.Errorf("%w: %s", err, lines[len(lines)-1])
+		}
+		return err
+	}
+	return nil
+}
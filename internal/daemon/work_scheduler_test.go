@@ -0,0 +1,158 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/scheduler"
+)
+
+func TestWorkScheduler_Execute(t *testing.T) {
+	townRoot := t.TempDir()
+	ws := NewWorkScheduler(townRoot, &scheduler.Config{Enabled: true}, t.Logf)
+	defer ws.Stop()
+
+	var slung []string
+	ws.dispatch = func(_ context.Context, _ string, item scheduler.Item) error {
+		slung = append(slung, item.Bead)
+		if item.Bead == "gt-bad" {
+			return errors.New("spawn failed")
+		}
+		return nil
+	}
+
+	run := &scheduler.Run{At: time.Now()}
+	ws.execute(run, scheduler.Input{
+		Items: []scheduler.Item{
+			{Bead: "gt-ok", Rig: "gastown", Priority: 0},
+			{Bead: "gt-bad", Rig: "gastown", Priority: 1},
+			{Bead: "gt-later", Rig: "gastown", Priority: 2},
+		},
+		Rigs:        []scheduler.RigState{{Name: "gastown", Max: 10}},
+		MaxDispatch: 2,
+	})
+
+	if len(slung) != 2 || slung[0] != "gt-ok" || slung[1] != "gt-bad" {
+		t.Errorf("slung = %v, want [gt-ok gt-bad]", slung)
+	}
+	if len(run.Dispatched) != 2 || run.Dispatched[0].Error != "" || run.Dispatched[1].Error != "spawn failed" {
+		t.Errorf("Dispatched = %+v", run.Dispatched)
+	}
+	if len(run.Plan.Waiting) != 1 || run.Plan.Waiting[0].Bead != "gt-later" {
+		t.Errorf("Waiting = %+v", run.Plan.Waiting)
+	}
+
+	ws.save(run)
+	if _, err := os.Stat(scheduler.StateFile(townRoot)); err != nil {
+		t.Errorf("state not saved: %v", err)
+	}
+}
+
+func TestWorkScheduler_SkipsClaimedBeads(t *testing.T) {
+	townRoot := t.TempDir()
+	ws := NewWorkScheduler(townRoot, &scheduler.Config{Enabled: true}, t.Logf)
+	defer ws.Stop()
+
+	var slung []string
+	ws.dispatch = func(_ context.Context, _ string, item scheduler.Item) error {
+		slung = append(slung, item.Bead)
+		return nil
+	}
+	// A witness autospawn is already slinging gt-taken.
+	if _, err := scheduler.ClaimBead(townRoot, "gt-taken", "gastown/witness", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	run := &scheduler.Run{At: time.Now()}
+	ws.execute(run, scheduler.Input{
+		Items: []scheduler.Item{
+			{Bead: "gt-taken", Rig: "gastown", Priority: 0},
+			{Bead: "gt-free", Rig: "gastown", Priority: 1},
+		},
+		Rigs: []scheduler.RigState{{Name: "gastown", Max: 10}},
+	})
+	if len(slung) != 1 || slung[0] != "gt-free" {
+		t.Errorf("slung = %v, want [gt-free]", slung)
+	}
+	if len(run.Dispatched) != 2 || run.Dispatched[0].Skipped == "" {
+		t.Errorf("Dispatched = %+v", run.Dispatched)
+	}
+	if _, err := scheduler.ClaimBead(townRoot, "gt-free", "gastown/witness", time.Now()); !errors.Is(err, scheduler.ErrClaimed) {
+		t.Errorf("slung bead not left claimed: %v", err)
+	}
+}
+
+func TestWorkScheduler_PausedSkipsCycle(t *testing.T) {
+	townRoot := t.TempDir()
+	ws := NewWorkScheduler(townRoot, &scheduler.Config{Enabled: true}, t.Logf)
+	defer ws.Stop()
+	ws.paused = func() bool { return true }
+
+	ws.cycle()
+	if _, err := os.Stat(scheduler.StateFile(townRoot)); !os.IsNotExist(err) {
+		t.Errorf("paused cycle should not record a run, stat err = %v", err)
+	}
+}
+
+func TestSchedulerConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	mayorDir := filepath.Join(tmpDir, "mayor")
+	if err := os.MkdirAll(mayorDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if SchedulerConfig(LoadPatrolConfig(tmpDir)) != nil {
+		t.Error("expected nil scheduler config when daemon.json is missing")
+	}
+
+	configJSON := `{
+		"type": "daemon-patrol-config",
+		"version": 1,
+		"patrols": {
+			"scheduler": {"enabled": true, "town_max_polecats": 8, "rigs": ["gastown"]}
+		}
+	}`
+	if err := os.WriteFile(filepath.Join(mayorDir, "daemon.json"), []byte(configJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := SchedulerConfig(LoadPatrolConfig(tmpDir))
+	if cfg == nil || !cfg.Enabled || cfg.TownMaxPolecats != 8 || !cfg.IncludesRig("gastown") || cfg.IncludesRig("beads") {
+		t.Errorf("SchedulerConfig = %+v", cfg)
+	}
+}
+
+func TestSlingItem_CancelKillsProcessGroup(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("process groups are unix-only")
+	}
+	// A gt that never finishes, with a child holding its output pipe open.
+	binDir := t.TempDir()
+	script := "#!/bin/sh\nsleep 60 &\nsleep 60\n"
+	if err := os.WriteFile(filepath.Join(binDir, "gt"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- slingItem(ctx, t.TempDir(), scheduler.Item{Bead: "gt-1", Rig: "gastown"}) }()
+	time.Sleep(200 * time.Millisecond)
+
+	start := time.Now()
+	cancel()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("cancelled sling should fail")
+		}
+		if waited := time.Since(start); waited >= slingKillGrace {
+			t.Errorf("cancelled sling took %v to return", waited)
+		}
+	case <-time.After(slingKillGrace + 5*time.Second):
+		t.Fatal("cancelled sling did not return")
+	}
+}
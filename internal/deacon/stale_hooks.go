@@ -2,12 +2,11 @@
 package deacon
 
 import (
-	"encoding/json"
 	"fmt"
-	"os/exec"
 	"strings"
 	"time"
 
+	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/tmux"
 )
@@ -59,6 +58,16 @@ type StaleHookScanResult struct {
 
 // ScanStaleHooks finds hooked beads older than the threshold and optionally unhooks them.
 func ScanStaleHooks(townRoot string, cfg *StaleHookConfig) (*StaleHookScanResult, error) {
+	t := tmux.NewTmux()
+	return scanStaleHooks(beads.New(townRoot), cfg, func(sessionName string) bool {
+		alive, _ := t.HasSession(sessionName)
+		return alive
+	})
+}
+
+// scanStaleHooks is ScanStaleHooks over store, with hasSession reporting
+// whether an agent's tmux session is alive.
+func scanStaleHooks(store beads.Store, cfg *StaleHookConfig, hasSession func(string) bool) (*StaleHookScanResult, error) {
 	if cfg == nil {
 		cfg = DefaultStaleHookConfig()
 	}
@@ -69,7 +78,7 @@ func ScanStaleHooks(townRoot string, cfg *StaleHookConfig) (*StaleHookScanResult
 	}
 
 	// Get all hooked beads
-	hookedBeads, err := listHookedBeads(townRoot)
+	hookedBeads, err := listHookedBeads(store)
 	if err != nil {
 		return nil, fmt.Errorf("listing hooked beads: %w", err)
 	}
@@ -78,7 +87,6 @@ func ScanStaleHooks(townRoot string, cfg *StaleHookConfig) (*StaleHookScanResult
 
 	// Filter to stale ones (older than threshold)
 	threshold := time.Now().Add(-cfg.MaxAge)
-	t := tmux.NewTmux()
 
 	for _, bead := range hookedBeads {
 		// Skip if updated recently (not stale)
@@ -99,14 +107,13 @@ func ScanStaleHooks(townRoot string, cfg *StaleHookConfig) (*StaleHookScanResult
 		if bead.Assignee != "" {
 			sessionName := assigneeToSessionName(bead.Assignee)
 			if sessionName != "" {
-				alive, _ := t.HasSession(sessionName)
-				hookResult.AgentAlive = alive
+				hookResult.AgentAlive = hasSession(sessionName)
 			}
 		}
 
 		// If agent is dead/gone and not dry run, unhook the bead
 		if !hookResult.AgentAlive && !cfg.DryRun {
-			if err := unhookBead(townRoot, bead.ID); err != nil {
+			if err := unhookBead(store, bead.ID); err != nil {
 				hookResult.Error = err.Error()
 			} else {
 				hookResult.Unhooked = true
@@ -120,30 +127,29 @@ func ScanStaleHooks(townRoot string, cfg *StaleHookConfig) (*StaleHookScanResult
 	return result, nil
 }
 
-// listHookedBeads returns all beads with status=hooked.
-func listHookedBeads(townRoot string) ([]*HookedBead, error) {
-	cmd := exec.Command("bd", "list", "--status=hooked", "--json", "--limit=0")
-	cmd.Dir = townRoot
-
-	output, err := cmd.Output()
+// listHookedBeads returns all beads with status=hooked. Beads whose
+// updated_at can't be parsed are skipped rather than treated as stale.
+func listHookedBeads(store beads.Store) ([]*HookedBead, error) {
+	issues, err := store.List(beads.ListOptions{Status: "hooked", Priority: -1, Limit: -1})
 	if err != nil {
-		// No hooked beads is not an error
-		if strings.Contains(string(output), "no issues found") {
-			return nil, nil
-		}
 		return nil, err
 	}
 
-	if len(output) == 0 || string(output) == "[]" || string(output) == "null\n" {
-		return nil, nil
-	}
-
-	var beads []*HookedBead
-	if err := json.Unmarshal(output, &beads); err != nil {
-		return nil, fmt.Errorf("parsing hooked beads: %w", err)
+	hooked := make([]*HookedBead, 0, len(issues))
+	for _, issue := range issues {
+		updatedAt, err := time.Parse(time.RFC3339, issue.UpdatedAt)
+		if err != nil {
+			continue
+		}
+		hooked = append(hooked, &HookedBead{
+			ID:        issue.ID,
+			Title:     issue.Title,
+			Status:    issue.Status,
+			Assignee:  issue.Assignee,
+			UpdatedAt: updatedAt,
+		})
 	}
-
-	return beads, nil
+	return hooked, nil
 }
 
 // assigneeToSessionName converts an assignee address to a tmux session name.
@@ -188,8 +194,7 @@ func assigneeToSessionName(assignee string) string {
 }
 
 // unhookBead sets a bead's status back to 'open'.
-func unhookBead(townRoot, beadID string) error {
-	cmd := exec.Command("bd", "update", beadID, "--status=open")
-	cmd.Dir = townRoot
-	return cmd.Run()
+func unhookBead(store beads.Store, beadID string) error {
+	status := "open"
+	return store.Update(beadID, beads.UpdateOptions{Status: &status})
 }
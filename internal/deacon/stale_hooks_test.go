@@ -0,0 +1,55 @@
+package deacon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+func TestScanStaleHooks_UnhooksDeadAgents(t *testing.T) {
+	old := time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339)
+	recent := time.Now().UTC().Format(time.RFC3339)
+	store := beads.NewMemStore("gt-")
+	store.Add(
+		&beads.Issue{ID: "gt-dead", Title: "Dead", Status: "hooked", Assignee: "gastown/polecats/max", UpdatedAt: old},
+		&beads.Issue{ID: "gt-live", Title: "Live", Status: "hooked", Assignee: "gastown/polecats/nux", UpdatedAt: old},
+		&beads.Issue{ID: "gt-new", Title: "New", Status: "hooked", Assignee: "gastown/polecats/max", UpdatedAt: recent},
+		&beads.Issue{ID: "gt-open", Title: "Open", Status: "open", UpdatedAt: old},
+	)
+	alive := func(sessionName string) bool { return sessionName == "gt-gastown-nux" }
+
+	result, err := scanStaleHooks(store, nil, alive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.TotalHooked != 3 || result.StaleCount != 2 || result.Unhooked != 1 {
+		t.Errorf("result = %+v", result)
+	}
+	for id, want := range map[string]string{"gt-dead": "open", "gt-live": "hooked", "gt-new": "hooked"} {
+		issue, err := store.Show(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if issue.Status != want {
+			t.Errorf("%s status = %s, want %s", id, issue.Status, want)
+		}
+	}
+}
+
+func TestScanStaleHooks_DryRun(t *testing.T) {
+	old := time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339)
+	store := beads.NewMemStore("gt-")
+	store.Add(&beads.Issue{ID: "gt-dead", Status: "hooked", UpdatedAt: old})
+
+	result, err := scanStaleHooks(store, &StaleHookConfig{MaxAge: time.Hour, DryRun: true}, func(string) bool { return false })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.StaleCount != 1 || result.Unhooked != 0 {
+		t.Errorf("result = %+v", result)
+	}
+	if issue, _ := store.Show("gt-dead"); issue.Status != "hooked" {
+		t.Errorf("dry run changed status to %s", issue.Status)
+	}
+}
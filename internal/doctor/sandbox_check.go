@@ -0,0 +1,122 @@
+package doctor
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/sandbox"
+)
+
+// SandboxRuntimeCheck verifies that rigs with sandbox mode enabled have a
+// working container runtime. Polecats in such rigs refuse to start without
+// one, so a broken runtime silently stalls all work on the rig.
+type SandboxRuntimeCheck struct {
+	BaseCheck
+}
+
+// NewSandboxRuntimeCheck creates a new sandbox runtime check.
+func NewSandboxRuntimeCheck() *SandboxRuntimeCheck {
+	return &SandboxRuntimeCheck{
+		BaseCheck: BaseCheck{
+			CheckName:        "sandbox-runtime",
+			CheckDescription: "Check container runtime for sandboxed rigs (podman/bubblewrap)",
+			CheckCategory:    CategoryInfrastructure,
+		},
+	}
+}
+
+// Run checks each sandboxed rig's runtime.
+func (c *SandboxRuntimeCheck) Run(ctx *CheckContext) *CheckResult {
+	rigsConfig, err := loadRigsConfig(filepath.Join(ctx.TownRoot, "mayor", "rigs.json"))
+	if err != nil {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusOK,
+			Message: "No rigs.json found (nothing to check)",
+		}
+	}
+
+	var rigNames []string
+	for name := range rigsConfig.Rigs {
+		if ctx.RigName == "" || ctx.RigName == name {
+			rigNames = append(rigNames, name)
+		}
+	}
+	sort.Strings(rigNames)
+
+	var sandboxed, problems []string
+	for _, name := range rigNames {
+		settings, err := config.LoadRigSettings(config.RigSettingsPath(filepath.Join(ctx.TownRoot, name)))
+		if err != nil || settings.Sandbox == nil || !settings.Sandbox.Enabled {
+			continue
+		}
+		sandboxed = append(sandboxed, name)
+		if err := probeSandboxRuntime(settings.Sandbox); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+
+	if len(sandboxed) == 0 {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusOK,
+			Message: "No rigs use sandbox mode",
+		}
+	}
+
+	if len(problems) > 0 {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusError,
+			Message: fmt.Sprintf("%d sandboxed rig(s) cannot start polecats", len(problems)),
+			Details: problems,
+			FixHint: "Install podman (rootless) or bubblewrap, or set sandbox.runtime in <rig>/settings/config.json",
+		}
+	}
+
+	return &CheckResult{
+		Name:    c.Name(),
+		Status:  StatusOK,
+		Message: fmt.Sprintf("Sandbox runtime OK for %s", strings.Join(sandboxed, ", ")),
+	}
+}
+
+// probeSandboxRuntime checks that the configured runtime is installed and
+// can actually create an unprivileged sandbox on this host.
+func probeSandboxRuntime(cfg *config.SandboxConfig) error {
+	rt, path, err := sandbox.Detect(sandbox.Runtime(cfg.Runtime))
+	if err != nil {
+		return err
+	}
+
+	switch rt {
+	case sandbox.RuntimePodman:
+		out, err := exec.Command(path, "info", "--format", "{{.Host.Security.Rootless}}").Output() //nolint:gosec
+		if err != nil {
+			return fmt.Errorf("podman info failed: %w", err)
+		}
+		if strings.TrimSpace(string(out)) != "true" {
+			return fmt.Errorf("podman is not running rootless")
+		}
+		image := cfg.Image
+		if image == "" {
+			image = sandbox.DefaultImage
+		}
+		if err := exec.Command(path, "image", "exists", image).Run(); err != nil { //nolint:gosec
+			return fmt.Errorf("image %s not present (run: podman pull %s)", image, image)
+		}
+	case sandbox.RuntimeBwrap:
+		if cfg.Network == "" || cfg.Network == sandbox.NetworkRestricted {
+			return fmt.Errorf("network %q requires podman", sandbox.NetworkRestricted)
+		}
+		// Unprivileged user namespaces may be disabled by the kernel or an LSM.
+		if out, err := exec.Command(path, "--ro-bind", "/", "/", "--unshare-pid", "true").CombinedOutput(); err != nil { //nolint:gosec
+			return fmt.Errorf("bwrap cannot create a sandbox: %s", strings.TrimSpace(string(out)))
+		}
+	}
+	return nil
+}
@@ -153,8 +153,8 @@ func (m *SessionManager) Start(dogName string, opts SessionStartOptions) error {
 		return fmt.Errorf("waiting for dog to start: %w", err)
 	}
 
-	// Accept bypass permissions warning if it appears
-	_ = m.tmux.AcceptBypassPermissionsWarning(sessionID)
+	// Answer the runtime's trust and permission dialogs (each answer is logged)
+	_, _ = runtime.AnswerStartupPrompts(m.tmux, sessionID, address, runtimeConfig)
 
 	time.Sleep(constants.ShutdownNotifyDelay)
 
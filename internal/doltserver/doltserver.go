@@ -365,14 +365,7 @@ func Start(townRoot string) error {
 	// Start dolt sql-server with --data-dir to serve all databases
 	// Note: --user flag is deprecated in newer Dolt; authentication is handled
 	// via privilege system. Default is root user with no password for localhost.
-	args := []string{"sql-server",
-		"--port", strconv.Itoa(config.Port),
-		"--data-dir", config.DataDir,
-	}
-	if config.MaxConnections > 0 {
-		args = append(args, "--max-connections", strconv.Itoa(config.MaxConnections))
-	}
-	cmd := exec.Command("dolt", args...)
+	cmd := exec.Command("dolt", ServerArgs(config)...)
 	cmd.Stdout = logFile
 	cmd.Stderr = logFile
 
@@ -426,6 +419,19 @@ func Start(townRoot string) error {
 	return nil
 }
 
+// ServerArgs returns the dolt arguments that start the town's SQL server.
+// Shared by gt dolt start and the generated systemd unit.
+func ServerArgs(config *Config) []string {
+	args := []string{"sql-server",
+		"--port", strconv.Itoa(config.Port),
+		"--data-dir", config.DataDir,
+	}
+	if config.MaxConnections > 0 {
+		args = append(args, "--max-connections", strconv.Itoa(config.MaxConnections))
+	}
+	return args
+}
+
 // cleanupStaleDoltLock removes a stale Dolt LOCK file if no process holds it.
 // Dolt's embedded mode uses a file lock at .dolt/noms/LOCK that can become stale
 // after crashes. This checks if any process holds the lock before removing.
@@ -0,0 +1,87 @@
+package eventbus
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultBuffer is the subscription buffer used when none is given.
+const DefaultBuffer = 256
+
+// Bus delivers events to in-process subscribers. Delivery never blocks the
+// publisher: a subscriber whose buffer is full misses the event, and the
+// miss is counted in Dropped.
+type Bus struct {
+	mu   sync.RWMutex
+	subs map[*Subscription]struct{}
+}
+
+// NewBus creates an empty bus.
+func NewBus() *Bus {
+	return &Bus{subs: make(map[*Subscription]struct{})}
+}
+
+// Subscription is a live subscription. Events arrive on C, which is closed
+// by Close.
+type Subscription struct {
+	C <-chan Event
+
+	c       chan Event
+	filter  Filter
+	bus     *Bus
+	dropped atomic.Int64
+	once    sync.Once
+}
+
+// Subscribe registers a subscriber for events matching filter. buffer <= 0
+// uses DefaultBuffer.
+func (b *Bus) Subscribe(filter Filter, buffer int) *Subscription {
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+	c := make(chan Event, buffer)
+	s := &Subscription{C: c, c: c, filter: filter, bus: b}
+
+	b.mu.Lock()
+	b.subs[s] = struct{}{}
+	b.mu.Unlock()
+	return s
+}
+
+// Publish delivers an event to matching subscribers.
+func (b *Bus) Publish(e Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for s := range b.subs {
+		if !s.filter.Match(e) {
+			continue
+		}
+		select {
+		case s.c <- e:
+		default:
+			s.dropped.Add(1)
+		}
+	}
+}
+
+// Len returns the number of subscribers.
+func (b *Bus) Len() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subs)
+}
+
+// Close unsubscribes and closes C. Safe to call more than once.
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.bus.mu.Lock()
+		delete(s.bus.subs, s)
+		s.bus.mu.Unlock()
+		close(s.c)
+	})
+}
+
+// Dropped returns how many events were missed because C was full.
+func (s *Subscription) Dropped() int64 {
+	return s.dropped.Load()
+}
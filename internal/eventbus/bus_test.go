@@ -0,0 +1,86 @@
+package eventbus
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBus_SubscribeFilterAndClose(t *testing.T) {
+	b := NewBus()
+	done := b.Subscribe(Filter{Types: []string{TypeDone}}, 4)
+	all := b.Subscribe(Filter{Audit: true}, 4)
+
+	b.Publish(Event{Type: TypeSling, Visibility: VisibilityFeed})
+	b.Publish(Event{Type: TypeDone, Visibility: VisibilityFeed})
+
+	if e := <-done.C; e.Type != TypeDone {
+		t.Errorf("done subscriber got %q", e.Type)
+	}
+	if len(all.C) != 2 {
+		t.Errorf("all subscriber has %d events, want 2", len(all.C))
+	}
+
+	done.Close()
+	done.Close() // idempotent
+	if _, ok := <-done.C; ok {
+		t.Error("C should be closed after Close")
+	}
+	if b.Len() != 1 {
+		t.Errorf("Len = %d after Close, want 1", b.Len())
+	}
+}
+
+func TestBus_SlowSubscriberDrops(t *testing.T) {
+	b := NewBus()
+	s := b.Subscribe(Filter{}, 1)
+	for i := 0; i < 3; i++ {
+		b.Publish(Event{Type: TypeMail, Visibility: VisibilityFeed})
+	}
+	if s.Dropped() != 2 {
+		t.Errorf("Dropped = %d, want 2", s.Dropped())
+	}
+}
+
+func TestPublish_FansOutToBothSinks(t *testing.T) {
+	townRoot := t.TempDir()
+	err := Publish(townRoot, Event{Type: TypeDone, Actor: "gastown/polecats/Toast", Context: "gt-abc",
+		Payload: map[string]interface{}{"bead": "gt-abc"}})
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(townRoot, EventsFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := Decode([]byte(strings.TrimSpace(string(data))))
+	if err != nil || e.Context != "gt-abc" {
+		t.Errorf("events file line %q: %v", data, err)
+	}
+	if e.Version != SchemaVersion || e.ID == "" || e.Visibility != VisibilityFeed || e.Time().IsZero() {
+		t.Errorf("envelope not filled: %+v", e)
+	}
+
+	log, err := os.ReadFile(filepath.Join(townRoot, "logs", "town.log"))
+	if err != nil {
+		t.Fatalf("town log not written: %v", err)
+	}
+	if !strings.Contains(string(log), "[done] gastown/polecats/Toast completed gt-abc") {
+		t.Errorf("town log = %q", log)
+	}
+}
+
+func TestEmit_RespectsSinks(t *testing.T) {
+	townRoot := t.TempDir()
+	if err := Emit(townRoot, Event{Type: TypeDone, Actor: "mayor"}, SinkEvents); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(townRoot, "logs", "town.log")); !os.IsNotExist(err) {
+		t.Error("SinkEvents alone must not write the town log")
+	}
+	if _, err := os.Stat(filepath.Join(townRoot, EventsFile)); err != nil {
+		t.Errorf("events file not written: %v", err)
+	}
+}
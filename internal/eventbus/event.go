@@ -0,0 +1,237 @@
+// Package eventbus is the single typed event schema for Gas Town activity.
+//
+// Agent lifecycle used to be recorded twice in different shapes: the JSON
+// activity log (.events.jsonl, read by the feed curator, seance and the
+// dashboard) and the human-readable town log (logs/town.log, read by
+// gt log), each with its own type constants. An eventbus.Event carries
+// everything both needed, with a schema version, and Publish fans it out to
+// both legacy sinks during the migration.
+//
+// .events.jsonl is the canonical stream: every published event lands there,
+// and the line format is a superset of the legacy events.Event so existing
+// readers keep working. Consumers can subscribe through the daemon's Unix
+// socket with Dial instead of tailing the file.
+package eventbus
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SchemaVersion is the event schema written by this build. Lines without a
+// version predate the bus and are read as version 1.
+const SchemaVersion = 1
+
+// ErrUnsupportedVersion means an event was written by a newer schema.
+var ErrUnsupportedVersion = errors.New("unsupported event schema version")
+
+// Event types. These are the union of the activity log and town log types;
+// the events and townlog packages keep their constants for compatibility.
+const (
+	TypeSling   = "sling"
+	TypeHook    = "hook"
+	TypeUnhook  = "unhook"
+	TypeHandoff = "handoff"
+	TypeDone    = "done"
+	TypeMail    = "mail"
+	TypeSpawn   = "spawn"
+	TypeWake    = "wake"
+	TypeKill    = "kill"
+	TypeCrash   = "crash"
+	TypeNudge   = "nudge"
+	TypeBoot    = "boot"
+	TypeHalt    = "halt"
+
+	// TypeCallback is a callback processed during a patrol.
+	TypeCallback = "callback"
+
+	// Session events (for seance discovery)
+	TypeSessionStart = "session_start"
+	TypeSessionEnd   = "session_end"
+
+	// Session death events (for crash investigation)
+	TypeSessionDeath = "session_death"
+	TypeMassDeath    = "mass_death"
+
+	// TypePaneState is a stuck or blocked classification of a session's
+	// output (permission dialog, question, error loop, repeated output).
+	TypePaneState = "pane_state"
+
+	// TypeStartupPrompt is a runtime startup dialog (workspace trust,
+	// permissions warning) answered automatically when a session started.
+	TypeStartupPrompt = "startup_prompt"
+
+	// Witness patrol events
+	TypePatrolStarted    = "patrol_started"
+	TypePolecatChecked   = "polecat_checked"
+	TypePolecatNudged    = "polecat_nudged"
+	TypeEscalationSent   = "escalation_sent"
+	TypeEscalationAcked  = "escalation_acked"
+	TypeEscalationClosed = "escalation_closed"
+	TypePatrolComplete   = "patrol_complete"
+
+	// Merge queue events (emitted by refinery)
+	TypeMergeStarted = "merge_started"
+	TypeMerged       = "merged"
+	TypeMergeFailed  = "merge_failed"
+	TypeMergeSkipped = "merge_skipped"
+
+	// Convoy events
+	TypeConvoyClosed = "convoy_closed"
+
+	// Daemon config hot-reload events
+	TypeConfigReloaded = "config_reloaded"
+	TypeConfigRejected = "config_rejected"
+)
+
+// Visibility levels for events.
+const (
+	VisibilityAudit = "audit" // Only in raw events log
+	VisibilityFeed  = "feed"  // Appears in curated feed
+	VisibilityBoth  = "both"  // Both audit and feed
+)
+
+// townLogTypes are the lifecycle types rendered into the town log.
+var townLogTypes = map[string]bool{
+	TypeSpawn:          true,
+	TypeWake:           true,
+	TypeNudge:          true,
+	TypeHandoff:        true,
+	TypeDone:           true,
+	TypeCrash:          true,
+	TypeKill:           true,
+	TypeCallback:       true,
+	TypePatrolStarted:  true,
+	TypePolecatChecked: true,
+	TypePolecatNudged:  true,
+	TypeEscalationSent: true,
+	TypePatrolComplete: true,
+	TypeSessionDeath:   true,
+	TypeMassDeath:      true,
+}
+
+// IsTownLogType reports whether events of this type belong in the town log.
+func IsTownLogType(eventType string) bool {
+	return townLogTypes[eventType]
+}
+
+// Event is a Gas Town activity event.
+type Event struct {
+	// Version is the schema version (see SchemaVersion).
+	Version int `json:"v,omitempty"`
+
+	// ID uniquely identifies the event, so subscribers can de-duplicate.
+	ID string `json:"id,omitempty"`
+
+	// Timestamp is RFC 3339 in UTC, as in the legacy activity log.
+	Timestamp string `json:"ts"`
+
+	// Source is the emitting component ("gt" for CLI commands).
+	Source string `json:"source"`
+	Type   string `json:"type"`
+
+	// Actor is who caused the event.
+	Actor string `json:"actor"`
+
+	// Agent is the agent the event is about, when that is not the actor
+	// (e.g. the target of a nudge). Defaults to Actor in the town log.
+	Agent string `json:"agent,omitempty"`
+
+	// Context is the one-line detail shown in the town log (issue ID,
+	// reason, message).
+	Context string `json:"context,omitempty"`
+
+	Payload    map[string]interface{} `json:"payload,omitempty"`
+	Visibility string                 `json:"visibility"`
+}
+
+// Time returns the event timestamp, or the zero time if it is malformed.
+func (e *Event) Time() time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, e.Timestamp)
+	return t
+}
+
+// Subject returns the agent the event is about.
+func (e *Event) Subject() string {
+	if e.Agent != "" {
+		return e.Agent
+	}
+	return e.Actor
+}
+
+// normalize fills in the envelope fields for a new event.
+func (e *Event) normalize() {
+	e.Version = SchemaVersion
+	if e.ID == "" {
+		e.ID = newID()
+	}
+	if e.Timestamp == "" {
+		e.Timestamp = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+	if e.Source == "" {
+		e.Source = "gt"
+	}
+	if e.Visibility == "" {
+		e.Visibility = VisibilityFeed
+	}
+}
+
+// newID returns a random event ID.
+func newID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// Decode parses one line of the activity log. Legacy lines (no version)
+// decode as version 1; lines from a newer schema return the event with
+// ErrUnsupportedVersion.
+func Decode(line []byte) (Event, error) {
+	var e Event
+	if err := json.Unmarshal(line, &e); err != nil {
+		return Event{}, fmt.Errorf("parsing event: %w", err)
+	}
+	if e.Version == 0 {
+		e.Version = 1
+	}
+	if e.Version > SchemaVersion {
+		return e, fmt.Errorf("%w: v%d (this build reads up to v%d)", ErrUnsupportedVersion, e.Version, SchemaVersion)
+	}
+	return e, nil
+}
+
+// Filter selects events for a subscriber. The zero Filter matches every
+// feed-visible event.
+type Filter struct {
+	// Types restricts to these event types.
+	Types []string `json:"types,omitempty"`
+
+	// Actor restricts to events whose actor or agent starts with this
+	// prefix (e.g. "gastown/" or "gastown/polecats/Toast").
+	Actor string `json:"actor,omitempty"`
+
+	// Audit includes audit-only events.
+	Audit bool `json:"audit,omitempty"`
+}
+
+// Match reports whether the filter selects the event.
+func (f Filter) Match(e Event) bool {
+	if !f.Audit && e.Visibility == VisibilityAudit {
+		return false
+	}
+	if len(f.Types) > 0 && !slices.Contains(f.Types, e.Type) {
+		return false
+	}
+	if f.Actor != "" && !strings.HasPrefix(e.Actor, f.Actor) && !strings.HasPrefix(e.Agent, f.Actor) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,76 @@
+package eventbus
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/townlog"
+)
+
+func TestDecode_LegacyLine(t *testing.T) {
+	line := `{"ts":"2026-01-02T03:04:05Z","source":"gt","type":"sling","actor":"mayor","payload":{"bead":"gt-1"},"visibility":"feed"}`
+	e, err := Decode([]byte(line))
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if e.Version != 1 || e.Type != TypeSling || e.Payload["bead"] != "gt-1" {
+		t.Errorf("decoded %+v", e)
+	}
+	if e.Time().IsZero() {
+		t.Error("Time() should parse the legacy timestamp")
+	}
+}
+
+func TestDecode_NewerVersion(t *testing.T) {
+	e, err := Decode([]byte(`{"v":99,"type":"done"}`))
+	if !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("err = %v, want ErrUnsupportedVersion", err)
+	}
+	if e.Type != "done" {
+		t.Errorf("newer event should still be returned, got %+v", e)
+	}
+}
+
+func TestFilter_Match(t *testing.T) {
+	feed := Event{Type: TypeDone, Actor: "gastown/polecats/Toast", Visibility: VisibilityFeed}
+	audit := Event{Type: TypeKill, Actor: "mayor", Agent: "gastown/crew/max", Visibility: VisibilityAudit}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		event  Event
+		want   bool
+	}{
+		{"zero filter feed", Filter{}, feed, true},
+		{"zero filter drops audit", Filter{}, audit, false},
+		{"audit included", Filter{Audit: true}, audit, true},
+		{"type match", Filter{Types: []string{TypeDone, TypeMerged}}, feed, true},
+		{"type mismatch", Filter{Types: []string{TypeMerged}}, feed, false},
+		{"actor prefix", Filter{Actor: "gastown/"}, feed, true},
+		{"agent prefix", Filter{Actor: "gastown/crew", Audit: true}, audit, true},
+		{"actor mismatch", Filter{Actor: "beads/"}, feed, false},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.Match(tt.event); got != tt.want {
+			t.Errorf("%s: Match = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// Every town log type must be routed to the town log by Publish.
+func TestTownLogTypesCoverTownlog(t *testing.T) {
+	for _, et := range []townlog.EventType{
+		townlog.EventSpawn, townlog.EventWake, townlog.EventNudge, townlog.EventHandoff,
+		townlog.EventDone, townlog.EventCrash, townlog.EventKill, townlog.EventCallback,
+		townlog.EventPatrolStarted, townlog.EventPolecatChecked, townlog.EventPolecatNudged,
+		townlog.EventEscalationSent, townlog.EventPatrolComplete,
+		townlog.EventSessionDeath, townlog.EventMassDeath,
+	} {
+		if !IsTownLogType(string(et)) {
+			t.Errorf("townlog type %q is not a town log type", et)
+		}
+	}
+	if IsTownLogType(TypeSling) {
+		t.Error("sling should not be rendered into the town log")
+	}
+}
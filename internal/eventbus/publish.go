@@ -0,0 +1,102 @@
+package eventbus
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/steveyegge/gastown/internal/townlog"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// EventsFile is the name of the activity log in the town root.
+const EventsFile = ".events.jsonl"
+
+// Sinks selects the persistent logs an event is written to.
+type Sinks uint8
+
+const (
+	// SinkEvents is the activity log (.events.jsonl).
+	SinkEvents Sinks = 1 << iota
+	// SinkTownLog is the human-readable town log (logs/town.log).
+	SinkTownLog
+)
+
+// mutex serializes appends to the activity log within this process.
+var mutex sync.Mutex
+
+// Publish records an event: it is appended to the activity log and rendered
+// into the town log if it is a lifecycle type. Subscribers receive it from
+// the daemon, which tails the activity log. An empty townRoot is resolved
+// from the working directory; outside a workspace the event is dropped.
+func Publish(townRoot string, e Event) error {
+	sinks := SinkEvents
+	if IsTownLogType(e.Type) {
+		sinks |= SinkTownLog
+	}
+	return Emit(townRoot, e, sinks)
+}
+
+// Emit is Publish with explicit sinks, for the legacy events and townlog
+// entry points that must keep writing only the log they always wrote.
+func Emit(townRoot string, e Event, sinks Sinks) error {
+	e.normalize()
+
+	if townRoot == "" {
+		townRoot, _ = workspace.FindFromCwd()
+	}
+
+	var err error
+	if townRoot != "" {
+		if sinks&SinkEvents != 0 {
+			err = appendEvent(townRoot, e)
+		}
+		if sinks&SinkTownLog != 0 {
+			if tlErr := appendTownLog(townRoot, e); err == nil {
+				err = tlErr
+			}
+		}
+	}
+
+	return err
+}
+
+// appendEvent appends an event to the activity log.
+func appendEvent(townRoot string, e Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshaling event: %w", err)
+	}
+	data = append(data, '\n')
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	path := filepath.Join(townRoot, EventsFile)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644) //nolint:gosec // G302: events file is non-sensitive operational data
+	if err != nil {
+		return fmt.Errorf("opening events file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("writing event: %w", err)
+	}
+	return nil
+}
+
+// appendTownLog renders an event into the town log.
+func appendTownLog(townRoot string, e Event) error {
+	ts := e.Time()
+	if ts.IsZero() {
+		return fmt.Errorf("event %s has malformed timestamp %q", e.ID, e.Timestamp)
+	}
+	return townlog.NewLogger(townRoot).LogEvent(townlog.Event{
+		Timestamp: ts.Local(),
+		Type:      townlog.EventType(e.Type),
+		Agent:     e.Subject(),
+		Context:   e.Context,
+	})
+}
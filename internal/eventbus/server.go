@@ -0,0 +1,237 @@
+package eventbus
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// tailInterval is how often the server polls the activity log.
+const tailInterval = 250 * time.Millisecond
+
+// ErrUnavailable means no event server is listening (the daemon is not
+// running, or predates the event bus).
+var ErrUnavailable = errors.New("event bus unavailable")
+
+// SocketPath returns the event bus socket, served by the daemon.
+func SocketPath(townRoot string) string {
+	return filepath.Join(townRoot, "daemon", "events.sock")
+}
+
+// Server streams the town's events to subscribers on a Unix socket.
+//
+// It tails the activity log, so it sees events from every process, and
+// fans them out to the connected clients.
+//
+// Protocol: the client sends one JSON Filter line; the server then writes
+// one JSON Event per line until either side closes.
+type Server struct {
+	townRoot string
+	bus      *Bus
+	listener net.Listener
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	logger   func(format string, args ...interface{})
+}
+
+// NewServer creates an event server for a town.
+func NewServer(townRoot string, logger func(format string, args ...interface{})) *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Server{
+		townRoot: townRoot,
+		bus:      NewBus(),
+		ctx:      ctx,
+		cancel:   cancel,
+		logger:   logger,
+	}
+}
+
+// Start listens on the socket and begins tailing the activity log.
+func (s *Server) Start() error {
+	path := SocketPath(s.townRoot)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating socket directory: %w", err)
+	}
+	_ = os.Remove(path) // Stale socket from an unclean exit
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", path, err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		_ = listener.Close()
+		return fmt.Errorf("securing %s: %w", path, err)
+	}
+	s.listener = listener
+
+	tailer := NewTailer(s.townRoot, true)
+	s.wg.Add(2)
+	go s.tail(tailer)
+	go s.accept()
+	return nil
+}
+
+// Stop closes the socket and disconnects subscribers.
+func (s *Server) Stop() {
+	s.cancel()
+	if s.listener != nil {
+		_ = s.listener.Close()
+		_ = os.Remove(SocketPath(s.townRoot))
+	}
+	s.wg.Wait()
+}
+
+// tail feeds the bus from the activity log.
+func (s *Server) tail(t *Tailer) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(tailInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			evs, err := t.Poll()
+			if err != nil {
+				s.logger("event bus: %v", err)
+				continue
+			}
+			for _, e := range evs {
+				s.bus.Publish(e)
+			}
+		}
+	}
+}
+
+func (s *Server) accept() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if s.ctx.Err() == nil {
+				s.logger("event bus: accept: %v", err)
+			}
+			return
+		}
+		s.wg.Add(1)
+		go s.serve(conn)
+	}
+}
+
+// serve streams events to one subscriber.
+func (s *Server) serve(conn net.Conn) {
+	defer s.wg.Done()
+	defer conn.Close()
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		return
+	}
+	var filter Filter
+	if err := json.Unmarshal(line, &filter); err != nil {
+		_ = json.NewEncoder(conn).Encode(map[string]string{"error": "invalid filter: " + err.Error()})
+		return
+	}
+	_ = conn.SetReadDeadline(time.Time{})
+
+	sub := s.bus.Subscribe(filter, 0)
+	defer sub.Close()
+	defer func() {
+		if n := sub.Dropped(); n > 0 {
+			s.logger("event bus: slow subscriber missed %d event(s)", n)
+		}
+	}()
+
+	// Subscribers send nothing after the filter; a read returning means
+	// they hung up.
+	gone := make(chan struct{})
+	go func() {
+		_, _ = reader.ReadByte()
+		close(gone)
+	}()
+
+	enc := json.NewEncoder(conn)
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-gone:
+			return
+		case e := <-sub.C:
+			_ = conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+			if err := enc.Encode(e); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Stream is a client subscription to the daemon's event bus.
+type Stream struct {
+	conn    net.Conn
+	scanner *bufio.Scanner
+}
+
+// Dial subscribes to the town's event bus. Returns ErrUnavailable if the
+// daemon is not serving it.
+func Dial(townRoot string, filter Filter) (*Stream, error) {
+	conn, err := net.DialTimeout("unix", SocketPath(townRoot), time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	data, err := json.Marshal(filter)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	if _, err := conn.Write(append(data, '\n')); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("sending filter: %w", err)
+	}
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	return &Stream{conn: conn, scanner: scanner}, nil
+}
+
+// Next blocks until the next event. Returns io.EOF when the server closes
+// the stream.
+func (s *Stream) Next() (Event, error) {
+	for s.scanner.Scan() {
+		line := s.scanner.Bytes()
+		var serverErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(line, &serverErr) == nil && serverErr.Error != "" {
+			return Event{}, errors.New(serverErr.Error)
+		}
+		e, err := Decode(line)
+		if errors.Is(err, ErrUnsupportedVersion) {
+			continue
+		}
+		if err != nil {
+			return Event{}, err
+		}
+		return e, nil
+	}
+	if err := s.scanner.Err(); err != nil {
+		return Event{}, err
+	}
+	return Event{}, io.EOF
+}
+
+// Close ends the subscription.
+func (s *Stream) Close() error {
+	return s.conn.Close()
+}
@@ -0,0 +1,159 @@
+package eventbus
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func appendLine(t *testing.T, path, line string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(line); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTailer_PartialLinesAndRewrite(t *testing.T) {
+	townRoot := t.TempDir()
+	path := filepath.Join(townRoot, EventsFile)
+	appendLine(t, path, `{"type":"old","visibility":"feed"}`+"\n")
+
+	tailer := NewTailer(townRoot, true)
+	if evs, _ := tailer.Poll(); len(evs) != 0 {
+		t.Fatalf("fromEnd tailer returned history: %+v", evs)
+	}
+
+	appendLine(t, path, `{"type":"sling","visibility":"feed"}`+"\n"+`{"type":"do`)
+	evs, err := tailer.Poll()
+	if err != nil || len(evs) != 1 || evs[0].Type != "sling" {
+		t.Fatalf("Poll = %+v, %v", evs, err)
+	}
+	appendLine(t, path, `ne","visibility":"feed"}`+"\n")
+	if evs, _ := tailer.Poll(); len(evs) != 1 || evs[0].Type != "done" {
+		t.Fatalf("partial line not completed: %+v", evs)
+	}
+
+	appendLine(t, path, `{"type":"merged","visibility":"feed"}`+"\n")
+	if evs, _ := tailer.Poll(); len(evs) != 1 || evs[0].Type != "merged" {
+		t.Errorf("after partial line: %+v", evs)
+	}
+}
+
+func TestTailer_RewriteKeepsUnreadEvents(t *testing.T) {
+	townRoot := t.TempDir()
+	path := filepath.Join(townRoot, EventsFile)
+	line := func(id, typ, ts string) string {
+		return `{"v":1,"id":"` + id + `","ts":"` + ts + `","type":"` + typ + `","visibility":"feed"}` + "\n"
+	}
+	// Pruning writes a new file and renames it over the log.
+	rewrite := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path+".tmp", []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(path+".tmp", path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tailer := NewTailer(townRoot, false)
+	appendLine(t, path, line("a", "sling", "2026-01-01T00:00:00Z")+line("b", "done", "2026-01-01T00:00:01Z"))
+	if evs, _ := tailer.Poll(); len(evs) != 2 {
+		t.Fatalf("Poll = %+v", evs)
+	}
+
+	// Pruning drops "a" and rewrites the file with an event the tailer
+	// hasn't read yet: only that event is returned.
+	rewrite(line("b", "done", "2026-01-01T00:00:01Z") + line("c", "merged", "2026-01-01T00:00:02Z"))
+	if evs, _ := tailer.Poll(); len(evs) != 1 || evs[0].ID != "c" {
+		t.Errorf("after rewrite: %+v", evs)
+	}
+
+	// Pruning removed the last event seen: older events are still skipped.
+	rewrite(line("x", "done", "2026-01-01T00:00:01Z") + line("d", "mail", "2026-01-01T00:00:03Z"))
+	if evs, _ := tailer.Poll(); len(evs) != 1 || evs[0].ID != "d" {
+		t.Errorf("after rewrite without last event: %+v", evs)
+	}
+
+	// Truncation to empty, then new events.
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if evs, _ := tailer.Poll(); len(evs) != 0 {
+		t.Errorf("truncated log returned %+v", evs)
+	}
+	appendLine(t, path, line("e", "sling", "2026-01-01T00:00:04.25Z"))
+	if evs, _ := tailer.Poll(); len(evs) != 1 || evs[0].ID != "e" {
+		t.Errorf("after truncate: %+v", evs)
+	}
+
+	// Pruning removed the last event seen and the log now holds events from
+	// the same second: only the ones written after it are returned.
+	rewrite(line("w", "done", "2026-01-01T00:00:04.1Z") + line("f", "mail", "2026-01-01T00:00:04.5Z"))
+	if evs, _ := tailer.Poll(); len(evs) != 1 || evs[0].ID != "f" {
+		t.Errorf("after same-second rewrite: %+v", evs)
+	}
+}
+
+func TestServer_StreamsEventsFromLog(t *testing.T) {
+	// Unix socket paths are length-limited; t.TempDir can be too long.
+	townRoot, err := os.MkdirTemp("", "gtbus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(townRoot)
+
+	srv := NewServer(townRoot, t.Logf)
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	stream, err := Dial(townRoot, Filter{Types: []string{TypeMerged}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+
+	// Wait for the server to register the subscription before emitting.
+	deadline := time.Now().Add(2 * time.Second)
+	for srv.bus.Len() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Written as another process would: straight to the activity log.
+	if err := Emit(townRoot, Event{Type: TypeSling, Actor: "mayor"}, SinkEvents); err != nil {
+		t.Fatal(err)
+	}
+	if err := Emit(townRoot, Event{Type: TypeMerged, Actor: "gastown/refinery"}, SinkEvents); err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(chan Event, 1)
+	go func() {
+		if e, err := stream.Next(); err == nil {
+			got <- e
+		}
+	}()
+	select {
+	case e := <-got:
+		if e.Type != TypeMerged || e.Actor != "gastown/refinery" {
+			t.Errorf("streamed %+v", e)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("no event streamed")
+	}
+}
+
+func TestDial_Unavailable(t *testing.T) {
+	_, err := Dial(t.TempDir(), Filter{})
+	if !errors.Is(err, ErrUnavailable) {
+		t.Errorf("err = %v, want ErrUnavailable", err)
+	}
+}
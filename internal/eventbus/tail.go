@@ -0,0 +1,157 @@
+package eventbus
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Tailer reads events appended to the activity log since the last Poll.
+//
+// KRC pruning rewrites the log in place, and events appended just before
+// the rewrite may not have been read yet. When the file is replaced or
+// shrinks the tailer re-reads it from the start and skips what it already
+// returned: everything up to the last event it saw, or, if pruning removed
+// that event, everything older than it.
+type Tailer struct {
+	path    string
+	offset  int64
+	info    os.FileInfo
+	partial []byte
+
+	// lastID and lastTime mark the newest event returned (or, for a
+	// tailer started at the end, the start time) for resyncing.
+	lastID   string
+	lastTime time.Time
+	resync   bool
+}
+
+// Cursor marks the last event a consumer has taken from the activity log,
+// so a durable consumer can resume after a restart.
+type Cursor struct {
+	ID   string    `json:"id"`
+	Time time.Time `json:"ts"`
+}
+
+// NewTailer creates a tailer for the town's activity log. With fromEnd set
+// only events appended after this call are returned.
+func NewTailer(townRoot string, fromEnd bool) *Tailer {
+	t := &Tailer{path: filepath.Join(townRoot, EventsFile)}
+	if fromEnd {
+		t.lastTime = time.Now().UTC()
+		if info, err := os.Stat(t.path); err == nil {
+			t.offset = info.Size()
+			t.info = info
+		}
+	}
+	return t
+}
+
+// NewTailerAfter creates a tailer that resumes after the event c marks.
+// Its first Poll reads the log from the start and skips everything up to
+// and including that event (or, if pruning removed it, anything older).
+func NewTailerAfter(townRoot string, c Cursor) *Tailer {
+	return &Tailer{
+		path:     filepath.Join(townRoot, EventsFile),
+		lastID:   c.ID,
+		lastTime: c.Time,
+		resync:   true,
+	}
+}
+
+// Cursor returns the position after the last event returned by Poll.
+func (t *Tailer) Cursor() Cursor {
+	return Cursor{ID: t.lastID, Time: t.lastTime}
+}
+
+// Poll returns the complete events appended since the last call. Lines
+// that fail to decode are skipped. A missing log yields no events.
+func (t *Tailer) Poll() ([]Event, error) {
+	f, err := os.Open(t.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("opening events file: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("stat events file: %w", err)
+	}
+	if (t.info != nil && !os.SameFile(t.info, info)) || info.Size() < t.offset {
+		// Rewritten by pruning: read it again, skipping what was returned.
+		t.offset = 0
+		t.partial = nil
+		t.resync = true
+	}
+	t.info = info
+	if info.Size() == t.offset {
+		return nil, nil
+	}
+
+	if _, err := f.Seek(t.offset, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("seeking events file: %w", err)
+	}
+	data, err := io.ReadAll(io.LimitReader(f, info.Size()-t.offset))
+	if err != nil {
+		return nil, fmt.Errorf("reading events file: %w", err)
+	}
+	t.offset += int64(len(data))
+
+	data = append(t.partial, data...)
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		t.partial = data
+		return nil, nil
+	}
+	t.partial = append([]byte(nil), data[end+1:]...)
+
+	var result []Event
+	for _, line := range bytes.Split(data[:end], []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		e, err := Decode(line)
+		if err != nil {
+			continue
+		}
+		result = append(result, e)
+	}
+	if t.resync {
+		result = t.unseen(result)
+		t.resync = false
+	}
+	if n := len(result); n > 0 {
+		t.lastID = result[n-1].ID
+		if ts := result[n-1].Time(); !ts.IsZero() {
+			t.lastTime = ts
+		}
+	}
+	return result, nil
+}
+
+// unseen drops the events a rewritten log repeats from before the rewrite.
+func (t *Tailer) unseen(evs []Event) []Event {
+	if t.lastID != "" {
+		for i := range evs {
+			if evs[i].ID == t.lastID {
+				return evs[i+1:]
+			}
+		}
+	}
+	if t.lastTime.IsZero() {
+		return evs
+	}
+	var out []Event
+	for _, e := range evs {
+		if e.ID != t.lastID && e.Time().After(t.lastTime) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
@@ -1,94 +1,96 @@
 // Package events provides event logging for the gt activity feed.
 //
 // Events are written to ~/gt/.events.jsonl (raw audit log) and later
-// curated by the feed daemon into ~/.feed.jsonl (user-facing).
+// curated by the feed daemon into ~/.feed.jsonl (user-facing). Writes go
+// through the event bus (see package eventbus), which owns the schema.
 package events
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
-	"sync"
-	"time"
 
-	"github.com/steveyegge/gastown/internal/workspace"
+	"github.com/steveyegge/gastown/internal/eventbus"
 )
 
-// Event represents an activity event in Gas Town.
-type Event struct {
-	Timestamp  string                 `json:"ts"`
-	Source     string                 `json:"source"`
-	Type       string                 `json:"type"`
-	Actor      string                 `json:"actor"`
-	Payload    map[string]interface{} `json:"payload,omitempty"`
-	Visibility string                 `json:"visibility"`
-}
+// Event represents an activity event in Gas Town. It is the event bus
+// schema; lines written before the bus decode with the envelope fields empty.
+type Event = eventbus.Event
 
 // Visibility levels for events.
 const (
-	VisibilityAudit = "audit" // Only in raw events log
-	VisibilityFeed  = "feed"  // Appears in curated feed
-	VisibilityBoth  = "both"  // Both audit and feed
+	VisibilityAudit = eventbus.VisibilityAudit // Only in raw events log
+	VisibilityFeed  = eventbus.VisibilityFeed  // Appears in curated feed
+	VisibilityBoth  = eventbus.VisibilityBoth  // Both audit and feed
 )
 
-// Common event types for gt commands.
+// Common event types for gt commands. The canonical list is in eventbus.
 const (
-	TypeSling   = "sling"
-	TypeHook    = "hook"
-	TypeUnhook  = "unhook"
-	TypeHandoff = "handoff"
-	TypeDone    = "done"
-	TypeMail    = "mail"
-	TypeSpawn   = "spawn"
-	TypeKill    = "kill"
-	TypeNudge   = "nudge"
-	TypeBoot    = "boot"
-	TypeHalt    = "halt"
+	TypeSling   = eventbus.TypeSling
+	TypeHook    = eventbus.TypeHook
+	TypeUnhook  = eventbus.TypeUnhook
+	TypeHandoff = eventbus.TypeHandoff
+	TypeDone    = eventbus.TypeDone
+	TypeMail    = eventbus.TypeMail
+	TypeSpawn   = eventbus.TypeSpawn
+	TypeKill    = eventbus.TypeKill
+	TypeNudge   = eventbus.TypeNudge
+	TypeBoot    = eventbus.TypeBoot
+	TypeHalt    = eventbus.TypeHalt
 
 	// Session events (for seance discovery)
-	TypeSessionStart = "session_start"
-	TypeSessionEnd   = "session_end"
+	TypeSessionStart = eventbus.TypeSessionStart
+	TypeSessionEnd   = eventbus.TypeSessionEnd
 
 	// Session death events (for crash investigation)
-	TypeSessionDeath = "session_death" // Feed-visible session termination
-	TypeMassDeath    = "mass_death"    // Multiple sessions died in short window
+	TypeSessionDeath = eventbus.TypeSessionDeath // Feed-visible session termination
+	TypeMassDeath    = eventbus.TypeMassDeath    // Multiple sessions died in short window
+
+	// Pane classification events (stuck/blocked agents)
+	TypePaneState = eventbus.TypePaneState
+
+	// Startup dialogs answered automatically
+	TypeStartupPrompt = eventbus.TypeStartupPrompt
 
 	// Witness patrol events
-	TypePatrolStarted   = "patrol_started"
-	TypePolecatChecked  = "polecat_checked"
-	TypePolecatNudged   = "polecat_nudged"
-	TypeEscalationSent   = "escalation_sent"
-	TypeEscalationAcked  = "escalation_acked"
-	TypeEscalationClosed = "escalation_closed"
-	TypePatrolComplete   = "patrol_complete"
+	TypePatrolStarted    = eventbus.TypePatrolStarted
+	TypePolecatChecked   = eventbus.TypePolecatChecked
+	TypePolecatNudged    = eventbus.TypePolecatNudged
+	TypeEscalationSent   = eventbus.TypeEscalationSent
+	TypeEscalationAcked  = eventbus.TypeEscalationAcked
+	TypeEscalationClosed = eventbus.TypeEscalationClosed
+	TypePatrolComplete   = eventbus.TypePatrolComplete
 
 	// Merge queue events (emitted by refinery)
-	TypeMergeStarted = "merge_started"
-	TypeMerged       = "merged"
-	TypeMergeFailed  = "merge_failed"
-	TypeMergeSkipped = "merge_skipped"
+	TypeMergeStarted = eventbus.TypeMergeStarted
+	TypeMerged       = eventbus.TypeMerged
+	TypeMergeFailed  = eventbus.TypeMergeFailed
+	TypeMergeSkipped = eventbus.TypeMergeSkipped
+
+	// Convoy events
+	TypeConvoyClosed = eventbus.TypeConvoyClosed
+
+	// Daemon config hot-reload events
+	TypeConfigReloaded = eventbus.TypeConfigReloaded
+	TypeConfigRejected = eventbus.TypeConfigRejected
 )
 
 // EventsFile is the name of the raw events log.
-const EventsFile = ".events.jsonl"
-
-// mutex protects concurrent writes to the events file.
-var mutex sync.Mutex
+const EventsFile = eventbus.EventsFile
 
 // Log writes an event to the events log.
-// The event is appended to ~/gt/.events.jsonl.
+// The event is appended to ~/gt/.events.jsonl and delivered to event bus
+// subscribers. It is not rendered into the town log; use eventbus.Publish
+// to record both.
 // Returns nil if logging fails (events are best-effort).
 func Log(eventType, actor string, payload map[string]interface{}, visibility string) error {
-	event := Event{
-		Timestamp:  time.Now().UTC().Format(time.RFC3339),
+	return eventbus.Emit("", eventbus.Event{
 		Source:     "gt",
 		Type:       eventType,
 		Actor:      actor,
 		Payload:    payload,
 		Visibility: visibility,
-	}
-	return write(event)
+	}, eventbus.SinkEvents)
 }
 
 // LogFeed is a convenience wrapper for feed-visible events.
@@ -101,39 +103,39 @@ func LogAudit(eventType, actor string, payload map[string]interface{}) error {
 	return Log(eventType, actor, payload, VisibilityAudit)
 }
 
-// write appends an event to the events file.
-func write(event Event) error {
-	// Find town root
-	townRoot, err := workspace.FindFromCwd()
-	if err != nil || townRoot == "" {
-		// Silently ignore - we're not in a Gas Town workspace
+// PaneStateDir holds the last pane state logged for each session, so
+// callers that classify panes every pass log a stuck session once.
+func PaneStateDir(townRoot string) string {
+	return filepath.Join(townRoot, "daemon", "pane-states")
+}
+
+// LogPaneState logs a pane_state event when a session's state changes.
+// state is the session's classification while it looks stuck, or "" once
+// it doesn't; callers report every pass, and only a new stuck state is
+// logged. The witness, daemon and deacon share the record, so a session
+// they all see stuck is logged once.
+func LogPaneState(townRoot, session, agent, state, detail, caller string) error {
+	path := filepath.Join(PaneStateDir(townRoot), filepath.Base(session))
+	last, _ := os.ReadFile(path) //nolint:gosec // G304: path is under the town root
+	if string(last) == state {
 		return nil
 	}
-
-	eventsPath := filepath.Join(townRoot, EventsFile)
-
-	// Marshal event to JSON
-	data, err := json.Marshal(event)
-	if err != nil {
-		return fmt.Errorf("marshaling event: %w", err)
+	if state == "" {
+		return os.Remove(path)
 	}
-	data = append(data, '\n')
-
-	// Append to file with proper locking
-	mutex.Lock()
-	defer mutex.Unlock()
-
-	f, err := os.OpenFile(eventsPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644) //nolint:gosec // G302: events file is non-sensitive operational data
-	if err != nil {
-		return fmt.Errorf("opening events file: %w", err)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
 	}
-	defer f.Close()
-
-	if _, err := f.Write(data); err != nil {
-		return fmt.Errorf("writing event: %w", err)
+	if err := os.WriteFile(path, []byte(state), 0644); err != nil { //nolint:gosec // G306: not secret
+		return err
 	}
-
-	return nil
+	return eventbus.Emit(townRoot, eventbus.Event{
+		Source:     "gt",
+		Type:       TypePaneState,
+		Actor:      agent,
+		Payload:    PaneStatePayload(session, agent, state, detail, caller),
+		Visibility: VisibilityFeed,
+	}, eventbus.SinkEvents)
 }
 
 // Payload helpers for common event structures.
@@ -213,6 +215,15 @@ func MergePayload(mrID, worker, branch, reason string) map[string]interface{} {
 	return p
 }
 
+// ConvoyClosedPayload creates a payload for convoy_closed events.
+func ConvoyClosedPayload(convoyID, title, reason string) map[string]interface{} {
+	return map[string]interface{}{
+		"convoy": convoyID,
+		"title":  title,
+		"reason": reason,
+	}
+}
+
 // PatrolPayload creates a payload for patrol start/complete events.
 func PatrolPayload(rig string, polecatCount int, message string) map[string]interface{} {
 	p := map[string]interface{}{
@@ -294,6 +305,41 @@ func SessionDeathPayload(session, agent, reason, caller string) map[string]inter
 	}
 }
 
+// PaneStatePayload creates a payload for pane state events.
+// session: tmux session name that was classified
+// agent: Gas Town agent identity (e.g., "gastown/polecats/Toast")
+// state: the classification (e.g., "permission", "error_loop")
+// detail: the output line that decided it
+// caller: what classified it (e.g., "daemon", "witness", "deacon")
+func PaneStatePayload(session, agent, state, detail, caller string) map[string]interface{} {
+	p := map[string]interface{}{
+		"session": session,
+		"agent":   agent,
+		"state":   state,
+		"caller":  caller,
+	}
+	if detail != "" {
+		p["detail"] = detail
+	}
+	return p
+}
+
+// StartupPromptPayload creates a payload for startup prompt events.
+// session: tmux session name
+// agent: Gas Town agent identity (e.g., "gastown/polecats/Toast")
+// prompt: the startup prompt's name (e.g., "trust-folder")
+// keys: the keys sent to answer it
+// line: the output line that matched
+func StartupPromptPayload(session, agent, prompt string, keys []string, line string) map[string]interface{} {
+	return map[string]interface{}{
+		"session": session,
+		"agent":   agent,
+		"prompt":  prompt,
+		"keys":    keys,
+		"line":    line,
+	}
+}
+
 // MassDeathPayload creates a payload for mass death events.
 // count: number of sessions that died
 // window: time window in which deaths occurred (e.g., "5s")
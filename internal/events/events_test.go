@@ -0,0 +1,36 @@
+package events
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLogPaneState_LogsChangesOnly(t *testing.T) {
+	townRoot := t.TempDir()
+	count := func() int {
+		data, _ := os.ReadFile(filepath.Join(townRoot, EventsFile))
+		return strings.Count(string(data), `"type":"pane_state"`)
+	}
+
+	steps := []struct {
+		state string
+		want  int
+	}{
+		{"permission", 1},
+		{"permission", 1}, // still stuck: not logged again
+		{"error_loop", 2}, // stuck a different way
+		{"", 2},           // recovered
+		{"", 2},
+		{"error_loop", 3}, // stuck again
+	}
+	for i, step := range steps {
+		if err := LogPaneState(townRoot, "gt-gastown-Toast", "gastown/polecats/Toast", step.state, "", "witness"); err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+		if got := count(); got != step.want {
+			t.Errorf("step %d (%q): %d pane_state events, want %d", i, step.state, got, step.want)
+		}
+	}
+}
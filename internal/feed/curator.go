@@ -365,6 +365,14 @@ func (c *Curator) generateSummary(event *events.Event) string {
 		}
 		return "Merge failed"
 
+	case events.TypeConvoyClosed:
+		title, _ := event.Payload["title"].(string)
+		convoy, _ := event.Payload["convoy"].(string)
+		if title != "" {
+			return fmt.Sprintf("Convoy %s landed: %s", convoy, title)
+		}
+		return fmt.Sprintf("Convoy %s landed", convoy)
+
 	case events.TypeSessionDeath:
 		session, _ := event.Payload["session"].(string)
 		reason, _ := event.Payload["reason"].(string)
@@ -376,6 +384,19 @@ func (c *Curator) generateSummary(event *events.Event) string {
 		}
 		return "Session terminated"
 
+	case events.TypePaneState:
+		agent, _ := event.Payload["agent"].(string)
+		state, _ := event.Payload["state"].(string)
+		if detail, _ := event.Payload["detail"].(string); detail != "" {
+			return fmt.Sprintf("%s looks stuck (%s): %s", agent, state, detail)
+		}
+		return fmt.Sprintf("%s looks stuck (%s)", agent, state)
+
+	case events.TypeStartupPrompt:
+		agent, _ := event.Payload["agent"].(string)
+		prompt, _ := event.Payload["prompt"].(string)
+		return fmt.Sprintf("%s: answered %s prompt at startup", agent, prompt)
+
 	case events.TypeMassDeath:
 		count, _ := event.Payload["count"].(float64) // JSON numbers are float64
 		possibleCause, _ := event.Payload["possible_cause"].(string)
@@ -387,6 +408,20 @@ func (c *Curator) generateSummary(event *events.Event) string {
 		}
 		return "Multiple sessions died simultaneously"
 
+	case events.TypeConfigReloaded:
+		if file, ok := event.Payload["file"].(string); ok {
+			return fmt.Sprintf("Daemon reloaded %s", file)
+		}
+		return "Daemon config reloaded"
+
+	case events.TypeConfigRejected:
+		file, _ := event.Payload["file"].(string)
+		reason, _ := event.Payload["reason"].(string)
+		if file != "" && reason != "" {
+			return fmt.Sprintf("Daemon rejected %s: %s", file, reason)
+		}
+		return "Daemon rejected config change"
+
 	default:
 		return fmt.Sprintf("%s: %s", event.Actor, event.Type)
 	}
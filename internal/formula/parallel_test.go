@@ -12,7 +12,7 @@ func TestParallelReadySteps(t *testing.T) {
 	}
 
 	// Verify parallel flag is not set on sequential steps
-	sequentialSteps := []string{"survey-workers", "check-timer-gates", "check-swarm-completion", "ping-deacon"}
+	sequentialSteps := []string{"survey-workers", "spawn-workers", "check-timer-gates", "check-swarm-completion", "ping-deacon"}
 	for _, id := range sequentialSteps {
 		step := f.GetStep(id)
 		if step == nil {
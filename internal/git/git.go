@@ -729,6 +729,13 @@ func (g *Git) ResetBranch(name, ref string) error {
 	return err
 }
 
+// CommonDir returns the absolute path of the repository's common git
+// directory. For a worktree this is the main repository's .git (or bare
+// repo) that holds objects and refs shared by all worktrees.
+func (g *Git) CommonDir() (string, error) {
+	return g.run("rev-parse", "--path-format=absolute", "--git-common-dir")
+}
+
 // Rev returns the commit hash for the given ref.
 func (g *Git) Rev(ref string) (string, error) {
 	return g.run("rev-parse", ref)
@@ -298,6 +298,34 @@ func TestRev(t *testing.T) {
 	}
 }
 
+func TestCommonDir(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+
+	wtPath := filepath.Join(t.TempDir(), "wt")
+	cmd := exec.Command("git", "worktree", "add", "-b", "wt-branch", wtPath)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git worktree add: %v\n%s", err, out)
+	}
+
+	want, err := g.CommonDir()
+	if err != nil {
+		t.Fatalf("CommonDir: %v", err)
+	}
+	if !filepath.IsAbs(want) || filepath.Base(want) != ".git" {
+		t.Errorf("CommonDir = %q, want absolute path to .git", want)
+	}
+
+	got, err := NewGit(wtPath).CommonDir()
+	if err != nil {
+		t.Fatalf("worktree CommonDir: %v", err)
+	}
+	if got != want {
+		t.Errorf("worktree CommonDir = %q, want %q", got, want)
+	}
+}
+
 func TestFetchBranch(t *testing.T) {
 	// Create a "remote" repo
 	remoteDir := t.TempDir()
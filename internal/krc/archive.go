@@ -0,0 +1,292 @@
+package krc
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// Cold archive layout: pruned events are appended to one gzip segment per
+// UTC day, <town>/archive/events/events-YYYY-MM-DD.jsonl.gz. Each prune
+// appends a new gzip member, so segments never need rewriting; readers see
+// the members as one stream.
+const (
+	segmentPrefix = "events-"
+	segmentSuffix = ".jsonl.gz"
+	segmentLayout = "2006-01-02"
+)
+
+// ArchiveDir returns the directory holding archived event segments.
+func ArchiveDir(townRoot string) string {
+	return filepath.Join(townRoot, "archive", "events")
+}
+
+// ArchiveSegment describes one day's archive file.
+type ArchiveSegment struct {
+	Path string    `json:"path"`
+	Day  time.Time `json:"day"`
+	Size int64     `json:"size"`
+}
+
+// ListArchive returns the archive segments, oldest first.
+func ListArchive(townRoot string) ([]ArchiveSegment, error) {
+	dir := ArchiveDir(townRoot)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading archive: %w", err)
+	}
+
+	var segments []ArchiveSegment
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, segmentPrefix) || !strings.HasSuffix(name, segmentSuffix) {
+			continue
+		}
+		day, err := time.Parse(segmentLayout, strings.TrimSuffix(strings.TrimPrefix(name, segmentPrefix), segmentSuffix))
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		segments = append(segments, ArchiveSegment{Path: filepath.Join(dir, name), Day: day, Size: info.Size()})
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i].Day.Before(segments[j].Day) })
+	return segments, nil
+}
+
+// ExpireArchive deletes segments whose whole day is older than retention
+// and returns how many were removed.
+func ExpireArchive(townRoot string, retention time.Duration, now time.Time) (int, error) {
+	segments, err := ListArchive(townRoot)
+	if err != nil {
+		return 0, err
+	}
+	cutoff := now.Add(-retention)
+	removed := 0
+	for _, seg := range segments {
+		if !seg.Day.Add(24 * time.Hour).Before(cutoff) {
+			break
+		}
+		if err := os.Remove(seg.Path); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("expiring %s: %w", filepath.Base(seg.Path), err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// archiveWriter collects pruned lines by day and appends them to their
+// segments on flush.
+type archiveWriter struct {
+	dir   string
+	byDay map[string][]string
+}
+
+func newArchiveWriter(townRoot string) *archiveWriter {
+	return &archiveWriter{dir: ArchiveDir(townRoot), byDay: make(map[string][]string)}
+}
+
+func (a *archiveWriter) add(ts time.Time, line string) {
+	day := ts.UTC().Format(segmentLayout)
+	a.byDay[day] = append(a.byDay[day], line)
+}
+
+// flush appends the collected lines as one gzip member per segment and
+// returns how many lines were written.
+func (a *archiveWriter) flush() (int, error) {
+	if len(a.byDay) == 0 {
+		return 0, nil
+	}
+	if err := os.MkdirAll(a.dir, 0755); err != nil {
+		return 0, err
+	}
+	days := make([]string, 0, len(a.byDay))
+	for day := range a.byDay {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+
+	written := 0
+	for _, day := range days {
+		lines := a.byDay[day]
+		if err := appendSegment(filepath.Join(a.dir, segmentPrefix+day+segmentSuffix), lines); err != nil {
+			return written, err
+		}
+		written += len(lines)
+		delete(a.byDay, day)
+	}
+	return written, nil
+}
+
+func appendSegment(path string, lines []string) (err error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644) //nolint:gosec // G302: archive is non-sensitive
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := f.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+
+	zw := gzip.NewWriter(f)
+	for _, line := range lines {
+		if _, err := zw.Write([]byte(line + "\n")); err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// QueryOptions filters events for Query. Empty fields match everything.
+type QueryOptions struct {
+	// Type and Actor match the event's type and actor; * globs are allowed.
+	Type  string
+	Actor string
+
+	// Since and Until bound the event timestamp (inclusive).
+	Since time.Time
+	Until time.Time
+
+	// Limit keeps only the most recent N matches (0 = no limit).
+	Limit int
+}
+
+// Record is an event found by Query.
+type Record struct {
+	events.Event
+
+	// Segment is "live" for .events.jsonl, or the archive segment's file name.
+	Segment string `json:"segment"`
+}
+
+// liveSegment names the live events file in Query results.
+const liveSegment = "live"
+
+// Query searches the archive segments that can overlap opts' time range and
+// the live events file, returning matches in timestamp order. Events that
+// appear in both (an interrupted prune) are reported once.
+func Query(townRoot string, opts QueryOptions) ([]Record, error) {
+	segments, err := ListArchive(townRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []Record
+	seen := make(map[string]bool)
+	collect := func(r io.Reader, segment string) error {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+		for scanner.Scan() {
+			var event events.Event
+			if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+				continue
+			}
+			if !opts.matches(&event) {
+				continue
+			}
+			if event.ID != "" {
+				if seen[event.ID] {
+					continue
+				}
+				seen[event.ID] = true
+			}
+			records = append(records, Record{Event: event, Segment: segment})
+		}
+		return scanner.Err()
+	}
+
+	for _, seg := range segments {
+		if !opts.Since.IsZero() && seg.Day.Add(24*time.Hour).Before(opts.Since) {
+			continue
+		}
+		if !opts.Until.IsZero() && seg.Day.After(opts.Until) {
+			continue
+		}
+		if err := readSegment(seg.Path, collect); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", filepath.Base(seg.Path), err)
+		}
+	}
+
+	live, err := os.Open(filepath.Join(townRoot, events.EventsFile))
+	if err == nil {
+		err = collect(live, liveSegment)
+		live.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading events: %w", err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Time().Before(records[j].Time())
+	})
+	if opts.Limit > 0 && len(records) > opts.Limit {
+		records = records[len(records)-opts.Limit:]
+	}
+	return records, nil
+}
+
+// readSegment streams a segment's lines to collect. A segment truncated by
+// an interrupted append yields the lines before the damage.
+func readSegment(path string, collect func(io.Reader, string) error) error {
+	f, err := os.Open(path) //nolint:gosec // G304: path is from ListArchive
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	err = collect(zr, filepath.Base(path))
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil
+	}
+	return err
+}
+
+func (o QueryOptions) matches(e *events.Event) bool {
+	if o.Type != "" && !matchGlob(o.Type, e.Type) {
+		return false
+	}
+	if o.Actor != "" && !matchGlob(o.Actor, e.Actor) {
+		return false
+	}
+	if o.Since.IsZero() && o.Until.IsZero() {
+		return true
+	}
+	ts := e.Time()
+	if ts.IsZero() {
+		return false
+	}
+	if !o.Since.IsZero() && ts.Before(o.Since) {
+		return false
+	}
+	if !o.Until.IsZero() && ts.After(o.Until) {
+		return false
+	}
+	return true
+}
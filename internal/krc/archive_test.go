@@ -0,0 +1,184 @@
+package krc
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeEvents(t *testing.T, path string, evs ...map[string]interface{}) {
+	t.Helper()
+	var b strings.Builder
+	for _, e := range evs {
+		data, err := json.Marshal(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.Write(data)
+		b.WriteString("\n")
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func event(id string, ts time.Time, typ, actor string) map[string]interface{} {
+	return map[string]interface{}{"id": id, "ts": ts.Format(time.RFC3339), "type": typ, "actor": actor}
+}
+
+func recordIDs(records []Record) string {
+	var ids []string
+	for _, r := range records {
+		ids = append(ids, r.ID+"@"+r.Segment)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestPrune_Archives(t *testing.T) {
+	townRoot := t.TempDir()
+	now := time.Now().UTC()
+	// Early in the UTC day, so events minutes apart share a segment.
+	day1 := now.Add(-40 * 24 * time.Hour).Truncate(24 * time.Hour).Add(time.Hour)
+	day2 := now.Add(-20 * 24 * time.Hour).Truncate(24 * time.Hour).Add(time.Hour)
+	writeEvents(t, filepath.Join(townRoot, ".events.jsonl"),
+		event("e1", day1, "sling", "mayor"),
+		event("e2", day1.Add(time.Minute), "session_death", "gastown/polecats/nux"),
+		event("e3", day2, "sling", "gastown/witness"),
+		event("e4", now.Add(-time.Hour), "sling", "mayor"),
+	)
+	writeEvents(t, filepath.Join(townRoot, ".feed.jsonl"), event("f1", day1, "sling", "mayor"))
+
+	config := DefaultConfig()
+	config.TTLs["session_death"] = 24 * time.Hour
+	result, err := NewPruner(townRoot, config).Prune()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.EventsPruned != 4 || result.EventsArchived != 3 {
+		t.Errorf("pruned %d, archived %d; want 4 (incl. feed), 3", result.EventsPruned, result.EventsArchived)
+	}
+
+	segments, err := ListArchive(townRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(segments) != 2 || !strings.HasSuffix(segments[0].Path, "events-"+day1.Format("2006-01-02")+".jsonl.gz") {
+		t.Fatalf("segments = %+v", segments)
+	}
+
+	// A second prune appends a new gzip member to an existing segment.
+	writeEvents(t, filepath.Join(townRoot, ".events.jsonl"),
+		event("e5", day1.Add(time.Hour), "sling", "mayor"),
+		event("e4", now.Add(-time.Hour), "sling", "mayor"),
+	)
+	if _, err := NewPruner(townRoot, config).Prune(); err != nil {
+		t.Fatal(err)
+	}
+
+	seg1 := "@events-" + day1.Format("2006-01-02") + ".jsonl.gz"
+	seg2 := "@events-" + day2.Format("2006-01-02") + ".jsonl.gz"
+	tests := []struct {
+		name string
+		opts QueryOptions
+		want string
+	}{
+		{"all", QueryOptions{}, "e1" + seg1 + ",e2" + seg1 + ",e5" + seg1 + ",e3" + seg2 + ",e4@live"},
+		{"type", QueryOptions{Type: "session_*"}, "e2" + seg1},
+		{"actor", QueryOptions{Type: "sling", Actor: "mayor"}, "e1" + seg1 + ",e5" + seg1 + ",e4@live"},
+		{"range", QueryOptions{Since: day2.Add(-time.Hour), Until: now.Add(-2 * time.Hour)}, "e3" + seg2},
+		{"limit", QueryOptions{Limit: 2}, "e3" + seg2 + ",e4@live"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := Query(townRoot, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := recordIDs(records); got != tt.want {
+				t.Errorf("Query = %s\nwant      %s", got, tt.want)
+			}
+		})
+	}
+
+	// Retention deletes whole days past the cutoff.
+	expired, err := ExpireArchive(townRoot, 30*24*time.Hour, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if segments, _ := ListArchive(townRoot); expired != 1 || len(segments) != 1 {
+		t.Errorf("expired %d, %d segments left; want 1, 1", expired, len(segments))
+	}
+}
+
+func TestPrune_ArchiveDisabled(t *testing.T) {
+	townRoot := t.TempDir()
+	writeEvents(t, filepath.Join(townRoot, ".events.jsonl"),
+		event("e1", time.Now().Add(-30*24*time.Hour), "sling", "mayor"))
+
+	config := DefaultConfig()
+	config.ArchiveRetention = 0
+	result, err := NewPruner(townRoot, config).Prune()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.EventsPruned != 1 || result.EventsArchived != 0 {
+		t.Errorf("result = %+v", result)
+	}
+	if _, err := os.Stat(ArchiveDir(townRoot)); !os.IsNotExist(err) {
+		t.Errorf("archive dir should not exist: %v", err)
+	}
+}
+
+func TestPrune_ArchiveFailureKeepsEvents(t *testing.T) {
+	townRoot := t.TempDir()
+	eventsPath := filepath.Join(townRoot, ".events.jsonl")
+	writeEvents(t, eventsPath, event("e1", time.Now().Add(-30*24*time.Hour), "sling", "mayor"))
+	// A file where the archive directory should be makes the archive fail.
+	if err := os.WriteFile(filepath.Join(townRoot, "archive"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewPruner(townRoot, DefaultConfig()).Prune(); err == nil {
+		t.Fatal("Prune should fail when the archive cannot be written")
+	}
+	data, _ := os.ReadFile(eventsPath)
+	if !strings.Contains(string(data), `"e1"`) {
+		t.Error("events must be kept when archiving fails")
+	}
+	if _, err := os.Stat(eventsPath + ".tmp"); !os.IsNotExist(err) {
+		t.Error("temp file should be cleaned up")
+	}
+}
+
+func TestQuery_TruncatedSegment(t *testing.T) {
+	townRoot := t.TempDir()
+	day := time.Now().Add(-10 * 24 * time.Hour)
+	w := newArchiveWriter(townRoot)
+	line, _ := json.Marshal(event("e1", day, "sling", "mayor"))
+	w.add(day, string(line))
+	if _, err := w.flush(); err != nil {
+		t.Fatal(err)
+	}
+	line, _ = json.Marshal(event("e2", day, "sling", "mayor"))
+	w.add(day, string(line))
+	if _, err := w.flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	segments, _ := ListArchive(townRoot)
+	data, _ := os.ReadFile(segments[0].Path)
+	if err := os.WriteFile(segments[0].Path, data[:len(data)-10], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := Query(townRoot, QueryOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) == 0 || records[0].ID != "e1" {
+		t.Errorf("records = %s, want e1 recovered", recordIDs(records))
+	}
+}
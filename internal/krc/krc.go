@@ -11,6 +11,7 @@
 // - Configurable TTLs per event type (default: 7 days)
 // - Auto-pruning on daemon startup and periodic intervals
 // - Stats and visibility into ephemeral data lifecycle
+// - A compressed cold archive of pruned events, searchable with Query
 package krc
 
 import (
@@ -45,6 +46,12 @@ type Config struct {
 	// MinRetainCount keeps at least N events even if expired (for debugging).
 	// Default: 100
 	MinRetainCount int `json:"min_retain_count"`
+
+	// ArchiveRetention is how long pruned events are kept in the cold
+	// archive (see ArchiveDir). Zero disables archiving, so pruned events
+	// are dropped.
+	// Default: 180 days
+	ArchiveRetention time.Duration `json:"archive_retention"`
 }
 
 // DefaultConfig returns the default KRC configuration.
@@ -53,6 +60,7 @@ func DefaultConfig() *Config {
 		DefaultTTL:    7 * 24 * time.Hour, // 7 days
 		PruneInterval: 1 * time.Hour,
 		MinRetainCount: 100,
+		ArchiveRetention: 180 * 24 * time.Hour, // 180 days
 		TTLs: map[string]time.Duration{
 			// Patrol events decay fastest - low forensic value after hours
 			"patrol_*":       24 * time.Hour,  // 1 day
@@ -109,6 +117,32 @@ func LoadConfig(townRoot string) (*Config, error) {
 	return config, nil
 }
 
+// MinPruneInterval is the shortest allowed auto-prune interval.
+const MinPruneInterval = time.Minute
+
+// Validate rejects configs the pruner can't run with: non-positive or
+// too-short intervals and negative TTLs or retain counts.
+func (c *Config) Validate() error {
+	if c.PruneInterval < MinPruneInterval {
+		return fmt.Errorf("prune_interval %v is below the %v minimum", c.PruneInterval, MinPruneInterval)
+	}
+	if c.DefaultTTL <= 0 {
+		return fmt.Errorf("default_ttl must be positive")
+	}
+	if c.MinRetainCount < 0 {
+		return fmt.Errorf("min_retain_count must not be negative")
+	}
+	if c.ArchiveRetention < 0 {
+		return fmt.Errorf("archive_retention must not be negative")
+	}
+	for pattern, ttl := range c.TTLs {
+		if ttl < 0 {
+			return fmt.Errorf("ttl for %q must not be negative", pattern)
+		}
+	}
+	return nil
+}
+
 // SaveConfig writes the KRC configuration to the town root.
 func SaveConfig(townRoot string, config *Config) error {
 	configPath := ConfigFile(townRoot)
@@ -171,6 +205,11 @@ type PruneResult struct {
 	BytesAfter      int64          `json:"bytes_after"`
 	PrunedByType    map[string]int `json:"pruned_by_type"`
 	Duration        time.Duration  `json:"duration"`
+
+	// EventsArchived counts pruned events moved to the cold archive, and
+	// SegmentsExpired the archive segments deleted past ArchiveRetention.
+	EventsArchived  int `json:"events_archived,omitempty"`
+	SegmentsExpired int `json:"segments_expired,omitempty"`
 }
 
 // Pruner handles the pruning of expired events.
@@ -189,14 +228,22 @@ func NewPruner(townRoot string, config *Config) *Pruner {
 
 // Prune removes expired events from the events and feed files.
 // It operates atomically by writing to temp files then renaming.
+// Unless ArchiveRetention is zero, events pruned from the events file are
+// first appended to the cold archive, and segments past retention are
+// deleted. The feed is derived from events, so its pruned lines are dropped.
 func (p *Pruner) Prune() (*PruneResult, error) {
 	start := time.Now()
 	result := &PruneResult{
 		PrunedByType: make(map[string]int),
 	}
 
+	var archive *archiveWriter
+	if p.config.ArchiveRetention > 0 {
+		archive = newArchiveWriter(p.townRoot)
+	}
+
 	// Prune events file
-	eventsResult, err := p.pruneFile(filepath.Join(p.townRoot, events.EventsFile))
+	eventsResult, err := p.pruneFile(filepath.Join(p.townRoot, events.EventsFile), archive)
 	if err != nil {
 		return nil, fmt.Errorf("pruning events: %w", err)
 	}
@@ -205,12 +252,13 @@ func (p *Pruner) Prune() (*PruneResult, error) {
 	result.EventsRetained += eventsResult.EventsRetained
 	result.BytesBefore += eventsResult.BytesBefore
 	result.BytesAfter += eventsResult.BytesAfter
+	result.EventsArchived += eventsResult.EventsArchived
 	for k, v := range eventsResult.PrunedByType {
 		result.PrunedByType[k] += v
 	}
 
 	// Prune feed file
-	feedResult, err := p.pruneFile(filepath.Join(p.townRoot, ".feed.jsonl"))
+	feedResult, err := p.pruneFile(filepath.Join(p.townRoot, ".feed.jsonl"), nil)
 	if err != nil {
 		return nil, fmt.Errorf("pruning feed: %w", err)
 	}
@@ -223,12 +271,22 @@ func (p *Pruner) Prune() (*PruneResult, error) {
 		result.PrunedByType[k] += v
 	}
 
+	if p.config.ArchiveRetention > 0 {
+		expired, err := ExpireArchive(p.townRoot, p.config.ArchiveRetention, start)
+		if err != nil {
+			return nil, fmt.Errorf("expiring archive: %w", err)
+		}
+		result.SegmentsExpired = expired
+	}
+
 	result.Duration = time.Since(start)
 	return result, nil
 }
 
-// pruneFile prunes a single JSONL file.
-func (p *Pruner) pruneFile(filePath string) (result *PruneResult, err error) {
+// pruneFile prunes a single JSONL file, passing pruned lines to archive
+// when it is non-nil. The archive is written before the file is replaced,
+// so a failed archive write leaves the file untouched.
+func (p *Pruner) pruneFile(filePath string, archive *archiveWriter) (result *PruneResult, err error) {
 	result = &PruneResult{
 		PrunedByType: make(map[string]int),
 	}
@@ -317,6 +375,9 @@ func (p *Pruner) pruneFile(filePath string) (result *PruneResult, err error) {
 		if now.Sub(ts) > ttl {
 			result.EventsPruned++
 			result.PrunedByType[event.Type]++
+			if archive != nil {
+				archive.add(ts, line)
+			}
 		} else {
 			retained = append(retained, line)
 		}
@@ -360,6 +421,12 @@ func (p *Pruner) pruneFile(filePath string) (result *PruneResult, err error) {
 	}
 	srcClosed = true
 
+	if archive != nil {
+		if result.EventsArchived, err = archive.flush(); err != nil {
+			return nil, fmt.Errorf("archiving pruned events: %w", err)
+		}
+	}
+
 	// Atomic replace
 	if err := os.Rename(tmpPath, filePath); err != nil {
 		return nil, fmt.Errorf("replacing file: %w", err)
@@ -110,6 +110,32 @@ func TestLoadConfig_Default(t *testing.T) {
 	}
 }
 
+func TestConfig_Validate(t *testing.T) {
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Fatalf("default config invalid: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{"short prune interval", func(c *Config) { c.PruneInterval = time.Second }},
+		{"zero default ttl", func(c *Config) { c.DefaultTTL = 0 }},
+		{"negative retain", func(c *Config) { c.MinRetainCount = -1 }},
+		{"negative ttl", func(c *Config) { c.TTLs["mail"] = -time.Hour }},
+		{"negative archive retention", func(c *Config) { c.ArchiveRetention = -time.Hour }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := DefaultConfig()
+			tt.modify(c)
+			if err := c.Validate(); err == nil {
+				t.Error("Validate() = nil, want error")
+			}
+		})
+	}
+}
+
 func TestSaveAndLoadConfig(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "krc-test-*")
 	if err != nil {
@@ -0,0 +1,352 @@
+package ledger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+// Record is an exported unit of completed work. Records hold only content
+// derived from the work itself, so re-exporting unchanged work yields the
+// same bytes and hash; when and why it was exported live on the Entry.
+type Record struct {
+	Kind    string `json:"kind"`
+	Subject string `json:"subject"` // bead, convoy or MR ID
+	Level   int    `json:"level"`
+	Reason  string `json:"level_reason,omitempty"` // why a record is Level 3
+
+	Rig     string  `json:"rig,omitempty"`
+	Agent   string  `json:"agent,omitempty"`
+	CostUSD float64 `json:"cost_usd,omitempty"`
+
+	Completion *Completion    `json:"completion,omitempty"`
+	Convoy     *ConvoySummary `json:"convoy,omitempty"`
+	Merge      *MergeSummary  `json:"merge,omitempty"`
+}
+
+// Completion summarizes a closed bead.
+type Completion struct {
+	Type         string   `json:"type"`
+	Title        string   `json:"title"`
+	Outcome      string   `json:"outcome,omitempty"`
+	Priority     int      `json:"priority"`
+	Owner        string   `json:"owner,omitempty"`
+	Labels       []string `json:"labels,omitempty"`
+	Parent       string   `json:"parent,omitempty"`
+	CreatedAt    string   `json:"created_at"`
+	ClosedAt     string   `json:"closed_at"`
+	DurationDays float64  `json:"duration_days"`
+	Commits      []string `json:"commits,omitempty"`
+}
+
+// ConvoySummary summarizes a closed convoy.
+type ConvoySummary struct {
+	Title        string   `json:"title"`
+	Beads        []string `json:"beads"`
+	Agents       []string `json:"agents,omitempty"`
+	Rigs         []string `json:"rigs,omitempty"`
+	CreatedAt    string   `json:"created_at"`
+	CompletedAt  string   `json:"completed_at"`
+	DurationDays float64  `json:"duration_days"`
+}
+
+// MergeSummary records a merge request that landed.
+type MergeSummary struct {
+	Bead        string `json:"bead,omitempty"`
+	Branch      string `json:"branch,omitempty"`
+	Target      string `json:"target,omitempty"`
+	Worker      string `json:"worker,omitempty"`
+	MergeCommit string `json:"merge_commit,omitempty"`
+	MergedBy    string `json:"merged_by,omitempty"`
+	Result      string `json:"result"`
+}
+
+// encode returns the record's canonical bytes and their hash.
+func (r *Record) encode() ([]byte, string, error) {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return nil, "", err
+	}
+	data = append(data, '\n')
+	return data, hashBytes(data), nil
+}
+
+// Source supplies the operational data an export reads.
+type Source interface {
+	// Show returns a bead by ID from whichever database holds it.
+	Show(id string) (*beads.Issue, error)
+
+	// Tracked returns the beads a convoy tracks.
+	Tracked(convoyID string) ([]*beads.Issue, error)
+
+	// Rig returns the rig a bead belongs to ("" for town beads).
+	Rig(beadID string) string
+
+	// Commits returns commits that reference a bead.
+	Commits(beadID, rig string) []string
+
+	// Cost returns the recorded session cost of work on a bead.
+	Cost(beadID string) float64
+}
+
+// ErrNotClosed is returned when asked to export work that is still open.
+var ErrNotClosed = errors.New("bead is not closed")
+
+// completionTypes are the bead types exported as completions.
+var completionTypes = []string{"task", "bug", "feature", "chore", "epic", "design", "decision"}
+
+// mergeRequestLabel marks merge-request beads.
+const mergeRequestLabel = "gt:merge-request"
+
+// mergedAtRe extracts the commit from a "Merged to main at <sha>" reason.
+var mergedAtRe = regexp.MustCompile(`\bat ([0-9a-f]{7,40})\b`)
+
+// level3Labels flag a completion as full-fidelity ground truth (design
+// Triggers 5, 6 and 8).
+var level3Labels = []string{"design-decision", "architecture", "rfc", "investigation", "debugging", "ledger-full"}
+
+// level returns a completion's fidelity level and, for Level 3, why.
+func level(issue *beads.Issue) (int, string) {
+	if issue.Type == "design" {
+		return 3, "type:design"
+	}
+	for _, l := range level3Labels {
+		if beads.HasLabel(issue, l) {
+			return 3, "label:" + l
+		}
+	}
+	return 2, ""
+}
+
+// Exporter turns closed beads into ledger records.
+type Exporter struct {
+	Store  *Store
+	Source Source
+
+	// Now stamps entries; defaults to time.Now.
+	Now func() time.Time
+}
+
+// Result describes one record considered by an export.
+type Result struct {
+	Entry Entry
+	Added bool
+}
+
+// Export writes the ledger records for a closed bead: a completion for
+// work beads, a convoy summary for convoys, and for a merged MR both the
+// merge record and its source bead's completion. Beads that aren't
+// completed work (agents, messages, wisps, rejected MRs) yield nothing.
+func (x *Exporter) Export(id, trigger string) ([]Result, error) {
+	issue, err := x.Source.Show(id)
+	if err != nil {
+		return nil, fmt.Errorf("loading %s: %w", id, err)
+	}
+	return x.ExportIssue(issue, trigger)
+}
+
+// ExportIssue is Export for a bead that has already been loaded.
+func (x *Exporter) ExportIssue(issue *beads.Issue, trigger string) ([]Result, error) {
+	if issue.Status != "closed" {
+		return nil, fmt.Errorf("%s: %w", issue.ID, ErrNotClosed)
+	}
+	if issue.Ephemeral {
+		return nil, nil
+	}
+
+	switch {
+	case issue.Type == "convoy":
+		rec, tracked, err := x.convoyRecord(issue)
+		if err != nil {
+			return nil, err
+		}
+		results, err := x.append(nil, rec, trigger)
+		if err != nil {
+			return results, err
+		}
+		// A landing convoy also flushes completions its beads' own close
+		// triggers missed.
+		for _, t := range tracked {
+			if t.Status != "closed" || t.Ephemeral || !slices.Contains(completionTypes, t.Type) {
+				continue
+			}
+			if results, err = x.append(results, x.completionRecord(t), trigger); err != nil {
+				return results, err
+			}
+		}
+		return results, nil
+
+	case issue.Type == "merge-request" || beads.HasLabel(issue, mergeRequestLabel):
+		rec := x.mergeRecord(issue)
+		if rec == nil {
+			return nil, nil
+		}
+		// Closing a merged MR is the refinery's merge.
+		if trigger == TriggerClose {
+			trigger = TriggerMerge
+		}
+		results, err := x.append(nil, rec, trigger)
+		if err != nil || rec.Merge.Bead == "" {
+			return results, err
+		}
+		// The merge is often the first trigger to see the source bead
+		// closed, so record its completion alongside.
+		source, err := x.Source.Show(rec.Merge.Bead)
+		if err != nil || source.Status != "closed" || !slices.Contains(completionTypes, source.Type) {
+			return results, nil //nolint:nilerr // Source completion is exported by its own trigger
+		}
+		return x.append(results, x.completionRecord(source), trigger)
+
+	case slices.Contains(completionTypes, issue.Type):
+		return x.append(nil, x.completionRecord(issue), trigger)
+	}
+	return nil, nil
+}
+
+func (x *Exporter) append(results []Result, rec *Record, trigger string) ([]Result, error) {
+	now := time.Now
+	if x.Now != nil {
+		now = x.Now
+	}
+	entry, added, err := x.Store.Append(rec, trigger, now())
+	if err != nil {
+		return results, fmt.Errorf("exporting %s: %w", rec.Subject, err)
+	}
+	return append(results, Result{Entry: entry, Added: added}), nil
+}
+
+func (x *Exporter) completionRecord(issue *beads.Issue) *Record {
+	rig := x.Source.Rig(issue.ID)
+	labels := append([]string(nil), issue.Labels...)
+	sort.Strings(labels)
+	lvl, reason := level(issue)
+	return &Record{
+		Kind:    KindCompletion,
+		Subject: issue.ID,
+		Level:   lvl,
+		Reason:  reason,
+		Rig:     rig,
+		Agent:   issue.Assignee,
+		CostUSD: x.Source.Cost(issue.ID),
+		Completion: &Completion{
+			Type:         issue.Type,
+			Title:        issue.Title,
+			Outcome:      issue.Description,
+			Priority:     issue.Priority,
+			Owner:        issue.CreatedBy,
+			Labels:       labels,
+			Parent:       issue.Parent,
+			CreatedAt:    issue.CreatedAt,
+			ClosedAt:     issue.ClosedAt,
+			DurationDays: durationDays(issue.CreatedAt, issue.ClosedAt),
+			Commits:      x.Source.Commits(issue.ID, rig),
+		},
+	}
+}
+
+// mergeRecord returns the merge record for an MR bead, or nil if the MR
+// was closed without merging.
+func (x *Exporter) mergeRecord(issue *beads.Issue) *Record {
+	fields := beads.ParseMRFields(issue)
+	if fields == nil {
+		fields = &beads.MRFields{}
+	}
+	// The refinery formula closes merged MRs with a "Merged to main at
+	// <sha>" reason rather than setting the close_reason field.
+	switch {
+	case fields.CloseReason == "merged":
+	case fields.CloseReason == "" && fields.MergeCommit != "":
+	case fields.CloseReason == "" && strings.HasPrefix(strings.ToLower(issue.CloseReason), "merged"):
+		if fields.MergeCommit == "" {
+			if m := mergedAtRe.FindStringSubmatch(issue.CloseReason); m != nil {
+				fields.MergeCommit = m[1]
+			}
+		}
+	default:
+		return nil
+	}
+	rig := fields.Rig
+	if rig == "" {
+		rig = x.Source.Rig(issue.ID)
+	}
+	mergedBy := ""
+	if rig != "" {
+		mergedBy = rig + "/refinery"
+	}
+	return &Record{
+		Kind:    KindMerge,
+		Subject: issue.ID,
+		Level:   2,
+		Rig:     rig,
+		Agent:   fields.Worker,
+		Merge: &MergeSummary{
+			Bead:        fields.SourceIssue,
+			Branch:      fields.Branch,
+			Target:      fields.Target,
+			Worker:      fields.Worker,
+			MergeCommit: fields.MergeCommit,
+			MergedBy:    mergedBy,
+			Result:      "merged",
+		},
+	}
+}
+
+func (x *Exporter) convoyRecord(issue *beads.Issue) (*Record, []*beads.Issue, error) {
+	tracked, err := x.Source.Tracked(issue.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing beads tracked by %s: %w", issue.ID, err)
+	}
+
+	summary := &ConvoySummary{
+		Title:       issue.Title,
+		Beads:       []string{},
+		CreatedAt:   issue.CreatedAt,
+		CompletedAt: issue.ClosedAt,
+	}
+	for _, t := range tracked {
+		summary.Beads = append(summary.Beads, t.ID)
+		if t.Assignee != "" && !slices.Contains(summary.Agents, t.Assignee) {
+			summary.Agents = append(summary.Agents, t.Assignee)
+		}
+		if rig := x.Source.Rig(t.ID); rig != "" && !slices.Contains(summary.Rigs, rig) {
+			summary.Rigs = append(summary.Rigs, rig)
+		}
+		// Completion is when the last tracked bead closed, if that's known.
+		if t.ClosedAt > summary.CompletedAt && issue.ClosedAt == "" {
+			summary.CompletedAt = t.ClosedAt
+		}
+	}
+	sort.Strings(summary.Beads)
+	sort.Strings(summary.Agents)
+	sort.Strings(summary.Rigs)
+	summary.DurationDays = durationDays(summary.CreatedAt, summary.CompletedAt)
+
+	return &Record{Kind: KindConvoy, Subject: issue.ID, Level: 2, Convoy: summary}, tracked, nil
+}
+
+// durationDays is closed-created in days, rounded to 0.01.
+func durationDays(created, closed string) float64 {
+	start, ok1 := parseTime(created)
+	end, ok2 := parseTime(closed)
+	if !ok1 || !ok2 || end.Before(start) {
+		return 0
+	}
+	days := end.Sub(start).Hours() / 24
+	return float64(int64(days*100+0.5)) / 100
+}
+
+func parseTime(s string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, strings.TrimSpace(s)); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
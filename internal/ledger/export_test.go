@@ -0,0 +1,178 @@
+package ledger
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+type fakeSource struct {
+	issues  map[string]*beads.Issue
+	tracked map[string][]*beads.Issue
+}
+
+func (f *fakeSource) Show(id string) (*beads.Issue, error) {
+	if issue, ok := f.issues[id]; ok {
+		return issue, nil
+	}
+	return nil, fmt.Errorf("%s not found", id)
+}
+
+func (f *fakeSource) Tracked(convoyID string) ([]*beads.Issue, error) {
+	return f.tracked[convoyID], nil
+}
+
+func (f *fakeSource) Rig(beadID string) string {
+	if len(beadID) > 3 && beadID[:3] == "gt-" {
+		return "gastown"
+	}
+	return ""
+}
+
+func (f *fakeSource) Commits(beadID, rig string) []string {
+	if beadID == "gt-1" {
+		return []string{"abc123"}
+	}
+	return nil
+}
+
+func (f *fakeSource) Cost(beadID string) float64 {
+	if beadID == "gt-1" {
+		return 1.25
+	}
+	return 0
+}
+
+func newExporter(t *testing.T, src *fakeSource) *Exporter {
+	t.Helper()
+	return &Exporter{
+		Store:  Open(t.TempDir()),
+		Source: src,
+		Now:    func() time.Time { return time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC) },
+	}
+}
+
+func TestExport_Completion(t *testing.T) {
+	src := &fakeSource{issues: map[string]*beads.Issue{
+		"gt-1": {
+			ID: "gt-1", Title: "Fix login", Description: "Session cookie now refreshed",
+			Status: "closed", Type: "bug", Priority: 1, Assignee: "gastown/polecats/nux",
+			Labels: []string{"zeta", "auth"}, CreatedAt: "2026-10-16T12:00:00Z", ClosedAt: "2026-10-18T00:00:00Z",
+		},
+		"gt-2":  {ID: "gt-2", Status: "open", Type: "task"},
+		"gt-3":  {ID: "gt-3", Status: "closed", Type: "task", Labels: []string{"ledger-full"}},
+		"gt-w":  {ID: "gt-w", Status: "closed", Type: "task", Ephemeral: true},
+		"gt-ag": {ID: "gt-ag", Status: "closed", Type: "agent"},
+	}}
+	x := newExporter(t, src)
+
+	results, err := x.Export("gt-1", TriggerClose)
+	if err != nil || len(results) != 1 || !results[0].Added {
+		t.Fatalf("Export = %+v, %v", results, err)
+	}
+	rec, err := x.Store.Record(results[0].Entry.Record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := rec.Completion
+	if rec.Rig != "gastown" || rec.Agent != "gastown/polecats/nux" || rec.CostUSD != 1.25 {
+		t.Errorf("record = %+v", rec)
+	}
+	if rec.Level != 2 || c.DurationDays != 1.5 || c.Labels[0] != "auth" || len(c.Commits) != 1 || c.Outcome == "" {
+		t.Errorf("completion = %+v", c)
+	}
+
+	results, err = x.Export("gt-3", TriggerClose)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec, _ := x.Store.Record(results[0].Entry.Record); rec.Level != 3 || rec.Reason != "label:ledger-full" {
+		t.Errorf("flagged record = level %d (%s), want 3", rec.Level, rec.Reason)
+	}
+
+	if _, err := x.Export("gt-2", TriggerClose); err == nil {
+		t.Error("exporting an open bead should fail")
+	}
+	for _, id := range []string{"gt-w", "gt-ag"} {
+		if results, err := x.Export(id, TriggerClose); err != nil || len(results) != 0 {
+			t.Errorf("Export(%s) = %+v, %v; want nothing", id, results, err)
+		}
+	}
+}
+
+func TestExport_Merge(t *testing.T) {
+	src := &fakeSource{issues: map[string]*beads.Issue{
+		"gt-1": {ID: "gt-1", Title: "Fix login", Status: "closed", Type: "bug"},
+		"gt-mr1": {
+			ID: "gt-mr1", Status: "closed", Type: "merge-request", CloseReason: "Merged to main at 4f2a9c1",
+			Description: "branch: polecat/nux/gt-1\ntarget: main\nsource_issue: gt-1\nworker: nux\nrig: gastown",
+		},
+		"gt-mr3": {
+			ID: "gt-mr3", Status: "closed", Type: "merge-request", CloseReason: "Branch no longer exists",
+			Description: "branch: polecat/nux/gt-3\nsource_issue: gt-3",
+		},
+		"gt-mr2": {
+			ID: "gt-mr2", Status: "closed", Type: "merge-request",
+			Description: "branch: polecat/nux/gt-2\nsource_issue: gt-2\nclose_reason: rejected",
+		},
+	}}
+	x := newExporter(t, src)
+
+	results, err := x.Export("gt-mr1", TriggerMerge)
+	if err != nil || len(results) != 2 {
+		t.Fatalf("Export = %+v, %v", results, err)
+	}
+	if results[0].Entry.Kind != KindMerge || results[1].Entry.Kind != KindCompletion || results[1].Entry.Subject != "gt-1" {
+		t.Errorf("entries = %+v", results)
+	}
+	rec, err := x.Store.Record(results[0].Entry.Record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Merge.Bead != "gt-1" || rec.Merge.Branch != "polecat/nux/gt-1" || rec.Merge.MergedBy != "gastown/refinery" || rec.Merge.MergeCommit != "4f2a9c1" {
+		t.Errorf("merge = %+v", rec.Merge)
+	}
+
+	// The bead's own close trigger then finds its completion already recorded.
+	if results, err := x.Export("gt-1", TriggerClose); err != nil || results[0].Added {
+		t.Errorf("re-export = %+v, %v", results, err)
+	}
+
+	for _, id := range []string{"gt-mr2", "gt-mr3"} {
+		if results, err := x.Export(id, TriggerMerge); err != nil || len(results) != 0 {
+			t.Errorf("unmerged MR %s exported: %+v, %v", id, results, err)
+		}
+	}
+}
+
+func TestExport_Convoy(t *testing.T) {
+	src := &fakeSource{
+		issues: map[string]*beads.Issue{
+			"hq-cv-1": {ID: "hq-cv-1", Title: "Auth sweep", Status: "closed", Type: "convoy",
+				CreatedAt: "2026-10-15T00:00:00Z", ClosedAt: "2026-10-18T00:00:00Z"},
+		},
+		tracked: map[string][]*beads.Issue{
+			"hq-cv-1": {
+				{ID: "gt-2", Assignee: "gastown/polecats/toast"},
+				{ID: "gt-1", Assignee: "gastown/polecats/nux", Status: "closed", Type: "task"},
+				{ID: "bd-9", Assignee: "gastown/polecats/nux"},
+			},
+		},
+	}
+	x := newExporter(t, src)
+
+	results, err := x.Export("hq-cv-1", TriggerConvoy)
+	if err != nil || len(results) != 2 || results[1].Entry.Subject != "gt-1" {
+		t.Fatalf("Export = %+v, %v", results, err)
+	}
+	rec, err := x.Store.Record(results[0].Entry.Record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cv := rec.Convoy
+	if fmt.Sprint(cv.Beads) != "[bd-9 gt-1 gt-2]" || len(cv.Agents) != 2 || fmt.Sprint(cv.Rigs) != "[gastown]" || cv.DurationDays != 3 {
+		t.Errorf("convoy = %+v", cv)
+	}
+}
@@ -0,0 +1,174 @@
+package ledger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+// NewTownExporter returns an Exporter writing to a town's ledger from its
+// beads, rig repositories and cost log. Every export trigger uses it, so
+// the same work always exports to the same record.
+func NewTownExporter(townRoot string) *Exporter {
+	return &Exporter{
+		Store:  Open(townRoot),
+		Source: &TownSource{TownRoot: townRoot, Beads: beads.New(townRoot), CostsLog: CostsLogPath()},
+	}
+}
+
+// ExportClosed exports closed beads after trigger fired, reporting each
+// failure through warn. Beads that turn out not to be closed are skipped.
+// Export fails safe: it never fails the operation that closed the work,
+// and 'gt ledger export --all' retries anything missed.
+func ExportClosed(townRoot string, ids []string, trigger string, warn func(id string, err error)) {
+	if len(ids) == 0 || townRoot == "" {
+		return
+	}
+	x := NewTownExporter(townRoot)
+	for _, id := range ids {
+		if _, err := x.Export(id, trigger); err != nil && !errors.Is(err, ErrNotClosed) {
+			warn(id, err)
+		}
+	}
+}
+
+// CostsLogPath returns the session cost log that 'gt costs record'
+// appends to (~/.gt/costs.jsonl).
+func CostsLogPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "/tmp/gt-costs.jsonl" // Fallback
+	}
+	return filepath.Join(home, ".gt", "costs.jsonl")
+}
+
+// TownSource reads export data from a town's beads, git and cost log.
+type TownSource struct {
+	TownRoot string
+	Beads    *beads.Beads
+	CostsLog string
+
+	costsOnce sync.Once
+	costs     map[string]float64
+}
+
+// Show returns a bead from whichever database holds it.
+func (s *TownSource) Show(id string) (*beads.Issue, error) {
+	return s.Beads.Show(id)
+}
+
+// Tracked returns the beads a convoy tracks.
+func (s *TownSource) Tracked(convoyID string) ([]*beads.Issue, error) {
+	// A convoy whose dependencies can't be listed summarizes as tracking
+	// nothing, as 'gt convoy status' shows it.
+	var tracked []*beads.Issue
+	if out, err := s.Beads.Run("dep", "list", convoyID, "--direction=down", "--type=tracks", "--json"); err == nil {
+		_ = json.Unmarshal(out, &tracked)
+	}
+
+	ids := make([]string, 0, len(tracked))
+	for _, t := range tracked {
+		t.ID = unwrapExternalID(t.ID)
+		ids = append(ids, t.ID)
+	}
+	full, err := s.Beads.ShowMultiple(ids)
+	if err != nil {
+		full = nil
+	}
+
+	issues := make([]*beads.Issue, 0, len(tracked))
+	for _, t := range tracked {
+		if issue, ok := full[t.ID]; ok {
+			issues = append(issues, issue)
+			continue
+		}
+		// Beads in rigs bd can't reach from here still count toward the
+		// summary with what the dependency listing knows.
+		issues = append(issues, &beads.Issue{
+			ID: t.ID, Title: t.Title, Status: t.Status, Type: t.Type, Assignee: t.Assignee,
+		})
+	}
+	return issues, nil
+}
+
+// unwrapExternalID strips the external:prefix:id wrapper convoys use to
+// track beads in other rigs.
+func unwrapExternalID(id string) string {
+	if strings.HasPrefix(id, "external:") {
+		if parts := strings.SplitN(id, ":", 3); len(parts) == 3 {
+			return parts[2]
+		}
+	}
+	return id
+}
+
+// Rig returns the rig a bead belongs to ("" for town beads).
+func (s *TownSource) Rig(beadID string) string {
+	rigPath := beads.GetRigPathForPrefix(s.TownRoot, beads.ExtractPrefix(beadID))
+	rel, err := filepath.Rel(s.TownRoot, rigPath)
+	if rigPath == "" || err != nil || rel == "." {
+		return ""
+	}
+	return strings.Split(filepath.ToSlash(rel), "/")[0]
+}
+
+// Commits returns commits in the rig's repository that reference beadID.
+func (s *TownSource) Commits(beadID, rig string) []string {
+	if rig == "" {
+		return nil
+	}
+	repo := filepath.Join(s.TownRoot, rig, "mayor", "rig")
+	cmd := exec.Command("git", "log", "--all", "--fixed-strings", "--grep="+beadID,
+		"-n", "50", "--format=%H%x00%B%x1e")
+	cmd.Dir = repo
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return nil
+	}
+	return matchBeadCommits(stdout.String(), beadID)
+}
+
+// matchBeadCommits filters 'git log --grep' output down to commits that
+// reference beadID itself, not a longer ID it's a prefix of (gt-1 vs gt-12
+// or gt-1.2). Output is oldest first.
+func matchBeadCommits(log, beadID string) []string {
+	re := regexp.MustCompile(`(^|[^A-Za-z0-9_.-])` + regexp.QuoteMeta(beadID) + `([^A-Za-z0-9_.-]|\.([^0-9]|$)|$)`)
+	var commits []string
+	for _, rec := range strings.Split(log, "\x1e") {
+		hash, msg, ok := strings.Cut(strings.TrimSpace(rec), "\x00")
+		if ok && re.MatchString(msg) {
+			commits = append([]string{hash}, commits...)
+		}
+	}
+	return commits
+}
+
+// Cost returns the session cost recorded against beadID in the cost log.
+func (s *TownSource) Cost(beadID string) float64 {
+	s.costsOnce.Do(func() {
+		s.costs = make(map[string]float64)
+		data, err := os.ReadFile(s.CostsLog)
+		if err != nil {
+			return
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			var entry struct {
+				CostUSD  float64 `json:"cost_usd"`
+				WorkItem string  `json:"work_item"`
+			}
+			if json.Unmarshal([]byte(line), &entry) == nil && entry.WorkItem != "" {
+				s.costs[entry.WorkItem] += entry.CostUSD
+			}
+		}
+	})
+	return s.costs[beadID]
+}
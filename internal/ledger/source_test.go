@@ -0,0 +1,17 @@
+package ledger
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchBeadCommits(t *testing.T) {
+	log := "c3\x00Fix follow-up (gt-12)\n\x1e\n" +
+		"c2\x00Refactor parser\n\nPart of gt-1.2\n\x1e\n" +
+		"c1\x00Fix login redirect (gt-1)\n\x1e\n" +
+		"c0\x00gt-1. Initial cut\n\x1e\n"
+	got := matchBeadCommits(log, "gt-1")
+	if want := []string{"c0", "c1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("matchBeadCommits = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,306 @@
+// Package ledger records completed work as immutable, content-addressed
+// ledger records (the Level 2 plane in docs/design/ledger-export-triggers.md),
+// so the record of what was done survives compaction of the operational
+// beads.
+//
+// The store lives at <town>/ledger:
+//
+//	objects/<aa>/<sha256>.json   one record per file, named by content hash
+//	chain.jsonl                  append-only export log, hash-chained
+//
+// Each chain entry commits to its record's hash and to the previous entry's
+// hash, so editing a record, editing an entry, or removing an entry from the
+// middle of the chain is detected by Verify.
+package ledger
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+)
+
+// Record kinds.
+const (
+	KindCompletion = "completion"
+	KindConvoy     = "convoy"
+	KindMerge      = "merge"
+)
+
+// Export triggers, recorded on each chain entry.
+const (
+	TriggerClose  = "close"
+	TriggerConvoy = "convoy"
+	TriggerMerge  = "merge"
+	TriggerScan   = "scan"
+)
+
+// Dir returns the ledger directory for a town.
+func Dir(townRoot string) string {
+	return filepath.Join(townRoot, "ledger")
+}
+
+// Entry is one line of the chain.
+type Entry struct {
+	Seq        int       `json:"seq"`
+	Kind       string    `json:"kind"`
+	Subject    string    `json:"subject"`
+	Record     string    `json:"record"` // sha256 of the record object
+	Prev       string    `json:"prev"`   // previous entry's Hash ("" for the first)
+	Trigger    string    `json:"trigger"`
+	ExportedAt time.Time `json:"exported_at"`
+	Hash       string    `json:"hash"`
+}
+
+// computeHash returns the entry's hash over every other field.
+func (e Entry) computeHash() string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		fmt.Sprint(e.Seq), e.Kind, e.Subject, e.Record, e.Prev, e.Trigger,
+		e.ExportedAt.UTC().Format(time.RFC3339Nano),
+	}, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Store is a town's ledger.
+type Store struct {
+	dir string
+}
+
+// Open returns the ledger store for a town. Nothing is created until the
+// first Append.
+func Open(townRoot string) *Store {
+	return &Store{dir: Dir(townRoot)}
+}
+
+func (s *Store) chainPath() string { return filepath.Join(s.dir, "chain.jsonl") }
+
+func (s *Store) objectPath(hash string) string {
+	return filepath.Join(s.dir, "objects", hash[:2], hash+".json")
+}
+
+// Append records rec unless the subject's latest entry already holds the
+// same content, in which case that entry is returned with added=false.
+// Re-exporting unchanged work is therefore a no-op, while a bead that was
+// reopened and closed again gets a new (correction) entry.
+func (s *Store) Append(rec *Record, trigger string, at time.Time) (entry Entry, added bool, err error) {
+	data, hash, err := rec.encode()
+	if err != nil {
+		return Entry{}, false, err
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return Entry{}, false, fmt.Errorf("creating ledger: %w", err)
+	}
+
+	lock := flock.New(filepath.Join(s.dir, ".lock"))
+	if err := lock.Lock(); err != nil {
+		return Entry{}, false, fmt.Errorf("locking ledger: %w", err)
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	entries, err := s.Entries()
+	if err != nil {
+		return Entry{}, false, err
+	}
+	var prev Entry
+	if len(entries) > 0 {
+		prev = entries[len(entries)-1]
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Kind == rec.Kind && entries[i].Subject == rec.Subject {
+			if entries[i].Record == hash {
+				return entries[i], false, nil
+			}
+			break
+		}
+	}
+
+	if err := s.writeObject(hash, data); err != nil {
+		return Entry{}, false, err
+	}
+
+	entry = Entry{
+		Seq:        prev.Seq + 1,
+		Kind:       rec.Kind,
+		Subject:    rec.Subject,
+		Record:     hash,
+		Prev:       prev.Hash,
+		Trigger:    trigger,
+		ExportedAt: at.UTC(),
+	}
+	entry.Hash = entry.computeHash()
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return Entry{}, false, err
+	}
+
+	f, err := os.OpenFile(s.chainPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644) //nolint:gosec // G302: ledger is non-sensitive
+	if err != nil {
+		return Entry{}, false, err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return Entry{}, false, err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return Entry{}, false, err
+	}
+	return entry, true, f.Close()
+}
+
+// writeObject stores a record under its hash. Objects are immutable: an
+// existing object is left alone.
+func (s *Store) writeObject(hash string, data []byte) error {
+	path := s.objectPath(hash)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0444); err != nil { //nolint:gosec // G306: ledger is non-sensitive
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// Entries returns the chain in order. A missing chain is empty.
+func (s *Store) Entries() ([]Entry, error) {
+	f, err := os.Open(s.chainPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("chain line %d: %w", line, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// Record loads the record stored under hash and checks it still matches.
+func (s *Store) Record(hash string) (*Record, error) {
+	if len(hash) < 2 {
+		return nil, fmt.Errorf("invalid record hash %q", hash)
+	}
+	data, err := os.ReadFile(s.objectPath(hash))
+	if err != nil {
+		return nil, err
+	}
+	if got := hashBytes(data); got != hash {
+		return nil, fmt.Errorf("record %s: content hash is %s", shortHash(hash), shortHash(got))
+	}
+	var rec Record
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("record %s: %w", shortHash(hash), err)
+	}
+	return &rec, nil
+}
+
+// History returns every entry for a subject, oldest first.
+func (s *Store) History(subject string) ([]Entry, error) {
+	entries, err := s.Entries()
+	if err != nil {
+		return nil, err
+	}
+	var out []Entry
+	for _, e := range entries {
+		if e.Subject == subject {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// Problem is an integrity failure found by Verify.
+type Problem struct {
+	Seq     int    `json:"seq"`
+	Subject string `json:"subject,omitempty"`
+	Detail  string `json:"detail"`
+}
+
+func (p Problem) String() string {
+	if p.Subject == "" {
+		return fmt.Sprintf("#%d: %s", p.Seq, p.Detail)
+	}
+	return fmt.Sprintf("#%d %s: %s", p.Seq, p.Subject, p.Detail)
+}
+
+// ErrCorrupt is returned by Verify when the chain cannot be parsed.
+var ErrCorrupt = errors.New("ledger chain is corrupt")
+
+// Verify walks the chain and checks sequence numbers, hash links, entry
+// hashes and every record's content hash. It returns the entries checked
+// and the problems found.
+func (s *Store) Verify() (int, []Problem, error) {
+	entries, err := s.Entries()
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+
+	var problems []Problem
+	prevHash := ""
+	for i, e := range entries {
+		add := func(format string, args ...interface{}) {
+			problems = append(problems, Problem{Seq: e.Seq, Subject: e.Subject, Detail: fmt.Sprintf(format, args...)})
+		}
+		if e.Seq != i+1 {
+			add("sequence %d at position %d", e.Seq, i+1)
+		}
+		if e.Prev != prevHash {
+			add("previous hash %s does not match %s", shortHash(e.Prev), shortHash(prevHash))
+		}
+		if got := e.computeHash(); got != e.Hash {
+			add("entry hash %s does not match contents (%s)", shortHash(e.Hash), shortHash(got))
+		}
+		if rec, err := s.Record(e.Record); err != nil {
+			add("%v", err)
+		} else if rec.Kind != e.Kind || rec.Subject != e.Subject {
+			add("record is %s %s", rec.Kind, rec.Subject)
+		}
+		prevHash = e.Hash
+	}
+	return len(entries), problems, nil
+}
+
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func shortHash(h string) string {
+	if h == "" {
+		return "(none)"
+	}
+	if len(h) > 12 {
+		return h[:12]
+	}
+	return h
+}
@@ -0,0 +1,177 @@
+package ledger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func completion(id, title string) *Record {
+	return &Record{
+		Kind:    KindCompletion,
+		Subject: id,
+		Level:   2,
+		Completion: &Completion{
+			Type:     "task",
+			Title:    title,
+			ClosedAt: "2026-10-18T10:00:00Z",
+		},
+	}
+}
+
+func TestAppend_Idempotent(t *testing.T) {
+	store := Open(t.TempDir())
+	at := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+
+	first, added, err := store.Append(completion("gt-1", "Fix it"), TriggerClose, at)
+	if err != nil || !added {
+		t.Fatalf("first Append = %v, %v", added, err)
+	}
+	if first.Seq != 1 || first.Prev != "" {
+		t.Errorf("first entry = %+v", first)
+	}
+
+	again, added, err := store.Append(completion("gt-1", "Fix it"), TriggerScan, at.Add(time.Hour))
+	if err != nil || added {
+		t.Fatalf("re-export Append = %v, %v; want no-op", added, err)
+	}
+	if again != first {
+		t.Errorf("re-export returned %+v, want %+v", again, first)
+	}
+
+	// Reopened and closed with a new outcome: a correction entry.
+	second, added, err := store.Append(completion("gt-1", "Fix it properly"), TriggerClose, at.Add(2*time.Hour))
+	if err != nil || !added {
+		t.Fatalf("correction Append = %v, %v", added, err)
+	}
+	if second.Seq != 2 || second.Prev != first.Hash || second.Record == first.Record {
+		t.Errorf("correction entry = %+v", second)
+	}
+
+	history, err := store.History("gt-1")
+	if err != nil || len(history) != 2 {
+		t.Fatalf("History = %d entries, %v", len(history), err)
+	}
+	rec, err := store.Record(history[1].Record)
+	if err != nil || rec.Completion.Title != "Fix it properly" {
+		t.Errorf("Record = %+v, %v", rec, err)
+	}
+
+	n, problems, err := store.Verify()
+	if err != nil || n != 2 || len(problems) != 0 {
+		t.Errorf("Verify = %d, %v, %v", n, problems, err)
+	}
+}
+
+func TestVerify_DetectsTampering(t *testing.T) {
+	at := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	setup := func(t *testing.T) (*Store, []Entry) {
+		t.Helper()
+		store := Open(t.TempDir())
+		for i, id := range []string{"gt-1", "gt-2", "gt-3"} {
+			if _, _, err := store.Append(completion(id, "work "+id), TriggerClose, at.Add(time.Duration(i)*time.Minute)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		entries, err := store.Entries()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return store, entries
+	}
+	rewriteChain := func(t *testing.T, store *Store, edit func(string) string) {
+		t.Helper()
+		data, err := os.ReadFile(store.chainPath())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(store.chainPath(), []byte(edit(string(data))), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(*testing.T, *Store, []Entry)
+		want   string
+	}{
+		{
+			name: "edited record",
+			tamper: func(t *testing.T, store *Store, entries []Entry) {
+				path := store.objectPath(entries[1].Record)
+				_ = os.Chmod(path, 0644)
+				data, _ := os.ReadFile(path)
+				if err := os.WriteFile(path, []byte(strings.Replace(string(data), "work gt-2", "forged", 1)), 0644); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: "content hash",
+		},
+		{
+			name: "edited entry",
+			tamper: func(t *testing.T, store *Store, entries []Entry) {
+				rewriteChain(t, store, func(s string) string {
+					return strings.Replace(s, `"trigger":"close"`, `"trigger":"scan"`, 1)
+				})
+			},
+			want: "entry hash",
+		},
+		{
+			name: "removed entry",
+			tamper: func(t *testing.T, store *Store, entries []Entry) {
+				rewriteChain(t, store, func(s string) string {
+					lines := strings.SplitAfter(s, "\n")
+					return lines[0] + lines[2]
+				})
+			},
+			want: "previous hash",
+		},
+		{
+			name: "missing record",
+			tamper: func(t *testing.T, store *Store, entries []Entry) {
+				if err := os.Remove(store.objectPath(entries[0].Record)); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: "no such file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, entries := setup(t)
+			tt.tamper(t, store, entries)
+			_, problems, err := store.Verify()
+			if err != nil {
+				t.Fatal(err)
+			}
+			found := false
+			for _, p := range problems {
+				found = found || strings.Contains(p.Detail, tt.want)
+			}
+			if !found {
+				t.Errorf("problems = %v, want %q", problems, tt.want)
+			}
+		})
+	}
+}
+
+func TestObjectsAreContentAddressed(t *testing.T) {
+	store := Open(t.TempDir())
+	entry, _, err := store.Append(completion("gt-1", "Fix it"), TriggerClose, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := store.objectPath(entry.Record)
+	if filepath.Base(filepath.Dir(path)) != entry.Record[:2] {
+		t.Errorf("object path = %s", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hashBytes(data) != entry.Record {
+		t.Errorf("object hash does not match entry")
+	}
+}
@@ -0,0 +1,488 @@
+package mail
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// indexVersion is bumped whenever the index format or tokenizer changes;
+// an index with another version is rebuilt.
+const indexVersion = 2
+
+// indexRefreshAge bounds how stale the inbox part of an index may get.
+// Messages delivered and acknowledged through this package update the
+// index directly; changes made behind its back (bd close, another tool)
+// are picked up by re-listing the inbox at most this often.
+const indexRefreshAge = 10 * time.Minute
+
+// indexCompactOps is how many logged updates a search replays before it
+// folds them into the index file and truncates the log.
+const indexCompactOps = 200
+
+// indexDoc is one indexed message.
+type indexDoc struct {
+	Msg      *Message `json:"msg"`
+	Archived bool     `json:"archived,omitempty"`
+	Closed   bool     `json:"closed,omitempty"` // Acknowledged beads message, no longer in the inbox
+}
+
+// Index log operations.
+const (
+	opPut    = "put"
+	opRead   = "read"
+	opUnread = "unread"
+	opClose  = "close"
+	opReopen = "reopen"
+	opDrop   = "drop"
+)
+
+// indexOp is one update appended to a mailbox's index log. Replaying an
+// op twice has the same effect as replaying it once.
+type indexOp struct {
+	Op       string   `json:"op"`
+	ID       string   `json:"id,omitempty"`
+	Msg      *Message `json:"msg,omitempty"`
+	Archived bool     `json:"archived,omitempty"`
+}
+
+// searchIndex is a mailbox's persistent inverted index. Postings map
+// "subject:<word>" and "body:<word>" to the sorted IDs of the messages
+// containing the word.
+type searchIndex struct {
+	Version     int                  `json:"version"`
+	Docs        map[string]*indexDoc `json:"docs"`
+	Postings    map[string][]string  `json:"postings"`
+	InboxSynced time.Time            `json:"inbox_synced"`
+	ArchiveSize int64                `json:"archive_size"` // Bytes of the archive file indexed so far
+}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{
+		Version:  indexVersion,
+		Docs:     make(map[string]*indexDoc),
+		Postings: make(map[string][]string),
+	}
+}
+
+func postingKey(field, word string) string {
+	return field + ":" + word
+}
+
+// docTerms returns the posting keys for a message.
+func docTerms(msg *Message) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, f := range []struct{ field, text string }{
+		{scopeSubject, msg.Subject},
+		{scopeBody, msg.Body},
+	} {
+		for _, w := range tokenize(f.text) {
+			k := postingKey(f.field, w)
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	return keys
+}
+
+// put adds or replaces a message. An archived copy is never replaced by
+// an inbox one.
+func (ix *searchIndex) put(msg *Message, archived bool) {
+	if msg == nil || msg.ID == "" {
+		return
+	}
+	if old := ix.Docs[msg.ID]; old != nil {
+		if old.Archived && !archived {
+			return
+		}
+		ix.remove(msg.ID)
+	}
+	ix.Docs[msg.ID] = &indexDoc{Msg: msg, Archived: archived}
+	for _, k := range docTerms(msg) {
+		ids := ix.Postings[k]
+		i := sort.SearchStrings(ids, msg.ID)
+		ids = append(ids, "")
+		copy(ids[i+1:], ids[i:])
+		ids[i] = msg.ID
+		ix.Postings[k] = ids
+	}
+}
+
+// remove drops a message and its postings.
+func (ix *searchIndex) remove(id string) {
+	doc := ix.Docs[id]
+	if doc == nil {
+		return
+	}
+	delete(ix.Docs, id)
+	for _, k := range docTerms(doc.Msg) {
+		ids := ix.Postings[k]
+		i := sort.SearchStrings(ids, id)
+		if i < len(ids) && ids[i] == id {
+			ids = append(ids[:i], ids[i+1:]...)
+		}
+		if len(ids) == 0 {
+			delete(ix.Postings, k)
+		} else {
+			ix.Postings[k] = ids
+		}
+	}
+}
+
+// setRead updates the read state of an inbox message.
+func (ix *searchIndex) setRead(id string, read bool) {
+	if doc := ix.Docs[id]; doc != nil && !doc.Archived {
+		doc.Msg.Read = read
+	}
+}
+
+// setClosed records that a beads message was acknowledged (closed) or
+// reopened. Closed messages stay searchable; they are read and no longer
+// in the inbox.
+func (ix *searchIndex) setClosed(id string, closed bool) {
+	if doc := ix.Docs[id]; doc != nil && !doc.Archived {
+		doc.Closed = closed
+		doc.Msg.Read = closed
+	}
+}
+
+// dropInbox removes a message that left the inbox, keeping archived copies.
+func (ix *searchIndex) dropInbox(id string) {
+	if doc := ix.Docs[id]; doc != nil && !doc.Archived {
+		ix.remove(id)
+	}
+}
+
+// apply replays one logged update.
+func (ix *searchIndex) apply(op *indexOp) {
+	switch op.Op {
+	case opPut:
+		ix.put(op.Msg, op.Archived)
+	case opRead:
+		ix.setRead(op.ID, true)
+	case opUnread:
+		ix.setRead(op.ID, false)
+	case opClose:
+		ix.setClosed(op.ID, true)
+	case opReopen:
+		ix.setClosed(op.ID, false)
+	case opDrop:
+		ix.dropInbox(op.ID)
+	}
+}
+
+// eachPrefix calls fn for every message with a word in field starting
+// with prefix.
+func (ix *searchIndex) eachPrefix(field, prefix string, fn func(id string)) {
+	want := postingKey(field, prefix)
+	for k, ids := range ix.Postings {
+		if strings.HasPrefix(k, want) {
+			for _, id := range ids {
+				fn(id)
+			}
+		}
+	}
+}
+
+// syncInbox replaces the inbox part of the index with msgs. Indexed inbox
+// messages missing from msgs are dropped, or marked closed when keepClosed
+// is set (beads mailboxes, where leaving the inbox means the bead closed).
+func (ix *searchIndex) syncInbox(msgs []*Message, now time.Time, keepClosed bool) {
+	current := make(map[string]bool, len(msgs))
+	for _, msg := range msgs {
+		current[msg.ID] = true
+	}
+	for id, doc := range ix.Docs {
+		if doc.Archived || doc.Closed || current[id] {
+			continue
+		}
+		if keepClosed {
+			ix.setClosed(id, true)
+		} else {
+			ix.remove(id)
+		}
+	}
+	for _, msg := range msgs {
+		ix.put(msg, false)
+	}
+	ix.InboxSynced = now
+}
+
+// search returns the messages matching q, newest first.
+func (ix *searchIndex) search(q *Query) []*Message {
+	var matches []*Message
+	consider := func(doc *indexDoc) {
+		if doc != nil && q.root.match(doc) {
+			matches = append(matches, doc.Msg)
+		}
+	}
+	if ids, ok := q.root.candidates(ix); ok {
+		for id := range ids {
+			consider(ix.Docs[id])
+		}
+	} else {
+		for _, doc := range ix.Docs {
+			consider(doc)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if !matches[i].Timestamp.Equal(matches[j].Timestamp) {
+			return matches[i].Timestamp.After(matches[j].Timestamp)
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	return matches
+}
+
+// IndexPath returns the path of the mailbox's search index: next to the
+// JSONL inbox for legacy mailboxes, under .beads/mail-index otherwise.
+func (m *Mailbox) IndexPath() string {
+	if m.legacy {
+		return m.path + ".index"
+	}
+	beadsDir := m.beadsDir
+	if beadsDir == "" {
+		beadsDir = filepath.Join(m.workDir, ".beads")
+	}
+	name := strings.Trim(strings.ReplaceAll(m.identity, "/", "_"), "_")
+	if name == "" {
+		name = "default"
+	}
+	return filepath.Join(beadsDir, "mail-index", name+".json")
+}
+
+// indexLogPath returns the append-only update log kept next to an index.
+func indexLogPath(indexPath string) string {
+	return indexPath + ".log"
+}
+
+// withIndex runs fn on the mailbox's index, with logged updates replayed,
+// under an exclusive file lock. The index file is rewritten and the log
+// truncated when fn reports a change or the log has grown past
+// indexCompactOps; otherwise nothing is written.
+func (m *Mailbox) withIndex(fn func(ix *searchIndex, exists bool) (bool, error)) error {
+	path := m.IndexPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	lock := flock.New(path + ".lock")
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("locking mail index: %w", err)
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	ix, ops, exists := openIndex(path)
+	changed, err := fn(ix, exists)
+	if err != nil {
+		return err
+	}
+	if !changed && ops < indexCompactOps {
+		return nil
+	}
+	if err := util.AtomicWriteJSON(path, ix); err != nil {
+		return err
+	}
+	// A crash before the truncate only replays ops the file already holds.
+	if err := os.Remove(indexLogPath(path)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// openIndex loads an index file and replays its log, returning the number
+// of ops replayed. A missing, corrupt or outdated index comes back empty
+// with exists=false and its log is ignored.
+func openIndex(path string) (*searchIndex, int, bool) {
+	ix, exists := loadIndex(path)
+	if !exists {
+		return ix, 0, false
+	}
+	ops := readIndexLog(indexLogPath(path))
+	for i := range ops {
+		ix.apply(&ops[i])
+	}
+	return ix, len(ops), true
+}
+
+// readIndexLog reads the ops in an index log, skipping torn or malformed
+// lines.
+func readIndexLog(path string) []indexOp {
+	f, err := os.Open(path) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		return nil
+	}
+	defer func() { _ = f.Close() }()
+
+	var ops []indexOp
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 {
+			var op indexOp
+			if json.Unmarshal(line, &op) == nil && op.Op != "" {
+				ops = append(ops, op)
+			}
+		}
+		if err != nil {
+			return ops
+		}
+	}
+}
+
+// loadIndex reads an index file. A missing, corrupt or outdated index
+// comes back empty with exists=false.
+func loadIndex(path string) (*searchIndex, bool) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		return newSearchIndex(), false
+	}
+	ix := newSearchIndex()
+	if err := json.Unmarshal(data, ix); err != nil || ix.Version != indexVersion {
+		return newSearchIndex(), false
+	}
+	if ix.Docs == nil {
+		ix.Docs = make(map[string]*indexDoc)
+	}
+	if ix.Postings == nil {
+		ix.Postings = make(map[string][]string)
+	}
+	return ix, true
+}
+
+// logIndex appends an update to an existing index's log, so delivering,
+// reading or archiving a message costs one short write however large the
+// index is. Index maintenance is best effort: a missed update is repaired
+// by the next refresh or reindex.
+func (m *Mailbox) logIndex(op indexOp) {
+	path := m.IndexPath()
+	if _, err := os.Stat(path); err != nil {
+		return
+	}
+	data, err := json.Marshal(op)
+	if err != nil {
+		return
+	}
+	lock := flock.New(path + ".lock")
+	if err := lock.Lock(); err != nil {
+		return
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	f, err := os.OpenFile(indexLogPath(path), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		return
+	}
+	_, _ = f.Write(append(data, '\n'))
+	_ = f.Close()
+}
+
+// Reindex rebuilds the mailbox's search index from the inbox and archive.
+func (m *Mailbox) Reindex() error {
+	return m.withIndex(func(ix *searchIndex, _ bool) (bool, error) {
+		*ix = *newSearchIndex()
+		_, err := m.refreshIndex(ix, true)
+		return true, err
+	})
+}
+
+// refreshIndex brings an index up to date. The inbox is re-listed when
+// full is set or the last listing is older than indexRefreshAge; a full
+// refresh of a beads mailbox also indexes its closed messages. The archive
+// is append-only except for purges, so only new lines are read unless it
+// shrank. It reports whether the index changed.
+func (m *Mailbox) refreshIndex(ix *searchIndex, full bool) (bool, error) {
+	changed := false
+	now := timeNow()
+	if full && !m.legacy {
+		closed, err := m.listClosedBeads()
+		if err != nil {
+			return false, err
+		}
+		for _, msg := range closed {
+			ix.put(msg, false)
+			ix.setClosed(msg.ID, true)
+		}
+		changed = true
+	}
+	if full || now.Sub(ix.InboxSynced) > indexRefreshAge || now.Before(ix.InboxSynced) {
+		inbox, err := m.List()
+		if err != nil {
+			return false, err
+		}
+		ix.syncInbox(inbox, now, !m.legacy)
+		changed = true
+	}
+
+	var size int64
+	if info, err := os.Stat(m.ArchivePath()); err == nil {
+		size = info.Size()
+	}
+	if size == ix.ArchiveSize {
+		return changed, nil
+	}
+	offset := ix.ArchiveSize
+	if size < offset {
+		// Purged or rewritten: drop archived docs and read it all again.
+		for id, doc := range ix.Docs {
+			if doc.Archived {
+				ix.remove(id)
+			}
+		}
+		offset = 0
+	}
+	archived, end, err := readArchiveFrom(m.ArchivePath(), offset)
+	if err != nil {
+		return changed, err
+	}
+	for _, msg := range archived {
+		ix.put(msg, true)
+	}
+	ix.ArchiveSize = end
+	return true, nil
+}
+
+// readArchiveFrom reads complete archive lines starting at offset and
+// returns the offset just past the last complete line.
+func readArchiveFrom(path string, offset int64) ([]*Message, int64, error) {
+	f, err := os.Open(path) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, 0, nil
+		}
+		return nil, offset, err
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, offset, err
+	}
+
+	var msgs []*Message
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil {
+			if err == io.EOF {
+				break // A partial last line is picked up next time
+			}
+			return nil, offset, err
+		}
+		offset += int64(len(line))
+		var msg Message
+		if json.Unmarshal(line, &msg) == nil {
+			msgs = append(msgs, &msg)
+		}
+	}
+	return msgs, offset, nil
+}
@@ -7,12 +7,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/steveyegge/gastown/internal/beads"
-	"github.com/steveyegge/gastown/internal/runtime"
 )
 
 // timeNow is a function that returns the current time. It can be overridden in tests.
@@ -22,15 +21,17 @@ var timeNow = time.Now
 var (
 	ErrMessageNotFound = errors.New("message not found")
 	ErrEmptyInbox      = errors.New("inbox is empty")
+	ErrMessageExpired  = errors.New("message has expired")
 )
 
 // Mailbox manages messages for an identity via beads.
 type Mailbox struct {
-	identity string // beads identity (e.g., "gastown/polecats/Toast")
-	workDir  string // directory to run bd commands in
-	beadsDir string // explicit .beads directory path (set via BEADS_DIR)
-	path     string // for legacy JSONL mode (crew workers)
-	legacy   bool   // true = use JSONL files, false = use beads
+	identity string      // beads identity (e.g., "gastown/polecats/Toast")
+	workDir  string      // directory to run bd commands in
+	beadsDir string      // explicit .beads directory path (set via BEADS_DIR)
+	path     string      // for legacy JSONL mode (crew workers)
+	legacy   bool        // true = use JSONL files, false = use beads
+	store    beads.Store // message state changes; nil = bd in workDir/beadsDir
 }
 
 // NewMailbox creates a mailbox for the given JSONL path (legacy mode).
@@ -73,6 +74,22 @@ func NewMailboxWithBeadsDir(address, workDir, beadsDir string) *Mailbox {
 	}
 }
 
+// issues returns the store that message state changes go through.
+func (m *Mailbox) issues() beads.Store {
+	if m.store != nil {
+		return m.store
+	}
+	return beads.NewWithBeadsDir(m.workDir, m.beadsDir)
+}
+
+// messageErr maps a store error for id to the mail package's errors.
+func messageErr(err error) error {
+	if errors.Is(err, beads.ErrNotFound) {
+		return ErrMessageNotFound
+	}
+	return err
+}
+
 // Identity returns the beads identity for this mailbox.
 func (m *Mailbox) Identity() string {
 	return m.identity
@@ -83,12 +100,28 @@ func (m *Mailbox) Path() string {
 	return m.path
 }
 
-// List returns all open messages in the mailbox.
+// List returns all open messages in the mailbox. Expired messages are
+// left out even before the daemon closes them.
 func (m *Mailbox) List() ([]*Message, error) {
+	var messages []*Message
+	var err error
 	if m.legacy {
-		return m.listLegacy()
+		messages, err = m.listLegacy()
+	} else {
+		messages, err = m.listBeads()
 	}
-	return m.listBeads()
+	if err != nil {
+		return nil, err
+	}
+
+	now := timeNow()
+	live := messages[:0]
+	for _, msg := range messages {
+		if !msg.Expired(now) {
+			live = append(live, msg)
+		}
+	}
+	return live, nil
 }
 
 func (m *Mailbox) listBeads() ([]*Message, error) {
@@ -113,20 +146,64 @@ func (m *Mailbox) listBeads() ([]*Message, error) {
 // Uses a single bd list call and filters client-side, replacing the previous
 // approach of N parallel queries (2-3 per identity variant).
 func (m *Mailbox) listFromDir(beadsDir string) ([]*Message, error) {
-	identities := m.identityVariants()
+	// Single bd query: fetch all non-closed messages of type "message",
+	// then filter client-side for assignee/CC match. Process-spawn overhead
+	// dominates query time, so 1 broad call beats N narrow calls.
+	allMsgs, err := m.queryMessages(beadsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Filter: assignee match (open/hooked) OR CC match (open only)
+	identitySet, ccLabelSet := m.identitySets()
+	var messages []*Message
+	for i := range allMsgs {
+		bm := &allMsgs[i]
 
+		// Assignee match: open or hooked status
+		if identitySet[bm.Assignee] && (bm.Status == "open" || bm.Status == "hooked") {
+			messages = append(messages, bm.ToMessage())
+			continue
+		}
+
+		// CC match: open status only
+		if bm.Status == "open" && hasAnyLabel(bm, ccLabelSet) {
+			messages = append(messages, bm.ToMessage())
+		}
+	}
+
+	return messages, nil
+}
+
+// listClosedBeads returns the mailbox's acknowledged (closed) messages,
+// for the search index.
+func (m *Mailbox) listClosedBeads() ([]*Message, error) {
+	allMsgs, err := m.queryMessages(m.beadsDir, "--status=closed")
+	if err != nil {
+		return nil, err
+	}
+	identitySet, ccLabelSet := m.identitySets()
+	var messages []*Message
+	for i := range allMsgs {
+		bm := &allMsgs[i]
+		if identitySet[bm.Assignee] || hasAnyLabel(bm, ccLabelSet) {
+			messages = append(messages, bm.ToMessage())
+		}
+	}
+	return messages, nil
+}
+
+// queryMessages lists the messages in a beads directory with bd list.
+func (m *Mailbox) queryMessages(beadsDir string, extra ...string) ([]BeadsMessage, error) {
 	if err := beads.EnsureCustomTypes(beadsDir); err != nil {
 		return nil, fmt.Errorf("ensuring custom types: %w", err)
 	}
 
-	// Single bd query: fetch all non-closed messages of type "message",
-	// then filter client-side for assignee/CC match. Process-spawn overhead
-	// dominates query time, so 1 broad call beats N narrow calls.
-	args := []string{"list",
+	args := append([]string{"list",
 		"--type", "message",
 		"--json",
 		"--limit", "0",
-	}
+	}, extra...)
 
 	stdout, err := runBdCommand(args, m.workDir, beadsDir)
 	if err != nil {
@@ -140,38 +217,30 @@ func (m *Mailbox) listFromDir(beadsDir string) ([]*Message, error) {
 		}
 		return nil, err
 	}
+	return allMsgs, nil
+}
 
-	// Build identity lookup sets for O(1) matching
-	identitySet := make(map[string]bool, len(identities))
-	ccLabelSet := make(map[string]bool, len(identities))
-	for _, id := range identities {
-		identitySet[id] = true
-		ccLabelSet["cc:"+id] = true
+// identitySets returns lookup sets for the mailbox's assignee identities
+// and their CC labels.
+func (m *Mailbox) identitySets() (identities, ccLabels map[string]bool) {
+	variants := m.identityVariants()
+	identities = make(map[string]bool, len(variants))
+	ccLabels = make(map[string]bool, len(variants))
+	for _, id := range variants {
+		identities[id] = true
+		ccLabels["cc:"+id] = true
 	}
+	return identities, ccLabels
+}
 
-	// Filter: assignee match (open/hooked) OR CC match (open only)
-	var messages []*Message
-	for i := range allMsgs {
-		bm := &allMsgs[i]
-
-		// Assignee match: open or hooked status
-		if identitySet[bm.Assignee] && (bm.Status == "open" || bm.Status == "hooked") {
-			messages = append(messages, bm.ToMessage())
-			continue
-		}
-
-		// CC match: open status only
-		if bm.Status == "open" {
-			for _, label := range bm.Labels {
-				if ccLabelSet[label] {
-					messages = append(messages, bm.ToMessage())
-					break
-				}
-			}
+// hasAnyLabel reports whether bm carries one of labels.
+func hasAnyLabel(bm *BeadsMessage, labels map[string]bool) bool {
+	for _, label := range bm.Labels {
+		if labels[label] {
+			return true
 		}
 	}
-
-	return messages, nil
+	return false
 }
 
 // identityVariants returns all identity formats to query.
@@ -304,26 +373,12 @@ func (m *Mailbox) MarkRead(id string) error {
 }
 
 func (m *Mailbox) markReadBeads(id string) error {
-	// Single DB - wisps and persistent messages in same store
-	return m.closeInDir(id, m.beadsDir)
-}
-
-// closeInDir closes a message in a specific beads directory.
-func (m *Mailbox) closeInDir(id, beadsDir string) error {
-	args := []string{"close", id}
-	// Pass session ID for work attribution if available
-	if sessionID := runtime.SessionIDFromEnv(); sessionID != "" {
-		args = append(args, "--session="+sessionID)
-	}
-
-	_, err := runBdCommand(args, m.workDir, beadsDir)
-	if err != nil {
-		if bdErr, ok := err.(*bdError); ok && bdErr.ContainsError("not found") {
-			return ErrMessageNotFound
-		}
-		return err
+	// Single DB - wisps and persistent messages in same store.
+	// Close passes the session ID for work attribution.
+	if err := m.issues().Close(id); err != nil {
+		return messageErr(err)
 	}
-
+	m.logIndex(indexOp{Op: opClose, ID: id})
 	return nil
 }
 
@@ -345,7 +400,11 @@ func (m *Mailbox) markReadLegacy(id string) error {
 		return ErrMessageNotFound
 	}
 
-	return m.rewriteLegacy(messages)
+	if err := m.rewriteLegacy(messages); err != nil {
+		return err
+	}
+	m.logIndex(indexOp{Op: opRead, ID: id})
+	return nil
 }
 
 // MarkReadOnly marks a message as read WITHOUT archiving/closing it.
@@ -361,16 +420,11 @@ func (m *Mailbox) MarkReadOnly(id string) error {
 
 func (m *Mailbox) markReadOnlyBeads(id string) error {
 	// Add "read" label to mark as read without closing
-	args := []string{"label", "add", id, "read"}
-
-	_, err := runBdCommand(args, m.workDir, m.beadsDir)
-	if err != nil {
-		if bdErr, ok := err.(*bdError); ok && bdErr.ContainsError("not found") {
-			return ErrMessageNotFound
-		}
-		return err
+	if err := m.issues().Update(id, beads.UpdateOptions{AddLabels: []string{"read"}}); err != nil {
+		return messageErr(err)
 	}
 
+	m.logIndex(indexOp{Op: opRead, ID: id})
 	return nil
 }
 
@@ -386,20 +440,11 @@ func (m *Mailbox) MarkUnreadOnly(id string) error {
 
 func (m *Mailbox) markUnreadOnlyBeads(id string) error {
 	// Remove "read" label to mark as unread
-	args := []string{"label", "remove", id, "read"}
-
-	_, err := runBdCommand(args, m.workDir, m.beadsDir)
-	if err != nil {
-		if bdErr, ok := err.(*bdError); ok && bdErr.ContainsError("not found") {
-			return ErrMessageNotFound
-		}
-		// Ignore error if label doesn't exist
-		if bdErr, ok := err.(*bdError); ok && bdErr.ContainsError("does not have label") {
-			return nil
-		}
-		return err
+	if err := m.issues().Update(id, beads.UpdateOptions{RemoveLabels: []string{"read"}}); err != nil {
+		return messageErr(err)
 	}
 
+	m.logIndex(indexOp{Op: opUnread, ID: id})
 	return nil
 }
 
@@ -412,16 +457,12 @@ func (m *Mailbox) MarkUnread(id string) error {
 }
 
 func (m *Mailbox) markUnreadBeads(id string) error {
-	args := []string{"reopen", id}
-
-	_, err := runBdCommand(args, m.workDir, m.beadsDir)
-	if err != nil {
-		if bdErr, ok := err.(*bdError); ok && bdErr.ContainsError("not found") {
-			return ErrMessageNotFound
-		}
-		return err
+	status := "open"
+	if err := m.issues().Update(id, beads.UpdateOptions{Status: &status}); err != nil {
+		return messageErr(err)
 	}
 
+	m.logIndex(indexOp{Op: opReopen, ID: id})
 	return nil
 }
 
@@ -443,7 +484,11 @@ func (m *Mailbox) markUnreadLegacy(id string) error {
 		return ErrMessageNotFound
 	}
 
-	return m.rewriteLegacy(messages)
+	if err := m.rewriteLegacy(messages); err != nil {
+		return err
+	}
+	m.logIndex(indexOp{Op: opUnread, ID: id})
+	return nil
 }
 
 // Delete removes a message.
@@ -474,7 +519,11 @@ func (m *Mailbox) deleteLegacy(id string) error {
 		return ErrMessageNotFound
 	}
 
-	return m.rewriteLegacy(filtered)
+	if err := m.rewriteLegacy(filtered); err != nil {
+		return err
+	}
+	m.logIndex(indexOp{Op: opDrop, ID: id})
+	return nil
 }
 
 // Archive moves a message to the archive file and removes it from inbox.
@@ -491,7 +540,11 @@ func (m *Mailbox) Archive(id string) error {
 	}
 
 	// Delete from inbox
-	return m.Delete(id)
+	if err := m.Delete(id); err != nil {
+		return err
+	}
+	m.logIndex(indexOp{Op: opPut, Msg: msg, Archived: true})
+	return nil
 }
 
 // ArchivePath returns the path to the archive file.
@@ -639,74 +692,52 @@ func (m *Mailbox) rewriteArchive(messages []*Message) error {
 
 // SearchOptions specifies search parameters.
 type SearchOptions struct {
-	Query       string // Regex pattern to search for
+	Query       string // Search expression (see Query for the syntax)
 	FromFilter  string // Optional: only match messages from this sender
-	SubjectOnly bool   // Only search subject
-	BodyOnly    bool   // Only search body
+	SubjectOnly bool   // Unqualified words match the subject only
+	BodyOnly    bool   // Unqualified words match the body only
+	Reindex     bool   // Rebuild the index before searching
 }
 
 // Search finds messages matching the given criteria.
-// Returns messages from both inbox and archive.
-// Query and FromFilter are treated as literal strings (not regex) to prevent ReDoS.
+// Returns messages from both inbox and archive, newest first.
+//
+// Searches go through the mailbox's persistent index (see IndexPath),
+// which is built on first use and kept current as mail is delivered,
+// read and archived. Read and acknowledged messages stay searchable.
 func (m *Mailbox) Search(opts SearchOptions) ([]*Message, error) {
-	// Use QuoteMeta to escape special regex chars - prevents ReDoS attacks
-	// and provides intuitive literal string matching for users
-	re, err := regexp.Compile("(?i)" + regexp.QuoteMeta(opts.Query))
-	if err != nil {
-		return nil, fmt.Errorf("invalid search pattern: %w", err)
-	}
-
-	var fromRe *regexp.Regexp
-	if opts.FromFilter != "" {
-		fromRe, err = regexp.Compile("(?i)" + regexp.QuoteMeta(opts.FromFilter))
-		if err != nil {
-			return nil, fmt.Errorf("invalid from pattern: %w", err)
-		}
+	scope := scopeAll
+	if opts.SubjectOnly {
+		scope = scopeSubject
+	} else if opts.BodyOnly {
+		scope = scopeBody
 	}
-
-	// Get inbox messages
-	inbox, err := m.List()
+	q, err := parseQuery(opts.Query, scope)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("invalid search query: %w", err)
 	}
-
-	// Get archived messages
-	archived, err := m.ListArchived()
-	if err != nil && !os.IsNotExist(err) {
-		return nil, err
+	if opts.FromFilter != "" {
+		from := strings.ToLower(opts.FromFilter)
+		q.root = andNode{kids: []queryNode{q.root, filterNode{func(d *indexDoc) bool {
+			return strings.Contains(strings.ToLower(d.Msg.From), from)
+		}}}}
 	}
 
-	// Combine and search
-	all := append(inbox, archived...)
 	var matches []*Message
-
-	for _, msg := range all {
-		// Apply from filter
-		if fromRe != nil && !fromRe.MatchString(msg.From) {
-			continue
+	err = m.withIndex(func(ix *searchIndex, exists bool) (bool, error) {
+		if !exists || opts.Reindex {
+			*ix = *newSearchIndex()
 		}
-
-		// Search in specified fields
-		matched := false
-		if opts.SubjectOnly {
-			matched = re.MatchString(msg.Subject)
-		} else if opts.BodyOnly {
-			matched = re.MatchString(msg.Body)
-		} else {
-			// Search in both subject and body
-			matched = re.MatchString(msg.Subject) || re.MatchString(msg.Body)
-		}
-
-		if matched {
-			matches = append(matches, msg)
+		changed, err := m.refreshIndex(ix, !exists || opts.Reindex)
+		if err != nil {
+			return false, err
 		}
-	}
-
-	// Sort by timestamp (newest first)
-	sort.Slice(matches, func(i, j int) bool {
-		return matches[i].Timestamp.After(matches[j].Timestamp)
+		matches = ix.search(q)
+		return changed || !exists, nil
 	})
-
+	if err != nil {
+		return nil, err
+	}
 	return matches, nil
 }
 
@@ -756,8 +787,11 @@ func (m *Mailbox) appendLegacy(msg *Message) error {
 		return err
 	}
 
-	_, err = file.WriteString(string(data) + "\n")
-	return err
+	if _, err := file.WriteString(string(data) + "\n"); err != nil {
+		return err
+	}
+	m.logIndex(indexOp{Op: opPut, Msg: msg})
+	return nil
 }
 
 // rewriteLegacy rewrites the mailbox with the given messages.
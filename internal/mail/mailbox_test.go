@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
 )
 
 func TestNewMailbox(t *testing.T) {
@@ -557,3 +559,53 @@ func TestMailboxLegacyMarkReadTwice(t *testing.T) {
 	}
 }
 
+
+func TestMailboxBeadsStateChanges(t *testing.T) {
+	store := beads.NewMemStore("hq-")
+	store.Add(&beads.Issue{ID: "hq-msg", Title: "Hello", Status: "open", Type: "message"})
+	m := NewMailboxBeads("gastown/Toast", t.TempDir())
+	m.store = store
+
+	status := func() (string, bool) {
+		t.Helper()
+		issue, err := store.Show("hq-msg")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return issue.Status, beads.HasLabel(issue, "read")
+	}
+
+	if err := m.MarkReadOnly("hq-msg"); err != nil {
+		t.Fatal(err)
+	}
+	if st, read := status(); st != "open" || !read {
+		t.Errorf("after MarkReadOnly: status=%s read=%v", st, read)
+	}
+	if err := m.MarkUnreadOnly("hq-msg"); err != nil {
+		t.Fatal(err)
+	}
+	if _, read := status(); read {
+		t.Error("MarkUnreadOnly kept the read label")
+	}
+	if err := m.MarkRead("hq-msg"); err != nil {
+		t.Fatal(err)
+	}
+	if st, _ := status(); st != "closed" {
+		t.Errorf("after MarkRead: status=%s, want closed", st)
+	}
+	if err := m.MarkUnread("hq-msg"); err != nil {
+		t.Fatal(err)
+	}
+	if st, _ := status(); st != "open" {
+		t.Errorf("after MarkUnread: status=%s, want open", st)
+	}
+
+	for name, op := range map[string]func(string) error{
+		"MarkRead": m.MarkRead, "MarkReadOnly": m.MarkReadOnly,
+		"MarkUnreadOnly": m.MarkUnreadOnly, "MarkUnread": m.MarkUnread,
+	} {
+		if err := op("hq-missing"); err != ErrMessageNotFound {
+			t.Errorf("%s(missing) = %v, want ErrMessageNotFound", name, err)
+		}
+	}
+}
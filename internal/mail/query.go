@@ -0,0 +1,576 @@
+package mail
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Query is a parsed mail search expression.
+//
+// Syntax:
+//
+//	deploy failed          both words, anywhere in subject or body
+//	"deploy failed"        the exact phrase
+//	deploy*                words starting with "deploy"
+//	subject:deploy         restrict a word or phrase to the subject (or body:)
+//	from:witness           sender contains "witness" (to: also matches CC)
+//	thread:thread-abc      exact thread ID
+//	type:task              message type (task, scavenge, notification, reply)
+//	label:ci               tagged with a label (see mail filter rules)
+//	priority:high          priority; priority:>=high for high and urgent
+//	after:2025-01-02       sent on or after a date; before: for strictly before
+//	after:7d               relative ages: 30m, 12h, 7d, 2w
+//	is:unread              also is:read, is:archived, is:inbox, is:pinned, is:wisp
+//	a OR b, a AND b        boolean operators (AND is implied between terms)
+//	NOT a, -a              negation
+//	( ... )                grouping
+//
+// Operators must be upper case; lower-case "or" and "not" are searched as
+// words. An empty query matches every message.
+type Query struct {
+	root queryNode
+}
+
+// Text scopes for unqualified words and phrases.
+const (
+	scopeAll     = ""
+	scopeSubject = "subject"
+	scopeBody    = "body"
+)
+
+// ParseQuery parses a search expression.
+func ParseQuery(s string) (*Query, error) {
+	return parseQuery(s, scopeAll)
+}
+
+func parseQuery(s, scope string) (*Query, error) {
+	toks, err := lexQuery(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{toks: toks, scope: scope, now: timeNow()}
+	if len(toks) == 0 {
+		return &Query{root: allNode{}}, nil
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.toks) {
+		return nil, fmt.Errorf("unexpected %q in query", p.toks[p.pos].text)
+	}
+	return &Query{root: root}, nil
+}
+
+// Match reports whether a message satisfies the query. archived says
+// whether the message comes from the archive (for is:archived/is:inbox).
+func (q *Query) Match(msg *Message, archived bool) bool {
+	return q.root.match(&indexDoc{Msg: msg, Archived: archived})
+}
+
+// queryNode is one node of a parsed query.
+type queryNode interface {
+	// match evaluates the node against a document.
+	match(d *indexDoc) bool
+	// candidates returns the IDs that can possibly match, using only the
+	// index postings. ok is false if the node can't narrow the search.
+	candidates(ix *searchIndex) (ids map[string]bool, ok bool)
+}
+
+type allNode struct{}
+
+func (allNode) match(*indexDoc) bool                            { return true }
+func (allNode) candidates(*searchIndex) (map[string]bool, bool) { return nil, false }
+
+type andNode struct{ kids []queryNode }
+
+func (n andNode) match(d *indexDoc) bool {
+	for _, k := range n.kids {
+		if !k.match(d) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n andNode) candidates(ix *searchIndex) (map[string]bool, bool) {
+	var out map[string]bool
+	narrowed := false
+	for _, k := range n.kids {
+		ids, ok := k.candidates(ix)
+		if !ok {
+			continue
+		}
+		if !narrowed {
+			out, narrowed = ids, true
+			continue
+		}
+		out = intersect(out, ids)
+	}
+	return out, narrowed
+}
+
+type orNode struct{ kids []queryNode }
+
+func (n orNode) match(d *indexDoc) bool {
+	for _, k := range n.kids {
+		if k.match(d) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n orNode) candidates(ix *searchIndex) (map[string]bool, bool) {
+	out := make(map[string]bool)
+	for _, k := range n.kids {
+		ids, ok := k.candidates(ix)
+		if !ok {
+			return nil, false
+		}
+		for id := range ids {
+			out[id] = true
+		}
+	}
+	return out, true
+}
+
+type notNode struct{ kid queryNode }
+
+func (n notNode) match(d *indexDoc) bool                        { return !n.kid.match(d) }
+func (notNode) candidates(*searchIndex) (map[string]bool, bool) { return nil, false }
+
+// textNode matches a word, prefix or phrase in the subject and/or body.
+type textNode struct {
+	scope  string   // scopeAll, scopeSubject or scopeBody
+	words  []string // normalized tokens; more than one is a phrase
+	prefix bool     // last word is a prefix (trailing *)
+}
+
+func (n textNode) match(d *indexDoc) bool {
+	if n.scope != scopeBody && n.matchTokens(tokenize(d.Msg.Subject)) {
+		return true
+	}
+	return n.scope != scopeSubject && n.matchTokens(tokenize(d.Msg.Body))
+}
+
+func (n textNode) matchTokens(toks []string) bool {
+	for i := 0; i+len(n.words) <= len(toks); i++ {
+		ok := true
+		for j, w := range n.words {
+			t := toks[i+j]
+			if n.prefix && j == len(n.words)-1 {
+				ok = strings.HasPrefix(t, w)
+			} else {
+				ok = t == w
+			}
+			if !ok {
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (n textNode) candidates(ix *searchIndex) (map[string]bool, bool) {
+	var out map[string]bool
+	for i, w := range n.words {
+		ids := make(map[string]bool)
+		for _, field := range []string{scopeSubject, scopeBody} {
+			if n.scope != scopeAll && n.scope != field {
+				continue
+			}
+			if n.prefix && i == len(n.words)-1 {
+				ix.eachPrefix(field, w, func(id string) { ids[id] = true })
+			} else {
+				for _, id := range ix.Postings[postingKey(field, w)] {
+					ids[id] = true
+				}
+			}
+		}
+		if i == 0 {
+			out = ids
+		} else {
+			out = intersect(out, ids)
+		}
+	}
+	return out, true
+}
+
+// filterNode matches on message metadata; it never narrows via postings.
+type filterNode struct {
+	fn func(d *indexDoc) bool
+}
+
+func (n filterNode) match(d *indexDoc) bool                        { return n.fn(d) }
+func (filterNode) candidates(*searchIndex) (map[string]bool, bool) { return nil, false }
+
+func intersect(a, b map[string]bool) map[string]bool {
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+	out := make(map[string]bool, len(a))
+	for id := range a {
+		if b[id] {
+			out[id] = true
+		}
+	}
+	return out
+}
+
+// tokenize splits text into lower-case words of letters and digits.
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Lexer
+
+type queryTokKind int
+
+const (
+	tokWord queryTokKind = iota
+	tokPhrase
+	tokLParen
+	tokRParen
+	tokNot // leading '-'
+)
+
+type queryTok struct {
+	kind  queryTokKind
+	field string // for words and phrases written as field:value
+	text  string
+}
+
+func lexQuery(s string) ([]queryTok, error) {
+	var toks []queryTok
+	rs := []rune(s)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			toks = append(toks, queryTok{kind: tokLParen, text: "("})
+			i++
+		case r == ')':
+			toks = append(toks, queryTok{kind: tokRParen, text: ")"})
+			i++
+		case r == '-' && i+1 < len(rs) && !unicode.IsSpace(rs[i+1]) && rs[i+1] != ')':
+			toks = append(toks, queryTok{kind: tokNot, text: "-"})
+			i++
+		case r == '"':
+			phrase, next, err := lexPhrase(rs, i)
+			if err != nil {
+				return nil, err
+			}
+			toks = append(toks, queryTok{kind: tokPhrase, text: phrase})
+			i = next
+		default:
+			start := i
+			for i < len(rs) && !unicode.IsSpace(rs[i]) && rs[i] != '(' && rs[i] != ')' && rs[i] != '"' {
+				i++
+			}
+			word := string(rs[start:i])
+			// field:"quoted value"
+			if strings.HasSuffix(word, ":") && i < len(rs) && rs[i] == '"' {
+				phrase, next, err := lexPhrase(rs, i)
+				if err != nil {
+					return nil, err
+				}
+				toks = append(toks, queryTok{kind: tokPhrase, field: strings.ToLower(strings.TrimSuffix(word, ":")), text: phrase})
+				i = next
+				continue
+			}
+			tok := queryTok{kind: tokWord, text: word}
+			if field, value, ok := strings.Cut(word, ":"); ok && field != "" && value != "" {
+				tok.field, tok.text = strings.ToLower(field), value
+			}
+			toks = append(toks, tok)
+		}
+	}
+	return toks, nil
+}
+
+// lexPhrase reads a double-quoted phrase starting at rs[i].
+func lexPhrase(rs []rune, i int) (string, int, error) {
+	end := i + 1
+	for end < len(rs) && rs[end] != '"' {
+		end++
+	}
+	if end >= len(rs) {
+		return "", 0, fmt.Errorf("unterminated quote in query")
+	}
+	return string(rs[i+1 : end]), end + 1, nil
+}
+
+// Parser
+
+type queryParser struct {
+	toks  []queryTok
+	pos   int
+	scope string
+	now   time.Time
+}
+
+func (p *queryParser) peek() *queryTok {
+	if p.pos < len(p.toks) {
+		return &p.toks[p.pos]
+	}
+	return nil
+}
+
+func (p *queryParser) isKeyword(word string) bool {
+	t := p.peek()
+	return t != nil && t.kind == tokWord && t.field == "" && t.text == word
+}
+
+// parseOr: and { OR and }
+func (p *queryParser) parseOr() (queryNode, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	kids := []queryNode{first}
+	for p.isKeyword("OR") {
+		p.pos++
+		next, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		kids = append(kids, next)
+	}
+	if len(kids) == 1 {
+		return first, nil
+	}
+	return orNode{kids: kids}, nil
+}
+
+// parseAnd: unary { [AND] unary }
+func (p *queryParser) parseAnd() (queryNode, error) {
+	var kids []queryNode
+	for {
+		t := p.peek()
+		if t == nil || t.kind == tokRParen || p.isKeyword("OR") {
+			break
+		}
+		if p.isKeyword("AND") {
+			p.pos++
+			continue
+		}
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		kids = append(kids, n)
+	}
+	switch len(kids) {
+	case 0:
+		if t := p.peek(); t != nil {
+			return nil, fmt.Errorf("unexpected %q in query", t.text)
+		}
+		return nil, fmt.Errorf("query ends with an operator")
+	case 1:
+		return kids[0], nil
+	}
+	return andNode{kids: kids}, nil
+}
+
+// parseUnary: (NOT | -) unary | "(" or ")" | term
+func (p *queryParser) parseUnary() (queryNode, error) {
+	t := p.peek()
+	if t == nil {
+		return nil, fmt.Errorf("query ends with an operator")
+	}
+	if t.kind == tokNot || p.isKeyword("NOT") {
+		p.pos++
+		kid, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{kid: kid}, nil
+	}
+	if t.kind == tokLParen {
+		p.pos++
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.peek(); t == nil || t.kind != tokRParen {
+			return nil, fmt.Errorf("missing ) in query")
+		}
+		p.pos++
+		return n, nil
+	}
+	p.pos++
+	return p.term(t)
+}
+
+func (p *queryParser) term(t *queryTok) (queryNode, error) {
+	switch t.field {
+	case "":
+		return p.text(p.scope, t.text, t.kind == tokPhrase), nil
+	case "subject", "body":
+		return p.text(t.field, t.text, t.kind == tokPhrase), nil
+	}
+
+	value := strings.ToLower(t.text)
+	switch t.field {
+	case "from":
+		return filterNode{func(d *indexDoc) bool {
+			return strings.Contains(strings.ToLower(d.Msg.From), value)
+		}}, nil
+	case "to":
+		return filterNode{func(d *indexDoc) bool {
+			if strings.Contains(strings.ToLower(d.Msg.To), value) {
+				return true
+			}
+			for _, cc := range d.Msg.CC {
+				if strings.Contains(strings.ToLower(cc), value) {
+					return true
+				}
+			}
+			return false
+		}}, nil
+	case "thread":
+		return filterNode{func(d *indexDoc) bool { return d.Msg.ThreadID == t.text }}, nil
+	case "type":
+		return filterNode{func(d *indexDoc) bool { return strings.EqualFold(string(d.Msg.Type), value) }}, nil
+	case "label":
+		return filterNode{func(d *indexDoc) bool {
+			for _, l := range d.Msg.Labels {
+				if strings.EqualFold(l, value) {
+					return true
+				}
+			}
+			return false
+		}}, nil
+	case "priority":
+		return priorityFilter(value)
+	case "before", "after":
+		at, err := parseQueryTime(value, p.now)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", t.field, err)
+		}
+		if t.field == "before" {
+			return filterNode{func(d *indexDoc) bool { return d.Msg.Timestamp.Before(at) }}, nil
+		}
+		return filterNode{func(d *indexDoc) bool { return !d.Msg.Timestamp.Before(at) }}, nil
+	case "is":
+		return isFilter(value)
+	}
+	return nil, fmt.Errorf("unknown search field %q (quote the term to search for it literally)", t.field)
+}
+
+// text builds a word, prefix or phrase node. Words with punctuation
+// ("ci-failure", "gt-abc12") become phrases of their parts.
+func (p *queryParser) text(scope, s string, phrase bool) queryNode {
+	prefix := !phrase && strings.HasSuffix(s, "*")
+	words := tokenize(s)
+	if len(words) == 0 {
+		return allNode{}
+	}
+	return textNode{scope: scope, words: words, prefix: prefix}
+}
+
+// priorityRank orders priorities from low (0) to urgent (3).
+func priorityRank(p Priority) int {
+	return 3 - PriorityToBeads(p)
+}
+
+func priorityFilter(value string) (queryNode, error) {
+	match, err := priorityMatcher(value)
+	if err != nil {
+		return nil, err
+	}
+	return filterNode{func(d *indexDoc) bool { return match(d.Msg.Priority) }}, nil
+}
+
+// priorityMatcher parses a priority condition: a name or 0-4, optionally
+// prefixed with >=, <=, > or <.
+func priorityMatcher(value string) (func(Priority) bool, error) {
+	op := ""
+	for _, o := range []string{">=", "<=", ">", "<"} {
+		if strings.HasPrefix(value, o) {
+			op, value = o, strings.TrimPrefix(value, o)
+			break
+		}
+	}
+	var want Priority
+	if n, err := strconv.Atoi(value); err == nil && n >= 0 && n <= 4 {
+		want = PriorityFromInt(n)
+	} else if ParsePriority(value) == Priority(value) {
+		want = Priority(value)
+	} else {
+		return nil, fmt.Errorf("priority: unknown priority %q (urgent, high, normal, low or 0-4)", value)
+	}
+	rank := priorityRank(want)
+	return func(p Priority) bool {
+		r := priorityRank(p)
+		switch op {
+		case ">=":
+			return r >= rank
+		case "<=":
+			return r <= rank
+		case ">":
+			return r > rank
+		case "<":
+			return r < rank
+		}
+		return r == rank
+	}, nil
+}
+
+func isFilter(value string) (queryNode, error) {
+	var fn func(d *indexDoc) bool
+	switch value {
+	case "unread":
+		fn = func(d *indexDoc) bool { return !d.Msg.Read && !d.Archived && !d.Closed }
+	case "read":
+		fn = func(d *indexDoc) bool { return d.Msg.Read || d.Archived || d.Closed }
+	case "archived":
+		fn = func(d *indexDoc) bool { return d.Archived }
+	case "inbox":
+		fn = func(d *indexDoc) bool { return !d.Archived && !d.Closed }
+	case "pinned":
+		fn = func(d *indexDoc) bool { return d.Msg.Pinned }
+	case "wisp":
+		fn = func(d *indexDoc) bool { return d.Msg.Wisp }
+	default:
+		return nil, fmt.Errorf("is: unknown state %q (unread, read, archived, inbox, pinned, wisp)", value)
+	}
+	return filterNode{fn}, nil
+}
+
+// parseQueryTime accepts a date (2006-01-02), an RFC 3339 timestamp, or an
+// age relative to now such as 30m, 12h, 7d or 2w.
+func parseQueryTime(s string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, strings.ToUpper(s)); err == nil {
+		return t, nil
+	}
+	if len(s) >= 2 {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err == nil && n >= 0 {
+			unit := map[byte]time.Duration{
+				'm': time.Minute,
+				'h': time.Hour,
+				'd': 24 * time.Hour,
+				'w': 7 * 24 * time.Hour,
+			}[s[len(s)-1]]
+			if unit != 0 {
+				return now.Add(-time.Duration(n) * unit), nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (use 2006-01-02 or an age like 7d)", s)
+}
@@ -0,0 +1,253 @@
+package mail
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func queryFixture(now time.Time) []*indexDoc {
+	return []*indexDoc{
+		{Msg: &Message{ID: "m1", From: "gastown/witness", To: "mayor/", Subject: "Polecat nux stuck",
+			Body: "No progress on gt-abc12 for 30 minutes.", Priority: PriorityHigh, Type: TypeNotification,
+			ThreadID: "thread-1", Timestamp: now.Add(-1 * time.Hour)}},
+		{Msg: &Message{ID: "m2", From: "gastown/refinery", To: "mayor/", Subject: "Merge conflict",
+			Body: "Deploy failed after the merge conflict in main.", Priority: PriorityUrgent, Type: TypeTask,
+			ThreadID: "thread-2", Timestamp: now.Add(-48 * time.Hour), Read: true}},
+		{Msg: &Message{ID: "m3", From: "mayor/", To: "gastown/nux", CC: []string{"gastown/witness"},
+			Subject: "Deployment plan", Body: "Failed deploys get retried.", Priority: PriorityLow,
+			Type: TypeTask, ThreadID: "thread-1", Timestamp: now.Add(-10 * 24 * time.Hour)}, Archived: true},
+	}
+}
+
+func TestQuery(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.Local)
+	oldNow := timeNow
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = oldNow }()
+
+	docs := queryFixture(now)
+	ix := newSearchIndex()
+	for _, d := range docs {
+		ix.put(d.Msg, d.Archived)
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", "m1,m2,m3"},
+		{"deploy", "m2"},
+		{"deploy*", "m2,m3"},
+		{`"merge conflict"`, "m2"},
+		{`"conflict merge"`, ""},
+		{"gt-abc12", "m1"},
+		{"subject:deploy*", "m3"},
+		{`body:"deploy failed"`, "m2"},
+		{"from:witness", "m1"},
+		{"to:witness", "m3"},
+		{"thread:thread-1", "m1,m3"},
+		{"type:task", "m2,m3"},
+		{"priority:urgent", "m2"},
+		{"priority:>=high", "m1,m2"},
+		{"priority:3", "m3"},
+		{"after:1d", "m1"},
+		{"before:2025-05-31", "m2,m3"},
+		{"is:unread", "m1"},
+		{"is:archived", "m3"},
+		{"is:read", "m2,m3"},
+		{"deploy* OR stuck", "m1,m2,m3"},
+		{"deploy* -is:archived", "m2"},
+		{"deploy* AND NOT type:task", ""},
+		{"(stuck OR conflict) priority:>=high", "m1,m2"},
+		{"failed or", ""}, // lower-case "or" is a word
+	}
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.query)
+		if err != nil {
+			t.Errorf("ParseQuery(%q): %v", tt.query, err)
+			continue
+		}
+		var got []string
+		for _, m := range ix.search(q) {
+			got = append(got, m.ID)
+		}
+		sort.Strings(got)
+		if strings.Join(got, ",") != tt.want {
+			t.Errorf("search %q = %v, want %s", tt.query, got, tt.want)
+		}
+
+		// Direct matching agrees with the index.
+		var direct []string
+		for _, d := range docs {
+			if q.Match(d.Msg, d.Archived) {
+				direct = append(direct, d.Msg.ID)
+			}
+		}
+		if strings.Join(direct, ",") != tt.want {
+			t.Errorf("Match %q = %v, want %s", tt.query, direct, tt.want)
+		}
+	}
+}
+
+func TestParseQuery_Errors(t *testing.T) {
+	for _, q := range []string{
+		`"unterminated`,
+		"(stuck",
+		"stuck)",
+		"stuck OR",
+		"priority:whenever",
+		"is:snoozed",
+		"after:yesterday",
+		"color:red",
+	} {
+		if _, err := ParseQuery(q); err == nil {
+			t.Errorf("ParseQuery(%q) should fail", q)
+		}
+	}
+}
+
+func TestMailboxSearch_Index(t *testing.T) {
+	m := NewMailbox(t.TempDir())
+	now := time.Now()
+	for _, msg := range []*Message{
+		{ID: "a", From: "gastown/witness", Subject: "Polecat stuck", Body: "nux idle", Timestamp: now.Add(-2 * time.Minute)},
+		{ID: "b", From: "mayor/", Subject: "Handoff", Body: "polecat nux is done", Timestamp: now.Add(-time.Minute)},
+	} {
+		if err := m.Append(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ids := func(opts SearchOptions) string {
+		t.Helper()
+		msgs, err := m.Search(opts)
+		if err != nil {
+			t.Fatalf("Search(%+v): %v", opts, err)
+		}
+		var out []string
+		for _, msg := range msgs {
+			out = append(out, msg.ID)
+		}
+		return strings.Join(out, ",")
+	}
+
+	if got := ids(SearchOptions{Query: "polecat"}); got != "b,a" {
+		t.Errorf("polecat = %q, want newest first b,a", got)
+	}
+	if _, err := os.Stat(m.IndexPath()); err != nil {
+		t.Fatalf("index not written: %v", err)
+	}
+	if got := ids(SearchOptions{Query: "polecat", SubjectOnly: true}); got != "a" {
+		t.Errorf("subject-only = %q", got)
+	}
+	if got := ids(SearchOptions{Query: "nux", FromFilter: "MAYOR"}); got != "b" {
+		t.Errorf("from filter = %q", got)
+	}
+
+	// Appends, reads and archiving update the existing index.
+	if err := m.Append(&Message{ID: "c", From: "deacon/", Subject: "Polecat patrol", Timestamp: now}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.MarkRead("a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Archive("b"); err != nil {
+		t.Fatal(err)
+	}
+	if snap, _ := loadIndex(m.IndexPath()); snap.Docs["c"] != nil {
+		t.Error("append rewrote the index file instead of logging")
+	}
+	ix, _, ok := openIndex(m.IndexPath())
+	if !ok || ix.Docs["c"] == nil || !ix.Docs["a"].Msg.Read || !ix.Docs["b"].Archived {
+		t.Fatalf("index not maintained: %+v", ix.Docs)
+	}
+	if got := ids(SearchOptions{Query: "polecat is:unread"}); got != "c" {
+		t.Errorf("is:unread = %q", got)
+	}
+	if got := ids(SearchOptions{Query: "is:archived"}); got != "b" {
+		t.Errorf("is:archived = %q", got)
+	}
+
+	// Purging the archive is noticed because the file shrank.
+	if _, err := m.PurgeArchive(0); err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(SearchOptions{Query: "polecat"}); got != "c,a" {
+		t.Errorf("after purge = %q", got)
+	}
+
+	// Changes made behind the index's back are picked up by a reindex.
+	if err := os.Remove(m.path); err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(SearchOptions{Query: "polecat"}); got != "c,a" {
+		t.Errorf("stale index = %q, want cached results", got)
+	}
+	if got := ids(SearchOptions{Query: "polecat", Reindex: true}); got != "" {
+		t.Errorf("after reindex = %q, want none", got)
+	}
+}
+
+func TestMailboxSearch_CompactsLog(t *testing.T) {
+	m := NewMailbox(t.TempDir())
+	if err := m.Append(&Message{ID: "a", Subject: "first", Timestamp: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Search(SearchOptions{Query: "first"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < indexCompactOps; i++ {
+		m.logIndex(indexOp{Op: opRead, ID: "a"})
+	}
+	got, err := m.Search(SearchOptions{Query: "first is:read"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Errorf("is:read = %d messages, want 1", len(got))
+	}
+	if _, err := os.Stat(indexLogPath(m.IndexPath())); !os.IsNotExist(err) {
+		t.Errorf("log not truncated after compaction: %v", err)
+	}
+	ix, _ := loadIndex(m.IndexPath())
+	if doc := ix.Docs["a"]; doc == nil || !doc.Msg.Read {
+		t.Errorf("compacted index = %+v", ix.Docs)
+	}
+}
+
+func TestSearchIndex_ClosedStaysSearchable(t *testing.T) {
+	ix := newSearchIndex()
+	now := time.Now()
+	ix.put(&Message{ID: "a", Subject: "Polecat stuck", Timestamp: now}, false)
+	ix.put(&Message{ID: "b", Subject: "Polecat done", Timestamp: now}, false)
+
+	ix.apply(&indexOp{Op: opClose, ID: "a"})
+	// b was closed behind the index's back; a beads re-listing misses it.
+	ix.syncInbox(nil, now, true)
+
+	search := func(query string) int {
+		q, err := ParseQuery(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(ix.search(q))
+	}
+	if n := search("polecat"); n != 2 {
+		t.Errorf("polecat = %d, want closed messages kept", n)
+	}
+	if n := search("polecat is:inbox"); n != 0 {
+		t.Errorf("is:inbox = %d, want 0", n)
+	}
+	if n := search("polecat is:read"); n != 2 {
+		t.Errorf("is:read = %d, want 2", n)
+	}
+
+	ix.apply(&indexOp{Op: opReopen, ID: "a"})
+	if n := search("polecat is:unread"); n != 1 {
+		t.Errorf("is:unread after reopen = %d, want 1", n)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
@@ -31,6 +32,7 @@ type Router struct {
 	workDir  string // fallback directory to run bd commands in
 	townRoot string // town root directory (e.g., ~/gt)
 	tmux     *tmux.Tmux
+	store    beads.Store // message closes; nil = bd in the resolved beads dir
 }
 
 // NewRouter creates a new mail router.
@@ -56,6 +58,14 @@ func NewRouterWithTownRoot(workDir, townRoot string) *Router {
 	}
 }
 
+// issues returns the store that closes messages in beadsDir.
+func (r *Router) issues(beadsDir string) beads.Store {
+	if r.store != nil {
+		return r.store
+	}
+	return beads.NewWithBeadsDir(filepath.Dir(beadsDir), beadsDir)
+}
+
 // isListAddress returns true if the address uses list:name syntax.
 func isListAddress(address string) bool {
 	return strings.HasPrefix(address, "list:")
@@ -578,6 +588,26 @@ func (r *Router) queryAgents(descContains string) []*agentBead {
 
 // queryAgentsInDir queries agent beads in a specific beads directory with optional description filtering.
 func (r *Router) queryAgentsInDir(beadsDir, descContains string) ([]*agentBead, error) {
+	if issues, ok := beads.ListAgentsSQL(beadsDir); ok {
+		var active []*agentBead
+		for _, issue := range issues {
+			if issue.Status != "open" && issue.Status != "in_progress" {
+				continue
+			}
+			if descContains != "" && !strings.Contains(strings.ToLower(issue.Description), strings.ToLower(descContains)) {
+				continue
+			}
+			active = append(active, &agentBead{
+				ID:          issue.ID,
+				Title:       issue.Title,
+				Description: issue.Description,
+				Status:      issue.Status,
+				CreatedBy:   issue.CreatedBy,
+			})
+		}
+		return active, nil
+	}
+
 	args := []string{"list", "--type=agent", "--json", "--limit=0"}
 
 	if descContains != "" {
@@ -643,6 +673,16 @@ func (r *Router) shouldBeWisp(msg *Message) bool {
 // - Queues (queue:name) - stores single message for worker claiming
 // - Announces (announce:name) - bulletin board, no claiming, retention-limited
 func (r *Router) Send(msg *Message) error {
+	// Deferred and recurring messages wait in the schedule
+	now := time.Now()
+	if msg.Recurrence != "" || (msg.DeliverAt != nil && msg.DeliverAt.After(now)) {
+		_, err := r.Schedule(msg)
+		return err
+	}
+	if msg.Expired(now) {
+		return ErrMessageExpired
+	}
+
 	// Check for mailing list address
 	if isListAddress(msg.To) {
 		return r.sendToList(msg)
@@ -763,6 +803,9 @@ func (r *Router) sendToSingle(msg *Message) error {
 		return fmt.Errorf("invalid recipient %q: %w", msg.To, err)
 	}
 
+	// Apply the recipient's filter rules (labels, priority, delivery)
+	msg, outcome := r.applyRules(msg)
+
 	// Build labels for from/thread/reply-to/cc
 	var labels []string
 	labels = append(labels, "from:"+msg.From)
@@ -777,12 +820,22 @@ func (r *Router) sendToSingle(msg *Message) error {
 		ccIdentity := AddressToIdentity(cc)
 		labels = append(labels, "cc:"+ccIdentity)
 	}
+	if msg.ExpiresAt != nil {
+		labels = append(labels, "expires-at:"+msg.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+	if msg.Type != "" && msg.Type != TypeNotification {
+		labels = append(labels, "msg-type:"+string(msg.Type))
+	}
+	for _, l := range msg.Labels {
+		labels = append(labels, "label:"+l)
+	}
 
 	// Build command: bd create <subject> --type=message --assignee=<recipient> -d <body>
 	args := []string{"create", msg.Subject,
 		"--type", "message",
 		"--assignee", toIdentity,
 		"-d", msg.Body,
+		"--json",
 	}
 
 	// Add priority flag
@@ -806,20 +859,61 @@ func (r *Router) sendToSingle(msg *Message) error {
 	if err := r.ensureCustomTypes(beadsDir); err != nil {
 		return err
 	}
-	_, err := runBdCommand(args, filepath.Dir(beadsDir), beadsDir)
+	out, err := runBdCommand(args, filepath.Dir(beadsDir), beadsDir)
 	if err != nil {
 		return fmt.Errorf("sending message: %w", err)
 	}
+	id := createdID(out)
+	r.indexDelivered(msg, id, beadsDir)
+	if outcome != nil {
+		r.runRuleActions(msg, id, outcome, beadsDir)
+		if outcome.Archive {
+			return nil // Filed away: nothing to notify about
+		}
+	}
 
 	// Notify recipient if they have an active session (best-effort notification)
-	// Skip notification for self-mail (handoffs to future-self don't need present-self notified)
-	if !isSelfMail(msg.From, msg.To) {
+	// Skip notification for self-mail (handoffs to future-self don't need present-self notified),
+	// unless a rule asked for an interrupt
+	if msg.Delivery == DeliveryInterrupt {
+		_ = r.interruptRecipient(msg, id)
+	} else if !isSelfMail(msg.From, msg.To) {
 		_ = r.notifyRecipient(msg)
 	}
 
 	return nil
 }
 
+// createdID returns the issue ID from bd create --json output, or "".
+func createdID(createOutput []byte) string {
+	var created struct {
+		ID string `json:"id"`
+	}
+	if json.Unmarshal(createOutput, &created) != nil {
+		return ""
+	}
+	return created.ID
+}
+
+// indexDelivered adds a just-created message to the search indexes of its
+// recipient and CC recipients, for those that have one. Without an ID in
+// bd's output the indexes catch up on their next refresh.
+func (r *Router) indexDelivered(msg *Message, id, beadsDir string) {
+	if id == "" {
+		return
+	}
+	indexed := *msg
+	indexed.ID = id
+	if indexed.Timestamp.IsZero() {
+		indexed.Timestamp = time.Now()
+	}
+	for _, addr := range append([]string{msg.To}, msg.CC...) {
+		copyForBox := indexed
+		NewMailboxWithBeadsDir(addr, filepath.Dir(beadsDir), beadsDir).
+			logIndex(indexOp{Op: opPut, Msg: &copyForBox})
+	}
+}
+
 // sendToList expands a mailing list and sends individual copies to each recipient.
 // Each recipient gets their own message copy with the same content.
 // Collects all delivery errors and reports partial failures.
@@ -887,6 +981,9 @@ func (r *Router) sendToQueue(msg *Message) error {
 		ccIdentity := AddressToIdentity(cc)
 		labels = append(labels, "cc:"+ccIdentity)
 	}
+	if msg.ExpiresAt != nil {
+		labels = append(labels, "expires-at:"+msg.ExpiresAt.UTC().Format(time.RFC3339))
+	}
 
 	// Build command: bd create <subject> --type=message --assignee=queue:<name> -d <body>
 	// Use queue:<name> as assignee so inbox queries can filter by queue
@@ -961,6 +1058,9 @@ func (r *Router) sendToAnnounce(msg *Message) error {
 		ccIdentity := AddressToIdentity(cc)
 		labels = append(labels, "cc:"+ccIdentity)
 	}
+	if msg.ExpiresAt != nil {
+		labels = append(labels, "expires-at:"+msg.ExpiresAt.UTC().Format(time.RFC3339))
+	}
 
 	// Build command: bd create <subject> --type=message --assignee=announce:<name> -d <body>
 	// Use announce:<name> as assignee so queries can filter by channel
@@ -1037,6 +1137,9 @@ func (r *Router) sendToChannel(msg *Message) error {
 		ccIdentity := AddressToIdentity(cc)
 		labels = append(labels, "cc:"+ccIdentity)
 	}
+	if msg.ExpiresAt != nil {
+		labels = append(labels, "expires-at:"+msg.ExpiresAt.UTC().Format(time.RFC3339))
+	}
 
 	// Build command: bd create <subject> --type=message --assignee=channel:<name> -d <body>
 	// Use channel:<name> as assignee so queries can filter by channel
@@ -1146,10 +1249,10 @@ func (r *Router) pruneAnnounce(announceName string, retainCount int) error {
 	}
 
 	// Delete oldest messages
+	store := r.issues(beadsDir)
 	for i := 0; i < toDelete && i < len(messages); i++ {
-		deleteArgs := []string{"close", messages[i].ID, "--reason=retention pruning"}
 		// Best-effort deletion - don't fail if one delete fails
-		_, _ = runBdCommand(deleteArgs, filepath.Dir(beadsDir), beadsDir)
+		_ = store.CloseWithReason("retention pruning", messages[i].ID)
 	}
 
 	return nil
@@ -1197,6 +1300,24 @@ func (r *Router) notifyRecipient(msg *Message) error {
 	return nil // No active session found
 }
 
+// interruptRecipient nudges the recipient's session to read a message now,
+// for mail delivered as an interrupt.
+func (r *Router) interruptRecipient(msg *Message, id string) error {
+	for _, sessionID := range addressToSessionIDs(msg.To) {
+		hasSession, err := r.tmux.HasSession(sessionID)
+		if err != nil || !hasSession {
+			continue
+		}
+		read := "gt mail inbox"
+		if id != "" {
+			read = "gt mail read " + id
+		}
+		notification := fmt.Sprintf("🚨 Interrupting mail from %s. Subject: %s. Run '%s' now.", msg.From, msg.Subject, read)
+		return r.tmux.NudgeSession(sessionID, notification)
+	}
+	return nil
+}
+
 // addressToSessionIDs converts a mail address to possible tmux session IDs.
 // Returns multiple candidates since the canonical address format (rig/name)
 // doesn't distinguish between crew workers (gt-rig-crew-name) and polecats
@@ -0,0 +1,431 @@
+package mail
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// rulesWarnings receives the warnings printed when a mailbox's rules can't
+// be loaded at delivery time.
+var rulesWarnings io.Writer = os.Stderr
+
+// RulesDir returns the directory holding per-mailbox filter rules.
+func RulesDir(townRoot string) string {
+	return filepath.Join(townRoot, "config", "mail-rules")
+}
+
+// RulesPath returns the filter rules file for a mailbox address, e.g.
+// config/mail-rules/gastown_witness.json.
+func RulesPath(townRoot, address string) string {
+	name := strings.Trim(strings.ReplaceAll(AddressToIdentity(address), "/", "_"), "_")
+	if name == "" {
+		name = "default"
+	}
+	return filepath.Join(RulesDir(townRoot), name+".json")
+}
+
+// RuleSet is the ordered list of filter rules for one mailbox. Rules are
+// evaluated in order when mail is delivered; every matching rule fires
+// until one with Stop set.
+type RuleSet struct {
+	Rules []*Rule `json:"rules"`
+}
+
+// Rule matches incoming mail and acts on it.
+type Rule struct {
+	Name     string      `json:"name"`
+	Disabled bool        `json:"disabled,omitempty"`
+	Match    RuleMatch   `json:"match"`
+	Actions  RuleActions `json:"actions"`
+
+	// Stop ends evaluation after this rule fires.
+	Stop bool `json:"stop,omitempty"`
+
+	subject *regexp.Regexp
+	from    *regexp.Regexp
+	cc      *regexp.Regexp
+	prio    func(Priority) bool
+}
+
+// RuleMatch lists the conditions a message must meet; all set conditions
+// must hold, and a rule without conditions matches every message.
+// Address patterns are case-insensitive globs where * matches anything
+// ("*/witness", "gastown/*").
+type RuleMatch struct {
+	// From matches the sender address.
+	From string `json:"from,omitempty"`
+
+	// Subject is a case-insensitive glob, or a regular expression
+	// between slashes ("/^CI (failed|broken)/").
+	Subject string `json:"subject,omitempty"`
+
+	// Type is the message type (task, scavenge, notification, reply).
+	Type MessageType `json:"type,omitempty"`
+
+	// Priority is a priority or comparison: "urgent", ">=high", "<normal".
+	Priority string `json:"priority,omitempty"`
+
+	// CC matches any CC'd address.
+	CC string `json:"cc,omitempty"`
+}
+
+// RuleActions is what a rule does to a matching message.
+type RuleActions struct {
+	// Archive files the message straight into the archive; it never
+	// shows in the inbox.
+	Archive bool `json:"archive,omitempty"`
+
+	// Labels tags the message (searchable with label:X).
+	Labels []string `json:"labels,omitempty"`
+
+	// Forward sends a copy to another address or queue:name.
+	Forward string `json:"forward,omitempty"`
+
+	// Priority raises the message to at least this priority.
+	Priority Priority `json:"priority,omitempty"`
+
+	// Interrupt delivers the message as an interrupt, nudging the
+	// recipient's session even for self-mail.
+	Interrupt bool `json:"interrupt,omitempty"`
+
+	// Reply sends an automatic reply to the sender.
+	Reply *AutoReply `json:"reply,omitempty"`
+}
+
+// AutoReply is an automatic reply. Replies are never sent in answer to
+// replies, so two auto-replying mailboxes cannot loop.
+type AutoReply struct {
+	// Subject defaults to "Re: <original subject>".
+	Subject string `json:"subject,omitempty"`
+	Body    string `json:"body"`
+}
+
+// LoadRules reads a mailbox's filter rules. A mailbox without a rules
+// file has an empty rule set.
+func LoadRules(townRoot, address string) (*RuleSet, error) {
+	path := RulesPath(townRoot, address)
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is constructed internally
+	if os.IsNotExist(err) {
+		return &RuleSet{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var rs RuleSet
+	if err := json.Unmarshal(data, &rs); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if err := rs.compile(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &rs, nil
+}
+
+// compile validates the rules and prepares their patterns.
+func (rs *RuleSet) compile() error {
+	for i, rule := range rs.Rules {
+		if rule == nil {
+			return fmt.Errorf("rule %d is empty", i+1)
+		}
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule-%d", i+1)
+		}
+		if err := rule.compile(); err != nil {
+			return fmt.Errorf("rule %q: %w", rule.Name, err)
+		}
+	}
+	return nil
+}
+
+func (r *Rule) compile() error {
+	var err error
+	m := r.Match
+	if m.From != "" {
+		r.from = globRegexp(m.From)
+	}
+	if m.CC != "" {
+		r.cc = globRegexp(m.CC)
+	}
+	if s := m.Subject; len(s) > 1 && strings.HasPrefix(s, "/") && strings.HasSuffix(s, "/") {
+		if r.subject, err = regexp.Compile("(?i)" + s[1:len(s)-1]); err != nil {
+			return fmt.Errorf("subject: %w", err)
+		}
+	} else if s != "" {
+		r.subject = globRegexp(s)
+	}
+	if m.Type != "" && ParseMessageType(string(m.Type)) != m.Type {
+		return fmt.Errorf("unknown message type %q", m.Type)
+	}
+	if m.Priority != "" {
+		if r.prio, err = priorityMatcher(m.Priority); err != nil {
+			return err
+		}
+	}
+
+	a := r.Actions
+	if !a.Archive && len(a.Labels) == 0 && a.Forward == "" && a.Priority == "" && !a.Interrupt && a.Reply == nil {
+		return fmt.Errorf("no actions")
+	}
+	for _, l := range a.Labels {
+		if l == "" || strings.ContainsAny(l, ", ") {
+			return fmt.Errorf("invalid label %q", l)
+		}
+	}
+	if a.Priority != "" && ParsePriority(string(a.Priority)) != a.Priority {
+		return fmt.Errorf("unknown priority %q", a.Priority)
+	}
+	if a.Reply != nil && a.Reply.Body == "" {
+		return fmt.Errorf("reply has no body")
+	}
+	return nil
+}
+
+// globRegexp compiles a case-insensitive glob where * matches any run of
+// characters and ? any one.
+func globRegexp(glob string) *regexp.Regexp {
+	parts := strings.Split(glob, "*")
+	for i, part := range parts {
+		parts[i] = strings.ReplaceAll(regexp.QuoteMeta(part), `\?`, ".")
+	}
+	return regexp.MustCompile("(?i)^" + strings.Join(parts, ".*") + "$")
+}
+
+// matchAddress matches an address pattern with or without the trailing
+// slash of town-level addresses ("mayor/").
+func matchAddress(re *regexp.Regexp, address string) bool {
+	return re.MatchString(address) || re.MatchString(strings.TrimSuffix(address, "/"))
+}
+
+// explain checks each condition of the rule against msg and describes
+// the result.
+func (r *Rule) explain(msg *Message) (bool, []string) {
+	matched := true
+	var reasons []string
+	check := func(ok bool, what, value, pattern string) {
+		verb := "matches"
+		if !ok {
+			verb = "does not match"
+			matched = false
+		}
+		reasons = append(reasons, fmt.Sprintf("%s %q %s %q", what, value, verb, pattern))
+	}
+
+	if r.from != nil {
+		check(matchAddress(r.from, msg.From), "from", msg.From, r.Match.From)
+	}
+	if r.subject != nil {
+		check(r.subject.MatchString(msg.Subject), "subject", msg.Subject, r.Match.Subject)
+	}
+	if r.Match.Type != "" {
+		msgType := msg.Type
+		if msgType == "" {
+			msgType = TypeNotification
+		}
+		check(msgType == r.Match.Type, "type", string(msgType), string(r.Match.Type))
+	}
+	if r.prio != nil {
+		p := msg.Priority
+		if p == "" {
+			p = PriorityNormal
+		}
+		check(r.prio(p), "priority", string(p), r.Match.Priority)
+	}
+	if r.cc != nil {
+		ok := false
+		for _, cc := range msg.CC {
+			if matchAddress(r.cc, cc) {
+				ok = true
+				break
+			}
+		}
+		check(ok, "cc", strings.Join(msg.CC, ","), r.Match.CC)
+	}
+	if len(reasons) == 0 {
+		reasons = append(reasons, "no conditions (matches every message)")
+	}
+	return matched, reasons
+}
+
+// Describe summarizes the rule's actions.
+func (a RuleActions) Describe() []string {
+	var out []string
+	if a.Archive {
+		out = append(out, "archive")
+	}
+	if len(a.Labels) > 0 {
+		out = append(out, "label "+strings.Join(a.Labels, ", "))
+	}
+	if a.Priority != "" {
+		out = append(out, "raise priority to "+string(a.Priority))
+	}
+	if a.Interrupt {
+		out = append(out, "deliver as interrupt")
+	}
+	if a.Forward != "" {
+		out = append(out, "forward to "+a.Forward)
+	}
+	if a.Reply != nil {
+		out = append(out, "auto-reply")
+	}
+	return out
+}
+
+// RuleTrace is one rule's verdict on a message.
+type RuleTrace struct {
+	Rule    *Rule    `json:"rule"`
+	Fired   bool     `json:"fired"`
+	Reasons []string `json:"reasons"`
+}
+
+// Explain evaluates every rule against msg and reports why each fired or
+// not, in order.
+func (rs *RuleSet) Explain(msg *Message) []RuleTrace {
+	traces := make([]RuleTrace, 0, len(rs.Rules))
+	stoppedBy := ""
+	for _, rule := range rs.Rules {
+		switch {
+		case stoppedBy != "":
+			traces = append(traces, RuleTrace{Rule: rule, Reasons: []string{fmt.Sprintf("skipped: %q stopped evaluation", stoppedBy)}})
+		case rule.Disabled:
+			traces = append(traces, RuleTrace{Rule: rule, Reasons: []string{"disabled"}})
+		default:
+			fired, reasons := rule.explain(msg)
+			traces = append(traces, RuleTrace{Rule: rule, Fired: fired, Reasons: reasons})
+			if fired && rule.Stop {
+				stoppedBy = rule.Name
+			}
+		}
+	}
+	return traces
+}
+
+// RuleOutcome is the combined effect of the rules that fired for a message.
+type RuleOutcome struct {
+	Fired     []string
+	Archive   bool
+	Labels    []string
+	Priority  Priority
+	Interrupt bool
+	Forward   []string
+	Replies   []*AutoReply
+}
+
+// Evaluate returns the combined actions of the rules that fire for msg,
+// or nil if none do.
+func (rs *RuleSet) Evaluate(msg *Message) *RuleOutcome {
+	var out *RuleOutcome
+	for _, t := range rs.Explain(msg) {
+		if !t.Fired {
+			continue
+		}
+		if out == nil {
+			out = &RuleOutcome{}
+		}
+		a := t.Rule.Actions
+		out.Fired = append(out.Fired, t.Rule.Name)
+		out.Archive = out.Archive || a.Archive
+		out.Interrupt = out.Interrupt || a.Interrupt
+		for _, l := range a.Labels {
+			if !slices.Contains(out.Labels, l) {
+				out.Labels = append(out.Labels, l)
+			}
+		}
+		if a.Priority != "" && (out.Priority == "" || priorityRank(a.Priority) > priorityRank(out.Priority)) {
+			out.Priority = a.Priority
+		}
+		if a.Forward != "" && !slices.Contains(out.Forward, a.Forward) {
+			out.Forward = append(out.Forward, a.Forward)
+		}
+		if a.Reply != nil {
+			out.Replies = append(out.Replies, a.Reply)
+		}
+	}
+	return out
+}
+
+// apply updates msg with the outcome's labels, priority and delivery.
+func (o *RuleOutcome) apply(msg *Message) {
+	for _, l := range o.Labels {
+		if !slices.Contains(msg.Labels, l) {
+			msg.Labels = append(msg.Labels, l)
+		}
+	}
+	if o.Priority != "" && priorityRank(o.Priority) > priorityRank(msg.Priority) {
+		msg.Priority = o.Priority
+	}
+	if o.Interrupt {
+		msg.Delivery = DeliveryInterrupt
+	}
+}
+
+// applyRules runs the recipient's filter rules on a message about to be
+// delivered. It returns the message to deliver (a copy, if rules changed
+// it) and the outcome, or msg and nil when no rule fires. A rules file
+// that fails to load doesn't block mail: the message is delivered
+// unfiltered with a warning naming the error.
+func (r *Router) applyRules(msg *Message) (*Message, *RuleOutcome) {
+	if msg.viaRule || r.townRoot == "" {
+		return msg, nil
+	}
+	rs, err := LoadRules(r.townRoot, msg.To)
+	if err != nil {
+		fmt.Fprintf(rulesWarnings, "Warning: mail rules for %s not applied: %v\n", msg.To, err)
+		return msg, nil
+	}
+	if len(rs.Rules) == 0 {
+		return msg, nil
+	}
+	outcome := rs.Evaluate(msg)
+	if outcome == nil {
+		return msg, nil
+	}
+	filtered := *msg
+	filtered.Labels = append([]string(nil), msg.Labels...)
+	outcome.apply(&filtered)
+	return &filtered, outcome
+}
+
+// runRuleActions carries out the rule actions that follow delivery of
+// msg under the bead ID id: archiving, forwarding and auto-replies. They
+// are best effort; the message itself has already been delivered.
+func (r *Router) runRuleActions(msg *Message, id string, outcome *RuleOutcome, beadsDir string) {
+	if outcome.Archive && id != "" {
+		_ = NewMailboxWithBeadsDir(msg.To, filepath.Dir(beadsDir), beadsDir).Archive(id)
+	}
+
+	for _, to := range outcome.Forward {
+		fwd := *msg
+		fwd.ID = ""
+		fwd.To = to
+		fwd.CC = nil
+		fwd.Delivery = ""
+		fwd.viaRule = true
+		if !strings.HasPrefix(strings.ToLower(fwd.Subject), "fwd:") {
+			fwd.Subject = "Fwd: " + msg.Subject
+		}
+		fwd.Body = fmt.Sprintf("Forwarded by a mail rule of %s (originally from %s).\n\n%s", msg.To, msg.From, msg.Body)
+		_ = r.Send(&fwd)
+	}
+
+	// Never answer replies or yourself: two auto-repliers would loop.
+	if msg.Type == TypeReply || msg.From == "" || isSelfMail(msg.From, msg.To) {
+		return
+	}
+	original := *msg
+	original.ID = id
+	for _, ar := range outcome.Replies {
+		subject := ar.Subject
+		if subject == "" {
+			subject = "Re: " + msg.Subject
+		}
+		reply := NewReplyMessage(msg.To, msg.From, subject, ar.Body, &original)
+		reply.viaRule = true
+		_ = r.Send(reply)
+	}
+}
@@ -0,0 +1,204 @@
+package mail
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeRules(t *testing.T, townRoot, address, body string) {
+	t.Helper()
+	path := RulesPath(townRoot, address)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+const testRules = `{
+  "rules": [
+    {"name": "off", "disabled": true, "actions": {"archive": true}},
+    {"name": "escalations", "match": {"subject": "/^(escalat|blocked)/", "priority": ">=normal"},
+     "actions": {"priority": "urgent", "interrupt": true, "labels": ["hot"]}},
+    {"name": "patrol", "match": {"from": "deacon", "subject": "patrol*"},
+     "actions": {"archive": true, "labels": ["patrol"]}, "stop": true},
+    {"name": "reviews", "match": {"type": "task", "cc": "*/refinery"},
+     "actions": {"forward": "queue:merges", "reply": {"body": "Queued for review."}}},
+    {"match": {}, "actions": {"labels": ["seen"]}}
+  ]
+}`
+
+func TestRulesPath(t *testing.T) {
+	if got := RulesPath("/town", "gastown/witness"); got != filepath.Join("/town", "config", "mail-rules", "gastown_witness.json") {
+		t.Errorf("RulesPath = %s", got)
+	}
+	if got := RulesPath("/town", "mayor/"); filepath.Base(got) != "mayor.json" {
+		t.Errorf("RulesPath(mayor/) = %s", got)
+	}
+}
+
+func TestRuleSet_Evaluate(t *testing.T) {
+	townRoot := t.TempDir()
+	writeRules(t, townRoot, "gastown/witness", testRules)
+	rs, err := LoadRules(townRoot, "gastown/witness")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rs.Rules[4].Name != "rule-5" {
+		t.Errorf("unnamed rule = %q", rs.Rules[4].Name)
+	}
+
+	tests := []struct {
+		name  string
+		msg   *Message
+		fired []string
+		check func(*RuleOutcome) bool
+	}{
+		{
+			name:  "escalation raised to urgent interrupt",
+			msg:   &Message{From: "gastown/nux", Subject: "Blocked on merge", Priority: PriorityHigh},
+			fired: []string{"escalations", "rule-5"},
+			check: func(o *RuleOutcome) bool {
+				return o.Priority == PriorityUrgent && o.Interrupt && strings.Join(o.Labels, ",") == "hot,seen"
+			},
+		},
+		{
+			name:  "low priority escalation is ignored",
+			msg:   &Message{From: "gastown/nux", Subject: "escalation", Priority: PriorityLow},
+			fired: []string{"rule-5"},
+		},
+		{
+			name:  "patrol archived and stops",
+			msg:   &Message{From: "deacon/", Subject: "Patrol report"},
+			fired: []string{"patrol"},
+			check: func(o *RuleOutcome) bool { return o.Archive && len(o.Labels) == 1 },
+		},
+		{
+			name:  "task cc'd to refinery forwarded and answered",
+			msg:   &Message{From: "gastown/nux", Subject: "MR ready", Type: TypeTask, CC: []string{"gastown/refinery"}},
+			fired: []string{"reviews", "rule-5"},
+			check: func(o *RuleOutcome) bool { return len(o.Forward) == 1 && len(o.Replies) == 1 },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := rs.Evaluate(tt.msg)
+			if o == nil {
+				t.Fatal("no rules fired")
+			}
+			if strings.Join(o.Fired, ",") != strings.Join(tt.fired, ",") {
+				t.Errorf("fired %v, want %v", o.Fired, tt.fired)
+			}
+			if tt.check != nil && !tt.check(o) {
+				t.Errorf("outcome = %+v", o)
+			}
+		})
+	}
+}
+
+func TestRuleSet_Explain(t *testing.T) {
+	townRoot := t.TempDir()
+	writeRules(t, townRoot, "mayor/", testRules)
+	rs, err := LoadRules(townRoot, "mayor/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	traces := rs.Explain(&Message{From: "deacon/", Subject: "Patrol report"})
+	if len(traces) != 5 {
+		t.Fatalf("got %d traces", len(traces))
+	}
+	if traces[0].Fired || traces[0].Reasons[0] != "disabled" {
+		t.Errorf("disabled rule: %+v", traces[0])
+	}
+	if traces[1].Fired || !strings.Contains(strings.Join(traces[1].Reasons, ";"), `subject "Patrol report" does not match`) {
+		t.Errorf("escalations: %+v", traces[1])
+	}
+	if !traces[2].Fired {
+		t.Errorf("patrol should fire: %+v", traces[2])
+	}
+	if traces[4].Fired || !strings.Contains(traces[4].Reasons[0], "stopped evaluation") {
+		t.Errorf("rule after stop: %+v", traces[4])
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	townRoot := t.TempDir()
+	rs, err := LoadRules(townRoot, "gastown/nux")
+	if err != nil || len(rs.Rules) != 0 {
+		t.Fatalf("missing file: %+v, %v", rs, err)
+	}
+
+	for _, bad := range []string{
+		`{"rules": [{"name": "x", "match": {"from": "a"}}]}`,
+		`{"rules": [{"name": "x", "match": {"subject": "/(/"}, "actions": {"archive": true}}]}`,
+		`{"rules": [{"name": "x", "match": {"priority": "soon"}, "actions": {"archive": true}}]}`,
+		`{"rules": [{"name": "x", "match": {"type": "memo"}, "actions": {"archive": true}}]}`,
+		`{"rules": [{"name": "x", "actions": {"priority": "critical"}}]}`,
+		`{"rules": [{"name": "x", "actions": {"labels": ["a,b"]}}]}`,
+		`{"rules": [{"name": "x", "actions": {"reply": {}}}]}`,
+		`{"rules": [`,
+	} {
+		writeRules(t, townRoot, "gastown/nux", bad)
+		if _, err := LoadRules(townRoot, "gastown/nux"); err == nil {
+			t.Errorf("LoadRules(%s) should fail", bad)
+		}
+	}
+}
+
+func TestRouterApplyRules(t *testing.T) {
+	townRoot := t.TempDir()
+	writeRules(t, townRoot, "gastown/witness", testRules)
+	r := &Router{workDir: townRoot, townRoot: townRoot}
+
+	msg := &Message{From: "gastown/nux", To: "gastown/witness", Subject: "Blocked", Priority: PriorityNormal}
+	got, outcome := r.applyRules(msg)
+	if outcome == nil || got == msg {
+		t.Fatal("rules should apply to a copy")
+	}
+	if got.Priority != PriorityUrgent || got.Delivery != DeliveryInterrupt || strings.Join(got.Labels, ",") != "hot,seen" {
+		t.Errorf("filtered = %+v", got)
+	}
+	if msg.Priority != PriorityNormal || msg.Labels != nil {
+		t.Errorf("original changed: %+v", msg)
+	}
+
+	// Copies sent by rules bypass the rules of their recipient.
+	msg.viaRule = true
+	if got, outcome := r.applyRules(msg); outcome != nil || got != msg {
+		t.Error("rule copies should not be filtered")
+	}
+
+	// A broken rules file never blocks delivery, but it is reported.
+	var warnings bytes.Buffer
+	rulesWarnings = &warnings
+	t.Cleanup(func() { rulesWarnings = os.Stderr })
+	writeRules(t, townRoot, "gastown/witness", `{"rules": [`)
+	msg.viaRule = false
+	if got, outcome := r.applyRules(msg); outcome != nil || got != msg {
+		t.Error("broken rules should not filter the message")
+	}
+	if !strings.Contains(warnings.String(), "gastown_witness.json") {
+		t.Errorf("warning = %q, want the broken rules file named", warnings.String())
+	}
+}
+
+func TestBeadsMessage_Labels(t *testing.T) {
+	bm := BeadsMessage{ID: "hq-1", Labels: []string{"from:mayor/", "label:ci", "cc:gastown/nux", "label:hot"}}
+	bm.ParseLabels()
+	msg := bm.ToMessage() // Parses again
+	if strings.Join(msg.Labels, ",") != "ci,hot" || len(msg.CC) != 1 {
+		t.Errorf("labels %v, cc %v", msg.Labels, msg.CC)
+	}
+
+	q, err := ParseQuery("label:CI")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !q.Match(msg, false) {
+		t.Error("label:CI should match")
+	}
+}
@@ -0,0 +1,491 @@
+package mail
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// ErrScheduleNotFound indicates a scheduled message ID was not found.
+var ErrScheduleNotFound = errors.New("scheduled message not found")
+
+// minRecurrence is the shortest allowed recurrence interval.
+const minRecurrence = time.Minute
+
+// maxReleaseAttempts is how many release passes in a row may fail to
+// deliver an occurrence before it is given up: a one-shot message is
+// marked failed and kept for 'gt mail scheduled' to show, a recurring one
+// skips to its next time.
+const maxReleaseAttempts = 10
+
+// releaseLease is how long a release pass holds the entries it is
+// delivering, so a concurrent pass doesn't send them too.
+const releaseLease = 5 * time.Minute
+
+// ScheduledMessage is a message held back for later delivery.
+// Message.DeliverAt is when it is next due.
+type ScheduledMessage struct {
+	ID         string    `json:"id"`
+	Message    *Message  `json:"message"`
+	Recipients []string  `json:"recipients,omitempty"` // Concrete addresses, resolved when scheduled
+	CreatedAt  time.Time `json:"created_at"`
+	Deliveries int       `json:"deliveries,omitempty"`
+	LastError  string    `json:"last_error,omitempty"`
+
+	Sent     []string `json:"sent,omitempty"`     // Recipients that have the current occurrence
+	Attempts int      `json:"attempts,omitempty"` // Failed releases of the current occurrence
+	Failed   bool     `json:"failed,omitempty"`   // Given up after maxReleaseAttempts
+
+	ClaimedUntil *time.Time `json:"claimed_until,omitempty"` // Held by a release pass
+}
+
+// recipients returns the addresses each occurrence goes to.
+func (e *ScheduledMessage) recipients() []string {
+	if len(e.Recipients) == 0 {
+		return []string{e.Message.To}
+	}
+	return e.Recipients
+}
+
+// SchedulePath returns the town's mail schedule file.
+func SchedulePath(townRoot string) string {
+	return filepath.Join(constants.TownRuntimePath(townRoot), "mail-schedule.json")
+}
+
+// Schedule stores deferred and recurring messages for a town.
+type Schedule struct {
+	path string
+}
+
+// NewSchedule returns the schedule for a town.
+func NewSchedule(townRoot string) *Schedule {
+	return &Schedule{path: SchedulePath(townRoot)}
+}
+
+// List returns the scheduled messages, soonest first.
+func (s *Schedule) List() ([]*ScheduledMessage, error) {
+	var out []*ScheduledMessage
+	err := s.update(func(entries []*ScheduledMessage) ([]*ScheduledMessage, bool, error) {
+		out = entries
+		return entries, false, nil
+	})
+	return out, err
+}
+
+// Cancel removes a scheduled message and returns it.
+func (s *Schedule) Cancel(id string) (*ScheduledMessage, error) {
+	var removed *ScheduledMessage
+	err := s.update(func(entries []*ScheduledMessage) ([]*ScheduledMessage, bool, error) {
+		for i, e := range entries {
+			if e.ID == id {
+				removed = e
+				return append(entries[:i], entries[i+1:]...), true, nil
+			}
+		}
+		return entries, false, ErrScheduleNotFound
+	})
+	return removed, err
+}
+
+func (s *Schedule) add(e *ScheduledMessage) error {
+	return s.update(func(entries []*ScheduledMessage) ([]*ScheduledMessage, bool, error) {
+		return append(entries, e), true, nil
+	})
+}
+
+// update runs fn on the schedule under an exclusive file lock and saves
+// the result if fn reports a change.
+func (s *Schedule) update(fn func([]*ScheduledMessage) ([]*ScheduledMessage, bool, error)) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	lock := flock.New(s.path + ".lock")
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("locking mail schedule: %w", err)
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	var entries []*ScheduledMessage
+	data, err := os.ReadFile(s.path) //nolint:gosec // G304: path is constructed internally
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return fmt.Errorf("parsing %s: %w", s.path, err)
+		}
+	}
+
+	entries, changed, err := fn(entries)
+	if err != nil || !changed {
+		return err
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Message.DeliverAt.Before(*entries[j].Message.DeliverAt)
+	})
+	if entries == nil {
+		entries = []*ScheduledMessage{}
+	}
+	return util.AtomicWriteJSON(s.path, entries)
+}
+
+func generateScheduleID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("sched-%x", time.Now().UnixNano())
+	}
+	return "sched-" + hex.EncodeToString(b)
+}
+
+// Schedule holds a message for later delivery and returns its schedule
+// entry. Without a DeliverAt, an interval recurrence delivers once now and
+// a daily rule waits for its first time of day. An expiry is kept
+// relative to each delivery of a recurring message.
+//
+// Recipients are resolved now, so groups, names and patterns that only
+// the resolver understands are delivered as they stood when the message
+// was scheduled. If a delivery that is due now reaches only some of them,
+// the entry is held for the next release pass to finish and its
+// LastError is set.
+func (r *Router) Schedule(msg *Message) (*ScheduledMessage, error) {
+	if r.townRoot == "" {
+		return nil, fmt.Errorf("scheduling mail requires a town root")
+	}
+	now := time.Now()
+	held := *msg
+	held.ID = ""
+
+	var rec *Recurrence
+	if held.Recurrence != "" {
+		var err error
+		if rec, err = ParseRecurrence(held.Recurrence); err != nil {
+			return nil, err
+		}
+	}
+
+	due := now
+	if held.DeliverAt != nil {
+		due = *held.DeliverAt
+	} else if rec != nil && rec.Daily() {
+		due = rec.Next(now)
+	}
+	if held.ExpiresAt != nil && !held.ExpiresAt.After(due) {
+		return nil, fmt.Errorf("message would expire before delivery (%s)", held.ExpiresAt.Format(time.RFC3339))
+	}
+	held.DeliverAt = &due
+
+	entry := &ScheduledMessage{
+		ID:         generateScheduleID(),
+		Message:    &held,
+		Recipients: r.resolveRecipients(held.To),
+		CreatedAt:  now,
+	}
+	if due.After(now) {
+		if err := NewSchedule(r.townRoot).add(entry); err != nil {
+			return nil, err
+		}
+		return entry, nil
+	}
+
+	// Due now: deliver this occurrence, then hold the next one.
+	if err := r.deliver(entry); err != nil {
+		if len(entry.Sent) == 0 {
+			return nil, err
+		}
+		entry.LastError = err.Error()
+	} else if rec == nil {
+		return entry, nil
+	} else {
+		advance(&held, rec, now)
+	}
+	if err := NewSchedule(r.townRoot).add(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// resolveRecipients expands an address to the concrete addresses Send
+// delivers to. An address the resolver can't expand is kept as given.
+func (r *Router) resolveRecipients(to string) []string {
+	recipients, err := NewResolver(beads.New(r.townRoot), r.townRoot).Resolve(to)
+	if err != nil || len(recipients) == 0 {
+		return []string{to}
+	}
+	addrs := make([]string, 0, len(recipients))
+	for _, rec := range recipients {
+		addrs = append(addrs, rec.Address)
+	}
+	return addrs
+}
+
+// deliver sends the current occurrence of e to the recipients that don't
+// have it yet. Once all do, the occurrence is counted and logged to the
+// activity feed like an immediate send.
+func (r *Router) deliver(e *ScheduledMessage) error {
+	sent := make(map[string]bool, len(e.Sent))
+	for _, addr := range e.Sent {
+		sent[addr] = true
+	}
+	var errs []string
+	for _, addr := range e.recipients() {
+		if sent[addr] {
+			continue
+		}
+		msg := occurrence(e.Message)
+		msg.To = addr
+		if err := r.Send(msg); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", addr, err))
+			continue
+		}
+		e.Sent = append(e.Sent, addr)
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	e.Deliveries++
+	e.Sent = nil
+	e.Attempts = 0
+	e.LastError = ""
+	_ = events.LogFeed(events.TypeMail, e.Message.From, events.MailPayload(e.Message.To, e.Message.Subject))
+	return nil
+}
+
+// occurrence returns the message to deliver for one release of a held
+// message.
+func occurrence(held *Message) *Message {
+	msg := *held
+	msg.ID = ""
+	msg.DeliverAt = nil
+	msg.Recurrence = ""
+	msg.Timestamp = time.Now()
+	return &msg
+}
+
+// advance moves a recurring message to its next delivery after now,
+// skipping occurrences missed while nothing was releasing mail, and shifts
+// its expiry along with it.
+func advance(held *Message, rec *Recurrence, now time.Time) {
+	prev := *held.DeliverAt
+	next := rec.Next(prev)
+	if !next.After(now) {
+		next = rec.Next(now)
+	}
+	held.DeliverAt = &next
+	if held.ExpiresAt != nil {
+		exp := next.Add(held.ExpiresAt.Sub(prev))
+		held.ExpiresAt = &exp
+	}
+}
+
+// ReleaseResult summarizes one ReleaseDue pass.
+type ReleaseResult struct {
+	Delivered int
+	Expired   int
+	Failed    int
+	GaveUp    int // Failed for the maxReleaseAttempts-th time in a row
+}
+
+// ReleaseDue delivers scheduled messages that are due at now. One-shot
+// messages leave the schedule once delivered or expired; recurring ones
+// move to their next time. A failed delivery stays due and is retried on
+// the next pass, up to maxReleaseAttempts times.
+//
+// Due entries are leased under the schedule lock and delivered after it
+// is released, so sending mail never holds up the schedule.
+func (r *Router) ReleaseDue(now time.Time) (ReleaseResult, error) {
+	var res ReleaseResult
+	if r.townRoot == "" {
+		return res, nil
+	}
+	sched := NewSchedule(r.townRoot)
+	if _, err := os.Stat(sched.path); os.IsNotExist(err) {
+		return res, nil
+	}
+
+	var due []*ScheduledMessage
+	err := sched.update(func(entries []*ScheduledMessage) ([]*ScheduledMessage, bool, error) {
+		for _, e := range entries {
+			if e.Failed || e.Message.DeliverAt.After(now) || (e.ClaimedUntil != nil && e.ClaimedUntil.After(now)) {
+				continue
+			}
+			until := now.Add(releaseLease)
+			e.ClaimedUntil = &until
+			due = append(due, e)
+		}
+		return entries, len(due) > 0, nil
+	})
+	if err != nil || len(due) == 0 {
+		return res, err
+	}
+
+	// The outcome for each leased entry: the entry to store, or nil once
+	// it leaves the schedule.
+	outcome := make(map[string]*ScheduledMessage, len(due))
+	for _, e := range due {
+		e.ClaimedUntil = nil
+		held := e.Message
+		var rec *Recurrence
+		if held.Recurrence != "" {
+			rec, _ = ParseRecurrence(held.Recurrence) // Validated when scheduled
+		}
+
+		if held.Expired(now) {
+			res.Expired++
+			e.Sent = nil
+		} else if err := r.deliver(e); err != nil {
+			res.Failed++
+			e.Attempts++
+			e.LastError = err.Error()
+			if e.Attempts < maxReleaseAttempts {
+				outcome[e.ID] = e
+				continue
+			}
+			res.GaveUp++
+			if rec == nil {
+				e.Failed = true
+				outcome[e.ID] = e
+				continue
+			}
+			// Skip this occurrence of a recurring message.
+			e.Sent = nil
+			e.Attempts = 0
+		} else {
+			res.Delivered++
+		}
+		if rec != nil {
+			advance(held, rec, now)
+			outcome[e.ID] = e
+		} else {
+			outcome[e.ID] = nil
+		}
+	}
+
+	// Entries cancelled while they were being delivered stay cancelled.
+	err = sched.update(func(entries []*ScheduledMessage) ([]*ScheduledMessage, bool, error) {
+		keep := entries[:0]
+		for _, e := range entries {
+			next, leased := outcome[e.ID]
+			switch {
+			case !leased:
+				keep = append(keep, e)
+			case next != nil:
+				keep = append(keep, next)
+			}
+		}
+		return keep, true, nil
+	})
+	return res, err
+}
+
+// PurgeExpired closes delivered messages whose expiry has passed and
+// returns how many were closed.
+func (r *Router) PurgeExpired(now time.Time) (int, error) {
+	beadsDir := r.resolveBeadsDir("")
+	args := []string{"list",
+		"--type=message",
+		"--json",
+		"--limit=0",
+	}
+	stdout, err := runBdCommand(args, filepath.Dir(beadsDir), beadsDir)
+	if err != nil {
+		return 0, fmt.Errorf("listing messages: %w", err)
+	}
+	var messages []BeadsMessage
+	if err := json.Unmarshal(stdout, &messages); err != nil {
+		if len(stdout) == 0 || string(stdout) == "null" {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("parsing messages: %w", err)
+	}
+
+	store := r.issues(beadsDir)
+	closed := 0
+	for i := range messages {
+		bm := &messages[i]
+		if bm.Status == "closed" {
+			continue
+		}
+		bm.ParseLabels()
+		if bm.expiresAt == nil || bm.expiresAt.After(now) {
+			continue
+		}
+		if err := store.CloseWithReason("expired", bm.ID); err == nil {
+			closed++
+		}
+	}
+	return closed, nil
+}
+
+// Recurrence is a parsed recurrence rule.
+type Recurrence struct {
+	every    time.Duration // Interval rules
+	hour     int           // Daily rules: local time of day
+	minute   int
+	weekdays bool // Daily rule that skips Saturday and Sunday
+}
+
+// ParseRecurrence parses a recurrence rule:
+//
+//	30m, 2h, 24h     a fixed interval (at least a minute)
+//	hourly           every hour
+//	daily@09:00      every day at a local time
+//	weekdays@09:00   Monday to Friday at a local time
+func ParseRecurrence(s string) (*Recurrence, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	if s == "hourly" {
+		return &Recurrence{every: time.Hour}, nil
+	}
+	if kind, at, ok := strings.Cut(s, "@"); ok {
+		if kind != "daily" && kind != "weekdays" {
+			return nil, fmt.Errorf("invalid recurrence %q: use daily@HH:MM or weekdays@HH:MM", s)
+		}
+		hh, mm, ok := strings.Cut(at, ":")
+		hour, herr := strconv.Atoi(hh)
+		minute, merr := strconv.Atoi(mm)
+		if !ok || herr != nil || merr != nil || hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+			return nil, fmt.Errorf("invalid time of day %q in recurrence", at)
+		}
+		return &Recurrence{hour: hour, minute: minute, weekdays: kind == "weekdays"}, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recurrence %q: use a duration (2h), hourly, daily@HH:MM or weekdays@HH:MM", s)
+	}
+	if d < minRecurrence {
+		return nil, fmt.Errorf("recurrence %s is shorter than %s", d, minRecurrence)
+	}
+	return &Recurrence{every: d}, nil
+}
+
+// Daily reports whether the rule fires at a time of day rather than at a
+// fixed interval.
+func (r *Recurrence) Daily() bool {
+	return r.every == 0
+}
+
+// Next returns the first occurrence strictly after t.
+func (r *Recurrence) Next(t time.Time) time.Time {
+	if !r.Daily() {
+		return t.Add(r.every)
+	}
+	t = t.Local()
+	next := time.Date(t.Year(), t.Month(), t.Day(), r.hour, r.minute, 0, 0, time.Local)
+	for !next.After(t) || (r.weekdays && (next.Weekday() == time.Saturday || next.Weekday() == time.Sunday)) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
@@ -0,0 +1,222 @@
+package mail
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	base := time.Date(2025, 6, 6, 10, 30, 0, 0, time.Local) // A Friday
+
+	tests := []struct {
+		rule string
+		want time.Time
+	}{
+		{"2h", base.Add(2 * time.Hour)},
+		{"hourly", base.Add(time.Hour)},
+		{"daily@09:00", time.Date(2025, 6, 7, 9, 0, 0, 0, time.Local)},
+		{"daily@11:15", time.Date(2025, 6, 6, 11, 15, 0, 0, time.Local)},
+		{"weekdays@09:00", time.Date(2025, 6, 9, 9, 0, 0, 0, time.Local)}, // Skips the weekend
+		{"Weekdays@10:30", time.Date(2025, 6, 9, 10, 30, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		rec, err := ParseRecurrence(tt.rule)
+		if err != nil {
+			t.Errorf("ParseRecurrence(%q): %v", tt.rule, err)
+			continue
+		}
+		if got := rec.Next(base); !got.Equal(tt.want) {
+			t.Errorf("%s: Next = %v, want %v", tt.rule, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "10s", "monthly@09:00", "daily@25:00", "daily@9", "sometimes"} {
+		if _, err := ParseRecurrence(bad); err == nil {
+			t.Errorf("ParseRecurrence(%q) should fail", bad)
+		}
+	}
+}
+
+func TestAdvance_SkipsMissedAndShiftsExpiry(t *testing.T) {
+	rec, _ := ParseRecurrence("1h")
+	due := time.Date(2025, 6, 6, 9, 0, 0, 0, time.UTC)
+	exp := due.Add(30 * time.Minute)
+	held := &Message{DeliverAt: &due, ExpiresAt: &exp}
+
+	now := due.Add(5*time.Hour + 10*time.Minute) // Five occurrences missed
+	advance(held, rec, now)
+	if want := now.Add(time.Hour); !held.DeliverAt.Equal(want) {
+		t.Errorf("DeliverAt = %v, want %v", held.DeliverAt, want)
+	}
+	if want := held.DeliverAt.Add(30 * time.Minute); !held.ExpiresAt.Equal(want) {
+		t.Errorf("ExpiresAt = %v, want %v", held.ExpiresAt, want)
+	}
+}
+
+func TestScheduleAndRelease(t *testing.T) {
+	townRoot := t.TempDir()
+	r := &Router{workDir: townRoot, townRoot: townRoot}
+	sched := NewSchedule(townRoot)
+
+	// Nothing scheduled: no schedule file is created.
+	if res, err := r.ReleaseDue(time.Now()); err != nil || res != (ReleaseResult{}) {
+		t.Fatalf("empty release = %+v, %v", res, err)
+	}
+
+	later := time.Now().Add(2 * time.Hour)
+	entry, err := r.Schedule(&Message{From: "mayor/", To: "gastown/witness", Subject: "Check nux", DeliverAt: &later})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Deliveries != 0 || entry.Message.ID != "" {
+		t.Errorf("entry = %+v", entry)
+	}
+
+	// Send holds future mail instead of delivering it.
+	expiring := time.Now().Add(time.Hour)
+	soon := time.Now().Add(time.Minute)
+	if err := r.Send(&Message{From: "mayor/", To: "gastown/nux", Subject: "Rebase", DeliverAt: &soon, ExpiresAt: &expiring}); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := sched.List()
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("List = %d entries, %v", len(entries), err)
+	}
+	if entries[0].Message.Subject != "Rebase" {
+		t.Errorf("schedule not ordered by due time: %s first", entries[0].Message.Subject)
+	}
+
+	// Past its expiry, a due message is dropped without delivery.
+	res, err := r.ReleaseDue(expiring.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Expired != 1 || res.Delivered != 0 {
+		t.Errorf("release = %+v", res)
+	}
+	entries, _ = sched.List()
+	if len(entries) != 1 || entries[0].ID != entry.ID {
+		t.Fatalf("after release: %+v", entries)
+	}
+
+	if _, err := sched.Cancel(entry.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sched.Cancel(entry.ID); !errors.Is(err, ErrScheduleNotFound) {
+		t.Errorf("second cancel = %v", err)
+	}
+
+	// Already expired mail is refused outright.
+	past := time.Now().Add(-time.Minute)
+	if err := r.Send(&Message{To: "mayor/", ExpiresAt: &past}); !errors.Is(err, ErrMessageExpired) {
+		t.Errorf("expired send = %v", err)
+	}
+	if _, err := r.Schedule(&Message{To: "mayor/", DeliverAt: &later, ExpiresAt: &soon}); err == nil {
+		t.Error("scheduling past its own expiry should fail")
+	}
+}
+
+func TestReleaseDue_GivesUpAfterMaxAttempts(t *testing.T) {
+	townRoot := t.TempDir()
+	r := &Router{workDir: townRoot, townRoot: townRoot}
+	sched := NewSchedule(townRoot)
+
+	// An unknown list fails to deliver without needing bd.
+	due := time.Now().Add(time.Minute)
+	entry, err := r.Schedule(&Message{From: "mayor/", To: "list:nobody", Subject: "Standup", DeliverAt: &due})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entry.Recipients) != 1 || entry.Recipients[0] != "list:nobody" {
+		t.Errorf("recipients = %v", entry.Recipients)
+	}
+
+	now := due.Add(time.Second)
+	for i := 1; i < maxReleaseAttempts; i++ {
+		res, err := r.ReleaseDue(now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Failed != 1 || res.GaveUp != 0 {
+			t.Fatalf("pass %d: %+v", i, res)
+		}
+		entries, _ := sched.List()
+		if len(entries) != 1 || entries[0].Attempts != i || entries[0].ClaimedUntil != nil {
+			t.Fatalf("pass %d entries = %+v", i, entries[0])
+		}
+	}
+	res, _ := r.ReleaseDue(now)
+	if res.GaveUp != 1 {
+		t.Errorf("final pass = %+v, want gave up", res)
+	}
+	if res, _ := r.ReleaseDue(now); res != (ReleaseResult{}) {
+		t.Errorf("failed entry released again: %+v", res)
+	}
+	entries, _ := sched.List()
+	if len(entries) != 1 || !entries[0].Failed || entries[0].LastError == "" {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+func TestReleaseDue_SkipsLeasedEntries(t *testing.T) {
+	townRoot := t.TempDir()
+	r := &Router{workDir: townRoot, townRoot: townRoot}
+	sched := NewSchedule(townRoot)
+
+	now := time.Now()
+	due := now.Add(-time.Minute)
+	lease := now.Add(time.Minute)
+	err := sched.add(&ScheduledMessage{
+		ID:           "sched-held",
+		Message:      &Message{To: "list:nobody", DeliverAt: &due},
+		ClaimedUntil: &lease,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res, _ := r.ReleaseDue(now); res != (ReleaseResult{}) {
+		t.Errorf("leased entry released: %+v", res)
+	}
+	// A lease left by a pass that died runs out.
+	if res, _ := r.ReleaseDue(lease.Add(time.Second)); res.Failed != 1 {
+		t.Errorf("after lease = %+v", res)
+	}
+}
+
+func TestBeadsMessage_ExpiresAt(t *testing.T) {
+	bm := BeadsMessage{
+		ID:     "hq-1",
+		Status: "open",
+		Labels: []string{"from:mayor/", "expires-at:2025-06-06T09:30:00Z"},
+	}
+	msg := bm.ToMessage()
+	want := time.Date(2025, 6, 6, 9, 30, 0, 0, time.UTC)
+	if msg.ExpiresAt == nil || !msg.ExpiresAt.Equal(want) {
+		t.Fatalf("ExpiresAt = %v, want %v", msg.ExpiresAt, want)
+	}
+	if msg.Expired(want.Add(-time.Second)) || !msg.Expired(want) {
+		t.Error("Expired boundary wrong")
+	}
+}
+
+func TestMailboxList_HidesExpired(t *testing.T) {
+	m := NewMailbox(t.TempDir())
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+	for _, msg := range []*Message{
+		{ID: "gone", Subject: "stale nudge", Timestamp: time.Now(), ExpiresAt: &past},
+		{ID: "live", Subject: "fresh nudge", Timestamp: time.Now(), ExpiresAt: &future},
+	} {
+		if err := m.Append(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	msgs, err := m.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 1 || msgs[0].ID != "live" {
+		t.Errorf("List = %+v", msgs)
+	}
+}
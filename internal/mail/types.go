@@ -123,6 +123,27 @@ type Message struct {
 	// ClaimedAt is when the queue message was claimed.
 	// Only set for queue messages after claiming.
 	ClaimedAt *time.Time `json:"claimed_at,omitempty"`
+
+	// DeliverAt defers delivery. Router.Send holds a message with a future
+	// DeliverAt in the town's mail schedule until the daemon releases it.
+	DeliverAt *time.Time `json:"deliver_at,omitempty"`
+
+	// ExpiresAt is when the message stops being useful. Expired messages
+	// are dropped before delivery, hidden from inboxes, and closed by the
+	// daemon.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	// Recurrence re-schedules the message after each delivery
+	// (see ParseRecurrence).
+	Recurrence string `json:"recurrence,omitempty"`
+
+	// Labels are free-form tags, such as those added by mail filter rules.
+	Labels []string `json:"labels,omitempty"`
+
+	// viaRule marks copies sent by a filter rule (forwards and
+	// auto-replies). They bypass the recipient's rules so rules cannot
+	// bounce mail back and forth.
+	viaRule bool
 }
 
 // NewMessage creates a new message with a generated ID and thread ID.
@@ -192,6 +213,11 @@ func NewChannelMessage(from, channel, subject, body string) *Message {
 	}
 }
 
+// Expired reports whether the message has expired at now.
+func (m *Message) Expired(now time.Time) bool {
+	return m.ExpiresAt != nil && !m.ExpiresAt.After(now)
+}
+
 // IsQueueMessage returns true if this is a queue-routed message.
 func (m *Message) IsQueueMessage() bool {
 	return m.Queue != ""
@@ -276,7 +302,7 @@ type BeadsMessage struct {
 	Priority    int       `json:"priority"`    // 0=urgent, 1=high, 2=normal, 3=low
 	Status      string    `json:"status"`      // open=unread, closed=read
 	CreatedAt   time.Time `json:"created_at"`
-	Labels      []string  `json:"labels"` // Metadata labels (from:X, thread:X, reply-to:X, msg-type:X, cc:X, queue:X, channel:X, claimed-by:X, claimed-at:X)
+	Labels      []string  `json:"labels"` // Metadata labels (from:X, thread:X, reply-to:X, msg-type:X, cc:X, queue:X, channel:X, claimed-by:X, claimed-at:X, expires-at:X, label:X)
 	Pinned      bool      `json:"pinned,omitempty"`
 	Wisp        bool      `json:"wisp,omitempty"` // Ephemeral message (filtered from JSONL export)
 
@@ -290,10 +316,13 @@ type BeadsMessage struct {
 	channel   string     // Channel name (for broadcast messages)
 	claimedBy string     // Who claimed the queue message
 	claimedAt *time.Time // When the queue message was claimed
+	expiresAt *time.Time // When the message expires
+	labels    []string   // Free-form tags
 }
 
 // ParseLabels extracts metadata from the labels array.
 func (bm *BeadsMessage) ParseLabels() {
+	bm.cc, bm.labels = nil, nil // Safe to call more than once
 	for _, label := range bm.Labels {
 		if strings.HasPrefix(label, "from:") {
 			bm.sender = strings.TrimPrefix(label, "from:")
@@ -316,6 +345,13 @@ func (bm *BeadsMessage) ParseLabels() {
 			if t, err := time.Parse(time.RFC3339, ts); err == nil {
 				bm.claimedAt = &t
 			}
+		} else if strings.HasPrefix(label, "expires-at:") {
+			ts := strings.TrimPrefix(label, "expires-at:")
+			if t, err := time.Parse(time.RFC3339, ts); err == nil {
+				bm.expiresAt = &t
+			}
+		} else if strings.HasPrefix(label, "label:") {
+			bm.labels = append(bm.labels, strings.TrimPrefix(label, "label:"))
 		}
 	}
 }
@@ -384,6 +420,8 @@ func (bm *BeadsMessage) ToMessage() *Message {
 		Channel:   bm.channel,
 		ClaimedBy: bm.claimedBy,
 		ClaimedAt: bm.claimedAt,
+		ExpiresAt: bm.expiresAt,
+		Labels:    bm.labels,
 	}
 }
 
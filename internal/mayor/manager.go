@@ -122,8 +122,8 @@ func (m *Manager) Start(agentOverride string) error {
 		return fmt.Errorf("waiting for mayor to start: %w", err)
 	}
 
-	// Accept bypass permissions warning dialog if it appears.
-	_ = t.AcceptBypassPermissionsWarning(sessionID)
+	// Answer the runtime's trust and permission dialogs (each answer is logged).
+	_, _ = runtime.AnswerStartupPrompts(t, sessionID, "mayor", runtimeConfig)
 
 	time.Sleep(constants.ShutdownNotifyDelay)
 
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/checkpoint"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/doltserver"
 	"github.com/steveyegge/gastown/internal/git"
@@ -333,6 +334,11 @@ func (m *Manager) exists(name string) bool {
 // AddOptions configures polecat creation.
 type AddOptions struct {
 	HookBead string // Bead ID to set as hook_bead at spawn time (atomic assignment)
+
+	// RestoreCheckpoint makes RepairWorktree reapply the old worktree's
+	// checkpoint snapshot. It is implied when HookBead matches the bead
+	// the checkpoint was taken on.
+	RestoreCheckpoint bool
 }
 
 // Add creates a new polecat as a git worktree from the repo base.
@@ -847,6 +853,11 @@ func (m *Manager) ReleaseName(name string) {
 //
 // Branch naming: Each repair gets a unique branch (polecat/<name>-<timestamp>).
 // Old branches are left for garbage collection - they're never pushed to origin.
+//
+// Uncommitted work in the old worktree is snapshotted to a checkpoint ref
+// before removal (see checkpoint.Snapshot), so a forced repair loses nothing.
+// When the repaired polecat resumes the same bead, the snapshot is
+// reapplied to the new worktree and the checkpoint carried over.
 func (m *Manager) RepairWorktree(name string, force bool) (*Polecat, error) {
 	return m.RepairWorktreeWithOptions(name, force, AddOptions{})
 }
@@ -907,6 +918,21 @@ func (m *Manager) RepairWorktreeWithOptions(name string, force bool, opts AddOpt
 		return nil, fmt.Errorf("creating fresh worktree from %s: %w", startPoint, err)
 	}
 
+	// Snapshot uncommitted work before the old worktree goes away (best-effort:
+	// the old worktree may be too broken to read).
+	cp, _ := checkpoint.Read(oldClonePath)
+	if ref, snapErr := checkpoint.Snapshot(oldClonePath, checkpoint.AgentName(m.rig.Name, name), time.Now()); snapErr == nil && ref != "" {
+		if cp == nil {
+			cp = &checkpoint.Checkpoint{}
+		}
+		cp.SnapshotRef = ref
+		cp.Timestamp = time.Now()
+	}
+	if cp != nil && cp.SnapshotRef == "" {
+		cp = nil
+	}
+	resume := cp != nil && (opts.RestoreCheckpoint || (opts.HookBead != "" && cp.HookedBead == opts.HookBead))
+
 	// New worktree created successfully — now safe to close old bead and remove old worktree.
 	// Closing the bead AFTER creation prevents inconsistent state if creation fails.
 	// NOTE: We use CloseAndClearAgentBead instead of DeleteAgentBead because bd delete --hard
@@ -966,6 +992,15 @@ func (m *Manager) RepairWorktreeWithOptions(name string, force bool, opts AddOpt
 		fmt.Printf("Warning: could not update .gitignore: %v\n", err)
 	}
 
+	// Resuming the same work: reapply the snapshot and keep the checkpoint.
+	if resume {
+		if err := checkpoint.Restore(newClonePath, cp.SnapshotRef); err != nil {
+			fmt.Printf("Warning: could not restore checkpoint snapshot %s: %v\n", cp.SnapshotRef, err)
+		} else if err := checkpoint.Write(newClonePath, cp); err != nil {
+			fmt.Printf("Warning: could not carry over checkpoint: %v\n", err)
+		}
+	}
+
 	// NOTE: Slash commands inherited from town level - no per-workspace copies needed.
 
 	// Create or reopen agent bead for ZFC compliance
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -14,10 +15,14 @@ import (
 	"time"
 
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/cgroup"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/doltserver"
+	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/runtime"
+	"github.com/steveyegge/gastown/internal/sandbox"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/tmux"
 )
@@ -94,6 +99,9 @@ type SessionInfo struct {
 
 	// LastActivity is when the session last had activity.
 	LastActivity time.Time `json:"last_activity,omitempty"`
+
+	// Resources is the session's cgroup usage, if it could be read.
+	Resources *cgroup.Usage `json:"resources,omitempty"`
 }
 
 // SessionName generates the tmux session name for a polecat.
@@ -215,6 +223,17 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 		command = config.PrependEnv(command, map[string]string{runtimeConfig.Session.ConfigDirEnv: opts.RuntimeConfigDir})
 	}
 
+	// Run inside a container if the rig opted into sandboxing. Unlike
+	// resource limits this is fatal: an untrusted repo must never silently
+	// fall back to running on the host.
+	command, err = m.applySandbox(polecat, workDir, opts.RuntimeConfigDir, command)
+	if err != nil {
+		return fmt.Errorf("sandboxing session: %w", err)
+	}
+
+	// Bound memory/CPU/pids if the polecat role configures resource limits.
+	command = m.applyResourceLimits(sessionID, command)
+
 	// Create session with command directly to avoid send-keys race condition.
 	// See: https://github.com/anthropics/gastown/issues/280
 	if err := m.tmux.NewSessionWithCommand(sessionID, workDir, command); err != nil {
@@ -255,8 +274,11 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 	// Wait for Claude to start (non-fatal)
 	debugSession("WaitForCommand", m.tmux.WaitForCommand(sessionID, constants.SupportedShells, constants.ClaudeStartTimeout))
 
-	// Accept bypass permissions warning dialog if it appears
-	debugSession("AcceptBypassPermissionsWarning", m.tmux.AcceptBypassPermissionsWarning(sessionID))
+	// Wait for the runtime prompt, then answer any trust or permission
+	// dialogs the runtime showed first (non-fatal; each answer is logged).
+	debugSession("WaitForRuntimeReady", m.tmux.WaitForRuntimeReady(sessionID, runtimeConfig, constants.ClaudeStartTimeout))
+	_, err = runtime.AnswerStartupPrompts(m.tmux, sessionID, address, runtimeConfig)
+	debugSession("AnswerStartupPrompts", err)
 
 	// Wait for runtime to be fully ready at the prompt (not just started)
 	runtime.SleepForReadyDelay(runtimeConfig)
@@ -300,6 +322,105 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 	return nil
 }
 
+// applySandbox wraps command to run in the rig's container sandbox when
+// settings/config.json enables it. The sandbox sees only the polecat's own
+// home (worktree, its git dir and runtime settings), the resolved beads
+// directory and the runtime config dir, and reaches the host Dolt server
+// for bd. The town root, other rigs, other polecats and mail stay hidden.
+//
+// A settings file that can't be read fails the start rather than running
+// an untrusted polecat unsandboxed.
+func (m *SessionManager) applySandbox(polecat, workDir, runtimeConfigDir, command string) (string, error) {
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(m.rig.Path))
+	if err != nil {
+		if errors.Is(err, config.ErrNotFound) {
+			return command, nil
+		}
+		return "", fmt.Errorf("loading rig settings for sandbox: %w", err)
+	}
+	if settings.Sandbox == nil || !settings.Sandbox.Enabled {
+		return command, nil
+	}
+	cfg := settings.Sandbox
+
+	rt, _, err := sandbox.Detect(sandbox.Runtime(cfg.Runtime))
+	if err != nil {
+		return "", err
+	}
+
+	townRoot := filepath.Dir(m.rig.Path)
+	homeDir := m.polecatDir(polecat)
+	beadsDir := beads.ResolveBeadsDir(workDir)
+	mounts := []sandbox.Mount{
+		{Path: homeDir},
+		{Path: workDir},
+		{Path: beadsDir},
+	}
+	if commonDir, err := git.NewGit(workDir).CommonDir(); err == nil {
+		mounts = append(mounts, sandbox.Mount{Path: commonDir})
+	}
+	if runtimeConfigDir != "" {
+		mounts = append(mounts, sandbox.Mount{Path: runtimeConfigDir})
+	}
+	for _, p := range cfg.ReadOnlyMounts {
+		mounts = append(mounts, sandbox.Mount{Path: p, ReadOnly: true})
+	}
+	mounts = append(mounts, sandbox.ToolMounts(rt, "gt", "bd", "git", "tmux", config.LoadRuntimeConfig(m.rig.Path).Command)...)
+
+	doltPort := doltserver.DefaultConfig(townRoot).Port
+	if sqlCfg, ok := beads.SQLConfigFor(beadsDir); ok {
+		if _, port, err := net.SplitHostPort(sqlCfg.Addr); err == nil {
+			if n, err := strconv.Atoi(port); err == nil {
+				doltPort = n
+			}
+		}
+	}
+
+	spec := &sandbox.Spec{
+		Runtime:   rt,
+		Image:     cfg.Image,
+		Network:   cfg.Network,
+		WorkDir:   workDir,
+		Home:      homeDir,
+		Mounts:    mounts,
+		HostPorts: []int{doltPort},
+		Name:      m.SessionName(polecat),
+	}
+	return spec.Wrap(command)
+}
+
+// applyResourceLimits wraps command so the agent runs under the polecat
+// role's [resources] limits. Limits are best-effort: if no cgroup backend is
+// usable, a warning is printed and the agent runs unbounded.
+func (m *SessionManager) applyResourceLimits(sessionID, command string) string {
+	townRoot := filepath.Dir(m.rig.Path)
+	roleDef, err := config.LoadRoleDefinition(townRoot, m.rig.Path, "polecat")
+	if err != nil || roleDef.Resources.IsZero() {
+		return command
+	}
+	res := roleDef.Resources
+
+	backend, err := cgroup.Detect(cgroup.Backend(res.Backend))
+	if err != nil {
+		fmt.Printf("Warning: resource limits not applied to %s: %v\n", sessionID, err)
+		return command
+	}
+
+	// Clear a failed scope or stale group left by a previous session.
+	cgroup.Release(sessionID)
+
+	wrapped, err := cgroup.WrapCommand(backend, sessionID, cgroup.Limits{
+		MemoryMax: res.MemoryMax,
+		CPUWeight: res.CPUWeight,
+		PidsMax:   res.PidsMax,
+	}, command)
+	if err != nil {
+		fmt.Printf("Warning: resource limits not applied to %s: %v\n", sessionID, err)
+		return command
+	}
+	return wrapped
+}
+
 // isSessionStale checks if a tmux session's pane process has died.
 // A stale session exists in tmux but its main process (the agent) is no longer running.
 // This happens when the agent crashes during startup but tmux keeps the dead pane.
@@ -347,6 +468,9 @@ func (m *SessionManager) Stop(polecat string, force bool) error {
 		return fmt.Errorf("killing session: %w", err)
 	}
 
+	// Drop the session's scope or cgroup (no-op when limits are unused).
+	cgroup.Release(sessionID)
+
 	return nil
 }
 
@@ -407,6 +531,14 @@ func (m *SessionManager) Status(polecat string) (*SessionInfo, error) {
 		}
 	}
 
+	if pidStr, err := m.tmux.GetPanePID(sessionID); err == nil {
+		if pid, err := strconv.Atoi(pidStr); err == nil {
+			if usage, err := cgroup.UsageForPID(pid); err == nil {
+				info.Resources = usage
+			}
+		}
+	}
+
 	return info, nil
 }
 
@@ -290,3 +290,26 @@ func TestSessionManager_resolveBeadsDir(t *testing.T) {
 		})
 	}
 }
+
+func TestApplySandbox_FailsClosed(t *testing.T) {
+	rigPath := filepath.Join(t.TempDir(), "gastown")
+	m := NewSessionManager(tmux.NewTmux(), &rig.Rig{Name: "gastown", Path: rigPath})
+	workDir := filepath.Join(rigPath, "polecats", "Toast", "gastown")
+
+	// No settings file: the sandbox is off.
+	got, err := m.applySandbox("Toast", workDir, "", "claude")
+	if err != nil || got != "claude" {
+		t.Fatalf("applySandbox without settings = %q, %v; want command unchanged", got, err)
+	}
+
+	// A settings file that can't be parsed must not run the agent unsandboxed.
+	if err := os.MkdirAll(filepath.Join(rigPath, "settings"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(rigPath, "settings", "config.json"), []byte(`{"sandbox":`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := m.applySandbox("Toast", workDir, "", "claude"); err == nil {
+		t.Errorf("applySandbox with malformed settings = %q, want error", got)
+	}
+}
@@ -0,0 +1,120 @@
+// Package envelope encodes protocol messages as a typed, schema-versioned
+// JSON envelope carried in the mail body.
+//
+// Protocol mail (MERGE_READY, MERGED, POLECAT_DONE, ...) historically used a
+// subject prefix plus "Key: value" body lines, which break on wording changes
+// and multi-line values. During the migration senders write both: the legacy
+// lines for older readers and humans, followed by one envelope line:
+//
+//	gt-protocol: {"v":1,"type":"MERGED","payload":{...}}
+//
+// Readers prefer the envelope and fall back to the legacy lines when it is
+// missing or written by a newer schema version.
+package envelope
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SchemaVersion is the envelope version written by this build. Bump it when
+// a payload changes incompatibly; older readers then use the legacy lines.
+const SchemaVersion = 1
+
+// Prefix starts the line carrying the envelope in a message body. The
+// envelope is a single line so "Key: value" scanners ignore it, and JSON
+// escaping keeps multi-line values (errors, instructions) intact.
+const Prefix = "gt-protocol: "
+
+var (
+	// ErrNoEnvelope means the body is in the legacy text-only format.
+	ErrNoEnvelope = errors.New("no protocol envelope")
+
+	// ErrUnsupportedVersion means the envelope was written by a newer schema.
+	ErrUnsupportedVersion = errors.New("unsupported protocol envelope version")
+)
+
+// Envelope is the typed, versioned wrapper around a protocol payload.
+type Envelope struct {
+	// Version is the schema version (see SchemaVersion).
+	Version int `json:"v"`
+
+	// Type is the protocol message type, e.g. "MERGED" or "POLECAT_DONE".
+	Type string `json:"type"`
+
+	// Payload is the type-specific payload.
+	Payload json.RawMessage `json:"payload"`
+}
+
+// Encode returns the envelope line for a payload, without a trailing newline.
+func Encode(msgType string, payload interface{}) (string, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("encoding %s payload: %w", msgType, err)
+	}
+	data, err := json.Marshal(Envelope{Version: SchemaVersion, Type: msgType, Payload: raw})
+	if err != nil {
+		return "", fmt.Errorf("encoding %s envelope: %w", msgType, err)
+	}
+	return Prefix + string(data), nil
+}
+
+// Append adds the envelope line to a legacy text body. If the payload
+// cannot be encoded the legacy body is returned unchanged.
+func Append(body, msgType string, payload interface{}) string {
+	line, err := Encode(msgType, payload)
+	if err != nil {
+		return body
+	}
+	if body != "" && !strings.HasSuffix(body, "\n") {
+		body += "\n"
+	}
+	return body + line + "\n"
+}
+
+// Decode finds and parses the envelope in a message body. Returns
+// ErrNoEnvelope for legacy bodies and ErrUnsupportedVersion (with the
+// envelope populated) for envelopes from a newer schema.
+func Decode(body string) (*Envelope, error) {
+	for _, line := range strings.Split(body, "\n") {
+		data, ok := strings.CutPrefix(strings.TrimSpace(line), Prefix)
+		if !ok {
+			continue
+		}
+		var env Envelope
+		if err := json.Unmarshal([]byte(data), &env); err != nil {
+			return nil, fmt.Errorf("parsing protocol envelope: %w", err)
+		}
+		if env.Version < 1 {
+			return nil, fmt.Errorf("protocol envelope missing version")
+		}
+		if env.Version > SchemaVersion {
+			return &env, fmt.Errorf("%w: v%d (this build reads up to v%d)", ErrUnsupportedVersion, env.Version, SchemaVersion)
+		}
+		return &env, nil
+	}
+	return nil, ErrNoEnvelope
+}
+
+// DecodePayload decodes the envelope payload of the given type into v.
+// It returns false with a nil error when the body has no usable envelope
+// (legacy format, newer version, or a different message type), in which
+// case the caller should parse the legacy text fields instead.
+func DecodePayload(body, msgType string, v interface{}) (bool, error) {
+	env, err := Decode(body)
+	if errors.Is(err, ErrNoEnvelope) || errors.Is(err, ErrUnsupportedVersion) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if env.Type != msgType {
+		return false, nil
+	}
+	if err := json.Unmarshal(env.Payload, v); err != nil {
+		return false, fmt.Errorf("parsing %s payload: %w", msgType, err)
+	}
+	return true, nil
+}
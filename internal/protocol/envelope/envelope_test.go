@@ -0,0 +1,79 @@
+package envelope
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testPayload struct {
+	Polecat string `json:"polecat"`
+	Error   string `json:"error"`
+}
+
+func TestAppendDecodeRoundTrip(t *testing.T) {
+	want := testPayload{Polecat: "nux", Error: "line one\nError: line two\n  indented"}
+	body := Append("Branch: polecat/nux\nError: line one", "MERGE_FAILED", want)
+
+	if !strings.HasPrefix(body, "Branch: polecat/nux\nError: line one\n"+Prefix) {
+		t.Errorf("legacy lines should precede the envelope:\n%s", body)
+	}
+	if strings.Count(body, "\n") != 3 {
+		t.Errorf("envelope must be a single line:\n%s", body)
+	}
+
+	var got testPayload
+	ok, err := DecodePayload(body, "MERGE_FAILED", &got)
+	if err != nil || !ok {
+		t.Fatalf("DecodePayload = %v, %v", ok, err)
+	}
+	if got != want {
+		t.Errorf("payload = %+v, want %+v", got, want)
+	}
+}
+
+func TestDecode_Legacy(t *testing.T) {
+	if _, err := Decode("Branch: x\nIssue: y"); !errors.Is(err, ErrNoEnvelope) {
+		t.Errorf("Decode legacy err = %v, want ErrNoEnvelope", err)
+	}
+	var p testPayload
+	ok, err := DecodePayload("Branch: x", "MERGED", &p)
+	if ok || err != nil {
+		t.Errorf("DecodePayload legacy = %v, %v; want false, nil", ok, err)
+	}
+}
+
+func TestDecode_UnknownVersion(t *testing.T) {
+	body := Prefix + `{"v":99,"type":"MERGED","payload":{"polecat":"nux"}}`
+	env, err := Decode(body)
+	if !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("err = %v, want ErrUnsupportedVersion", err)
+	}
+	if env == nil || env.Type != "MERGED" {
+		t.Errorf("envelope should still be returned for inspection, got %+v", env)
+	}
+
+	var p testPayload
+	ok, err := DecodePayload(body, "MERGED", &p)
+	if ok || err != nil {
+		t.Errorf("DecodePayload newer version = %v, %v; want fallback (false, nil)", ok, err)
+	}
+}
+
+func TestDecode_Malformed(t *testing.T) {
+	if _, err := Decode(Prefix + "{not json"); err == nil || errors.Is(err, ErrNoEnvelope) {
+		t.Errorf("malformed envelope err = %v", err)
+	}
+	if _, err := Decode(Prefix + `{"type":"MERGED"}`); err == nil {
+		t.Error("expected error for envelope without version")
+	}
+}
+
+func TestDecodePayload_TypeMismatch(t *testing.T) {
+	body := Append("", "MERGED", testPayload{Polecat: "nux"})
+	var p testPayload
+	ok, err := DecodePayload(body, "MERGE_FAILED", &p)
+	if ok || err != nil {
+		t.Errorf("type mismatch = %v, %v; want false, nil", ok, err)
+	}
+}
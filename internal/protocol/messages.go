@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/protocol/envelope"
 )
 
 // NewMergeReadyMessage creates a MERGE_READY protocol message.
@@ -20,7 +21,7 @@ func NewMergeReadyMessage(rig, polecat, branch, issue string) *mail.Message {
 		Timestamp: time.Now(),
 	}
 
-	body := formatMergeReadyBody(payload)
+	body := envelope.Append(formatMergeReadyBody(payload), string(TypeMergeReady), payload)
 
 	msg := mail.NewMessage(
 		fmt.Sprintf("%s/witness", rig),
@@ -60,7 +61,7 @@ func NewMergedMessage(rig, polecat, branch, issue, targetBranch, mergeCommit str
 		TargetBranch: targetBranch,
 	}
 
-	body := formatMergedBody(payload)
+	body := envelope.Append(formatMergedBody(payload), string(TypeMerged), payload)
 
 	msg := mail.NewMessage(
 		fmt.Sprintf("%s/refinery", rig),
@@ -103,7 +104,7 @@ func NewMergeFailedMessage(rig, polecat, branch, issue, targetBranch, failureTyp
 		TargetBranch: targetBranch,
 	}
 
-	body := formatMergeFailedBody(payload)
+	body := envelope.Append(formatMergeFailedBody(payload), string(TypeMergeFailed), payload)
 
 	msg := mail.NewMessage(
 		fmt.Sprintf("%s/refinery", rig),
@@ -145,7 +146,7 @@ func NewReworkRequestMessage(rig, polecat, branch, issue, targetBranch string, c
 		Instructions:  formatRebaseInstructions(targetBranch),
 	}
 
-	body := formatReworkRequestBody(payload)
+	body := envelope.Append(formatReworkRequestBody(payload), string(TypeReworkRequest), payload)
 
 	msg := mail.NewMessage(
 		fmt.Sprintf("%s/refinery", rig),
@@ -192,7 +193,12 @@ The Refinery will retry the merge after rebase is complete.`, targetBranch, targ
 }
 
 // ParseMergeReadyPayload parses a MERGE_READY message body into a payload.
+// The JSON envelope is preferred; legacy "Key: value" bodies still parse.
 func ParseMergeReadyPayload(body string) *MergeReadyPayload {
+	var p MergeReadyPayload
+	if ok, _ := envelope.DecodePayload(body, string(TypeMergeReady), &p); ok {
+		return &p
+	}
 	return &MergeReadyPayload{
 		Branch:    parseField(body, "Branch"),
 		Issue:     parseField(body, "Issue"),
@@ -204,7 +210,13 @@ func ParseMergeReadyPayload(body string) *MergeReadyPayload {
 }
 
 // ParseMergedPayload parses a MERGED message body into a payload.
+// The JSON envelope is preferred; legacy "Key: value" bodies still parse.
 func ParseMergedPayload(body string) *MergedPayload {
+	var p MergedPayload
+	if ok, _ := envelope.DecodePayload(body, string(TypeMerged), &p); ok {
+		return &p
+	}
+
 	payload := &MergedPayload{
 		Branch:       parseField(body, "Branch"),
 		Issue:        parseField(body, "Issue"),
@@ -225,7 +237,13 @@ func ParseMergedPayload(body string) *MergedPayload {
 }
 
 // ParseMergeFailedPayload parses a MERGE_FAILED message body into a payload.
+// The JSON envelope is preferred, which keeps multi-line errors intact.
 func ParseMergeFailedPayload(body string) *MergeFailedPayload {
+	var p MergeFailedPayload
+	if ok, _ := envelope.DecodePayload(body, string(TypeMergeFailed), &p); ok {
+		return &p
+	}
+
 	payload := &MergeFailedPayload{
 		Branch:       parseField(body, "Branch"),
 		Issue:        parseField(body, "Issue"),
@@ -247,7 +265,13 @@ func ParseMergeFailedPayload(body string) *MergeFailedPayload {
 }
 
 // ParseReworkRequestPayload parses a REWORK_REQUEST message body into a payload.
+// The JSON envelope is preferred; legacy "Key: value" bodies still parse.
 func ParseReworkRequestPayload(body string) *ReworkRequestPayload {
+	var p ReworkRequestPayload
+	if ok, _ := envelope.DecodePayload(body, string(TypeReworkRequest), &p); ok {
+		return &p
+	}
+
 	payload := &ReworkRequestPayload{
 		Branch:       parseField(body, "Branch"),
 		Issue:        parseField(body, "Issue"),
@@ -271,7 +295,7 @@ func ParseReworkRequestPayload(body string) *ReworkRequestPayload {
 	return payload
 }
 
-// parseField extracts a field value from a key-value body format.
+// parseField extracts a field value from the legacy key-value body format.
 // Format: "Key: value"
 func parseField(body, key string) string {
 	lines := strings.Split(body, "\n")
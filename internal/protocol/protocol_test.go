@@ -376,3 +376,43 @@ func (m *mockRefineryHandler) HandleMergeReady(payload *MergeReadyPayload) error
 	m.readyCalled = true
 	return nil
 }
+
+func TestMessageEnvelopeRoundTrip(t *testing.T) {
+	multiLine := "go test ./...\n--- FAIL: TestX\nError: boom"
+
+	failed := NewMergeFailedMessage("gastown", "nux", "polecat/nux", "gt-abc", "main", "tests", multiLine)
+	fp := ParseMergeFailedPayload(failed.Body)
+	if fp.Error != multiLine || fp.FailureType != "tests" || fp.Polecat != "nux" || fp.TargetBranch != "main" {
+		t.Errorf("MERGE_FAILED round trip = %+v", fp)
+	}
+	if fp.FailedAt.IsZero() {
+		t.Error("FailedAt should survive the round trip")
+	}
+
+	merged := NewMergedMessage("gastown", "nux", "polecat/nux", "gt-abc", "main", "abc123")
+	mp := ParseMergedPayload(merged.Body)
+	if mp.MergeCommit != "abc123" || mp.Issue != "gt-abc" || mp.Rig != "gastown" {
+		t.Errorf("MERGED round trip = %+v", mp)
+	}
+
+	ready := NewMergeReadyMessage("gastown", "nux", "polecat/nux", "gt-abc")
+	rp := ParseMergeReadyPayload(ready.Body)
+	if rp.Branch != "polecat/nux" || rp.Verified == "" {
+		t.Errorf("MERGE_READY round trip = %+v", rp)
+	}
+
+	rework := NewReworkRequestMessage("gastown", "nux", "polecat/nux", "gt-abc", "main", []string{"a, b.go", "c.go"})
+	wp := ParseReworkRequestPayload(rework.Body)
+	if len(wp.ConflictFiles) != 2 || wp.ConflictFiles[0] != "a, b.go" || !strings.Contains(wp.Instructions, "git rebase origin/main") {
+		t.Errorf("REWORK_REQUEST round trip = %+v", wp)
+	}
+}
+
+func TestParsePayload_NewerVersionFallsBackToLegacy(t *testing.T) {
+	body := "Branch: polecat/nux\nIssue: gt-abc\nFailure-Type: build\nError: legacy text\n" +
+		`gt-protocol: {"v":2,"type":"MERGE_FAILED","payload":{"renamed":"field"}}`
+	p := ParseMergeFailedPayload(body)
+	if p.Error != "legacy text" || p.FailureType != "build" || p.Issue != "gt-abc" {
+		t.Errorf("fallback payload = %+v", p)
+	}
+}
@@ -8,6 +8,10 @@
 //   - MERGED: Refinery → Witness (merge succeeded, cleanup ok)
 //   - MERGE_FAILED: Refinery → Witness (merge failed, needs rework)
 //   - REWORK_REQUEST: Refinery → Witness (rebase needed)
+//
+// Message bodies carry the legacy "Key: value" lines followed by a typed,
+// versioned JSON envelope (see the envelope subpackage). Parsers prefer the
+// envelope and fall back to the legacy lines.
 package protocol
 
 import (
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -16,10 +17,13 @@ import (
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/convoy"
 	"github.com/steveyegge/gastown/internal/crew"
+	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/ledger"
 	"github.com/steveyegge/gastown/internal/mail"
 	"github.com/steveyegge/gastown/internal/protocol"
 	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/tracing"
 )
 
 // MergeQueueConfig holds configuration for the merge queue processor.
@@ -232,6 +236,25 @@ type ProcessResult struct {
 	Error       string
 	Conflict    bool
 	TestsFailed bool
+
+	// Failure categorizes failures that Conflict and TestsFailed don't cover.
+	Failure FailureType
+}
+
+// FailureType categorizes a failed result. Failures not otherwise
+// identified are reported as build failures.
+func (r ProcessResult) FailureType() FailureType {
+	switch {
+	case r.Success:
+		return FailureNone
+	case r.Conflict:
+		return FailureConflict
+	case r.TestsFailed:
+		return FailureTestsFail
+	case r.Failure != "":
+		return r.Failure
+	}
+	return FailureBuildFail
 }
 
 // ProcessMR processes a single merge request from a beads issue.
@@ -256,7 +279,19 @@ func (e *Engineer) ProcessMR(ctx context.Context, mr *beads.Issue) ProcessResult
 
 // doMerge performs the actual git merge operation.
 // This is the core merge logic shared by ProcessMR and ProcessMRFromQueue.
-func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue string) ProcessResult {
+func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue string) (res ProcessResult) {
+	span := tracing.Start("refinery.merge", sourceIssue)
+	span.SetAttr("branch", branch)
+	span.SetAttr("target", target)
+	defer func() {
+		span.SetAttr("merge_commit", res.MergeCommit)
+		if res.Success {
+			span.End(nil)
+		} else {
+			span.End(errors.New(res.Error))
+		}
+	}()
+
 	// Step 1: Verify source branch exists locally (shared .repo.git with polecats)
 	_, _ = fmt.Fprintf(e.output, "[Engineer] Checking local branch %s...\n", branch)
 	exists, err := e.git.BranchExists(branch)
@@ -264,12 +299,14 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 		return ProcessResult{
 			Success: false,
 			Error:   fmt.Sprintf("failed to check branch %s: %v", branch, err),
+			Failure: FailureFetch,
 		}
 	}
 	if !exists {
 		return ProcessResult{
 			Success: false,
 			Error:   fmt.Sprintf("branch %s not found locally", branch),
+			Failure: FailureFetch,
 		}
 	}
 
@@ -279,6 +316,7 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 		return ProcessResult{
 			Success: false,
 			Error:   fmt.Sprintf("failed to checkout target %s: %v", target, err),
+			Failure: FailureCheckout,
 		}
 	}
 
@@ -366,6 +404,7 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 		return ProcessResult{
 			Success: false,
 			Error:   fmt.Sprintf("failed to push to origin: %v", err),
+			Failure: FailurePushFail,
 		}
 	}
 
@@ -447,14 +486,14 @@ func (e *Engineer) handleSuccess(mr *beads.Issue, result ProcessResult) {
 	}
 
 	// 2. Close MR with reason 'merged'
-	if err := e.beads.CloseWithReason("merged", mr.ID); err != nil {
+	if err := e.closeMerged("merged", mr.ID); err != nil {
 		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to close MR %s: %v\n", mr.ID, err)
 	}
 
 	// 3. Close source issue with reference to MR
 	if mrFields.SourceIssue != "" {
 		closeReason := fmt.Sprintf("Merged in %s", mr.ID)
-		if err := e.beads.CloseWithReason(closeReason, mrFields.SourceIssue); err != nil {
+		if err := e.closeMerged(closeReason, mrFields.SourceIssue); err != nil {
 			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to close source issue %s: %v\n", mrFields.SourceIssue, err)
 		} else {
 			_, _ = fmt.Fprintf(e.output, "[Engineer] Closed source issue: %s\n", mrFields.SourceIssue)
@@ -495,9 +534,23 @@ func (e *Engineer) handleSuccess(mr *beads.Issue, result ProcessResult) {
 	e.syncCrewWorkspaces()
 
 	// 6. Log success
+	e.logMergeEvent(events.TypeMerged, mrInfoFromFields(mr.ID, mrFields), "")
 	_, _ = fmt.Fprintf(e.output, "[Engineer] ✓ Merged: %s (commit: %s)\n", mr.ID, result.MergeCommit)
 }
 
+// closeMerged closes a bead the merge completed and records it in the town
+// ledger. A failed export is only a warning; 'gt ledger export --all'
+// picks up anything missed.
+func (e *Engineer) closeMerged(reason, id string) error {
+	if err := e.beads.CloseWithReason(reason, id); err != nil {
+		return err
+	}
+	ledger.ExportClosed(filepath.Dir(e.rig.Path), []string{id}, ledger.TriggerMerge, func(id string, err error) {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: ledger export of %s failed: %v\n", id, err)
+	})
+	return nil
+}
+
 // syncCrewWorkspaces pulls latest changes to all crew workspaces.
 // This ensures crew members have access to newly merged code without manual sync.
 func (e *Engineer) syncCrewWorkspaces() {
@@ -540,9 +593,19 @@ func (e *Engineer) handleFailure(mr *beads.Issue, result ProcessResult) {
 	}
 
 	// Log the failure
+	mrFields := beads.ParseMRFields(mr)
+	if mrFields == nil {
+		mrFields = &beads.MRFields{}
+	}
+	e.logMergeEvent(events.TypeMergeFailed, mrInfoFromFields(mr.ID, mrFields), result.FailureType())
 	_, _ = fmt.Fprintf(e.output, "[Engineer] ✗ Failed: %s - %s\n", mr.ID, result.Error)
 }
 
+// mrInfoFromFields builds the MRInfo fields logMergeEvent needs.
+func mrInfoFromFields(id string, f *beads.MRFields) *MRInfo {
+	return &MRInfo{ID: id, Branch: f.Branch, SourceIssue: f.SourceIssue, Worker: f.Worker}
+}
+
 // ProcessMRInfo processes a merge request from MRInfo.
 func (e *Engineer) ProcessMRInfo(ctx context.Context, mr *MRInfo) ProcessResult {
 	// MR fields are directly on the struct
@@ -593,7 +656,7 @@ func (e *Engineer) HandleMRInfoSuccess(mr *MRInfo, result ProcessResult) {
 		}
 
 		// Close MR bead with reason 'merged'
-		if err := e.beads.CloseWithReason("merged", mr.ID); err != nil {
+		if err := e.closeMerged("merged", mr.ID); err != nil {
 			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to close MR %s: %v\n", mr.ID, err)
 		} else {
 			_, _ = fmt.Fprintf(e.output, "[Engineer] Closed MR bead: %s\n", mr.ID)
@@ -603,7 +666,7 @@ func (e *Engineer) HandleMRInfoSuccess(mr *MRInfo, result ProcessResult) {
 	// 1. Close source issue with reference to MR
 	if mr.SourceIssue != "" {
 		closeReason := fmt.Sprintf("Merged in %s", mr.ID)
-		if err := e.beads.CloseWithReason(closeReason, mr.SourceIssue); err != nil {
+		if err := e.closeMerged(closeReason, mr.SourceIssue); err != nil {
 			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to close source issue %s: %v\n", mr.SourceIssue, err)
 		} else {
 			_, _ = fmt.Fprintf(e.output, "[Engineer] Closed source issue: %s\n", mr.SourceIssue)
@@ -633,6 +696,7 @@ func (e *Engineer) HandleMRInfoSuccess(mr *MRInfo, result ProcessResult) {
 	}
 
 	// 3. Log success
+	e.logMergeEvent(events.TypeMerged, mr, "")
 	_, _ = fmt.Fprintf(e.output, "[Engineer] ✓ Merged: %s (commit: %s)\n", mr.ID, result.MergeCommit)
 }
 
@@ -673,6 +737,7 @@ func (e *Engineer) HandleMRInfoFailure(mr *MRInfo, result ProcessResult) {
 	}
 
 	// Log the failure - MR stays in queue but may be blocked
+	e.logMergeEvent(events.TypeMergeFailed, mr, result.FailureType())
 	_, _ = fmt.Fprintf(e.output, "[Engineer] ✗ Failed: %s - %s\n", mr.ID, result.Error)
 	if mr.BlockedBy != "" {
 		_, _ = fmt.Fprintln(e.output, "[Engineer] MR blocked pending conflict resolution - queue continues to next MR")
@@ -681,6 +746,20 @@ func (e *Engineer) HandleMRInfoFailure(mr *MRInfo, result ProcessResult) {
 	}
 }
 
+// logMergeEvent records a merge outcome in the activity feed. Scorecards
+// attribute outcomes through the bead and score failures by type.
+func (e *Engineer) logMergeEvent(eventType string, mr *MRInfo, failure FailureType) {
+	payload := events.MergePayload(mr.ID, mr.Worker, mr.Branch, string(failure))
+	payload["rig"] = e.rig.Name
+	if mr.SourceIssue != "" {
+		payload["bead"] = mr.SourceIssue
+	}
+	if failure != FailureNone {
+		payload["failure_type"] = string(failure)
+	}
+	_ = events.LogFeed(eventType, e.rig.Name+"/refinery", payload)
+}
+
 // createConflictResolutionTaskForMR creates a dispatchable task for resolving merge conflicts.
 // This task will be picked up by bd ready and can be slung to a fresh polecat (spawned on demand).
 // Returns the created task's ID for blocking the MR until resolution.
@@ -0,0 +1,739 @@
+package rig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/hooks"
+)
+
+// Manifest declares a rig: its repository, settings, crew, setup hooks,
+// overlay files, plugins and hook overrides. `gt rig apply` converges a rig
+// to a manifest and `gt rig export` writes one from an existing rig.
+//
+// Manifests are additive: applying one creates and updates what it
+// declares, and leaves crew members and files it doesn't mention alone.
+type Manifest struct {
+	Name          string `json:"name" toml:"name"`
+	GitURL        string `json:"git_url,omitempty" toml:"git_url,omitempty"`
+	Prefix        string `json:"prefix,omitempty" toml:"prefix,omitempty"`
+	DefaultBranch string `json:"default_branch,omitempty" toml:"default_branch,omitempty"`
+	LocalRepo     string `json:"local_repo,omitempty" toml:"local_repo,omitempty"`
+
+	// DefaultFormula sets workflow.default_formula in the rig settings.
+	DefaultFormula string `json:"default_formula,omitempty" toml:"default_formula,omitempty"`
+
+	// RoleAgents sets role_agents in the rig settings (role → agent).
+	RoleAgents map[string]string `json:"role_agents,omitempty" toml:"role_agents,omitempty"`
+
+	// Settings are settings/config.json keys (merge_queue, namepool, crew,
+	// agent, witness, ...). Each top-level key replaces the rig's value.
+	Settings map[string]interface{} `json:"settings,omitempty" toml:"settings,omitempty"`
+
+	// Crew lists crew members to create.
+	Crew []string `json:"crew,omitempty" toml:"crew,omitempty"`
+
+	// SetupHooks are executables for .runtime/setup-hooks/ (see RunSetupHooks).
+	SetupHooks []ManifestFile `json:"setup_hooks,omitempty" toml:"setup_hooks,omitempty"`
+
+	// Overlay files go to .runtime/overlay/ (see CopyOverlay).
+	Overlay []ManifestFile `json:"overlay,omitempty" toml:"overlay,omitempty"`
+
+	// Plugins are rig plugins; Content is the plugin's plugin.md.
+	Plugins []ManifestFile `json:"plugins,omitempty" toml:"plugins,omitempty"`
+
+	// Hooks are Claude hook overrides for the rig's roles, keyed by role
+	// (crew, witness, refinery, polecats, rig), in the hooks-override format.
+	Hooks map[string]map[string]interface{} `json:"hooks,omitempty" toml:"hooks,omitempty"`
+}
+
+// ManifestFile is a file a manifest places in the rig.
+type ManifestFile struct {
+	Name    string `json:"name" toml:"name"`
+	Content string `json:"content" toml:"content,multiline"`
+
+	// Mode is an octal permission string (e.g., "0600"). Default: 0755
+	// for setup hooks, 0644 otherwise.
+	Mode string `json:"mode,omitempty" toml:"mode,omitempty"`
+}
+
+// LoadManifest reads a manifest file. Files ending in .json are JSON;
+// anything else is TOML.
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is user-provided by design
+	if err != nil {
+		return nil, err
+	}
+	format := "toml"
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		format = "json"
+	}
+	m, err := ParseManifest(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// ParseManifest parses a JSON or TOML manifest and validates it. Unknown
+// keys are errors, so a misspelled setting isn't silently ignored.
+func ParseManifest(data []byte, format string) (*Manifest, error) {
+	var m Manifest
+	switch format {
+	case "json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&m); err != nil {
+			return nil, fmt.Errorf("parsing manifest: %w", err)
+		}
+	case "toml":
+		md, err := toml.Decode(string(data), &m)
+		if err != nil {
+			return nil, fmt.Errorf("parsing manifest: %w", err)
+		}
+		// Keys under settings and hooks land in generic maps and are
+		// checked by Validate.
+		for _, key := range md.Undecoded() {
+			if len(key) > 0 && key[0] != "settings" && key[0] != "hooks" {
+				return nil, fmt.Errorf("parsing manifest: unknown key %q", key.String())
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown manifest format %q", format)
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Encode writes the manifest as JSON or TOML.
+func (m *Manifest) Encode(format string) ([]byte, error) {
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(m, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	case "toml":
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(m); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unknown manifest format %q", format)
+	}
+}
+
+// Validate checks names, file modes, settings keys and hook roles.
+func (m *Manifest) Validate() error {
+	if m.Name == "" {
+		return fmt.Errorf("manifest missing name")
+	}
+	if !isSafeName(m.Name) {
+		return fmt.Errorf("invalid rig name %q", m.Name)
+	}
+	if m.Prefix != "" && !isValidBeadsPrefix(m.Prefix) {
+		return fmt.Errorf("invalid beads prefix %q", m.Prefix)
+	}
+	for _, name := range m.Crew {
+		if !isSafeName(name) {
+			return fmt.Errorf("invalid crew name %q", name)
+		}
+	}
+	for kind, files := range map[string][]ManifestFile{"setup_hooks": m.SetupHooks, "overlay": m.Overlay, "plugins": m.Plugins} {
+		seen := make(map[string]bool)
+		for _, f := range files {
+			if !isSafeName(f.Name) {
+				return fmt.Errorf("%s: invalid name %q", kind, f.Name)
+			}
+			if seen[f.Name] {
+				return fmt.Errorf("%s: %s listed twice", kind, f.Name)
+			}
+			seen[f.Name] = true
+			if _, err := f.mode(0644); err != nil {
+				return fmt.Errorf("%s: %s: %w", kind, f.Name, err)
+			}
+		}
+	}
+	if _, err := decodeSettings(m.Settings, true); err != nil {
+		return fmt.Errorf("settings: %w", err)
+	}
+	for role, override := range m.Hooks {
+		if !manifestHookRoles[role] {
+			return fmt.Errorf("hooks: invalid role %q (want crew, polecats, refinery, rig or witness)", role)
+		}
+		if _, err := decodeHooks(override); err != nil {
+			return fmt.Errorf("hooks.%s: %w", role, err)
+		}
+	}
+	return nil
+}
+
+// manifestHookRoles are the hook override roles that belong to a rig.
+var manifestHookRoles = map[string]bool{
+	"crew": true, "polecats": true, "refinery": true, "rig": true, "witness": true,
+}
+
+func (f ManifestFile) mode(def os.FileMode) (os.FileMode, error) {
+	if f.Mode == "" {
+		return def, nil
+	}
+	n, err := strconv.ParseUint(f.Mode, 8, 32)
+	if err != nil || n > 0777 {
+		return 0, fmt.Errorf("invalid mode %q", f.Mode)
+	}
+	return os.FileMode(n), nil
+}
+
+// isSafeName reports whether name can be used as a single path element.
+func isSafeName(name string) bool {
+	return name != "" && name != "." && name != ".." &&
+		!strings.ContainsAny(name, `/\`) && !strings.HasPrefix(name, "-")
+}
+
+// ManifestChange is one step of converging a rig to its manifest.
+type ManifestChange struct {
+	Action string `json:"action"` // "create" or "update"
+	Kind   string `json:"kind"`   // "rig", "settings", "crew", "setup_hook", "overlay", "plugin", "hooks"
+	Name   string `json:"name"`
+
+	// Path is the file written, for file changes.
+	Path string `json:"path,omitempty"`
+
+	// Diff shows changed lines ("-" current, "+" manifest) for updates.
+	// Overlay contents often hold secrets and are left out.
+	Diff string `json:"diff,omitempty"`
+
+	content []byte
+	mode    os.FileMode
+}
+
+// ManifestDrift is a field the manifest sets differently from an existing
+// rig that applying can't change (the repository and beads are fixed when
+// the rig is created).
+type ManifestDrift struct {
+	Field    string `json:"field"` // "git_url", "prefix", "default_branch" or "local_repo"
+	Current  string `json:"current"`
+	Manifest string `json:"manifest"`
+}
+
+// ManifestPlan is what applying a manifest would change. An empty plan
+// means the rig already matches.
+type ManifestPlan struct {
+	Rig     string           `json:"rig"`
+	Changes []ManifestChange `json:"changes"`
+
+	// Drift lists fields that differ but can't be converged.
+	Drift []ManifestDrift `json:"drift,omitempty"`
+}
+
+// CreatesRig reports whether the plan starts by creating the rig. Until the
+// rig exists, its other changes are planned against an empty rig.
+func (p *ManifestPlan) CreatesRig() bool {
+	return len(p.Changes) > 0 && p.Changes[0].Kind == "rig"
+}
+
+// PlanManifest compares a rig with its manifest.
+func PlanManifest(townRoot string, m *Manifest) (*ManifestPlan, error) {
+	rigPath := filepath.Join(townRoot, m.Name)
+	plan := &ManifestPlan{Rig: m.Name, Changes: []ManifestChange{}}
+	add := func(c ManifestChange) { plan.Changes = append(plan.Changes, c) }
+
+	if rc, err := LoadRigConfig(rigPath); err != nil {
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("reading rig config: %w", err)
+		}
+		if m.GitURL == "" {
+			return nil, fmt.Errorf("rig %s does not exist and the manifest has no git_url", m.Name)
+		}
+		add(ManifestChange{Action: "create", Kind: "rig", Name: m.Name, Diff: "+ " + m.GitURL})
+	} else {
+		plan.Drift = m.drift(rc)
+	}
+
+	// Settings: merge the manifest's keys over the current file.
+	settingsPath := config.RigSettingsPath(rigPath)
+	current, err := readSettingsMap(settingsPath)
+	if err != nil {
+		return nil, err
+	}
+	desired := m.settingsOver(current)
+	currentJSON, err := canonicalSettings(current)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", settingsPath, err)
+	}
+	desiredJSON, err := canonicalSettings(desired)
+	if err != nil {
+		return nil, fmt.Errorf("settings: %w", err)
+	}
+	if current == nil && (len(m.Settings) > 0 || len(m.RoleAgents) > 0 || m.DefaultFormula != "") {
+		add(ManifestChange{Action: "create", Kind: "settings", Name: "settings/config.json", Path: settingsPath,
+			Diff: lineDiff("", string(desiredJSON)), content: desiredJSON, mode: 0644})
+	} else if current != nil && !bytes.Equal(currentJSON, desiredJSON) {
+		add(ManifestChange{Action: "update", Kind: "settings", Name: "settings/config.json", Path: settingsPath,
+			Diff: lineDiff(string(currentJSON), string(desiredJSON)), content: desiredJSON, mode: 0644})
+	}
+
+	for _, name := range m.Crew {
+		if _, err := os.Stat(filepath.Join(rigPath, "crew", name)); os.IsNotExist(err) {
+			add(ManifestChange{Action: "create", Kind: "crew", Name: name})
+		}
+	}
+
+	fileSets := []struct {
+		kind  string
+		files []ManifestFile
+		path  func(name string) string
+		mode  os.FileMode
+	}{
+		{"setup_hook", m.SetupHooks, func(n string) string { return filepath.Join(rigPath, ".runtime", "setup-hooks", n) }, 0755},
+		{"overlay", m.Overlay, func(n string) string { return filepath.Join(rigPath, ".runtime", "overlay", n) }, 0644},
+		{"plugin", m.Plugins, func(n string) string { return filepath.Join(rigPath, "plugins", n, "plugin.md") }, 0644},
+	}
+	for _, set := range fileSets {
+		for _, f := range set.files {
+			mode, _ := f.mode(set.mode)
+			if c, ok := planFile(set.kind, f.Name, set.path(f.Name), []byte(f.Content), mode); ok {
+				add(c)
+			}
+		}
+	}
+
+	roles := make([]string, 0, len(m.Hooks))
+	for role := range m.Hooks {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	for _, role := range roles {
+		cfg, err := decodeHooks(m.Hooks[role])
+		if err != nil {
+			return nil, err
+		}
+		data, err := hooks.MarshalConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		target := m.Name + "/" + role
+		if c, ok := planFile("hooks", target, hooks.OverridePath(target), data, 0644); ok {
+			add(c)
+		}
+	}
+	return plan, nil
+}
+
+// drift returns the fields the manifest sets that differ from the rig's
+// config. Fields the manifest leaves empty don't drift.
+func (m *Manifest) drift(rc *RigConfig) []ManifestDrift {
+	prefix := ""
+	if rc.Beads != nil {
+		prefix = rc.Beads.Prefix
+	}
+	fields := []ManifestDrift{
+		{Field: "git_url", Current: rc.GitURL, Manifest: m.GitURL},
+		{Field: "prefix", Current: strings.TrimSuffix(prefix, "-"), Manifest: strings.TrimSuffix(m.Prefix, "-")},
+		{Field: "default_branch", Current: rc.DefaultBranch, Manifest: m.DefaultBranch},
+		{Field: "local_repo", Current: rc.LocalRepo, Manifest: m.LocalRepo},
+	}
+	var drift []ManifestDrift
+	for _, f := range fields {
+		if f.Manifest != "" && f.Manifest != f.Current {
+			drift = append(drift, f)
+		}
+	}
+	return drift
+}
+
+// planFile compares a file with the content a manifest gives it.
+func planFile(kind, name, path string, content []byte, mode os.FileMode) (ManifestChange, bool) {
+	c := ManifestChange{Kind: kind, Name: name, Path: path, content: content, mode: mode}
+	info, err := os.Stat(path)
+	if err != nil {
+		c.Action = "create"
+		if kind != "overlay" {
+			c.Diff = lineDiff("", string(content))
+		}
+		return c, true
+	}
+	current, err := os.ReadFile(path) //nolint:gosec // G304: path is within the rig
+	if err != nil {
+		c.Action = "create"
+		return c, true
+	}
+	if bytes.Equal(current, content) && info.Mode().Perm() == mode {
+		return c, false
+	}
+	c.Action = "update"
+	if kind != "overlay" {
+		c.Diff = lineDiff(string(current), string(content))
+	}
+	if info.Mode().Perm() != mode {
+		c.Diff = fmt.Sprintf("- mode %04o\n+ mode %04o\n", info.Mode().Perm(), mode) + c.Diff
+	}
+	return c, true
+}
+
+// WriteFiles writes the plan's file changes (settings, setup hooks, overlay
+// files, plugins and hook overrides). Rig and crew creation are left to the
+// caller.
+func (p *ManifestPlan) WriteFiles() error {
+	for _, c := range p.Changes {
+		if c.Path == "" {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(c.Path), 0755); err != nil {
+			return fmt.Errorf("%s %s: %w", c.Kind, c.Name, err)
+		}
+		if err := os.WriteFile(c.Path, c.content, c.mode); err != nil {
+			return fmt.Errorf("%s %s: %w", c.Kind, c.Name, err)
+		}
+		// WriteFile leaves an existing file's mode alone.
+		if err := os.Chmod(c.Path, c.mode); err != nil {
+			return fmt.Errorf("%s %s: %w", c.Kind, c.Name, err)
+		}
+	}
+	return nil
+}
+
+// settingsOver returns current with the manifest's settings keys, role
+// agents and default formula applied.
+func (m *Manifest) settingsOver(current map[string]interface{}) map[string]interface{} {
+	out := map[string]interface{}{"type": "rig-settings", "version": config.CurrentRigSettingsVersion}
+	for k, v := range current {
+		out[k] = v
+	}
+	for k, v := range m.Settings {
+		out[k] = v
+	}
+	if len(m.RoleAgents) > 0 {
+		agents := make(map[string]interface{}, len(m.RoleAgents))
+		for role, agent := range m.RoleAgents {
+			agents[role] = agent
+		}
+		out["role_agents"] = agents
+	}
+	if m.DefaultFormula != "" {
+		workflow := make(map[string]interface{})
+		if existing, ok := out["workflow"].(map[string]interface{}); ok {
+			for k, v := range existing {
+				workflow[k] = v
+			}
+		}
+		workflow["default_formula"] = m.DefaultFormula
+		out["workflow"] = workflow
+	}
+	return out
+}
+
+// readSettingsMap reads settings/config.json as a generic map, or nil if
+// the rig has no settings file.
+func readSettingsMap(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is within the rig
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading settings: %w", err)
+	}
+	var settings map[string]interface{}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return settings, nil
+}
+
+// decodeSettings converts a settings map to RigSettings. Strict decoding
+// rejects keys RigSettings doesn't have.
+func decodeSettings(settings map[string]interface{}, strict bool) (*config.RigSettings, error) {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if strict {
+		dec.DisallowUnknownFields()
+	}
+	var rs config.RigSettings
+	if err := dec.Decode(&rs); err != nil {
+		return nil, err
+	}
+	return &rs, nil
+}
+
+// canonicalSettings validates settings and renders them with sorted keys,
+// so formatting differences don't count as changes. The map itself is
+// rendered rather than the decoded RigSettings, so keys this version of gt
+// doesn't know survive an apply.
+func canonicalSettings(settings map[string]interface{}) ([]byte, error) {
+	if settings == nil {
+		return nil, nil
+	}
+	rs, err := decodeSettings(settings, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.ValidateRigSettings(rs); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(settings, "", "  ")
+}
+
+func decodeHooks(override map[string]interface{}) (*hooks.HooksConfig, error) {
+	data, err := json.Marshal(override)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var cfg hooks.HooksConfig
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// lineDiff lists the lines removed from old ("- ") and added in new ("+ "),
+// in order, using a longest-common-subsequence match.
+func lineDiff(old, new string) string {
+	a, b := manifestLines(old), manifestLines(new)
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var out strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] >= lcs[i+1][j]):
+			out.WriteString("+ " + b[j] + "\n")
+			j++
+		default:
+			out.WriteString("- " + a[i] + "\n")
+			i++
+		}
+	}
+	return out.String()
+}
+
+func manifestLines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// ExportManifest builds a manifest from an existing rig. Overlay files
+// usually hold secrets (.env), so their contents are only included when
+// asked for. Settings keys this version of gt doesn't know are left out,
+// since a manifest rejects them; applying keeps them in the rig's file.
+func ExportManifest(townRoot, rigName string, includeOverlay bool) (*Manifest, error) {
+	rigPath := filepath.Join(townRoot, rigName)
+	rc, err := LoadRigConfig(rigPath)
+	if err != nil {
+		return nil, fmt.Errorf("loading rig config: %w", err)
+	}
+	m := &Manifest{
+		Name:          rigName,
+		GitURL:        rc.GitURL,
+		DefaultBranch: rc.DefaultBranch,
+		LocalRepo:     rc.LocalRepo,
+	}
+	if rc.Beads != nil {
+		m.Prefix = rc.Beads.Prefix
+	}
+
+	settings, err := readSettingsMap(config.RigSettingsPath(rigPath))
+	if err != nil {
+		return nil, err
+	}
+	if settings != nil {
+		delete(settings, "type")
+		delete(settings, "version")
+		if agents, ok := settings["role_agents"].(map[string]interface{}); ok {
+			m.RoleAgents = make(map[string]string, len(agents))
+			for role, agent := range agents {
+				m.RoleAgents[role] = fmt.Sprint(agent)
+			}
+			delete(settings, "role_agents")
+		}
+		if workflow, ok := settings["workflow"].(map[string]interface{}); ok {
+			m.DefaultFormula, _ = workflow["default_formula"].(string)
+			delete(workflow, "default_formula")
+			if len(workflow) == 0 {
+				delete(settings, "workflow")
+			}
+		}
+		knownKeys(settings, reflect.TypeOf(config.RigSettings{}))
+		if len(settings) > 0 {
+			m.Settings = integralNumbers(settings).(map[string]interface{})
+		}
+	}
+
+	if entries, err := os.ReadDir(filepath.Join(rigPath, "crew")); err == nil {
+		for _, e := range entries {
+			if e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
+				m.Crew = append(m.Crew, e.Name())
+			}
+		}
+	}
+
+	if m.SetupHooks, err = exportFiles(filepath.Join(rigPath, ".runtime", "setup-hooks"), 0755); err != nil {
+		return nil, err
+	}
+	if includeOverlay {
+		if m.Overlay, err = exportFiles(filepath.Join(rigPath, ".runtime", "overlay"), 0644); err != nil {
+			return nil, err
+		}
+	}
+
+	if entries, err := os.ReadDir(filepath.Join(rigPath, "plugins")); err == nil {
+		for _, e := range entries {
+			if !e.IsDir() {
+				continue
+			}
+			content, err := os.ReadFile(filepath.Join(rigPath, "plugins", e.Name(), "plugin.md")) //nolint:gosec // G304: path is within the rig
+			if err != nil {
+				continue // Not a plugin
+			}
+			m.Plugins = append(m.Plugins, ManifestFile{Name: e.Name(), Content: string(content)})
+		}
+	}
+
+	for role := range manifestHookRoles {
+		data, err := os.ReadFile(hooks.OverridePath(rigName + "/" + role))
+		if err != nil {
+			continue
+		}
+		var override map[string]interface{}
+		if err := json.Unmarshal(data, &override); err != nil {
+			return nil, fmt.Errorf("hooks override %s/%s: %w", rigName, role, err)
+		}
+		if m.Hooks == nil {
+			m.Hooks = make(map[string]map[string]interface{})
+		}
+		m.Hooks[role] = integralNumbers(override).(map[string]interface{})
+	}
+	return m, nil
+}
+
+// exportFiles reads the regular files in dir, recording modes that differ
+// from def.
+func exportFiles(dir string, def os.FileMode) ([]ManifestFile, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var files []ManifestFile
+	for _, e := range entries {
+		if !e.Type().IsRegular() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		content, err := os.ReadFile(filepath.Join(dir, e.Name())) //nolint:gosec // G304: path is within the rig
+		if err != nil {
+			return nil, err
+		}
+		f := ManifestFile{Name: e.Name(), Content: string(content)}
+		if perm := info.Mode().Perm(); perm != def {
+			f.Mode = fmt.Sprintf("%04o", perm)
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// integralNumbers turns whole float64 values from JSON into int64, so TOML
+// output says 5 rather than 5.0.
+func integralNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = integralNumbers(e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = integralNumbers(e)
+		}
+	case float64:
+		if v == float64(int64(v)) {
+			return int64(v)
+		}
+	}
+	return v
+}
+
+// knownKeys removes the keys of v that the JSON form of t doesn't have,
+// at every level.
+func knownKeys(v interface{}, t reflect.Type) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return
+		}
+		fields := make(map[string]reflect.Type, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" || !f.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			fields[name] = f.Type
+		}
+		for k, e := range m {
+			if ft, ok := fields[k]; ok {
+				knownKeys(e, ft)
+			} else {
+				delete(m, k)
+			}
+		}
+	case reflect.Map:
+		if m, ok := v.(map[string]interface{}); ok {
+			for _, e := range m {
+				knownKeys(e, t.Elem())
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if s, ok := v.([]interface{}); ok {
+			for _, e := range s {
+				knownKeys(e, t.Elem())
+			}
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package rig
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testManifest = `
+name = "gastown"
+git_url = "https://github.com/example/gastown.git"
+prefix = "gt"
+default_formula = "shiny"
+crew = ["max"]
+
+[role_agents]
+polecat = "codex"
+
+[settings.merge_queue]
+enabled = true
+run_tests = true
+test_command = "go test ./..."
+
+[settings.namepool]
+style = "minerals"
+
+[[setup_hooks]]
+name = "01-git-config.sh"
+content = """
+#!/bin/sh
+git config user.name polecat
+"""
+
+[[overlay]]
+name = ".env"
+mode = "0600"
+content = "TOKEN=abc\n"
+
+[[plugins]]
+name = "lint"
+content = "+++\nname = \"lint\"\n+++\nRun the linter.\n"
+
+[hooks.crew]
+[[hooks.crew.SessionStart]]
+matcher = ""
+[[hooks.crew.SessionStart.hooks]]
+type = "command"
+command = "gt prime"
+`
+
+// setupManifestRig creates a minimal existing rig with settings.
+func setupManifestRig(t *testing.T) string {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	townRoot := t.TempDir()
+	rigPath := filepath.Join(townRoot, "gastown")
+	for _, dir := range []string{"settings", "crew/max"} {
+		if err := os.MkdirAll(filepath.Join(rigPath, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := `{"type":"rig","version":1,"name":"gastown","git_url":"https://github.com/example/gastown.git","beads":{"prefix":"gt"}}`
+	if err := os.WriteFile(filepath.Join(rigPath, "config.json"), []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+	settings := `{"type":"rig-settings","version":1,"theme":{"name":"rust"},"merge_queue":{"enabled":false},"x_local":{"note":"keep"}}`
+	if err := os.WriteFile(filepath.Join(rigPath, "settings", "config.json"), []byte(settings), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return townRoot
+}
+
+func TestPlanManifest_ConvergesIdempotently(t *testing.T) {
+	townRoot := setupManifestRig(t)
+	m, err := ParseManifest([]byte(testManifest), "toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := PlanManifest(townRoot, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := make(map[string]ManifestChange)
+	for _, c := range plan.Changes {
+		kinds[c.Kind] = c
+	}
+	if plan.CreatesRig() || len(plan.Changes) != 5 {
+		t.Fatalf("plan = %+v, want settings, setup hook, overlay, plugin and hooks changes", plan.Changes)
+	}
+	if s := kinds["settings"]; s.Action != "update" || !strings.Contains(s.Diff, `+     "enabled": true`) {
+		t.Errorf("settings change = %+v", s)
+	}
+	if o := kinds["overlay"]; o.Action != "create" || o.Diff != "" {
+		t.Errorf("overlay change = %+v, want no diff", o)
+	}
+	if len(plan.Drift) != 0 {
+		t.Errorf("drift = %+v, want none", plan.Drift)
+	}
+
+	if err := plan.WriteFiles(); err != nil {
+		t.Fatal(err)
+	}
+	again, err := PlanManifest(townRoot, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again.Changes) != 0 {
+		t.Errorf("second plan = %+v, want no changes", again.Changes)
+	}
+
+	rigPath := filepath.Join(townRoot, "gastown")
+	var settings map[string]interface{}
+	data, _ := os.ReadFile(filepath.Join(rigPath, "settings", "config.json"))
+	if err := json.Unmarshal(data, &settings); err != nil {
+		t.Fatal(err)
+	}
+	if settings["theme"] == nil || settings["x_local"] == nil || settings["workflow"].(map[string]interface{})["default_formula"] != "shiny" {
+		t.Errorf("settings = %s", data)
+	}
+	if info, err := os.Stat(filepath.Join(rigPath, ".runtime", "overlay", ".env")); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("overlay .env = %v, %v", info, err)
+	}
+	if info, err := os.Stat(filepath.Join(rigPath, ".runtime", "setup-hooks", "01-git-config.sh")); err != nil || info.Mode().Perm() != 0755 {
+		t.Errorf("setup hook = %v, %v", info, err)
+	}
+}
+
+func TestPlanManifest_Drift(t *testing.T) {
+	townRoot := setupManifestRig(t)
+	m, err := ParseManifest([]byte(`name = "gastown"
+git_url = "https://github.com/example/fork.git"
+prefix = "gt-"
+default_branch = "develop"
+`), "toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err := PlanManifest(townRoot, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ManifestDrift{
+		{Field: "git_url", Current: "https://github.com/example/gastown.git", Manifest: "https://github.com/example/fork.git"},
+		{Field: "default_branch", Current: "", Manifest: "develop"},
+	}
+	if len(plan.Drift) != len(want) {
+		t.Fatalf("drift = %+v, want %+v", plan.Drift, want)
+	}
+	for i := range want {
+		if plan.Drift[i] != want[i] {
+			t.Errorf("drift[%d] = %+v, want %+v", i, plan.Drift[i], want[i])
+		}
+	}
+	if len(plan.Changes) != 0 {
+		t.Errorf("changes = %+v, want none", plan.Changes)
+	}
+}
+
+func TestPlanManifest_OverlayUpdateHasNoDiff(t *testing.T) {
+	townRoot := setupManifestRig(t)
+	path := filepath.Join(townRoot, "gastown", ".runtime", "overlay", ".env")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("TOKEN=old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := &Manifest{Name: "gastown", Overlay: []ManifestFile{{Name: ".env", Mode: "0600", Content: "TOKEN=new\n"}}}
+	plan, err := PlanManifest(townRoot, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Changes) != 1 || plan.Changes[0].Action != "update" {
+		t.Fatalf("changes = %+v, want one overlay update", plan.Changes)
+	}
+	if d := plan.Changes[0].Diff; strings.Contains(d, "TOKEN") || !strings.Contains(d, "+ mode 0600") {
+		t.Errorf("overlay diff = %q, want only the mode change", d)
+	}
+}
+
+func TestPlanManifest_NewRig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	m, err := ParseManifest([]byte(`name = "fresh"`+"\n"+`crew = ["joe"]`), "toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := PlanManifest(t.TempDir(), m); err == nil {
+		t.Error("creating a rig without git_url should fail")
+	}
+
+	m.GitURL = "https://github.com/example/fresh.git"
+	plan, err := PlanManifest(t.TempDir(), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !plan.CreatesRig() || len(plan.Changes) != 2 || plan.Changes[1].Kind != "crew" {
+		t.Errorf("plan = %+v", plan.Changes)
+	}
+}
+
+func TestExportManifest_RoundTrip(t *testing.T) {
+	townRoot := setupManifestRig(t)
+	m, err := ParseManifest([]byte(testManifest), "toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err := PlanManifest(townRoot, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := plan.WriteFiles(); err != nil {
+		t.Fatal(err)
+	}
+
+	exported, err := ExportManifest(townRoot, "gastown", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exported.DefaultFormula != "shiny" || exported.RoleAgents["polecat"] != "codex" || len(exported.Overlay) != 0 {
+		t.Errorf("exported = %+v", exported)
+	}
+	if _, ok := exported.Settings["role_agents"]; ok {
+		t.Error("role_agents should be exported at the top level only")
+	}
+
+	for _, format := range []string{"toml", "json"} {
+		data, err := exported.Encode(format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if format == "toml" && strings.Contains(string(data), "5.0") {
+			t.Errorf("whole numbers exported as floats:\n%s", data)
+		}
+		parsed, err := ParseManifest(data, format)
+		if err != nil {
+			t.Fatalf("%s: %v\n%s", format, err, data)
+		}
+		plan, err := PlanManifest(townRoot, parsed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(plan.Changes) != 0 {
+			t.Errorf("%s: re-applying the export changes %+v", format, plan.Changes)
+		}
+	}
+}
+
+func TestParseManifest_Rejects(t *testing.T) {
+	tests := map[string]string{
+		"unknown key":     "name = \"x\"\ncrews = [\"max\"]",
+		"unknown setting": "name = \"x\"\n[settings]\nmerge_q = {}",
+		"path in name":    "name = \"x\"\n[[overlay]]\nname = \"../../etc/passwd\"\ncontent = \"\"",
+		"bad mode":        "name = \"x\"\n[[overlay]]\nname = \".env\"\nmode = \"rw\"\ncontent = \"\"",
+		"bad hook role":   "name = \"x\"\n[hooks.mayor]",
+		"missing name":    "crew = [\"max\"]",
+	}
+	for name, manifest := range tests {
+		if _, err := ParseManifest([]byte(manifest), "toml"); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestLineDiff(t *testing.T) {
+	got := lineDiff("a\nb\nc\n", "a\nx\nc\nd\n")
+	want := "+ x\n- b\n+ d\n"
+	if got != want {
+		t.Errorf("lineDiff = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,174 @@
+package routing
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// Outcome counts resolved slings for one history key.
+type Outcome struct {
+	Successes int
+	Failures  int
+}
+
+// Total returns the number of resolved slings.
+func (o Outcome) Total() int {
+	return o.Successes + o.Failures
+}
+
+// record is one resolved sling.
+type record struct {
+	key     string
+	caps    []string
+	success bool
+}
+
+// History holds resolved sling outcomes derived from the events log.
+type History struct {
+	records []record
+}
+
+// pendingSling is a sling that has not yet produced an outcome.
+type pendingSling struct {
+	key  string
+	caps []string
+}
+
+// LoadHistory reads sling outcomes from an events log (.events.jsonl).
+// A missing file yields an empty history.
+//
+// A sling succeeds when its bead later gets a done event with exit type
+// COMPLETED (or no exit type, for events written before it was recorded).
+// It fails when the bead is escalated or re-slung to another target before
+// completing. Slings still in flight are ignored.
+func LoadHistory(path string) (*History, error) {
+	f, err := os.Open(path) //nolint:gosec // G304: path is the town events log
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &History{}, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	h := &History{}
+	pending := make(map[string]pendingSling) // bead -> in-flight sling
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e events.Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		bead, _ := e.Payload["bead"].(string)
+		if bead == "" {
+			continue
+		}
+
+		switch e.Type {
+		case events.TypeSling:
+			target, _ := e.Payload["target"].(string)
+			if prev, ok := pending[bead]; ok {
+				h.records = append(h.records, record{key: prev.key, caps: prev.caps, success: false})
+			}
+			pending[bead] = pendingSling{key: KeyForTarget(target), caps: payloadStrings(e.Payload["capabilities"])}
+
+		case events.TypeDone:
+			p, ok := pending[bead]
+			if !ok {
+				continue
+			}
+			exitType, _ := e.Payload["exit_type"].(string)
+			switch exitType {
+			case "", "COMPLETED":
+				h.records = append(h.records, record{key: p.key, caps: p.caps, success: true})
+				delete(pending, bead)
+			case "ESCALATED":
+				h.records = append(h.records, record{key: p.key, caps: p.caps, success: false})
+				delete(pending, bead)
+			}
+		}
+	}
+	return h, scanner.Err()
+}
+
+// KeyForTarget maps a sling target address to a history key. Individual
+// polecats are pooled per rig because each one is ephemeral.
+func KeyForTarget(target string) string {
+	parts := strings.Split(strings.TrimSuffix(target, "/"), "/")
+	if len(parts) >= 2 && parts[1] == "polecats" {
+		return parts[0] + "/polecats"
+	}
+	return strings.TrimSuffix(target, "/")
+}
+
+// Add records an outcome directly. Used by tests and callers that derive
+// outcomes from sources other than the events log.
+func (h *History) Add(key string, caps []string, success bool) {
+	h.records = append(h.records, record{key: key, caps: caps, success: success})
+}
+
+// Outcomes returns the counts for a key restricted to slings that share at
+// least one capability with required (all slings when required is empty).
+func (h *History) Outcomes(key string, required []string) Outcome {
+	var o Outcome
+	if h == nil {
+		return o
+	}
+	for _, r := range h.records {
+		if r.key != key || !similar(r.caps, required) {
+			continue
+		}
+		if r.success {
+			o.Successes++
+		} else {
+			o.Failures++
+		}
+	}
+	return o
+}
+
+// Rate returns the Laplace-smoothed success rate for a key on beads similar
+// to required, and the number of samples. With no history the rate is 0.5.
+// If there is no history on similar beads, the key's overall record is used.
+func (h *History) Rate(key string, required []string) (float64, int) {
+	o := h.Outcomes(key, required)
+	if o.Total() == 0 && len(required) > 0 {
+		o = h.Outcomes(key, nil)
+	}
+	return (float64(o.Successes) + 1) / (float64(o.Total()) + 2), o.Total()
+}
+
+// similar reports whether a past sling's capabilities overlap required.
+func similar(caps, required []string) bool {
+	if len(required) == 0 {
+		return true
+	}
+	for _, c := range caps {
+		if slices.Contains(required, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// payloadStrings converts a JSON-decoded []interface{} to []string.
+func payloadStrings(v interface{}) []string {
+	items, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,95 @@
+package routing
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeEvents(t *testing.T, lines ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ".events.jsonl")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadHistory(t *testing.T) {
+	path := writeEvents(t,
+		// Completed by a polecat.
+		`{"type":"sling","payload":{"bead":"gt-1","target":"gastown/polecats/Toast","capabilities":["rust"]}}`,
+		`{"type":"done","payload":{"bead":"gt-1","exit_type":"COMPLETED"}}`,
+		// Escalated by crew.
+		`{"type":"sling","payload":{"bead":"gt-2","target":"gastown/crew/max","capabilities":["rust"]}}`,
+		`{"type":"done","payload":{"bead":"gt-2","exit_type":"ESCALATED"}}`,
+		// Re-slung: counts as failure for the first target, second still in flight.
+		`{"type":"sling","payload":{"bead":"gt-3","target":"gastown/crew/max","capabilities":["db"]}}`,
+		`{"type":"sling","payload":{"bead":"gt-3","target":"gastown/polecats/Nux"}}`,
+		// Legacy done without exit type counts as success.
+		`{"type":"sling","payload":{"bead":"gt-4","target":"gastown/polecats/Nux"}}`,
+		`{"type":"done","payload":{"bead":"gt-4"}}`,
+		// Deferred does not resolve; garbage is skipped.
+		`{"type":"sling","payload":{"bead":"gt-5","target":"gastown/crew/ann"}}`,
+		`{"type":"done","payload":{"bead":"gt-5","exit_type":"DEFERRED"}}`,
+		`not json`,
+	)
+
+	h, err := LoadHistory(path)
+	if err != nil {
+		t.Fatalf("LoadHistory: %v", err)
+	}
+
+	if o := h.Outcomes("gastown/polecats", nil); o.Successes != 2 || o.Failures != 0 {
+		t.Errorf("polecats outcomes = %+v, want 2/0", o)
+	}
+	if o := h.Outcomes("gastown/crew/max", nil); o.Successes != 0 || o.Failures != 2 {
+		t.Errorf("max outcomes = %+v, want 0/2", o)
+	}
+	if o := h.Outcomes("gastown/crew/max", []string{"rust"}); o.Total() != 1 {
+		t.Errorf("max rust outcomes = %+v, want 1 total", o)
+	}
+	if o := h.Outcomes("gastown/crew/ann", nil); o.Total() != 0 {
+		t.Errorf("ann outcomes = %+v, want none", o)
+	}
+}
+
+func TestLoadHistory_Missing(t *testing.T) {
+	h, err := LoadHistory(filepath.Join(t.TempDir(), "nope.jsonl"))
+	if err != nil {
+		t.Fatalf("LoadHistory: %v", err)
+	}
+	if rate, n := h.Rate("x", nil); rate != 0.5 || n != 0 {
+		t.Errorf("Rate = %v/%d, want 0.5/0", rate, n)
+	}
+}
+
+func TestHistoryRate_FallsBackToOverall(t *testing.T) {
+	h := &History{}
+	h.Add("gastown/polecats", []string{"go"}, true)
+	h.Add("gastown/polecats", []string{"go"}, true)
+
+	rate, n := h.Rate("gastown/polecats", []string{"rust"})
+	if n != 2 || rate != 0.75 {
+		t.Errorf("Rate = %v/%d, want 0.75/2 from overall record", rate, n)
+	}
+
+	var nilHist *History
+	if rate, _ := nilHist.Rate("x", nil); rate != 0.5 {
+		t.Errorf("nil history rate = %v, want 0.5", rate)
+	}
+}
+
+func TestKeyForTarget(t *testing.T) {
+	tests := map[string]string{
+		"gastown/polecats/Toast": "gastown/polecats",
+		"gastown/crew/max/":      "gastown/crew/max",
+		"mayor":                  "mayor",
+	}
+	for in, want := range tests {
+		if got := KeyForTarget(in); got != want {
+			t.Errorf("KeyForTarget(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
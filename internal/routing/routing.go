@@ -0,0 +1,259 @@
+// Package routing picks the best sling target for a bead based on the
+// capabilities the bead requires and the capabilities agents advertise.
+//
+// Beads declare requirements with "needs:<capability>" labels. Candidates
+// (idle crew members and fresh polecats, one per agent preset) advertise
+// capabilities through role definitions, agent_capabilities settings and
+// per-crew configuration. Route filters out candidates missing a required
+// capability and ranks the rest by availability, pool headroom, their
+// recent success rate on similar beads and, when a scorecard is available,
+// how often their merge requests land on the first attempt.
+package routing
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// CapabilityLabelPrefix marks a bead label as a required capability.
+const CapabilityLabelPrefix = "needs:"
+
+// Kind is the type of sling target a candidate represents.
+type Kind string
+
+const (
+	// KindCrew is an existing crew member's session.
+	KindCrew Kind = "crew"
+
+	// KindPolecat is a freshly spawned polecat in a rig.
+	KindPolecat Kind = "polecat"
+)
+
+// Scoring weights. Success rate dominates so that a proven target wins
+// over a merely available one; idle crew beat fresh polecats because they
+// need no spawn.
+const (
+	weightIdleCrew     = 30.0
+	weightPoolHeadroom = 20.0
+	weightSuccess      = 40.0
+	weightReliability  = 20.0
+	penaltyExtraCap    = 2.0
+)
+
+// RequiredCapabilities extracts required capabilities from bead labels.
+// The result is sorted and de-duplicated.
+func RequiredCapabilities(labels []string) []string {
+	var caps []string
+	for _, l := range labels {
+		if c, ok := strings.CutPrefix(l, CapabilityLabelPrefix); ok {
+			c = strings.TrimSpace(c)
+			if c != "" && !slices.Contains(caps, c) {
+				caps = append(caps, c)
+			}
+		}
+	}
+	sort.Strings(caps)
+	return caps
+}
+
+// Candidate is a possible sling target.
+type Candidate struct {
+	// Target is the sling target string: "<rig>/crew/<name>" for crew,
+	// "<rig>" for a fresh polecat.
+	Target string
+
+	// Kind is crew or polecat.
+	Kind Kind
+
+	// Rig is the rig the candidate works in.
+	Rig string
+
+	// Agent is the agent preset the candidate runs.
+	Agent string
+
+	// Capabilities are all capabilities the candidate advertises.
+	Capabilities []string
+
+	// Idle is true for crew members whose session is running with an
+	// empty hook. Busy crew are never chosen.
+	Idle bool
+
+	// PoolUsed and PoolMax describe polecat pool occupancy for polecat
+	// candidates. PoolMax == 0 means unlimited.
+	PoolUsed int
+	PoolMax  int
+
+	// Reliability is the candidate's smoothed first-pass merge rate from
+	// its scorecard, over ReliabilitySamples merge requests. With no
+	// samples it does not affect the score.
+	Reliability        float64
+	ReliabilitySamples int
+}
+
+// HistoryKey returns the key under which outcomes for this candidate are
+// recorded: the crew address for crew, "<rig>/polecats" for polecats.
+func (c Candidate) HistoryKey() string {
+	if c.Kind == KindPolecat {
+		return c.Rig + "/polecats"
+	}
+	return c.Target
+}
+
+// Score is a ranked candidate.
+type Score struct {
+	Candidate
+
+	// Eligible is false when the candidate cannot take the bead.
+	Eligible bool
+
+	// Score is the ranking score (higher is better). Zero when ineligible.
+	Score float64
+
+	// Missing lists required capabilities the candidate lacks.
+	Missing []string
+
+	// SuccessRate is the smoothed success rate used for scoring.
+	SuccessRate float64
+
+	// Samples is how many past outcomes informed SuccessRate.
+	Samples int
+
+	// Reasons explains each scoring contribution or the disqualification.
+	Reasons []string
+}
+
+// Decision is the outcome of routing one bead.
+type Decision struct {
+	// Required are the capabilities the bead needs.
+	Required []string
+
+	// Chosen is the winning candidate, or nil if none is eligible.
+	Chosen *Score
+
+	// Ranked are all candidates, eligible first, best first.
+	Ranked []Score
+}
+
+// Route scores candidates for a bead requiring the given capabilities.
+// hist may be nil, in which case every candidate gets a neutral success rate.
+func Route(required []string, candidates []Candidate, hist *History) *Decision {
+	d := &Decision{Required: required}
+	for _, c := range candidates {
+		d.Ranked = append(d.Ranked, score(required, c, hist))
+	}
+
+	sort.SliceStable(d.Ranked, func(i, j int) bool {
+		a, b := d.Ranked[i], d.Ranked[j]
+		if a.Eligible != b.Eligible {
+			return a.Eligible
+		}
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.Target+a.Agent < b.Target+b.Agent
+	})
+
+	if len(d.Ranked) > 0 && d.Ranked[0].Eligible {
+		d.Chosen = &d.Ranked[0]
+	}
+	return d
+}
+
+// score evaluates a single candidate.
+func score(required []string, c Candidate, hist *History) Score {
+	s := Score{Candidate: c}
+
+	for _, r := range required {
+		if !slices.Contains(c.Capabilities, r) {
+			s.Missing = append(s.Missing, r)
+		}
+	}
+	if len(s.Missing) > 0 {
+		s.Reasons = append(s.Reasons, "missing "+strings.Join(s.Missing, ", "))
+		return s
+	}
+
+	switch c.Kind {
+	case KindCrew:
+		if !c.Idle {
+			s.Reasons = append(s.Reasons, "busy (has hooked work or no session)")
+			return s
+		}
+		s.Score += weightIdleCrew
+		s.Reasons = append(s.Reasons, fmt.Sprintf("idle crew +%.0f", weightIdleCrew))
+	case KindPolecat:
+		if c.PoolMax > 0 && c.PoolUsed >= c.PoolMax {
+			s.Reasons = append(s.Reasons, fmt.Sprintf("polecat pool full (%d/%d)", c.PoolUsed, c.PoolMax))
+			return s
+		}
+		headroom := 1.0
+		if c.PoolMax > 0 {
+			headroom = 1 - float64(c.PoolUsed)/float64(c.PoolMax)
+		}
+		s.Score += weightPoolHeadroom * headroom
+		s.Reasons = append(s.Reasons, fmt.Sprintf("pool headroom %s +%.1f", poolString(c), weightPoolHeadroom*headroom))
+	}
+
+	s.SuccessRate, s.Samples = hist.Rate(c.HistoryKey(), required)
+	s.Score += weightSuccess * s.SuccessRate
+	s.Reasons = append(s.Reasons, fmt.Sprintf("success %.0f%% over %d similar +%.1f",
+		s.SuccessRate*100, s.Samples, weightSuccess*s.SuccessRate))
+
+	// Centered on a neutral 0.5 so an unproven candidate is not penalized.
+	if c.ReliabilitySamples > 0 {
+		adj := weightReliability * (c.Reliability - 0.5)
+		s.Score += adj
+		s.Reasons = append(s.Reasons, fmt.Sprintf("first-pass merge %.0f%% over %d MRs %+.1f",
+			c.Reliability*100, c.ReliabilitySamples, adj))
+	}
+
+	// Prefer the most specific match so specialists stay free for work
+	// only they can do.
+	if extra := len(c.Capabilities) - len(required); extra > 0 {
+		s.Score -= penaltyExtraCap * float64(extra)
+		s.Reasons = append(s.Reasons, fmt.Sprintf("%d unused capabilities -%.0f", extra, penaltyExtraCap*float64(extra)))
+	}
+
+	s.Eligible = true
+	return s
+}
+
+func poolString(c Candidate) string {
+	if c.PoolMax == 0 {
+		return fmt.Sprintf("%d/unlimited", c.PoolUsed)
+	}
+	return fmt.Sprintf("%d/%d", c.PoolUsed, c.PoolMax)
+}
+
+// Explain writes a human-readable account of the decision.
+func (d *Decision) Explain(w io.Writer) {
+	req := "(none)"
+	if len(d.Required) > 0 {
+		req = strings.Join(d.Required, ", ")
+	}
+	fmt.Fprintf(w, "Required capabilities: %s\n", req)
+	if len(d.Ranked) == 0 {
+		fmt.Fprintln(w, "No candidates.")
+		return
+	}
+	for i, s := range d.Ranked {
+		marker := " "
+		if d.Chosen != nil && i == 0 {
+			marker = "→"
+		} else if !s.Eligible {
+			marker = "✗"
+		}
+		label := s.Target
+		if s.Kind == KindPolecat {
+			label = s.Target + " (new polecat)"
+		}
+		fmt.Fprintf(w, "%s %-32s agent=%-12s score=%5.1f  %s\n",
+			marker, label, s.Agent, s.Score, strings.Join(s.Reasons, "; "))
+	}
+	if d.Chosen == nil {
+		fmt.Fprintln(w, "No eligible target.")
+	}
+}
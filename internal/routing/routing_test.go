@@ -0,0 +1,134 @@
+package routing
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRequiredCapabilities(t *testing.T) {
+	got := RequiredCapabilities([]string{"bug", "needs:rust", "needs: db ", "needs:rust", "needs:", "priority:high"})
+	want := []string{"db", "rust"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RequiredCapabilities = %v, want %v", got, want)
+	}
+	if got := RequiredCapabilities(nil); got != nil {
+		t.Errorf("RequiredCapabilities(nil) = %v, want nil", got)
+	}
+}
+
+func TestRoute_FiltersMissingCapabilities(t *testing.T) {
+	candidates := []Candidate{
+		{Target: "gastown", Kind: KindPolecat, Rig: "gastown", Agent: "claude", Capabilities: []string{"go"}},
+		{Target: "gastown", Kind: KindPolecat, Rig: "gastown", Agent: "codex", Capabilities: []string{"go", "rust"}},
+	}
+	d := Route([]string{"rust"}, candidates, nil)
+	if d.Chosen == nil || d.Chosen.Agent != "codex" {
+		t.Fatalf("Chosen = %+v, want codex", d.Chosen)
+	}
+	last := d.Ranked[len(d.Ranked)-1]
+	if last.Eligible || !reflect.DeepEqual(last.Missing, []string{"rust"}) {
+		t.Errorf("claude should be ineligible missing rust, got %+v", last)
+	}
+}
+
+func TestRoute_PrefersIdleCrew(t *testing.T) {
+	candidates := []Candidate{
+		{Target: "gastown", Kind: KindPolecat, Rig: "gastown", Agent: "claude", Capabilities: []string{"go"}, PoolUsed: 0, PoolMax: 10},
+		{Target: "gastown/crew/max", Kind: KindCrew, Rig: "gastown", Agent: "claude", Capabilities: []string{"go"}, Idle: true},
+		{Target: "gastown/crew/joe", Kind: KindCrew, Rig: "gastown", Agent: "claude", Capabilities: []string{"go"}, Idle: false},
+	}
+	d := Route([]string{"go"}, candidates, nil)
+	if d.Chosen == nil || d.Chosen.Target != "gastown/crew/max" {
+		t.Fatalf("Chosen = %+v, want idle crew max", d.Chosen)
+	}
+	for _, s := range d.Ranked {
+		if s.Target == "gastown/crew/joe" && s.Eligible {
+			t.Error("busy crew should be ineligible")
+		}
+	}
+}
+
+func TestRoute_PoolFull(t *testing.T) {
+	candidates := []Candidate{
+		{Target: "gastown", Kind: KindPolecat, Rig: "gastown", Agent: "claude", PoolUsed: 3, PoolMax: 3},
+	}
+	d := Route(nil, candidates, nil)
+	if d.Chosen != nil {
+		t.Fatalf("Chosen = %+v, want nil for full pool", d.Chosen)
+	}
+	if !strings.Contains(strings.Join(d.Ranked[0].Reasons, ";"), "pool full") {
+		t.Errorf("reasons = %v, want pool full", d.Ranked[0].Reasons)
+	}
+}
+
+func TestRoute_SuccessHistoryWins(t *testing.T) {
+	hist := &History{}
+	for i := 0; i < 5; i++ {
+		hist.Add("gastown/crew/max", []string{"rust"}, false)
+		hist.Add("gastown/crew/ann", []string{"rust"}, true)
+	}
+	candidates := []Candidate{
+		{Target: "gastown/crew/max", Kind: KindCrew, Rig: "gastown", Agent: "claude", Capabilities: []string{"rust"}, Idle: true},
+		{Target: "gastown/crew/ann", Kind: KindCrew, Rig: "gastown", Agent: "claude", Capabilities: []string{"rust"}, Idle: true},
+	}
+	d := Route([]string{"rust"}, candidates, hist)
+	if d.Chosen == nil || d.Chosen.Target != "gastown/crew/ann" {
+		t.Fatalf("Chosen = %+v, want ann (better record)", d.Chosen)
+	}
+	if d.Chosen.Samples != 5 {
+		t.Errorf("Samples = %d, want 5", d.Chosen.Samples)
+	}
+}
+
+func TestRoute_PrefersSpecificMatch(t *testing.T) {
+	candidates := []Candidate{
+		{Target: "gastown", Kind: KindPolecat, Rig: "gastown", Agent: "generalist", Capabilities: []string{"go", "rust", "db", "docs"}},
+		{Target: "gastown", Kind: KindPolecat, Rig: "gastown", Agent: "gopher", Capabilities: []string{"go"}},
+	}
+	d := Route([]string{"go"}, candidates, nil)
+	if d.Chosen == nil || d.Chosen.Agent != "gopher" {
+		t.Fatalf("Chosen = %+v, want gopher", d.Chosen)
+	}
+}
+
+func TestDecisionExplain(t *testing.T) {
+	candidates := []Candidate{
+		{Target: "gastown", Kind: KindPolecat, Rig: "gastown", Agent: "codex", Capabilities: []string{"rust"}},
+		{Target: "gastown/crew/max", Kind: KindCrew, Rig: "gastown", Agent: "claude"},
+	}
+	var buf bytes.Buffer
+	Route([]string{"rust"}, candidates, nil).Explain(&buf)
+	out := buf.String()
+	for _, want := range []string{"Required capabilities: rust", "→ gastown (new polecat)", "✗ gastown/crew/max", "missing rust"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Explain missing %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	Route(nil, nil, nil).Explain(&buf)
+	if !strings.Contains(buf.String(), "No candidates.") {
+		t.Errorf("empty Explain = %q", buf.String())
+	}
+}
+
+func TestRoute_Reliability(t *testing.T) {
+	candidates := []Candidate{
+		{Target: "gastown", Kind: KindPolecat, Rig: "gastown", Agent: "claude", Reliability: 0.3, ReliabilitySamples: 8},
+		{Target: "gastown", Kind: KindPolecat, Rig: "gastown", Agent: "codex", Reliability: 0.9, ReliabilitySamples: 8},
+		{Target: "gastown", Kind: KindPolecat, Rig: "gastown", Agent: "gemini"},
+	}
+	d := Route(nil, candidates, nil)
+	var order []string
+	for _, s := range d.Ranked {
+		order = append(order, s.Agent)
+	}
+	if got := strings.Join(order, ","); got != "codex,gemini,claude" {
+		t.Errorf("ranking = %s, want codex,gemini,claude", got)
+	}
+	if !strings.Contains(strings.Join(d.Ranked[0].Reasons, "; "), "first-pass merge 90% over 8 MRs +8.0") {
+		t.Errorf("reasons = %v", d.Ranked[0].Reasons)
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/steveyegge/gastown/internal/claude"
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/opencode"
 	"github.com/steveyegge/gastown/internal/templates/commands"
 	"github.com/steveyegge/gastown/internal/tmux"
@@ -80,6 +81,26 @@ func SleepForReadyDelay(rc *config.RuntimeConfig) {
 	time.Sleep(time.Duration(rc.Tmux.ReadyDelayMs) * time.Millisecond)
 }
 
+// StartupPromptSettle is how long AnswerStartupPrompts watches for another
+// startup prompt after the last one it saw.
+const StartupPromptSettle = 2 * time.Second
+
+// AnswerStartupPrompts answers the runtime's startup prompts (trust and
+// permission dialogs, from RuntimeTmuxConfig.StartupPrompts) in a freshly
+// started session. Each answer is logged as a startup_prompt event for the
+// agent, so unattended keystrokes are always on record.
+func AnswerStartupPrompts(t *tmux.Tmux, sessionID, agent string, rc *config.RuntimeConfig) ([]tmux.StartupPromptAnswer, error) {
+	if rc == nil || rc.Tmux == nil || len(rc.Tmux.StartupPrompts) == 0 {
+		return nil, nil
+	}
+	answers, err := t.AnswerStartupPrompts(sessionID, rc.Tmux.StartupPrompts, StartupPromptSettle)
+	for _, a := range answers {
+		_ = events.LogFeed(events.TypeStartupPrompt, agent,
+			events.StartupPromptPayload(sessionID, agent, a.Prompt, a.Keys, a.Line))
+	}
+	return answers, err
+}
+
 // StartupFallbackCommands returns commands that approximate Claude hooks when hooks are unavailable.
 func StartupFallbackCommands(role string, rc *config.RuntimeConfig) []string {
 	if rc == nil {
@@ -0,0 +1,58 @@
+//go:build integration
+
+package sandbox
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// TestIntegrationSandboxRunsTools starts a real sandbox the way a polecat
+// session does and checks that gt and bd run inside it and the town root
+// stays hidden. It needs podman or bwrap, and gt and bd on PATH.
+//
+// Run with: go test -tags=integration -v ./internal/sandbox
+func TestIntegrationSandboxRunsTools(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	rt, _, err := Detect(RuntimeAuto)
+	if err != nil {
+		t.Skip(err)
+	}
+	for _, tool := range []string{"gt", "bd"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s not in PATH", tool)
+		}
+	}
+
+	townRoot := t.TempDir()
+	workDir := filepath.Join(townRoot, "rig", "polecats", "Toast")
+	if err := os.MkdirAll(filepath.Join(townRoot, "mayor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	spec := &Spec{
+		Runtime: rt,
+		Network: NetworkNone,
+		WorkDir: workDir,
+		Home:    workDir,
+		Mounts: append([]Mount{
+			{Path: workDir},
+		}, ToolMounts(rt, "gt", "bd")...),
+	}
+	wrapped, err := spec.Wrap("gt version && bd version && ! test -d " + shellQuote(filepath.Join(townRoot, "mayor")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := exec.Command("sh", "-c", wrapped).CombinedOutput()
+	if err != nil {
+		t.Fatalf("sandbox (%s) failed: %v\n%s", rt, err, out)
+	}
+}
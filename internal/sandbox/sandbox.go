@@ -0,0 +1,341 @@
+// Package sandbox runs agent sessions inside a rootless container.
+//
+// A sandboxed polecat only sees its own workspace, the rig's shared beads
+// database and the git objects its worktree needs. Everything else in the
+// user's home directory is hidden. The sandbox runs in the foreground of the
+// tmux pane, so capture-pane, send-keys and nudges behave exactly as they do
+// for an unsandboxed agent.
+//
+// Two runtimes are supported:
+//   - podman: full OCI container from a configurable image
+//   - bwrap (bubblewrap): lightweight namespace sandbox over the host /usr
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Runtime identifies a container runtime.
+type Runtime string
+
+const (
+	// RuntimeAuto prefers podman and falls back to bwrap.
+	RuntimeAuto Runtime = "auto"
+
+	// RuntimePodman runs the agent with `podman run` (rootless).
+	RuntimePodman Runtime = "podman"
+
+	// RuntimeBwrap runs the agent with bubblewrap.
+	RuntimeBwrap Runtime = "bwrap"
+)
+
+// Network modes.
+const (
+	// NetworkRestricted gives the sandbox outbound internet access (for the
+	// model API) but no access to services listening on the host, except
+	// the loopback ports listed in Spec.HostPorts. Requires podman.
+	NetworkRestricted = "restricted"
+
+	// NetworkNone disables networking entirely.
+	NetworkNone = "none"
+
+	// NetworkHost shares the host network namespace.
+	NetworkHost = "host"
+)
+
+// DefaultImage is the podman image used when none is configured.
+const DefaultImage = "docker.io/library/debian:stable-slim"
+
+// ErrNoRuntime is returned when no supported runtime is installed.
+var ErrNoRuntime = errors.New("no sandbox runtime found (install podman or bubblewrap)")
+
+// Mount is a host path exposed inside the sandbox at the same location.
+type Mount struct {
+	Path     string
+	ReadOnly bool
+}
+
+// Spec describes one sandboxed agent session.
+type Spec struct {
+	// Runtime is the resolved runtime (podman or bwrap).
+	Runtime Runtime
+
+	// Image is the container image (podman only).
+	Image string
+
+	// Network is the network mode: restricted, none or host.
+	Network string
+
+	// WorkDir is the directory the agent starts in.
+	WorkDir string
+
+	// Home is the HOME directory inside the sandbox. It must be inside a
+	// writable mount so the agent can keep its own state.
+	Home string
+
+	// Mounts are host paths bind-mounted at the same path.
+	Mounts []Mount
+
+	// HostPorts are host loopback ports reachable at 127.0.0.1 inside a
+	// restricted sandbox, such as the Dolt server's port for bd.
+	HostPorts []int
+
+	// Name is the container name (podman only).
+	Name string
+}
+
+// ValidateNetwork checks that a network mode is known.
+func ValidateNetwork(mode string) error {
+	switch mode {
+	case "", NetworkRestricted, NetworkNone, NetworkHost:
+		return nil
+	default:
+		return fmt.Errorf("invalid sandbox network %q: want %q, %q or %q",
+			mode, NetworkRestricted, NetworkNone, NetworkHost)
+	}
+}
+
+// Detect resolves the runtime to use and returns the path of its binary.
+func Detect(preferred Runtime) (Runtime, string, error) {
+	switch preferred {
+	case RuntimePodman, RuntimeBwrap:
+		path, err := exec.LookPath(string(preferred))
+		if err != nil {
+			return "", "", fmt.Errorf("sandbox runtime %s not found in PATH", preferred)
+		}
+		return preferred, path, nil
+	case "", RuntimeAuto:
+		for _, rt := range []Runtime{RuntimePodman, RuntimeBwrap} {
+			if path, err := exec.LookPath(string(rt)); err == nil {
+				return rt, path, nil
+			}
+		}
+		return "", "", ErrNoRuntime
+	default:
+		return "", "", fmt.Errorf("unknown sandbox runtime %q", preferred)
+	}
+}
+
+// Wrap returns command wrapped to run inside the sandbox. The result is a
+// shell string suitable for tmux.NewSessionWithCommand.
+func (s *Spec) Wrap(command string) (string, error) {
+	if s.WorkDir == "" {
+		return "", fmt.Errorf("sandbox: work dir is required")
+	}
+	if err := ValidateNetwork(s.Network); err != nil {
+		return "", err
+	}
+
+	var args []string
+	switch s.Runtime {
+	case RuntimePodman:
+		args = s.podmanArgs()
+	case RuntimeBwrap:
+		if s.network() == NetworkRestricted {
+			return "", fmt.Errorf("sandbox network %q requires podman; use %q or %q with bwrap",
+				NetworkRestricted, NetworkNone, NetworkHost)
+		}
+		args = s.bwrapArgs()
+	default:
+		return "", fmt.Errorf("unknown sandbox runtime %q", s.Runtime)
+	}
+
+	args = append(args, "sh", "-c", command)
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = shellQuote(a)
+	}
+	return "exec " + strings.Join(quoted, " "), nil
+}
+
+// network returns the effective network mode.
+func (s *Spec) network() string {
+	if s.Network == "" {
+		return NetworkRestricted
+	}
+	return s.Network
+}
+
+// podmanArgs builds the `podman run` prefix.
+func (s *Spec) podmanArgs() []string {
+	image := s.Image
+	if image == "" {
+		image = DefaultImage
+	}
+	args := []string{
+		"podman", "run", "--rm", "-it",
+		// Keep the host UID so files written to the worktree stay owned by the user.
+		"--userns=keep-id",
+		"--security-opt", "no-new-privileges",
+		"--cap-drop=all",
+	}
+	if s.Name != "" {
+		args = append(args, "--name", s.Name, "--replace")
+	}
+	switch s.network() {
+	case NetworkNone:
+		args = append(args, "--network=none")
+	case NetworkHost:
+		args = append(args, "--network=host")
+	default:
+		if len(s.HostPorts) == 0 {
+			args = append(args, "--network=slirp4netns:allow_host_loopback=false")
+			break
+		}
+		// pasta forwards only the listed ports from the container's
+		// loopback to the host's; other host services stay unreachable.
+		opt := "--network=pasta:--no-map-gw"
+		for _, port := range s.HostPorts {
+			opt += fmt.Sprintf(",-T,%d", port)
+		}
+		args = append(args, opt)
+	}
+	for _, m := range s.sortedMounts() {
+		opt := m.Path + ":" + m.Path
+		if m.ReadOnly {
+			opt += ":ro"
+		}
+		args = append(args, "-v", opt)
+	}
+	if s.Home != "" {
+		args = append(args, "-e", "HOME="+s.Home)
+	}
+	args = append(args, "-e", "TERM", "-w", s.WorkDir, image)
+	return args
+}
+
+// systemDirs are host directories exposed read-only to bwrap sandboxes so
+// the agent, git, gt and bd binaries and their libraries are usable.
+var systemDirs = []string{"/usr", "/bin", "/sbin", "/lib", "/lib64", "/lib32", "/etc", "/opt", "/nix"}
+
+// imageDirs are directories a podman image provides itself. Host
+// directories there are never mounted over the image's; a tool found in one
+// is mounted as a single file instead.
+var imageDirs = []string{"/usr", "/bin", "/sbin", "/lib", "/lib64", "/lib32", "/etc"}
+
+// bwrapArgs builds the bubblewrap prefix.
+func (s *Spec) bwrapArgs() []string {
+	args := []string{"bwrap", "--die-with-parent", "--unshare-pid", "--unshare-ipc", "--unshare-uts"}
+	for _, dir := range systemDirs {
+		args = append(args, "--ro-bind-try", dir, dir)
+	}
+	args = append(args,
+		"--dev", "/dev",
+		"--proc", "/proc",
+		"--tmpfs", "/tmp",
+	)
+	// Hide the real home directory; only explicit mounts show through.
+	if home, err := os.UserHomeDir(); err == nil {
+		args = append(args, "--tmpfs", home)
+	}
+	if s.network() == NetworkNone {
+		args = append(args, "--unshare-net")
+	}
+	for _, m := range s.sortedMounts() {
+		flag := "--bind"
+		if m.ReadOnly {
+			flag = "--ro-bind"
+		}
+		args = append(args, flag, m.Path, m.Path)
+	}
+	if s.Home != "" {
+		args = append(args, "--setenv", "HOME", s.Home)
+	}
+	args = append(args, "--chdir", s.WorkDir, "--")
+	return args
+}
+
+// sortedMounts returns mounts de-duplicated and ordered parent-first, so a
+// read-only child mount is not shadowed by a later writable parent.
+func (s *Spec) sortedMounts() []Mount {
+	seen := make(map[string]int)
+	var out []Mount
+	for _, m := range s.Mounts {
+		if m.Path == "" {
+			continue
+		}
+		p := filepath.Clean(m.Path)
+		if i, ok := seen[p]; ok {
+			// Writable wins over read-only for the same path.
+			out[i].ReadOnly = out[i].ReadOnly && m.ReadOnly
+			continue
+		}
+		seen[p] = len(out)
+		out = append(out, Mount{Path: p, ReadOnly: m.ReadOnly})
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return strings.Count(out[i].Path, string(filepath.Separator)) <
+			strings.Count(out[j].Path, string(filepath.Separator))
+	})
+	return out
+}
+
+// ToolMounts returns read-only mounts that make the given executables
+// (e.g., gt, bd, claude) runnable inside a sandbox of runtime rt. Missing
+// tools are skipped.
+//
+// bwrap sandboxes already see the host's system directories, so only tools
+// outside them (~/go/bin, ~/.local/bin) need a mount. A podman image has its
+// own /usr, so every tool is mounted: the directories holding it and its
+// symlink target (an npm package, for example), or just the binary when it
+// sits in a directory the image provides, like /usr/bin.
+func ToolMounts(rt Runtime, tools ...string) []Mount {
+	var mounts []Mount
+	for _, tool := range tools {
+		path, err := exec.LookPath(tool)
+		if err != nil {
+			continue
+		}
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		resolved := path
+		if r, err := filepath.EvalSymlinks(path); err == nil {
+			resolved = r
+		}
+
+		if rt != RuntimePodman {
+			if dir := filepath.Dir(resolved); !underAny(dir, systemDirs) {
+				mounts = append(mounts, Mount{Path: dir, ReadOnly: true})
+			}
+			continue
+		}
+		for _, p := range []string{path, resolved} {
+			dir := filepath.Dir(p)
+			if underAny(dir, imageDirs) && !underAny(dir, []string{"/usr/local"}) {
+				// Bind mounts follow symlinks, so the name on PATH works too.
+				mounts = append(mounts, Mount{Path: p, ReadOnly: true})
+				continue
+			}
+			mounts = append(mounts, Mount{Path: dir, ReadOnly: true})
+		}
+	}
+	return mounts
+}
+
+// underAny reports whether dir is one of dirs or inside one of them.
+func underAny(dir string, dirs []string) bool {
+	for _, d := range dirs {
+		if dir == d || strings.HasPrefix(dir, d+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// shellQuote single-quotes s unless it consists only of safe characters.
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+			strings.ContainsRune("-_./=:@+,", r))
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
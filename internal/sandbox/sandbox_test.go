@@ -0,0 +1,213 @@
+package sandbox
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testSpec(rt Runtime, network string) *Spec {
+	return &Spec{
+		Runtime: rt,
+		Network: network,
+		WorkDir: "/town/rig/polecats/Toast/rig",
+		Home:    "/town/rig/polecats/Toast",
+		Name:    "gt-rig-Toast",
+		Mounts: []Mount{
+			{Path: "/town/rig/polecats/Toast/rig"},
+			{Path: "/town/rig/polecats/Toast"},
+			{Path: "/town/rig/mayor/rig/.beads"},
+			{Path: "/opt/rust", ReadOnly: true},
+		},
+	}
+}
+
+func TestWrap_Podman(t *testing.T) {
+	got, err := testSpec(RuntimePodman, "").Wrap("exec claude 'do it'")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"exec podman run --rm -it --userns=keep-id",
+		"--cap-drop=all",
+		"--name gt-rig-Toast --replace",
+		"--network=slirp4netns:allow_host_loopback=false",
+		"-v /town/rig/polecats/Toast:/town/rig/polecats/Toast ",
+		"-v /town/rig/mayor/rig/.beads:/town/rig/mayor/rig/.beads ",
+		"-v /opt/rust:/opt/rust:ro",
+		"-e HOME=/town/rig/polecats/Toast",
+		"-w /town/rig/polecats/Toast/rig " + DefaultImage,
+		`sh -c 'exec claude '\''do it'\'''`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("podman command missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestWrap_PodmanNetworkModes(t *testing.T) {
+	tests := map[string]string{
+		NetworkNone: "--network=none",
+		NetworkHost: "--network=host",
+	}
+	for mode, want := range tests {
+		got, err := testSpec(RuntimePodman, mode).Wrap("true")
+		if err != nil {
+			t.Fatalf("%s: %v", mode, err)
+		}
+		if !strings.Contains(got, want) {
+			t.Errorf("network %s: missing %q in %s", mode, want, got)
+		}
+	}
+}
+
+func TestWrap_PodmanHostPorts(t *testing.T) {
+	spec := testSpec(RuntimePodman, "")
+	spec.HostPorts = []int{3307}
+	got, err := spec.Wrap("true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "--network=pasta:--no-map-gw,-T,3307") {
+		t.Errorf("restricted network should forward only the Dolt port:\n%s", got)
+	}
+}
+
+func TestToolMounts(t *testing.T) {
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "gt-fake"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not in PATH")
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+filepath.Dir(sh))
+
+	// bwrap sees the host /usr; only tools outside it need a mount.
+	got := ToolMounts(RuntimeBwrap, "gt-fake", "sh", "missing-tool")
+	if len(got) != 1 || got[0].Path != binDir || !got[0].ReadOnly {
+		t.Errorf("bwrap mounts = %+v, want only %s", got, binDir)
+	}
+
+	// podman brings its own /usr: tools there are mounted file by file.
+	paths := make(map[string]bool)
+	for _, m := range ToolMounts(RuntimePodman, "gt-fake", "sh") {
+		paths[m.Path] = true
+	}
+	if !paths[binDir] || !paths[filepath.Join(filepath.Dir(sh), "sh")] {
+		t.Errorf("podman mounts = %v, want %s and the sh binary", paths, binDir)
+	}
+	if paths[filepath.Dir(sh)] {
+		t.Errorf("podman must not mount over the image's %s", filepath.Dir(sh))
+	}
+}
+
+func TestWrap_Bwrap(t *testing.T) {
+	got, err := testSpec(RuntimeBwrap, NetworkNone).Wrap("exec claude")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"exec bwrap --die-with-parent --unshare-pid",
+		"--ro-bind-try /usr /usr",
+		"--unshare-net",
+		"--bind /town/rig/polecats/Toast /town/rig/polecats/Toast",
+		"--ro-bind /opt/rust /opt/rust",
+		"--setenv HOME /town/rig/polecats/Toast",
+		"--chdir /town/rig/polecats/Toast/rig -- sh -c 'exec claude'",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("bwrap command missing %q:\n%s", want, got)
+		}
+	}
+
+	// Parent mounts must come before nested ones so they don't shadow them.
+	parent := strings.Index(got, "--bind /town/rig/polecats/Toast /town")
+	child := strings.Index(got, "--bind /town/rig/polecats/Toast/rig ")
+	if parent < 0 || child < 0 || parent > child {
+		t.Errorf("expected parent mount before child mount:\n%s", got)
+	}
+}
+
+func TestWrap_BwrapHostNetwork(t *testing.T) {
+	got, err := testSpec(RuntimeBwrap, NetworkHost).Wrap("true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(got, "--unshare-net") {
+		t.Errorf("host network should not unshare net:\n%s", got)
+	}
+}
+
+func TestWrap_BwrapRestrictedUnsupported(t *testing.T) {
+	if _, err := testSpec(RuntimeBwrap, "").Wrap("true"); err == nil {
+		t.Error("expected error: restricted network requires podman")
+	}
+}
+
+func TestWrap_Errors(t *testing.T) {
+	spec := testSpec(RuntimePodman, "wide-open")
+	if _, err := spec.Wrap("true"); err == nil {
+		t.Error("expected error for invalid network")
+	}
+
+	spec = testSpec("docker", "")
+	if _, err := spec.Wrap("true"); err == nil {
+		t.Error("expected error for unknown runtime")
+	}
+
+	spec = testSpec(RuntimePodman, "")
+	spec.WorkDir = ""
+	if _, err := spec.Wrap("true"); err == nil {
+		t.Error("expected error for missing work dir")
+	}
+}
+
+func TestSortedMounts_Dedup(t *testing.T) {
+	spec := &Spec{Mounts: []Mount{
+		{Path: "/a/b", ReadOnly: true},
+		{Path: "/a"},
+		{Path: "/a/b/"},
+		{Path: ""},
+	}}
+	got := spec.sortedMounts()
+	if len(got) != 2 {
+		t.Fatalf("got %d mounts, want 2: %+v", len(got), got)
+	}
+	if got[0].Path != "/a" || got[1].Path != "/a/b" {
+		t.Errorf("order = %+v, want /a then /a/b", got)
+	}
+	if got[1].ReadOnly {
+		t.Error("duplicate writable mount should make /a/b writable")
+	}
+}
+
+func TestDetect_Unknown(t *testing.T) {
+	if _, _, err := Detect("docker"); err == nil {
+		t.Error("expected error for unknown runtime")
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := map[string]string{
+		"simple":        "simple",
+		"/path/to-x":    "/path/to-x",
+		"HOME=/a":       "HOME=/a",
+		"has space":     "'has space'",
+		"it's":          `'it'\''s'`,
+		"":              "''",
+		"$HOME":         "'$HOME'",
+		"a:b:ro":        "a:b:ro",
+		"{{.Rootless}}": "'{{.Rootless}}'",
+	}
+	for in, want := range tests {
+		if got := shellQuote(in); got != want {
+			t.Errorf("shellQuote(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ClaimTTL is how long a dispatched bead stays claimed. It covers the gap
+// between a sling succeeding and the bead dropping out of bd ready, so a
+// dispatcher working from an older ready list doesn't sling it again.
+const ClaimTTL = 15 * time.Minute
+
+// ErrClaimed indicates another dispatcher holds a bead's claim.
+var ErrClaimed = errors.New("bead already claimed for dispatch")
+
+// ClaimsDir returns the directory of dispatch claims for a town. Every
+// component that slings ready work on its own (the daemon scheduler, the
+// witness autospawn) claims a bead here first.
+func ClaimsDir(townRoot string) string {
+	return filepath.Join(townRoot, "daemon", "dispatch-claims")
+}
+
+// claimRecord is the content of a claim file.
+type claimRecord struct {
+	Owner string    `json:"owner"`
+	At    time.Time `json:"at"`
+}
+
+// Claim is a held dispatch claim on a bead.
+type Claim struct {
+	path string
+}
+
+// ClaimBead claims bead for dispatch by owner. It returns ErrClaimed
+// (wrapped with the holder) if another dispatcher claimed it within
+// ClaimTTL. Keep the claim after a successful sling so it runs out on its
+// own; Release it when the sling fails so the bead can be retried.
+func ClaimBead(townRoot, bead, owner string, now time.Time) (*Claim, error) {
+	dir := ClaimsDir(townRoot)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, strings.ReplaceAll(bead, string(filepath.Separator), "_")+".json")
+	data, err := json.Marshal(claimRecord{Owner: owner, At: now})
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644) //nolint:gosec // G304: path is constructed internally
+		if err == nil {
+			_, werr := f.Write(data)
+			cerr := f.Close()
+			if werr != nil || cerr != nil {
+				_ = os.Remove(path)
+				return nil, errors.Join(werr, cerr)
+			}
+			return &Claim{path: path}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		held, ok := readClaim(path)
+		if ok && now.Sub(held.At) < ClaimTTL {
+			return nil, fmt.Errorf("%w by %s", ErrClaimed, held.Owner)
+		}
+		// Expired or gone: take it over.
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("%w: lost a race for %s", ErrClaimed, bead)
+}
+
+// Release gives up the claim.
+func (c *Claim) Release() {
+	_ = os.Remove(c.path)
+}
+
+// PruneClaims removes claims older than ClaimTTL.
+func PruneClaims(townRoot string, now time.Time) {
+	entries, err := os.ReadDir(ClaimsDir(townRoot))
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		path := filepath.Join(ClaimsDir(townRoot), e.Name())
+		if held, ok := readClaim(path); !ok || now.Sub(held.At) >= ClaimTTL {
+			_ = os.Remove(path)
+		}
+	}
+}
+
+// readClaim reads a claim file. A claim still being written is dated by
+// the file's modification time.
+func readClaim(path string) (claimRecord, bool) {
+	var rec claimRecord
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is constructed internally
+	if err == nil && json.Unmarshal(data, &rec) == nil {
+		return rec, true
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return rec, false
+	}
+	return claimRecord{Owner: "unknown", At: info.ModTime()}, true
+}
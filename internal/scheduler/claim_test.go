@@ -0,0 +1,52 @@
+package scheduler
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestClaimBead(t *testing.T) {
+	townRoot := t.TempDir()
+	now := time.Now()
+
+	claim, err := ClaimBead(townRoot, "gt-1", "scheduler", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ClaimBead(townRoot, "gt-1", "gastown/witness", now); !errors.Is(err, ErrClaimed) {
+		t.Fatalf("second claim = %v, want ErrClaimed", err)
+	}
+
+	// A released claim (failed sling) can be taken again.
+	claim.Release()
+	if _, err := ClaimBead(townRoot, "gt-1", "gastown/witness", now); err != nil {
+		t.Fatalf("claim after release: %v", err)
+	}
+
+	// A kept claim runs out after ClaimTTL.
+	later := now.Add(ClaimTTL + time.Second)
+	if _, err := ClaimBead(townRoot, "gt-1", "scheduler", later); err != nil {
+		t.Fatalf("claim after expiry: %v", err)
+	}
+}
+
+func TestPruneClaims(t *testing.T) {
+	townRoot := t.TempDir()
+	now := time.Now()
+	if _, err := ClaimBead(townRoot, "gt-old", "scheduler", now.Add(-ClaimTTL)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ClaimBead(townRoot, "gt-new", "scheduler", now); err != nil {
+		t.Fatal(err)
+	}
+	PruneClaims(townRoot, now)
+	entries, err := os.ReadDir(ClaimsDir(townRoot))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "gt-new.json" {
+		t.Errorf("claims after prune = %v", entries)
+	}
+}
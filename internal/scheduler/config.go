@@ -0,0 +1,105 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// DefaultInterval is how often the daemon runs a scheduler cycle.
+const DefaultInterval = time.Minute
+
+// Config is the "scheduler" section of mayor/daemon.json patrols.
+// The scheduler is off unless explicitly enabled: it slings work on its
+// own, which a town should opt into.
+type Config struct {
+	// Enabled turns on daemon-driven dispatch.
+	Enabled bool `json:"enabled"`
+
+	// Interval between cycles (Go duration, default 1m).
+	Interval string `json:"interval,omitempty"`
+
+	// TownMaxPolecats caps working polecats across all rigs (0 = unlimited).
+	TownMaxPolecats int `json:"town_max_polecats,omitempty"`
+
+	// MaxDispatchPerCycle bounds spawns per cycle (default 3).
+	MaxDispatchPerCycle int `json:"max_dispatch_per_cycle,omitempty"`
+
+	// Label, when set, restricts scheduling to ready beads carrying it.
+	Label string `json:"label,omitempty"`
+
+	// Rigs limits scheduling to these rigs. Empty means all rigs.
+	Rigs []string `json:"rigs,omitempty"`
+}
+
+// IntervalDuration returns the configured interval, or the default if unset
+// or invalid.
+func (c *Config) IntervalDuration() time.Duration {
+	if c == nil || c.Interval == "" {
+		return DefaultInterval
+	}
+	d, err := time.ParseDuration(c.Interval)
+	if err != nil || d <= 0 {
+		return DefaultInterval
+	}
+	return d
+}
+
+// IncludesRig reports whether the rig is in scope.
+func (c *Config) IncludesRig(name string) bool {
+	return c == nil || len(c.Rigs) == 0 || slices.Contains(c.Rigs, name)
+}
+
+// Run records the outcome of one daemon scheduler cycle.
+type Run struct {
+	At         time.Time  `json:"at"`
+	Plan       *Plan      `json:"plan,omitempty"`
+	Dispatched []Dispatch `json:"dispatched,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// Dispatch is the result of slinging one planned bead.
+type Dispatch struct {
+	Bead  string `json:"bead"`
+	Rig   string `json:"rig"`
+	Error string `json:"error,omitempty"`
+
+	// Skipped is set when another dispatcher had already claimed the bead.
+	Skipped string `json:"skipped,omitempty"`
+}
+
+// StateFile returns the path where the daemon records its last run.
+func StateFile(townRoot string) string {
+	return filepath.Join(townRoot, "daemon", "scheduler.json")
+}
+
+// LoadLastRun reads the daemon's last recorded cycle. Returns nil, nil if
+// the scheduler has never run.
+func LoadLastRun(townRoot string) (*Run, error) {
+	data, err := os.ReadFile(StateFile(townRoot))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var run Run
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", StateFile(townRoot), err)
+	}
+	return &run, nil
+}
+
+// SaveLastRun records a cycle for `gt sched status`.
+func SaveLastRun(townRoot string, run *Run) error {
+	path := StateFile(townRoot)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return util.AtomicWriteJSON(path, run)
+}
@@ -0,0 +1,60 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfigIntervalDuration(t *testing.T) {
+	tests := []struct {
+		cfg  *Config
+		want time.Duration
+	}{
+		{nil, DefaultInterval},
+		{&Config{}, DefaultInterval},
+		{&Config{Interval: "30s"}, 30 * time.Second},
+		{&Config{Interval: "bogus"}, DefaultInterval},
+		{&Config{Interval: "-5m"}, DefaultInterval},
+	}
+	for _, tt := range tests {
+		if got := tt.cfg.IntervalDuration(); got != tt.want {
+			t.Errorf("IntervalDuration(%+v) = %v, want %v", tt.cfg, got, tt.want)
+		}
+	}
+}
+
+func TestConfigIncludesRig(t *testing.T) {
+	var nilCfg *Config
+	if !nilCfg.IncludesRig("any") {
+		t.Error("nil config should include all rigs")
+	}
+	cfg := &Config{Rigs: []string{"gastown"}}
+	if !cfg.IncludesRig("gastown") || cfg.IncludesRig("beads") {
+		t.Error("rigs filter not applied")
+	}
+}
+
+func TestLastRunRoundTrip(t *testing.T) {
+	townRoot := t.TempDir()
+
+	run, err := LoadLastRun(townRoot)
+	if err != nil || run != nil {
+		t.Fatalf("LoadLastRun on empty town = %v, %v; want nil, nil", run, err)
+	}
+
+	want := &Run{
+		At:         time.Now().UTC().Truncate(time.Second),
+		Plan:       &Plan{Dispatch: []Item{{Bead: "gt-1", Rig: "gastown"}}, TownActive: 1},
+		Dispatched: []Dispatch{{Bead: "gt-1", Rig: "gastown", Error: "boom"}},
+	}
+	if err := SaveLastRun(townRoot, want); err != nil {
+		t.Fatalf("SaveLastRun: %v", err)
+	}
+	got, err := LoadLastRun(townRoot)
+	if err != nil {
+		t.Fatalf("LoadLastRun: %v", err)
+	}
+	if !got.At.Equal(want.At) || len(got.Dispatched) != 1 || got.Dispatched[0].Error != "boom" || got.Plan.Dispatch[0].Bead != "gt-1" {
+		t.Errorf("round trip = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,212 @@
+// Package scheduler plans town-wide polecat dispatch.
+//
+// The daemon pulls ready beads from every rig and asks Plan which of them
+// to sling now. Plan enforces per-rig polecat quotas (max_polecats), an
+// optional town-wide cap, Dolt connection capacity and a per-cycle dispatch
+// budget. Higher-priority beads go first; among equal priorities the least
+// loaded rig goes first so one busy rig cannot starve the others.
+//
+// Every bead that is not dispatched gets a reason, which `gt sched status`
+// shows so humans can tell why work is waiting.
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DefaultMaxDispatchPerCycle bounds how many polecats one scheduler cycle
+// may spawn when the config does not say otherwise.
+const DefaultMaxDispatchPerCycle = 3
+
+// Item is a ready bead that could be dispatched.
+type Item struct {
+	Bead      string `json:"bead"`
+	Rig       string `json:"rig"`
+	Title     string `json:"title,omitempty"`
+	Priority  int    `json:"priority"`
+	CreatedAt string `json:"created_at,omitempty"`
+}
+
+// RigState describes a rig's polecat pool at planning time.
+type RigState struct {
+	Name string `json:"name"`
+
+	// Active is the number of working polecats.
+	Active int `json:"active"`
+
+	// Max is the rig's polecat quota. Zero means unlimited.
+	Max int `json:"max"`
+
+	// Blocked is non-empty when the rig must not receive work
+	// (e.g. "rig is parked").
+	Blocked string `json:"blocked,omitempty"`
+}
+
+// full reports whether the rig's quota is used up.
+func (r *RigState) full() bool {
+	return r.Max > 0 && r.Active >= r.Max
+}
+
+// load is the fraction of the quota in use; unlimited rigs count as empty.
+func (r *RigState) load() float64 {
+	if r.Max <= 0 {
+		return 0
+	}
+	return float64(r.Active) / float64(r.Max)
+}
+
+// Input is everything Plan needs to decide.
+type Input struct {
+	Items []Item
+	Rigs  []RigState
+
+	// TownMax caps working polecats across all rigs. Zero means unlimited.
+	TownMax int
+
+	// DoltAtCapacity blocks all dispatch when the Dolt server is near its
+	// connection limit. DoltActive is reported in the waiting reason.
+	DoltAtCapacity bool
+	DoltActive     int
+
+	// MaxDispatch is the per-cycle dispatch budget. Zero uses the default.
+	MaxDispatch int
+}
+
+// Waiting is a bead held back and why.
+type Waiting struct {
+	Item
+	Reason string `json:"reason"`
+}
+
+// Plan is the scheduler's decision for one cycle.
+type Plan struct {
+	// Dispatch lists beads to sling now, in dispatch order.
+	Dispatch []Item `json:"dispatch"`
+
+	// Waiting lists the remaining beads in queue order with reasons.
+	Waiting []Waiting `json:"waiting"`
+
+	// Rigs is the pool state after the planned dispatches.
+	Rigs []RigState `json:"rigs"`
+
+	// TownActive and TownMax describe the town-wide pool after dispatch.
+	TownActive int `json:"town_active"`
+	TownMax    int `json:"town_max"`
+}
+
+// Schedule computes which ready beads to dispatch this cycle.
+func Schedule(in Input) *Plan {
+	budget := in.MaxDispatch
+	if budget <= 0 {
+		budget = DefaultMaxDispatchPerCycle
+	}
+
+	rigs := make(map[string]*RigState, len(in.Rigs))
+	plan := &Plan{TownMax: in.TownMax}
+	for _, r := range in.Rigs {
+		r := r
+		rigs[r.Name] = &r
+		plan.TownActive += r.Active
+	}
+
+	items := append([]Item(nil), in.Items...)
+	sort.SliceStable(items, func(i, j int) bool { return queueLess(items[i], items[j]) })
+
+	// Per-rig queues, each already in priority order.
+	queues := make(map[string][]Item)
+	var rigOrder []string
+	for _, it := range items {
+		if _, ok := queues[it.Rig]; !ok {
+			rigOrder = append(rigOrder, it.Rig)
+		}
+		queues[it.Rig] = append(queues[it.Rig], it)
+	}
+	sort.Strings(rigOrder)
+
+	townFull := func() bool { return in.TownMax > 0 && plan.TownActive >= in.TownMax }
+	dispatched := make(map[string]bool)
+
+	for !in.DoltAtCapacity && len(plan.Dispatch) < budget && !townFull() {
+		// Pick the best head-of-queue among rigs that can take work.
+		var best string
+		for _, name := range rigOrder {
+			q := queues[name]
+			r := rigs[name]
+			if len(q) == 0 || r == nil || r.Blocked != "" || r.full() {
+				continue
+			}
+			if best == "" || headLess(q[0], r, queues[best][0], rigs[best]) {
+				best = name
+			}
+		}
+		if best == "" {
+			break
+		}
+		it := queues[best][0]
+		queues[best] = queues[best][1:]
+		rigs[best].Active++
+		plan.TownActive++
+		plan.Dispatch = append(plan.Dispatch, it)
+		dispatched[it.Bead] = true
+	}
+
+	for _, it := range items {
+		if dispatched[it.Bead] {
+			continue
+		}
+		plan.Waiting = append(plan.Waiting, Waiting{Item: it, Reason: waitReason(it, rigs[it.Rig], in, plan, budget)})
+	}
+
+	for _, r := range in.Rigs {
+		plan.Rigs = append(plan.Rigs, *rigs[r.Name])
+	}
+	return plan
+}
+
+// waitReason explains why an item was not dispatched, most fundamental
+// constraint first.
+func waitReason(it Item, r *RigState, in Input, plan *Plan, budget int) string {
+	switch {
+	case in.DoltAtCapacity:
+		return fmt.Sprintf("dolt at connection capacity (%d active)", in.DoltActive)
+	case r == nil:
+		return fmt.Sprintf("rig %s not found", it.Rig)
+	case r.Blocked != "":
+		return r.Blocked
+	case r.full():
+		return fmt.Sprintf("rig quota full (%d/%d polecats)", r.Active, r.Max)
+	case in.TownMax > 0 && plan.TownActive >= in.TownMax:
+		return fmt.Sprintf("town cap reached (%d/%d polecats)", plan.TownActive, in.TownMax)
+	case len(plan.Dispatch) >= budget:
+		return fmt.Sprintf("cycle dispatch limit reached (%d)", budget)
+	default:
+		return "queued"
+	}
+}
+
+// queueLess orders items within the queue: priority, then age, then ID.
+func queueLess(a, b Item) bool {
+	if a.Priority != b.Priority {
+		return a.Priority < b.Priority
+	}
+	if a.CreatedAt != b.CreatedAt {
+		return a.CreatedAt < b.CreatedAt
+	}
+	return a.Bead < b.Bead
+}
+
+// headLess compares two rig queue heads: priority wins, then the less
+// loaded rig (fairness), then the older bead.
+func headLess(a Item, ra *RigState, b Item, rb *RigState) bool {
+	if a.Priority != b.Priority {
+		return a.Priority < b.Priority
+	}
+	if la, lb := ra.load(), rb.load(); la != lb {
+		return la < lb
+	}
+	if ra.Active != rb.Active {
+		return ra.Active < rb.Active
+	}
+	return queueLess(a, b)
+}
@@ -0,0 +1,132 @@
+package scheduler
+
+import (
+	"strings"
+	"testing"
+)
+
+func beadsOf(items []Item) string {
+	var ids []string
+	for _, it := range items {
+		ids = append(ids, it.Bead)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestSchedule_PriorityFirst(t *testing.T) {
+	plan := Schedule(Input{
+		Items: []Item{
+			{Bead: "a-low", Rig: "alpha", Priority: 3},
+			{Bead: "b-high", Rig: "beta", Priority: 0},
+			{Bead: "a-mid", Rig: "alpha", Priority: 1},
+		},
+		Rigs:        []RigState{{Name: "alpha", Max: 5}, {Name: "beta", Max: 5}},
+		MaxDispatch: 2,
+	})
+	if got := beadsOf(plan.Dispatch); got != "b-high,a-mid" {
+		t.Errorf("Dispatch = %s, want b-high,a-mid", got)
+	}
+	if len(plan.Waiting) != 1 || !strings.Contains(plan.Waiting[0].Reason, "cycle dispatch limit") {
+		t.Errorf("Waiting = %+v, want a-low held by cycle limit", plan.Waiting)
+	}
+}
+
+func TestSchedule_FairAcrossRigs(t *testing.T) {
+	// Equal priority: the less loaded rig goes first, alternating as load changes.
+	plan := Schedule(Input{
+		Items: []Item{
+			{Bead: "a1", Rig: "alpha", Priority: 2, CreatedAt: "2026-01-01"},
+			{Bead: "a2", Rig: "alpha", Priority: 2, CreatedAt: "2026-01-02"},
+			{Bead: "a3", Rig: "alpha", Priority: 2, CreatedAt: "2026-01-03"},
+			{Bead: "b1", Rig: "beta", Priority: 2, CreatedAt: "2026-02-01"},
+			{Bead: "b2", Rig: "beta", Priority: 2, CreatedAt: "2026-02-02"},
+		},
+		Rigs:        []RigState{{Name: "alpha", Active: 1, Max: 4}, {Name: "beta", Max: 4}},
+		MaxDispatch: 4,
+	})
+	if got := beadsOf(plan.Dispatch); got != "b1,a1,b2,a2" {
+		t.Errorf("Dispatch = %s, want b1,a1,b2,a2", got)
+	}
+}
+
+func TestSchedule_RigQuota(t *testing.T) {
+	plan := Schedule(Input{
+		Items: []Item{
+			{Bead: "a1", Rig: "alpha", Priority: 0},
+			{Bead: "b1", Rig: "beta", Priority: 4},
+		},
+		Rigs: []RigState{{Name: "alpha", Active: 2, Max: 2}, {Name: "beta", Max: 0}},
+	})
+	if got := beadsOf(plan.Dispatch); got != "b1" {
+		t.Errorf("Dispatch = %s, want b1 (alpha full, beta unlimited)", got)
+	}
+	if len(plan.Waiting) != 1 || plan.Waiting[0].Reason != "rig quota full (2/2 polecats)" {
+		t.Errorf("Waiting = %+v", plan.Waiting)
+	}
+}
+
+func TestSchedule_TownCap(t *testing.T) {
+	plan := Schedule(Input{
+		Items: []Item{
+			{Bead: "a1", Rig: "alpha", Priority: 1},
+			{Bead: "b1", Rig: "beta", Priority: 2},
+		},
+		Rigs:    []RigState{{Name: "alpha", Active: 2}, {Name: "beta", Active: 1}},
+		TownMax: 4,
+	})
+	if got := beadsOf(plan.Dispatch); got != "a1" {
+		t.Errorf("Dispatch = %s, want a1", got)
+	}
+	if plan.TownActive != 4 {
+		t.Errorf("TownActive = %d, want 4", plan.TownActive)
+	}
+	if len(plan.Waiting) != 1 || plan.Waiting[0].Reason != "town cap reached (4/4 polecats)" {
+		t.Errorf("Waiting = %+v", plan.Waiting)
+	}
+}
+
+func TestSchedule_DoltAtCapacity(t *testing.T) {
+	plan := Schedule(Input{
+		Items:          []Item{{Bead: "a1", Rig: "alpha"}},
+		Rigs:           []RigState{{Name: "alpha"}},
+		DoltAtCapacity: true,
+		DoltActive:     850,
+	})
+	if len(plan.Dispatch) != 0 {
+		t.Fatalf("Dispatch = %v, want none", plan.Dispatch)
+	}
+	if !strings.Contains(plan.Waiting[0].Reason, "850 active") {
+		t.Errorf("Reason = %q", plan.Waiting[0].Reason)
+	}
+}
+
+func TestSchedule_BlockedRig(t *testing.T) {
+	plan := Schedule(Input{
+		Items: []Item{{Bead: "a1", Rig: "alpha"}, {Bead: "x1", Rig: "ghost"}},
+		Rigs:  []RigState{{Name: "alpha", Blocked: "rig is parked"}},
+	})
+	if len(plan.Dispatch) != 0 {
+		t.Fatalf("Dispatch = %v, want none", plan.Dispatch)
+	}
+	reasons := map[string]string{}
+	for _, w := range plan.Waiting {
+		reasons[w.Bead] = w.Reason
+	}
+	if reasons["a1"] != "rig is parked" || reasons["x1"] != "rig ghost not found" {
+		t.Errorf("reasons = %v", reasons)
+	}
+}
+
+func TestSchedule_DoesNotMutateInput(t *testing.T) {
+	in := Input{
+		Items: []Item{{Bead: "b", Rig: "alpha", Priority: 2}, {Bead: "a", Rig: "alpha", Priority: 1}},
+		Rigs:  []RigState{{Name: "alpha", Max: 3}},
+	}
+	plan := Schedule(in)
+	if in.Items[0].Bead != "b" || in.Rigs[0].Active != 0 {
+		t.Errorf("input mutated: %+v", in)
+	}
+	if plan.Rigs[0].Active != 2 {
+		t.Errorf("plan rig Active = %d, want 2", plan.Rigs[0].Active)
+	}
+}
@@ -0,0 +1,130 @@
+package scheduler
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/doltserver"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/tmux"
+	"github.com/steveyegge/gastown/internal/wisp"
+)
+
+// schedulableTypes are the bead types the scheduler hands to polecats.
+// Epics, convoys, agents and molecules are coordination beads, not work.
+var schedulableTypes = []string{"task", "bug", "feature", "chore"}
+
+// Snapshot gathers the scheduler input for a town: ready beads and polecat
+// pool state for every rig in scope, plus Dolt capacity. Rigs whose beads
+// cannot be read are reported as blocked rather than failing the snapshot.
+func Snapshot(townRoot string, cfg *Config) (Input, error) {
+	in := Input{}
+	if cfg != nil {
+		in.TownMax = cfg.TownMaxPolecats
+		in.MaxDispatch = cfg.MaxDispatchPerCycle
+	}
+
+	rigsConfig, err := config.LoadRigsConfig(filepath.Join(townRoot, "mayor", "rigs.json"))
+	if err != nil {
+		return in, fmt.Errorf("loading rigs config: %w", err)
+	}
+	rigs, err := rig.NewManager(townRoot, rigsConfig, git.NewGit(townRoot)).DiscoverRigs()
+	if err != nil {
+		return in, fmt.Errorf("discovering rigs: %w", err)
+	}
+	slices.SortFunc(rigs, func(a, b *rig.Rig) int {
+		if a.Name < b.Name {
+			return -1
+		}
+		if a.Name > b.Name {
+			return 1
+		}
+		return 0
+	})
+
+	t := tmux.NewTmux()
+	for _, r := range rigs {
+		state := RigState{Name: r.Name, Max: r.GetIntConfig("max_polecats")}
+		if polecats, err := polecat.NewManager(r, git.NewGit(r.Path), t).List(); err == nil {
+			for _, p := range polecats {
+				if p.State.IsActive() {
+					state.Active++
+				}
+			}
+		}
+
+		// Out-of-scope rigs still count toward the town cap.
+		if !cfg.IncludesRig(r.Name) {
+			state.Blocked = "rig not scheduled"
+			in.Rigs = append(in.Rigs, state)
+			continue
+		}
+		state.Blocked = rigBlocked(townRoot, r.Name)
+
+		items, err := readyItems(r, cfg)
+		if err != nil {
+			state.Blocked = fmt.Sprintf("reading ready beads: %v", err)
+		}
+		in.Items = append(in.Items, items...)
+		in.Rigs = append(in.Rigs, state)
+	}
+
+	hasCapacity, active, err := doltserver.HasConnectionCapacity(townRoot)
+	if err == nil && !hasCapacity {
+		in.DoltAtCapacity = true
+		in.DoltActive = active
+	}
+	return in, nil
+}
+
+// rigBlocked returns why a rig must not receive work, or "".
+func rigBlocked(townRoot, rigName string) string {
+	switch wisp.NewConfig(townRoot, rigName).GetString("status") {
+	case "parked":
+		return "rig is parked"
+	case "docked":
+		return "rig is docked"
+	}
+	return ""
+}
+
+// readyItems lists unassigned ready work beads in a rig.
+func readyItems(r *rig.Rig, cfg *Config) ([]Item, error) {
+	bd := beads.NewWithBeadsDir(r.Path, beads.ResolveBeadsDir(r.Path))
+	issues, err := bd.Ready()
+	if err != nil {
+		return nil, err
+	}
+
+	var items []Item
+	for _, issue := range issues {
+		if !Schedulable(issue) {
+			continue
+		}
+		if cfg != nil && cfg.Label != "" && !slices.Contains(issue.Labels, cfg.Label) {
+			continue
+		}
+		items = append(items, Item{
+			Bead:      issue.ID,
+			Rig:       r.Name,
+			Title:     issue.Title,
+			Priority:  issue.Priority,
+			CreatedAt: issue.CreatedAt,
+		})
+	}
+	return items, nil
+}
+
+// Schedulable reports whether a ready issue is work a polecat can take:
+// unassigned, not ephemeral, and of a schedulable type.
+func Schedulable(issue *beads.Issue) bool {
+	if issue.Assignee != "" || issue.Ephemeral {
+		return false
+	}
+	return issue.Type == "" || slices.Contains(schedulableTypes, issue.Type)
+}
@@ -0,0 +1,454 @@
+// Package scorecard aggregates how agents have performed from the town's
+// history: beads completed and how long they took, merge request outcomes,
+// handoffs, escalations, crashes and cost. Cards are built per identity
+// (e.g. "gastown/polecats/nux") and per agent preset (e.g. "claude"), so
+// ephemeral polecats still contribute to a durable record for the runtime
+// they ran.
+package scorecard
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/krc"
+)
+
+// Card is the aggregated record for one identity or preset.
+type Card struct {
+	// Key is the identity address or the preset name.
+	Key string `json:"key"`
+
+	// Preset is the agent preset an identity ran (identity cards only).
+	Preset string `json:"preset,omitempty"`
+
+	// Identities counts the identities rolled into a preset card.
+	Identities int `json:"identities,omitempty"`
+
+	BeadsCompleted int `json:"beads_completed"`
+
+	// MedianTimeToDone is measured from the bead's first sling (or its
+	// creation, if it was never slung) to completion.
+	MedianTimeToDone time.Duration `json:"median_time_to_done_ns"`
+
+	// MRs counts merge requests with at least one refinery outcome.
+	MRs             int `json:"mrs"`
+	MRsMerged       int `json:"mrs_merged"`
+	FirstPassMerges int `json:"first_pass_merges"`
+
+	// FirstPassRate is FirstPassMerges/MRs (0 when there are no MRs).
+	FirstPassRate float64 `json:"first_pass_rate"`
+
+	// MergeFailures counts failed merge attempts by refinery FailureType.
+	MergeFailures map[string]int `json:"merge_failures,omitempty"`
+
+	Handoffs int `json:"handoffs"`
+
+	// HandoffsPerBead is Handoffs/BeadsCompleted (0 when none completed).
+	HandoffsPerBead float64 `json:"handoffs_per_bead"`
+
+	Escalations int     `json:"escalations"`
+	Crashes     int     `json:"crashes"`
+	CostUSD     float64 `json:"cost_usd"`
+
+	durations []time.Duration
+	mrs       map[string]*mrState
+}
+
+// mrState tracks one merge request's outcomes in time order.
+type mrState struct {
+	failed bool
+	merged bool
+}
+
+// Reliability returns the Laplace-smoothed first-pass merge rate and the
+// number of MRs behind it. With no MRs the rate is a neutral 0.5. This is
+// the signal dispatch uses to prefer agents whose work lands cleanly.
+func (c *Card) Reliability() (float64, int) {
+	if c == nil {
+		return 0.5, 0
+	}
+	return (float64(c.FirstPassMerges) + 1) / (float64(c.MRs) + 2), c.MRs
+}
+
+// Report holds the cards built from one set of sources.
+type Report struct {
+	Since      time.Time `json:"since,omitempty"`
+	Identities []*Card   `json:"identities"`
+	Presets    []*Card   `json:"presets"`
+}
+
+// Identity returns the card for an identity address, or nil.
+func (r *Report) Identity(key string) *Card {
+	if r == nil {
+		return nil
+	}
+	return findCard(r.Identities, NormalizeIdentity(key))
+}
+
+// Preset returns the card for an agent preset, or nil.
+func (r *Report) Preset(name string) *Card {
+	if r == nil {
+		return nil
+	}
+	return findCard(r.Presets, name)
+}
+
+func findCard(cards []*Card, key string) *Card {
+	for _, c := range cards {
+		if c.Key == key {
+			return c
+		}
+	}
+	return nil
+}
+
+// Bead is a closed bead from the beads database.
+type Bead struct {
+	ID        string
+	Assignee  string
+	CreatedAt time.Time
+	ClosedAt  time.Time
+}
+
+// Crash is an agent crash from the town log.
+type Crash struct {
+	Agent string
+	At    time.Time
+}
+
+// Cost is the cost of one agent session.
+type Cost struct {
+	Identity string
+	USD      float64
+	At       time.Time
+}
+
+// Sources are the inputs to Build. Any of them may be empty.
+type Sources struct {
+	// Events are activity events (live and archived), in any order.
+	// Events before Since are still used to find when a bead was slung
+	// and to whom.
+	Events []events.Event
+
+	Beads   []Bead
+	Crashes []Crash
+	Costs   []Cost
+
+	// Since limits counted outcomes to those at or after it (zero = all).
+	Since time.Time
+
+	// Preset resolves an identity's agent preset when no sling event
+	// recorded one. May be nil.
+	Preset func(identity string) string
+}
+
+// Build aggregates the sources into identity and preset cards, each sorted
+// by key.
+func Build(src Sources) *Report {
+	b := &builder{
+		since:    src.Since,
+		cards:    make(map[string]*Card),
+		owner:    make(map[string]string),
+		slungAt:  make(map[string]time.Time),
+		done:     make(map[string]bool),
+		presetOf: make(map[string]string),
+	}
+
+	evs := append([]events.Event(nil), src.Events...)
+	sort.SliceStable(evs, func(i, j int) bool { return evs[i].Time().Before(evs[j].Time()) })
+	for i := range evs {
+		b.event(&evs[i])
+	}
+
+	for _, bead := range src.Beads {
+		if bead.Assignee == "" || b.done[bead.ID] || !b.counts(bead.ClosedAt) {
+			continue
+		}
+		b.complete(NormalizeIdentity(bead.Assignee), bead.ID, bead.ClosedAt, bead.CreatedAt)
+	}
+	for _, c := range src.Crashes {
+		if b.counts(c.At) && c.Agent != "" {
+			b.card(c.Agent).Crashes++
+		}
+	}
+	for _, c := range src.Costs {
+		if b.counts(c.At) && c.Identity != "" {
+			b.card(c.Identity).CostUSD += c.USD
+		}
+	}
+
+	report := &Report{Since: src.Since}
+	presets := make(map[string]*Card)
+	for key, card := range b.cards {
+		card.finish()
+		card.Preset = b.presetOf[key]
+		if card.Preset == "" && src.Preset != nil {
+			card.Preset = src.Preset(key)
+		}
+		report.Identities = append(report.Identities, card)
+
+		name := card.Preset
+		if name == "" {
+			name = "unknown"
+		}
+		p := presets[name]
+		if p == nil {
+			p = &Card{Key: name}
+			presets[name] = p
+			report.Presets = append(report.Presets, p)
+		}
+		p.merge(card)
+	}
+	for _, p := range report.Presets {
+		p.finish()
+	}
+
+	sort.Slice(report.Identities, func(i, j int) bool { return report.Identities[i].Key < report.Identities[j].Key })
+	sort.Slice(report.Presets, func(i, j int) bool { return report.Presets[i].Key < report.Presets[j].Key })
+	return report
+}
+
+type builder struct {
+	since    time.Time
+	cards    map[string]*Card
+	owner    map[string]string    // bead -> identity it was last slung to
+	slungAt  map[string]time.Time // bead -> first sling
+	done     map[string]bool      // beads already completed by a done event
+	presetOf map[string]string    // identity -> preset from its latest sling
+}
+
+func (b *builder) counts(at time.Time) bool {
+	return b.since.IsZero() || !at.Before(b.since)
+}
+
+func (b *builder) card(identity string) *Card {
+	identity = NormalizeIdentity(identity)
+	c := b.cards[identity]
+	if c == nil {
+		c = &Card{Key: identity}
+		b.cards[identity] = c
+	}
+	return c
+}
+
+func (b *builder) complete(identity, bead string, at, created time.Time) {
+	c := b.card(identity)
+	c.BeadsCompleted++
+	start := created
+	if slung, ok := b.slungAt[bead]; ok {
+		start = slung
+	}
+	if !start.IsZero() && at.After(start) {
+		c.durations = append(c.durations, at.Sub(start))
+	}
+}
+
+func (b *builder) event(e *events.Event) {
+	ts := e.Time()
+	bead := payloadString(e.Payload, "bead")
+
+	switch e.Type {
+	case events.TypeSling:
+		target := NormalizeIdentity(payloadString(e.Payload, "target"))
+		if bead == "" || !isIdentity(target) {
+			return
+		}
+		b.owner[bead] = target
+		if _, ok := b.slungAt[bead]; !ok && !ts.IsZero() {
+			b.slungAt[bead] = ts
+		}
+		if agent := payloadString(e.Payload, "agent"); agent != "" {
+			b.presetOf[target] = agent
+		}
+
+	case events.TypeDone:
+		if !b.counts(ts) || bead == "" || b.done[bead] {
+			return
+		}
+		switch payloadString(e.Payload, "exit_type") {
+		case "", "COMPLETED":
+			b.done[bead] = true
+			b.complete(e.Actor, bead, ts, time.Time{})
+		}
+
+	case events.TypeHandoff:
+		if b.counts(ts) && e.Actor != "" {
+			b.card(e.Actor).Handoffs++
+		}
+
+	case events.TypeEscalationSent:
+		// Re-escalations are raised by the system, not the agent.
+		if reescalated, _ := e.Payload["reescalated"].(bool); reescalated {
+			return
+		}
+		if b.counts(ts) && e.Actor != "" {
+			b.card(e.Actor).Escalations++
+		}
+
+	case events.TypeMerged, events.TypeMergeFailed:
+		if !b.counts(ts) {
+			return
+		}
+		identity := b.mergeIdentity(e, bead)
+		if identity == "" {
+			return
+		}
+		mr := payloadString(e.Payload, "mr")
+		if mr == "" {
+			mr = bead
+		}
+		if mr == "" {
+			return
+		}
+		c := b.card(identity)
+		if c.mrs == nil {
+			c.mrs = make(map[string]*mrState)
+		}
+		st := c.mrs[mr]
+		if st == nil {
+			st = &mrState{}
+			c.mrs[mr] = st
+		}
+		if e.Type == events.TypeMerged {
+			st.merged = true
+			return
+		}
+		st.failed = true
+		failure := payloadString(e.Payload, "failure_type")
+		if failure == "" {
+			failure = "unknown"
+		}
+		if c.MergeFailures == nil {
+			c.MergeFailures = make(map[string]int)
+		}
+		c.MergeFailures[failure]++
+	}
+}
+
+// mergeIdentity attributes a merge outcome to whoever the bead was slung
+// to, falling back to the MR's worker within the refinery's rig.
+func (b *builder) mergeIdentity(e *events.Event, bead string) string {
+	if owner, ok := b.owner[bead]; ok {
+		return owner
+	}
+	worker := payloadString(e.Payload, "worker")
+	if worker == "" || strings.Contains(worker, "/") {
+		return NormalizeIdentity(worker)
+	}
+	rig := payloadString(e.Payload, "rig")
+	if rig == "" {
+		rig, _, _ = strings.Cut(e.Actor, "/")
+	}
+	if rig == "" {
+		return ""
+	}
+	return rig + "/polecats/" + worker
+}
+
+// finish derives the summary fields from the raw counts.
+func (c *Card) finish() {
+	if c.mrs != nil {
+		c.MRs, c.MRsMerged, c.FirstPassMerges = 0, 0, 0
+		for _, st := range c.mrs {
+			c.MRs++
+			if st.merged {
+				c.MRsMerged++
+				if !st.failed {
+					c.FirstPassMerges++
+				}
+			}
+		}
+	}
+	if c.MRs > 0 {
+		c.FirstPassRate = float64(c.FirstPassMerges) / float64(c.MRs)
+	}
+	if c.BeadsCompleted > 0 {
+		c.HandoffsPerBead = float64(c.Handoffs) / float64(c.BeadsCompleted)
+	}
+	c.MedianTimeToDone = median(c.durations)
+}
+
+// merge adds an identity card's raw counts into a preset card.
+func (c *Card) merge(o *Card) {
+	c.Identities++
+	c.BeadsCompleted += o.BeadsCompleted
+	c.durations = append(c.durations, o.durations...)
+	c.MRs += o.MRs
+	c.MRsMerged += o.MRsMerged
+	c.FirstPassMerges += o.FirstPassMerges
+	for k, v := range o.MergeFailures {
+		if c.MergeFailures == nil {
+			c.MergeFailures = make(map[string]int)
+		}
+		c.MergeFailures[k] += v
+	}
+	c.Handoffs += o.Handoffs
+	c.Escalations += o.Escalations
+	c.Crashes += o.Crashes
+	c.CostUSD += o.CostUSD
+}
+
+func median(ds []time.Duration) time.Duration {
+	if len(ds) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), ds...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// NormalizeIdentity trims the trailing slash some addresses carry
+// ("mayor/") so every source keys the same identity.
+func NormalizeIdentity(s string) string {
+	return strings.TrimSuffix(strings.TrimSpace(s), "/")
+}
+
+// isIdentity rejects sling targets that don't name a concrete agent, such
+// as a bare rig or "<rig>/polecats/<new>" placeholders.
+func isIdentity(target string) bool {
+	return target != "" && !strings.Contains(target, "<")
+}
+
+// IdentityFor builds an identity address from the role, rig and worker a
+// cost record or session name carries.
+func IdentityFor(role, rig, worker string) string {
+	switch role {
+	case "mayor", "deacon":
+		return role
+	case "witness", "refinery":
+		return rig + "/" + role
+	case "crew":
+		return rig + "/crew/" + worker
+	case "polecat":
+		return rig + "/polecats/" + worker
+	}
+	if rig == "" {
+		return worker
+	}
+	return rig + "/" + worker
+}
+
+func payloadString(p map[string]interface{}, key string) string {
+	s, _ := p[key].(string)
+	return s
+}
+
+// LoadEvents returns the town's activity events at or after since, from
+// both the live events file and the cold archive.
+func LoadEvents(townRoot string, since time.Time) ([]events.Event, error) {
+	records, err := krc.Query(townRoot, krc.QueryOptions{Since: since})
+	if err != nil {
+		return nil, err
+	}
+	evs := make([]events.Event, len(records))
+	for i, r := range records {
+		evs[i] = r.Event
+	}
+	return evs, nil
+}
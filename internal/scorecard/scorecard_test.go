@@ -0,0 +1,142 @@
+package scorecard
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+var t0 = time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+func ev(offset time.Duration, typ, actor string, payload map[string]interface{}) events.Event {
+	return events.Event{Timestamp: t0.Add(offset).Format(time.RFC3339), Type: typ, Actor: actor, Payload: payload}
+}
+
+func mergeEv(offset time.Duration, typ, mr, bead, worker, failure string) events.Event {
+	p := events.MergePayload(mr, worker, "polecat/"+worker, failure)
+	p["rig"] = "gastown"
+	p["bead"] = bead
+	if failure != "" {
+		p["failure_type"] = failure
+	}
+	return ev(offset, typ, "gastown/refinery", p)
+}
+
+func TestBuild(t *testing.T) {
+	nux := "gastown/polecats/nux"
+	slingNux := events.SlingPayload("gt-1", nux)
+	slingNux["agent"] = "codex"
+
+	src := Sources{
+		Events: []events.Event{
+			// Out of order on purpose: Build sorts by time.
+			ev(2*time.Hour, events.TypeDone, nux, map[string]interface{}{"bead": "gt-1", "exit_type": "COMPLETED"}),
+			ev(0, events.TypeSling, "mayor", slingNux),
+			ev(time.Hour, events.TypeHandoff, nux, events.HandoffPayload("context", true)),
+			mergeEv(3*time.Hour, events.TypeMerged, "gt-mr1", "gt-1", "nux", ""),
+
+			ev(0, events.TypeSling, "mayor", events.SlingPayload("gt-2", nux)),
+			ev(4*time.Hour, events.TypeDone, nux, map[string]interface{}{"bead": "gt-2"}),
+			mergeEv(5*time.Hour, events.TypeMergeFailed, "gt-mr2", "gt-2", "nux", "conflict"),
+			mergeEv(6*time.Hour, events.TypeMergeFailed, "gt-mr2", "gt-2", "nux", "tests_fail"),
+			mergeEv(7*time.Hour, events.TypeMerged, "gt-mr2", "gt-2", "nux", ""),
+
+			// Unknown bead: attributed to the worker in the refinery's rig.
+			mergeEv(8*time.Hour, events.TypeMergeFailed, "gt-mr3", "", "toast", "build_fail"),
+
+			ev(time.Hour, events.TypeEscalationSent, "gastown/crew/max", events.EscalationPayload("gt-9", "gastown/crew/max", "mayor", "stuck")),
+			ev(2*time.Hour, events.TypeEscalationSent, "deacon", map[string]interface{}{"reescalated": true}),
+
+			// Before Since: ignored.
+			ev(-48*time.Hour, events.TypeHandoff, nux, nil),
+		},
+		Beads: []Bead{
+			{ID: "gt-1", Assignee: nux, CreatedAt: t0.Add(-time.Hour), ClosedAt: t0.Add(3 * time.Hour)}, // already done via event
+			{ID: "gt-9", Assignee: "gastown/crew/max/", CreatedAt: t0, ClosedAt: t0.Add(30 * time.Minute)},
+			{ID: "gt-old", Assignee: "gastown/crew/max", CreatedAt: t0.Add(-72 * time.Hour), ClosedAt: t0.Add(-48 * time.Hour)},
+		},
+		Crashes: []Crash{{Agent: nux, At: t0.Add(time.Hour)}},
+		Costs:   []Cost{{Identity: nux, USD: 1.5, At: t0}, {Identity: nux, USD: 2, At: t0.Add(time.Hour)}},
+		Since:   t0.Add(-time.Hour),
+		Preset: func(identity string) string {
+			if identity == "gastown/crew/max" {
+				return "claude"
+			}
+			return ""
+		},
+	}
+
+	report := Build(src)
+
+	c := report.Identity(nux)
+	if c == nil {
+		t.Fatalf("no card for %s: %+v", nux, report.Identities)
+	}
+	if c.Preset != "codex" || c.BeadsCompleted != 2 || c.MedianTimeToDone != 3*time.Hour {
+		t.Errorf("nux preset=%q done=%d median=%v", c.Preset, c.BeadsCompleted, c.MedianTimeToDone)
+	}
+	if c.MRs != 2 || c.MRsMerged != 2 || c.FirstPassMerges != 1 || c.FirstPassRate != 0.5 {
+		t.Errorf("nux MRs=%d merged=%d first-pass=%d rate=%v", c.MRs, c.MRsMerged, c.FirstPassMerges, c.FirstPassRate)
+	}
+	if c.MergeFailures["conflict"] != 1 || c.MergeFailures["tests_fail"] != 1 {
+		t.Errorf("nux failures = %v", c.MergeFailures)
+	}
+	if c.Handoffs != 1 || c.HandoffsPerBead != 0.5 || c.Crashes != 1 || c.CostUSD != 3.5 {
+		t.Errorf("nux handoffs=%d per-bead=%v crashes=%d cost=%v", c.Handoffs, c.HandoffsPerBead, c.Crashes, c.CostUSD)
+	}
+	if rate, n := c.Reliability(); n != 2 || rate != 0.5 {
+		t.Errorf("Reliability = %v over %d", rate, n)
+	}
+
+	if toast := report.Identity("gastown/polecats/toast"); toast == nil || toast.MergeFailures["build_fail"] != 1 || toast.FirstPassRate != 0 {
+		t.Errorf("toast = %+v", toast)
+	}
+
+	crew := report.Identity("gastown/crew/max")
+	if crew == nil || crew.BeadsCompleted != 1 || crew.Escalations != 1 || crew.MedianTimeToDone != 30*time.Minute || crew.Preset != "claude" {
+		t.Errorf("crew/max = %+v", crew)
+	}
+	if report.Identity("deacon") != nil {
+		t.Error("re-escalations should not create a card for the re-escalator")
+	}
+
+	if p := report.Preset("codex"); p == nil || p.Identities != 1 || p.BeadsCompleted != 2 {
+		t.Errorf("codex preset = %+v", p)
+	}
+	if p := report.Preset("unknown"); p == nil || p.MRs != 1 {
+		t.Errorf("unknown preset = %+v", p)
+	}
+	if rate, n := report.Preset("gemini").Reliability(); n != 0 || rate != 0.5 {
+		t.Errorf("missing preset reliability = %v over %d, want neutral", rate, n)
+	}
+}
+
+func TestIdentityFor(t *testing.T) {
+	tests := []struct{ role, rig, worker, want string }{
+		{"mayor", "", "mayor", "mayor"},
+		{"witness", "gastown", "", "gastown/witness"},
+		{"crew", "gastown", "max", "gastown/crew/max"},
+		{"polecat", "gastown", "nux", "gastown/polecats/nux"},
+		{"unknown", "", "gt-x", "gt-x"},
+	}
+	for _, tt := range tests {
+		if got := IdentityFor(tt.role, tt.rig, tt.worker); got != tt.want {
+			t.Errorf("IdentityFor(%q, %q, %q) = %q, want %q", tt.role, tt.rig, tt.worker, got, tt.want)
+		}
+	}
+}
+
+func TestLoadEvents(t *testing.T) {
+	townRoot := t.TempDir()
+	line := `{"ts":"` + time.Now().UTC().Format(time.RFC3339) + `","type":"sling","actor":"mayor","payload":{"bead":"gt-1","target":"gastown/polecats/nux"}}` + "\n"
+	if err := os.WriteFile(filepath.Join(townRoot, events.EventsFile), []byte(line), 0644); err != nil {
+		t.Fatal(err)
+	}
+	evs, err := LoadEvents(townRoot, time.Now().Add(-time.Hour))
+	if err != nil || len(evs) != 1 || evs[0].Type != events.TypeSling {
+		t.Errorf("LoadEvents = %+v, %v", evs, err)
+	}
+}
@@ -0,0 +1,86 @@
+// Package systemd integrates Gas Town services with a systemd user manager:
+// the sd_notify readiness/watchdog protocol, unit file generation for the
+// daemon and Dolt server, and unit state queries via systemctl.
+//
+// Everything here is a no-op outside systemd, so callers don't need to
+// check whether they are supervised.
+package systemd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Notify states (see sd_notify(3)).
+const (
+	StateReady     = "READY=1"
+	StateStopping  = "STOPPING=1"
+	StateReloading = "RELOADING=1"
+	StateWatchdog  = "WATCHDOG=1"
+)
+
+// Notify sends state to the service manager over $NOTIFY_SOCKET.
+// Returns false, nil when not running under systemd with notify support.
+func Notify(state string) (bool, error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return false, nil
+	}
+	// A leading @ denotes the Linux abstract namespace.
+	if strings.HasPrefix(socket, "@") {
+		socket = "\x00" + socket[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return false, fmt.Errorf("connecting to notify socket: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, fmt.Errorf("writing to notify socket: %w", err)
+	}
+	return true, nil
+}
+
+// Ready reports startup complete, with a human-readable status line.
+func Ready(status string) (bool, error) {
+	return Notify(StateReady + "\nSTATUS=" + status)
+}
+
+// Status updates the status line shown by systemctl status.
+func Status(status string) (bool, error) {
+	return Notify("STATUS=" + status)
+}
+
+// Stopping reports that shutdown has begun.
+func Stopping() (bool, error) {
+	return Notify(StateStopping)
+}
+
+// Watchdog pets the service watchdog.
+func Watchdog() (bool, error) {
+	return Notify(StateWatchdog)
+}
+
+// WatchdogInterval returns how often the service must pet the watchdog:
+// half of $WATCHDOG_USEC, as sd_watchdog_enabled(3) recommends. Returns
+// false if the watchdog is not enabled for this process.
+func WatchdogInterval() (time.Duration, bool) {
+	usec := os.Getenv("WATCHDOG_USEC")
+	if usec == "" {
+		return 0, false
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0, false // Meant for another process (e.g. a wrapper)
+	}
+	n, err := strconv.ParseInt(usec, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	return time.Duration(n) * time.Microsecond / 2, true
+}
@@ -0,0 +1,103 @@
+package systemd
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// listenNotify creates a local notify socket and points NOTIFY_SOCKET at it.
+func listenNotify(t *testing.T) *net.UnixConn {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "sdn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	t.Setenv("NOTIFY_SOCKET", path)
+	return conn
+}
+
+func readDatagram(t *testing.T, conn *net.UnixConn) string {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 1024)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("reading notify datagram: %v", err)
+	}
+	return string(buf[:n])
+}
+
+func TestNotify_NoSocket(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	ok, err := Ready("up")
+	if ok || err != nil {
+		t.Errorf("Ready() without socket = %v, %v; want false, nil", ok, err)
+	}
+}
+
+func TestNotify_Ready(t *testing.T) {
+	conn := listenNotify(t)
+
+	ok, err := Ready("Running (PID 1)")
+	if !ok || err != nil {
+		t.Fatalf("Ready() = %v, %v", ok, err)
+	}
+	if got, want := readDatagram(t, conn), "READY=1\nSTATUS=Running (PID 1)"; got != want {
+		t.Errorf("datagram = %q, want %q", got, want)
+	}
+}
+
+func TestNotify_WatchdogAndStopping(t *testing.T) {
+	conn := listenNotify(t)
+
+	if _, err := Watchdog(); err != nil {
+		t.Fatal(err)
+	}
+	if got := readDatagram(t, conn); got != StateWatchdog {
+		t.Errorf("watchdog datagram = %q", got)
+	}
+	if _, err := Stopping(); err != nil {
+		t.Fatal(err)
+	}
+	if got := readDatagram(t, conn); got != StateStopping {
+		t.Errorf("stopping datagram = %q", got)
+	}
+}
+
+func TestNotify_BadSocket(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", filepath.Join(t.TempDir(), "missing.sock"))
+	if ok, err := Watchdog(); ok || err == nil {
+		t.Errorf("Watchdog() to missing socket = %v, %v; want error", ok, err)
+	}
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	t.Setenv("WATCHDOG_USEC", "")
+	if _, ok := WatchdogInterval(); ok {
+		t.Error("watchdog should be disabled without WATCHDOG_USEC")
+	}
+
+	t.Setenv("WATCHDOG_USEC", "600000000") // 10 minutes
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()))
+	got, ok := WatchdogInterval()
+	if !ok || got != 5*time.Minute {
+		t.Errorf("WatchdogInterval() = %v, %v; want 5m, true", got, ok)
+	}
+
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()+1))
+	if _, ok := WatchdogInterval(); ok {
+		t.Error("watchdog meant for another PID should be ignored")
+	}
+}
@@ -0,0 +1,155 @@
+package systemd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// UserUnitDir returns the directory for user unit files
+// ($XDG_CONFIG_HOME/systemd/user, default ~/.config/systemd/user).
+func UserUnitDir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "systemd", "user"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("finding home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "systemd", "user"), nil
+}
+
+// Available reports whether a systemd user manager is reachable.
+func Available() bool {
+	if _, err := exec.LookPath("systemctl"); err != nil {
+		return false
+	}
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" {
+		runtimeDir = fmt.Sprintf("/run/user/%d", os.Getuid())
+	}
+	_, err := os.Stat(filepath.Join(runtimeDir, "systemd"))
+	return err == nil
+}
+
+// Installed reports whether a user unit file exists.
+func Installed(unitName string) bool {
+	dir, err := UserUnitDir()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(filepath.Join(dir, unitName))
+	return err == nil
+}
+
+// WriteUnit writes a unit file to the user unit directory and returns its path.
+func WriteUnit(u *Unit) (string, error) {
+	dir, err := UserUnitDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("creating %s: %w", dir, err)
+	}
+	path := filepath.Join(dir, u.Name)
+	if err := os.WriteFile(path, []byte(u.Render()), 0644); err != nil {
+		return "", fmt.Errorf("writing %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// Systemctl runs systemctl --user with args.
+func Systemctl(args ...string) error {
+	cmd := exec.Command("systemctl", append([]string{"--user"}, args...)...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("systemctl --user %s: %s", strings.Join(args, " "), msg)
+		}
+		return fmt.Errorf("systemctl --user %s: %w", strings.Join(args, " "), err)
+	}
+	return nil
+}
+
+// UnitStatus is the systemd view of a unit.
+type UnitStatus struct {
+	Unit        string `json:"unit"`
+	LoadState   string `json:"load_state"`   // loaded, not-found, ...
+	ActiveState string `json:"active_state"` // active, inactive, failed, activating, ...
+	SubState    string `json:"sub_state"`    // running, dead, auto-restart, ...
+	MainPID     int    `json:"main_pid,omitempty"`
+	Restarts    int    `json:"restarts,omitempty"`
+	WatchdogSec int    `json:"watchdog_sec,omitempty"`
+}
+
+// Active reports whether the unit is active.
+func (s *UnitStatus) Active() bool {
+	return s.ActiveState == "active"
+}
+
+// Show queries a unit's state.
+func Show(unitName string) (*UnitStatus, error) {
+	out, err := exec.Command("systemctl", "--user", "show",
+		"--property=LoadState,ActiveState,SubState,MainPID,NRestarts,WatchdogUSec",
+		unitName).Output()
+	if err != nil {
+		return nil, fmt.Errorf("systemctl --user show %s: %w", unitName, err)
+	}
+	return parseShow(unitName, string(out)), nil
+}
+
+// parseShow parses systemctl show key=value output.
+func parseShow(unitName, output string) *UnitStatus {
+	s := &UnitStatus{Unit: unitName}
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "LoadState":
+			s.LoadState = value
+		case "ActiveState":
+			s.ActiveState = value
+		case "SubState":
+			s.SubState = value
+		case "MainPID":
+			s.MainPID, _ = strconv.Atoi(value)
+		case "NRestarts":
+			s.Restarts, _ = strconv.Atoi(value)
+		case "WatchdogUSec":
+			s.WatchdogSec = parseUSec(value)
+		}
+	}
+	return s
+}
+
+// parseUSec parses a WatchdogUSec value, which systemctl prints either as
+// raw microseconds or as a timespan like "10min" or "1min 30s".
+func parseUSec(v string) int {
+	if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return int(n / 1_000_000)
+	}
+	total := 0
+	for _, part := range strings.Fields(v) {
+		i := strings.IndexFunc(part, func(r rune) bool { return r < '0' || r > '9' })
+		if i <= 0 {
+			continue
+		}
+		n, _ := strconv.Atoi(part[:i])
+		switch part[i:] {
+		case "h":
+			total += n * 3600
+		case "min":
+			total += n * 60
+		case "s":
+			total += n
+		}
+	}
+	return total
+}
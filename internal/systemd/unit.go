@@ -0,0 +1,174 @@
+package systemd
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultWatchdog is the daemon's WatchdogSec. The daemon pets the watchdog
+// from its main loop, so a heartbeat wedged for this long gets the daemon
+// restarted.
+const DefaultWatchdog = 10 * time.Minute
+
+// Unit is a systemd service unit.
+type Unit struct {
+	// Name is the unit file name, e.g. "gastown-town-daemon.service".
+	Name        string
+	Description string
+	After       []string
+	Wants       []string
+
+	// Type is the service type: "simple" or "notify".
+	Type             string
+	ExecStart        []string
+	WorkingDirectory string
+	Environment      map[string]string
+	Restart          string
+	RestartSec       time.Duration
+	WatchdogSec      time.Duration
+	TimeoutStopSec   time.Duration
+
+	// KillMode "process" leaves children (e.g. the tmux server the daemon
+	// starts) running when the unit stops.
+	KillMode string
+
+	StandardOutput string
+	StandardError  string
+}
+
+// Render returns the unit file contents.
+func (u *Unit) Render() string {
+	var b strings.Builder
+	b.WriteString("# Generated by gt daemon install-unit. Regenerate rather than edit.\n")
+	b.WriteString("[Unit]\n")
+	fmt.Fprintf(&b, "Description=%s\n", u.Description)
+	if len(u.Wants) > 0 {
+		fmt.Fprintf(&b, "Wants=%s\n", strings.Join(u.Wants, " "))
+	}
+	if len(u.After) > 0 {
+		fmt.Fprintf(&b, "After=%s\n", strings.Join(u.After, " "))
+	}
+
+	b.WriteString("\n[Service]\n")
+	fmt.Fprintf(&b, "Type=%s\n", u.Type)
+	quoted := make([]string, len(u.ExecStart))
+	for i, arg := range u.ExecStart {
+		quoted[i] = quoteArg(arg)
+	}
+	fmt.Fprintf(&b, "ExecStart=%s\n", strings.Join(quoted, " "))
+	if u.WorkingDirectory != "" {
+		fmt.Fprintf(&b, "WorkingDirectory=%s\n", escapeSpecifiers(u.WorkingDirectory))
+	}
+	for _, key := range sortedKeys(u.Environment) {
+		fmt.Fprintf(&b, "Environment=%s\n", quoteArg(key+"="+u.Environment[key]))
+	}
+	if u.Restart != "" {
+		fmt.Fprintf(&b, "Restart=%s\n", u.Restart)
+	}
+	if u.RestartSec > 0 {
+		fmt.Fprintf(&b, "RestartSec=%d\n", int(u.RestartSec.Seconds()))
+	}
+	if u.WatchdogSec > 0 {
+		fmt.Fprintf(&b, "WatchdogSec=%d\n", int(u.WatchdogSec.Seconds()))
+	}
+	if u.TimeoutStopSec > 0 {
+		fmt.Fprintf(&b, "TimeoutStopSec=%d\n", int(u.TimeoutStopSec.Seconds()))
+	}
+	if u.KillMode != "" {
+		fmt.Fprintf(&b, "KillMode=%s\n", u.KillMode)
+	}
+	if u.StandardOutput != "" {
+		fmt.Fprintf(&b, "StandardOutput=%s\n", escapeSpecifiers(u.StandardOutput))
+	}
+	if u.StandardError != "" {
+		fmt.Fprintf(&b, "StandardError=%s\n", escapeSpecifiers(u.StandardError))
+	}
+
+	b.WriteString("\n[Install]\n")
+	b.WriteString("WantedBy=default.target\n")
+	return b.String()
+}
+
+// unitNameUnsafe matches characters not allowed in unit names.
+var unitNameUnsafe = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// UnitName returns the unit name for a town service ("daemon" or "dolt").
+// Names include the town directory so several towns can share a user.
+func UnitName(townRoot, service string) string {
+	town := unitNameUnsafe.ReplaceAllString(filepath.Base(townRoot), "-")
+	town = strings.Trim(town, "-.")
+	if town == "" {
+		town = "town"
+	}
+	return fmt.Sprintf("gastown-%s-%s.service", town, service)
+}
+
+// DaemonUnit returns the unit for the Gas Town daemon. doltUnit, if set,
+// is started first.
+func DaemonUnit(townRoot, gtPath, path, doltUnit string) *Unit {
+	u := &Unit{
+		Name:             UnitName(townRoot, "daemon"),
+		Description:      fmt.Sprintf("Gas Town daemon (%s)", townRoot),
+		Type:             "notify",
+		ExecStart:        []string{gtPath, "daemon", "run"},
+		WorkingDirectory: townRoot,
+		Restart:          "on-failure",
+		RestartSec:       5 * time.Second,
+		WatchdogSec:      DefaultWatchdog,
+		TimeoutStopSec:   time.Minute,
+		KillMode:         "process",
+	}
+	if path != "" {
+		u.Environment = map[string]string{"PATH": path}
+	}
+	if doltUnit != "" {
+		u.Wants = []string{doltUnit}
+		u.After = []string{doltUnit}
+	}
+	return u
+}
+
+// DoltUnit returns the unit for the town's Dolt SQL server. args are the
+// sql-server arguments, as used by gt dolt start.
+func DoltUnit(townRoot, doltPath string, args []string, dataDir, logFile string) *Unit {
+	return &Unit{
+		Name:             UnitName(townRoot, "dolt"),
+		Description:      fmt.Sprintf("Gas Town Dolt SQL server (%s)", townRoot),
+		Type:             "simple",
+		ExecStart:        append([]string{doltPath}, args...),
+		WorkingDirectory: dataDir,
+		Restart:          "on-failure",
+		RestartSec:       5 * time.Second,
+		TimeoutStopSec:   30 * time.Second,
+		StandardOutput:   "append:" + logFile,
+		StandardError:    "append:" + logFile,
+	}
+}
+
+// escapeSpecifiers escapes systemd % specifiers in a literal value.
+func escapeSpecifiers(s string) string {
+	return strings.ReplaceAll(s, "%", "%%")
+}
+
+// quoteArg quotes a command-line or Environment= value for a unit file.
+func quoteArg(s string) string {
+	s = escapeSpecifiers(s)
+	if s != "" && !strings.ContainsAny(s, " \t\"'\\$;") {
+		return s
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `$$`)
+	return `"` + r.Replace(s) + `"`
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
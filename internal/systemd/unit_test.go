@@ -0,0 +1,89 @@
+package systemd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnitName(t *testing.T) {
+	tests := []struct {
+		townRoot string
+		want     string
+	}{
+		{"/home/me/gt", "gastown-gt-daemon.service"},
+		{"/srv/my town!", "gastown-my-town-daemon.service"},
+		{"/", "gastown-town-daemon.service"},
+	}
+	for _, tt := range tests {
+		if got := UnitName(tt.townRoot, "daemon"); got != tt.want {
+			t.Errorf("UnitName(%q) = %q, want %q", tt.townRoot, got, tt.want)
+		}
+	}
+}
+
+func TestDaemonUnit_Render(t *testing.T) {
+	u := DaemonUnit("/home/me/gt", "/usr/local/bin/gt", "/usr/bin:/bin", "gastown-gt-dolt.service")
+	out := u.Render()
+
+	for _, want := range []string{
+		"Type=notify\n",
+		"ExecStart=/usr/local/bin/gt daemon run\n",
+		"WorkingDirectory=/home/me/gt\n",
+		"Environment=PATH=/usr/bin:/bin\n",
+		"WatchdogSec=600\n",
+		"KillMode=process\n",
+		"Wants=gastown-gt-dolt.service\n",
+		"After=gastown-gt-dolt.service\n",
+		"WantedBy=default.target\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("unit missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestDoltUnit_Render(t *testing.T) {
+	u := DoltUnit("/home/me/gt", "/usr/bin/dolt", []string{"sql-server", "--port", "3307"}, "/home/me/gt/.dolt-data", "/home/me/gt/daemon/dolt.log")
+	out := u.Render()
+	for _, want := range []string{
+		"Type=simple\n",
+		"ExecStart=/usr/bin/dolt sql-server --port 3307\n",
+		"StandardOutput=append:/home/me/gt/daemon/dolt.log\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("unit missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "WatchdogSec") {
+		t.Error("dolt unit should not set a watchdog")
+	}
+}
+
+func TestQuoteArg(t *testing.T) {
+	tests := map[string]string{
+		"/usr/bin/gt":      "/usr/bin/gt",
+		"/path with space": `"/path with space"`,
+		"100%":             "100%%",
+		`a"b`:              `"a\"b"`,
+		"$HOME":            `"$$HOME"`,
+	}
+	for in, want := range tests {
+		if got := quoteArg(in); got != want {
+			t.Errorf("quoteArg(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestParseShow(t *testing.T) {
+	out := "LoadState=loaded\nActiveState=active\nSubState=running\nMainPID=4242\nNRestarts=2\nWatchdogUSec=10min\n"
+	s := parseShow("gastown-gt-daemon.service", out)
+	if !s.Active() || s.SubState != "running" || s.MainPID != 4242 || s.Restarts != 2 || s.WatchdogSec != 600 {
+		t.Errorf("parseShow() = %+v", s)
+	}
+	if got := parseUSec("1min 30s"); got != 90 {
+		t.Errorf("parseUSec(1min 30s) = %d", got)
+	}
+	if got := parseUSec("600000000"); got != 600 {
+		t.Errorf("parseUSec(600000000) = %d", got)
+	}
+}
@@ -0,0 +1,212 @@
+package tmux
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// PaneState is what a session's output says the agent is doing.
+type PaneState string
+
+// Pane states, from ClassifyPane.
+const (
+	PaneWorking    PaneState = "working"       // Output shows the runtime busy, or nothing conclusive
+	PaneIdle       PaneState = "idle"          // Sitting at an empty prompt
+	PanePermission PaneState = "permission"    // A permission or trust dialog is waiting
+	PaneInput      PaneState = "waiting_input" // The agent asked a question and is waiting
+	PaneErrorLoop  PaneState = "error_loop"    // The same kind of error, over and over
+	PaneRepeating  PaneState = "repeating"     // Identical output lines repeating
+	PaneEmpty      PaneState = "empty"         // No output to classify
+)
+
+// Blocked reports whether the agent can't continue without someone
+// answering it.
+func (s PaneState) Blocked() bool {
+	return s == PanePermission || s == PaneInput
+}
+
+// Stuck reports whether the state suggests the agent needs intervention.
+func (s PaneState) Stuck() bool {
+	return s.Blocked() || s == PaneErrorLoop || s == PaneRepeating
+}
+
+// Pane classification thresholds.
+const (
+	// PaneClassifyLines is how much scrollback ClassifyPane captures.
+	PaneClassifyLines = 60
+
+	// dialogWindow is how many trailing lines are searched for a dialog,
+	// question or prompt; older output is history.
+	dialogWindow = 12
+
+	// busyWindow is how many trailing lines are searched for a busy marker.
+	busyWindow = 6
+
+	// errorLoopMin is how many copies of one error in the trailing
+	// dialogWindow lines make a loop. Errors that differ only in numbers (line numbers, attempt
+	// counts, timestamps) count as the same error.
+	errorLoopMin = 3
+
+	// repeatMin is how many copies of one line make repeated output.
+	repeatMin = 4
+)
+
+// PaneClassification is the result of classifying a pane.
+type PaneClassification struct {
+	State PaneState `json:"state"`
+
+	// Detail is the output line that decided the state, if any.
+	Detail string `json:"detail,omitempty"`
+}
+
+// PaneClassifier classifies pane output using one runtime's patterns.
+type PaneClassifier struct {
+	busy, idle, permission, input, errors []*regexp.Regexp
+}
+
+// NewPaneClassifier compiles a runtime's pane patterns (RuntimeTmuxConfig.Panes,
+// with provider defaults). A nil runtime config uses the default runtime.
+func NewPaneClassifier(rc *config.RuntimeConfig) (*PaneClassifier, error) {
+	p := rc.PanePatterns()
+	c := &PaneClassifier{}
+	for _, set := range []struct {
+		name     string
+		patterns []string
+		dst      *[]*regexp.Regexp
+	}{
+		{"busy", p.Busy, &c.busy},
+		{"idle", p.Idle, &c.idle},
+		{"permission", p.Permission, &c.permission},
+		{"input", p.Input, &c.input},
+		{"error", p.Error, &c.errors},
+	} {
+		for _, pattern := range set.patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s pane pattern %q: %w", set.name, pattern, err)
+			}
+			*set.dst = append(*set.dst, re)
+		}
+	}
+	return c, nil
+}
+
+// Classify decides what pane output shows. Dialogs win over everything,
+// since an agent waiting on one makes no progress; then repeated output
+// (which can happen while busy); then busy markers; then an error loop at
+// the end of the output, so one the agent recovered from doesn't count;
+// then a question above an idle prompt; then the idle prompt alone.
+func (c *PaneClassifier) Classify(output string) PaneClassification {
+	lines := nonEmptyLines(output)
+	if len(lines) == 0 {
+		return PaneClassification{State: PaneEmpty}
+	}
+	tail := lastN(lines, dialogWindow)
+
+	if line, ok := matchAny(c.permission, tail); ok {
+		return PaneClassification{State: PanePermission, Detail: line}
+	}
+	if line, ok := repeatedLine(lastN(lines, 2*dialogWindow)); ok {
+		return PaneClassification{State: PaneRepeating, Detail: line}
+	}
+	if line, ok := matchAny(c.busy, lastN(lines, busyWindow)); ok {
+		return PaneClassification{State: PaneWorking, Detail: line}
+	}
+	if line, ok := errorLoop(c.errors, tail); ok {
+		return PaneClassification{State: PaneErrorLoop, Detail: line}
+	}
+	if line, ok := matchAny(c.input, tail); ok {
+		return PaneClassification{State: PaneInput, Detail: line}
+	}
+	if line, ok := matchAny(c.idle, lastN(lines, busyWindow)); ok {
+		return PaneClassification{State: PaneIdle, Detail: line}
+	}
+	return PaneClassification{State: PaneWorking}
+}
+
+// ClassifyPane captures a session's recent output and classifies it.
+func (t *Tmux) ClassifyPane(session string, c *PaneClassifier) (PaneClassification, error) {
+	out, err := t.CapturePane(session, PaneClassifyLines)
+	if err != nil {
+		return PaneClassification{}, err
+	}
+	return c.Classify(out), nil
+}
+
+func nonEmptyLines(output string) []string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimRightFunc(line, unicode.IsSpace); strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func lastN(lines []string, n int) []string {
+	if len(lines) > n {
+		return lines[len(lines)-n:]
+	}
+	return lines
+}
+
+// matchAny returns the last line matching any pattern.
+func matchAny(patterns []*regexp.Regexp, lines []string) (string, bool) {
+	for i := len(lines) - 1; i >= 0; i-- {
+		for _, re := range patterns {
+			if re.MatchString(lines[i]) {
+				return strings.TrimSpace(lines[i]), true
+			}
+		}
+	}
+	return "", false
+}
+
+var digitsRe = regexp.MustCompile(`[0-9]+`)
+
+// errorLoop finds an error line that recurs errorLoopMin or more times.
+func errorLoop(patterns []*regexp.Regexp, lines []string) (string, bool) {
+	counts := make(map[string]int)
+	for _, line := range lines {
+		if _, ok := matchAny(patterns, []string{line}); !ok {
+			continue
+		}
+		key := digitsRe.ReplaceAllString(strings.TrimSpace(line), "#")
+		counts[key]++
+		if counts[key] >= errorLoopMin {
+			return strings.TrimSpace(line), true
+		}
+	}
+	return "", false
+}
+
+// repeatedLine finds a line of real text (not borders or spinners) that
+// appears repeatMin or more times.
+func repeatedLine(lines []string) (string, bool) {
+	counts := make(map[string]int)
+	for _, line := range lines {
+		key := strings.TrimSpace(line)
+		if letterCount(key) < 8 {
+			continue
+		}
+		counts[key]++
+		if counts[key] >= repeatMin {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+func letterCount(s string) int {
+	n := 0
+	for _, r := range s {
+		if unicode.IsLetter(r) {
+			n++
+		}
+	}
+	return n
+}
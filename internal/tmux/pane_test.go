@@ -0,0 +1,115 @@
+package tmux
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func TestPaneClassifier_Claude(t *testing.T) {
+	c, err := NewPaneClassifier(config.DefaultRuntimeConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prompt := "────────────────────────\n❯ \n────────────────────────\n  ? for shortcuts\n"
+	tests := []struct {
+		name   string
+		output string
+		want   PaneState
+	}{
+		{"empty", "\n\n", PaneEmpty},
+		{"idle", "⏺ Done. Tests pass.\n\n" + prompt, PaneIdle},
+		{"busy", "⏺ Running tests\n\n✻ Thinking… (12s · esc to interrupt)\n\n" + prompt, PaneWorking},
+		{
+			"permission",
+			"⏺ Bash(rm -rf build)\n Do you want to proceed?\n ❯ 1. Yes\n   2. No, and tell Claude what to do differently (esc)\n",
+			PanePermission,
+		},
+		{
+			"trust dialog",
+			" Do you trust the files in this folder?\n\n ❯ 1. Yes, proceed\n   2. No, exit\n",
+			PanePermission,
+		},
+		{"question", "⏺ The migration is ready. Should I run it against production?\n\n" + prompt, PaneInput},
+		{
+			"error loop",
+			"⎿ API Error: 529 overloaded · Retrying in 2 seconds (attempt 1/10)\n" +
+				"⎿ API Error: 529 overloaded · Retrying in 4 seconds (attempt 2/10)\n" +
+				"⎿ API Error: 529 overloaded · Retrying in 8 seconds (attempt 3/10)\n",
+			PaneErrorLoop,
+		},
+		{
+			"repeating",
+			strings.Repeat("⏺ Let me check the test output again.\n", 4),
+			PaneRepeating,
+		},
+		{
+			"recovered from errors",
+			strings.Repeat("Error: connection refused\n", 3) + diffLines(14) + "✻ Thinking… (3s · esc to interrupt)\n",
+			PaneWorking,
+		},
+		{
+			"errors while busy",
+			"FAIL: TestA\nFAIL: TestB\nFAIL: TestC\n⏺ Fixing the tests\n✻ Thinking… (3s · esc to interrupt)\n",
+			PaneWorking,
+		},
+		{"old question scrolled away", "Should I continue?\n" + diffLines(14) + prompt, PaneIdle},
+		{"inconclusive", "⏺ Reading files\n  src/main.go\n", PaneWorking},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := c.Classify(tt.output)
+			if got.State != tt.want {
+				t.Errorf("Classify = %s (%q), want %s", got.State, got.Detail, tt.want)
+			}
+		})
+	}
+}
+
+// diffLines returns n distinct lines of output.
+func diffLines(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "  %d + changed line %d\n", i, i)
+	}
+	return b.String()
+}
+
+func TestPaneClassifier_RuntimePatterns(t *testing.T) {
+	rc := &config.RuntimeConfig{
+		Command: "codex",
+		Tmux: &config.RuntimeTmuxConfig{
+			Panes: &config.RuntimePanePatterns{Idle: []string{`^READY>$`}},
+		},
+	}
+	c, err := NewPaneClassifier(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Classify("done\nREADY>\n"); got.State != PaneIdle {
+		t.Errorf("custom idle pattern: %s", got.State)
+	}
+	// Provider defaults still apply to the lists left unset.
+	if got := c.Classify("Allow command?\n  git push --force\n"); got.State != PanePermission {
+		t.Errorf("codex permission: %s", got.State)
+	}
+
+	rc.Tmux.Panes.Error = []string{"("}
+	if _, err := NewPaneClassifier(rc); err == nil {
+		t.Error("invalid pattern should fail")
+	}
+}
+
+func TestPaneState_Stuck(t *testing.T) {
+	for state, want := range map[PaneState]bool{
+		PaneWorking: false, PaneIdle: false, PaneEmpty: false,
+		PanePermission: true, PaneInput: true, PaneErrorLoop: true, PaneRepeating: true,
+	} {
+		if got := state.Stuck(); got != want {
+			t.Errorf("%s.Stuck() = %v", state, got)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package tmux
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// startupPromptLines is how many trailing lines are searched for a startup
+// prompt. Dialogs render at the bottom of the pane; older output is history.
+const startupPromptLines = 20
+
+// startupPromptPoll is how often AnswerStartupPrompts checks the pane.
+const startupPromptPoll = 250 * time.Millisecond
+
+// StartupPromptAnswer records one startup prompt answered automatically.
+type StartupPromptAnswer struct {
+	Prompt string   `json:"prompt"`
+	Keys   []string `json:"keys"`
+	Line   string   `json:"line"` // The output line that matched
+}
+
+type compiledStartupPrompt struct {
+	config.StartupPrompt
+	re *regexp.Regexp
+}
+
+// AnswerStartupPrompts watches a freshly started session for the runtime's
+// startup prompts and answers each one it sees with its keys. It returns
+// once settle passes with no prompt on screen, so prompts that appear one
+// after another (a trust dialog, then a permissions warning) are all handled.
+//
+// Each prompt is answered at most once: if the same dialog is still showing
+// after its answer, the keys didn't work, and sending them again could act on
+// whatever screen comes next. Every prompt is validated before anything is
+// sent; an invalid table answers nothing.
+func (t *Tmux) AnswerStartupPrompts(session string, prompts []config.StartupPrompt, settle time.Duration) ([]StartupPromptAnswer, error) {
+	compiled, err := compileStartupPrompts(prompts)
+	if err != nil || len(compiled) == 0 {
+		return nil, err
+	}
+
+	var answers []StartupPromptAnswer
+	answered := make(map[string]bool)
+	deadline := time.Now().Add(settle)
+	for time.Now().Before(deadline) {
+		content, err := t.CapturePane(session, startupPromptLines)
+		if err != nil {
+			return answers, err
+		}
+		p, line := matchStartupPrompt(compiled, answered, content)
+		if p == nil {
+			time.Sleep(startupPromptPoll)
+			continue
+		}
+
+		for i, key := range p.Keys {
+			if i > 0 {
+				time.Sleep(200 * time.Millisecond) // Let the selection update
+			}
+			if _, err := t.run("send-keys", "-t", session, key); err != nil {
+				return answers, err
+			}
+		}
+		answered[p.Name] = true
+		answers = append(answers, StartupPromptAnswer{Prompt: p.Name, Keys: p.Keys, Line: line})
+		deadline = time.Now().Add(settle)
+	}
+	return answers, nil
+}
+
+func compileStartupPrompts(prompts []config.StartupPrompt) ([]compiledStartupPrompt, error) {
+	compiled := make([]compiledStartupPrompt, 0, len(prompts))
+	for _, p := range prompts {
+		if err := p.Validate(); err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, compiledStartupPrompt{StartupPrompt: p, re: regexp.MustCompile(p.Pattern)})
+	}
+	return compiled, nil
+}
+
+// matchStartupPrompt returns the first prompt not yet answered that matches
+// a line of the pane's trailing output, and the line it matched.
+func matchStartupPrompt(prompts []compiledStartupPrompt, answered map[string]bool, content string) (*compiledStartupPrompt, string) {
+	lines := lastN(nonEmptyLines(content), startupPromptLines)
+	for i := range prompts {
+		if answered[prompts[i].Name] {
+			continue
+		}
+		if line, ok := matchAny([]*regexp.Regexp{prompts[i].re}, lines); ok {
+			return &prompts[i], line
+		}
+	}
+	return nil, ""
+}
@@ -0,0 +1,54 @@
+package tmux
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func TestMatchStartupPrompt(t *testing.T) {
+	prompts, err := compileStartupPrompts(config.GetAgentPresetByName("claude").StartupPrompts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	answered := map[string]bool{}
+
+	trust := " Do you trust the files in this folder?\n\n ❯ 1. Yes, proceed\n   2. No, exit\n"
+	p, line := matchStartupPrompt(prompts, answered, trust)
+	if p == nil || p.Name != "trust-folder" || line != "Do you trust the files in this folder?" {
+		t.Fatalf("trust dialog matched %v, %q", p, line)
+	}
+	answered[p.Name] = true
+
+	// Still on screen after being answered: never answered twice.
+	if p, _ := matchStartupPrompt(prompts, answered, trust); p != nil {
+		t.Errorf("answered prompt matched again: %s", p.Name)
+	}
+
+	bypass := "WARNING: Claude Code running in Bypass Permissions mode\n ❯ 1. No, exit\n   2. Yes, I accept\n"
+	if p, _ := matchStartupPrompt(prompts, answered, bypass); p == nil || strings.Join(p.Keys, ",") != "Down,Enter" {
+		t.Errorf("bypass warning matched %v", p)
+	}
+
+	// Prompt text that has scrolled out of the trailing window is history.
+	old := bypass + strings.Repeat("⏺ working\n", startupPromptLines)
+	if p, _ := matchStartupPrompt(prompts, map[string]bool{}, old); p != nil {
+		t.Errorf("scrolled-away prompt matched: %s", p.Name)
+	}
+}
+
+func TestCompileStartupPrompts_RejectsUnsafe(t *testing.T) {
+	tests := []config.StartupPrompt{
+		{Name: "shell", Pattern: "trust", Keys: []string{"rm -rf ~", "Enter"}},
+		{Name: "no-keys", Pattern: "trust"},
+		{Name: "bad-pattern", Pattern: "(", Keys: []string{"Enter"}},
+		{Pattern: "trust", Keys: []string{"Enter"}},
+	}
+	for _, p := range tests {
+		valid := config.StartupPrompt{Name: "ok", Pattern: "ok", Keys: []string{"Enter"}}
+		if _, err := compileStartupPrompts([]config.StartupPrompt{valid, p}); err == nil {
+			t.Errorf("%+v: expected error", p)
+		}
+	}
+}
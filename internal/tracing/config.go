@@ -0,0 +1,113 @@
+package tracing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Exporter names accepted in settings/tracing.json.
+const (
+	ExportFile = "file" // Append spans to logs/traces.jsonl (the default)
+	ExportOTLP = "otlp" // POST spans to an OTLP/HTTP collector
+	ExportBoth = "both" // Both of the above
+	ExportNone = "none" // Disable tracing
+)
+
+// EnvEndpoint is the standard OpenTelemetry variable for the collector
+// endpoint; it is used when the config doesn't name one.
+const EnvEndpoint = "OTEL_EXPORTER_OTLP_ENDPOINT"
+
+// ConfigPath returns the tracing config path for a town.
+func ConfigPath(townRoot string) string {
+	return filepath.Join(townRoot, "settings", "tracing.json")
+}
+
+// FilePath returns the file exporter's output path for a town.
+func FilePath(townRoot string) string {
+	return filepath.Join(townRoot, "logs", "traces.jsonl")
+}
+
+// Config is settings/tracing.json.
+type Config struct {
+	Type    string `json:"type"`    // "tracing"
+	Version int    `json:"version"` // schema version
+
+	// Exporter is "file", "otlp", "both" or "none" (default "file").
+	Exporter string `json:"exporter,omitempty"`
+
+	// Endpoint is the OTLP/HTTP collector base URL, e.g.
+	// "http://localhost:4318". Spans are POSTed to <endpoint>/v1/traces.
+	// Defaults to $OTEL_EXPORTER_OTLP_ENDPOINT.
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// Headers are added to OTLP requests (e.g. an API key).
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// ServiceName is the service.name resource attribute (default "gastown").
+	ServiceName string `json:"service_name,omitempty"`
+}
+
+// LoadConfig reads settings/tracing.json. A missing file disables tracing
+// and returns nil.
+func LoadConfig(townRoot string) (*Config, error) {
+	data, err := os.ReadFile(ConfigPath(townRoot)) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading tracing config: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing tracing config: %w", err)
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = os.Getenv(EnvEndpoint)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Validate checks the exporter and endpoint.
+func (c *Config) Validate() error {
+	switch c.exporter() {
+	case ExportFile, ExportNone:
+	case ExportOTLP, ExportBoth:
+		if c.Endpoint == "" {
+			return fmt.Errorf("tracing: exporter %q needs an endpoint (or $%s)", c.Exporter, EnvEndpoint)
+		}
+	default:
+		return fmt.Errorf("tracing: exporter must be file, otlp, both or none, got %q", c.Exporter)
+	}
+	return nil
+}
+
+func (c *Config) exporter() string {
+	if c.Exporter == "" {
+		return ExportFile
+	}
+	return c.Exporter
+}
+
+// UsesFile reports whether spans are written to the local file.
+func (c *Config) UsesFile() bool {
+	e := c.exporter()
+	return e == ExportFile || e == ExportBoth
+}
+
+// UsesOTLP reports whether spans are sent to a collector.
+func (c *Config) UsesOTLP() bool {
+	e := c.exporter()
+	return e == ExportOTLP || e == ExportBoth
+}
+
+func (c *Config) serviceName() string {
+	if c.ServiceName == "" {
+		return "gastown"
+	}
+	return c.ServiceName
+}
@@ -0,0 +1,252 @@
+package tracing
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// otlpTimeout bounds each collector request and how long Flush waits.
+const otlpTimeout = 5 * time.Second
+
+// FileExporter appends spans as JSON lines, one span per line.
+type FileExporter struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileExporter creates an exporter writing to path.
+func NewFileExporter(path string) *FileExporter {
+	return &FileExporter{path: path}
+}
+
+// Export appends spans to the file.
+func (e *FileExporter) Export(spans []*Span) error {
+	var buf bytes.Buffer
+	for _, s := range spans {
+		data, err := json.Marshal(s)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(e.path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(e.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644) //nolint:gosec // G302: trace log is non-sensitive
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(buf.Bytes())
+	return err
+}
+
+// ReadSpans returns the spans in a file exporter's output for traceID
+// (all spans if empty), ordered by start time. A missing file has none.
+func ReadSpans(path, traceID string) ([]Span, error) {
+	f, err := os.Open(path) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var spans []Span
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if traceID != "" && !bytes.Contains(line, []byte(traceID)) {
+			continue
+		}
+		var s Span
+		if err := json.Unmarshal(line, &s); err != nil {
+			continue // Skip torn or foreign lines
+		}
+		if traceID == "" || s.TraceID == traceID {
+			spans = append(spans, s)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].Start.Before(spans[j].Start) })
+	return spans, nil
+}
+
+// OTLPExporter sends spans to an OpenTelemetry collector using the
+// OTLP/HTTP JSON encoding. Requests are made in the background; Flush
+// waits for them.
+type OTLPExporter struct {
+	url     string
+	headers map[string]string
+	service string
+	client  *http.Client
+	wg      sync.WaitGroup
+}
+
+// NewOTLPExporter creates an exporter for a collector base URL such as
+// "http://localhost:4318".
+func NewOTLPExporter(endpoint string, headers map[string]string, service string) *OTLPExporter {
+	url := strings.TrimRight(endpoint, "/")
+	if !strings.HasSuffix(url, "/v1/traces") {
+		url += "/v1/traces"
+	}
+	return &OTLPExporter{
+		url:     url,
+		headers: headers,
+		service: service,
+		client:  &http.Client{Timeout: otlpTimeout},
+	}
+}
+
+// Export posts spans to the collector asynchronously.
+func (e *OTLPExporter) Export(spans []*Span) error {
+	body, err := json.Marshal(e.request(spans))
+	if err != nil {
+		return err
+	}
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		_ = e.post(body)
+	}()
+	return nil
+}
+
+func (e *OTLPExporter) post(body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), otlpTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("collector returned %s", resp.Status)
+	}
+	return nil
+}
+
+// Flush waits for in-flight requests, up to the request timeout.
+func (e *OTLPExporter) Flush() {
+	done := make(chan struct{})
+	go func() {
+		e.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(otlpTimeout):
+	}
+}
+
+// OTLP/HTTP JSON wire types. IDs are hex strings and times are decimal
+// nanosecond strings, per the OTLP JSON mapping.
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpAttr `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope struct {
+			Name string `json:"name"`
+		} `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpSpan struct {
+		TraceID           string     `json:"traceId"`
+		SpanID            string     `json:"spanId"`
+		ParentSpanID      string     `json:"parentSpanId,omitempty"`
+		Name              string     `json:"name"`
+		Kind              int        `json:"kind"`
+		StartTimeUnixNano string     `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+		Attributes        []otlpAttr `json:"attributes,omitempty"`
+		Status            otlpStatus `json:"status"`
+	}
+	otlpAttr struct {
+		Key   string `json:"key"`
+		Value struct {
+			StringValue string `json:"stringValue"`
+		} `json:"value"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code"` // 1 = OK, 2 = ERROR
+		Message string `json:"message,omitempty"`
+	}
+)
+
+func newAttr(key, value string) otlpAttr {
+	a := otlpAttr{Key: key}
+	a.Value.StringValue = value
+	return a
+}
+
+func (e *OTLPExporter) request(spans []*Span) otlpRequest {
+	scope := otlpScopeSpans{}
+	scope.Scope.Name = "github.com/steveyegge/gastown/internal/tracing"
+	for _, s := range spans {
+		out := otlpSpan{
+			TraceID:           s.TraceID,
+			SpanID:            s.SpanID,
+			ParentSpanID:      s.ParentID,
+			Name:              s.Name,
+			Kind:              1, // SPAN_KIND_INTERNAL
+			StartTimeUnixNano: strconv.FormatInt(s.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.Finish.UnixNano(), 10),
+			Attributes:        []otlpAttr{newAttr("gastown.bead", s.Bead)},
+			Status:            otlpStatus{Code: 1},
+		}
+		keys := make([]string, 0, len(s.Attrs))
+		for k := range s.Attrs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			out.Attributes = append(out.Attributes, newAttr("gastown."+k, s.Attrs[k]))
+		}
+		if s.Status == StatusError {
+			out.Status = otlpStatus{Code: 2, Message: s.Error}
+		}
+		scope.Spans = append(scope.Spans, out)
+	}
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource: otlpResource{Attributes: []otlpAttr{
+			newAttr("service.name", e.service),
+			newAttr("process.pid", strconv.Itoa(os.Getpid())),
+		}},
+		ScopeSpans: []otlpScopeSpans{scope},
+	}}}
+}
@@ -0,0 +1,347 @@
+// Package tracing records spans for the bead lifecycle - sling, polecat
+// spawn, molecule steps, gt done, merge queue submission, refinery merges
+// and convoy close - so slow work can be broken down by phase.
+//
+// Every span belongs to the trace of a bead. The trace ID is derived from
+// the bead ID (see TraceID), so the separate processes that touch a bead
+// agree on it without coordination; gt sling also records it on the bead
+// as trace_id for external lookups. Within a process, a span started for a
+// bead nests under the innermost open span of the same trace.
+//
+// Spans are exported to logs/traces.jsonl (read by gt trace), to an OTLP/HTTP
+// collector, or both, as configured in settings/tracing.json. Without that
+// file tracing is off and every call here is a cheap no-op.
+package tracing
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Status values for finished spans.
+const (
+	StatusOK    = "ok"
+	StatusError = "error"
+)
+
+// Span is one timed phase of a bead's lifecycle.
+type Span struct {
+	TraceID  string            `json:"trace_id"`
+	SpanID   string            `json:"span_id"`
+	ParentID string            `json:"parent_id,omitempty"`
+	Name     string            `json:"name"`
+	Bead     string            `json:"bead"`
+	Start    time.Time         `json:"start"`
+	Finish   time.Time         `json:"end"`
+	Status   string            `json:"status"`
+	Error    string            `json:"error,omitempty"`
+	Attrs    map[string]string `json:"attrs,omitempty"`
+
+	tracer *Tracer
+	ended  bool
+}
+
+// Duration returns the span's length.
+func (s *Span) Duration() time.Duration {
+	return s.Finish.Sub(s.Start)
+}
+
+// Exporter receives finished spans.
+type Exporter interface {
+	Export(spans []*Span) error
+}
+
+// Tracer creates spans and hands finished ones to its exporters.
+type Tracer struct {
+	mu        sync.Mutex
+	exporters []Exporter
+	open      map[string][]*Span // Open spans per trace, innermost last
+	attrs     map[string]string  // Added to every span
+
+	// Now returns the current time; defaults to time.Now.
+	Now func() time.Time
+}
+
+// NewTracer creates a tracer that exports to the given exporters.
+func NewTracer(exporters ...Exporter) *Tracer {
+	attrs := make(map[string]string)
+	if actor := os.Getenv("BD_ACTOR"); actor != "" {
+		attrs["actor"] = actor
+	}
+	if len(os.Args) > 1 {
+		attrs["command"] = "gt " + os.Args[1]
+	}
+	return &Tracer{
+		exporters: exporters,
+		open:      make(map[string][]*Span),
+		attrs:     attrs,
+		Now:       time.Now,
+	}
+}
+
+// TraceID returns the trace ID for a bead: the first 16 bytes of
+// SHA-256("gastown:" + beadID), hex encoded as OTLP expects.
+func TraceID(beadID string) string {
+	sum := sha256.Sum256([]byte("gastown:" + beadID))
+	return hex.EncodeToString(sum[:16])
+}
+
+func newSpanID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Start opens a span. bead may be empty and supplied later with SetBead
+// once the caller knows it; spans that never get a bead are dropped.
+func (t *Tracer) Start(name, bead string) *Span {
+	s := &Span{
+		SpanID: newSpanID(),
+		Name:   name,
+		Start:  t.Now(),
+		Attrs:  make(map[string]string, len(t.attrs)),
+		tracer: t,
+	}
+	for k, v := range t.attrs {
+		s.Attrs[k] = v
+	}
+	s.SetBead(bead)
+	return s
+}
+
+// Record exports a span whose times are already known, such as a convoy's
+// lifetime at close.
+func (t *Tracer) Record(name, bead string, start, end time.Time, attrs map[string]string) {
+	if bead == "" {
+		return
+	}
+	s := &Span{
+		TraceID: TraceID(bead),
+		SpanID:  newSpanID(),
+		Name:    name,
+		Bead:    bead,
+		Start:   start,
+		Finish:  end,
+		Status:  StatusOK,
+		Attrs:   make(map[string]string, len(t.attrs)+len(attrs)),
+		tracer:  t,
+		ended:   true,
+	}
+	for k, v := range t.attrs {
+		s.Attrs[k] = v
+	}
+	for k, v := range attrs {
+		s.Attrs[k] = v
+	}
+	t.export(s)
+}
+
+// SetBead binds the span to a bead's trace if it isn't bound yet.
+func (s *Span) SetBead(bead string) {
+	if s == nil || bead == "" || s.TraceID != "" {
+		return
+	}
+	t := s.tracer
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s.Bead = bead
+	s.TraceID = TraceID(bead)
+	if stack := t.open[s.TraceID]; len(stack) > 0 {
+		s.ParentID = stack[len(stack)-1].SpanID
+	}
+	t.open[s.TraceID] = append(t.open[s.TraceID], s)
+}
+
+// SetAttr sets a string attribute on the span.
+func (s *Span) SetAttr(key, value string) {
+	if s == nil || value == "" {
+		return
+	}
+	s.tracer.mu.Lock()
+	s.Attrs[key] = value
+	s.tracer.mu.Unlock()
+}
+
+// End finishes the span, marking it failed if err is non-nil. Only the
+// first call has any effect, so End can be both deferred and called early.
+func (s *Span) End(err error) {
+	if s == nil {
+		return
+	}
+	t := s.tracer
+	t.mu.Lock()
+	if s.ended {
+		t.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.Finish = t.Now()
+	s.Status = StatusOK
+	if err != nil {
+		s.Status = StatusError
+		s.Error = err.Error()
+	}
+	if s.TraceID != "" {
+		stack := t.open[s.TraceID]
+		for i := len(stack) - 1; i >= 0; i-- {
+			if stack[i] == s {
+				stack = append(stack[:i], stack[i+1:]...)
+				break
+			}
+		}
+		if len(stack) == 0 {
+			delete(t.open, s.TraceID)
+		} else {
+			t.open[s.TraceID] = stack
+		}
+	}
+	t.mu.Unlock()
+
+	if s.TraceID != "" {
+		t.export(s)
+	}
+}
+
+func (t *Tracer) export(s *Span) {
+	for _, e := range t.exporters {
+		_ = e.Export([]*Span{s})
+	}
+}
+
+// Flush waits for asynchronous exporters to finish.
+func (t *Tracer) Flush() {
+	for _, e := range t.exporters {
+		if f, ok := e.(interface{ Flush() }); ok {
+			f.Flush()
+		}
+	}
+}
+
+// Process-wide tracer, configured from the town on first use.
+var (
+	globalMu   sync.Mutex
+	globalInit bool
+	global     *Tracer
+)
+
+// Init configures the process-wide tracer from a town's settings. It is
+// called lazily from the working directory otherwise; long-running
+// processes outside the town tree should call it explicitly.
+func Init(townRoot string) error {
+	globalMu.Lock()
+	defer globalMu.Unlock()
+	globalInit = true
+	global = nil
+
+	cfg, err := LoadConfig(townRoot)
+	if err != nil || cfg == nil {
+		return err
+	}
+	var exporters []Exporter
+	if cfg.UsesFile() {
+		exporters = append(exporters, NewFileExporter(FilePath(townRoot)))
+	}
+	if cfg.UsesOTLP() {
+		exporters = append(exporters, NewOTLPExporter(cfg.Endpoint, cfg.Headers, cfg.serviceName()))
+	}
+	if len(exporters) > 0 {
+		global = NewTracer(exporters...)
+	}
+	return nil
+}
+
+func defaultTracer() *Tracer {
+	globalMu.Lock()
+	ready := globalInit
+	globalMu.Unlock()
+	if !ready {
+		townRoot, err := workspace.FindFromCwd()
+		if err != nil || townRoot == "" {
+			globalMu.Lock()
+			globalInit = true
+			globalMu.Unlock()
+			return nil
+		}
+		_ = Init(townRoot)
+	}
+	globalMu.Lock()
+	defer globalMu.Unlock()
+	return global
+}
+
+// Enabled reports whether the process-wide tracer exports anywhere.
+func Enabled() bool {
+	return defaultTracer() != nil
+}
+
+// Start opens a span on the process-wide tracer. It returns nil when
+// tracing is disabled; all Span methods accept a nil receiver.
+func Start(name, bead string) *Span {
+	t := defaultTracer()
+	if t == nil {
+		return nil
+	}
+	return t.Start(name, bead)
+}
+
+// Record exports a span with known times on the process-wide tracer.
+func Record(name, bead string, start, end time.Time, attrs map[string]string) {
+	if t := defaultTracer(); t != nil {
+		t.Record(name, bead, start, end, attrs)
+	}
+}
+
+// Flush waits for pending exports on the process-wide tracer. Call it
+// before the process exits.
+func Flush() {
+	globalMu.Lock()
+	t := global
+	globalMu.Unlock()
+	if t != nil {
+		t.Flush()
+	}
+}
+
+// Row is a span placed in a waterfall: Depth is its nesting level.
+type Row struct {
+	Span  Span
+	Depth int
+}
+
+// Waterfall orders a trace's spans depth-first, children under their
+// parents by start time. Spans whose parent isn't in the set (roots, and
+// phases recorded by other processes) are top level.
+func Waterfall(spans []Span) []Row {
+	byID := make(map[string]bool, len(spans))
+	for _, s := range spans {
+		byID[s.SpanID] = true
+	}
+	children := make(map[string][]Span)
+	var roots []Span
+	for _, s := range spans {
+		if s.ParentID != "" && byID[s.ParentID] {
+			children[s.ParentID] = append(children[s.ParentID], s)
+		} else {
+			roots = append(roots, s)
+		}
+	}
+
+	var rows []Row
+	var walk func(list []Span, depth int)
+	walk = func(list []Span, depth int) {
+		sort.SliceStable(list, func(i, j int) bool { return list[i].Start.Before(list[j].Start) })
+		for _, s := range list {
+			rows = append(rows, Row{Span: s, Depth: depth})
+			walk(children[s.SpanID], depth+1)
+		}
+	}
+	walk(roots, 0)
+	return rows
+}
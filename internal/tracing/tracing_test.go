@@ -0,0 +1,200 @@
+package tracing
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memExporter struct {
+	mu    sync.Mutex
+	spans []Span
+}
+
+func (m *memExporter) Export(spans []*Span) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, s := range spans {
+		m.spans = append(m.spans, *s)
+	}
+	return nil
+}
+
+func TestTraceID_StableAndDistinct(t *testing.T) {
+	a, b := TraceID("gt-abc"), TraceID("gt-abc")
+	if a != b || len(a) != 32 {
+		t.Errorf("TraceID not stable or wrong length: %q %q", a, b)
+	}
+	if TraceID("gt-xyz") == a {
+		t.Error("different beads share a trace ID")
+	}
+}
+
+func TestTracer_NestingAndLateBinding(t *testing.T) {
+	mem := &memExporter{}
+	tr := NewTracer(mem)
+
+	sling := tr.Start("sling", "")
+	spawn := tr.Start("polecat.spawn", "gt-1") // Opened before sling knows its bead
+	spawn.End(nil)
+	sling.SetBead("gt-1")
+	start := tr.Start("session.start", "gt-1")
+	start.End(errors.New("tmux died"))
+	sling.End(nil)
+	sling.End(errors.New("ignored")) // Second End is a no-op
+
+	orphan := tr.Start("no-bead", "")
+	orphan.End(nil)
+
+	if len(mem.spans) != 3 {
+		t.Fatalf("exported %d spans, want 3: %+v", len(mem.spans), mem.spans)
+	}
+	byName := make(map[string]Span)
+	for _, s := range mem.spans {
+		byName[s.Name] = s
+	}
+	if byName["polecat.spawn"].ParentID != "" {
+		t.Error("span started before parent was bound should be a root")
+	}
+	if byName["session.start"].ParentID != byName["sling"].SpanID {
+		t.Errorf("session.start parent = %q, want sling", byName["session.start"].ParentID)
+	}
+	if s := byName["session.start"]; s.Status != StatusError || s.Error != "tmux died" {
+		t.Errorf("session.start status = %s %q", s.Status, s.Error)
+	}
+	if s := byName["sling"]; s.Status != StatusOK || s.TraceID != TraceID("gt-1") {
+		t.Errorf("sling = %+v", s)
+	}
+	if len(tr.open) != 0 {
+		t.Errorf("open spans left: %v", tr.open)
+	}
+}
+
+func TestNilSpanIsNoop(t *testing.T) {
+	var s *Span
+	s.SetBead("x")
+	s.SetAttr("k", "v")
+	s.End(nil)
+}
+
+func TestFileExporter_ReadSpansAndWaterfall(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "traces.jsonl")
+	tr := NewTracer(NewFileExporter(path))
+	base := time.Unix(1_700_000_000, 0)
+	now := base
+	tr.Now = func() time.Time { now = now.Add(time.Second); return now }
+
+	done := tr.Start("gt.done", "gt-7")
+	mq := tr.Start("mq.submit", "gt-7")
+	mq.End(nil)
+	done.End(nil)
+	tr.Record("convoy.close", "gt-7", base, base.Add(time.Minute), map[string]string{"convoy": "hq-cv1"})
+	other := tr.Start("gt.done", "gt-8")
+	other.End(nil)
+
+	spans, err := ReadSpans(path, TraceID("gt-7"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spans) != 3 {
+		t.Fatalf("ReadSpans = %d spans, want 3", len(spans))
+	}
+	rows := Waterfall(spans)
+	var got []string
+	for _, r := range rows {
+		got = append(got, strings.Repeat(" ", r.Depth)+r.Span.Name)
+	}
+	want := "convoy.close|gt.done| mq.submit"
+	if strings.Join(got, "|") != want {
+		t.Errorf("waterfall = %q, want %q", strings.Join(got, "|"), want)
+	}
+	if rows[0].Span.Attrs["convoy"] != "hq-cv1" {
+		t.Errorf("attrs = %v", rows[0].Span.Attrs)
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var mu sync.Mutex
+	var got otlpRequest
+	var path, auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		path, auth = r.URL.Path, r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+
+	exp := NewOTLPExporter(srv.URL+"/", map[string]string{"Authorization": "Bearer k"}, "town")
+	tr := NewTracer(exp)
+	s := tr.Start("refinery.merge", "gt-9")
+	s.SetAttr("branch", "polecat/nux")
+	s.End(errors.New("conflict"))
+	tr.Flush()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if path != "/v1/traces" || auth != "Bearer k" {
+		t.Errorf("path = %q, auth = %q", path, auth)
+	}
+	if len(got.ResourceSpans) != 1 || len(got.ResourceSpans[0].ScopeSpans[0].Spans) != 1 {
+		t.Fatalf("request = %+v", got)
+	}
+	span := got.ResourceSpans[0].ScopeSpans[0].Spans[0]
+	if span.TraceID != TraceID("gt-9") || span.Status.Code != 2 || span.Status.Message != "conflict" {
+		t.Errorf("span = %+v", span)
+	}
+	var branch bool
+	for _, a := range span.Attributes {
+		if a.Key == "gastown.branch" && a.Value.StringValue == "polecat/nux" {
+			branch = true
+		}
+	}
+	if !branch {
+		t.Errorf("attributes = %+v", span.Attributes)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	townRoot := t.TempDir()
+	cfg, err := LoadConfig(townRoot)
+	if cfg != nil || err != nil {
+		t.Fatalf("missing config = %+v, %v", cfg, err)
+	}
+	if err := os.MkdirAll(filepath.Join(townRoot, "settings"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(s string) {
+		if err := os.WriteFile(ConfigPath(townRoot), []byte(s), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(`{"type":"tracing","version":1}`)
+	cfg, err = LoadConfig(townRoot)
+	if err != nil || !cfg.UsesFile() || cfg.UsesOTLP() {
+		t.Errorf("default config = %+v, %v", cfg, err)
+	}
+
+	t.Setenv(EnvEndpoint, "")
+	write(`{"exporter":"otlp"}`)
+	if _, err := LoadConfig(townRoot); err == nil {
+		t.Error("otlp without endpoint should be rejected")
+	}
+	t.Setenv(EnvEndpoint, "http://collector:4318")
+	cfg, err = LoadConfig(townRoot)
+	if err != nil || cfg.Endpoint != "http://collector:4318" {
+		t.Errorf("env endpoint: %+v, %v", cfg, err)
+	}
+	write(`{"exporter":"zipkin"}`)
+	if _, err := LoadConfig(townRoot); err == nil {
+		t.Error("unknown exporter should be rejected")
+	}
+}
@@ -478,6 +478,13 @@ func buildEventMessage(eventType string, payload map[string]interface{}) string
 		}
 		return "merge failed"
 
+	case "convoy_closed":
+		title := getPayloadString(payload, "title")
+		if title != "" {
+			return fmt.Sprintf("convoy landed: %s", title)
+		}
+		return "convoy landed"
+
 	default:
 		if msg := getPayloadString(payload, "message"); msg != "" {
 			return msg
@@ -144,6 +144,7 @@ var (
 		"merged":        "✓",
 		"merge_failed":  "✗",
 		"merge_skipped": "⊘",
+		"convoy_closed": "🚚",
 		// General gt events
 		"sling":   "🎯",
 		"hook":    "🪝",
@@ -156,5 +157,8 @@ var (
 		"nudge":   "⚡",
 		"boot":    "🔌",
 		"halt":    "⏹",
+		// Daemon events
+		"config_reloaded": "↻",
+		"config_rejected": "⚠",
 	}
 )
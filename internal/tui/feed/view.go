@@ -253,9 +253,9 @@ func (m *Model) renderEvent(e Event) string {
 		symbolStyle = EventCreateStyle
 	case "update":
 		symbolStyle = EventUpdateStyle
-	case "complete", "patrol_complete", "merged", "done":
+	case "complete", "patrol_complete", "merged", "done", "convoy_closed":
 		symbolStyle = EventCompleteStyle
-	case "fail", "merge_failed":
+	case "fail", "merge_failed", "config_rejected":
 		symbolStyle = EventFailStyle
 	case "delete":
 		symbolStyle = EventDeleteStyle
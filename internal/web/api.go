@@ -4,13 +4,21 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/tmux"
+	"github.com/steveyegge/gastown/internal/workspace"
 )
 
 const (
@@ -49,6 +57,9 @@ type APIHandler struct {
 	gtPath string
 	// workDir is the working directory for command execution.
 	workDir string
+	// store returns the store for issues shown and created from the
+	// dashboard, bounding each bd call by timeout.
+	store func(timeout time.Duration) beads.Store
 	// Options cache
 	optionsCache     *OptionsResponse
 	optionsCacheTime time.Time
@@ -65,6 +76,11 @@ func NewAPIHandler() *APIHandler {
 	return &APIHandler{
 		gtPath:  "gt",
 		workDir: workDir,
+		store: func(timeout time.Duration) beads.Store {
+			b := beads.New(workDir)
+			b.SetTimeout(timeout)
+			return b
+		},
 	}
 }
 
@@ -94,6 +110,8 @@ func (h *APIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		h.handleMailRead(w, r)
 	case path == "/mail/send" && r.Method == http.MethodPost:
 		h.handleMailSend(w, r)
+	case path == "/mail/search" && r.Method == http.MethodGet:
+		h.handleMailSearch(w, r)
 	case path == "/issues/show" && r.Method == http.MethodGet:
 		h.handleIssueShow(w, r)
 	case path == "/issues/create" && r.Method == http.MethodPost:
@@ -318,6 +336,49 @@ func (h *APIHandler) handleMailRead(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(msg)
 }
 
+// MailSearchResponse is the response for /api/mail/search.
+type MailSearchResponse struct {
+	Query    string        `json:"query"`
+	Messages []MailMessage `json:"messages"`
+	Total    int           `json:"total"`
+}
+
+// handleMailSearch searches the user's mail with the gt mail search query
+// syntax, e.g. /api/mail/search?q=from:witness+is:unread.
+func (h *APIHandler) handleMailSearch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	args := []string{"mail", "search", "--json"}
+	if r.URL.Query().Get("reindex") == "true" {
+		args = append(args, "--reindex")
+	}
+	args = append(args, "--", query) // Queries may start with "-"
+
+	output, err := h.runGtCommand(r.Context(), 15*time.Second, args)
+	if err != nil {
+		if msg := strings.TrimSpace(output); msg != "" {
+			err = fmt.Errorf("%s", msg)
+		}
+		h.sendError(w, "Search failed: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var messages []MailMessage
+	if err := json.Unmarshal([]byte(output), &messages); err != nil {
+		h.sendError(w, "Failed to parse search results: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if messages == nil {
+		messages = []MailMessage{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(MailSearchResponse{
+		Query:    query,
+		Messages: messages,
+		Total:    len(messages),
+	})
+}
+
 // MailSendRequest is the request body for /api/mail/send.
 type MailSendRequest struct {
 	To      string `json:"to"`
@@ -766,7 +827,6 @@ type IssueShowResponse struct {
 	Updated     string   `json:"updated,omitempty"`
 	DependsOn   []string `json:"depends_on,omitempty"`
 	Blocks      []string `json:"blocks,omitempty"`
-	RawOutput   string   `json:"raw_output"`
 }
 
 // handleIssueShow returns details for a specific issue/bead.
@@ -777,15 +837,17 @@ func (h *APIHandler) handleIssueShow(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Run bd show to get issue details
-	output, err := h.runBdCommand(r.Context(), 10*time.Second, []string{"show", issueID})
+	issue, err := h.store(10 * time.Second).Show(issueID)
+	if errors.Is(err, beads.ErrNotFound) {
+		h.sendError(w, "Issue not found: "+issueID, http.StatusNotFound)
+		return
+	}
 	if err != nil {
 		h.sendError(w, "Failed to fetch issue: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// Parse the output
-	resp := parseIssueShowOutput(output, issueID)
+	resp := issueShowResponse(issue)
 
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(resp)
@@ -806,7 +868,7 @@ type IssueCreateResponse struct {
 	Error   string `json:"error,omitempty"`
 }
 
-// handleIssueCreate creates a new issue via bd create.
+// handleIssueCreate creates a new issue.
 func (h *APIHandler) handleIssueCreate(w http.ResponseWriter, r *http.Request) {
 	var req IssueCreateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -831,184 +893,54 @@ func (h *APIHandler) handleIssueCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Build bd create command
-	args := []string{"create", req.Title}
-
-	// Add priority if specified (default is P2)
+	// Priority defaults to P2 when not in 1-4
+	priority := -1
 	if req.Priority >= 1 && req.Priority <= 4 {
-		args = append(args, fmt.Sprintf("--priority=%d", req.Priority))
-	}
-
-	// Add description if provided
-	if req.Description != "" {
-		args = append(args, "--body", req.Description)
+		priority = req.Priority
 	}
 
-	// Run bd create
-	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
-	defer cancel()
-
-	output, err := h.runBdCommand(ctx, 12*time.Second, args)
-
 	resp := IssueCreateResponse{}
+	issue, err := h.store(12 * time.Second).Create(beads.CreateOptions{
+		Title:       req.Title,
+		Priority:    priority,
+		Description: req.Description,
+	})
 	if err != nil {
-		resp.Success = false
 		resp.Error = "Failed to create issue: " + err.Error()
-		if output != "" {
-			resp.Message = output
-		}
 	} else {
 		resp.Success = true
-		resp.Message = output
-
-		// Try to extract issue ID from output (e.g., "Created issue: abc123")
-		if strings.Contains(output, "Created") {
-			parts := strings.Fields(output)
-			for i, p := range parts {
-				if strings.HasSuffix(p, ":") && i+1 < len(parts) {
-					resp.ID = strings.TrimSpace(parts[i+1])
-					break
-				}
-			}
-		}
+		resp.ID = issue.ID
+		resp.Message = "Created issue: " + issue.ID
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(resp)
 }
 
-// runBdCommand executes a bd command with the given args.
-func (h *APIHandler) runBdCommand(ctx context.Context, timeout time.Duration, args []string) (string, error) {
-	ctx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
-
-	cmd := exec.CommandContext(ctx, "bd", args...)
-	if h.workDir != "" {
-		cmd.Dir = h.workDir
-	}
-	cmd.Stdin = nil
-
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	err := cmd.Run()
-
-	output := stdout.String()
-	if stderr.Len() > 0 {
-		if output != "" {
-			output += "\n"
-		}
-		output += stderr.String()
-	}
-
-	if ctx.Err() == context.DeadlineExceeded {
-		return output, fmt.Errorf("command timed out after %v", timeout)
-	}
-
-	if err != nil {
-		return output, fmt.Errorf("command failed: %v", err)
-	}
-
-	return output, nil
-}
-
-// parseIssueShowOutput parses the output from "bd show <id>".
-func parseIssueShowOutput(output string, issueID string) IssueShowResponse {
+// issueShowResponse converts an issue to its /api/issues/show form.
+func issueShowResponse(issue *beads.Issue) IssueShowResponse {
 	resp := IssueShowResponse{
-		ID:        issueID,
-		RawOutput: output,
-	}
-
-	lines := strings.Split(output, "\n")
-	inDescription := false
-	parsedFirstLine := false
-	var descLines []string
-	var dependsOn []string
-	var blocks []string
-
-	for _, line := range lines {
-		// First non-empty line usually has the format: "○ id · title   [● P2 · OPEN]"
-		if !parsedFirstLine && (strings.HasPrefix(line, "○") || strings.HasPrefix(line, "●")) {
-			parsedFirstLine = true
-			// Parse the first line for title and status
-			// Format: "○ id · title   [● P2 · OPEN]"
-			// Find the bracket first to isolate the status
-			if bracketIdx := strings.Index(line, "["); bracketIdx > 0 {
-				beforeBracket := line[:bracketIdx]
-				statusPart := line[bracketIdx:]
-
-				// Extract priority and status from [● P2 · OPEN]
-				statusPart = strings.Trim(statusPart, "[]●○ ")
-				statusParts := strings.Split(statusPart, "·")
-				if len(statusParts) >= 1 {
-					resp.Priority = strings.TrimSpace(statusParts[0])
-				}
-				if len(statusParts) >= 2 {
-					resp.Status = strings.TrimSpace(statusParts[1])
-				}
-
-				// Now parse the title from before the bracket
-				// Format: "○ id · title"
-				// Find the second · which separates id from title
-				if firstDot := strings.Index(beforeBracket, "·"); firstDot > 0 {
-					afterFirstDot := beforeBracket[firstDot+len("·"):]
-					if secondDot := strings.Index(afterFirstDot, "·"); secondDot > 0 {
-						resp.Title = strings.TrimSpace(afterFirstDot[secondDot+len("·"):])
-					} else {
-						// Only one dot - id is embedded in icon part
-						resp.Title = strings.TrimSpace(afterFirstDot)
-					}
-				}
-			}
-			continue
+		ID:          issue.ID,
+		Title:       issue.Title,
+		Type:        issue.Type,
+		Status:      strings.ToUpper(issue.Status),
+		Priority:    fmt.Sprintf("P%d", issue.Priority),
+		Description: issue.Description,
+		Created:     issue.CreatedAt,
+		Updated:     issue.UpdatedAt,
+		DependsOn:   issue.DependsOn,
+		Blocks:      issue.Blocks,
+	}
+	if len(resp.DependsOn) == 0 {
+		for _, dep := range issue.Dependencies {
+			resp.DependsOn = append(resp.DependsOn, dep.ID)
 		}
-
-		if strings.HasPrefix(line, "Type:") {
-			resp.Type = strings.TrimSpace(strings.TrimPrefix(line, "Type:"))
-		} else if strings.HasPrefix(line, "Created:") {
-			parts := strings.Split(line, "·")
-			resp.Created = strings.TrimSpace(strings.TrimPrefix(parts[0], "Created:"))
-			if len(parts) > 1 {
-				resp.Updated = strings.TrimSpace(strings.TrimPrefix(parts[1], "Updated:"))
-			}
-		} else if line == "DESCRIPTION" {
-			inDescription = true
-		} else if line == "DEPENDS ON" || line == "BLOCKS" {
-			inDescription = false
-		} else if inDescription && strings.TrimSpace(line) != "" {
-			descLines = append(descLines, line)
-		} else if strings.HasPrefix(strings.TrimSpace(line), "→") {
-			// Dependency line
-			depLine := strings.TrimSpace(line)
-			depLine = strings.TrimPrefix(depLine, "→")
-			depLine = strings.TrimSpace(depLine)
-			// Extract just the bead ID
-			if colonIdx := strings.Index(depLine, ":"); colonIdx > 0 {
-				parts := strings.Fields(depLine[:colonIdx])
-				if len(parts) >= 2 {
-					dependsOn = append(dependsOn, parts[1])
-				}
-			}
-		} else if strings.HasPrefix(strings.TrimSpace(line), "←") {
-			// Blocks line
-			blockLine := strings.TrimSpace(line)
-			blockLine = strings.TrimPrefix(blockLine, "←")
-			blockLine = strings.TrimSpace(blockLine)
-			// Extract just the bead ID
-			if colonIdx := strings.Index(blockLine, ":"); colonIdx > 0 {
-				parts := strings.Fields(blockLine[:colonIdx])
-				if len(parts) >= 2 {
-					blocks = append(blocks, parts[1])
-				}
-			}
+	}
+	if len(resp.Blocks) == 0 {
+		for _, dep := range issue.Dependents {
+			resp.Blocks = append(resp.Blocks, dep.ID)
 		}
 	}
-
-	resp.Description = strings.TrimSpace(strings.Join(descLines, "\n"))
-	resp.DependsOn = dependsOn
-	resp.Blocks = blocks
-
 	return resp
 }
 
@@ -1246,10 +1178,19 @@ func (h *APIHandler) handleCrew(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Resolve each rig's crew pane patterns once for the request.
+	townRoot, _ := workspace.Find(h.workDir)
+	classifiers := make(map[string]*tmux.PaneClassifier)
+	for _, c := range crewData {
+		if _, ok := classifiers[c.Rig]; !ok {
+			classifiers[c.Rig] = crewPaneClassifier(townRoot, c.Rig)
+		}
+	}
+
 	// Convert to CrewMember format with state detection
 	for _, c := range crewData {
 		sessionName := fmt.Sprintf("gt-%s-crew-%s", c.Rig, c.Name)
-		state, lastActive, sessionStatus := h.detectCrewState(ctx, sessionName, c.Hook)
+		state, lastActive, sessionStatus := h.detectCrewState(ctx, classifiers[c.Rig], sessionName, c.Hook)
 
 		member := CrewMember{
 			Name:       c.Name,
@@ -1270,7 +1211,7 @@ func (h *APIHandler) handleCrew(w http.ResponseWriter, r *http.Request) {
 
 // detectCrewState determines crew member state from tmux session.
 // Returns: state (spinning/finished/questions/ready), lastActive string, session status
-func (h *APIHandler) detectCrewState(ctx context.Context, sessionName, hook string) (string, string, string) {
+func (h *APIHandler) detectCrewState(ctx context.Context, classifier *tmux.PaneClassifier, sessionName, hook string) (string, string, string) {
 	// Check if tmux session exists and get activity
 	cmd := exec.CommandContext(ctx, "tmux", "list-sessions", "-F", "#{session_name}|#{window_activity}|#{session_attached}")
 	var stdout bytes.Buffer
@@ -1310,7 +1251,7 @@ func (h *APIHandler) detectCrewState(ctx context.Context, sessionName, hook stri
 
 		// Check for questions if state is potentially finished
 		if state == "finished" || (state == "ready" && hook != "") {
-			if h.hasQuestionInPane(ctx, sessionName) {
+			if h.hasQuestionInPane(ctx, classifier, sessionName) {
 				state = "questions"
 			}
 		}
@@ -1340,44 +1281,33 @@ func (h *APIHandler) isClaudeRunningInSession(ctx context.Context, sessionName s
 	       strings.Contains(output, "opencode")
 }
 
-// hasQuestionInPane checks the last output for question indicators.
-func (h *APIHandler) hasQuestionInPane(ctx context.Context, sessionName string) bool {
-	cmd := exec.CommandContext(ctx, "tmux", "capture-pane", "-t", sessionName, "-p", "-J")
+// crewPaneClassifier compiles a rig's crew runtime pane patterns, falling
+// back to the default runtime's when the town isn't found or the rig's
+// patterns don't compile.
+func crewPaneClassifier(townRoot, rigName string) *tmux.PaneClassifier {
+	var rc *config.RuntimeConfig
+	if townRoot != "" {
+		rc = config.ResolveRoleAgentConfig(constants.RoleCrew, townRoot, filepath.Join(townRoot, rigName))
+	}
+	classifier, err := tmux.NewPaneClassifier(rc)
+	if err != nil {
+		classifier, _ = tmux.NewPaneClassifier(nil)
+	}
+	return classifier
+}
+
+// hasQuestionInPane reports whether a crew session is waiting on its
+// user: a question, a permission dialog or a y/n prompt, recognized with
+// the rig's crew runtime patterns.
+func (h *APIHandler) hasQuestionInPane(ctx context.Context, classifier *tmux.PaneClassifier, sessionName string) bool {
+	cmd := exec.CommandContext(ctx, "tmux", "capture-pane", "-p", "-t", sessionName,
+		"-S", fmt.Sprintf("-%d", tmux.PaneClassifyLines))
 	var stdout bytes.Buffer
 	cmd.Stdout = &stdout
 	if err := cmd.Run(); err != nil {
 		return false
 	}
-
-	// Get last few lines
-	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
-	lastLines := ""
-	if len(lines) > 10 {
-		lastLines = strings.Join(lines[len(lines)-10:], "\n")
-	} else {
-		lastLines = strings.Join(lines, "\n")
-	}
-	lastLines = strings.ToLower(lastLines)
-
-	// Look for question indicators
-	questionIndicators := []string{
-		"?",
-		"what do you think",
-		"should i",
-		"would you like",
-		"please confirm",
-		"waiting for",
-		"need your input",
-		"your thoughts",
-		"let me know",
-	}
-
-	for _, indicator := range questionIndicators {
-		if strings.Contains(lastLines, indicator) {
-			return true
-		}
-	}
-	return false
+	return classifier.Classify(stdout.String()).State.Blocked()
 }
 
 // determineCrewState determines state from activity and Claude status.
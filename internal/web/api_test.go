@@ -6,6 +6,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
 )
 
 func TestValidateCommand(t *testing.T) {
@@ -508,3 +511,56 @@ func TestAPIHandler_IssueCreate_InvalidJSON(t *testing.T) {
 		t.Errorf("POST /api/issues/create invalid JSON status = %d, want %d", w.Code, http.StatusBadRequest)
 	}
 }
+
+func TestAPIHandler_IssueCreateAndShow(t *testing.T) {
+	handler := NewAPIHandler()
+	store := beads.NewMemStore("gt-")
+	handler.store = func(time.Duration) beads.Store { return store }
+	store.Add(&beads.Issue{ID: "gt-dep", Title: "Dependency", Status: "open"})
+
+	body := `{"title": "Fix login", "description": "Users can't log in", "priority": 1}`
+	req := httptest.NewRequest(http.MethodPost, "/api/issues/create", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	var created IssueCreateResponse
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+	if !created.Success || created.ID == "" {
+		t.Fatalf("create response = %+v", created)
+	}
+	if err := store.AddDependency(created.ID, "gt-dep"); err != nil {
+		t.Fatal(err)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/issues/show?id="+created.ID, nil)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	var shown IssueShowResponse
+	if err := json.NewDecoder(w.Body).Decode(&shown); err != nil {
+		t.Fatal(err)
+	}
+	if shown.Title != "Fix login" || shown.Description != "Users can't log in" ||
+		shown.Priority != "P1" || shown.Status != "OPEN" {
+		t.Errorf("show response = %+v", shown)
+	}
+	if len(shown.DependsOn) != 1 || shown.DependsOn[0] != "gt-dep" {
+		t.Errorf("DependsOn = %v, want [gt-dep]", shown.DependsOn)
+	}
+}
+
+func TestAPIHandler_IssueShow_NotFound(t *testing.T) {
+	handler := NewAPIHandler()
+	store := beads.NewMemStore("gt-")
+	handler.store = func(time.Duration) beads.Store { return store }
+
+	req := httptest.NewRequest(http.MethodGet, "/api/issues/show?id=gt-missing", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("GET /api/issues/show unknown ID status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
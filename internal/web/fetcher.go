@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/steveyegge/gastown/internal/activity"
+	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -72,6 +73,7 @@ func runBdCmd(beadsDir string, args ...string) (*bytes.Buffer, error) {
 type LiveConvoyFetcher struct {
 	townRoot  string
 	townBeads string
+	store     beads.Store
 }
 
 // NewLiveConvoyFetcher creates a fetcher for the current workspace.
@@ -81,9 +83,12 @@ func NewLiveConvoyFetcher() (*LiveConvoyFetcher, error) {
 		return nil, fmt.Errorf("not in a Gas Town workspace: %w", err)
 	}
 
+	store := beads.New(townRoot)
+	store.SetTimeout(cmdTimeout)
 	return &LiveConvoyFetcher{
 		townRoot:  townRoot,
 		townBeads: filepath.Join(townRoot, ".beads"),
+		store:     store,
 	}, nil
 }
 
@@ -281,39 +286,21 @@ func (f *LiveConvoyFetcher) getIssueDetailsBatch(issueIDs []string) map[string]*
 		return result
 	}
 
-	args := append([]string{"show"}, issueIDs...)
-	args = append(args, "--json")
-
-	stdout, err := runCmd(cmdTimeout, "bd", args...)
+	issues, err := f.store.ShowMultiple(issueIDs)
 	if err != nil {
 		return result
 	}
-
-	var issues []struct {
-		ID        string `json:"id"`
-		Title     string `json:"title"`
-		Status    string `json:"status"`
-		Assignee  string `json:"assignee"`
-		UpdatedAt string `json:"updated_at"`
-	}
-	if err := json.Unmarshal(stdout.Bytes(), &issues); err != nil {
-		return result
-	}
-
-	for _, issue := range issues {
+	for id, issue := range issues {
 		detail := &issueDetail{
 			ID:       issue.ID,
 			Title:    issue.Title,
 			Status:   issue.Status,
 			Assignee: issue.Assignee,
 		}
-		// Parse updated_at timestamp
-		if issue.UpdatedAt != "" {
-			if t, err := time.Parse(time.RFC3339, issue.UpdatedAt); err == nil {
-				detail.UpdatedAt = t
-			}
+		if t, err := time.Parse(time.RFC3339, issue.UpdatedAt); err == nil {
+			detail.UpdatedAt = t
 		}
-		result[issue.ID] = detail
+		result[id] = detail
 	}
 
 	return result
@@ -781,20 +768,11 @@ func (f *LiveConvoyFetcher) getAssignedIssuesMap() map[string]assignedIssue {
 	result := make(map[string]assignedIssue)
 
 	// Query all in_progress issues (these are the ones being worked on)
-	stdout, err := runBdCmd(f.townRoot, "list", "--status=in_progress", "--json")
+	issues, err := f.store.List(beads.ListOptions{Status: "in_progress", Priority: -1})
 	if err != nil {
 		return result // Return empty map on error
 	}
 
-	var issues []struct {
-		ID       string `json:"id"`
-		Title    string `json:"title"`
-		Assignee string `json:"assignee"`
-	}
-	if err := json.Unmarshal(stdout.Bytes(), &issues); err != nil {
-		return result
-	}
-
 	for _, issue := range issues {
 		if issue.Assignee != "" {
 			result[issue.Assignee] = assignedIssue{
@@ -1160,23 +1138,11 @@ func (f *LiveConvoyFetcher) FetchDogs() ([]DogRow, error) {
 // FetchEscalations returns open escalations needing attention.
 func (f *LiveConvoyFetcher) FetchEscalations() ([]EscalationRow, error) {
 	// List open escalations
-	stdout, err := runBdCmd(f.townRoot, "list", "--label=gt:escalation", "--status=open", "--json")
+	issues, err := f.store.List(beads.ListOptions{Label: "gt:escalation", Status: "open", Priority: -1})
 	if err != nil {
 		return nil, nil // No escalations or bd not available
 	}
 
-	var issues []struct {
-		ID          string   `json:"id"`
-		Title       string   `json:"title"`
-		CreatedAt   string   `json:"created_at"`
-		CreatedBy   string   `json:"created_by"`
-		Labels      []string `json:"labels"`
-		Description string   `json:"description"`
-	}
-	if err := json.Unmarshal(stdout.Bytes(), &issues); err != nil {
-		return nil, fmt.Errorf("parsing escalations: %w", err)
-	}
-
 	var rows []EscalationRow
 	for _, issue := range issues {
 		row := EscalationRow{
@@ -1398,23 +1364,13 @@ func (f *LiveConvoyFetcher) FetchSessions() ([]SessionRow, error) {
 // FetchHooks returns all hooked beads (work pinned to agents).
 func (f *LiveConvoyFetcher) FetchHooks() ([]HookRow, error) {
 	// Query all beads with status=hooked
-	stdout, err := runBdCmd(f.townRoot, "list", "--status=hooked", "--json", "--limit=0")
+	hooked, err := f.store.List(beads.ListOptions{Status: "hooked", Priority: -1, Limit: -1})
 	if err != nil {
 		return nil, nil // No hooked beads or bd not available
 	}
 
-	var beads []struct {
-		ID        string `json:"id"`
-		Title     string `json:"title"`
-		Assignee  string `json:"assignee"`
-		UpdatedAt string `json:"updated_at"`
-	}
-	if err := json.Unmarshal(stdout.Bytes(), &beads); err != nil {
-		return nil, fmt.Errorf("parsing hooked beads: %w", err)
-	}
-
 	var rows []HookRow
-	for _, bead := range beads {
+	for _, bead := range hooked {
 		row := HookRow{
 			ID:       bead.ID,
 			Title:    bead.Title,
@@ -1494,49 +1450,15 @@ func (f *LiveConvoyFetcher) FetchMayor() (*MayorStatus, error) {
 func (f *LiveConvoyFetcher) FetchIssues() ([]IssueRow, error) {
 	// Query both open AND hooked issues for the Work panel
 	// Open = ready to assign, Hooked = in progress
-	var allBeads []struct {
-		ID        string   `json:"id"`
-		Title     string   `json:"title"`
-		Type      string   `json:"type"`
-		Priority  int      `json:"priority"`
-		Labels    []string `json:"labels"`
-		CreatedAt string   `json:"created_at"`
-	}
-
-	// Fetch open issues
-	if stdout, err := runBdCmd(f.townRoot, "list", "--status=open", "--json", "--limit=50"); err == nil {
-		var openBeads []struct {
-			ID        string   `json:"id"`
-			Title     string   `json:"title"`
-			Type      string   `json:"type"`
-			Priority  int      `json:"priority"`
-			Labels    []string `json:"labels"`
-			CreatedAt string   `json:"created_at"`
-		}
-		if err := json.Unmarshal(stdout.Bytes(), &openBeads); err == nil {
-			allBeads = append(allBeads, openBeads...)
+	var issues []*beads.Issue
+	for _, status := range []string{"open", "hooked"} {
+		if list, err := f.store.List(beads.ListOptions{Status: status, Priority: -1, Limit: 50}); err == nil {
+			issues = append(issues, list...)
 		}
 	}
 
-	// Fetch hooked issues (in progress)
-	if stdout, err := runBdCmd(f.townRoot, "list", "--status=hooked", "--json", "--limit=50"); err == nil {
-		var hookedBeads []struct {
-			ID        string   `json:"id"`
-			Title     string   `json:"title"`
-			Type      string   `json:"type"`
-			Priority  int      `json:"priority"`
-			Labels    []string `json:"labels"`
-			CreatedAt string   `json:"created_at"`
-		}
-		if err := json.Unmarshal(stdout.Bytes(), &hookedBeads); err == nil {
-			allBeads = append(allBeads, hookedBeads...)
-		}
-	}
-
-	beads := allBeads
-
 	var rows []IssueRow
-	for _, bead := range beads {
+	for _, bead := range issues {
 		// Skip internal types (messages, convoys, queues, merge-requests, wisps)
 		switch bead.Type {
 		case "message", "convoy", "queue", "merge-request", "wisp", "agent":
@@ -2,8 +2,10 @@ package web
 
 import (
 	"testing"
+	"time"
 
 	"github.com/steveyegge/gastown/internal/activity"
+	"github.com/steveyegge/gastown/internal/beads"
 )
 
 func TestCalculateWorkStatus(t *testing.T) {
@@ -415,3 +417,54 @@ func TestParseActivityTimestamp(t *testing.T) {
 		})
 	}
 }
+
+func TestLiveConvoyFetcher_StoreBackedPanels(t *testing.T) {
+	old := time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339)
+	store := beads.NewMemStore("gt-")
+	store.Add(
+		&beads.Issue{ID: "gt-hook", Title: "Hooked work", Status: "hooked", Priority: 1, Type: "task",
+			Assignee: "gastown/polecats/nux", UpdatedAt: old, CreatedAt: old},
+		&beads.Issue{ID: "gt-esc", Title: "Help", Status: "open", Priority: 2, Type: "task",
+			Labels: []string{"gt:escalation", "severity:critical"}, CreatedBy: "gastown/witness", CreatedAt: old},
+		&beads.Issue{ID: "gt-msg", Title: "Mail", Status: "open", Type: "message"},
+		&beads.Issue{ID: "gt-wip", Title: "In progress", Status: "in_progress", Assignee: "gastown/polecats/max"},
+	)
+	f := &LiveConvoyFetcher{store: store}
+
+	hooks, err := f.FetchHooks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hooks) != 1 || hooks[0].ID != "gt-hook" || !hooks[0].IsStale {
+		t.Errorf("FetchHooks() = %+v", hooks)
+	}
+
+	escalations, err := f.FetchEscalations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(escalations) != 1 || escalations[0].Severity != "critical" {
+		t.Errorf("FetchEscalations() = %+v", escalations)
+	}
+
+	issues, err := f.FetchIssues()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, row := range issues {
+		ids = append(ids, row.ID)
+	}
+	if len(ids) != 2 || ids[0] != "gt-hook" || ids[1] != "gt-esc" {
+		t.Errorf("FetchIssues() IDs = %v, want [gt-hook gt-esc]", ids)
+	}
+
+	if got := f.getAssignedIssuesMap(); got["gastown/polecats/max"].ID != "gt-wip" || len(got) != 1 {
+		t.Errorf("getAssignedIssuesMap() = %+v", got)
+	}
+
+	details := f.getIssueDetailsBatch([]string{"gt-hook", "gt-missing"})
+	if len(details) != 1 || details["gt-hook"].Assignee != "gastown/polecats/nux" {
+		t.Errorf("getIssueDetailsBatch() = %+v", details)
+	}
+}
@@ -1331,7 +1331,7 @@
 
                 document.getElementById('issue-detail-id').textContent = data.id || issueId;
                 document.getElementById('issue-detail-title-text').textContent = data.title || '(no title)';
-                document.getElementById('issue-detail-description').textContent = data.description || data.raw_output || '(no description)';
+                document.getElementById('issue-detail-description').textContent = data.description || '(no description)';
 
                 // Priority badge
                 var priorityEl = document.getElementById('issue-detail-priority');
@@ -0,0 +1,197 @@
+// Package webhooks connects the town to external systems over HTTP.
+//
+// Outbound: endpoints are configured in settings/webhooks.json. The
+// daemon's Dispatcher reads the activity log and POSTs each matching
+// event as a signed JSON payload, retrying with exponential backoff.
+// Deliveries that still fail are appended to a dead-letter file for
+// gt webhooks replay.
+//
+// Inbound: the Receiver accepts GitHub, Gitea and generic JSON webhooks,
+// verifies their HMAC signatures, rejects replays, and runs mapping rules
+// that create beads, mail the mayor or sling work.
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/eventbus"
+)
+
+// Defaults for endpoint settings.
+const (
+	DefaultMaxAttempts = 5
+	DefaultTimeout     = 10 * time.Second
+)
+
+// DefaultEvents are delivered to endpoints that don't list event types.
+var DefaultEvents = []string{
+	eventbus.TypeSling,
+	eventbus.TypeDone,
+	eventbus.TypeMerged,
+	eventbus.TypeMergeFailed,
+	eventbus.TypeEscalationSent,
+	eventbus.TypeSessionDeath,
+	eventbus.TypeMassDeath,
+	eventbus.TypeConvoyClosed,
+}
+
+// ConfigPath returns the webhook config path for a town.
+func ConfigPath(townRoot string) string {
+	return filepath.Join(townRoot, "settings", "webhooks.json")
+}
+
+// Config is settings/webhooks.json.
+type Config struct {
+	Type      string     `json:"type"`    // "webhooks"
+	Version   int        `json:"version"` // schema version
+	Endpoints []Endpoint `json:"endpoints"`
+
+	// Inbound configures the receiver for external webhooks (see
+	// Receiver). Nil disables it.
+	Inbound *InboundConfig `json:"inbound,omitempty"`
+}
+
+// Endpoint is one webhook destination.
+type Endpoint struct {
+	// Name identifies the endpoint in gt webhooks commands and the
+	// dead-letter file.
+	Name string `json:"name"`
+	URL  string `json:"url"`
+
+	// Events lists the event types to deliver; "*" delivers every
+	// feed-visible event. Empty means DefaultEvents.
+	Events []string `json:"events,omitempty"`
+
+	// Secret signs payloads (see Sign). SecretEnv names an environment
+	// variable to read it from instead, keeping it out of the town repo.
+	Secret    string `json:"secret,omitempty"`
+	SecretEnv string `json:"secret_env,omitempty"`
+
+	// Headers are added to every request (e.g. an Authorization token).
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// MaxAttempts bounds delivery attempts (default 5).
+	MaxAttempts int `json:"max_attempts,omitempty"`
+
+	// Timeout is the per-request timeout as a Go duration (default "10s").
+	Timeout string `json:"timeout,omitempty"`
+
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// LoadConfig reads settings/webhooks.json. A missing file is an empty
+// config.
+func LoadConfig(townRoot string) (*Config, error) {
+	path := ConfigPath(townRoot)
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Config{Type: "webhooks", Version: 1}, nil
+		}
+		return nil, fmt.Errorf("reading webhook config: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing webhook config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Validate checks endpoint names, URLs and settings, and the inbound
+// section if present.
+func (c *Config) Validate() error {
+	if c.Inbound != nil {
+		if err := c.Inbound.Validate(); err != nil {
+			return err
+		}
+	}
+	seen := make(map[string]bool)
+	for i, ep := range c.Endpoints {
+		if ep.Name == "" {
+			return fmt.Errorf("endpoints[%d]: name is required", i)
+		}
+		if seen[ep.Name] {
+			return fmt.Errorf("endpoints[%d]: duplicate name %q", i, ep.Name)
+		}
+		seen[ep.Name] = true
+
+		u, err := url.Parse(ep.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("endpoint %q: url must be an http(s) URL, got %q", ep.Name, ep.URL)
+		}
+		if ep.MaxAttempts < 0 {
+			return fmt.Errorf("endpoint %q: max_attempts must not be negative", ep.Name)
+		}
+		if ep.Timeout != "" {
+			d, err := time.ParseDuration(ep.Timeout)
+			if err != nil || d <= 0 {
+				return fmt.Errorf("endpoint %q: invalid timeout %q", ep.Name, ep.Timeout)
+			}
+		}
+	}
+	return nil
+}
+
+// Endpoint returns the named endpoint, or nil.
+func (c *Config) Endpoint(name string) *Endpoint {
+	for i := range c.Endpoints {
+		if c.Endpoints[i].Name == name {
+			return &c.Endpoints[i]
+		}
+	}
+	return nil
+}
+
+// EventTypes returns the types delivered to the endpoint.
+func (e *Endpoint) EventTypes() []string {
+	if len(e.Events) == 0 {
+		return DefaultEvents
+	}
+	return e.Events
+}
+
+// Wants reports whether the endpoint receives an event.
+func (e *Endpoint) Wants(ev eventbus.Event) bool {
+	if e.Disabled {
+		return false
+	}
+	types := e.EventTypes()
+	if slices.Contains(types, "*") {
+		return ev.Visibility != eventbus.VisibilityAudit
+	}
+	return slices.Contains(types, ev.Type)
+}
+
+// SecretValue returns the signing secret, or "" for unsigned deliveries.
+func (e *Endpoint) SecretValue() string {
+	if e.SecretEnv != "" {
+		return os.Getenv(e.SecretEnv)
+	}
+	return e.Secret
+}
+
+// Attempts returns the maximum number of delivery attempts.
+func (e *Endpoint) Attempts() int {
+	if e.MaxAttempts > 0 {
+		return e.MaxAttempts
+	}
+	return DefaultMaxAttempts
+}
+
+// RequestTimeout returns the per-request timeout.
+func (e *Endpoint) RequestTimeout() time.Duration {
+	if d, err := time.ParseDuration(e.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return DefaultTimeout
+}
@@ -0,0 +1,142 @@
+package webhooks
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gofrs/flock"
+)
+
+// DeadLetterPath returns the dead-letter file for a town.
+func DeadLetterPath(townRoot string) string {
+	return filepath.Join(townRoot, "daemon", "webhooks-dead.jsonl")
+}
+
+// DeadLetter is a delivery that exhausted its attempts.
+type DeadLetter struct {
+	Endpoint string    `json:"endpoint"`
+	URL      string    `json:"url"`
+	Payload  Payload   `json:"payload"`
+	Attempts int       `json:"attempts"`
+	Status   int       `json:"status,omitempty"`
+	Error    string    `json:"error"`
+	FailedAt time.Time `json:"failed_at"`
+}
+
+// deadMu serializes dead-letter file access within this process; the file
+// lock taken by lockDeadLetters serializes it with other processes (the
+// daemon appends while 'gt webhooks replay' rewrites).
+var deadMu sync.Mutex
+
+// lockDeadLetters takes the dead-letter file lock and returns its release.
+func lockDeadLetters(townRoot string) (func(), error) {
+	path := DeadLetterPath(townRoot)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("creating dead-letter directory: %w", err)
+	}
+	lock := flock.New(path + ".lock")
+	if err := lock.Lock(); err != nil {
+		return nil, fmt.Errorf("locking dead-letter file: %w", err)
+	}
+	return func() { _ = lock.Unlock() }, nil
+}
+
+// AppendDeadLetter records a failed delivery.
+func AppendDeadLetter(townRoot string, dl DeadLetter) error {
+	data, err := json.Marshal(dl)
+	if err != nil {
+		return fmt.Errorf("encoding dead letter: %w", err)
+	}
+
+	deadMu.Lock()
+	defer deadMu.Unlock()
+	unlock, err := lockDeadLetters(townRoot)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	f, err := os.OpenFile(DeadLetterPath(townRoot), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("opening dead-letter file: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("writing dead letter: %w", err)
+	}
+	return nil
+}
+
+// ReadDeadLetters returns the recorded failed deliveries, oldest first.
+func ReadDeadLetters(townRoot string) ([]DeadLetter, error) {
+	deadMu.Lock()
+	defer deadMu.Unlock()
+	return readDeadLetters(townRoot)
+}
+
+func readDeadLetters(townRoot string) ([]DeadLetter, error) {
+	f, err := os.Open(DeadLetterPath(townRoot))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("opening dead-letter file: %w", err)
+	}
+	defer f.Close()
+
+	var result []DeadLetter
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var dl DeadLetter
+		if err := json.Unmarshal(scanner.Bytes(), &dl); err != nil {
+			continue
+		}
+		result = append(result, dl)
+	}
+	return result, scanner.Err()
+}
+
+// RemoveDeadLetters drops the given deliveries (by delivery ID) from the
+// file, keeping any recorded since they were read.
+func RemoveDeadLetters(townRoot string, deliveries map[string]bool) error {
+	deadMu.Lock()
+	defer deadMu.Unlock()
+	unlock, err := lockDeadLetters(townRoot)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	all, err := readDeadLetters(townRoot)
+	if err != nil {
+		return err
+	}
+	var keep []byte
+	for _, dl := range all {
+		if deliveries[dl.Payload.Delivery] {
+			continue
+		}
+		data, err := json.Marshal(dl)
+		if err != nil {
+			return fmt.Errorf("encoding dead letter: %w", err)
+		}
+		keep = append(keep, data...)
+		keep = append(keep, '\n')
+	}
+
+	path := DeadLetterPath(townRoot)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, keep, 0600); err != nil {
+		return fmt.Errorf("writing dead-letter file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("replacing dead-letter file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/eventbus"
+)
+
+// Request headers.
+const (
+	HeaderEvent     = "X-Gastown-Event"
+	HeaderDelivery  = "X-Gastown-Delivery"
+	HeaderTimestamp = "X-Gastown-Timestamp"
+	HeaderSignature = "X-Gastown-Signature"
+)
+
+// PayloadVersion is the webhook body schema version.
+const PayloadVersion = 1
+
+// Payload is the JSON body POSTed to endpoints.
+type Payload struct {
+	Version  int            `json:"v"`
+	Delivery string         `json:"delivery"`
+	Event    eventbus.Event `json:"event"`
+}
+
+// NewPayload wraps an event for delivery with a fresh delivery ID.
+func NewPayload(ev eventbus.Event) Payload {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return Payload{Version: PayloadVersion, Delivery: hex.EncodeToString(b), Event: ev}
+}
+
+// Sign returns the signature header value for a body sent at timestamp
+// (Unix seconds): "sha256=" + hex HMAC-SHA256 of "<timestamp>.<body>".
+// Covering the timestamp lets receivers reject replayed deliveries.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature produced by Sign and that the timestamp is
+// within maxSkew of now.
+func Verify(secret string, timestamp int64, body []byte, signature string, maxSkew time.Duration, now time.Time) error {
+	if skew := now.Sub(time.Unix(timestamp, 0)); skew > maxSkew || skew < -maxSkew {
+		return fmt.Errorf("timestamp outside %v window", maxSkew)
+	}
+	if !hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature)) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+// Result is the outcome of delivering one payload.
+type Result struct {
+	Attempts int
+	Status   int // Last HTTP status, 0 if no response
+	Err      error
+}
+
+// Sender POSTs payloads to endpoints.
+type Sender struct {
+	Client *http.Client
+
+	// Backoff returns the wait before retry n (1-based). Defaults to
+	// exponential backoff from one second, capped at one minute.
+	Backoff func(n int) time.Duration
+
+	// Now returns the signing time; defaults to time.Now.
+	Now func() time.Time
+}
+
+// NewSender creates a sender with default settings.
+func NewSender() *Sender {
+	return &Sender{Client: &http.Client{}}
+}
+
+func defaultBackoff(n int) time.Duration {
+	d := time.Second << (n - 1)
+	if d <= 0 || d > time.Minute {
+		return time.Minute
+	}
+	return d
+}
+
+// Send makes one delivery attempt and returns the HTTP status. Non-2xx
+// responses are returned as errors.
+func (s *Sender) Send(ctx context.Context, ep *Endpoint, p Payload) (int, error) {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return 0, fmt.Errorf("encoding payload: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, ep.RequestTimeout())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("building request: %w", err)
+	}
+
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	ts := now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "gastown-webhooks/1")
+	req.Header.Set(HeaderEvent, p.Event.Type)
+	req.Header.Set(HeaderDelivery, p.Delivery)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	if secret := ep.SecretValue(); secret != "" {
+		req.Header.Set(HeaderSignature, Sign(secret, ts, body))
+	}
+	for k, v := range ep.Headers {
+		req.Header.Set(k, v)
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint returned %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// retryable reports whether a failed attempt is worth retrying: network
+// errors, timeouts, 429 and 5xx. Other 4xx responses will not change.
+func retryable(status int) bool {
+	return status == 0 || status == http.StatusTooManyRequests || status >= 500
+}
+
+// Deliver sends a payload, retrying with backoff up to the endpoint's
+// attempt limit. It stops early when ctx is cancelled.
+func (s *Sender) Deliver(ctx context.Context, ep *Endpoint, p Payload) Result {
+	backoff := s.Backoff
+	if backoff == nil {
+		backoff = defaultBackoff
+	}
+
+	var r Result
+	for r.Attempts < ep.Attempts() {
+		if r.Attempts > 0 {
+			select {
+			case <-ctx.Done():
+				r.Err = fmt.Errorf("%w (gave up: %v)", r.Err, ctx.Err())
+				return r
+			case <-time.After(backoff(r.Attempts)):
+			}
+		}
+		r.Attempts++
+		r.Status, r.Err = s.Send(ctx, ep, p)
+		if r.Err == nil || !retryable(r.Status) {
+			return r
+		}
+	}
+	return r
+}
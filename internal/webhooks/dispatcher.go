@@ -0,0 +1,232 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/eventbus"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// queueSize bounds the deliveries waiting per endpoint. Overflow goes
+// straight to the dead-letter file so a dead endpoint can't grow memory.
+const queueSize = 256
+
+// pollInterval is how often the dispatcher reads new events from the log.
+const pollInterval = 250 * time.Millisecond
+
+// CursorPath returns the file recording the last event the dispatcher
+// queued, so deliveries resume where they left off after a restart.
+func CursorPath(townRoot string) string {
+	return filepath.Join(townRoot, "daemon", "webhooks-cursor.json")
+}
+
+// Dispatcher delivers events from the town's activity log to the
+// configured endpoints. It reads the log itself, by offset, rather than
+// subscribing to the event bus, so no event is skipped when it falls behind
+// or the daemon restarts: every event is either delivered or dead-lettered.
+// Each endpoint has its own queue and worker, so deliveries to one endpoint
+// stay in order and a slow endpoint does not hold up the others.
+type Dispatcher struct {
+	townRoot string
+	sender   *Sender
+	logger   func(format string, args ...interface{})
+
+	mu     sync.Mutex
+	cfg    *Config
+	queues map[string]chan Payload
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewDispatcher creates a dispatcher for a town's activity log.
+func NewDispatcher(townRoot string, cfg *Config, logger func(format string, args ...interface{})) *Dispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Dispatcher{
+		townRoot: townRoot,
+		sender:   NewSender(),
+		logger:   logger,
+		cfg:      cfg,
+		queues:   make(map[string]chan Payload),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Start begins delivering. It resumes after the last event queued before
+// the previous stop; on first start it begins at the end of the log.
+func (d *Dispatcher) Start() {
+	var tailer *eventbus.Tailer
+	if c, ok := d.loadCursor(); ok {
+		tailer = eventbus.NewTailerAfter(d.townRoot, c)
+	} else {
+		tailer = eventbus.NewTailer(d.townRoot, true)
+	}
+	d.wg.Add(1)
+	go d.run(tailer)
+}
+
+// Stop stops delivering. Queued and in-flight deliveries are written to
+// the dead-letter file for replay.
+func (d *Dispatcher) Stop() {
+	d.cancel()
+	d.wg.Wait()
+}
+
+// UpdateConfig swaps the endpoint configuration. Queued deliveries for
+// removed or disabled endpoints are dead-lettered.
+func (d *Dispatcher) UpdateConfig(cfg *Config) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.cfg = cfg
+}
+
+func (d *Dispatcher) run(t *eventbus.Tailer) {
+	defer d.wg.Done()
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+			evs, err := t.Poll()
+			if err != nil {
+				d.logger("webhooks: %v", err)
+				continue
+			}
+			if len(evs) == 0 {
+				continue
+			}
+			for _, ev := range evs {
+				d.dispatch(ev)
+			}
+			// Queued deliveries are delivered or dead-lettered, even at
+			// shutdown, so the cursor can move past them now.
+			d.saveCursor(t.Cursor())
+		}
+	}
+}
+
+func (d *Dispatcher) loadCursor() (eventbus.Cursor, bool) {
+	var c eventbus.Cursor
+	data, err := os.ReadFile(CursorPath(d.townRoot))
+	if err != nil || json.Unmarshal(data, &c) != nil || (c.ID == "" && c.Time.IsZero()) {
+		return c, false
+	}
+	return c, true
+}
+
+func (d *Dispatcher) saveCursor(c eventbus.Cursor) {
+	if err := os.MkdirAll(filepath.Dir(CursorPath(d.townRoot)), 0755); err != nil {
+		d.logger("webhooks: saving cursor: %v", err)
+		return
+	}
+	if err := util.AtomicWriteJSON(CursorPath(d.townRoot), c); err != nil {
+		d.logger("webhooks: saving cursor: %v", err)
+	}
+}
+
+// dispatch queues an event for every endpoint that wants it.
+func (d *Dispatcher) dispatch(ev eventbus.Event) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for i := range d.cfg.Endpoints {
+		ep := &d.cfg.Endpoints[i]
+		if !ep.Wants(ev) {
+			continue
+		}
+		q, ok := d.queues[ep.Name]
+		if !ok {
+			q = make(chan Payload, queueSize)
+			d.queues[ep.Name] = q
+			d.wg.Add(1)
+			go d.worker(ep.Name, q)
+		}
+		p := NewPayload(ev)
+		select {
+		case q <- p:
+		default:
+			d.deadLetter(ep, p, Result{Err: fmt.Errorf("delivery queue full")})
+		}
+	}
+}
+
+// endpoint returns a copy of the named endpoint's current config. If it
+// was removed or disabled, the error says which; for a removed endpoint
+// only the name is known.
+func (d *Dispatcher) endpoint(name string) (*Endpoint, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	ep := d.cfg.Endpoint(name)
+	if ep == nil {
+		return &Endpoint{Name: name}, fmt.Errorf("endpoint removed before delivery")
+	}
+	cp := *ep
+	if cp.Disabled {
+		return &cp, fmt.Errorf("endpoint disabled before delivery")
+	}
+	return &cp, nil
+}
+
+func (d *Dispatcher) worker(name string, q chan Payload) {
+	defer d.wg.Done()
+	for {
+		select {
+		case <-d.ctx.Done():
+			d.drain(name, q)
+			return
+		case p := <-q:
+			ep, err := d.endpoint(name)
+			if err != nil {
+				d.deadLetter(ep, p, Result{Err: err})
+				continue
+			}
+			r := d.sender.Deliver(d.ctx, ep, p)
+			if r.Err != nil {
+				d.deadLetter(ep, p, r)
+			}
+		}
+	}
+}
+
+// drain dead-letters deliveries still queued at shutdown.
+func (d *Dispatcher) drain(name string, q chan Payload) {
+	ep, err := d.endpoint(name)
+	if err == nil {
+		err = fmt.Errorf("daemon stopped before delivery")
+	}
+	for {
+		select {
+		case p := <-q:
+			d.deadLetter(ep, p, Result{Err: err})
+		default:
+			return
+		}
+	}
+}
+
+func (d *Dispatcher) deadLetter(ep *Endpoint, p Payload, r Result) {
+	d.logger("webhook %s: %s %s failed after %d attempt(s): %v", ep.Name, p.Event.Type, p.Delivery, r.Attempts, r.Err)
+	err := AppendDeadLetter(d.townRoot, DeadLetter{
+		Endpoint: ep.Name,
+		URL:      ep.URL,
+		Payload:  p,
+		Attempts: r.Attempts,
+		Status:   r.Status,
+		Error:    r.Err.Error(),
+		FailedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		d.logger("webhook %s: %v", ep.Name, err)
+	}
+}
@@ -0,0 +1,505 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HooksPrefix is the URL path under which sources are served.
+const HooksPrefix = "/hooks/"
+
+// maxInboundBody bounds inbound request bodies.
+const maxInboundBody = 1 << 20
+
+// SeenTTL is how long deliveries are remembered for replay protection.
+const SeenTTL = 7 * 24 * time.Hour
+
+// inboundQueueSize bounds deliveries accepted but not yet applied.
+const inboundQueueSize = 64
+
+// SeenPath returns the replay-protection store for a town.
+func SeenPath(townRoot string) string {
+	return filepath.Join(townRoot, "daemon", "hooks-seen.json")
+}
+
+// SignBody returns "sha256=" + hex HMAC-SHA256 of body, the scheme GitHub
+// uses for X-Hub-Signature-256.
+func SignBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// SeenStore remembers recent deliveries so replayed requests are
+// rejected. It also records which rules of a delivery have completed, so
+// a sender's retry after a partial failure runs only the rules that
+// failed. It persists to a JSON file when path is non-empty.
+type SeenStore struct {
+	mu     sync.Mutex
+	path   string
+	ids    map[string]*seenDelivery
+	active map[string]bool // deliveries being applied right now
+}
+
+// seenDelivery is the stored state of one delivery.
+type seenDelivery struct {
+	At    time.Time            `json:"at"`
+	Done  bool                 `json:"done,omitempty"`
+	Rules map[string]RuleState `json:"rules,omitempty"`
+}
+
+// RuleState is the progress of one rule on a delivery.
+type RuleState struct {
+	// Bead is the bead the rule created, reused when a sling is retried.
+	Bead string `json:"bead,omitempty"`
+
+	// Done is set once the rule's action succeeded.
+	Done bool `json:"done,omitempty"`
+}
+
+// NewSeenStore loads the store at path ("" keeps it in memory only).
+func NewSeenStore(path string) (*SeenStore, error) {
+	s := &SeenStore{path: path, ids: make(map[string]*seenDelivery), active: make(map[string]bool)}
+	if path == "" {
+		return s, nil
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	for id, v := range raw {
+		d := &seenDelivery{}
+		var at time.Time
+		if err := json.Unmarshal(v, &at); err == nil {
+			// Older stores kept only the time of fully handled deliveries.
+			d.At, d.Done = at, true
+		} else if err := json.Unmarshal(v, d); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+		s.ids[id] = d
+	}
+	return s, nil
+}
+
+// Claim reports whether the delivery id should be applied: it is new, or
+// an earlier attempt left rules unfinished and no attempt is running.
+// A claimed delivery must be ended with Finish. Expired entries are
+// pruned on each call.
+func (s *SeenStore) Claim(id string, now time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, d := range s.ids {
+		if now.Sub(d.At) > SeenTTL && !s.active[k] {
+			delete(s.ids, k)
+		}
+	}
+	if d, ok := s.ids[id]; ok {
+		if d.Done || s.active[id] {
+			return false, nil
+		}
+		s.active[id] = true
+		return true, nil
+	}
+	s.ids[id] = &seenDelivery{At: now}
+	s.active[id] = true
+	return true, s.save()
+}
+
+// Rule returns the recorded progress of rule on delivery id.
+func (s *SeenStore) Rule(id, rule string) RuleState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if d, ok := s.ids[id]; ok {
+		return d.Rules[rule]
+	}
+	return RuleState{}
+}
+
+// SetRule records the progress of rule on delivery id.
+func (s *SeenStore) SetRule(id, rule string, st RuleState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.ids[id]
+	if !ok {
+		return nil
+	}
+	if d.Rules == nil {
+		d.Rules = make(map[string]RuleState)
+	}
+	d.Rules[rule] = st
+	return s.save()
+}
+
+// Finish ends the attempt started by Claim. When done is false the
+// delivery stays open so the sender's retry is accepted.
+func (s *SeenStore) Finish(id string, done bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.active, id)
+	d, ok := s.ids[id]
+	if !ok {
+		return nil
+	}
+	d.Done = done
+	if done {
+		d.Rules = nil
+	}
+	return s.save()
+}
+
+func (s *SeenStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(s.ids)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// Actions performs the effects of inbound rules.
+type Actions interface {
+	// CreateBead creates a bead in rig and returns its ID.
+	CreateBead(rig, title, description string, priority int) (string, error)
+
+	// Mail sends a message to an address such as "mayor/".
+	Mail(to, subject, body string) error
+
+	// Sling assigns a bead to a target agent or rig.
+	Sling(bead, target string) error
+}
+
+// Outcome reports one rule's effect on a delivery.
+type Outcome struct {
+	Rule   string `json:"rule"`
+	Action string `json:"action"`
+	Bead   string `json:"bead,omitempty"`
+	Target string `json:"target,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Receiver is an http.Handler for inbound webhooks at /hooks/<source>.
+// Deliveries are acknowledged once recorded; their actions run on a
+// worker, since a sling can outlast the sender's delivery timeout.
+type Receiver struct {
+	mu      sync.RWMutex
+	cfg     *InboundConfig
+	actions Actions
+	seen    *SeenStore
+	logger  func(format string, args ...interface{})
+
+	queue   chan inboundJob
+	pending sync.WaitGroup // queued or running jobs
+	done    chan struct{}
+
+	// Now returns the current time; defaults to time.Now.
+	Now func() time.Time
+}
+
+// inboundJob is a recorded delivery waiting for its actions to run.
+type inboundJob struct {
+	cfg *InboundConfig
+	in  *Inbound
+	key string
+}
+
+// NewReceiver creates a receiver for cfg and starts its worker.
+func NewReceiver(cfg *InboundConfig, actions Actions, seen *SeenStore, logger func(format string, args ...interface{})) *Receiver {
+	if cfg == nil {
+		cfg = &InboundConfig{}
+	}
+	r := &Receiver{
+		cfg:     cfg,
+		actions: actions,
+		seen:    seen,
+		logger:  logger,
+		queue:   make(chan inboundJob, inboundQueueSize),
+		done:    make(chan struct{}),
+		Now:     time.Now,
+	}
+	go r.work()
+	return r
+}
+
+// Close stops the worker after it applies the deliveries already queued.
+func (r *Receiver) Close() {
+	close(r.queue)
+	<-r.done
+}
+
+// work applies queued deliveries one at a time.
+func (r *Receiver) work() {
+	defer close(r.done)
+	for job := range r.queue {
+		_, failed := r.apply(job.cfg, job.in, job.key)
+		// A failed delivery stays open: a redelivery runs only the rules
+		// that have not completed.
+		if err := r.seen.Finish(job.key, !failed); err != nil {
+			r.logger("webhooks: saving replay store: %v", err)
+		}
+		r.pending.Done()
+	}
+}
+
+// UpdateConfig swaps in a new inbound config.
+func (r *Receiver) UpdateConfig(cfg *InboundConfig) {
+	if cfg == nil {
+		cfg = &InboundConfig{}
+	}
+	r.mu.Lock()
+	r.cfg = cfg
+	r.mu.Unlock()
+}
+
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	r.mu.RLock()
+	cfg := r.cfg
+	r.mu.RUnlock()
+
+	src := cfg.Source(strings.TrimPrefix(req.URL.Path, HooksPrefix))
+	if src == nil {
+		http.NotFound(w, req)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxInboundBody))
+	if err != nil {
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	secret := src.SecretValue()
+	if secret == "" {
+		// Fail closed: a source whose secret_env is unset accepts nothing.
+		r.logger("webhooks: source %s has no secret; rejecting delivery", src.Name)
+		http.Error(w, "source not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	in, err := r.authenticate(cfg, src, secret, req.Header, body)
+	if errors.Is(err, errIgnored) {
+		writeJSON(w, http.StatusOK, map[string]bool{"ignored": true})
+		return
+	}
+	var authErr *authError
+	if errors.As(err, &authErr) {
+		r.logger("webhooks: rejected delivery to %s: %v", src.Name, err)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	in.Source, in.Kind = src.Name, src.Kind
+	var rules []string
+	for i := range cfg.Rules {
+		if cfg.Rules[i].Matches(in) {
+			rules = append(rules, cfg.Rules[i].Name)
+		}
+	}
+	if len(rules) == 0 {
+		writeJSON(w, http.StatusOK, map[string]bool{"ignored": true})
+		return
+	}
+
+	key := deliveryKey(src, body)
+	fresh, err := r.seen.Claim(key, r.Now())
+	if err != nil {
+		r.logger("webhooks: saving replay store: %v", err)
+	}
+	if !fresh {
+		http.Error(w, "duplicate delivery", http.StatusConflict)
+		return
+	}
+
+	r.pending.Add(1)
+	select {
+	case r.queue <- inboundJob{cfg: cfg, in: in, key: key}:
+	default:
+		r.pending.Done()
+		if err := r.seen.Finish(key, false); err != nil {
+			r.logger("webhooks: saving replay store: %v", err)
+		}
+		r.logger("webhooks: queue full; rejecting %s delivery %s", src.Name, in.Delivery)
+		http.Error(w, "busy", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]interface{}{"rules": rules})
+}
+
+// deliveryKey identifies a delivery for replay protection by a hash of its
+// signed body. Delivery ID headers aren't covered by the signature, so a
+// captured body resent under a fresh ID is still a duplicate.
+func deliveryKey(src *Source, body []byte) string {
+	sum := sha256.Sum256(body)
+	return src.Name + "/" + hex.EncodeToString(sum[:])
+}
+
+// authError marks signature and timestamp failures.
+type authError struct{ err error }
+
+func (e *authError) Error() string { return e.err.Error() }
+
+// authenticate verifies the request signature and normalizes the payload.
+func (r *Receiver) authenticate(cfg *InboundConfig, src *Source, secret string, h http.Header, body []byte) (*Inbound, error) {
+	if src.Kind == KindGeneric {
+		ts, err := strconv.ParseInt(h.Get(HeaderTimestamp), 10, 64)
+		if err != nil {
+			return nil, &authError{fmt.Errorf("missing or invalid %s", HeaderTimestamp)}
+		}
+		if err := Verify(secret, ts, body, h.Get(HeaderSignature), cfg.Skew(), r.Now()); err != nil {
+			return nil, &authError{err}
+		}
+		in, err := parseGeneric(body)
+		if err != nil {
+			return nil, err
+		}
+		if in.Delivery == "" {
+			in.Delivery = h.Get(HeaderDelivery)
+		}
+		return in, nil
+	}
+
+	event, delivery, sig := forgeHeaders(src.Kind, h)
+	if !hmac.Equal([]byte(forgeSignature(src.Kind, secret, body)), []byte(sig)) {
+		return nil, &authError{fmt.Errorf("signature mismatch")}
+	}
+	in, err := parseForge(event, body)
+	if err != nil {
+		return nil, err
+	}
+	in.Delivery = delivery
+	return in, nil
+}
+
+// apply runs every matching rule that has not already completed for
+// delivery key, reporting whether any failed.
+func (r *Receiver) apply(cfg *InboundConfig, in *Inbound, key string) ([]Outcome, bool) {
+	var outcomes []Outcome
+	failed := false
+	for i := range cfg.Rules {
+		rule := &cfg.Rules[i]
+		if !rule.Matches(in) {
+			continue
+		}
+		o := Outcome{Rule: rule.Name, Action: rule.Action, Target: rule.Target}
+		st := r.seen.Rule(key, rule.Name)
+		if st.Done {
+			o.Bead = st.Bead
+			outcomes = append(outcomes, o)
+			continue
+		}
+		var err error
+		switch rule.Action {
+		case ActionBead:
+			o.Bead, err = r.actions.CreateBead(rule.Rig, in.Summary(), in.Description(), rule.BeadPriority())
+		case ActionMail:
+			if o.Target == "" {
+				o.Target = "mayor/"
+			}
+			err = r.actions.Mail(o.Target, in.Summary(), in.Description())
+		case ActionSling:
+			o.Bead = in.Bead
+			if o.Bead == "" {
+				o.Bead = st.Bead
+			}
+			if o.Bead == "" {
+				o.Bead, err = r.actions.CreateBead(rule.Rig, in.Summary(), in.Description(), rule.BeadPriority())
+				if err == nil {
+					// Remember the bead so a retried sling doesn't create another.
+					r.recordRule(key, rule.Name, RuleState{Bead: o.Bead})
+				}
+			}
+			if err == nil {
+				err = r.actions.Sling(o.Bead, rule.Target)
+			}
+		}
+		if err != nil {
+			failed = true
+			o.Error = err.Error()
+			r.logger("webhooks: %s rule %q for %s %s: %v", rule.Action, rule.Name, in.Source, in.Delivery, err)
+		} else {
+			r.recordRule(key, rule.Name, RuleState{Bead: o.Bead, Done: true})
+			r.logger("webhooks: %s %s ran %s rule %q", in.Source, in.Event, rule.Action, rule.Name)
+		}
+		outcomes = append(outcomes, o)
+	}
+	return outcomes, failed
+}
+
+func (r *Receiver) recordRule(key, rule string, st RuleState) {
+	if err := r.seen.SetRule(key, rule, st); err != nil {
+		r.logger("webhooks: saving replay store: %v", err)
+	}
+}
+
+// Summary is a one-line title for beads and mail.
+func (in *Inbound) Summary() string {
+	title := in.Title
+	if title == "" {
+		title = in.Event
+	}
+	switch {
+	case in.Repo != "" && in.Number > 0:
+		return fmt.Sprintf("%s#%d: %s", in.Repo, in.Number, title)
+	case in.Repo != "":
+		return in.Repo + ": " + title
+	}
+	return title
+}
+
+// Description is the bead or mail body: the payload body plus provenance.
+func (in *Inbound) Description() string {
+	var b strings.Builder
+	if in.Body != "" {
+		b.WriteString(in.Body)
+		b.WriteString("\n\n")
+	}
+	fmt.Fprintf(&b, "Source: %s (%s)\n", in.Source, in.Event)
+	if in.URL != "" {
+		fmt.Fprintf(&b, "URL: %s\n", in.URL)
+	}
+	if in.Author != "" {
+		fmt.Fprintf(&b, "Author: %s\n", in.Author)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,199 @@
+package webhooks
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"time"
+)
+
+// Inbound source kinds.
+const (
+	KindGitHub  = "github"
+	KindGitea   = "gitea"
+	KindGeneric = "generic"
+)
+
+// Inbound rule actions.
+const (
+	ActionBead  = "bead"  // Create a bead in the rule's rig
+	ActionMail  = "mail"  // Mail the rule's target (default the mayor)
+	ActionSling = "sling" // Sling a bead (created if the payload names none) to the target
+)
+
+// DefaultMaxSkew is the replay window for generic (Gas Town signed)
+// deliveries.
+const DefaultMaxSkew = 5 * time.Minute
+
+// InboundConfig is the "inbound" section of settings/webhooks.json.
+type InboundConfig struct {
+	// Sources are the accepted senders, served at /hooks/<name>.
+	Sources []Source `json:"sources"`
+
+	// Rules map normalized inbound events to actions. Every matching rule
+	// runs.
+	Rules []Rule `json:"rules"`
+
+	// MaxSkew bounds the age of generic deliveries as a Go duration
+	// (default "5m").
+	MaxSkew string `json:"max_skew,omitempty"`
+}
+
+// Source is an authenticated inbound sender.
+type Source struct {
+	Name string `json:"name"`
+
+	// Kind selects the payload format and signature scheme: "github",
+	// "gitea" or "generic".
+	Kind string `json:"kind"`
+
+	// Secret verifies the HMAC signature; SecretEnv reads it from an
+	// environment variable instead. Required.
+	Secret    string `json:"secret,omitempty"`
+	SecretEnv string `json:"secret_env,omitempty"`
+}
+
+// Rule turns matching inbound events into an action.
+type Rule struct {
+	Name string `json:"name"`
+
+	// Source restricts the rule to one source.
+	Source string `json:"source,omitempty"`
+
+	// Event is a glob over normalized event names: "issue.opened",
+	// "issue_comment.created", "pr_comment.created", "ci.failed", or a
+	// generic payload's type.
+	Event string `json:"event"`
+
+	// Repo, Label and Author are optional globs the event must match.
+	Repo   string `json:"repo,omitempty"`
+	Label  string `json:"label,omitempty"`
+	Author string `json:"author,omitempty"`
+
+	Action string `json:"action"`
+
+	// Rig is where beads are created (required for bead and sling).
+	Rig string `json:"rig,omitempty"`
+
+	// Target is the mail recipient (default "mayor/") or sling target.
+	Target string `json:"target,omitempty"`
+
+	// Priority of created beads, 0-4 (default 2).
+	Priority *int `json:"priority,omitempty"`
+}
+
+// Validate checks sources and rules.
+func (c *InboundConfig) Validate() error {
+	sources := make(map[string]bool)
+	for i, s := range c.Sources {
+		if s.Name == "" {
+			return fmt.Errorf("inbound.sources[%d]: name is required", i)
+		}
+		if sources[s.Name] {
+			return fmt.Errorf("inbound.sources[%d]: duplicate name %q", i, s.Name)
+		}
+		sources[s.Name] = true
+		if !slices.Contains([]string{KindGitHub, KindGitea, KindGeneric}, s.Kind) {
+			return fmt.Errorf("inbound source %q: kind must be github, gitea or generic", s.Name)
+		}
+		if s.Secret == "" && s.SecretEnv == "" {
+			return fmt.Errorf("inbound source %q: secret or secret_env is required", s.Name)
+		}
+	}
+	for i, r := range c.Rules {
+		name := r.Name
+		if name == "" {
+			name = fmt.Sprintf("inbound.rules[%d]", i)
+		}
+		if r.Source != "" && !sources[r.Source] {
+			return fmt.Errorf("%s: unknown source %q", name, r.Source)
+		}
+		for _, glob := range []string{r.Event, r.Repo, r.Label, r.Author} {
+			if _, err := path.Match(glob, ""); err != nil {
+				return fmt.Errorf("%s: bad pattern %q", name, glob)
+			}
+		}
+		if r.Event == "" {
+			return fmt.Errorf("%s: event is required", name)
+		}
+		switch r.Action {
+		case ActionMail:
+		case ActionBead, ActionSling:
+			if r.Rig == "" {
+				return fmt.Errorf("%s: rig is required for %s", name, r.Action)
+			}
+			if r.Action == ActionSling && r.Target == "" {
+				return fmt.Errorf("%s: target is required for sling", name)
+			}
+		default:
+			return fmt.Errorf("%s: action must be bead, mail or sling", name)
+		}
+		if r.Priority != nil && (*r.Priority < 0 || *r.Priority > 4) {
+			return fmt.Errorf("%s: priority must be 0-4", name)
+		}
+	}
+	if c.MaxSkew != "" {
+		if d, err := time.ParseDuration(c.MaxSkew); err != nil || d <= 0 {
+			return fmt.Errorf("inbound: invalid max_skew %q", c.MaxSkew)
+		}
+	}
+	return nil
+}
+
+// Source returns the named source, or nil.
+func (c *InboundConfig) Source(name string) *Source {
+	for i := range c.Sources {
+		if c.Sources[i].Name == name {
+			return &c.Sources[i]
+		}
+	}
+	return nil
+}
+
+// Skew returns the replay window for generic deliveries.
+func (c *InboundConfig) Skew() time.Duration {
+	if d, err := time.ParseDuration(c.MaxSkew); err == nil && d > 0 {
+		return d
+	}
+	return DefaultMaxSkew
+}
+
+// SecretValue returns the source's verification secret.
+func (s *Source) SecretValue() string {
+	if s.SecretEnv != "" {
+		return os.Getenv(s.SecretEnv)
+	}
+	return s.Secret
+}
+
+// Matches reports whether the rule selects an inbound event.
+func (r *Rule) Matches(in *Inbound) bool {
+	if r.Source != "" && r.Source != in.Source {
+		return false
+	}
+	if !globMatch(r.Event, in.Event) || !globMatch(r.Repo, in.Repo) || !globMatch(r.Author, in.Author) {
+		return false
+	}
+	if r.Label != "" && !slices.ContainsFunc(in.Labels, func(l string) bool { return globMatch(r.Label, l) }) {
+		return false
+	}
+	return true
+}
+
+// BeadPriority returns the priority for beads the rule creates.
+func (r *Rule) BeadPriority() int {
+	if r.Priority != nil {
+		return *r.Priority
+	}
+	return 2
+}
+
+// globMatch matches value against an optional glob; "" matches anything.
+func globMatch(pattern, value string) bool {
+	if pattern == "" {
+		return true
+	}
+	ok, _ := path.Match(pattern, value)
+	return ok
+}
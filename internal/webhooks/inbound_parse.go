@@ -0,0 +1,250 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// errIgnored marks deliveries that are valid but carry nothing to act on
+// (pings, successful CI runs, unsupported event types).
+var errIgnored = errors.New("event ignored")
+
+// Inbound is an external webhook normalized across sources.
+type Inbound struct {
+	Source string `json:"source"`
+	Kind   string `json:"kind"`
+
+	// Event is the normalized event name, e.g. "issue.opened",
+	// "pr_comment.created", "ci.failed".
+	Event string `json:"event"`
+
+	// Delivery is the sender's unique delivery ID, used for replay
+	// protection.
+	Delivery string `json:"delivery"`
+
+	Repo   string   `json:"repo,omitempty"`
+	Number int      `json:"number,omitempty"`
+	Title  string   `json:"title"`
+	Body   string   `json:"body,omitempty"`
+	URL    string   `json:"url,omitempty"`
+	Author string   `json:"author,omitempty"`
+	Labels []string `json:"labels,omitempty"`
+
+	// Bead is an existing bead to act on (generic payloads only).
+	Bead string `json:"bead,omitempty"`
+}
+
+// forgePayload covers the GitHub and Gitea fields we use; the two APIs are
+// compatible for issues, comments and statuses.
+type forgePayload struct {
+	Action string `json:"action"`
+	Issue  *struct {
+		Number      int    `json:"number"`
+		Title       string `json:"title"`
+		Body        string `json:"body"`
+		HTMLURL     string `json:"html_url"`
+		PullRequest *struct {
+			URL string `json:"url"`
+		} `json:"pull_request"`
+		Labels []struct {
+			Name string `json:"name"`
+		} `json:"labels"`
+	} `json:"issue"`
+	PullRequest *struct {
+		Number  int    `json:"number"`
+		Title   string `json:"title"`
+		HTMLURL string `json:"html_url"`
+	} `json:"pull_request"`
+	Comment *struct {
+		Body    string `json:"body"`
+		HTMLURL string `json:"html_url"`
+	} `json:"comment"`
+	IsPull     bool `json:"is_pull"` // Gitea: issue_comment on a PR
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+	Sender struct {
+		Login string `json:"login"`
+	} `json:"sender"`
+
+	// CI: GitHub check_run / workflow_run, commit status (both).
+	CheckRun *struct {
+		Name       string `json:"name"`
+		Conclusion string `json:"conclusion"`
+		HTMLURL    string `json:"html_url"`
+		HeadBranch string `json:"head_branch"`
+		CheckSuite struct {
+			HeadBranch string `json:"head_branch"`
+		} `json:"check_suite"`
+	} `json:"check_run"`
+	WorkflowRun *struct {
+		Name       string `json:"name"`
+		Conclusion string `json:"conclusion"`
+		HTMLURL    string `json:"html_url"`
+		HeadBranch string `json:"head_branch"`
+	} `json:"workflow_run"`
+	State       string `json:"state"`
+	Context     string `json:"context"`
+	Description string `json:"description"`
+	TargetURL   string `json:"target_url"`
+	SHA         string `json:"sha"`
+}
+
+// parseForge normalizes a GitHub or Gitea payload. eventType is the
+// X-GitHub-Event / X-Gitea-Event header.
+func parseForge(eventType string, body []byte) (*Inbound, error) {
+	if eventType == "ping" {
+		return nil, errIgnored
+	}
+	var p forgePayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, fmt.Errorf("parsing %s payload: %w", eventType, err)
+	}
+	in := &Inbound{Repo: p.Repository.FullName, Author: p.Sender.Login}
+
+	switch eventType {
+	case "issues":
+		if p.Issue == nil {
+			return nil, fmt.Errorf("issues event without issue")
+		}
+		in.Event = "issue." + p.Action
+		in.Number, in.Title, in.Body, in.URL = p.Issue.Number, p.Issue.Title, p.Issue.Body, p.Issue.HTMLURL
+		for _, l := range p.Issue.Labels {
+			in.Labels = append(in.Labels, l.Name)
+		}
+
+	case "issue_comment", "pull_request_comment", "pull_request_review_comment":
+		if p.Comment == nil {
+			return nil, fmt.Errorf("%s event without comment", eventType)
+		}
+		kind := "issue_comment"
+		if eventType != "issue_comment" || p.IsPull || (p.Issue != nil && p.Issue.PullRequest != nil) {
+			kind = "pr_comment"
+		}
+		in.Event = kind + "." + p.Action
+		in.Body, in.URL = p.Comment.Body, p.Comment.HTMLURL
+		switch {
+		case p.Issue != nil:
+			in.Number, in.Title = p.Issue.Number, p.Issue.Title
+			for _, l := range p.Issue.Labels {
+				in.Labels = append(in.Labels, l.Name)
+			}
+		case p.PullRequest != nil:
+			in.Number, in.Title = p.PullRequest.Number, p.PullRequest.Title
+		}
+
+	case "check_run":
+		if p.CheckRun == nil || !ciFailed(p.CheckRun.Conclusion) {
+			return nil, errIgnored
+		}
+		branch := p.CheckRun.HeadBranch
+		if branch == "" {
+			branch = p.CheckRun.CheckSuite.HeadBranch
+		}
+		in.Event = "ci.failed"
+		in.Title = ciTitle(p.CheckRun.Name, branch)
+		in.Body = "Conclusion: " + p.CheckRun.Conclusion
+		in.URL = p.CheckRun.HTMLURL
+
+	case "workflow_run":
+		if p.WorkflowRun == nil || p.Action != "completed" || !ciFailed(p.WorkflowRun.Conclusion) {
+			return nil, errIgnored
+		}
+		in.Event = "ci.failed"
+		in.Title = ciTitle(p.WorkflowRun.Name, p.WorkflowRun.HeadBranch)
+		in.Body = "Conclusion: " + p.WorkflowRun.Conclusion
+		in.URL = p.WorkflowRun.HTMLURL
+
+	case "status":
+		if p.State != "failure" && p.State != "error" {
+			return nil, errIgnored
+		}
+		in.Event = "ci.failed"
+		sha := p.SHA
+		if len(sha) > 8 {
+			sha = sha[:8]
+		}
+		in.Title = fmt.Sprintf("CI failed: %s on %s", p.Context, sha)
+		in.Body = p.Description
+		in.URL = p.TargetURL
+
+	default:
+		return nil, errIgnored
+	}
+	return in, nil
+}
+
+func ciFailed(conclusion string) bool {
+	switch conclusion {
+	case "failure", "timed_out", "startup_failure":
+		return true
+	}
+	return false
+}
+
+func ciTitle(name, branch string) string {
+	if branch == "" {
+		return "CI failed: " + name
+	}
+	return fmt.Sprintf("CI failed: %s on %s", name, branch)
+}
+
+// GenericPayload is the schema accepted from generic sources.
+type GenericPayload struct {
+	// ID uniquely identifies the delivery (or use X-Gastown-Delivery).
+	ID     string   `json:"id"`
+	Type   string   `json:"type"`
+	Title  string   `json:"title"`
+	Body   string   `json:"body,omitempty"`
+	Repo   string   `json:"repo,omitempty"`
+	URL    string   `json:"url,omitempty"`
+	Author string   `json:"author,omitempty"`
+	Labels []string `json:"labels,omitempty"`
+	Bead   string   `json:"bead,omitempty"`
+}
+
+func parseGeneric(body []byte) (*Inbound, error) {
+	var p GenericPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, fmt.Errorf("parsing generic payload: %w", err)
+	}
+	if p.Type == "" {
+		return nil, fmt.Errorf("generic payload: type is required")
+	}
+	if p.Title == "" && p.Bead == "" {
+		return nil, fmt.Errorf("generic payload: title or bead is required")
+	}
+	return &Inbound{
+		Event:    p.Type,
+		Delivery: p.ID,
+		Repo:     p.Repo,
+		Title:    p.Title,
+		Body:     p.Body,
+		URL:      p.URL,
+		Author:   p.Author,
+		Labels:   p.Labels,
+		Bead:     p.Bead,
+	}, nil
+}
+
+// forgeHeaders returns the event type, delivery ID and signature headers
+// for a forge request.
+func forgeHeaders(kind string, h http.Header) (event, delivery, signature string) {
+	if kind == KindGitea {
+		return h.Get("X-Gitea-Event"), h.Get("X-Gitea-Delivery"), h.Get("X-Gitea-Signature")
+	}
+	return h.Get("X-GitHub-Event"), h.Get("X-GitHub-Delivery"), h.Get("X-Hub-Signature-256")
+}
+
+// forgeSignature computes a forge's body signature: GitHub prefixes the
+// hex HMAC with "sha256=", Gitea sends it bare.
+func forgeSignature(kind, secret string, body []byte) string {
+	sig := SignBody(secret, body)
+	if kind == KindGitea {
+		return strings.TrimPrefix(sig, "sha256=")
+	}
+	return sig
+}
@@ -0,0 +1,354 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeActions struct {
+	beads  []string
+	mails  []string
+	slings []string
+	fail   bool
+
+	failSling bool
+}
+
+func (f *fakeActions) CreateBead(rig, title, description string, priority int) (string, error) {
+	if f.fail {
+		return "", fmt.Errorf("bd unavailable")
+	}
+	id := fmt.Sprintf("%s-%d", rig, len(f.beads)+1)
+	f.beads = append(f.beads, fmt.Sprintf("%s P%d %s", id, priority, title))
+	return id, nil
+}
+
+func (f *fakeActions) Mail(to, subject, body string) error {
+	f.mails = append(f.mails, to+" "+subject)
+	return nil
+}
+
+func (f *fakeActions) Sling(bead, target string) error {
+	if f.failSling {
+		return fmt.Errorf("sling failed")
+	}
+	f.slings = append(f.slings, bead+" "+target)
+	return nil
+}
+
+func newTestReceiver(t *testing.T, actions Actions) *Receiver {
+	t.Helper()
+	p1 := 1
+	cfg := &InboundConfig{
+		Sources: []Source{
+			{Name: "gh", Kind: KindGitHub, Secret: "ghs"},
+			{Name: "tea", Kind: KindGitea, Secret: "teas"},
+			{Name: "ops", Kind: KindGeneric, Secret: "opss"},
+		},
+		Rules: []Rule{
+			{Name: "bugs", Source: "gh", Event: "issue.opened", Label: "bug", Action: ActionBead, Rig: "gastown", Priority: &p1},
+			{Name: "ci", Event: "ci.failed", Repo: "acme/*", Action: ActionMail},
+			{Name: "pages", Source: "ops", Event: "page", Action: ActionSling, Rig: "ops", Target: "ops/polecats"},
+		},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	seen, err := NewSeenStore(filepath.Join(t.TempDir(), "seen.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewReceiver(cfg, actions, seen, t.Logf)
+	t.Cleanup(r.Close)
+	return r
+}
+
+func post(t *testing.T, h http.Handler, path string, headers map[string]string, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if r, ok := h.(*Receiver); ok {
+		// Let the worker apply the delivery before the test looks.
+		r.pending.Wait()
+	}
+	return rec
+}
+
+func TestReceiver_GitHubIssue(t *testing.T) {
+	fa := &fakeActions{}
+	r := newTestReceiver(t, fa)
+	body := `{"action":"opened","issue":{"number":7,"title":"Crash on boot","labels":[{"name":"bug"}]},"repository":{"full_name":"acme/app"},"sender":{"login":"ann"}}`
+	headers := map[string]string{
+		"X-GitHub-Event":      "issues",
+		"X-GitHub-Delivery":   "d1",
+		"X-Hub-Signature-256": SignBody("ghs", []byte(body)),
+	}
+
+	rec := post(t, r, "/hooks/gh", headers, body)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	if len(fa.beads) != 1 || fa.beads[0] != "gastown-1 P1 acme/app#7: Crash on boot" {
+		t.Errorf("beads = %v", fa.beads)
+	}
+
+	if rec := post(t, r, "/hooks/gh", headers, body); rec.Code != http.StatusConflict {
+		t.Errorf("replay status = %d, want 409", rec.Code)
+	}
+	// The delivery header isn't signed: a fresh one doesn't make a replay new.
+	headers["X-GitHub-Delivery"] = "d2"
+	if rec := post(t, r, "/hooks/gh", headers, body); rec.Code != http.StatusConflict {
+		t.Errorf("replay with new delivery id status = %d, want 409", rec.Code)
+	}
+
+	headers["X-GitHub-Delivery"] = "d3"
+	headers["X-Hub-Signature-256"] = SignBody("wrong", []byte(body))
+	if rec := post(t, r, "/hooks/gh", headers, body); rec.Code != http.StatusUnauthorized {
+		t.Errorf("bad signature status = %d, want 401", rec.Code)
+	}
+	if len(fa.beads) != 1 {
+		t.Errorf("rejected deliveries created beads: %v", fa.beads)
+	}
+}
+
+func TestReceiver_GiteaStatusFailure(t *testing.T) {
+	fa := &fakeActions{}
+	r := newTestReceiver(t, fa)
+	body := `{"state":"failure","context":"ci/build","sha":"0123456789abcdef","repository":{"full_name":"acme/lib"}}`
+	rec := post(t, r, "/hooks/tea", map[string]string{
+		"X-Gitea-Event":     "status",
+		"X-Gitea-Delivery":  "g1",
+		"X-Gitea-Signature": strings.TrimPrefix(SignBody("teas", []byte(body)), "sha256="),
+	}, body)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	if len(fa.mails) != 1 || fa.mails[0] != "mayor/ acme/lib: CI failed: ci/build on 01234567" {
+		t.Errorf("mails = %v", fa.mails)
+	}
+
+	ok := `{"state":"success","context":"ci/build","repository":{"full_name":"acme/lib"}}`
+	rec = post(t, r, "/hooks/tea", map[string]string{
+		"X-Gitea-Event":     "status",
+		"X-Gitea-Delivery":  "g2",
+		"X-Gitea-Signature": strings.TrimPrefix(SignBody("teas", []byte(ok)), "sha256="),
+	}, ok)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "ignored") {
+		t.Errorf("success status: %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestReceiver_GenericSkewAndSling(t *testing.T) {
+	fa := &fakeActions{}
+	r := newTestReceiver(t, fa)
+	now := time.Unix(1_700_000_000, 0)
+	r.Now = func() time.Time { return now }
+
+	body := `{"id":"p1","type":"page","title":"Disk full on db1"}`
+	signed := func(ts time.Time) map[string]string {
+		return map[string]string{
+			HeaderTimestamp: strconv.FormatInt(ts.Unix(), 10),
+			HeaderSignature: Sign("opss", ts.Unix(), []byte(body)),
+		}
+	}
+
+	if rec := post(t, r, "/hooks/ops", signed(now.Add(-time.Hour)), body); rec.Code != http.StatusUnauthorized {
+		t.Errorf("stale delivery status = %d, want 401", rec.Code)
+	}
+	rec := post(t, r, "/hooks/ops", signed(now), body)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	var resp struct{ Rules []string }
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Rules) != 1 || resp.Rules[0] != "pages" {
+		t.Errorf("rules = %+v", resp.Rules)
+	}
+	if len(fa.slings) != 1 || fa.slings[0] != "ops-1 ops/polecats" {
+		t.Errorf("slings = %v", fa.slings)
+	}
+}
+
+func TestReceiver_FailureAllowsRetry(t *testing.T) {
+	fa := &fakeActions{fail: true}
+	r := newTestReceiver(t, fa)
+	body := `{"action":"opened","issue":{"number":1,"title":"x","labels":[{"name":"bug"}]},"repository":{"full_name":"acme/app"}}`
+	headers := map[string]string{
+		"X-GitHub-Event":      "issues",
+		"X-GitHub-Delivery":   "d1",
+		"X-Hub-Signature-256": SignBody("ghs", []byte(body)),
+	}
+	if rec := post(t, r, "/hooks/gh", headers, body); rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202", rec.Code)
+	}
+	fa.fail = false
+	if rec := post(t, r, "/hooks/gh", headers, body); rec.Code != http.StatusAccepted {
+		t.Errorf("redelivery status = %d, want 202", rec.Code)
+	}
+	if len(fa.beads) != 1 {
+		t.Errorf("beads = %v", fa.beads)
+	}
+}
+
+func TestReceiver_RetryRunsOnlyFailedRules(t *testing.T) {
+	fa := &fakeActions{failSling: true}
+	r := newTestReceiver(t, fa)
+	now := time.Unix(1_700_000_000, 0)
+	r.Now = func() time.Time { return now }
+	body := `{"id":"p2","type":"page","title":"Disk full on db2"}`
+	headers := map[string]string{
+		HeaderTimestamp: strconv.FormatInt(now.Unix(), 10),
+		HeaderSignature: Sign("opss", now.Unix(), []byte(body)),
+	}
+
+	if rec := post(t, r, "/hooks/ops", headers, body); rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202", rec.Code)
+	}
+	fa.failSling = false
+	if rec := post(t, r, "/hooks/ops", headers, body); rec.Code != http.StatusAccepted {
+		t.Fatalf("retry status = %d: %s", rec.Code, rec.Body)
+	}
+	if len(fa.beads) != 1 {
+		t.Errorf("retry created another bead: %v", fa.beads)
+	}
+	if len(fa.slings) != 1 || fa.slings[0] != "ops-1 ops/polecats" {
+		t.Errorf("slings = %v", fa.slings)
+	}
+	if rec := post(t, r, "/hooks/ops", headers, body); rec.Code != http.StatusConflict {
+		t.Errorf("replay after success status = %d, want 409", rec.Code)
+	}
+}
+
+// blockingActions holds every sling until release is closed.
+type blockingActions struct {
+	fakeActions
+	release chan struct{}
+}
+
+func (b *blockingActions) Sling(bead, target string) error {
+	<-b.release
+	return b.fakeActions.Sling(bead, target)
+}
+
+func TestReceiver_AcknowledgesBeforeActionsRun(t *testing.T) {
+	ba := &blockingActions{release: make(chan struct{})}
+	r := newTestReceiver(t, ba)
+	now := time.Unix(1_700_000_000, 0)
+	r.Now = func() time.Time { return now }
+	body := `{"id":"p3","type":"page","title":"Disk full on db3"}`
+	req := httptest.NewRequest(http.MethodPost, "/hooks/ops", strings.NewReader(body))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(HeaderSignature, Sign("opss", now.Unix(), []byte(body)))
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	close(ba.release)
+	r.pending.Wait()
+	if len(ba.slings) != 1 {
+		t.Errorf("slings = %v", ba.slings)
+	}
+}
+
+func TestReceiver_UnknownSourceAndMethod(t *testing.T) {
+	r := newTestReceiver(t, &fakeActions{})
+	if rec := post(t, r, "/hooks/nope", nil, "{}"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown source status = %d", rec.Code)
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/hooks/gh", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET status = %d", rec.Code)
+	}
+}
+
+func TestParseForge_PRComment(t *testing.T) {
+	in, err := parseForge("issue_comment", []byte(`{"action":"created","issue":{"number":3,"title":"Add x","pull_request":{"url":"u"}},"comment":{"body":"please fix"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if in.Event != "pr_comment.created" || in.Body != "please fix" {
+		t.Errorf("inbound = %+v", in)
+	}
+	if _, err := parseForge("ping", []byte(`{}`)); err != errIgnored {
+		t.Errorf("ping err = %v", err)
+	}
+}
+
+func TestSeenStore_Persists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "seen.json")
+	s, _ := NewSeenStore(path)
+	now := time.Now()
+	if ok, err := s.Claim("a", now); !ok || err != nil {
+		t.Fatalf("first claim = %v, %v", ok, err)
+	}
+	if err := s.SetRule("a", "r1", RuleState{Bead: "gt-1", Done: true}); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := s.Claim("a", now); ok {
+		t.Error("claimed a delivery that is still being applied")
+	}
+	if err := s.Finish("a", false); err != nil {
+		t.Fatal(err)
+	}
+
+	// An unfinished delivery survives a restart with its rule progress.
+	s2, err := NewSeenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := s2.Claim("a", now); !ok {
+		t.Error("unfinished delivery should be claimable for a retry")
+	}
+	if st := s2.Rule("a", "r1"); !st.Done || st.Bead != "gt-1" {
+		t.Errorf("rule state = %+v", st)
+	}
+	if err := s2.Finish("a", true); err != nil {
+		t.Fatal(err)
+	}
+
+	s2, err = NewSeenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := s2.Claim("a", now); ok {
+		t.Error("reloaded store accepted a replay")
+	}
+	if ok, _ := s2.Claim("a", now.Add(SeenTTL+time.Hour)); !ok {
+		t.Error("expired id should be claimable")
+	}
+}
+
+func TestInboundConfig_Validate(t *testing.T) {
+	cfg := &InboundConfig{
+		Sources: []Source{{Name: "gh", Kind: KindGitHub}},
+	}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "secret") {
+		t.Errorf("missing secret: %v", err)
+	}
+	cfg.Sources[0].Secret = "s"
+	cfg.Rules = []Rule{{Name: "r", Event: "issue.*", Action: ActionBead}}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "rig") {
+		t.Errorf("bead without rig: %v", err)
+	}
+	cfg.Rules[0].Source = "other"
+	cfg.Rules[0].Rig = "x"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "unknown source") {
+		t.Errorf("unknown source: %v", err)
+	}
+}
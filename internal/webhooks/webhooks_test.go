@@ -0,0 +1,332 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/eventbus"
+)
+
+func noBackoff(int) time.Duration { return 0 }
+
+func TestLoadConfig(t *testing.T) {
+	townRoot := t.TempDir()
+	cfg, err := LoadConfig(townRoot)
+	if err != nil || len(cfg.Endpoints) != 0 {
+		t.Fatalf("missing config: %+v, %v", cfg, err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(townRoot, "settings"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(s string) {
+		if err := os.WriteFile(ConfigPath(townRoot), []byte(s), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(`{"endpoints":[{"name":"a","url":"ftp://x"}]}`)
+	if _, err := LoadConfig(townRoot); err == nil || !strings.Contains(err.Error(), "http(s)") {
+		t.Errorf("bad scheme: err = %v", err)
+	}
+	write(`{"endpoints":[{"name":"a","url":"http://x"},{"name":"a","url":"http://y"}]}`)
+	if _, err := LoadConfig(townRoot); err == nil || !strings.Contains(err.Error(), "duplicate") {
+		t.Errorf("duplicate name: err = %v", err)
+	}
+	write(`{"endpoints":[{"name":"a","url":"http://x","timeout":"soon"}]}`)
+	if _, err := LoadConfig(townRoot); err == nil {
+		t.Error("invalid timeout should be rejected")
+	}
+}
+
+func TestEndpoint_Wants(t *testing.T) {
+	def := Endpoint{Name: "d"}
+	if !def.Wants(eventbus.Event{Type: eventbus.TypeConvoyClosed}) {
+		t.Error("default endpoint should want convoy_closed")
+	}
+	if def.Wants(eventbus.Event{Type: eventbus.TypeNudge}) {
+		t.Error("default endpoint should not want nudge")
+	}
+	all := Endpoint{Name: "all", Events: []string{"*"}}
+	if !all.Wants(eventbus.Event{Type: eventbus.TypeNudge, Visibility: eventbus.VisibilityFeed}) {
+		t.Error("* should want feed events")
+	}
+	if all.Wants(eventbus.Event{Type: eventbus.TypeKill, Visibility: eventbus.VisibilityAudit}) {
+		t.Error("* should not want audit events")
+	}
+	off := Endpoint{Name: "off", Disabled: true}
+	if off.Wants(eventbus.Event{Type: eventbus.TypeDone}) {
+		t.Error("disabled endpoint wants nothing")
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	body := []byte(`{"v":1}`)
+	sig := Sign("s3cret", now.Unix(), body)
+
+	if err := Verify("s3cret", now.Unix(), body, sig, 5*time.Minute, now); err != nil {
+		t.Errorf("valid signature rejected: %v", err)
+	}
+	if err := Verify("other", now.Unix(), body, sig, 5*time.Minute, now); err == nil {
+		t.Error("wrong secret accepted")
+	}
+	if err := Verify("s3cret", now.Unix(), []byte(`{"v":2}`), sig, 5*time.Minute, now); err == nil {
+		t.Error("tampered body accepted")
+	}
+	if err := Verify("s3cret", now.Unix(), body, sig, 5*time.Minute, now.Add(time.Hour)); err == nil {
+		t.Error("stale timestamp accepted")
+	}
+}
+
+func TestSender_SignsAndRetries(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		ts, _ := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+		if err := Verify("k", ts, body, r.Header.Get(HeaderSignature), time.Minute, time.Now()); err != nil {
+			t.Errorf("attempt %d: %v", n, err)
+		}
+		if r.Header.Get(HeaderEvent) != eventbus.TypeMerged || r.Header.Get("X-Team") != "infra" {
+			t.Errorf("headers = %v", r.Header)
+		}
+		if n < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+
+	ep := &Endpoint{Name: "ci", URL: srv.URL, Secret: "k", Headers: map[string]string{"X-Team": "infra"}}
+	s := &Sender{Client: srv.Client(), Backoff: noBackoff}
+	r := s.Deliver(context.Background(), ep, NewPayload(eventbus.Event{Type: eventbus.TypeMerged}))
+	if r.Err != nil || r.Attempts != 3 || r.Status != http.StatusOK {
+		t.Errorf("Deliver = %+v", r)
+	}
+}
+
+func TestSender_NoRetryOnClientError(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	s := &Sender{Client: srv.Client(), Backoff: noBackoff}
+	r := s.Deliver(context.Background(), &Endpoint{Name: "x", URL: srv.URL}, NewPayload(eventbus.Event{Type: eventbus.TypeDone}))
+	if r.Err == nil || r.Attempts != 1 || calls.Load() != 1 {
+		t.Errorf("Deliver = %+v after %d calls", r, calls.Load())
+	}
+}
+
+func TestDispatcher_DeliversAndDeadLetters(t *testing.T) {
+	townRoot := t.TempDir()
+
+	var mu sync.Mutex
+	var got []Payload
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p Payload
+		_ = json.NewDecoder(r.Body).Decode(&p)
+		mu.Lock()
+		got = append(got, p)
+		mu.Unlock()
+	}))
+	defer ok.Close()
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+
+	cfg := &Config{Endpoints: []Endpoint{
+		{Name: "ok", URL: ok.URL, Events: []string{eventbus.TypeMerged}},
+		{Name: "down", URL: down.URL, MaxAttempts: 2},
+	}}
+	d := NewDispatcher(townRoot, cfg, t.Logf)
+	d.sender.Backoff = noBackoff
+	d.Start()
+
+	publish(t, townRoot, eventbus.Event{ID: "e1", Type: eventbus.TypeMerged, Visibility: eventbus.VisibilityFeed})
+	publish(t, townRoot, eventbus.Event{ID: "e2", Type: eventbus.TypeNudge, Visibility: eventbus.VisibilityFeed})
+
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		dead, _ := ReadDeadLetters(townRoot)
+		mu.Lock()
+		n := len(got)
+		mu.Unlock()
+		if n == 1 && len(dead) == 1 {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	d.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 1 || got[0].Event.ID != "e1" || got[0].Version != PayloadVersion {
+		t.Errorf("ok endpoint got %+v", got)
+	}
+	dead, err := ReadDeadLetters(townRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dead) != 1 || dead[0].Endpoint != "down" || dead[0].Attempts != 2 || dead[0].Status != http.StatusServiceUnavailable {
+		t.Fatalf("dead letters = %+v", dead)
+	}
+
+	if err := RemoveDeadLetters(townRoot, map[string]bool{dead[0].Payload.Delivery: true}); err != nil {
+		t.Fatal(err)
+	}
+	if dead, _ := ReadDeadLetters(townRoot); len(dead) != 0 {
+		t.Errorf("after remove: %+v", dead)
+	}
+}
+
+func TestRemoveDeadLetters_WaitsForFileLock(t *testing.T) {
+	townRoot := t.TempDir()
+	if err := AppendDeadLetter(townRoot, DeadLetter{Endpoint: "ci", Payload: Payload{Delivery: "d1"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another process (the daemon) holds the lock while appending.
+	other := flock.New(DeadLetterPath(townRoot) + ".lock")
+	if err := other.Lock(); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- RemoveDeadLetters(townRoot, map[string]bool{"d1": true}) }()
+
+	select {
+	case err := <-done:
+		t.Fatalf("RemoveDeadLetters finished while the file was locked: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	f, err := os.OpenFile(DeadLetterPath(townRoot), os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(DeadLetter{Endpoint: "ci", Payload: Payload{Delivery: "d2"}})
+	_, _ = f.Write(append(data, '\n'))
+	f.Close()
+	_ = other.Unlock()
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	dead, err := ReadDeadLetters(townRoot)
+	if err != nil || len(dead) != 1 || dead[0].Payload.Delivery != "d2" {
+		t.Errorf("dead letters = %+v, %v; want only d2", dead, err)
+	}
+}
+
+func publish(t *testing.T, townRoot string, ev eventbus.Event) {
+	t.Helper()
+	if err := eventbus.Emit(townRoot, ev, eventbus.SinkEvents); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDispatcher_ResumesAfterRestart(t *testing.T) {
+	townRoot := t.TempDir()
+
+	var mu sync.Mutex
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p Payload
+		_ = json.NewDecoder(r.Body).Decode(&p)
+		mu.Lock()
+		got = append(got, p.Event.ID)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+	cfg := &Config{Endpoints: []Endpoint{{Name: "ci", URL: srv.URL, Events: []string{eventbus.TypeMerged}}}}
+
+	waitFor := func(n int) {
+		t.Helper()
+		deadline := time.Now().Add(3 * time.Second)
+		for time.Now().Before(deadline) {
+			mu.Lock()
+			done := len(got) >= n
+			mu.Unlock()
+			if done {
+				return
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		t.Fatalf("delivered %v, want %d", got, n)
+	}
+
+	d := NewDispatcher(townRoot, cfg, t.Logf)
+	d.Start()
+	publish(t, townRoot, eventbus.Event{ID: "e1", Type: eventbus.TypeMerged})
+	waitFor(1)
+	d.Stop()
+
+	// Events logged while the daemon is down are delivered on restart,
+	// and nothing is delivered twice.
+	publish(t, townRoot, eventbus.Event{ID: "e2", Type: eventbus.TypeMerged})
+	d = NewDispatcher(townRoot, cfg, t.Logf)
+	d.Start()
+	waitFor(2)
+	time.Sleep(2 * pollInterval)
+	d.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Join(got, ",") != "e1,e2" {
+		t.Errorf("delivered %v, want [e1 e2]", got)
+	}
+}
+
+func TestDispatcher_DeadLettersForRemovedEndpoints(t *testing.T) {
+	townRoot := t.TempDir()
+	d := NewDispatcher(townRoot, &Config{Endpoints: []Endpoint{
+		{Name: "off", URL: "http://127.0.0.1:1/hook"},
+		{Name: "gone", URL: "http://127.0.0.1:1/hook"},
+	}}, t.Logf)
+
+	// Deliveries queued before a reload disabled one endpoint and removed
+	// the other.
+	queues := map[string]chan Payload{"off": make(chan Payload, 1), "gone": make(chan Payload, 1)}
+	queues["off"] <- NewPayload(eventbus.Event{ID: "e1", Type: eventbus.TypeMerged})
+	queues["gone"] <- NewPayload(eventbus.Event{ID: "e2", Type: eventbus.TypeMerged})
+	d.UpdateConfig(&Config{Endpoints: []Endpoint{{Name: "off", URL: "http://127.0.0.1:1/hook", Disabled: true}}})
+	for name, q := range queues {
+		d.wg.Add(1)
+		go d.worker(name, q)
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if dead, _ := ReadDeadLetters(townRoot); len(dead) == 2 {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	d.Stop()
+
+	dead, err := ReadDeadLetters(townRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reasons := make(map[string]string)
+	for _, dl := range dead {
+		reasons[dl.Endpoint] = dl.Error
+	}
+	if len(dead) != 2 || !strings.Contains(reasons["off"], "disabled") || !strings.Contains(reasons["gone"], "removed") {
+		t.Errorf("dead letters = %+v", dead)
+	}
+}
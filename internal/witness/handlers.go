@@ -149,13 +149,12 @@ func HandleLifecycleShutdown(workDir, rigName string, msg *mail.Message) *Handle
 		ProtocolType: ProtoLifecycleShutdown,
 	}
 
-	// Extract polecat name from subject
-	matches := PatternLifecycleShutdown.FindStringSubmatch(msg.Subject)
-	if len(matches) < 2 {
-		result.Error = fmt.Errorf("invalid LIFECYCLE:Shutdown subject: %s", msg.Subject)
+	payload, err := ParseLifecycleShutdown(msg.Subject, msg.Body)
+	if err != nil {
+		result.Error = err
 		return result
 	}
-	polecatName := matches[1]
+	polecatName := payload.PolecatName
 
 	// Shutdown means no pending work - try to auto-nuke immediately
 	nukeResult := AutoNukeIfClean(workDir, rigName, polecatName)
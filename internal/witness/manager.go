@@ -177,8 +177,8 @@ func (m *Manager) Start(foreground bool, agentOverride string, envOverrides []st
 		return fmt.Errorf("waiting for witness to start: %w", err)
 	}
 
-	// Accept bypass permissions warning dialog if it appears.
-	_ = t.AcceptBypassPermissionsWarning(sessionID)
+	// Answer the runtime's trust and permission dialogs (each answer is logged).
+	_, _ = runtime.AnswerStartupPrompts(t, sessionID, m.rig.Name+"/witness", runtimeConfig)
 
 	time.Sleep(constants.ShutdownNotifyDelay)
 
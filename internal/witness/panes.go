@@ -0,0 +1,90 @@
+package witness
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+// PolecatPane is one polecat's pane classification from a survey.
+type PolecatPane struct {
+	Polecat string         `json:"polecat"`
+	Session string         `json:"session"`
+	State   tmux.PaneState `json:"state"`
+	Detail  string         `json:"detail,omitempty"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// SurveyPanes classifies the output of every running polecat session in the
+// rig, using the rig's polecat runtime patterns. A polecat whose session
+// starts to look stuck (a dialog, a question, an error loop or repeated
+// output) is logged as a pane_state event, so the feed shows it without
+// anyone watching the pane.
+//
+// Polecats without a session are skipped; DetectZombiePolecats covers them.
+func (m *Manager) SurveyPanes() ([]PolecatPane, error) {
+	townRoot := filepath.Dir(m.rig.Path)
+	classifier, err := tmux.NewPaneClassifier(config.ResolveRoleAgentConfig(constants.RolePolecat, townRoot, m.rig.Path))
+	if err != nil {
+		return nil, fmt.Errorf("loading pane patterns: %w", err)
+	}
+
+	t := tmux.NewTmux()
+	panes := surveyPanes(m.rig.Name, m.polecatNames(), t.HasSession, func(session string) (tmux.PaneClassification, error) {
+		return t.ClassifyPane(session, classifier)
+	})
+	for _, p := range panes {
+		if p.Error != "" {
+			continue
+		}
+		state := ""
+		if p.State.Stuck() {
+			state = string(p.State)
+		}
+		agent := fmt.Sprintf("%s/polecats/%s", m.rig.Name, p.Polecat)
+		_ = events.LogPaneState(townRoot, p.Session, agent, state, p.Detail, "witness")
+	}
+	return panes, nil
+}
+
+// surveyPanes classifies each live polecat session.
+func surveyPanes(rigName string, polecats []string, alive func(string) (bool, error), classify func(string) (tmux.PaneClassification, error)) []PolecatPane {
+	var panes []PolecatPane
+	for _, name := range polecats {
+		sessionName := fmt.Sprintf("gt-%s-%s", rigName, name)
+		if ok, err := alive(sessionName); err != nil || !ok {
+			continue
+		}
+		pane := PolecatPane{Polecat: name, Session: sessionName}
+		if c, err := classify(sessionName); err != nil {
+			pane.Error = err.Error()
+		} else {
+			pane.State, pane.Detail = c.State, c.Detail
+		}
+		panes = append(panes, pane)
+	}
+	return panes
+}
+
+// polecatNames lists the rig's polecat directories.
+func (m *Manager) polecatNames() []string {
+	entries, err := os.ReadDir(filepath.Join(m.rig.Path, "polecats"))
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,37 @@
+package witness
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+func TestSurveyPanes(t *testing.T) {
+	alive := func(session string) (bool, error) {
+		return session != "gt-gastown-toast", nil
+	}
+	classify := func(session string) (tmux.PaneClassification, error) {
+		switch session {
+		case "gt-gastown-nux":
+			return tmux.PaneClassification{State: tmux.PanePermission, Detail: "Do you want to proceed?"}, nil
+		case "gt-gastown-slit":
+			return tmux.PaneClassification{}, errors.New("capture failed")
+		}
+		return tmux.PaneClassification{State: tmux.PaneWorking}, nil
+	}
+
+	panes := surveyPanes("gastown", []string{"furiosa", "nux", "slit", "toast"}, alive, classify)
+	if len(panes) != 3 {
+		t.Fatalf("surveyPanes = %+v, want 3 live panes", panes)
+	}
+	if panes[0].State != tmux.PaneWorking {
+		t.Errorf("furiosa = %+v", panes[0])
+	}
+	if p := panes[1]; p.Session != "gt-gastown-nux" || !p.State.Stuck() || p.Detail == "" {
+		t.Errorf("nux = %+v", p)
+	}
+	if p := panes[2]; p.Error == "" || p.State != "" {
+		t.Errorf("slit = %+v", p)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"regexp"
 	"strings"
 	"time"
+
+	"github.com/steveyegge/gastown/internal/protocol/envelope"
 )
 
 // Protocol message patterns for Witness inbox routing.
@@ -48,48 +50,59 @@ const (
 
 // PolecatDonePayload contains parsed data from a POLECAT_DONE message.
 type PolecatDonePayload struct {
-	PolecatName string
-	Exit        string // COMPLETED, ESCALATED, DEFERRED, PHASE_COMPLETE
-	IssueID     string
-	MRID        string
-	Branch      string
-	Gate        string // Gate ID when Exit is PHASE_COMPLETE
+	PolecatName string `json:"polecat"`
+	Exit        string `json:"exit"` // COMPLETED, ESCALATED, DEFERRED, PHASE_COMPLETE
+	IssueID     string `json:"issue,omitempty"`
+	MRID        string `json:"mr,omitempty"`
+	Branch      string `json:"branch,omitempty"`
+	Gate        string `json:"gate,omitempty"` // Gate ID when Exit is PHASE_COMPLETE
 }
 
 // HelpPayload contains parsed data from a HELP message.
 type HelpPayload struct {
-	Topic       string
-	Agent       string
-	IssueID     string
-	Problem     string
-	Tried       string
-	RequestedAt time.Time
+	Topic       string    `json:"topic"`
+	Agent       string    `json:"agent,omitempty"`
+	IssueID     string    `json:"issue,omitempty"`
+	Problem     string    `json:"problem,omitempty"`
+	Tried       string    `json:"tried,omitempty"`
+	RequestedAt time.Time `json:"requested_at"`
 }
 
 // MergedPayload contains parsed data from a MERGED message.
+// JSON field names match protocol.MergedPayload, which the Refinery sends.
 type MergedPayload struct {
-	PolecatName string
-	Branch      string
-	IssueID     string
-	MergedAt    time.Time
+	PolecatName string    `json:"polecat"`
+	Branch      string    `json:"branch"`
+	IssueID     string    `json:"issue"`
+	MergedAt    time.Time `json:"merged_at"`
 }
 
 // MergeFailedPayload contains parsed data from a MERGE_FAILED message.
+// JSON field names match protocol.MergeFailedPayload, which the Refinery sends.
 type MergeFailedPayload struct {
-	PolecatName string
-	Branch      string
-	IssueID     string
-	FailureType string // "build", "test", "lint", etc.
-	Error       string
-	FailedAt    time.Time
+	PolecatName string    `json:"polecat"`
+	Branch      string    `json:"branch"`
+	IssueID     string    `json:"issue"`
+	FailureType string    `json:"failure_type"` // "build", "test", "lint", etc.
+	Error       string    `json:"error"`
+	FailedAt    time.Time `json:"failed_at"`
 }
 
 // SwarmStartPayload contains parsed data from a SWARM_START message.
 type SwarmStartPayload struct {
-	SwarmID   string
-	BeadIDs   []string
-	Total     int
-	StartedAt time.Time
+	SwarmID   string    `json:"swarm_id"`
+	BeadIDs   []string  `json:"beads"`
+	Total     int       `json:"total"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// LifecycleShutdownPayload contains parsed data from a LIFECYCLE:Shutdown message.
+type LifecycleShutdownPayload struct {
+	PolecatName string `json:"polecat"`
+	Reason      string `json:"reason,omitempty"`
+	RequestedBy string `json:"requested_by,omitempty"`
+	Bead        string `json:"bead,omitempty"`
+	NewAssignee string `json:"new_assignee,omitempty"`
 }
 
 // ClassifyMessage determines the protocol type from a message subject.
@@ -114,6 +127,84 @@ func ClassifyMessage(subject string) ProtocolType {
 	}
 }
 
+// Envelope message types (see internal/protocol/envelope). MERGED and
+// MERGE_FAILED match the types the Refinery writes via internal/protocol.
+const (
+	EnvPolecatDone       = "POLECAT_DONE"
+	EnvLifecycleShutdown = "LIFECYCLE:Shutdown"
+	EnvHelp              = "HELP"
+	EnvMerged            = "MERGED"
+	EnvMergeFailed       = "MERGE_FAILED"
+	EnvSwarmStart        = "SWARM_START"
+)
+
+// subjectName returns the first capture of pattern in subject, or "".
+func subjectName(pattern *regexp.Regexp, subject string) string {
+	if m := pattern.FindStringSubmatch(subject); len(m) >= 2 {
+		return m[1]
+	}
+	return ""
+}
+
+// FormatPolecatDoneBody builds a POLECAT_DONE body: legacy "Key: value"
+// lines followed by the typed envelope.
+func FormatPolecatDoneBody(p PolecatDonePayload) string {
+	lines := []string{fmt.Sprintf("Exit: %s", p.Exit)}
+	if p.IssueID != "" {
+		lines = append(lines, fmt.Sprintf("Issue: %s", p.IssueID))
+	}
+	if p.MRID != "" {
+		lines = append(lines, fmt.Sprintf("MR: %s", p.MRID))
+	}
+	if p.Gate != "" {
+		lines = append(lines, fmt.Sprintf("Gate: %s", p.Gate))
+	}
+	lines = append(lines, fmt.Sprintf("Branch: %s", p.Branch))
+	return envelope.Append(strings.Join(lines, "\n"), EnvPolecatDone, p)
+}
+
+// FormatLifecycleShutdownBody builds a LIFECYCLE:Shutdown body with legacy
+// lines and the typed envelope.
+func FormatLifecycleShutdownBody(p LifecycleShutdownPayload) string {
+	body := fmt.Sprintf("Reason: %s\nRequestedBy: %s\nBead: %s\nNewAssignee: %s",
+		p.Reason, p.RequestedBy, p.Bead, p.NewAssignee)
+	return envelope.Append(body, EnvLifecycleShutdown, p)
+}
+
+// ParseLifecycleShutdown extracts payload from a LIFECYCLE:Shutdown message.
+// Subject format: LIFECYCLE:Shutdown <polecat-name>
+func ParseLifecycleShutdown(subject, body string) (*LifecycleShutdownPayload, error) {
+	name := subjectName(PatternLifecycleShutdown, subject)
+	payload := &LifecycleShutdownPayload{}
+	ok, err := envelope.DecodePayload(body, EnvLifecycleShutdown, payload)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		payload.Reason = legacyField(body, "Reason")
+		payload.RequestedBy = legacyField(body, "RequestedBy")
+		payload.Bead = legacyField(body, "Bead")
+		payload.NewAssignee = legacyField(body, "NewAssignee")
+	}
+	if payload.PolecatName == "" {
+		payload.PolecatName = name
+	}
+	if payload.PolecatName == "" {
+		return nil, fmt.Errorf("invalid LIFECYCLE:Shutdown subject: %s", subject)
+	}
+	return payload, nil
+}
+
+// legacyField extracts "Key: value" from a legacy text body.
+func legacyField(body, key string) string {
+	for _, line := range strings.Split(body, "\n") {
+		if v, ok := strings.CutPrefix(strings.TrimSpace(line), key+":"); ok {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
 // ParsePolecatDone extracts payload from a POLECAT_DONE message.
 // Subject format: POLECAT_DONE <polecat-name>
 // Body format:
@@ -124,16 +215,26 @@ func ClassifyMessage(subject string) ProtocolType {
 //	Gate: <gate-id>
 //	Branch: <branch>
 func ParsePolecatDone(subject, body string) (*PolecatDonePayload, error) {
+	payload := &PolecatDonePayload{}
+	if ok, err := envelope.DecodePayload(body, EnvPolecatDone, payload); err != nil {
+		return nil, err
+	} else if ok {
+		if payload.PolecatName == "" {
+			payload.PolecatName = subjectName(PatternPolecatDone, subject)
+		}
+		if payload.PolecatName == "" {
+			return nil, fmt.Errorf("POLECAT_DONE envelope has no polecat: %s", subject)
+		}
+		return payload, nil
+	}
+
 	matches := PatternPolecatDone.FindStringSubmatch(subject)
 	if len(matches) < 2 {
 		return nil, fmt.Errorf("invalid POLECAT_DONE subject: %s", subject)
 	}
+	payload.PolecatName = matches[1]
 
-	payload := &PolecatDonePayload{
-		PolecatName: matches[1],
-	}
-
-	// Parse body for structured fields
+	// Legacy body: "Key: value" lines
 	for _, line := range strings.Split(body, "\n") {
 		line = strings.TrimSpace(line)
 		if strings.HasPrefix(line, "Exit:") {
@@ -161,17 +262,27 @@ func ParsePolecatDone(subject, body string) (*PolecatDonePayload, error) {
 //	Problem: <description>
 //	Tried: <what was attempted>
 func ParseHelp(subject, body string) (*HelpPayload, error) {
+	payload := &HelpPayload{}
+	if ok, err := envelope.DecodePayload(body, EnvHelp, payload); err != nil {
+		return nil, err
+	} else if ok {
+		if payload.Topic == "" {
+			payload.Topic = subjectName(PatternHelp, subject)
+		}
+		if payload.RequestedAt.IsZero() {
+			payload.RequestedAt = time.Now()
+		}
+		return payload, nil
+	}
+
 	matches := PatternHelp.FindStringSubmatch(subject)
 	if len(matches) < 2 {
 		return nil, fmt.Errorf("invalid HELP subject: %s", subject)
 	}
+	payload.Topic = matches[1]
+	payload.RequestedAt = time.Now()
 
-	payload := &HelpPayload{
-		Topic:       matches[1],
-		RequestedAt: time.Now(),
-	}
-
-	// Parse body for structured fields
+	// Legacy body: "Key: value" lines
 	for _, line := range strings.Split(body, "\n") {
 		line = strings.TrimSpace(line)
 		if strings.HasPrefix(line, "Agent:") {
@@ -196,16 +307,26 @@ func ParseHelp(subject, body string) (*HelpPayload, error) {
 //	Issue: <issue-id>
 //	Merged-At: <timestamp>
 func ParseMerged(subject, body string) (*MergedPayload, error) {
+	payload := &MergedPayload{}
+	if ok, err := envelope.DecodePayload(body, EnvMerged, payload); err != nil {
+		return nil, err
+	} else if ok {
+		if payload.PolecatName == "" {
+			payload.PolecatName = subjectName(PatternMerged, subject)
+		}
+		if payload.PolecatName == "" {
+			return nil, fmt.Errorf("MERGED envelope has no polecat: %s", subject)
+		}
+		return payload, nil
+	}
+
 	matches := PatternMerged.FindStringSubmatch(subject)
 	if len(matches) < 2 {
 		return nil, fmt.Errorf("invalid MERGED subject: %s", subject)
 	}
+	payload.PolecatName = matches[1]
 
-	payload := &MergedPayload{
-		PolecatName: matches[1],
-	}
-
-	// Parse body for structured fields
+	// Legacy body: "Key: value" lines
 	for _, line := range strings.Split(body, "\n") {
 		line = strings.TrimSpace(line)
 		if strings.HasPrefix(line, "Branch:") {
@@ -232,17 +353,31 @@ func ParseMerged(subject, body string) (*MergedPayload, error) {
 //	FailureType: <type>
 //	Error: <error-message>
 func ParseMergeFailed(subject, body string) (*MergeFailedPayload, error) {
+	payload := &MergeFailedPayload{}
+	if ok, err := envelope.DecodePayload(body, EnvMergeFailed, payload); err != nil {
+		return nil, err
+	} else if ok {
+		if payload.PolecatName == "" {
+			payload.PolecatName = subjectName(PatternMergeFailed, subject)
+		}
+		if payload.PolecatName == "" {
+			return nil, fmt.Errorf("MERGE_FAILED envelope has no polecat: %s", subject)
+		}
+		if payload.FailedAt.IsZero() {
+			payload.FailedAt = time.Now()
+		}
+		return payload, nil
+	}
+
 	matches := PatternMergeFailed.FindStringSubmatch(subject)
 	if len(matches) < 2 {
 		return nil, fmt.Errorf("invalid MERGE_FAILED subject: %s", subject)
 	}
+	payload.PolecatName = matches[1]
+	payload.FailedAt = time.Now()
 
-	payload := &MergeFailedPayload{
-		PolecatName: matches[1],
-		FailedAt:    time.Now(),
-	}
-
-	// Parse body for structured fields
+	// Legacy body: "Key: value" lines. The Refinery writes Failure-Type;
+	// older senders wrote FailureType.
 	for _, line := range strings.Split(body, "\n") {
 		line = strings.TrimSpace(line)
 		switch {
@@ -252,6 +387,8 @@ func ParseMergeFailed(subject, body string) (*MergeFailedPayload, error) {
 			payload.IssueID = strings.TrimSpace(strings.TrimPrefix(line, "Issue:"))
 		case strings.HasPrefix(line, "FailureType:"):
 			payload.FailureType = strings.TrimSpace(strings.TrimPrefix(line, "FailureType:"))
+		case strings.HasPrefix(line, "Failure-Type:"):
+			payload.FailureType = strings.TrimSpace(strings.TrimPrefix(line, "Failure-Type:"))
 		case strings.HasPrefix(line, "Error:"):
 			payload.Error = strings.TrimSpace(strings.TrimPrefix(line, "Error:"))
 		}
@@ -261,14 +398,23 @@ func ParseMergeFailed(subject, body string) (*MergeFailedPayload, error) {
 }
 
 // ParseSwarmStart extracts payload from a SWARM_START message.
-// Body format is JSON: {"swarm_id": "batch-123", "beads": ["bd-a", "bd-b"]}
+// The typed envelope is preferred; legacy bodies carry "SwarmID:" and
+// "Total:" lines.
 func ParseSwarmStart(body string) (*SwarmStartPayload, error) {
-	payload := &SwarmStartPayload{
-		StartedAt: time.Now(),
+	payload := &SwarmStartPayload{}
+	if ok, err := envelope.DecodePayload(body, EnvSwarmStart, payload); err != nil {
+		return nil, err
+	} else if ok {
+		if payload.Total == 0 {
+			payload.Total = len(payload.BeadIDs)
+		}
+		if payload.StartedAt.IsZero() {
+			payload.StartedAt = time.Now()
+		}
+		return payload, nil
 	}
+	payload.StartedAt = time.Now()
 
-	// Parse the JSON-like body (simplified parsing for key-value extraction)
-	// Full JSON parsing would require encoding/json import
 	for _, line := range strings.Split(body, "\n") {
 		line = strings.TrimSpace(line)
 		if strings.HasPrefix(line, "SwarmID:") || strings.HasPrefix(line, "swarm_id:") {
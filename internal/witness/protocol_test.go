@@ -1,7 +1,10 @@
 package witness
 
 import (
+	"strings"
 	"testing"
+
+	"github.com/steveyegge/gastown/internal/protocol/envelope"
 )
 
 func TestClassifyMessage(t *testing.T) {
@@ -309,3 +312,138 @@ func TestAssessHelpRequest_BuildIssues(t *testing.T) {
 		t.Error("Should be able to help with build issues")
 	}
 }
+
+func TestPolecatDoneEnvelopeRoundTrip(t *testing.T) {
+	want := PolecatDonePayload{
+		PolecatName: "nux",
+		Exit:        "PHASE_COMPLETE",
+		IssueID:     "gt-abc",
+		MRID:        "gt-mr1",
+		Branch:      "polecat/nux",
+		Gate:        "gt-gate1",
+	}
+	body := FormatPolecatDoneBody(want)
+	if !strings.Contains(body, "Exit: PHASE_COMPLETE\n") {
+		t.Errorf("legacy lines missing:\n%s", body)
+	}
+
+	got, err := ParsePolecatDone("POLECAT_DONE nux", body)
+	if err != nil {
+		t.Fatalf("ParsePolecatDone: %v", err)
+	}
+	if *got != want {
+		t.Errorf("round trip = %+v, want %+v", *got, want)
+	}
+}
+
+func TestParseMergeFailed_Envelope(t *testing.T) {
+	// Shape written by protocol.NewMergeFailedMessage (Refinery).
+	body := envelope.Append("Branch: polecat/nux\nFailure-Type: tests\nError: first line", EnvMergeFailed, map[string]interface{}{
+		"branch":        "polecat/nux",
+		"issue":         "gt-abc",
+		"polecat":       "nux",
+		"rig":           "gastown",
+		"failed_at":     "2026-01-02T03:04:05Z",
+		"failure_type":  "tests",
+		"error":         "first line\nsecond line",
+		"target_branch": "main",
+	})
+
+	p, err := ParseMergeFailed("MERGE_FAILED nux", body)
+	if err != nil {
+		t.Fatalf("ParseMergeFailed: %v", err)
+	}
+	if p.Error != "first line\nsecond line" || p.FailureType != "tests" || p.IssueID != "gt-abc" {
+		t.Errorf("payload = %+v", p)
+	}
+	if p.FailedAt.Year() != 2026 {
+		t.Errorf("FailedAt = %v", p.FailedAt)
+	}
+}
+
+func TestParseMergeFailed_LegacyHyphenatedFailureType(t *testing.T) {
+	p, err := ParseMergeFailed("MERGE_FAILED nux", "Failure-Type: conflict")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.FailureType != "conflict" {
+		t.Errorf("FailureType = %q, want conflict", p.FailureType)
+	}
+}
+
+func TestParse_UnknownVersionFallsBack(t *testing.T) {
+	body := "Exit: ESCALATED\nIssue: gt-legacy\n" +
+		envelope.Prefix + `{"v":42,"type":"POLECAT_DONE","payload":{"exit":"SOMETHING_NEW"}}`
+	p, err := ParsePolecatDone("POLECAT_DONE nux", body)
+	if err != nil {
+		t.Fatalf("ParsePolecatDone: %v", err)
+	}
+	if p.Exit != "ESCALATED" || p.IssueID != "gt-legacy" {
+		t.Errorf("expected legacy fallback, got %+v", p)
+	}
+}
+
+func TestParse_EnvelopeBeforeSubject(t *testing.T) {
+	// A reworded subject doesn't stop a structured message from parsing.
+	body := envelope.Append("", EnvPolecatDone, PolecatDonePayload{PolecatName: "nux", Exit: "COMPLETED"})
+	done, err := ParsePolecatDone("Work finished: nux", body)
+	if err != nil || done.PolecatName != "nux" || done.Exit != "COMPLETED" {
+		t.Errorf("ParsePolecatDone = %+v, %v", done, err)
+	}
+
+	// The envelope's fields win over the subject and legacy lines.
+	body = envelope.Append("Branch: legacy", EnvMerged, MergedPayload{PolecatName: "nux", Branch: "polecat/nux"})
+	merged, err := ParseMerged("MERGED furiosa", body)
+	if err != nil || merged.PolecatName != "nux" || merged.Branch != "polecat/nux" {
+		t.Errorf("ParseMerged = %+v, %v", merged, err)
+	}
+
+	body = envelope.Append("", EnvMergeFailed, MergeFailedPayload{Branch: "polecat/nux", FailureType: "test"})
+	failed, err := ParseMergeFailed("MERGE_FAILED nux", body)
+	if err != nil || failed.PolecatName != "nux" || failed.FailureType != "test" {
+		t.Errorf("ParseMergeFailed = %+v, %v", failed, err)
+	}
+	if _, err := ParseMergeFailed("Merge failed", body); err == nil {
+		t.Error("envelope without a polecat and an unparseable subject should fail")
+	}
+}
+
+func TestLifecycleShutdownRoundTrip(t *testing.T) {
+	want := LifecycleShutdownPayload{
+		PolecatName: "nux",
+		Reason:      "work_reassigned",
+		RequestedBy: "mayor",
+		Bead:        "gt-abc",
+		NewAssignee: "gastown/polecats/ace",
+	}
+	got, err := ParseLifecycleShutdown("LIFECYCLE:Shutdown nux", FormatLifecycleShutdownBody(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *got != want {
+		t.Errorf("round trip = %+v, want %+v", *got, want)
+	}
+
+	legacy, err := ParseLifecycleShutdown("LIFECYCLE:Shutdown ace", "Reason: idle\nBead: gt-x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if legacy.PolecatName != "ace" || legacy.Reason != "idle" || legacy.Bead != "gt-x" {
+		t.Errorf("legacy = %+v", legacy)
+	}
+
+	if _, err := ParseLifecycleShutdown("garbage", ""); err == nil {
+		t.Error("expected error for invalid subject")
+	}
+}
+
+func TestParseSwarmStart_Envelope(t *testing.T) {
+	body := envelope.Append("SwarmID: batch-1", EnvSwarmStart, SwarmStartPayload{SwarmID: "batch-1", BeadIDs: []string{"gt-a", "gt-b"}})
+	p, err := ParseSwarmStart(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.SwarmID != "batch-1" || len(p.BeadIDs) != 2 || p.Total != 2 {
+		t.Errorf("payload = %+v", p)
+	}
+}
@@ -0,0 +1,297 @@
+package witness
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/scheduler"
+	"github.com/steveyegge/gastown/internal/tmux"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// WitnessConfig defaults.
+const (
+	DefaultMaxWorkers    = 4
+	DefaultSpawnDelayMs  = 5000
+	DefaultMaxQueueDepth = 10
+)
+
+// DefaultConfig returns the built-in witness configuration.
+func DefaultConfig() *WitnessConfig {
+	return &WitnessConfig{
+		MaxWorkers:    DefaultMaxWorkers,
+		SpawnDelayMs:  DefaultSpawnDelayMs,
+		AutoSpawn:     true,
+		MaxQueueDepth: DefaultMaxQueueDepth,
+	}
+}
+
+// LoadConfig returns the witness configuration for a rig: the defaults,
+// overridden by the witness role bead (hq-witness-role), overridden by the
+// "witness" section of the rig's settings/config.json. Sources that are
+// missing or unreadable are skipped.
+func LoadConfig(townRoot, rigPath string) *WitnessConfig {
+	cfg := DefaultConfig()
+
+	bd := beads.NewWithBeadsDir(townRoot, beads.ResolveBeadsDir(townRoot))
+	if rc, err := bd.GetRoleConfig(beads.RoleBeadIDTown("witness")); err == nil && rc != nil {
+		applyRoleConfig(cfg, rc)
+	}
+	if settings, err := config.LoadRigSettings(config.RigSettingsPath(rigPath)); err == nil && settings.Witness != nil {
+		applyRigSettings(cfg, settings.Witness)
+	}
+	return cfg
+}
+
+func applyRoleConfig(cfg *WitnessConfig, rc *beads.RoleConfig) {
+	if rc.MaxWorkers > 0 {
+		cfg.MaxWorkers = rc.MaxWorkers
+	}
+	if rc.SpawnDelayMs > 0 {
+		cfg.SpawnDelayMs = rc.SpawnDelayMs
+	}
+	if rc.AutoSpawn != nil {
+		cfg.AutoSpawn = *rc.AutoSpawn
+	}
+	if rc.EpicID != "" {
+		cfg.EpicID = rc.EpicID
+	}
+	if rc.IssuePrefix != "" {
+		cfg.IssuePrefix = rc.IssuePrefix
+	}
+	if rc.MaxQueueDepth != 0 {
+		cfg.MaxQueueDepth = rc.MaxQueueDepth
+	}
+}
+
+func applyRigSettings(cfg *WitnessConfig, s *config.WitnessSettings) {
+	if s.MaxWorkers != nil {
+		cfg.MaxWorkers = *s.MaxWorkers
+	}
+	if s.SpawnDelayMs > 0 {
+		cfg.SpawnDelayMs = s.SpawnDelayMs
+	}
+	if s.AutoSpawn != nil {
+		cfg.AutoSpawn = *s.AutoSpawn
+	}
+	if s.EpicID != "" {
+		cfg.EpicID = s.EpicID
+	}
+	if s.IssuePrefix != "" {
+		cfg.IssuePrefix = s.IssuePrefix
+	}
+	if s.MaxQueueDepth != 0 {
+		cfg.MaxQueueDepth = s.MaxQueueDepth
+	}
+}
+
+// SpawnInput is what PlanSpawns decides from.
+type SpawnInput struct {
+	Config *WitnessConfig
+
+	// Active is the number of working polecats in the rig.
+	Active int
+
+	// QueueDepth is the number of open merge requests.
+	QueueDepth int
+
+	// Ready lists the rig's ready issues.
+	Ready []*beads.Issue
+
+	// EpicChildren holds the IDs of Config.EpicID's children.
+	EpicChildren map[string]bool
+}
+
+// SpawnItem is an issue chosen for a new polecat.
+type SpawnItem struct {
+	ID       string `json:"id"`
+	Title    string `json:"title,omitempty"`
+	Priority int    `json:"priority"`
+
+	// Error is set when slinging the issue failed.
+	Error string `json:"error,omitempty"`
+
+	// Skipped is set when another dispatcher had already claimed the issue.
+	Skipped string `json:"skipped,omitempty"`
+}
+
+// SpawnPlan is the witness's spawning decision for one patrol cycle.
+type SpawnPlan struct {
+	Config     *WitnessConfig `json:"config"`
+	Active     int            `json:"active"`
+	QueueDepth int            `json:"queue_depth"`
+
+	// Eligible counts the ready issues that pass the epic and prefix
+	// filters.
+	Eligible int `json:"eligible"`
+
+	// Spawn lists the issues to sling, in order.
+	Spawn []SpawnItem `json:"spawn"`
+
+	// Reason explains why fewer issues than eligible are spawned, if so.
+	Reason string `json:"reason,omitempty"`
+}
+
+// PlanSpawns picks the ready issues to sling to new polecats: unassigned
+// work matching the epic and prefix filters, highest priority first, up
+// to the free MaxWorkers slots. Nothing is spawned while auto-spawn is off
+// or the merge queue is at MaxQueueDepth.
+func PlanSpawns(in SpawnInput) *SpawnPlan {
+	cfg := in.Config
+	plan := &SpawnPlan{Config: cfg, Active: in.Active, QueueDepth: in.QueueDepth}
+
+	var eligible []*beads.Issue
+	for _, issue := range in.Ready {
+		if !scheduler.Schedulable(issue) {
+			continue
+		}
+		if cfg.IssuePrefix != "" && !strings.HasPrefix(issue.ID, cfg.IssuePrefix) {
+			continue
+		}
+		if cfg.EpicID != "" && !in.EpicChildren[issue.ID] && issue.Parent != cfg.EpicID {
+			continue
+		}
+		eligible = append(eligible, issue)
+	}
+	sort.SliceStable(eligible, func(i, j int) bool {
+		a, b := eligible[i], eligible[j]
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+		if a.CreatedAt != b.CreatedAt {
+			return a.CreatedAt < b.CreatedAt
+		}
+		return a.ID < b.ID
+	})
+	plan.Eligible = len(eligible)
+
+	free := cfg.MaxWorkers - in.Active
+	switch {
+	case !cfg.AutoSpawn:
+		plan.Reason = "auto_spawn is off"
+		return plan
+	case len(eligible) == 0:
+		plan.Reason = "no ready issues"
+		return plan
+	case cfg.MaxQueueDepth > 0 && in.QueueDepth >= cfg.MaxQueueDepth:
+		plan.Reason = fmt.Sprintf("merge queue is deep (%d/%d open)", in.QueueDepth, cfg.MaxQueueDepth)
+		return plan
+	case free <= 0:
+		plan.Reason = fmt.Sprintf("at max_workers (%d/%d working)", in.Active, cfg.MaxWorkers)
+		return plan
+	}
+
+	if len(eligible) > free {
+		plan.Reason = fmt.Sprintf("max_workers leaves %d free slot(s)", free)
+		eligible = eligible[:free]
+	}
+	for _, issue := range eligible {
+		plan.Spawn = append(plan.Spawn, SpawnItem{ID: issue.ID, Title: issue.Title, Priority: issue.Priority})
+	}
+	return plan
+}
+
+// slingToRig starts a polecat on an issue. Tests replace it.
+var slingToRig = func(townRoot, issueID, rigName string) error {
+	return util.ExecRun(townRoot, "gt", "sling", issueID, rigName)
+}
+
+// spawnSleep waits between spawns. Tests replace it.
+var spawnSleep = time.Sleep
+
+// AutoSpawn plans this cycle's spawns for the rig and, unless dryRun,
+// slings each planned issue to a new polecat, SpawnDelayMs apart. Failed
+// slings are recorded on their SpawnItem and do not stop the others.
+// Each issue is claimed through the town's dispatch claims first, so an
+// issue the daemon scheduler is slinging is skipped rather than slung
+// twice.
+func (m *Manager) AutoSpawn(dryRun bool) (*SpawnPlan, error) {
+	townRoot := filepath.Dir(m.rig.Path)
+	in, err := m.spawnInput(townRoot)
+	if err != nil {
+		return nil, err
+	}
+	plan := PlanSpawns(in)
+	if dryRun {
+		return plan, nil
+	}
+
+	delay := time.Duration(plan.Config.SpawnDelayMs) * time.Millisecond
+	slung := 0
+	for i := range plan.Spawn {
+		claim, err := scheduler.ClaimBead(townRoot, plan.Spawn[i].ID, m.rig.Name+"/witness", time.Now())
+		if err != nil {
+			plan.Spawn[i].Skipped = err.Error()
+			continue
+		}
+		if slung > 0 && delay > 0 {
+			spawnSleep(delay)
+		}
+		slung++
+		if err := slingToRig(townRoot, plan.Spawn[i].ID, m.rig.Name); err != nil {
+			claim.Release()
+			plan.Spawn[i].Error = err.Error()
+		}
+	}
+	return plan, nil
+}
+
+// spawnInput gathers the rig's configuration, polecat pool, merge queue
+// and ready work.
+func (m *Manager) spawnInput(townRoot string) (SpawnInput, error) {
+	in := SpawnInput{Config: LoadConfig(townRoot, m.rig.Path)}
+
+	polecats, err := polecat.NewManager(m.rig, git.NewGit(m.rig.Path), tmux.NewTmux()).List()
+	if err != nil {
+		return in, fmt.Errorf("listing polecats: %w", err)
+	}
+	for _, p := range polecats {
+		if p.State.IsActive() {
+			in.Active++
+		}
+	}
+
+	work := beads.NewWithBeadsDir(m.rig.Path, beads.ResolveBeadsDir(m.rig.Path))
+	return in, readSpawnWork(&in, work, beads.New(m.rig.BeadsPath()))
+}
+
+// readSpawnWork fills in the merge queue depth from queue and the ready
+// issues (and epic children) from work.
+func readSpawnWork(in *SpawnInput, work, queue beads.Store) error {
+	if in.Config.MaxQueueDepth > 0 {
+		// Same query as the refinery's queue (refinery imports witness
+		// via protocol, so it cannot be called from here).
+		mrs, err := queue.List(beads.ListOptions{
+			Type:     "merge-request",
+			Status:   "open",
+			Priority: -1,
+		})
+		if err != nil {
+			return fmt.Errorf("reading merge queue: %w", err)
+		}
+		in.QueueDepth = len(mrs)
+	}
+
+	var err error
+	if in.Ready, err = work.Ready(); err != nil {
+		return fmt.Errorf("listing ready issues: %w", err)
+	}
+	if in.Config.EpicID != "" {
+		children, err := work.List(beads.ListOptions{Parent: in.Config.EpicID, Status: "open", Priority: -1})
+		if err != nil {
+			return fmt.Errorf("listing children of %s: %w", in.Config.EpicID, err)
+		}
+		in.EpicChildren = make(map[string]bool, len(children))
+		for _, c := range children {
+			in.EpicChildren[c.ID] = true
+		}
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package witness
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func readyIssues() []*beads.Issue {
+	return []*beads.Issue{
+		{ID: "gt-3", Title: "low", Type: "task", Priority: 3, CreatedAt: "2026-01-01T00:00:00Z"},
+		{ID: "gt-1", Title: "urgent", Type: "bug", Priority: 0, CreatedAt: "2026-01-03T00:00:00Z"},
+		{ID: "gt-2", Title: "normal old", Type: "feature", Priority: 2, CreatedAt: "2026-01-01T00:00:00Z"},
+		{ID: "gt-4", Title: "normal new", Type: "task", Priority: 2, CreatedAt: "2026-01-02T00:00:00Z", Parent: "gt-epic"},
+		{ID: "gt-5", Title: "taken", Type: "task", Priority: 0, Assignee: "gastown/polecats/nux"},
+		{ID: "gt-epic", Title: "epic", Type: "epic", Priority: 0},
+		{ID: "bd-1", Title: "other prefix", Type: "task", Priority: 0, CreatedAt: "2026-01-04T00:00:00Z"},
+	}
+}
+
+func spawnIDs(plan *SpawnPlan) string {
+	var ids []string
+	for _, s := range plan.Spawn {
+		ids = append(ids, s.ID)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestPlanSpawns(t *testing.T) {
+	tests := []struct {
+		name     string
+		mutate   func(*SpawnInput)
+		want     string
+		eligible int
+		reason   string
+	}{
+		{
+			name:     "priority order within free slots",
+			want:     "gt-1,bd-1,gt-2",
+			eligible: 5,
+			reason:   "max_workers leaves 3 free slot(s)",
+		},
+		{
+			name:     "issue prefix filter",
+			mutate:   func(in *SpawnInput) { in.Config.IssuePrefix = "gt-" },
+			want:     "gt-1,gt-2,gt-4",
+			eligible: 4,
+			reason:   "max_workers leaves 3 free slot(s)",
+		},
+		{
+			name: "epic filter",
+			mutate: func(in *SpawnInput) {
+				in.Config.EpicID = "gt-epic"
+				in.EpicChildren = map[string]bool{"gt-3": true}
+			},
+			want:     "gt-4,gt-3",
+			eligible: 2,
+		},
+		{
+			name:     "auto spawn off",
+			mutate:   func(in *SpawnInput) { in.Config.AutoSpawn = false },
+			eligible: 5,
+			reason:   "auto_spawn is off",
+		},
+		{
+			name:     "deep merge queue",
+			mutate:   func(in *SpawnInput) { in.QueueDepth = 10 },
+			eligible: 5,
+			reason:   "merge queue is deep (10/10 open)",
+		},
+		{
+			name: "queue back-off disabled",
+			mutate: func(in *SpawnInput) {
+				in.QueueDepth = 50
+				in.Config.MaxQueueDepth = -1
+				in.Config.MaxWorkers = 2
+			},
+			want:     "gt-1",
+			eligible: 5,
+			reason:   "max_workers leaves 1 free slot(s)",
+		},
+		{
+			name:     "at max workers",
+			mutate:   func(in *SpawnInput) { in.Active = 4 },
+			eligible: 5,
+			reason:   "at max_workers (4/4 working)",
+		},
+		{
+			name:   "nothing ready",
+			mutate: func(in *SpawnInput) { in.Ready = nil },
+			reason: "no ready issues",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := SpawnInput{Config: DefaultConfig(), Active: 1, Ready: readyIssues()}
+			if tt.mutate != nil {
+				tt.mutate(&in)
+			}
+			plan := PlanSpawns(in)
+			if got := spawnIDs(plan); got != tt.want {
+				t.Errorf("spawn = %q, want %q", got, tt.want)
+			}
+			if plan.Eligible != tt.eligible {
+				t.Errorf("eligible = %d, want %d", plan.Eligible, tt.eligible)
+			}
+			if plan.Reason != tt.reason {
+				t.Errorf("reason = %q, want %q", plan.Reason, tt.reason)
+			}
+		})
+	}
+}
+
+func TestConfigLayering(t *testing.T) {
+	off, on := false, true
+	cfg := DefaultConfig()
+	applyRoleConfig(cfg, &beads.RoleConfig{MaxWorkers: 8, AutoSpawn: &off, IssuePrefix: "gt-"})
+	two := 2
+	applyRigSettings(cfg, &config.WitnessSettings{MaxWorkers: &two, AutoSpawn: &on, MaxQueueDepth: -1})
+
+	if cfg.MaxWorkers != 2 || !cfg.AutoSpawn || cfg.MaxQueueDepth != -1 {
+		t.Errorf("rig settings should win: %+v", cfg)
+	}
+	if cfg.IssuePrefix != "gt-" {
+		t.Errorf("role bead prefix lost: %+v", cfg)
+	}
+	if cfg.SpawnDelayMs != DefaultSpawnDelayMs {
+		t.Errorf("SpawnDelayMs = %d, want default", cfg.SpawnDelayMs)
+	}
+}
+
+func TestReadSpawnWork(t *testing.T) {
+	work := beads.NewMemStore("gt")
+	work.Add(
+		&beads.Issue{ID: "gt-epic", Title: "Epic", Type: "epic", Priority: 1},
+		&beads.Issue{ID: "gt-1", Title: "In epic", Parent: "gt-epic", Priority: 2},
+		&beads.Issue{ID: "gt-2", Title: "Blocked", Priority: 0, DependsOn: []string{"gt-1"}},
+		&beads.Issue{ID: "gt-3", Title: "Loose", Priority: 3},
+	)
+	queue := beads.NewMemStore("gt")
+	for i := 0; i < 3; i++ {
+		if _, err := queue.Create(beads.CreateOptions{Title: "MR", Type: "merge-request", Priority: i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := queue.Close("gt-3"); err != nil {
+		t.Fatal(err)
+	}
+
+	in := SpawnInput{Config: DefaultConfig()}
+	in.Config.EpicID = "gt-epic"
+	if err := readSpawnWork(&in, work, queue); err != nil {
+		t.Fatal(err)
+	}
+	if in.QueueDepth != 2 {
+		t.Errorf("QueueDepth = %d, want 2", in.QueueDepth)
+	}
+	if len(in.Ready) != 3 || !in.EpicChildren["gt-1"] || len(in.EpicChildren) != 1 {
+		t.Errorf("ready = %d, epic children = %v", len(in.Ready), in.EpicChildren)
+	}
+
+	plan := PlanSpawns(in)
+	if got := spawnIDs(plan); got != "gt-1" {
+		t.Errorf("spawn = %q, want gt-1 (the epic itself and other work filtered)", got)
+	}
+}
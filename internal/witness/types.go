@@ -20,4 +20,8 @@ type WitnessConfig struct {
 
 	// IssuePrefix limits spawning to issues with this prefix (optional).
 	IssuePrefix string `json:"issue_prefix,omitempty"`
+
+	// MaxQueueDepth pauses spawning while this many merge requests are
+	// open (default: 10; negative disables the back-off).
+	MaxQueueDepth int `json:"max_queue_depth,omitempty"`
 }